@@ -2,9 +2,22 @@ package application
 
 import (
 	"api/cmd/rest/handlers"
+	"api/cmd/rest/middlewares/idempotent"
 	"api/cmd/rest/middlewares/logger"
+	"api/cmd/rest/middlewares/mapping"
+	"api/internal/comment"
+	"api/internal/idempotency"
+	"api/internal/profiles/mapper"
+	"api/internal/profiles/storage"
+	"api/internal/project"
 	"api/internal/task"
+	"api/pkg/uuidgenerator"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -12,12 +25,42 @@ import (
 
 // -----------------------------------------------------------------------------
 func NewConfigDefault() *Config {
-	return &Config{}
+	return &Config{
+		TrashRetention: 	30 * 24 * time.Hour,
+		TrashPurgeInterval: time.Hour,
+		TaskHierarchy: 		task.HierarchyRestrict,
+		IdempotencyTTL: 	24 * time.Hour,
+		RankRebalanceInterval: time.Hour,
+	}
 }
 
 // Config is an struct that contains all the configuration of the application.
 type Config struct {
-	// ...
+	// TrashRetention: time a deleted task is kept in the trash before it is purged.
+	TrashRetention time.Duration
+	// TrashPurgeInterval: time between two purges of the trash (and of the expired idempotency keys), never purged if not positive.
+	TrashPurgeInterval time.Duration
+	// TaskHierarchy: rule applied to the subtasks when a task is completed.
+	TaskHierarchy task.HierarchyRule
+	// TaskWorkflow: allowed transitions between the statuses of a task (task.DefaultWorkflow if nil).
+	TaskWorkflow task.Workflow
+	// TaskStorageDir: directory the tasks, their projects and their comments are kept in by the durable storages (task.NewStorageWAL,
+	// project.NewStorageFile, comment.NewStorageFile), in memory only if empty (task.NewStorageLocal, project.NewStorageLocal, comment.NewStorageLocal).
+	TaskStorageDir string
+	// TaskDatabase: database the tasks, their history, their projects and their comments are kept in, instead of TaskStorageDir (not used if nil).
+	TaskDatabase *sql.DB
+	// TaskDatabaseDriver: driver TaskDatabase was opened with ("sqlite" for task.NewStorageSQLite, "postgres" for task.NewStoragePostgres).
+	TaskDatabaseDriver string
+	// ProfileMapper: maps the user of a request to its profile, the owner of the tasks (required).
+	ProfileMapper mapper.ProfileMapper
+	// ProfilesStorage: keeps the profiles of the users, served by the /profiles routes (not served if nil).
+	ProfilesStorage storage.ProfilesStorage
+	// IdempotencyStore: keeps the responses of the requests with an Idempotency-Key header (idempotency.NewStoreLocal if nil).
+	IdempotencyStore idempotency.Store
+	// IdempotencyTTL: time an idempotency key is kept since the first request.
+	IdempotencyTTL time.Duration
+	// RankRebalanceInterval: time between two rebalances of the ranks that order the tasks by hand, never rebalanced if not positive.
+	RankRebalanceInterval time.Duration
 }
 
 
@@ -31,15 +74,91 @@ type App struct {
 	config *Config
 	// router: represents the| router of the application.
 	router chi.Router
+	// storage: represents the task storage of the application.
+	storage task.Storage
+	// keys: represents the store of the idempotency keys of the application.
+	keys idempotency.Store
 }
 
 func (a *App) Dependencies() (err error) {
 	// initialize dependencies (based on config)
-	db := []*task.Task{}
-	vl := task.NewValidatorLocal()
-	st := task.NewStorageLocal(db, vl)
+	if a.config.ProfileMapper == nil {
+		err = errors.New("profile mapper required")
+		return
+	}
+	mp := mapping.NewProfileMapping(a.config.ProfileMapper)
+
+	// -> the projects are kept next to the tasks, the tasks are checked against them
+	var ps project.Storage
+	switch {
+	case a.config.TaskDatabase != nil:
+		ps, err = a.projectDatabase()
+		if err != nil {
+			return
+		}
+	case a.config.TaskStorageDir != "":
+		ps, err = project.NewStorageFile(a.config.TaskStorageDir, project.NewValidatorLocal(nil))
+		if err != nil {
+			return
+		}
+	default:
+		ps = project.NewStorageLocal([]*project.Project{}, project.NewValidatorLocal(nil))
+	}
+
+	vl := task.NewValidatorLocal(&task.ValidatorConfig{Workflow: a.config.TaskWorkflow})
+	tc := &task.Config{Hierarchy: a.config.TaskHierarchy, Projects: ps}
+	var sl task.Storage
+	var hs task.History
+	switch {
+	case a.config.TaskDatabase != nil:
+		sl, hs, err = a.taskDatabase(vl, tc)
+		if err != nil {
+			return
+		}
+	case a.config.TaskStorageDir != "":
+		sl, err = task.NewStorageWAL(a.config.TaskStorageDir, vl, tc)
+		if err != nil {
+			return
+		}
+		hs = task.NewHistoryLocal()
+	default:
+		db := []*task.Task{}
+		sl = task.NewStorageLocal(db, vl, tc)
+		hs = task.NewHistoryLocal()
+	}
+	st := task.NewStorageHistory(sl, hs)
+	a.storage = st
+
+	// -> the comments are kept next to the tasks
+	var cs comment.Storage
+	switch {
+	case a.config.TaskDatabase != nil:
+		cs, err = a.commentDatabase()
+		if err != nil {
+			return
+		}
+	case a.config.TaskStorageDir != "":
+		cs, err = comment.NewStorageFile(a.config.TaskStorageDir, comment.NewValidatorLocal(nil))
+		if err != nil {
+			return
+		}
+	default:
+		cs = comment.NewStorageLocal([]*comment.Comment{}, comment.NewValidatorLocal(nil))
+	}
 
 	ct := handlers.NewTaskController(st)
+	cm := handlers.NewCommentController(st, cs)
+	ch := handlers.NewHistoryController(st, hs)
+	cp := handlers.NewProjectController(ps, st)
+
+	ks := a.config.IdempotencyStore
+	if ks == nil {
+		ks = idempotency.NewStoreLocal()
+	}
+	a.keys = ks
+	ip := idempotent.NewIdempotent(ks, a.config.IdempotencyTTL, idempotent.CallerProfile)
+	// -> a profile is activated before the user has one
+	iu := idempotent.NewIdempotent(ks, a.config.IdempotencyTTL, idempotent.CallerUser)
 
 	// register routes
 	// -> middlewares: handler#1 -> (http.HandlerFunc) middleware #1 -> (http.Handler) middleware #2 -> ... -> serveHTTP()
@@ -50,18 +169,198 @@ func (a *App) Dependencies() (err error) {
 	a.router.Get("/ping", handlers.Health())
 
 	a.router.Route("/tasks", func(r chi.Router) {
+		// Map the profile of the user (tasks are scoped to it)
+		r.Use(mp.MapProfile)
+
+		// List tasks
+		r.Get("/", ct.List())
+		// List the tasks in the trash
+		r.Get("/trash", ct.Trash())
+		// List the tasks shared with the profile
+		r.Get("/shared", ct.Shared())
+		// List the open tasks by due date
+		r.Get("/overdue", ct.Overdue())
+		r.Get("/due-today", ct.DueToday())
+		r.Get("/upcoming", ct.Upcoming())
+		// Order tasks by their dependencies
+		r.Get("/order", ct.Order())
 		// Get a task
 		r.Get("/{id}", ct.Get())
-		// Create a task
-		r.Post("/", ct.Create())
+		// Create a task (retries with the same Idempotency-Key replay the first response)
+		r.With(ip.Replay).Post("/", ct.Create())
+		// Update a task
+		r.Put("/{id}", ct.Update())
+		// Patch a task
+		r.Patch("/{id}", ct.Patch())
+		// Move a task to another status
+		r.Post("/{id}/transitions", ct.Transition())
+		// Delete a task (moves it to the trash)
+		r.Delete("/{id}", ct.Delete())
+		// Restore a task from the trash
+		r.Post("/{id}/restore", ct.Restore())
+		// Order a task by hand, between two others
+		r.Post("/{id}/move", ct.Move())
+		// Add and remove labels of a task
+		r.Post("/{id}/labels", ct.AddLabel())
+		r.Delete("/{id}/labels/{label}", ct.RemoveLabel())
+		// Add, check, reorder and remove the items of the checklist of a task
+		r.Post("/{id}/checklist", ct.AddItem())
+		r.Patch("/{id}/checklist/{item_id}", ct.CheckItem())
+		r.Post("/{id}/checklist/{item_id}/move", ct.MoveItem())
+		r.Delete("/{id}/checklist/{item_id}", ct.RemoveItem())
+		// Add and remove the tasks that block a task
+		r.Post("/{id}/dependencies", ct.AddDependency())
+		r.Delete("/{id}/dependencies/{blocker_id}", ct.RemoveDependency())
+		// Share a task with other profiles and stop sharing it
+		r.Get("/{id}/grants", ct.Grants())
+		r.Post("/{id}/grants", ct.Grant())
+		r.Delete("/{id}/grants/{profile_id}", ct.Revoke())
+		// Comment on a task and reply to its comments (only the author can edit or delete a comment)
+		r.Get("/{id}/comments", cm.List())
+		r.Post("/{id}/comments", cm.Create())
+		r.Put("/{id}/comments/{comment_id}", cm.Update())
+		r.Delete("/{id}/comments/{comment_id}", cm.Delete())
+		// Get the history of changes of a task
+		r.Get("/{id}/history", ch.List())
+	})
+	// Run many operations over the tasks in a single request
+	a.router.With(mp.MapProfile).Post("/tasks:batch", ct.Batch())
+	// List the labels in use
+	a.router.With(mp.MapProfile).Get("/labels", ct.Labels())
+
+	if a.config.ProfilesStorage != nil {
+		cf := handlers.NewProfileController(a.config.ProfilesStorage, uuidgenerator.NewUUIDGeneratorGoogle())
+
+		a.router.Route("/profiles", func(r chi.Router) {
+			// Activate the profile of the user (retries with the same Idempotency-Key replay the first response)
+			r.With(iu.Replay).Post("/", cf.ActivateProfile())
+			// Get and update the profile of the user
+			r.With(mp.MapProfile).Get("/me", cf.GetProfileById())
+			r.With(mp.MapProfile).Put("/me", cf.UpdateProfile())
+		})
+	}
+
+	a.router.Route("/projects", func(r chi.Router) {
+		// Map the profile of the user (projects are scoped to it)
+		r.Use(mp.MapProfile)
+
+		// List projects
+		r.Get("/", cp.List())
+		// Get a project
+		r.Get("/{id}", cp.Get())
+		// Create a project
+		r.Post("/", cp.Create())
+		// Update a project
+		r.Put("/{id}", cp.Update())
+		// Delete a project (only if it has no tasks)
+		r.Delete("/{id}", cp.Delete())
+		// List the tasks of a project (a task is moved with its project_id)
+		r.Get("/{id}/tasks", cp.Tasks())
 	})
 
 	return
 }
 
+// projectDatabase returns the storage of the projects kept in the task database, by its driver.
+func (a *App) projectDatabase() (ps project.Storage, err error) {
+	switch a.config.TaskDatabaseDriver {
+	case "sqlite":
+		ps = project.NewStorageSQLite(a.config.TaskDatabase, project.NewValidatorLocal(nil))
+	case "postgres":
+		ps = project.NewStoragePostgres(a.config.TaskDatabase, project.NewValidatorLocal(nil))
+	default:
+		err = fmt.Errorf("task database driver %q not supported", a.config.TaskDatabaseDriver)
+	}
+	return
+}
+
+// commentDatabase returns the storage of the comments kept in the task database, by its driver.
+func (a *App) commentDatabase() (cs comment.Storage, err error) {
+	switch a.config.TaskDatabaseDriver {
+	case "sqlite":
+		cs = comment.NewStorageSQLite(a.config.TaskDatabase, comment.NewValidatorLocal(nil))
+	case "postgres":
+		cs = comment.NewStoragePostgres(a.config.TaskDatabase, comment.NewValidatorLocal(nil))
+	default:
+		err = fmt.Errorf("task database driver %q not supported", a.config.TaskDatabaseDriver)
+	}
+	return
+}
+
+// taskDatabase returns the storage and the history of the tasks kept in the task database, by its driver.
+func (a *App) taskDatabase(vl task.Validator, tc *task.Config) (st task.Storage, hs task.History, err error) {
+	switch a.config.TaskDatabaseDriver {
+	case "sqlite":
+		// -> the tables are created the first time
+		st, err = task.NewStorageSQLite(a.config.TaskDatabase, vl, tc)
+		if err != nil {
+			return
+		}
+		hs = task.NewHistorySQLite(a.config.TaskDatabase)
+	case "postgres":
+		// -> the tables are created the first time
+		_, err = a.config.TaskDatabase.Exec(task.SchemaPostgres)
+		if err != nil {
+			return
+		}
+		st = task.NewStoragePostgres(a.config.TaskDatabase, vl, tc)
+		hs = task.NewHistoryPostgres(a.config.TaskDatabase)
+	default:
+		err = fmt.Errorf("task database driver %q not supported", a.config.TaskDatabaseDriver)
+	}
+	return
+}
+
 // Run starts the application.
 func (a *App) Run() (err error) {
+	// purge the trash in background
+	// -> a ticker needs a positive interval
+	if a.config.TrashPurgeInterval > 0 {
+		go a.purge()
+	}
+	// rebalance the ranks of the tasks in background
+	if a.config.RankRebalanceInterval > 0 {
+		go a.rebalance()
+	}
+
 	// start the application
 	err = http.ListenAndServe(":8080", a.router)
 	return
-}
\ No newline at end of file
+}
+
+// purge removes for good the tasks kept in the trash longer than the retention, and the expired idempotency keys.
+func (a *App) purge() {
+	ticker := time.NewTicker(a.config.TrashPurgeInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		n, err := a.storage.Purge(time.Now().Add(-a.config.TrashRetention))
+		if err != nil {
+			log.Println("failed to purge the trash:", err)
+		} else {
+			log.Printf("purged %d tasks from the trash", n)
+		}
+
+		n, err = a.keys.Purge()
+		if err != nil {
+			log.Println("failed to purge the idempotency keys:", err)
+			continue
+		}
+		log.Printf("purged %d idempotency keys", n)
+	}
+}
+
+// rebalance spreads again the ranks of the tasks that grew too long by moving them.
+func (a *App) rebalance() {
+	ticker := time.NewTicker(a.config.RankRebalanceInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		n, err := a.storage.Rebalance()
+		if err != nil {
+			log.Println("failed to rebalance the ranks:", err)
+			continue
+		}
+		log.Printf("rebalanced %d tasks", n)
+	}
+}
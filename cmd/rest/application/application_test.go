@@ -0,0 +1,184 @@
+package application
+
+import (
+	"api/internal/profiles/mapper"
+	"api/internal/profiles/storage"
+	"api/internal/task"
+	"api/pkg/sqlite"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newConfig returns the default config, with the user u1 mapped to the profile p1.
+func newConfig() (cfg *Config) {
+	pm := mapper.NewProfileMapperMock()
+	pm.On("MapProfile", "u1").Return("p1", nil)
+
+	cfg = NewConfigDefault()
+	cfg.ProfileMapper = pm
+	return
+}
+
+// newApp returns an application with the config.
+func newApp(t *testing.T, cfg *Config) (a *App) {
+	a = NewApp(cfg, chi.NewRouter())
+	assert.NoError(t, a.Dependencies())
+	return
+}
+
+// serve serves the request (as the user u1 if the header has no user), and returns the response with the id of the data.
+func serve(a *App, method string, target string, body string, header http.Header) (res *httptest.ResponseRecorder, id string) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if req.Header.Get("User-Id") == "" {
+		req.Header.Set("User-Id", "u1")
+	}
+	res = httptest.NewRecorder()
+	a.router.ServeHTTP(res, req)
+
+	var resBody struct {
+		Data struct {ID string `json:"id"`} `json:"data"`
+	}
+	_ = json.NewDecoder(strings.NewReader(res.Body.String())).Decode(&resBody)
+	id = resBody.Data.ID
+	return
+}
+
+// Tests
+func TestApp_Restart(t *testing.T) {
+	// arrange
+	cfg := newConfig()
+	cfg.TaskStorageDir = t.TempDir()
+	a := newApp(t, cfg)
+	res, projectId := serve(a, http.MethodPost, "/projects/", `{"name": "work"}`, nil)
+	assert.Equal(t, http.StatusCreated, res.Code)
+	res, taskId := serve(a, http.MethodPost, "/tasks/", `{"title": "title", "status": "todo", "project_id": "`+projectId+`"}`, nil)
+	assert.Equal(t, http.StatusCreated, res.Code)
+	tag := res.Header().Get("ETag")
+	res, _ = serve(a, http.MethodPost, "/tasks/"+taskId+"/comments", `{"body": "first"}`, nil)
+	assert.Equal(t, http.StatusCreated, res.Code)
+
+	// act
+	restarted := newApp(t, cfg)
+	res, _ = serve(restarted, http.MethodPut, "/tasks/"+taskId, `{"title": "renamed", "status": "todo", "project_id": "`+projectId+`"}`, http.Header{"If-Match": {tag}})
+
+	// assert
+	// -> the project of the task is still found after the restart
+	assert.Equal(t, http.StatusOK, res.Code)
+	res, _ = serve(restarted, http.MethodGet, "/projects/"+projectId+"/tasks", "", nil)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `"renamed"`)
+	// -> the comments of the task too
+	res, _ = serve(restarted, http.MethodGet, "/tasks/"+taskId+"/comments", "", nil)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `"first"`)
+}
+
+func TestApp_TaskDatabase(t *testing.T) {
+	// arrange
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "api.db"))
+	assert.NoError(t, err)
+	defer db.Close()
+	cfg := newConfig()
+	cfg.TaskDatabase = db
+	cfg.TaskDatabaseDriver = "sqlite"
+	a := newApp(t, cfg)
+	res, projectId := serve(a, http.MethodPost, "/projects/", `{"name": "work"}`, nil)
+	assert.Equal(t, http.StatusCreated, res.Code)
+	res, taskId := serve(a, http.MethodPost, "/tasks/", `{"title": "title", "status": "todo", "project_id": "`+projectId+`"}`, nil)
+	assert.Equal(t, http.StatusCreated, res.Code)
+	tag := res.Header().Get("ETag")
+	res, _ = serve(a, http.MethodPost, "/tasks/"+taskId+"/comments", `{"body": "first"}`, nil)
+	assert.Equal(t, http.StatusCreated, res.Code)
+
+	// act
+	restarted := newApp(t, cfg)
+	res, _ = serve(restarted, http.MethodPut, "/tasks/"+taskId, `{"title": "renamed", "status": "todo", "project_id": "`+projectId+`"}`, http.Header{"If-Match": {tag}})
+
+	// assert
+	// -> the tasks, their projects, their comments and their history are kept in the database
+	assert.Equal(t, http.StatusOK, res.Code)
+	var count int
+	assert.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM tasks WHERE project_id = ?`, projectId).Scan(&count))
+	assert.Equal(t, 1, count)
+	assert.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM task_comments WHERE task_id = ?`, taskId).Scan(&count))
+	assert.Equal(t, 1, count)
+	res, _ = serve(restarted, http.MethodGet, "/tasks/"+taskId+"/history", "", nil)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `"renamed"`)
+}
+
+func TestApp_TaskDatabasePostgres(t *testing.T) {
+	// arrange
+	db, mk, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	// -> the tables are created the first time, then the task is read from them
+	mk.ExpectExec(task.SchemaPostgres).WillReturnResult(sqlmock.NewResult(0, 0))
+	mk.
+		ExpectPrepare(`SELECT id, owner_id, title, description, status, parent_id, start_at, due_at, created_at, updated_at, deleted_at, recurrence, series_id, occurrence, version, project_id, rank_key, checklist, (SELECT STRING_AGG(label, ',' ORDER BY label) FROM task_labels WHERE task_labels.task_id = tasks.id) AS labels FROM tasks WHERE id = $1 AND deleted_at IS NULL AND (tasks.owner_id = $2 OR EXISTS (SELECT 1 FROM task_grants WHERE task_grants.task_id = tasks.id AND task_grants.profile_id = $3))`).
+		ExpectQuery().WithArgs("1", "p1", "p1").
+		WillReturnError(sql.ErrNoRows)
+	cfg := newConfig()
+	cfg.TaskDatabase = db
+	cfg.TaskDatabaseDriver = "postgres"
+	a := newApp(t, cfg)
+
+	// act
+	res, _ := serve(a, http.MethodGet, "/tasks/1", "", nil)
+
+	// assert
+	assert.Equal(t, http.StatusNotFound, res.Code)
+	assert.NoError(t, mk.ExpectationsWereMet())
+}
+
+func TestApp_TaskDatabaseDriver(t *testing.T) {
+	// arrange
+	cfg := newConfig()
+	cfg.TaskDatabase = &sql.DB{}
+	cfg.TaskDatabaseDriver = "oracle"
+	a := NewApp(cfg, chi.NewRouter())
+
+	// act
+	err := a.Dependencies()
+
+	// assert
+	assert.EqualError(t, err, `task database driver "oracle" not supported`)
+}
+
+func TestApp_ActivateProfileReplay(t *testing.T) {
+	// arrange
+	st := storage.NewImplProfilesStorageMock()
+	st.On("ActivateProfile", mock.Anything).Return(nil)
+	cfg := newConfig()
+	cfg.ProfilesStorage = st
+	a := newApp(t, cfg)
+
+	// act
+	first, _ := serve(a, http.MethodPost, "/profiles/", "", http.Header{"Idempotency-Key": {"k1"}})
+	retry, _ := serve(a, http.MethodPost, "/profiles/", "", http.Header{"Idempotency-Key": {"k1"}})
+	other, _ := serve(a, http.MethodPost, "/profiles/", "", http.Header{"Idempotency-Key": {"k1"}, "User-Id": {"u2"}})
+
+	// assert
+	// -> the retry gets the first response back, without activating the profile again
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, http.StatusOK, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	// -> the keys are scoped to the user
+	assert.Equal(t, http.StatusOK, other.Code)
+	assert.Empty(t, other.Header().Get("Idempotent-Replayed"))
+	st.AssertNumberOfCalls(t, "ActivateProfile", 2)
+}
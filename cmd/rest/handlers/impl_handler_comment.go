@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"api/cmd/rest/middlewares/logger"
+	"api/cmd/rest/response"
+	"api/internal/comment"
+	"api/internal/profiles/contexter"
+	"api/internal/task"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/LNMMusic/optional"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func NewCommentController(tasks task.Storage, comments comment.Storage) *Comment {
+	return &Comment{tasks: tasks, comments: comments}
+}
+
+// Comment is an implementation of the comment controller.
+// - the comments of a task are visible to the profiles that can read it (its owner and the ones it is shared with)
+type Comment struct {
+	// tasks is the storage used to check the access to the task
+	tasks task.Storage
+	// comments
+	comments comment.Storage
+}
+
+// CommentDTO is the representation of a comment in the responses.
+type CommentDTO struct {
+	ID			optional.Option[string]	`json:"id"`
+	TaskID		optional.Option[string]	`json:"task_id"`
+	AuthorID	optional.Option[string]	`json:"author_id"`
+	ParentID	optional.Option[string]	`json:"parent_id"`
+	Body		optional.Option[string]	`json:"body"`
+	CreatedAt	optional.Option[time.Time] `json:"created_at"`
+	UpdatedAt	optional.Option[time.Time] `json:"updated_at"`
+}
+
+// NewCommentDTO returns the representation of the given comment.
+func NewCommentDTO(c *comment.Comment) (dto CommentDTO) {
+	dto = CommentDTO{
+		ID: 	   c.ID,
+		TaskID:    c.TaskID,
+		AuthorID:  c.AuthorID,
+		ParentID:  c.ParentID,
+		Body: 	   c.Body,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+	return
+}
+
+// ThreadDTO is the representation of a comment with its replies in the responses.
+type ThreadDTO struct {
+	CommentDTO
+	Replies []CommentDTO `json:"replies"`
+}
+
+// NewThreadDTO returns the representation of the given thread.
+func NewThreadDTO(th *comment.Thread) (dto ThreadDTO) {
+	dto = ThreadDTO{CommentDTO: NewCommentDTO(th.Comment), Replies: make([]CommentDTO, 0, len(th.Replies))}
+	for _, rp := range th.Replies {
+		dto.Replies = append(dto.Replies, NewCommentDTO(rp))
+	}
+	return
+}
+
+// access checks the profile can read the task, responding with the error otherwise.
+func (c *Comment) access(w http.ResponseWriter, r *http.Request, profileId string, taskId string, action string) (ok bool) {
+	_, err := c.tasks.WithContext(r.Context()).Get(profileId, taskId)
+	if err != nil {
+		switch {
+			case errors.Is(err, task.ErrStorageNotFound):
+				response.Err(w, http.StatusNotFound, "failed to " + action + ": task not found")
+			default:
+				response.Err(w, http.StatusInternalServerError, "internal error")
+		}
+		logger.Errors(r, err)
+
+		return
+	}
+
+	ok = true
+	return
+}
+
+// List returns the threads of a task, sorted by creation time.
+func (c *Comment) List() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// param id
+		taskId := chi.URLParam(r, "id")
+
+		// process
+		if !c.access(w, r, profileId, taskId, "list comments") {
+			return
+		}
+		ths, err := c.comments.List(taskId)
+		if err != nil {
+			response.Err(w, http.StatusInternalServerError, "internal error")
+			logger.Errors(r, err)
+			return
+		}
+
+		// response
+		data := make([]ThreadDTO, 0, len(ths))
+		for _, th := range ths {
+			data = append(data, NewThreadDTO(th))
+		}
+		response.Ok(w, http.StatusOK, "succeed to list comments", data)
+	}
+}
+
+// Create comments on a task, or replies to a top level comment with parent_id.
+func (c *Comment) Create() http.HandlerFunc {
+	type request struct {
+		ParentID optional.Option[string] `json:"parent_id"`
+		Body 	 optional.Option[string] `json:"body"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// param id
+		taskId := chi.URLParam(r, "id")
+
+		// request
+		var req request
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			response.Err(w, http.StatusBadRequest, "failed to create comment: invalid request")
+			logger.Errors(r, err)
+			return
+		}
+
+		// process
+		if !c.access(w, r, profileId, taskId, "create comment") {
+			return
+		}
+		cm := &comment.Comment{
+			ID: 	  optional.None[string](),
+			TaskID:   optional.Some(taskId),
+			AuthorID: optional.Some(profileId),
+			ParentID: req.ParentID,
+			Body: 	  req.Body,
+		}
+		err = c.comments.Save(cm)
+		if err != nil {
+			switch {
+				case errors.Is(err, comment.ErrStorageInvalid):
+					response.Err(w, http.StatusUnprocessableEntity, "failed to create comment: invalid comment")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		response.Ok(w, http.StatusCreated, "succeed to create comment", NewCommentDTO(cm))
+	}
+}
+
+// Update replaces the body of a comment (only its author can).
+func (c *Comment) Update() http.HandlerFunc {
+	type request struct {
+		Body optional.Option[string] `json:"body"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// param id and comment id
+		taskId := chi.URLParam(r, "id")
+		id := chi.URLParam(r, "comment_id")
+
+		// request
+		var req request
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			response.Err(w, http.StatusBadRequest, "failed to update comment: invalid request")
+			logger.Errors(r, err)
+			return
+		}
+
+		// process
+		if !c.access(w, r, profileId, taskId, "update comment") {
+			return
+		}
+		cm := &comment.Comment{
+			ID: 	optional.Some(id),
+			TaskID: optional.Some(taskId),
+			Body: 	req.Body,
+		}
+		err = c.comments.Update(profileId, cm)
+		if err != nil {
+			switch {
+				case errors.Is(err, comment.ErrStorageNotFound):
+					response.Err(w, http.StatusNotFound, "failed to update comment: not found")
+				case errors.Is(err, comment.ErrStorageForbidden):
+					response.Err(w, http.StatusForbidden, "failed to update comment: forbidden")
+				case errors.Is(err, comment.ErrStorageInvalid):
+					response.Err(w, http.StatusUnprocessableEntity, "failed to update comment: invalid comment")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		response.Ok(w, http.StatusOK, "succeed to update comment", NewCommentDTO(cm))
+	}
+}
+
+// Delete removes a comment with its replies (only its author can).
+func (c *Comment) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// param id and comment id
+		taskId := chi.URLParam(r, "id")
+		id := chi.URLParam(r, "comment_id")
+
+		// process
+		if !c.access(w, r, profileId, taskId, "delete comment") {
+			return
+		}
+		err := c.comments.Delete(profileId, taskId, id)
+		if err != nil {
+			switch {
+				case errors.Is(err, comment.ErrStorageNotFound):
+					response.Err(w, http.StatusNotFound, "failed to delete comment: not found")
+				case errors.Is(err, comment.ErrStorageForbidden):
+					response.Err(w, http.StatusForbidden, "failed to delete comment: forbidden")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		response.Ok(w, http.StatusOK, "succeed to delete comment", nil)
+	}
+}
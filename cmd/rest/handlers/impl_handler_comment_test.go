@@ -0,0 +1,420 @@
+package handlers
+
+import (
+	"api/internal/comment"
+	"api/internal/profiles/contexter"
+	"api/internal/task"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LNMMusic/optional"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Tests
+func TestHandlerComment_List(t *testing.T) {
+	createdAt := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type input struct {id string}
+	type output struct {status int; body string}
+	type testCase struct {
+		title		string
+		input		input
+		output		output
+		setTasks	func(mk *task.StorageMock)
+		setComments func(mk *comment.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "List the threads of a task",
+			input: input{id: "1"},
+			output: output{
+				status: http.StatusOK,
+				body: `{
+					"message": "succeed to list comments",
+					"data": [
+						{
+							"id": "c1", "task_id": "1", "author_id": "p1", "parent_id": null, "body": "first",
+							"created_at": "2023-01-01T00:00:00Z", "updated_at": "2023-01-01T00:00:00Z",
+							"replies": [
+								{
+									"id": "c2", "task_id": "1", "author_id": "p2", "parent_id": "c1", "body": "reply",
+									"created_at": "2023-01-01T00:00:00Z", "updated_at": "2023-01-01T00:00:00Z"
+								}
+							]
+						}
+					]
+				}`,
+			},
+			setTasks: func(mk *task.StorageMock) {
+				mk.On("Get", "p1", "1").Return(&task.Task{ID: optional.Some("1")}, nil)
+			},
+			setComments: func(mk *comment.StorageMock) {
+				mk.On("List", "1").Return([]*comment.Thread{
+					{
+						Comment: &comment.Comment{ID: optional.Some("c1"), TaskID: optional.Some("1"), AuthorID: optional.Some("p1"), Body: optional.Some("first"), CreatedAt: optional.Some(createdAt), UpdatedAt: optional.Some(createdAt)},
+						Replies: []*comment.Comment{{ID: optional.Some("c2"), TaskID: optional.Some("1"), AuthorID: optional.Some("p2"), ParentID: optional.Some("c1"), Body: optional.Some("reply"), CreatedAt: optional.Some(createdAt), UpdatedAt: optional.Some(createdAt)}},
+					},
+				}, nil)
+			},
+		},
+
+		// failed cases
+		{
+			title: "Failed to list the comments of a task: task not found",
+			input: input{id: "1"},
+			output: output{
+				status: http.StatusNotFound,
+				body: `{"data": null, "message": "failed to list comments: task not found"}`,
+			},
+			setTasks: func(mk *task.StorageMock) {
+				mk.On("Get", "p1", "1").Return((*task.Task)(nil), task.ErrStorageNotFound)
+			},
+			setComments: func(mk *comment.StorageMock) {},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			ts := task.NewStorageMock()
+			c.setTasks(ts)
+			cs := comment.NewStorageMock()
+			c.setComments(cs)
+
+			cl := NewCommentController(ts, cs)
+			hd := cl.List()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/tasks/"+c.input.id+"/comments", nil)
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("id", c.input.id)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chiCtx))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			ts.AssertExpectations(t)
+			cs.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerComment_Create(t *testing.T) {
+	createdAt := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type input struct {id string; body string}
+	type output struct {status int; body string}
+	type testCase struct {
+		title		string
+		input		input
+		output		output
+		setTasks	func(mk *task.StorageMock)
+		setComments func(mk *comment.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "Reply to a comment",
+			input: input{id: "1", body: `{"parent_id": "c1", "body": "reply"}`},
+			output: output{
+				status: http.StatusCreated,
+				body: `{
+					"message": "succeed to create comment",
+					"data": {
+						"id": "c2", "task_id": "1", "author_id": "p1", "parent_id": "c1", "body": "reply",
+						"created_at": "2023-01-01T00:00:00Z", "updated_at": "2023-01-01T00:00:00Z"
+					}
+				}`,
+			},
+			setTasks: func(mk *task.StorageMock) {
+				mk.On("Get", "p1", "1").Return(&task.Task{ID: optional.Some("1")}, nil)
+			},
+			setComments: func(mk *comment.StorageMock) {
+				mk.On("Save", &comment.Comment{TaskID: optional.Some("1"), AuthorID: optional.Some("p1"), ParentID: optional.Some("c1"), Body: optional.Some("reply")}).Return(nil)
+				mk.SetComment = func(c *comment.Comment) {
+					c.ID = optional.Some("c2")
+					c.CreatedAt = optional.Some(createdAt)
+					c.UpdatedAt = optional.Some(createdAt)
+				}
+			},
+		},
+
+		// failed cases
+		{
+			title: "Failed to create a comment: invalid request",
+			input: input{id: "1", body: `invalid`},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{"data": null, "message": "failed to create comment: invalid request"}`,
+			},
+			setTasks: func(mk *task.StorageMock) {},
+			setComments: func(mk *comment.StorageMock) {},
+		},
+		{
+			title: "Failed to create a comment: task not found",
+			input: input{id: "1", body: `{"body": "body"}`},
+			output: output{
+				status: http.StatusNotFound,
+				body: `{"data": null, "message": "failed to create comment: task not found"}`,
+			},
+			setTasks: func(mk *task.StorageMock) {
+				mk.On("Get", "p1", "1").Return((*task.Task)(nil), task.ErrStorageNotFound)
+			},
+			setComments: func(mk *comment.StorageMock) {},
+		},
+		{
+			title: "Failed to create a comment: invalid comment",
+			input: input{id: "1", body: `{"body": ""}`},
+			output: output{
+				status: http.StatusUnprocessableEntity,
+				body: `{"data": null, "message": "failed to create comment: invalid comment"}`,
+			},
+			setTasks: func(mk *task.StorageMock) {
+				mk.On("Get", "p1", "1").Return(&task.Task{ID: optional.Some("1")}, nil)
+			},
+			setComments: func(mk *comment.StorageMock) {
+				mk.On("Save", mock.Anything).Return(comment.ErrStorageInvalid)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			ts := task.NewStorageMock()
+			c.setTasks(ts)
+			cs := comment.NewStorageMock()
+			c.setComments(cs)
+
+			cl := NewCommentController(ts, cs)
+			hd := cl.Create()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/tasks/"+c.input.id+"/comments", strings.NewReader(c.input.body))
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("id", c.input.id)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chiCtx))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			ts.AssertExpectations(t)
+			cs.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerComment_Update(t *testing.T) {
+	createdAt := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	updatedAt := createdAt.Add(time.Hour)
+
+	type input struct {id string; commentId string; body string}
+	type output struct {status int; body string}
+	type testCase struct {
+		title		string
+		input		input
+		output		output
+		setTasks	func(mk *task.StorageMock)
+		setComments func(mk *comment.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "Update a comment",
+			input: input{id: "1", commentId: "c1", body: `{"body": "edited"}`},
+			output: output{
+				status: http.StatusOK,
+				body: `{
+					"message": "succeed to update comment",
+					"data": {
+						"id": "c1", "task_id": "1", "author_id": "p1", "parent_id": null, "body": "edited",
+						"created_at": "2023-01-01T00:00:00Z", "updated_at": "2023-01-01T01:00:00Z"
+					}
+				}`,
+			},
+			setTasks: func(mk *task.StorageMock) {
+				mk.On("Get", "p1", "1").Return(&task.Task{ID: optional.Some("1")}, nil)
+			},
+			setComments: func(mk *comment.StorageMock) {
+				mk.On("Update", "p1", &comment.Comment{ID: optional.Some("c1"), TaskID: optional.Some("1"), Body: optional.Some("edited")}).Return(nil)
+				mk.SetComment = func(c *comment.Comment) {
+					c.AuthorID = optional.Some("p1")
+					c.CreatedAt = optional.Some(createdAt)
+					c.UpdatedAt = optional.Some(updatedAt)
+				}
+			},
+		},
+
+		// failed cases
+		{
+			title: "Failed to update a comment: forbidden",
+			input: input{id: "1", commentId: "c1", body: `{"body": "edited"}`},
+			output: output{
+				status: http.StatusForbidden,
+				body: `{"data": null, "message": "failed to update comment: forbidden"}`,
+			},
+			setTasks: func(mk *task.StorageMock) {
+				mk.On("Get", "p1", "1").Return(&task.Task{ID: optional.Some("1")}, nil)
+			},
+			setComments: func(mk *comment.StorageMock) {
+				mk.On("Update", "p1", mock.Anything).Return(comment.ErrStorageForbidden)
+			},
+		},
+		{
+			title: "Failed to update a comment: not found",
+			input: input{id: "1", commentId: "c1", body: `{"body": "edited"}`},
+			output: output{
+				status: http.StatusNotFound,
+				body: `{"data": null, "message": "failed to update comment: not found"}`,
+			},
+			setTasks: func(mk *task.StorageMock) {
+				mk.On("Get", "p1", "1").Return(&task.Task{ID: optional.Some("1")}, nil)
+			},
+			setComments: func(mk *comment.StorageMock) {
+				mk.On("Update", "p1", mock.Anything).Return(comment.ErrStorageNotFound)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			ts := task.NewStorageMock()
+			c.setTasks(ts)
+			cs := comment.NewStorageMock()
+			c.setComments(cs)
+
+			cl := NewCommentController(ts, cs)
+			hd := cl.Update()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPut, "/tasks/"+c.input.id+"/comments/"+c.input.commentId, strings.NewReader(c.input.body))
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("id", c.input.id)
+			chiCtx.URLParams.Add("comment_id", c.input.commentId)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chiCtx))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			ts.AssertExpectations(t)
+			cs.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerComment_Delete(t *testing.T) {
+	type input struct {id string; commentId string}
+	type output struct {status int; body string}
+	type testCase struct {
+		title		string
+		input		input
+		output		output
+		setTasks	func(mk *task.StorageMock)
+		setComments func(mk *comment.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "Delete a comment",
+			input: input{id: "1", commentId: "c1"},
+			output: output{
+				status: http.StatusOK,
+				body: `{"data": null, "message": "succeed to delete comment"}`,
+			},
+			setTasks: func(mk *task.StorageMock) {
+				mk.On("Get", "p1", "1").Return(&task.Task{ID: optional.Some("1")}, nil)
+			},
+			setComments: func(mk *comment.StorageMock) {
+				mk.On("Delete", "p1", "1", "c1").Return(nil)
+			},
+		},
+
+		// failed cases
+		{
+			title: "Failed to delete a comment: forbidden",
+			input: input{id: "1", commentId: "c1"},
+			output: output{
+				status: http.StatusForbidden,
+				body: `{"data": null, "message": "failed to delete comment: forbidden"}`,
+			},
+			setTasks: func(mk *task.StorageMock) {
+				mk.On("Get", "p1", "1").Return(&task.Task{ID: optional.Some("1")}, nil)
+			},
+			setComments: func(mk *comment.StorageMock) {
+				mk.On("Delete", "p1", "1", "c1").Return(comment.ErrStorageForbidden)
+			},
+		},
+		{
+			title: "Failed to delete a comment: task not found",
+			input: input{id: "1", commentId: "c1"},
+			output: output{
+				status: http.StatusNotFound,
+				body: `{"data": null, "message": "failed to delete comment: task not found"}`,
+			},
+			setTasks: func(mk *task.StorageMock) {
+				mk.On("Get", "p1", "1").Return((*task.Task)(nil), task.ErrStorageNotFound)
+			},
+			setComments: func(mk *comment.StorageMock) {},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			ts := task.NewStorageMock()
+			c.setTasks(ts)
+			cs := comment.NewStorageMock()
+			c.setComments(cs)
+
+			cl := NewCommentController(ts, cs)
+			hd := cl.Delete()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodDelete, "/tasks/"+c.input.id+"/comments/"+c.input.commentId, nil)
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("id", c.input.id)
+			chiCtx.URLParams.Add("comment_id", c.input.commentId)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chiCtx))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			ts.AssertExpectations(t)
+			cs.AssertExpectations(t)
+		})
+	}
+}
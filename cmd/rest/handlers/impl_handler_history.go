@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"api/cmd/rest/middlewares/logger"
+	"api/cmd/rest/response"
+	"api/internal/profiles/contexter"
+	"api/internal/task"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func NewHistoryController(tasks task.Storage, history task.History) *History {
+	return &History{tasks: tasks, history: history}
+}
+
+// History is an implementation of the task history controller.
+// - the history of a task is visible to the profiles that can read it (its owner and the ones it is shared with)
+type History struct {
+	// tasks is the storage used to check the access to the task
+	tasks task.Storage
+	// history
+	history task.History
+}
+
+// ChangeDTO is the representation of the change of a field in the responses.
+type ChangeDTO struct {
+	Field string 		  `json:"field"`
+	From  json.RawMessage `json:"from"`
+	To 	  json.RawMessage `json:"to"`
+}
+
+// EntryDTO is the representation of a history entry in the responses.
+type EntryDTO struct {
+	ProfileID string	  `json:"profile_id"`
+	Action	  task.Action `json:"action"`
+	Changes	  []ChangeDTO `json:"changes"`
+	At		  time.Time	  `json:"at"`
+}
+
+// NewEntryDTO returns the representation of the given entry.
+func NewEntryDTO(e *task.Entry) (dto EntryDTO) {
+	dto = EntryDTO{ProfileID: e.ProfileID, Action: e.Action, Changes: make([]ChangeDTO, 0, len(e.Changes)), At: e.At}
+	for _, c := range e.Changes {
+		dto.Changes = append(dto.Changes, ChangeDTO{Field: c.Field, From: c.From, To: c.To})
+	}
+	return
+}
+
+// List returns the history of a task, from the oldest change.
+func (h *History) List() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// param id
+		id := chi.URLParam(r, "id")
+
+		// process
+		_, err := h.tasks.WithContext(r.Context()).Get(profileId, id)
+		if err != nil {
+			switch {
+				case errors.Is(err, task.ErrStorageNotFound):
+					response.Err(w, http.StatusNotFound, "failed to get history: not found")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+		es, err := h.history.WithContext(r.Context()).List(id)
+		if err != nil {
+			response.Err(w, http.StatusInternalServerError, "internal error")
+			logger.Errors(r, err)
+			return
+		}
+
+		// response
+		data := make([]EntryDTO, 0, len(es))
+		for _, e := range es {
+			data = append(data, NewEntryDTO(e))
+		}
+		response.Ok(w, http.StatusOK, "succeed to get history", data)
+	}
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"api/internal/profiles/contexter"
+	"api/internal/task"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/LNMMusic/optional"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests
+func TestHandlerHistory_List(t *testing.T) {
+	at := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type input struct {id string}
+	type output struct {status int; body string}
+	type testCase struct {
+		title	   string
+		input	   input
+		output	   output
+		setTasks   func(mk *task.StorageMock)
+		setHistory func(mk *task.HistoryMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "Get the history of a task",
+			input: input{id: "1"},
+			output: output{
+				status: http.StatusOK,
+				body: `{
+					"message": "succeed to get history",
+					"data": [
+						{
+							"profile_id": "p1", "action": "create", "at": "2023-01-01T00:00:00Z",
+							"changes": [{"field": "title", "from": null, "to": "title"}]
+						},
+						{
+							"profile_id": "p2", "action": "update", "at": "2023-01-01T00:00:00Z",
+							"changes": [{"field": "status", "from": "done", "to": "todo"}]
+						}
+					]
+				}`,
+			},
+			setTasks: func(mk *task.StorageMock) {
+				mk.On("Get", "p1", "1").Return(&task.Task{ID: optional.Some("1")}, nil)
+			},
+			setHistory: func(mk *task.HistoryMock) {
+				mk.On("List", "1").Return([]*task.Entry{
+					{TaskID: "1", ProfileID: "p1", Action: task.ActionCreate, Changes: []task.Change{{Field: "title", From: json.RawMessage(`null`), To: json.RawMessage(`"title"`)}}, At: at},
+					{TaskID: "1", ProfileID: "p2", Action: task.ActionUpdate, Changes: []task.Change{{Field: "status", From: json.RawMessage(`"done"`), To: json.RawMessage(`"todo"`)}}, At: at},
+				}, nil)
+			},
+		},
+
+		// failed cases
+		{
+			title: "Failed to get the history of a task: not found",
+			input: input{id: "1"},
+			output: output{
+				status: http.StatusNotFound,
+				body: `{"data": null, "message": "failed to get history: not found"}`,
+			},
+			setTasks: func(mk *task.StorageMock) {
+				mk.On("Get", "p1", "1").Return((*task.Task)(nil), task.ErrStorageNotFound)
+			},
+			setHistory: func(mk *task.HistoryMock) {},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			ts := task.NewStorageMock()
+			c.setTasks(ts)
+			hs := task.NewHistoryMock()
+			c.setHistory(hs)
+
+			cl := NewHistoryController(ts, hs)
+			hd := cl.List()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/tasks/"+c.input.id+"/history", nil)
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("id", c.input.id)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chiCtx))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			ts.AssertExpectations(t)
+			hs.AssertExpectations(t)
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"api/internal/profiles/storage"
 	"api/pkg/uuidgenerator"
 	"api/pkg/web"
+	"encoding/json"
 	"errors"
 	"net/http"
 
@@ -43,7 +44,7 @@ func (ct *ProfileController) GetProfileById() http.HandlerFunc {
 		id := r.Context().Value(contexter.KeyProfileId).(string)
 
 		// process
-		pf, err := ct.st.GetProfileById(id)
+		pf, err := ct.st.WithContext(r.Context()).GetProfileById(id)
 		if err != nil {
 			var code int; var body *ResponseGetProfileByID
 
@@ -69,6 +70,7 @@ func (ct *ProfileController) GetProfileById() http.HandlerFunc {
 		}
 
 		// response
+		etag(w, pf.Version)
 		code := http.StatusOK
 		body := &ResponseGetProfileByID{
 			Message: "Success",
@@ -104,7 +106,7 @@ func (ct *ProfileController) ActivateProfile() http.HandlerFunc {
 		pf.ID = optional.Some(ct.uuid.UUID())
 		pf.UserID = optional.Some(userId)
 
-		err := ct.st.ActivateProfile(pf)
+		err := ct.st.WithContext(r.Context()).ActivateProfile(pf)
 		if err != nil {
 			var code int; var body *ResponseActivateProfile
 
@@ -137,6 +139,7 @@ func (ct *ProfileController) ActivateProfile() http.HandlerFunc {
 		}
 
 		// response
+		etag(w, pf.Version)
 		code := http.StatusOK
 		body := &ResponseActivateProfile{
 			Message: "Success",
@@ -144,6 +147,133 @@ func (ct *ProfileController) ActivateProfile() http.HandlerFunc {
 			Error:   false,
 		}
 
+		web.JSON(w, code, body)
+	}
+}
+
+// UpdateProfile updates the name, email, phone and address of a profile
+// - the update is conditional on the version of the profile in the If-Match header ("*" for the current one)
+type RequestUpdateProfile struct {
+	Name   optional.Option[string] `json:"name"`
+	Email  optional.Option[string] `json:"email"`
+	Phone  optional.Option[string] `json:"phone"`
+	Address optional.Option[string] `json:"address"`
+}
+type ResponseUpdateProfile struct {
+	Message string		`json:"message"`
+	Data    *ProfileDTO `json:"data"`
+	Error	bool		`json:"error"`
+}
+func (ct *ProfileController) UpdateProfile() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id := r.Context().Value(contexter.KeyProfileId).(string)
+		// -> version
+		version, err := ifMatch(r)
+		if err != nil {
+			var code int; var body *ResponseUpdateProfile
+
+			switch {
+			case errors.Is(err, errIfMatchRequired):
+				code = http.StatusPreconditionRequired
+				body = &ResponseUpdateProfile{
+					Message: "Precondition required",
+					Data:    nil,
+					Error:   true,
+				}
+			default:
+				code = http.StatusBadRequest
+				body = &ResponseUpdateProfile{
+					Message: "Invalid precondition",
+					Data:    nil,
+					Error:   true,
+				}
+			}
+
+			web.JSON(w, code, body)
+			return
+		}
+		// -> body
+		var req RequestUpdateProfile
+		err = json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			code := http.StatusBadRequest
+			body := &ResponseUpdateProfile{
+				Message: "Invalid request",
+				Data:    nil,
+				Error:   true,
+			}
+
+			web.JSON(w, code, body)
+			return
+		}
+
+		// process
+		// -> the user of the profile is kept
+		pf, err := ct.st.WithContext(r.Context()).GetProfileById(id)
+		if err == nil {
+			pf.Name = req.Name
+			pf.Email = req.Email
+			pf.Phone = req.Phone
+			pf.Address = req.Address
+			if version.IsSome() {
+				pf.Version = version
+			}
+			err = ct.st.WithContext(r.Context()).UpdateProfile(pf)
+		}
+		if err != nil {
+			var code int; var body *ResponseUpdateProfile
+
+			switch {
+			case errors.Is(err, storage.ErrStorageNotFound):
+				code = http.StatusNotFound
+				body = &ResponseUpdateProfile{
+					Message: "Profile not found",
+					Data:    nil,
+					Error:   true,
+				}
+			case errors.Is(err, storage.ErrStorageVersionMismatch):
+				code = http.StatusPreconditionFailed
+				body = &ResponseUpdateProfile{
+					Message: "Profile version mismatch",
+					Data:    nil,
+					Error:   true,
+				}
+			case errors.Is(err, storage.ErrStorageInvalidProfile):
+				code = http.StatusUnprocessableEntity
+				body = &ResponseUpdateProfile{
+					Message: "Invalid profile",
+					Data:    nil,
+					Error:   true,
+				}
+			default:
+				code = http.StatusInternalServerError
+				body = &ResponseUpdateProfile{
+					Message: "Internal server error",
+					Data:    nil,
+					Error:   true,
+				}
+			}
+
+			web.JSON(w, code, body)
+			return
+		}
+
+		// response
+		etag(w, pf.Version)
+		code := http.StatusOK
+		body := &ResponseUpdateProfile{
+			Message: "Success",
+			Data: &ProfileDTO{
+				UserID: pf.UserID,
+				Name:   pf.Name,
+				Email:  pf.Email,
+				Phone:  pf.Phone,
+				Address: pf.Address,
+			},
+			Error: false,
+		}
+
 		web.JSON(w, code, body)
 	}
 }
\ No newline at end of file
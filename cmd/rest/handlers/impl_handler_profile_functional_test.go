@@ -57,6 +57,7 @@ func TestFunctionalProfileController_GetProfileByUserId(t *testing.T) {
 				body: `{"message":"Success","data":{"user_id":"1","name":"John Doe","email":"johndoe@gmail.com", "phone":"111122223", "address":"Jl. Raya Bogor"}, "error":false}`,
 				headers: http.Header{
 					"Content-Type": []string{"application/json"},
+					"Etag": []string{`"1"`},
 				},
 			},
 			setUpDatabase: func(mk sqlmock.Sqlmock) {
@@ -64,9 +65,9 @@ func TestFunctionalProfileController_GetProfileByUserId(t *testing.T) {
 				mk.ExpectBegin()
 
 				// query
-				query := "SELECT id, user_id, name, email, phone, address FROM profiles WHERE id = ?" 
+				query := "SELECT id, user_id, name, email, phone, address, version FROM profiles WHERE id = ?" 
 
-				cols := []string{"id", "user_id", "name", "email", "phone", "address"}
+				cols := []string{"id", "user_id", "name", "email", "phone", "address", "version"}
 				rows := sqlmock.NewRows(cols)
 				rows.AddRow(
 					sql.NullString{String: "1", Valid: true},
@@ -75,6 +76,7 @@ func TestFunctionalProfileController_GetProfileByUserId(t *testing.T) {
 					sql.NullString{String: "johndoe@gmail.com", Valid: true},
 					sql.NullString{String: "111122223", Valid: true},
 					sql.NullString{String: "Jl. Raya Bogor", Valid: true},
+					sql.NullInt64{Int64: 1, Valid: true},
 				)
 
 				mk.ExpectPrepare(regexp.QuoteMeta(query)).ExpectQuery().WithArgs("1").WillReturnRows(rows)
@@ -105,6 +107,7 @@ func TestFunctionalProfileController_GetProfileByUserId(t *testing.T) {
 				body: `{"message":"Success","data":{"user_id":"1","name":null,"email":null, "phone":null, "address":null}, "error":false}`,
 				headers: http.Header{
 					"Content-Type": []string{"application/json"},
+					"Etag": []string{`"1"`},
 				},
 			},
 			setUpDatabase: func(mk sqlmock.Sqlmock) {
@@ -112,9 +115,9 @@ func TestFunctionalProfileController_GetProfileByUserId(t *testing.T) {
 				mk.ExpectBegin()
 
 				// query
-				query := "SELECT id, user_id, name, email, phone, address FROM profiles WHERE id = ?" 
+				query := "SELECT id, user_id, name, email, phone, address, version FROM profiles WHERE id = ?" 
 
-				cols := []string{"id", "user_id", "name", "email", "phone", "address"}
+				cols := []string{"id", "user_id", "name", "email", "phone", "address", "version"}
 				rows := sqlmock.NewRows(cols)
 				rows.AddRow(
 					sql.NullString{String: "1", Valid: true},
@@ -123,6 +126,7 @@ func TestFunctionalProfileController_GetProfileByUserId(t *testing.T) {
 					sql.NullString{String: "", Valid: false},
 					sql.NullString{String: "", Valid: false},
 					sql.NullString{String: "", Valid: false},
+					sql.NullInt64{Int64: 1, Valid: true},
 				)
 
 				mk.ExpectPrepare(regexp.QuoteMeta(query)).ExpectQuery().WithArgs("1").WillReturnRows(rows)
@@ -161,7 +165,7 @@ func TestFunctionalProfileController_GetProfileByUserId(t *testing.T) {
 				mk.ExpectBegin()
 
 				// query
-				query := "SELECT id, user_id, name, email, phone, address FROM profiles WHERE id = ?" 
+				query := "SELECT id, user_id, name, email, phone, address, version FROM profiles WHERE id = ?" 
 
 				mk.ExpectPrepare(regexp.QuoteMeta(query)).ExpectQuery().WithArgs("1").WillReturnError(sql.ErrNoRows)
 
@@ -198,7 +202,7 @@ func TestFunctionalProfileController_GetProfileByUserId(t *testing.T) {
 				mk.ExpectBegin()
 
 				// query
-				query := "SELECT id, user_id, name, email, phone, address FROM profiles WHERE id = ?" 
+				query := "SELECT id, user_id, name, email, phone, address, version FROM profiles WHERE id = ?" 
 
 				mk.ExpectPrepare(regexp.QuoteMeta(query)).ExpectQuery().WithArgs("1").WillReturnError(sql.ErrConnDone)
 
@@ -279,6 +283,7 @@ func TestFunctionalProfileController_ActivateProfile(t *testing.T) {
 				body: `{"message":"Success","data":null,"error":false}`,
 				headers: http.Header{
 					"Content-Type": []string{"application/json"},
+					"Etag": []string{`"1"`},
 				},
 			},
 			setUpDatabase: func(mk sqlmock.Sqlmock) {
@@ -8,6 +8,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/LNMMusic/optional"
@@ -297,4 +298,145 @@ func TestProfileController_ActivateProfile(t *testing.T) {
 			uuid.AssertExpectations(t)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestProfileController_UpdateProfile(t *testing.T) {
+	type input struct { w *httptest.ResponseRecorder; r *http.Request; setR func (r *http.Request) }
+	type output struct { code int; body string; headers http.Header }
+	type testCase struct {
+		name string
+		input input
+		output output
+		// set-up
+		setUpStorage func(mk *storage.ImplProfilesStorageMock)
+	}
+
+	// request
+	newRequest := func(ifMatch string) (r *http.Request) {
+		r = httptest.NewRequest(http.MethodPut, "/profiles", strings.NewReader(`{"name":"name","email":"email"}`))
+		if ifMatch != "" {
+			r.Header.Set("If-Match", ifMatch)
+		}
+		return
+	}
+	setR := func (r *http.Request) {
+		// set-up request context
+		(*r) = *r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "id"))
+	}
+
+	cases := []testCase{
+		// valid case
+		{
+			name: "valid case",
+			input: input{w: httptest.NewRecorder(), r: newRequest(`"2"`), setR: setR},
+			output: output{
+				code: http.StatusOK,
+				body: `{"message":"Success","data":{"user_id":"user_id","name":"name","email":"email","phone":null,"address":null},"error":false}`,
+				headers: http.Header{
+					"Content-Type": {"application/json"},
+					"Etag": {`"2"`},
+				},
+			},
+			setUpStorage: func(mk *storage.ImplProfilesStorageMock) {
+				mk.
+					On("GetProfileById", "id").
+					Return(&profiles.Profile{
+						ID:      optional.Some("id"),
+						UserID:  optional.Some("user_id"),
+						Phone:   optional.Some("phone"),
+						Version: optional.Some(2),
+					}, nil)
+				mk.
+					On("UpdateProfile", &profiles.Profile{
+						ID:      optional.Some("id"),
+						UserID:  optional.Some("user_id"),
+						Name:    optional.Some("name"),
+						Email:   optional.Some("email"),
+						Version: optional.Some(2),
+					}).
+					Return(nil)
+			},
+		},
+
+		// invalid case: precondition required
+		{
+			name: "invalid case: precondition required",
+			input: input{w: httptest.NewRecorder(), r: newRequest(""), setR: setR},
+			output: output{
+				code: http.StatusPreconditionRequired,
+				body: `{"message":"Precondition required","data":null,"error":true}`,
+				headers: http.Header{
+					"Content-Type": {"application/json"},
+				},
+			},
+			setUpStorage: func(mk *storage.ImplProfilesStorageMock) {},
+		},
+		// invalid case: storage error - version mismatch
+		{
+			name: "invalid case: storage error - version mismatch",
+			input: input{w: httptest.NewRecorder(), r: newRequest(`"1"`), setR: setR},
+			output: output{
+				code: http.StatusPreconditionFailed,
+				body: `{"message":"Profile version mismatch","data":null,"error":true}`,
+				headers: http.Header{
+					"Content-Type": {"application/json"},
+				},
+			},
+			setUpStorage: func(mk *storage.ImplProfilesStorageMock) {
+				mk.
+					On("GetProfileById", "id").
+					Return(&profiles.Profile{ID: optional.Some("id"), Version: optional.Some(2)}, nil)
+				mk.
+					On("UpdateProfile", &profiles.Profile{
+						ID:      optional.Some("id"),
+						Name:    optional.Some("name"),
+						Email:   optional.Some("email"),
+						Version: optional.Some(1),
+					}).
+					Return(storage.ErrStorageVersionMismatch)
+			},
+		},
+		// invalid case: storage error - not found
+		{
+			name: "invalid case: storage error - not found",
+			input: input{w: httptest.NewRecorder(), r: newRequest("*"), setR: setR},
+			output: output{
+				code: http.StatusNotFound,
+				body: `{"message":"Profile not found","data":null,"error":true}`,
+				headers: http.Header{
+					"Content-Type": {"application/json"},
+				},
+			},
+			setUpStorage: func(mk *storage.ImplProfilesStorageMock) {
+				mk.
+					On("GetProfileById", "id").
+					Return(&profiles.Profile{}, storage.ErrStorageNotFound)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			st := storage.NewImplProfilesStorageMock()
+			c.setUpStorage(st)
+
+			uuid := uuidgenerator.NewUUIDGeneratorMock()
+
+			ct := NewProfileController(st, uuid)
+			hd := ct.UpdateProfile()
+
+			// act
+			c.input.setR(c.input.r)
+			hd(c.input.w, c.input.r)
+
+			// assert
+			assert.Equal(t, c.output.code, c.input.w.Code)
+			assert.JSONEq(t, c.output.body, c.input.w.Body.String())
+			assert.Equal(t, c.output.headers, c.input.w.Header())
+			// -> expectations
+			st.AssertExpectations(t)
+		})
+	}
+}
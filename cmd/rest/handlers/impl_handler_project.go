@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"api/cmd/rest/middlewares/logger"
+	"api/cmd/rest/response"
+	"api/internal/profiles/contexter"
+	"api/internal/project"
+	"api/internal/task"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/LNMMusic/optional"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func NewProjectController(projects project.Storage, tasks task.Storage) *Project {
+	return &Project{projects: projects, tasks: tasks}
+}
+
+// Project is an implementation of the project controller.
+// - projects are scoped to the profile that owns them, as their tasks are
+type Project struct {
+	// projects
+	projects project.Storage
+	// tasks is the storage used to list the tasks of a project
+	tasks task.Storage
+}
+
+// ProjectDTO is the representation of a project in the responses.
+type ProjectDTO struct {
+	ID			optional.Option[string]	`json:"id"`
+	OwnerID		optional.Option[string]	`json:"owner_id"`
+	Name		optional.Option[string]	`json:"name"`
+	Description	optional.Option[string]	`json:"description"`
+	CreatedAt	optional.Option[time.Time] `json:"created_at"`
+	UpdatedAt	optional.Option[time.Time] `json:"updated_at"`
+}
+
+// NewProjectDTO returns the representation of the given project.
+func NewProjectDTO(p *project.Project) (dto ProjectDTO) {
+	dto = ProjectDTO{
+		ID: 		 p.ID,
+		OwnerID: 	 p.OwnerID,
+		Name: 		 p.Name,
+		Description: p.Description,
+		CreatedAt: 	 p.CreatedAt,
+		UpdatedAt: 	 p.UpdatedAt,
+	}
+	return
+}
+
+// List returns the projects of the profile, sorted by name.
+func (p *Project) List() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// process
+		ps, err := p.projects.List(profileId)
+		if err != nil {
+			response.Err(w, http.StatusInternalServerError, "internal error")
+			logger.Errors(r, err)
+			return
+		}
+
+		// response
+		data := make([]ProjectDTO, 0, len(ps))
+		for _, pj := range ps {
+			data = append(data, NewProjectDTO(pj))
+		}
+		response.Ok(w, http.StatusOK, "succeed to list projects", data)
+	}
+}
+
+func (p *Project) Get() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// param id
+		id := chi.URLParam(r, "id")
+
+		// process
+		pj, err := p.projects.Get(profileId, id)
+		if err != nil {
+			switch {
+				case errors.Is(err, project.ErrStorageNotFound):
+					response.Err(w, http.StatusNotFound, "failed to get project: not found")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		response.Ok(w, http.StatusOK, "succeed to get project", NewProjectDTO(pj))
+	}
+}
+
+func (p *Project) Create() http.HandlerFunc {
+	type request struct {
+		Name 		optional.Option[string] `json:"name"`
+		Description optional.Option[string] `json:"description"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// request
+		var req request
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			response.Err(w, http.StatusBadRequest, "failed to create project: invalid request")
+			logger.Errors(r, err)
+			return
+		}
+
+		// process
+		pj := &project.Project{
+			ID: 		 optional.None[string](),
+			OwnerID: 	 optional.Some(profileId),
+			Name: 		 req.Name,
+			Description: req.Description,
+		}
+		err = p.projects.Save(pj)
+		if err != nil {
+			switch {
+				case errors.Is(err, project.ErrStorageInvalid):
+					response.Err(w, http.StatusUnprocessableEntity, "failed to create project: invalid project")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		response.Ok(w, http.StatusCreated, "succeed to create project", NewProjectDTO(pj))
+	}
+}
+
+// Update replaces the name and the description of a project.
+func (p *Project) Update() http.HandlerFunc {
+	type request struct {
+		Name 		optional.Option[string] `json:"name"`
+		Description optional.Option[string] `json:"description"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// param id
+		id := chi.URLParam(r, "id")
+
+		// request
+		var req request
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			response.Err(w, http.StatusBadRequest, "failed to update project: invalid request")
+			logger.Errors(r, err)
+			return
+		}
+
+		// process
+		pj := &project.Project{
+			ID: 		 optional.Some(id),
+			Name: 		 req.Name,
+			Description: req.Description,
+		}
+		err = p.projects.Update(profileId, pj)
+		if err != nil {
+			switch {
+				case errors.Is(err, project.ErrStorageNotFound):
+					response.Err(w, http.StatusNotFound, "failed to update project: not found")
+				case errors.Is(err, project.ErrStorageInvalid):
+					response.Err(w, http.StatusUnprocessableEntity, "failed to update project: invalid project")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		response.Ok(w, http.StatusOK, "succeed to update project", NewProjectDTO(pj))
+	}
+}
+
+// Delete removes a project, only if it has no tasks (in the trash neither): they must be moved out of it first.
+func (p *Project) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// param id
+		id := chi.URLParam(r, "id")
+
+		// process
+		empty, err := p.empty(r.Context(), profileId, id)
+		if err != nil {
+			response.Err(w, http.StatusInternalServerError, "internal error")
+			logger.Errors(r, err)
+			return
+		}
+		if !empty {
+			response.Err(w, http.StatusConflict, "failed to delete project: not empty")
+			logger.Errors(r, project.ErrStorageNotEmpty)
+			return
+		}
+		err = p.projects.Delete(profileId, id)
+		if err != nil {
+			switch {
+				case errors.Is(err, project.ErrStorageNotFound):
+					response.Err(w, http.StatusNotFound, "failed to delete project: not found")
+				case errors.Is(err, project.ErrStorageNotEmpty):
+					response.Err(w, http.StatusConflict, "failed to delete project: not empty")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		response.Ok(w, http.StatusOK, "succeed to delete project", nil)
+	}
+}
+
+// empty checks the project has no tasks, in the trash neither.
+func (p *Project) empty(ctx context.Context, profileId string, id string) (empty bool, err error) {
+	for _, deleted := range []bool{false, true} {
+		var pg *task.Page
+		pg, err = p.tasks.WithContext(ctx).List(profileId, &task.Query{
+			Filter: task.Condition{Field: task.FieldProjectID, Operator: task.OperatorEq, Value: id},
+			Size: 1,
+			Deleted: deleted,
+		})
+		if err != nil {
+			return
+		}
+		if len(pg.Tasks) > 0 {
+			return
+		}
+	}
+
+	empty = true
+	return
+}
+
+// Tasks lists the tasks of a project, with the same params as the list of tasks.
+func (p *Project) Tasks() http.HandlerFunc {
+	ct := NewTaskController(p.tasks)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// param id
+		id := chi.URLParam(r, "id")
+
+		// process
+		_, err := p.projects.Get(profileId, id)
+		if err != nil {
+			switch {
+				case errors.Is(err, project.ErrStorageNotFound):
+					response.Err(w, http.StatusNotFound, "failed to list tasks: project not found")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		ct.list(func(query *task.Query, params url.Values) (err error) {
+			cond := task.Condition{Field: task.FieldProjectID, Operator: task.OperatorEq, Value: id}
+			if query.Filter != nil {
+				query.Filter = task.And{Filters: []task.Filter{query.Filter, cond}}
+				return
+			}
+			query.Filter = cond
+			return
+		})(w, r)
+	}
+}
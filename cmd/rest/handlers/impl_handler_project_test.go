@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"api/internal/profiles/contexter"
+	"api/internal/project"
+	"api/internal/task"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LNMMusic/optional"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Tests
+func TestHandlerProject_Create(t *testing.T) {
+	createdAt := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type input struct {body string}
+	type output struct {status int; body string}
+	type testCase struct {
+		title		string
+		input		input
+		output		output
+		setProjects func(mk *project.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "Create a project",
+			input: input{body: `{"name": "inbox", "description": "description"}`},
+			output: output{
+				status: http.StatusCreated,
+				body: `{
+					"message": "succeed to create project",
+					"data": {
+						"id": "1", "owner_id": "p1", "name": "inbox", "description": "description",
+						"created_at": "2023-01-01T00:00:00Z", "updated_at": "2023-01-01T00:00:00Z"
+					}
+				}`,
+			},
+			setProjects: func(mk *project.StorageMock) {
+				mk.On("Save", &project.Project{ID: optional.None[string](), OwnerID: optional.Some("p1"), Name: optional.Some("inbox"), Description: optional.Some("description")}).Return(nil)
+				mk.SetProject = func(p *project.Project) {
+					p.ID = optional.Some("1")
+					p.CreatedAt = optional.Some(createdAt)
+					p.UpdatedAt = optional.Some(createdAt)
+				}
+			},
+		},
+
+		// failed cases
+		{
+			title: "Failed to create a project: invalid request",
+			input: input{body: `{"name": `},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{"data": null, "message": "failed to create project: invalid request"}`,
+			},
+			setProjects: func(mk *project.StorageMock) {},
+		},
+		{
+			title: "Failed to create a project: invalid project",
+			input: input{body: `{"description": "description"}`},
+			output: output{
+				status: http.StatusUnprocessableEntity,
+				body: `{"data": null, "message": "failed to create project: invalid project"}`,
+			},
+			setProjects: func(mk *project.StorageMock) {
+				mk.On("Save", mock.Anything).Return(project.ErrStorageInvalid)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			ps := project.NewStorageMock()
+			c.setProjects(ps)
+			ts := task.NewStorageMock()
+
+			cl := NewProjectController(ps, ts)
+			hd := cl.Create()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/projects", strings.NewReader(c.input.body))
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			ps.AssertExpectations(t)
+			ts.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerProject_Delete(t *testing.T) {
+	type input struct {id string}
+	type output struct {status int; body string}
+	type testCase struct {
+		title		string
+		input		input
+		output		output
+		setTasks	func(mk *task.StorageMock)
+		setProjects func(mk *project.StorageMock)
+	}
+
+	// queries of the tasks of the project
+	active := &task.Query{Filter: task.Condition{Field: task.FieldProjectID, Operator: task.OperatorEq, Value: "1"}, Size: 1}
+	trash := &task.Query{Filter: task.Condition{Field: task.FieldProjectID, Operator: task.OperatorEq, Value: "1"}, Size: 1, Deleted: true}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "Delete a project",
+			input: input{id: "1"},
+			output: output{
+				status: http.StatusOK,
+				body: `{"data": null, "message": "succeed to delete project"}`,
+			},
+			setTasks: func(mk *task.StorageMock) {
+				mk.On("List", "p1", active).Return(&task.Page{Tasks: []*task.Task{}}, nil)
+				mk.On("List", "p1", trash).Return(&task.Page{Tasks: []*task.Task{}}, nil)
+			},
+			setProjects: func(mk *project.StorageMock) {
+				mk.On("Delete", "p1", "1").Return(nil)
+			},
+		},
+
+		// failed cases
+		{
+			title: "Failed to delete a project: it has tasks",
+			input: input{id: "1"},
+			output: output{
+				status: http.StatusConflict,
+				body: `{"data": null, "message": "failed to delete project: not empty"}`,
+			},
+			setTasks: func(mk *task.StorageMock) {
+				mk.On("List", "p1", active).Return(&task.Page{Tasks: []*task.Task{{ID: optional.Some("t1")}}}, nil)
+			},
+			setProjects: func(mk *project.StorageMock) {},
+		},
+		{
+			title: "Failed to delete a project: it has tasks in the trash",
+			input: input{id: "1"},
+			output: output{
+				status: http.StatusConflict,
+				body: `{"data": null, "message": "failed to delete project: not empty"}`,
+			},
+			setTasks: func(mk *task.StorageMock) {
+				mk.On("List", "p1", active).Return(&task.Page{Tasks: []*task.Task{}}, nil)
+				mk.On("List", "p1", trash).Return(&task.Page{Tasks: []*task.Task{{ID: optional.Some("t1")}}}, nil)
+			},
+			setProjects: func(mk *project.StorageMock) {},
+		},
+		{
+			title: "Failed to delete a project: not found",
+			input: input{id: "1"},
+			output: output{
+				status: http.StatusNotFound,
+				body: `{"data": null, "message": "failed to delete project: not found"}`,
+			},
+			setTasks: func(mk *task.StorageMock) {
+				mk.On("List", "p1", active).Return(&task.Page{Tasks: []*task.Task{}}, nil)
+				mk.On("List", "p1", trash).Return(&task.Page{Tasks: []*task.Task{}}, nil)
+			},
+			setProjects: func(mk *project.StorageMock) {
+				mk.On("Delete", "p1", "1").Return(project.ErrStorageNotFound)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			ts := task.NewStorageMock()
+			c.setTasks(ts)
+			ps := project.NewStorageMock()
+			c.setProjects(ps)
+
+			cl := NewProjectController(ps, ts)
+			hd := cl.Delete()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodDelete, "/projects/"+c.input.id, nil)
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("id", c.input.id)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chiCtx))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			ts.AssertExpectations(t)
+			ps.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerProject_Tasks(t *testing.T) {
+	type input struct {id string; query string}
+	type output struct {status int; body string}
+	type testCase struct {
+		title		string
+		input		input
+		output		output
+		setTasks	func(mk *task.StorageMock)
+		setProjects func(mk *project.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "List the tasks of a project",
+			input: input{id: "1", query: "?size=10"},
+			output: output{
+				status: http.StatusOK,
+				body: `{
+					"message": "succeed to list tasks",
+					"data": [
+						{
+							"id": "t1", "owner_id": "p1", "title": "title", "description": null, "status": "todo", "parent_id": null,
+							"start_at": null, "due_at": null, "created_at": null, "updated_at": null, "labels": [], "deleted_at": null,
+							"recurrence": null, "series_id": null, "occurrence": null, "version": 1, "project_id": "1", "rank": null, "checklist": [], "progress": {"checked": 0, "total": 0}
+						}
+					],
+					"next": null
+				}`,
+			},
+			setTasks: func(mk *task.StorageMock) {
+				mk.
+					On("List", "p1", &task.Query{Size: 10, Filter: task.Condition{Field: task.FieldProjectID, Operator: task.OperatorEq, Value: "1"}}).
+					Return(&task.Page{Tasks: []*task.Task{
+						{ID: optional.Some("t1"), OwnerID: optional.Some("p1"), Title: optional.Some("title"), Status: optional.Some(task.StatusTodo), Version: optional.Some(1), ProjectID: optional.Some("1")},
+					}}, nil)
+			},
+			setProjects: func(mk *project.StorageMock) {
+				mk.On("Get", "p1", "1").Return(&project.Project{ID: optional.Some("1")}, nil)
+			},
+		},
+
+		// failed cases
+		{
+			title: "Failed to list the tasks of a project: project not found",
+			input: input{id: "1"},
+			output: output{
+				status: http.StatusNotFound,
+				body: `{"data": null, "message": "failed to list tasks: project not found"}`,
+			},
+			setTasks: func(mk *task.StorageMock) {},
+			setProjects: func(mk *project.StorageMock) {
+				mk.On("Get", "p1", "1").Return((*project.Project)(nil), project.ErrStorageNotFound)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			ts := task.NewStorageMock()
+			c.setTasks(ts)
+			ps := project.NewStorageMock()
+			c.setProjects(ps)
+
+			cl := NewProjectController(ps, ts)
+			hd := cl.Tasks()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/projects/"+c.input.id+"/tasks"+c.input.query, nil)
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("id", c.input.id)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chiCtx))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			ts.AssertExpectations(t)
+			ps.AssertExpectations(t)
+		})
+	}
+}
@@ -3,44 +3,325 @@ package handlers
 import (
 	"api/cmd/rest/middlewares/logger"
 	"api/cmd/rest/response"
+	"api/internal/profiles/contexter"
 	"api/internal/task"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/LNMMusic/optional"
 
-	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/v5"
 )
 
 func NewTaskController(storage task.Storage) *Task {
-	return &Task{storage: storage}
+	return &Task{storage: storage, now: time.Now}
 }
 
 // Task is an implementation of the task controller.
 type Task struct {
 	// storage
 	storage task.Storage
+	// now returns the current time (used by the due date views)
+	now func() time.Time
 }
 
-func (t *Task) Get() http.HandlerFunc {
-	type resp struct {
-		ID			optional.Option[string]	`json:"id"`
-		Title		optional.Option[string]	`json:"title"`
-		Description	optional.Option[string]	`json:"description"`
-		Completed	optional.Option[bool]	`json:"completed"`
+// on returns the storage bound to the context of the request, so its statements are canceled if the client goes away.
+func (t *Task) on(r *http.Request) (st task.Storage) {
+	st = t.storage.WithContext(r.Context())
+	return
+}
+
+// TaskDTO is the representation of a task in the responses.
+type TaskDTO struct {
+	ID			optional.Option[string]	`json:"id"`
+	OwnerID		optional.Option[string]	`json:"owner_id"`
+	Title		optional.Option[string]	`json:"title"`
+	Description	optional.Option[string]	`json:"description"`
+	Status		optional.Option[task.Status] `json:"status"`
+	ParentID	optional.Option[string]	`json:"parent_id"`
+	StartAt		optional.Option[time.Time] `json:"start_at"`
+	DueAt		optional.Option[time.Time] `json:"due_at"`
+	CreatedAt	optional.Option[time.Time] `json:"created_at"`
+	UpdatedAt	optional.Option[time.Time] `json:"updated_at"`
+	Labels		[]string				`json:"labels"`
+	DeletedAt	optional.Option[time.Time] `json:"deleted_at"`
+	Recurrence	optional.Option[string]	`json:"recurrence"`
+	SeriesID	optional.Option[string]	`json:"series_id"`
+	Occurrence	optional.Option[int]	`json:"occurrence"`
+	Version		optional.Option[int]	`json:"version"`
+	ProjectID	optional.Option[string]	`json:"project_id"`
+	Rank		optional.Option[string]	`json:"rank"`
+	Checklist	[]ItemDTO				`json:"checklist"`
+	Progress	ProgressDTO				`json:"progress"`
+}
+
+// ItemDTO is the representation of an item of a checklist in the responses.
+type ItemDTO struct {
+	ID		string	`json:"id"`
+	Text	string	`json:"text"`
+	Checked	bool	`json:"checked"`
+}
+
+// ProgressDTO is the progress of a checklist: how many items are checked out of the total.
+type ProgressDTO struct {
+	Checked	int	`json:"checked"`
+	Total	int	`json:"total"`
+}
+
+// NewTaskDTO returns the representation of the given task.
+func NewTaskDTO(ts *task.Task) (dto TaskDTO) {
+	dto = TaskDTO{
+		ID: 		 ts.ID,
+		OwnerID: 	 ts.OwnerID,
+		Title: 		 ts.Title,
+		Description: ts.Description,
+		Status: 	 ts.Status,
+		ParentID: 	 ts.ParentID,
+		StartAt: 	 ts.StartAt,
+		DueAt: 		 ts.DueAt,
+		CreatedAt: 	 ts.CreatedAt,
+		UpdatedAt: 	 ts.UpdatedAt,
+		Labels: 	 ts.Labels,
+		DeletedAt: 	 ts.DeletedAt,
+		Recurrence:  ts.Recurrence,
+		SeriesID: 	 ts.SeriesID,
+		Occurrence:  ts.Occurrence,
+		Version: 	 ts.Version,
+		ProjectID: 	 ts.ProjectID,
+		Rank: 		 ts.Rank,
+	}
+	// -> no labels is an empty list
+	if dto.Labels == nil {
+		dto.Labels = []string{}
+	}
+	dto.Checklist = make([]ItemDTO, 0, len(ts.Checklist))
+	for _, it := range ts.Checklist {
+		dto.Checklist = append(dto.Checklist, ItemDTO{ID: it.ID, Text: it.Text, Checked: it.Checked})
+	}
+	dto.Progress.Checked, dto.Progress.Total = ts.Progress()
+	return
+}
+
+// NodeDTO is the representation of a task with its subtasks in the responses.
+type NodeDTO struct {
+	TaskDTO
+	Children []NodeDTO `json:"children"`
+}
+
+// NewNodeDTO returns the representation of the given node and its subtree.
+func NewNodeDTO(nd *task.Node) (dto NodeDTO) {
+	dto = NodeDTO{TaskDTO: NewTaskDTO(nd.Task), Children: make([]NodeDTO, 0, len(nd.Children))}
+	for _, ch := range nd.Children {
+		dto.Children = append(dto.Children, NewNodeDTO(ch))
 	}
+	return
+}
 
+// Get returns a task.
+// - expand=children returns the task with its subtasks (recursively)
+func (t *Task) Get() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
 		// param id
 		id := chi.URLParam(r, "id")
 
+		// query params
+		expand := r.URL.Query().Get("expand")
+		switch expand {
+		case "", "children":
+		default:
+			response.Err(w, http.StatusBadRequest, fmt.Sprintf("failed to get task: invalid expand %q", expand))
+			return
+		}
+
 		// process
-		ts, err := t.storage.Get(id)
+		var data any
+		var version optional.Option[int]
+		var err error
+		switch expand {
+		case "children":
+			var nd *task.Node
+			nd, err = t.on(r).Tree(profileId, id)
+			if err == nil {
+				data = NewNodeDTO(nd)
+				version = nd.Task.Version
+			}
+		default:
+			var ts *task.Task
+			ts, err = t.on(r).Get(profileId, id)
+			if err == nil {
+				data = NewTaskDTO(ts)
+				version = ts.Version
+			}
+		}
 		if err != nil {
 			switch {
 				case errors.Is(err, task.ErrStorageNotFound):
 					response.Err(w, http.StatusNotFound, "failed to get task: not found")
+				case errors.Is(err, task.ErrStorageForbidden):
+					response.Err(w, http.StatusForbidden, "failed to get task: forbidden")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		etag(w, version)
+		response.Ok(w, http.StatusOK, "succeed to get task", data)
+	}
+}
+
+func (t *Task) List() http.HandlerFunc {
+	return t.list(nil)
+}
+
+// Shared lists the tasks other profiles share with the profile.
+func (t *Task) Shared() http.HandlerFunc {
+	return t.page(task.Storage.Shared, nil)
+}
+
+func (t *Task) Trash() http.HandlerFunc {
+	return t.list(func(query *task.Query, params url.Values) (err error) {
+		query.Deleted = true
+		return
+	})
+}
+
+// Overdue lists the open tasks whose due date has passed.
+func (t *Task) Overdue() http.HandlerFunc {
+	return t.list(func(query *task.Query, params url.Values) (err error) {
+		due := task.Condition{Field: task.FieldDueAt, Operator: task.OperatorLt, Value: t.now().UTC()}
+		dueView(query, due)
+		return
+	})
+}
+
+// DueToday lists the open tasks due today.
+// - tz is the time zone of the day (UTC by default)
+func (t *Task) DueToday() http.HandlerFunc {
+	return t.list(func(query *task.Query, params url.Values) (err error) {
+		today, err := startOfDay(t.now(), params.Get("tz"))
+		if err != nil {
+			return
+		}
+
+		dueView(query,
+			task.Condition{Field: task.FieldDueAt, Operator: task.OperatorGte, Value: today.UTC()},
+			task.Condition{Field: task.FieldDueAt, Operator: task.OperatorLt, Value: today.AddDate(0, 0, 1).UTC()},
+		)
+		return
+	})
+}
+
+// Upcoming lists the open tasks due in the next days, after today.
+// - tz is the time zone of the days (UTC by default)
+// - days is the amount of days (DefaultUpcomingDays by default)
+func (t *Task) Upcoming() http.HandlerFunc {
+	return t.list(func(query *task.Query, params url.Values) (err error) {
+		today, err := startOfDay(t.now(), params.Get("tz"))
+		if err != nil {
+			return
+		}
+		days := DefaultUpcomingDays
+		if raw := params.Get("days"); raw != "" {
+			days, err = strconv.Atoi(raw)
+			if err != nil || days < 1 || days > MaxUpcomingDays {
+				err = fmt.Errorf("invalid days %q", raw)
+				return
+			}
+		}
+
+		tomorrow := today.AddDate(0, 0, 1)
+		dueView(query,
+			task.Condition{Field: task.FieldDueAt, Operator: task.OperatorGte, Value: tomorrow.UTC()},
+			task.Condition{Field: task.FieldDueAt, Operator: task.OperatorLt, Value: tomorrow.AddDate(0, 0, days).UTC()},
+		)
+		return
+	})
+}
+
+const (
+	DefaultUpcomingDays = 7
+	MaxUpcomingDays 	= 90
+)
+
+// dueView restricts the query to the open tasks (neither done nor archived) that match the given due date conditions.
+// - tasks are sorted by due date, unless other sort is requested
+func dueView(query *task.Query, conds ...task.Filter) {
+	conds = append(conds,
+		task.Condition{Field: task.FieldStatus, Operator: task.OperatorNe, Value: string(task.StatusDone)},
+		task.Condition{Field: task.FieldStatus, Operator: task.OperatorNe, Value: string(task.StatusArchived)},
+	)
+	if query.Filter != nil {
+		conds = append([]task.Filter{query.Filter}, conds...)
+	}
+	query.Filter = task.And{Filters: conds}
+
+	if query.Sort.Field == "" {
+		query.Sort = task.Sort{Field: task.FieldDueAt}
+	}
+}
+
+// startOfDay returns the start of the day of the given time in the given time zone (UTC if empty).
+func startOfDay(now time.Time, tz string) (day time.Time, err error) {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		err = fmt.Errorf("invalid time zone %q", tz)
+		return
+	}
+
+	now = now.In(loc)
+	day = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	return
+}
+
+// view restricts the query of a list of tasks.
+// - params are the query params of the request
+type view func(query *task.Query, params url.Values) (err error)
+
+// list returns the handler that lists the tasks of the profile, restricted by the given view (if any).
+func (t *Task) list(vw view) http.HandlerFunc {
+	return t.page(task.Storage.List, vw)
+}
+
+// lister returns the page of tasks of the profile that matches the query, from the given storage.
+type lister func(st task.Storage, profileId string, query *task.Query) (pg *task.Page, err error)
+
+// page returns the handler that lists the tasks of the given lister, restricted by the given view (if any).
+func (t *Task) page(ls lister, vw view) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// query params
+		params := r.URL.Query()
+		query, err := newTaskQuery(params)
+		if err == nil && vw != nil {
+			err = vw(query, params)
+		}
+		if err != nil {
+			response.Err(w, http.StatusBadRequest, "failed to list tasks: "+err.Error())
+			logger.Errors(r, err)
+			return
+		}
+
+		// process
+		pg, err := ls(t.on(r), profileId, query)
+		if err != nil {
+			switch {
+				case errors.Is(err, task.ErrStorageInvalidQuery):
+					response.Err(w, http.StatusBadRequest, "failed to list tasks: invalid query")
 				default:
 					response.Err(w, http.StatusInternalServerError, "internal error")
 			}
@@ -50,30 +331,64 @@ func (t *Task) Get() http.HandlerFunc {
 		}
 
 		// response
-		response.Ok(w, http.StatusOK, "succeed to get task", resp{
-			ID: 		 ts.ID,
-			Title: 		 ts.Title,
-			Description: ts.Description,
-			Completed: 	 ts.Completed,
-		})
+		data := make([]TaskDTO, 0, len(pg.Tasks))
+		for _, ts := range pg.Tasks {
+			data = append(data, NewTaskDTO(ts))
+		}
+		response.OkPage(w, http.StatusOK, "succeed to list tasks", data, pg.Next)
+	}
+}
+
+// newTaskQuery returns the query of the given params.
+// - cursor, size and sort are reserved, any other param is a filter
+// - tz and days are reserved for the views
+func newTaskQuery(params url.Values) (query *task.Query, err error) {
+	query = &task.Query{Cursor: params.Get("cursor")}
+	if size := params.Get("size"); size != "" {
+		query.Size, err = strconv.Atoi(size)
+		if err != nil {
+			err = fmt.Errorf("invalid size %q", size)
+			return
+		}
+	}
+	query.Sort, err = task.ParseSort(params.Get("sort"))
+	if err != nil {
+		return
+	}
+
+	// filter
+	filters := make(url.Values, len(params))
+	for key, values := range params {
+		switch key {
+		case "cursor", "size", "sort", "tz", "days":
+		default:
+			filters[key] = values
+		}
 	}
+	query.Filter, err = task.ParseFilter(filters)
+	return
 }
 
+// Create creates a task.
+// - completed is kept for the clients previous to the statuses: true is done and false is todo (status takes precedence)
 func (t *Task) Create() http.HandlerFunc {
 	type request struct {
 		Title 		optional.Option[string] `json:"title"`
 		Description optional.Option[string] `json:"description"`
+		Status 		optional.Option[task.Status] `json:"status"`
 		Completed 	optional.Option[bool]	`json:"completed"`
-	}
-
-	type resp struct {
-		ID			optional.Option[string]	`json:"id"`
-		Title		optional.Option[string]	`json:"title"`
-		Description	optional.Option[string]	`json:"description"`
-		Completed	optional.Option[bool]	`json:"completed"`
+		ParentID 	optional.Option[string] `json:"parent_id"`
+		StartAt 	optional.Option[time.Time] `json:"start_at"`
+		DueAt 		optional.Option[time.Time] `json:"due_at"`
+		Labels 		[]string				`json:"labels"`
+		Recurrence 	optional.Option[string] `json:"recurrence"`
+		ProjectID 	optional.Option[string] `json:"project_id"`
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
 		// request
 		var req request
 		err := json.NewDecoder(r.Body).Decode(&req)
@@ -83,16 +398,33 @@ func (t *Task) Create() http.HandlerFunc {
 			return
 		}
 
+		// -> compatibility with completed
+		if !req.Status.IsSome() && req.Completed.IsSome() {
+			req.Status = optional.Some(task.StatusTodo)
+			if completed, _ := req.Completed.Unwrap(); completed {
+				req.Status = optional.Some(task.StatusDone)
+			}
+		}
+
 		// process
 		ts := &task.Task{
 			ID: 		 optional.None[string](),
+			OwnerID: 	 optional.Some(profileId),
 			Title: 		 req.Title,
 			Description: req.Description,
-			Completed: 	 req.Completed,
+			Status: 	 req.Status,
+			ParentID: 	 req.ParentID,
+			StartAt: 	 req.StartAt,
+			DueAt: 		 req.DueAt,
+			Labels: 	 normalizeLabels(req.Labels),
+			Recurrence:  req.Recurrence,
+			ProjectID: 	 req.ProjectID,
 		}
-		err = t.storage.Save(ts)
+		err = t.on(r).Save(ts)
 		if err != nil {
 			switch {
+				case errors.Is(err, task.ErrStorageCycle):
+					response.Err(w, http.StatusConflict, "failed to create task: cycle")
 				case errors.Is(err, task.ErrStorageInvalid):
 					response.Err(w, http.StatusUnprocessableEntity, "failed to create task: invalid task")
 				default:
@@ -104,11 +436,1276 @@ func (t *Task) Create() http.HandlerFunc {
 		}
 
 		// response
-		response.Ok(w, http.StatusCreated, "succeed to create task", resp{
-			ID: 		 ts.ID,
-			Title: 		 ts.Title,
-			Description: ts.Description,
-			Completed: 	 ts.Completed,
-		})
+		etag(w, ts.Version)
+		response.Ok(w, http.StatusCreated, "succeed to create task", NewTaskDTO(ts))
+	}
+}
+
+func (t *Task) Update() http.HandlerFunc {
+	type request struct {
+		Title 		optional.Option[string] `json:"title"`
+		Description optional.Option[string] `json:"description"`
+		Status 		optional.Option[task.Status] `json:"status"`
+		ParentID 	optional.Option[string] `json:"parent_id"`
+		StartAt 	optional.Option[time.Time] `json:"start_at"`
+		DueAt 		optional.Option[time.Time] `json:"due_at"`
+		Labels 		[]string				`json:"labels"`
+		Recurrence 	optional.Option[string] `json:"recurrence"`
+		ProjectID 	optional.Option[string] `json:"project_id"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// param id
+		id := chi.URLParam(r, "id")
+
+		// request
+		var req request
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			response.Err(w, http.StatusBadRequest, "failed to update task: invalid request")
+			logger.Errors(r, err)
+			return
+		}
+
+		// precondition
+		// -> writes are conditional on the version of the task
+		version, err := ifMatch(r)
+		if err != nil {
+			switch {
+				case errors.Is(err, errIfMatchRequired):
+					response.Err(w, http.StatusPreconditionRequired, "failed to update task: if-match required")
+				default:
+					response.Err(w, http.StatusBadRequest, "failed to update task: invalid if-match")
+			}
+			logger.Errors(r, err)
+			return
+		}
+
+		// process
+		ts := &task.Task{
+			ID: 		 optional.Some(id),
+			Version: 	 version,
+			Title: 		 req.Title,
+			Description: req.Description,
+			Status: 	 req.Status,
+			ParentID: 	 req.ParentID,
+			StartAt: 	 req.StartAt,
+			DueAt: 		 req.DueAt,
+			Labels: 	 normalizeLabels(req.Labels),
+			Recurrence:  req.Recurrence,
+			ProjectID: 	 req.ProjectID,
+		}
+		err = t.on(r).Update(profileId, ts)
+		if err != nil {
+			switch {
+				case errors.Is(err, task.ErrStorageNotFound):
+					response.Err(w, http.StatusNotFound, "failed to update task: not found")
+				case errors.Is(err, task.ErrStorageForbidden):
+					response.Err(w, http.StatusForbidden, "failed to update task: forbidden")
+				case errors.Is(err, task.ErrStorageVersionMismatch):
+					response.Err(w, http.StatusPreconditionFailed, "failed to update task: version mismatch")
+				case errors.Is(err, task.ErrStorageCycle):
+					response.Err(w, http.StatusConflict, "failed to update task: cycle")
+				case errors.Is(err, task.ErrStorageTransition):
+					response.Err(w, http.StatusConflict, "failed to update task: illegal transition")
+				case errors.Is(err, task.ErrStorageInvalid):
+					response.Err(w, http.StatusUnprocessableEntity, "failed to update task: invalid task")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		etag(w, ts.Version)
+		response.Ok(w, http.StatusOK, "succeed to update task", NewTaskDTO(ts))
+	}
+}
+
+func (t *Task) Patch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// param id
+		id := chi.URLParam(r, "id")
+
+		// request
+		// -> fields are decoded one by one to tell apart the absent ones from the null ones
+		var req map[string]json.RawMessage
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			response.Err(w, http.StatusBadRequest, "failed to patch task: invalid request")
+			logger.Errors(r, err)
+			return
+		}
+		patch, err := newTaskPatch(req)
+		if err != nil {
+			response.Err(w, http.StatusBadRequest, "failed to patch task: invalid request")
+			logger.Errors(r, err)
+			return
+		}
+
+		// precondition
+		// -> writes are conditional on the version of the task
+		version, err := ifMatch(r)
+		if err != nil {
+			switch {
+				case errors.Is(err, errIfMatchRequired):
+					response.Err(w, http.StatusPreconditionRequired, "failed to patch task: if-match required")
+				default:
+					response.Err(w, http.StatusBadRequest, "failed to patch task: invalid if-match")
+			}
+			logger.Errors(r, err)
+			return
+		}
+
+		// process
+		ts, err := t.on(r).Get(profileId, id)
+		if err == nil {
+			patch.Apply(ts)
+			ts.Version = version
+			err = t.on(r).Update(profileId, ts)
+		}
+		if err != nil {
+			switch {
+				case errors.Is(err, task.ErrStorageNotFound):
+					response.Err(w, http.StatusNotFound, "failed to patch task: not found")
+				case errors.Is(err, task.ErrStorageForbidden):
+					response.Err(w, http.StatusForbidden, "failed to patch task: forbidden")
+				case errors.Is(err, task.ErrStorageVersionMismatch):
+					response.Err(w, http.StatusPreconditionFailed, "failed to patch task: version mismatch")
+				case errors.Is(err, task.ErrStorageCycle):
+					response.Err(w, http.StatusConflict, "failed to patch task: cycle")
+				case errors.Is(err, task.ErrStorageTransition):
+					response.Err(w, http.StatusConflict, "failed to patch task: illegal transition")
+				case errors.Is(err, task.ErrStorageInvalid):
+					response.Err(w, http.StatusUnprocessableEntity, "failed to patch task: invalid task")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		etag(w, ts.Version)
+		response.Ok(w, http.StatusOK, "succeed to patch task", NewTaskDTO(ts))
+	}
+}
+
+// Transition moves a task to another status, as long as the workflow allows it.
+func (t *Task) Transition() http.HandlerFunc {
+	type request struct {
+		Status task.Status `json:"status"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// param id
+		id := chi.URLParam(r, "id")
+
+		// request
+		var req request
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			response.Err(w, http.StatusBadRequest, "failed to transition task: invalid request")
+			logger.Errors(r, err)
+			return
+		}
+
+		// precondition
+		// -> writes are conditional on the version of the task
+		version, err := ifMatch(r)
+		if err != nil {
+			switch {
+				case errors.Is(err, errIfMatchRequired):
+					response.Err(w, http.StatusPreconditionRequired, "failed to transition task: if-match required")
+				default:
+					response.Err(w, http.StatusBadRequest, "failed to transition task: invalid if-match")
+			}
+			logger.Errors(r, err)
+			return
+		}
+
+		// process
+		ts, err := t.on(r).Get(profileId, id)
+		if err == nil {
+			ts.Status = optional.Some(req.Status)
+			ts.Version = version
+			err = t.on(r).Update(profileId, ts)
+		}
+		if err != nil {
+			switch {
+				case errors.Is(err, task.ErrStorageNotFound):
+					response.Err(w, http.StatusNotFound, "failed to transition task: not found")
+				case errors.Is(err, task.ErrStorageForbidden):
+					response.Err(w, http.StatusForbidden, "failed to transition task: forbidden")
+				case errors.Is(err, task.ErrStorageVersionMismatch):
+					response.Err(w, http.StatusPreconditionFailed, "failed to transition task: version mismatch")
+				case errors.Is(err, task.ErrStorageTransition):
+					response.Err(w, http.StatusConflict, "failed to transition task: illegal transition")
+				case errors.Is(err, task.ErrStorageInvalid):
+					response.Err(w, http.StatusUnprocessableEntity, "failed to transition task: invalid task")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		etag(w, ts.Version)
+		response.Ok(w, http.StatusOK, "succeed to transition task", NewTaskDTO(ts))
 	}
-}
\ No newline at end of file
+}
+
+func (t *Task) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// param id
+		id := chi.URLParam(r, "id")
+
+		// precondition
+		// -> writes are conditional on the version of the task
+		version, err := ifMatch(r)
+		if err != nil {
+			switch {
+				case errors.Is(err, errIfMatchRequired):
+					response.Err(w, http.StatusPreconditionRequired, "failed to delete task: if-match required")
+				default:
+					response.Err(w, http.StatusBadRequest, "failed to delete task: invalid if-match")
+			}
+			logger.Errors(r, err)
+			return
+		}
+
+		// process
+		err = t.on(r).Delete(profileId, id, version)
+		if err != nil {
+			switch {
+				case errors.Is(err, task.ErrStorageNotFound):
+					response.Err(w, http.StatusNotFound, "failed to delete task: not found")
+				case errors.Is(err, task.ErrStorageVersionMismatch):
+					response.Err(w, http.StatusPreconditionFailed, "failed to delete task: version mismatch")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		response.Ok(w, http.StatusOK, "succeed to delete task", nil)
+	}
+}
+
+func (t *Task) Restore() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// param id
+		id := chi.URLParam(r, "id")
+
+		// precondition
+		// -> writes are conditional on the version of the task
+		version, err := ifMatch(r)
+		if err != nil {
+			switch {
+				case errors.Is(err, errIfMatchRequired):
+					response.Err(w, http.StatusPreconditionRequired, "failed to restore task: if-match required")
+				default:
+					response.Err(w, http.StatusBadRequest, "failed to restore task: invalid if-match")
+			}
+			logger.Errors(r, err)
+			return
+		}
+
+		// process
+		err = t.on(r).Restore(profileId, id, version)
+		if err != nil {
+			switch {
+				case errors.Is(err, task.ErrStorageNotFound):
+					response.Err(w, http.StatusNotFound, "failed to restore task: not found in trash")
+				case errors.Is(err, task.ErrStorageVersionMismatch):
+					response.Err(w, http.StatusPreconditionFailed, "failed to restore task: version mismatch")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		response.Ok(w, http.StatusOK, "succeed to restore task", nil)
+	}
+}
+
+// Move changes the order of a task by hand, placing it after the task with the after_id and before the one with the before_id.
+// - one of the neighbours can be left out, to place the task right next to the other one
+// - only the rank of the task changes, the rest of the tasks are not renumbered
+func (t *Task) Move() http.HandlerFunc {
+	type request struct {
+		AfterID  string `json:"after_id"`
+		BeforeID string `json:"before_id"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// param id
+		id := chi.URLParam(r, "id")
+
+		// request
+		var req request
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err == nil && req.AfterID == "" && req.BeforeID == "" {
+			err = errors.New("neighbour required")
+		}
+		if err != nil {
+			response.Err(w, http.StatusBadRequest, "failed to move task: invalid request")
+			logger.Errors(r, err)
+			return
+		}
+
+		// precondition
+		// -> writes are conditional on the version of the task
+		version, err := ifMatch(r)
+		if err != nil {
+			switch {
+				case errors.Is(err, errIfMatchRequired):
+					response.Err(w, http.StatusPreconditionRequired, "failed to move task: if-match required")
+				default:
+					response.Err(w, http.StatusBadRequest, "failed to move task: invalid if-match")
+			}
+			logger.Errors(r, err)
+			return
+		}
+
+		// process
+		err = t.on(r).Move(profileId, id, req.AfterID, req.BeforeID, version)
+		var ts *task.Task
+		if err == nil {
+			ts, err = t.on(r).Get(profileId, id)
+		}
+		if err != nil {
+			switch {
+				case errors.Is(err, task.ErrStorageNotFound):
+					response.Err(w, http.StatusNotFound, "failed to move task: not found")
+				case errors.Is(err, task.ErrStorageForbidden):
+					response.Err(w, http.StatusForbidden, "failed to move task: forbidden")
+				case errors.Is(err, task.ErrStorageVersionMismatch):
+					response.Err(w, http.StatusPreconditionFailed, "failed to move task: version mismatch")
+				case errors.Is(err, task.ErrStorageInvalid):
+					response.Err(w, http.StatusUnprocessableEntity, "failed to move task: invalid neighbours")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		etag(w, ts.Version)
+		response.Ok(w, http.StatusOK, "succeed to move task", NewTaskDTO(ts))
+	}
+}
+
+// BatchResultDTO is the representation of the result of an operation of a batch in the responses.
+type BatchResultDTO struct {
+	Status	int						`json:"status"`
+	Data	*TaskDTO				`json:"data"`
+	Error	optional.Option[string]	`json:"error"`
+}
+
+// Batch runs many create, update and delete operations in a single request (up to task.MaxBatch).
+// - mode atomic (default) applies all of them or none, best_effort applies every operation on its own
+// - updates and deletes need the version of the task, they are conditional on it (as If-Match)
+// - every operation has its own status and error, as if it was requested on its own
+func (t *Task) Batch() http.HandlerFunc {
+	type requestTask struct {
+		Title 		optional.Option[string] `json:"title"`
+		Description optional.Option[string] `json:"description"`
+		Status 		optional.Option[task.Status] `json:"status"`
+		ParentID 	optional.Option[string] `json:"parent_id"`
+		StartAt 	optional.Option[time.Time] `json:"start_at"`
+		DueAt 		optional.Option[time.Time] `json:"due_at"`
+		Labels 		[]string				`json:"labels"`
+		Recurrence 	optional.Option[string] `json:"recurrence"`
+		ProjectID 	optional.Option[string] `json:"project_id"`
+	}
+	type requestOperation struct {
+		Op 		task.OpKind 			`json:"op"`
+		ID 		optional.Option[string] `json:"id"`
+		Version optional.Option[int]	`json:"version"`
+		Task 	requestTask				`json:"task"`
+	}
+	type request struct {
+		Mode 	   optional.Option[task.Mode] `json:"mode"`
+		Operations []requestOperation	  `json:"operations"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// request
+		var req request
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			response.Err(w, http.StatusBadRequest, "failed to run batch: invalid request")
+			logger.Errors(r, err)
+			return
+		}
+		if len(req.Operations) == 0 || len(req.Operations) > task.MaxBatch {
+			response.Err(w, http.StatusBadRequest, "failed to run batch: invalid number of operations")
+			logger.Errors(r, fmt.Errorf("batch of %d operations", len(req.Operations)))
+			return
+		}
+		mode := task.ModeAtomic
+		if m, e := req.Mode.Unwrap(); e == nil {
+			mode = m
+		}
+		if mode != task.ModeAtomic && mode != task.ModeBestEffort {
+			response.Err(w, http.StatusBadRequest, "failed to run batch: invalid mode")
+			logger.Errors(r, fmt.Errorf("batch mode %v", mode))
+			return
+		}
+
+		// process
+		ops := make([]*task.Op, len(req.Operations))
+		for i, o := range req.Operations {
+			id, e := o.ID.Unwrap()
+			if o.Op != task.OpCreate && (e != nil || id == "") {
+				response.Err(w, http.StatusBadRequest, fmt.Sprintf("failed to run batch: invalid operation %d", i))
+				logger.Errors(r, fmt.Errorf("operation %d: %v without id", i, o.Op))
+				return
+			}
+			// -> writes are conditional on the version of the task, as If-Match
+			if (o.Op == task.OpUpdate || o.Op == task.OpDelete) && !o.Version.IsSome() {
+				response.Err(w, http.StatusPreconditionRequired, fmt.Sprintf("failed to run batch: version required on operation %d", i))
+				logger.Errors(r, fmt.Errorf("operation %d: %v without version", i, o.Op))
+				return
+			}
+
+			switch o.Op {
+			case task.OpCreate, task.OpUpdate:
+				ts := &task.Task{
+					Title: 		 o.Task.Title,
+					Description: o.Task.Description,
+					Status: 	 o.Task.Status,
+					ParentID: 	 o.Task.ParentID,
+					StartAt: 	 o.Task.StartAt,
+					DueAt: 		 o.Task.DueAt,
+					Labels: 	 normalizeLabels(o.Task.Labels),
+					Recurrence:  o.Task.Recurrence,
+					ProjectID: 	 o.Task.ProjectID,
+				}
+				if o.Op == task.OpUpdate {
+					ts.ID = optional.Some(id)
+					ts.Version = o.Version
+				}
+				ops[i] = &task.Op{Kind: o.Op, Task: ts}
+			case task.OpDelete:
+				ops[i] = &task.Op{Kind: o.Op, ID: id, Version: o.Version}
+			default:
+				response.Err(w, http.StatusBadRequest, fmt.Sprintf("failed to run batch: invalid operation %d", i))
+				logger.Errors(r, fmt.Errorf("operation %d: unknown op %v", i, o.Op))
+				return
+			}
+		}
+
+		rs, err := t.on(r).Batch(profileId, ops, mode)
+		if err != nil && !errors.Is(err, task.ErrStorageBatchAborted) {
+			switch {
+				case errors.Is(err, task.ErrStorageInvalid):
+					response.Err(w, http.StatusBadRequest, "failed to run batch: invalid request")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		data := make([]BatchResultDTO, len(rs))
+		for i, res := range rs {
+			data[i] = newBatchResultDTO(ops[i].Kind, res)
+		}
+		if err != nil {
+			logger.Errors(r, err)
+			response.Ok(w, http.StatusUnprocessableEntity, "failed to run batch: aborted", data)
+			return
+		}
+		response.Ok(w, http.StatusOK, "succeed to run batch", data)
+	}
+}
+
+// newBatchResultDTO returns the representation of the result of an operation of the given kind.
+// - the status and the error are the ones of the operation requested on its own
+func newBatchResultDTO(kind task.OpKind, rs *task.Result) (dto BatchResultDTO) {
+	if rs.Err == nil {
+		dto.Status = http.StatusOK
+		if kind == task.OpCreate {
+			dto.Status = http.StatusCreated
+		}
+		if rs.Task != nil {
+			ts := NewTaskDTO(rs.Task)
+			dto.Data = &ts
+		}
+		return
+	}
+
+	var msg string
+	switch {
+		case errors.Is(rs.Err, task.ErrStorageBatchAborted):
+			dto.Status, msg = http.StatusFailedDependency, "aborted"
+		case errors.Is(rs.Err, task.ErrStorageNotFound):
+			dto.Status, msg = http.StatusNotFound, "not found"
+		case errors.Is(rs.Err, task.ErrStorageForbidden):
+			dto.Status, msg = http.StatusForbidden, "forbidden"
+		case errors.Is(rs.Err, task.ErrStorageVersionMismatch):
+			dto.Status, msg = http.StatusPreconditionFailed, "version mismatch"
+		case errors.Is(rs.Err, task.ErrStorageCycle):
+			dto.Status, msg = http.StatusConflict, "cycle"
+		case errors.Is(rs.Err, task.ErrStorageTransition):
+			dto.Status, msg = http.StatusConflict, "illegal transition"
+		case errors.Is(rs.Err, task.ErrStorageInvalid):
+			dto.Status, msg = http.StatusUnprocessableEntity, "invalid task"
+		default:
+			dto.Status, msg = http.StatusInternalServerError, "internal error"
+	}
+	dto.Error = optional.Some(msg)
+	return
+}
+
+func (t *Task) AddLabel() http.HandlerFunc {
+	type request struct {
+		Label string `json:"label"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// param id
+		id := chi.URLParam(r, "id")
+
+		// request
+		var req request
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			response.Err(w, http.StatusBadRequest, "failed to add label: invalid request")
+			logger.Errors(r, err)
+			return
+		}
+
+		// precondition
+		// -> writes are conditional on the version of the task
+		version, err := ifMatch(r)
+		if err != nil {
+			switch {
+				case errors.Is(err, errIfMatchRequired):
+					response.Err(w, http.StatusPreconditionRequired, "failed to add label: if-match required")
+				default:
+					response.Err(w, http.StatusBadRequest, "failed to add label: invalid if-match")
+			}
+			logger.Errors(r, err)
+			return
+		}
+
+		// process
+		err = t.on(r).AddLabel(profileId, id, task.NormalizeLabel(req.Label), version)
+		if err != nil {
+			switch {
+				case errors.Is(err, task.ErrStorageNotFound):
+					response.Err(w, http.StatusNotFound, "failed to add label: not found")
+				case errors.Is(err, task.ErrStorageForbidden):
+					response.Err(w, http.StatusForbidden, "failed to add label: forbidden")
+				case errors.Is(err, task.ErrStorageVersionMismatch):
+					response.Err(w, http.StatusPreconditionFailed, "failed to add label: version mismatch")
+				case errors.Is(err, task.ErrStorageInvalid):
+					response.Err(w, http.StatusUnprocessableEntity, "failed to add label: invalid label")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		response.Ok(w, http.StatusOK, "succeed to add label", nil)
+	}
+}
+
+func (t *Task) RemoveLabel() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// param id and label
+		id := chi.URLParam(r, "id")
+		label := chi.URLParam(r, "label")
+
+		// precondition
+		// -> writes are conditional on the version of the task
+		version, err := ifMatch(r)
+		if err != nil {
+			switch {
+				case errors.Is(err, errIfMatchRequired):
+					response.Err(w, http.StatusPreconditionRequired, "failed to remove label: if-match required")
+				default:
+					response.Err(w, http.StatusBadRequest, "failed to remove label: invalid if-match")
+			}
+			logger.Errors(r, err)
+			return
+		}
+
+		// process
+		err = t.on(r).RemoveLabel(profileId, id, task.NormalizeLabel(label), version)
+		if err != nil {
+			switch {
+				case errors.Is(err, task.ErrStorageNotFound):
+					response.Err(w, http.StatusNotFound, "failed to remove label: not found")
+				case errors.Is(err, task.ErrStorageForbidden):
+					response.Err(w, http.StatusForbidden, "failed to remove label: forbidden")
+				case errors.Is(err, task.ErrStorageVersionMismatch):
+					response.Err(w, http.StatusPreconditionFailed, "failed to remove label: version mismatch")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		response.Ok(w, http.StatusOK, "succeed to remove label", nil)
+	}
+}
+
+// AddItem adds an item to the end of the checklist of a task.
+func (t *Task) AddItem() http.HandlerFunc {
+	type request struct {
+		Text 	string `json:"text"`
+		Checked bool   `json:"checked"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// param id
+		id := chi.URLParam(r, "id")
+
+		// request
+		var req request
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			response.Err(w, http.StatusBadRequest, "failed to add item: invalid request")
+			logger.Errors(r, err)
+			return
+		}
+
+		// precondition
+		// -> writes are conditional on the version of the task
+		version, err := ifMatch(r)
+		if err != nil {
+			switch {
+				case errors.Is(err, errIfMatchRequired):
+					response.Err(w, http.StatusPreconditionRequired, "failed to add item: if-match required")
+				default:
+					response.Err(w, http.StatusBadRequest, "failed to add item: invalid if-match")
+			}
+			logger.Errors(r, err)
+			return
+		}
+
+		// process
+		it := &task.Item{Text: strings.TrimSpace(req.Text), Checked: req.Checked}
+		err = t.on(r).AddItem(profileId, id, it, version)
+		if err != nil {
+			switch {
+				case errors.Is(err, task.ErrStorageNotFound):
+					response.Err(w, http.StatusNotFound, "failed to add item: not found")
+				case errors.Is(err, task.ErrStorageForbidden):
+					response.Err(w, http.StatusForbidden, "failed to add item: forbidden")
+				case errors.Is(err, task.ErrStorageVersionMismatch):
+					response.Err(w, http.StatusPreconditionFailed, "failed to add item: version mismatch")
+				case errors.Is(err, task.ErrStorageInvalid):
+					response.Err(w, http.StatusUnprocessableEntity, "failed to add item: invalid item")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		response.Ok(w, http.StatusCreated, "succeed to add item", ItemDTO{ID: it.ID, Text: it.Text, Checked: it.Checked})
+	}
+}
+
+// CheckItem checks or unchecks an item of the checklist of a task.
+func (t *Task) CheckItem() http.HandlerFunc {
+	type request struct {
+		Checked optional.Option[bool] `json:"checked"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// param id and item id
+		id := chi.URLParam(r, "id")
+		itemId := chi.URLParam(r, "item_id")
+
+		// request
+		var req request
+		err := json.NewDecoder(r.Body).Decode(&req)
+		var checked bool
+		if err == nil {
+			checked, err = req.Checked.Unwrap()
+		}
+		if err != nil {
+			response.Err(w, http.StatusBadRequest, "failed to check item: invalid request")
+			logger.Errors(r, err)
+			return
+		}
+
+		// precondition
+		// -> writes are conditional on the version of the task
+		version, err := ifMatch(r)
+		if err != nil {
+			switch {
+				case errors.Is(err, errIfMatchRequired):
+					response.Err(w, http.StatusPreconditionRequired, "failed to check item: if-match required")
+				default:
+					response.Err(w, http.StatusBadRequest, "failed to check item: invalid if-match")
+			}
+			logger.Errors(r, err)
+			return
+		}
+
+		// process
+		err = t.on(r).CheckItem(profileId, id, itemId, checked, version)
+		if err != nil {
+			switch {
+				case errors.Is(err, task.ErrStorageNotFound):
+					response.Err(w, http.StatusNotFound, "failed to check item: not found")
+				case errors.Is(err, task.ErrStorageForbidden):
+					response.Err(w, http.StatusForbidden, "failed to check item: forbidden")
+				case errors.Is(err, task.ErrStorageVersionMismatch):
+					response.Err(w, http.StatusPreconditionFailed, "failed to check item: version mismatch")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		response.Ok(w, http.StatusOK, "succeed to check item", nil)
+	}
+}
+
+// MoveItem moves an item of the checklist of a task to another position (from zero).
+func (t *Task) MoveItem() http.HandlerFunc {
+	type request struct {
+		Position optional.Option[int] `json:"position"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// param id and item id
+		id := chi.URLParam(r, "id")
+		itemId := chi.URLParam(r, "item_id")
+
+		// request
+		var req request
+		err := json.NewDecoder(r.Body).Decode(&req)
+		var position int
+		if err == nil {
+			position, err = req.Position.Unwrap()
+		}
+		if err != nil {
+			response.Err(w, http.StatusBadRequest, "failed to move item: invalid request")
+			logger.Errors(r, err)
+			return
+		}
+
+		// precondition
+		// -> writes are conditional on the version of the task
+		version, err := ifMatch(r)
+		if err != nil {
+			switch {
+				case errors.Is(err, errIfMatchRequired):
+					response.Err(w, http.StatusPreconditionRequired, "failed to move item: if-match required")
+				default:
+					response.Err(w, http.StatusBadRequest, "failed to move item: invalid if-match")
+			}
+			logger.Errors(r, err)
+			return
+		}
+
+		// process
+		err = t.on(r).MoveItem(profileId, id, itemId, position, version)
+		if err != nil {
+			switch {
+				case errors.Is(err, task.ErrStorageNotFound):
+					response.Err(w, http.StatusNotFound, "failed to move item: not found")
+				case errors.Is(err, task.ErrStorageForbidden):
+					response.Err(w, http.StatusForbidden, "failed to move item: forbidden")
+				case errors.Is(err, task.ErrStorageVersionMismatch):
+					response.Err(w, http.StatusPreconditionFailed, "failed to move item: version mismatch")
+				case errors.Is(err, task.ErrStorageInvalid):
+					response.Err(w, http.StatusUnprocessableEntity, "failed to move item: invalid position")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		response.Ok(w, http.StatusOK, "succeed to move item", nil)
+	}
+}
+
+// RemoveItem removes an item from the checklist of a task.
+func (t *Task) RemoveItem() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// param id and item id
+		id := chi.URLParam(r, "id")
+		itemId := chi.URLParam(r, "item_id")
+
+		// precondition
+		// -> writes are conditional on the version of the task
+		version, err := ifMatch(r)
+		if err != nil {
+			switch {
+				case errors.Is(err, errIfMatchRequired):
+					response.Err(w, http.StatusPreconditionRequired, "failed to remove item: if-match required")
+				default:
+					response.Err(w, http.StatusBadRequest, "failed to remove item: invalid if-match")
+			}
+			logger.Errors(r, err)
+			return
+		}
+
+		// process
+		err = t.on(r).RemoveItem(profileId, id, itemId, version)
+		if err != nil {
+			switch {
+				case errors.Is(err, task.ErrStorageNotFound):
+					response.Err(w, http.StatusNotFound, "failed to remove item: not found")
+				case errors.Is(err, task.ErrStorageForbidden):
+					response.Err(w, http.StatusForbidden, "failed to remove item: forbidden")
+				case errors.Is(err, task.ErrStorageVersionMismatch):
+					response.Err(w, http.StatusPreconditionFailed, "failed to remove item: version mismatch")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		response.Ok(w, http.StatusOK, "succeed to remove item", nil)
+	}
+}
+
+func (t *Task) AddDependency() http.HandlerFunc {
+	type request struct {
+		BlockerID string `json:"blocker_id"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// param id
+		id := chi.URLParam(r, "id")
+
+		// request
+		var req request
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			response.Err(w, http.StatusBadRequest, "failed to add dependency: invalid request")
+			logger.Errors(r, err)
+			return
+		}
+
+		// process
+		err = t.on(r).AddDependency(profileId, id, req.BlockerID)
+		if err != nil {
+			switch {
+				case errors.Is(err, task.ErrStorageNotFound):
+					response.Err(w, http.StatusNotFound, "failed to add dependency: not found")
+				case errors.Is(err, task.ErrStorageCycle):
+					response.Err(w, http.StatusConflict, "failed to add dependency: cycle")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		response.Ok(w, http.StatusOK, "succeed to add dependency", nil)
+	}
+}
+
+func (t *Task) RemoveDependency() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// param id and blocker id
+		id := chi.URLParam(r, "id")
+		blockerId := chi.URLParam(r, "blocker_id")
+
+		// process
+		err := t.on(r).RemoveDependency(profileId, id, blockerId)
+		if err != nil {
+			switch {
+				case errors.Is(err, task.ErrStorageNotFound):
+					response.Err(w, http.StatusNotFound, "failed to remove dependency: not found")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		response.Ok(w, http.StatusOK, "succeed to remove dependency", nil)
+	}
+}
+
+// Order lists the tasks with the given ids, every task after the tasks that block it.
+// - id is repeated once per task (up to task.MaxPageSize)
+func (t *Task) Order() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// query params
+		ids := r.URL.Query()["id"]
+		if len(ids) == 0 || len(ids) > task.MaxPageSize {
+			response.Err(w, http.StatusBadRequest, fmt.Sprintf("failed to order tasks: between 1 and %d ids are required", task.MaxPageSize))
+			return
+		}
+
+		// process
+		ts, err := t.on(r).Order(profileId, ids)
+		if err != nil {
+			switch {
+				case errors.Is(err, task.ErrStorageNotFound):
+					response.Err(w, http.StatusNotFound, "failed to order tasks: not found")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		data := make([]TaskDTO, 0, len(ts))
+		for _, tk := range ts {
+			data = append(data, NewTaskDTO(tk))
+		}
+		response.Ok(w, http.StatusOK, "succeed to order tasks", data)
+	}
+}
+
+// GrantDTO is the representation of a grant in the responses.
+type GrantDTO struct {
+	ProfileID  string			 `json:"profile_id"`
+	Permission task.Permission `json:"permission"`
+}
+
+// Grant shares a task with another profile, with read or edit permission (it replaces the permission if it already has one).
+func (t *Task) Grant() http.HandlerFunc {
+	type request struct {
+		ProfileID  string		   `json:"profile_id"`
+		Permission task.Permission `json:"permission"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// param id
+		id := chi.URLParam(r, "id")
+
+		// request
+		var req request
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			response.Err(w, http.StatusBadRequest, "failed to grant access: invalid request")
+			logger.Errors(r, err)
+			return
+		}
+
+		// process
+		err = t.on(r).Grant(profileId, id, &task.Grant{ProfileID: req.ProfileID, Permission: req.Permission})
+		if err != nil {
+			switch {
+				case errors.Is(err, task.ErrStorageNotFound):
+					response.Err(w, http.StatusNotFound, "failed to grant access: not found")
+				case errors.Is(err, task.ErrStorageInvalid):
+					response.Err(w, http.StatusUnprocessableEntity, "failed to grant access: invalid grant")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		response.Ok(w, http.StatusOK, "succeed to grant access", GrantDTO{ProfileID: req.ProfileID, Permission: req.Permission})
+	}
+}
+
+func (t *Task) Revoke() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// param id and profile id
+		id := chi.URLParam(r, "id")
+		granteeId := chi.URLParam(r, "profile_id")
+
+		// process
+		err := t.on(r).Revoke(profileId, id, granteeId)
+		if err != nil {
+			switch {
+				case errors.Is(err, task.ErrStorageNotFound):
+					response.Err(w, http.StatusNotFound, "failed to revoke access: not found")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		response.Ok(w, http.StatusOK, "succeed to revoke access", nil)
+	}
+}
+
+// Grants lists the profiles a task is shared with.
+func (t *Task) Grants() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// param id
+		id := chi.URLParam(r, "id")
+
+		// process
+		gs, err := t.on(r).Grants(profileId, id)
+		if err != nil {
+			switch {
+				case errors.Is(err, task.ErrStorageNotFound):
+					response.Err(w, http.StatusNotFound, "failed to list grants: not found")
+				default:
+					response.Err(w, http.StatusInternalServerError, "internal error")
+			}
+			logger.Errors(r, err)
+
+			return
+		}
+
+		// response
+		data := make([]GrantDTO, 0, len(gs))
+		for _, g := range gs {
+			data = append(data, GrantDTO{ProfileID: g.ProfileID, Permission: g.Permission})
+		}
+		response.Ok(w, http.StatusOK, "succeed to list grants", data)
+	}
+}
+
+// LabelDTO is the representation of a label in the responses.
+type LabelDTO struct {
+	Name  string `json:"name"`
+	Tasks int	 `json:"tasks"`
+}
+
+// Labels lists the labels in use with the amount of tasks that have them.
+func (t *Task) Labels() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// profile
+		profileId := r.Context().Value(contexter.KeyProfileId).(string)
+
+		// process
+		ls, err := t.on(r).Labels(profileId)
+		if err != nil {
+			response.Err(w, http.StatusInternalServerError, "internal error")
+			logger.Errors(r, err)
+			return
+		}
+
+		// response
+		data := make([]LabelDTO, 0, len(ls))
+		for _, l := range ls {
+			data = append(data, LabelDTO{Name: l.Name, Tasks: l.Tasks})
+		}
+		response.Ok(w, http.StatusOK, "succeed to list labels", data)
+	}
+}
+
+// normalizeLabels returns the normalized labels (nil if there are none).
+func normalizeLabels(labels []string) (normalized []string) {
+	for _, label := range labels {
+		normalized = append(normalized, task.NormalizeLabel(label))
+	}
+	return
+}
+
+// newTaskPatch returns the patch of the given request fields.
+func newTaskPatch(fields map[string]json.RawMessage) (patch task.Patch, err error) {
+	patch.Title, err = patchField[string](fields, "title")
+	if err != nil {
+		return
+	}
+	patch.Description, err = patchField[string](fields, "description")
+	if err != nil {
+		return
+	}
+	patch.Status, err = patchField[task.Status](fields, "status")
+	if err != nil {
+		return
+	}
+	patch.ParentID, err = patchField[string](fields, "parent_id")
+	if err != nil {
+		return
+	}
+	patch.StartAt, err = patchField[time.Time](fields, "start_at")
+	if err != nil {
+		return
+	}
+	patch.DueAt, err = patchField[time.Time](fields, "due_at")
+	if err != nil {
+		return
+	}
+	patch.Labels, err = patchField[[]string](fields, "labels")
+	if err != nil {
+		return
+	}
+	if labels, _ := patch.Labels.Unwrap(); labels.IsSome() {
+		ls, _ := labels.Unwrap()
+		patch.Labels = optional.Some(optional.Some(normalizeLabels(ls)))
+	}
+	patch.Recurrence, err = patchField[string](fields, "recurrence")
+	if err != nil {
+		return
+	}
+	patch.ProjectID, err = patchField[string](fields, "project_id")
+	if err != nil {
+		return
+	}
+
+	// unknown fields
+	for key := range fields {
+		err = fmt.Errorf("unknown field %q", key)
+		return
+	}
+
+	return
+}
+
+// patchField decodes and consumes the field with the given key.
+// - None: the field is absent
+// - Some(None): the field is null
+// - Some(Some(v)): the field has a value
+func patchField[T any](fields map[string]json.RawMessage, key string) (field optional.Option[optional.Option[T]], err error) {
+	raw, ok := fields[key]
+	if !ok {
+		return
+	}
+	delete(fields, key)
+
+	var value optional.Option[T]
+	err = json.Unmarshal(raw, &value)
+	if err != nil {
+		return
+	}
+
+	field = optional.Some(value)
+	return
+}
+
+var (
+	// errIfMatchRequired is returned when a write has no If-Match header
+	errIfMatchRequired = errors.New("if-match required")
+	// errIfMatchInvalid is returned when the If-Match header is not a version
+	errIfMatchInvalid = errors.New("invalid if-match")
+)
+
+// etag sets the ETag header to the given version.
+func etag(w http.ResponseWriter, version optional.Option[int]) {
+	if v, err := version.Unwrap(); err == nil {
+		w.Header().Set("ETag", strconv.Quote(strconv.Itoa(v)))
+	}
+}
+
+// ifMatch returns the version a write is conditional on, from the If-Match header of the request.
+// - "*" matches any version, so no version is returned
+func ifMatch(r *http.Request) (version optional.Option[int], err error) {
+	header := r.Header.Get("If-Match")
+	switch header {
+	case "":
+		err = errIfMatchRequired
+		return
+	case "*":
+		return
+	}
+
+	// -> the tag is quoted, as the ETag header
+	tag, e := strconv.Unquote(header)
+	if e != nil {
+		tag = header
+	}
+	v, e := strconv.Atoi(tag)
+	if e != nil || v < 1 {
+		err = fmt.Errorf("%w: %s", errIfMatchInvalid, header)
+		return
+	}
+
+	version = optional.Some(v)
+	return
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"api/internal/profiles/contexter"
 	"api/internal/task"
 	"context"
 	"io"
@@ -8,6 +9,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/LNMMusic/optional"
 
@@ -19,7 +21,7 @@ import (
 // Tests
 func TestHandlerTask_Get(t *testing.T) {
 	type input struct {setW func(w *httptest.ResponseRecorder); setR func(r *http.Request)}
-	type output struct {status int; body string}
+	type output struct {status int; body string; etag string}
 	type testCase struct {
 		title	   string
 		input	   input
@@ -49,20 +51,172 @@ func TestHandlerTask_Get(t *testing.T) {
 					"message": "succeed to get task",
 					"data": {
 						"id": "1",
+						"owner_id": null,
 						"title": "title",
 						"description": "description",
-						"completed": false
+						"status": "todo",
+						"parent_id": null,
+						"start_at": null,
+						"due_at": null,
+						"created_at": null,
+						"updated_at": null,
+						"labels": [],
+						"deleted_at": null,
+						"recurrence": null,
+						"series_id": null,
+						"occurrence": null,
+						"version": 3,
+						"project_id": null,
+						"rank": null,
+						"checklist": [],
+						"progress": {"checked": 0, "total": 0}
 					}
 				}`,
+				etag: `"3"`,
 			},
 			setStorage: func(mk *task.StorageMock) {
 				mk.
-					On("Get", mock.Anything).
+					On("Get", "p1", mock.Anything).
 					Return(&task.Task{
 						ID: optional.Some("1"),
 						Title: optional.Some("title"),
 						Description: optional.Some("description"),
-						Completed: optional.Some(false),
+						Status: optional.Some(task.StatusTodo),
+						Version: optional.Some(3),
+					}, nil)
+			},
+		},
+		{
+			title: "Get a task with its checklist",
+			input: input{
+				setW: func(w *httptest.ResponseRecorder) {},
+				setR: func(r *http.Request) {
+					// base
+					r.Method = http.MethodGet
+					r.URL.Path = "/tasks/{id}"
+					// context (to get route params from path with chi)
+					chiCtx := chi.NewRouteContext()
+					chiCtx.URLParams.Add("id", "1")
+					*r = *r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chiCtx))
+				},
+			},
+			output: output{
+				status: http.StatusOK,
+				body: `{
+					"message": "succeed to get task",
+					"data": {
+						"id": "1", "owner_id": null, "title": "title", "description": null, "status": "todo", "parent_id": null,
+						"start_at": null, "due_at": null, "created_at": null, "updated_at": null, "labels": [], "deleted_at": null,
+						"recurrence": null, "series_id": null, "occurrence": null, "version": 1, "project_id": null, "rank": null,
+						"checklist": [
+							{"id": "a", "text": "milk", "checked": true},
+							{"id": "b", "text": "bread", "checked": false},
+							{"id": "c", "text": "eggs", "checked": true}
+						],
+						"progress": {"checked": 2, "total": 3}
+					}
+				}`,
+				etag: `"1"`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("Get", "p1", mock.Anything).
+					Return(&task.Task{
+						ID: optional.Some("1"),
+						Title: optional.Some("title"),
+						Status: optional.Some(task.StatusTodo),
+						Version: optional.Some(1),
+						Checklist: []task.Item{{ID: "a", Text: "milk", Checked: true}, {ID: "b", Text: "bread"}, {ID: "c", Text: "eggs", Checked: true}},
+					}, nil)
+			},
+		},
+		{
+			title: "Get a task with its subtasks",
+			input: input{
+				setW: func(w *httptest.ResponseRecorder) {},
+				setR: func(r *http.Request) {
+					// base
+					r.Method = http.MethodGet
+					r.URL.Path = "/tasks/{id}"
+					r.URL.RawQuery = "expand=children"
+					// context (to get route params from path with chi)
+					chiCtx := chi.NewRouteContext()
+					chiCtx.URLParams.Add("id", "1")
+					*r = *r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chiCtx))
+				},
+			},
+			output: output{
+				status: http.StatusOK,
+				body: `{
+					"message": "succeed to get task",
+					"data": {
+						"id": "1",
+						"owner_id": null,
+						"title": "title",
+						"description": null,
+						"status": "todo",
+						"parent_id": null,
+						"start_at": null,
+						"due_at": null,
+						"created_at": null,
+						"updated_at": null,
+						"labels": [],
+						"deleted_at": null,
+						"recurrence": null,
+						"series_id": null,
+						"occurrence": null,
+						"version": null,
+						"project_id": null,
+						"rank": null,
+						"checklist": [],
+						"progress": {"checked": 0, "total": 0},
+						"children": [
+							{
+								"id": "2",
+								"owner_id": null,
+								"title": "subtask",
+								"description": null,
+								"status": "todo",
+								"parent_id": "1",
+								"start_at": null,
+								"due_at": null,
+								"created_at": null,
+								"updated_at": null,
+								"labels": [],
+								"deleted_at": null,
+								"recurrence": null,
+								"series_id": null,
+								"occurrence": null,
+								"version": null,
+								"project_id": null,
+								"rank": null,
+								"checklist": [],
+								"progress": {"checked": 0, "total": 0},
+								"children": []
+							}
+						]
+					}
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("Tree", "p1", "1").
+					Return(&task.Node{
+						Task: &task.Task{
+							ID: optional.Some("1"),
+							Title: optional.Some("title"),
+							Status: optional.Some(task.StatusTodo),
+						},
+						Children: []*task.Node{
+							{
+								Task: &task.Task{
+									ID: optional.Some("2"),
+									Title: optional.Some("subtask"),
+									Status: optional.Some(task.StatusTodo),
+									ParentID: optional.Some("1"),
+								},
+							},
+						},
 					}, nil)
 			},
 		},
@@ -91,10 +245,34 @@ func TestHandlerTask_Get(t *testing.T) {
 			},
 			setStorage: func(mk *task.StorageMock) {
 				mk.
-					On("Get", mock.Anything).
+					On("Get", "p1", mock.Anything).
 					Return(&task.Task{}, task.ErrStorageNotFound)
 			},
 		},
+		{
+			title: "Failed to get a task: invalid expand",
+			input: input{
+				setW: func(w *httptest.ResponseRecorder) {},
+				setR: func(r *http.Request) {
+					// base
+					r.Method = http.MethodGet
+					r.URL.Path = "/tasks/1"
+					r.URL.RawQuery = "expand=parent"
+					// context (to get route params from path with chi)
+					chiCtx := chi.NewRouteContext()
+					chiCtx.URLParams.Add("id", "1")
+					*r = *r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chiCtx))
+				},
+			},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{
+					"data": null,
+					"message": "failed to get task: invalid expand \"parent\""
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
 		{
 			title: "Failed to get a task: internal error",
 			input: input{
@@ -118,7 +296,7 @@ func TestHandlerTask_Get(t *testing.T) {
 			},
 			setStorage: func(mk *task.StorageMock) {
 				mk.
-					On("Get", mock.Anything).
+					On("Get", "p1", mock.Anything).
 					Return(&task.Task{}, task.ErrStorageInternal)
 			},
 		},
@@ -137,9 +315,211 @@ func TestHandlerTask_Get(t *testing.T) {
 			// act
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodGet, "/tasks/1", nil)
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
 			c.input.setR(r)
 			hd(w, r)
 
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			assert.Equal(t, c.output.etag, w.Header().Get("ETag"))
+			st.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerTask_List(t *testing.T) {
+	type input struct {query string}
+	type output struct {status int; body string}
+	type testCase struct {
+		title	   string
+		input	   input
+		output	   output
+		setStorage func(mk *task.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "List the first page of tasks",
+			input: input{query: ""},
+			output: output{
+				status: http.StatusOK,
+				body: `{
+					"message": "succeed to list tasks",
+					"data": [
+						{
+							"id": "1",
+							"owner_id": null,
+							"title": "title",
+							"description": null,
+							"status": "todo",
+							"parent_id": null,
+							"start_at": null,
+							"due_at": null,
+							"created_at": null,
+							"updated_at": null,
+							"labels": [],
+							"deleted_at": null,
+							"recurrence": null,
+							"series_id": null,
+							"occurrence": null,
+							"version": null,
+							"project_id": null,
+							"rank": null,
+							"checklist": [],
+							"progress": {"checked": 0, "total": 0}
+						}
+					],
+					"next": "cursor"
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("List", "p1", &task.Query{Cursor: "", Size: 0}).
+					Return(&task.Page{
+						Tasks: []*task.Task{
+							{
+								ID: optional.Some("1"),
+								Title: optional.Some("title"),
+								Description: optional.None[string](),
+								Status: optional.Some(task.StatusTodo),
+							},
+						},
+						Next: optional.Some("cursor"),
+					}, nil)
+			},
+		},
+		{
+			title: "List the last page of tasks",
+			input: input{query: "?cursor=cursor&size=10"},
+			output: output{
+				status: http.StatusOK,
+				body: `{
+					"message": "succeed to list tasks",
+					"data": [],
+					"next": null
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("List", "p1", &task.Query{Cursor: "cursor", Size: 10}).
+					Return(&task.Page{Tasks: []*task.Task{}, Next: optional.None[string]()}, nil)
+			},
+		},
+
+		// failed cases
+		{
+			title: "Failed to list tasks: invalid size",
+			input: input{query: "?size=ten"},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{
+					"data": null,
+					"message": "failed to list tasks: invalid size \"ten\""
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "List filtered and sorted tasks",
+			input: input{query: "?status=todo&title[contains]=report&sort=-title"},
+			output: output{
+				status: http.StatusOK,
+				body: `{
+					"message": "succeed to list tasks",
+					"data": [],
+					"next": null
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("List", "p1", &task.Query{
+						Filter: task.And{Filters: []task.Filter{
+							task.Condition{Field: task.FieldStatus, Operator: task.OperatorEq, Value: "todo"},
+							task.Condition{Field: task.FieldTitle, Operator: task.OperatorContains, Value: "report"},
+						}},
+						Sort: task.Sort{Field: task.FieldTitle, Desc: true},
+					}).
+					Return(&task.Page{Tasks: []*task.Task{}, Next: optional.None[string]()}, nil)
+			},
+		},
+		{
+			title: "Failed to list tasks: unknown filter field",
+			input: input{query: "?owner=me"},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{
+					"data": null,
+					"message": "failed to list tasks: query unknown field: owner"
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to list tasks: unknown filter operator",
+			input: input{query: "?title[regex]=.*"},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{
+					"data": null,
+					"message": "failed to list tasks: query unknown operator: regex for title"
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to list tasks: invalid query",
+			input: input{query: "?cursor=invalid"},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{
+					"data": null,
+					"message": "failed to list tasks: invalid query"
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("List", "p1", &task.Query{Cursor: "invalid", Size: 0}).
+					Return(&task.Page{}, task.ErrStorageInvalidQuery)
+			},
+		},
+		{
+			title: "Failed to list tasks: internal error",
+			input: input{query: ""},
+			output: output{
+				status: http.StatusInternalServerError,
+				body: `{
+					"data": null,
+					"message": "internal error"
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("List", "p1", &task.Query{Cursor: "", Size: 0}).
+					Return(&task.Page{}, task.ErrStorageInternal)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := task.NewStorageMock()
+			c.setStorage(st)
+
+			cl := NewTaskController(st)
+			hd := cl.List()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/tasks"+c.input.query, nil)
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			hd(w, r)
+
 			// assert
 			assert.Equal(t, c.output.status, w.Code)
 			assert.JSONEq(t, c.output.body, w.Body.String())
@@ -171,7 +551,7 @@ func TestHandlerTask_Create(t *testing.T) {
 					body := strings.NewReader(`{
 						"title": "title",
 						"description": "description",
-						"completed": false
+						"status": "todo"
 					}`)
 					r.Body = io.NopCloser(body)
 				},
@@ -182,9 +562,25 @@ func TestHandlerTask_Create(t *testing.T) {
 					"message": "succeed to create task",
 					"data": {
 						"id": "1",
+						"owner_id": "p1",
 						"title": "title",
 						"description": "description",
-						"completed": false
+						"status": "todo",
+						"parent_id": null,
+						"start_at": null,
+						"due_at": null,
+						"created_at": null,
+						"updated_at": null,
+						"labels": [],
+						"deleted_at": null,
+						"recurrence": null,
+						"series_id": null,
+						"occurrence": null,
+						"version": null,
+						"project_id": null,
+						"rank": null,
+						"checklist": [],
+						"progress": {"checked": 0, "total": 0}
 					}
 				}`,
 			},
@@ -195,38 +591,81 @@ func TestHandlerTask_Create(t *testing.T) {
 				mk.
 					On("Save", &task.Task{
 						ID: optional.None[string](),
+						OwnerID: optional.Some("p1"),
 						Title: optional.Some("title"),
 						Description: optional.Some("description"),
-						Completed: optional.Some(false),
+						Status: optional.Some(task.StatusTodo),
 					}).
 					Return(nil)
 			},
 		},
 
-		// failed cases
 		{
-			title: "Failed to create a task: decoder",
+			title: "Create a recurring task",
 			input: input{
 				setW: func(w *httptest.ResponseRecorder) {},
 				setR: func(r *http.Request) {
 					// base
 					r.Method = http.MethodPost
 					r.URL.Path = "/tasks"
-					body := strings.NewReader(`{wrong decoder}`)
+					body := strings.NewReader(`{
+						"title": "title",
+						"status": "todo",
+						"due_at": "2023-01-02T09:00:00Z",
+						"recurrence": "FREQ=WEEKLY;BYDAY=MO"
+					}`)
 					r.Body = io.NopCloser(body)
 				},
 			},
 			output: output{
-				status: http.StatusBadRequest,
+				status: http.StatusCreated,
 				body: `{
-					"data": null,
-					"message": "failed to create task: invalid request"
+					"message": "succeed to create task",
+					"data": {
+						"id": "1",
+						"owner_id": "p1",
+						"title": "title",
+						"description": null,
+						"status": "todo",
+						"parent_id": null,
+						"start_at": null,
+						"due_at": "2023-01-02T09:00:00Z",
+						"created_at": null,
+						"updated_at": null,
+						"labels": [],
+						"deleted_at": null,
+						"recurrence": "FREQ=WEEKLY;BYDAY=MO",
+						"series_id": "1",
+						"occurrence": 1,
+						"version": null,
+						"project_id": null,
+						"rank": null,
+						"checklist": [],
+						"progress": {"checked": 0, "total": 0}
+					}
 				}`,
 			},
-			setStorage: func(mk *task.StorageMock) {},
+			setStorage: func(mk *task.StorageMock) {
+				mk.SetTask = func(t *task.Task) {
+					t.ID = optional.Some("1")
+					t.SeriesID = optional.Some("1")
+					t.Occurrence = optional.Some(1)
+				}
+				mk.
+					On("Save", &task.Task{
+						ID: optional.None[string](),
+						OwnerID: optional.Some("p1"),
+						Title: optional.Some("title"),
+						Status: optional.Some(task.StatusTodo),
+						DueAt: optional.Some(time.Date(2023, 1, 2, 9, 0, 0, 0, time.UTC)),
+						Recurrence: optional.Some("FREQ=WEEKLY;BYDAY=MO"),
+					}).
+					Return(nil)
+			},
 		},
+
 		{
-			title: "Failed to create a task: validator",
+			title: "Create a task with completed (compatibility)",
 			input: input{
 				setW: func(w *httptest.ResponseRecorder) {},
 				setR: func(r *http.Request) {
@@ -234,33 +673,56 @@ func TestHandlerTask_Create(t *testing.T) {
 					r.Method = http.MethodPost
 					r.URL.Path = "/tasks"
 					body := strings.NewReader(`{
-						"title": null,
-						"description": null,
-						"completed": null
+						"title": "title",
+						"completed": true
 					}`)
 					r.Body = io.NopCloser(body)
 				},
 			},
 			output: output{
-				status: http.StatusUnprocessableEntity,
+				status: http.StatusCreated,
 				body: `{
-					"data": null,
-					"message": "failed to create task: invalid task"
+					"message": "succeed to create task",
+					"data": {
+						"id": "1",
+						"owner_id": "p1",
+						"title": "title",
+						"description": null,
+						"status": "done",
+						"parent_id": null,
+						"start_at": null,
+						"due_at": null,
+						"created_at": null,
+						"updated_at": null,
+						"labels": [],
+						"deleted_at": null,
+						"recurrence": null,
+						"series_id": null,
+						"occurrence": null,
+						"version": null,
+						"project_id": null,
+						"rank": null,
+						"checklist": [],
+						"progress": {"checked": 0, "total": 0}
+					}
 				}`,
 			},
 			setStorage: func(mk *task.StorageMock) {
+				mk.SetTask = func(t *task.Task) {
+					t.ID = optional.Some("1")
+				}
 				mk.
 					On("Save", &task.Task{
 						ID: optional.None[string](),
-						Title: optional.None[string](),
-						Description: optional.None[string](),
-						Completed: optional.None[bool](),
+						OwnerID: optional.Some("p1"),
+						Title: optional.Some("title"),
+						Status: optional.Some(task.StatusDone),
 					}).
-					Return(task.ErrStorageInvalid)
+					Return(nil)
 			},
 		},
 		{
-			title: "Failed to create a task: internal error",
+			title: "Create a task with dates and labels",
 			input: input{
 				setW: func(w *httptest.ResponseRecorder) {},
 				setR: func(r *http.Request) {
@@ -269,26 +731,151 @@ func TestHandlerTask_Create(t *testing.T) {
 					r.URL.Path = "/tasks"
 					body := strings.NewReader(`{
 						"title": "title",
-						"description": "description",
-						"completed": false
+						"status": "todo",
+						"parent_id": null,
+						"start_at": "2023-01-01T00:00:00Z",
+						"due_at": "2023-01-02T00:00:00Z",
+						"labels": [" Backend"]
 					}`)
 					r.Body = io.NopCloser(body)
 				},
 			},
 			output: output{
-				status: http.StatusInternalServerError,
+				status: http.StatusCreated,
 				body: `{
-					"data": null,
-					"message": "internal error"
-				}`,
-			},
+					"message": "succeed to create task",
+					"data": {
+						"id": "1",
+						"owner_id": "p1",
+						"title": "title",
+						"description": null,
+						"status": "todo",
+						"parent_id": null,
+						"start_at": "2023-01-01T00:00:00Z",
+						"due_at": "2023-01-02T00:00:00Z",
+						"created_at": "2023-01-01T12:00:00Z",
+						"updated_at": "2023-01-01T12:00:00Z",
+						"labels": ["backend"],
+						"deleted_at": null,
+						"recurrence": null,
+						"series_id": null,
+						"occurrence": null,
+						"version": null,
+						"project_id": null,
+						"rank": null,
+						"checklist": [],
+						"progress": {"checked": 0, "total": 0}
+					}
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.SetTask = func(t *task.Task) {
+					t.ID = optional.Some("1")
+					t.CreatedAt = optional.Some(time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC))
+					t.UpdatedAt = optional.Some(time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC))
+				}
+				mk.
+					On("Save", &task.Task{
+						ID: optional.None[string](),
+						OwnerID: optional.Some("p1"),
+						Title: optional.Some("title"),
+						Status: optional.Some(task.StatusTodo),
+						StartAt: optional.Some(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)),
+						DueAt: optional.Some(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)),
+						Labels: []string{"backend"},
+					}).
+					Return(nil)
+			},
+		},
+
+		// failed cases
+		{
+			title: "Failed to create a task: decoder",
+			input: input{
+				setW: func(w *httptest.ResponseRecorder) {},
+				setR: func(r *http.Request) {
+					// base
+					r.Method = http.MethodPost
+					r.URL.Path = "/tasks"
+					body := strings.NewReader(`{wrong decoder}`)
+					r.Body = io.NopCloser(body)
+				},
+			},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{
+					"data": null,
+					"message": "failed to create task: invalid request"
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to create a task: validator",
+			input: input{
+				setW: func(w *httptest.ResponseRecorder) {},
+				setR: func(r *http.Request) {
+					// base
+					r.Method = http.MethodPost
+					r.URL.Path = "/tasks"
+					body := strings.NewReader(`{
+						"title": null,
+						"description": null,
+						"status": null
+					}`)
+					r.Body = io.NopCloser(body)
+				},
+			},
+			output: output{
+				status: http.StatusUnprocessableEntity,
+				body: `{
+					"data": null,
+					"message": "failed to create task: invalid task"
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("Save", &task.Task{
+						ID: optional.None[string](),
+						OwnerID: optional.Some("p1"),
+						Title: optional.None[string](),
+						Description: optional.None[string](),
+						Status: optional.None[task.Status](),
+					}).
+					Return(task.ErrStorageInvalid)
+			},
+		},
+		{
+			title: "Failed to create a task: internal error",
+			input: input{
+				setW: func(w *httptest.ResponseRecorder) {},
+				setR: func(r *http.Request) {
+					// base
+					r.Method = http.MethodPost
+					r.URL.Path = "/tasks"
+					body := strings.NewReader(`{
+						"title": "title",
+						"description": "description",
+						"status": "todo"
+					}`)
+					r.Body = io.NopCloser(body)
+				},
+			},
+			output: output{
+				status: http.StatusInternalServerError,
+				body: `{
+					"data": null,
+					"message": "internal error"
+				}`,
+			},
 			setStorage: func(mk *task.StorageMock) {
 				mk.
 					On("Save", &task.Task{
 						ID: optional.None[string](),
+						OwnerID: optional.Some("p1"),
 						Title: optional.Some("title"),
 						Description: optional.Some("description"),
-						Completed: optional.Some(false),
+						Status: optional.Some(task.StatusTodo),
 					}).
 					Return(task.ErrStorageInternal)
 			},
@@ -308,6 +895,8 @@ func TestHandlerTask_Create(t *testing.T) {
 			// act
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodPost, "/tasks", nil)
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
 			c.input.setR(r)
 			hd(w, r)
 
@@ -317,4 +906,2519 @@ func TestHandlerTask_Create(t *testing.T) {
 			st.AssertExpectations(t)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestHandlerTask_Update(t *testing.T) {
+	type input struct {id string; ifMatch string; body string}
+	type output struct {status int; body string; etag string}
+	type testCase struct {
+		title	   string
+		input	   input
+		output	   output
+		setStorage func(mk *task.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "Update a task",
+			input: input{
+				id: "1",
+				ifMatch: `"3"`,
+				body: `{
+					"title": "title",
+					"description": null,
+					"status": "done"
+				}`,
+			},
+			output: output{
+				status: http.StatusOK,
+				body: `{
+					"message": "succeed to update task",
+					"data": {
+						"id": "1",
+						"owner_id": null,
+						"title": "title",
+						"description": null,
+						"status": "done",
+						"parent_id": null,
+						"start_at": null,
+						"due_at": null,
+						"created_at": null,
+						"updated_at": null,
+						"labels": [],
+						"deleted_at": null,
+						"recurrence": null,
+						"series_id": null,
+						"occurrence": null,
+						"version": 3,
+						"project_id": null,
+						"rank": null,
+						"checklist": [],
+						"progress": {"checked": 0, "total": 0}
+					}
+				}`,
+				etag: `"3"`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("Update", "p1", &task.Task{
+						ID: optional.Some("1"),
+						Version: optional.Some(3),
+						Title: optional.Some("title"),
+						Description: optional.None[string](),
+						Status: optional.Some(task.StatusDone),
+					}).
+					Return(nil)
+			},
+		},
+
+		// failed cases
+		{
+			title: "Failed to update a task: if-match required",
+			input: input{id: "1", body: `{"title": "title", "status": "done"}`},
+			output: output{
+				status: http.StatusPreconditionRequired,
+				body: `{
+					"data": null,
+					"message": "failed to update task: if-match required"
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to update a task: invalid if-match",
+			input: input{id: "1", ifMatch: `"abc"`, body: `{"title": "title", "status": "done"}`},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{
+					"data": null,
+					"message": "failed to update task: invalid if-match"
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to update a task: version mismatch",
+			input: input{id: "1", ifMatch: `"2"`, body: `{"title": "title", "status": "done"}`},
+			output: output{
+				status: http.StatusPreconditionFailed,
+				body: `{
+					"data": null,
+					"message": "failed to update task: version mismatch"
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("Update", "p1", mock.Anything).
+					Return(task.ErrStorageVersionMismatch)
+			},
+		},
+		{
+			title: "Failed to update a task: decoder",
+			input: input{id: "1", ifMatch: "*", body: `{wrong decoder}`},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{
+					"data": null,
+					"message": "failed to update task: invalid request"
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to update a task: not found",
+			input: input{id: "1", ifMatch: "*", body: `{"title": "title", "status": "done"}`},
+			output: output{
+				status: http.StatusNotFound,
+				body: `{
+					"data": null,
+					"message": "failed to update task: not found"
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("Update", "p1", mock.Anything).
+					Return(task.ErrStorageNotFound)
+			},
+		},
+		{
+			title: "Failed to update a task: forbidden",
+			input: input{id: "1", ifMatch: "*", body: `{"title": "title", "status": "todo"}`},
+			output: output{
+				status: http.StatusForbidden,
+				body: `{
+					"data": null,
+					"message": "failed to update task: forbidden"
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("Update", "p1", mock.Anything).
+					Return(task.ErrStorageForbidden)
+			},
+		},
+		{
+			title: "Failed to update a task: validator",
+			input: input{id: "1", ifMatch: "*", body: `{"title": null, "status": "done"}`},
+			output: output{
+				status: http.StatusUnprocessableEntity,
+				body: `{
+					"data": null,
+					"message": "failed to update task: invalid task"
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("Update", "p1", mock.Anything).
+					Return(task.ErrStorageInvalid)
+			},
+		},
+		{
+			title: "Failed to update a task: cycle",
+			input: input{id: "1", ifMatch: "*", body: `{"title": "title", "status": "todo", "parent_id": "2"}`},
+			output: output{
+				status: http.StatusConflict,
+				body: `{
+					"data": null,
+					"message": "failed to update task: cycle"
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("Update", "p1", mock.Anything).
+					Return(task.ErrStorageCycle)
+			},
+		},
+		{
+			title: "Failed to update a task: illegal transition",
+			input: input{id: "1", ifMatch: "*", body: `{"title": "title", "status": "done"}`},
+			output: output{
+				status: http.StatusConflict,
+				body: `{
+					"data": null,
+					"message": "failed to update task: illegal transition"
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("Update", "p1", mock.Anything).
+					Return(task.ErrStorageTransition)
+			},
+		},
+		{
+			title: "Failed to update a task: internal error",
+			input: input{id: "1", ifMatch: "*", body: `{"title": "title", "status": "done"}`},
+			output: output{
+				status: http.StatusInternalServerError,
+				body: `{
+					"data": null,
+					"message": "internal error"
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("Update", "p1", mock.Anything).
+					Return(task.ErrStorageInternal)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := task.NewStorageMock()
+			c.setStorage(st)
+
+			cl := NewTaskController(st)
+			hd := cl.Update()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPut, "/tasks/"+c.input.id, strings.NewReader(c.input.body))
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			if c.input.ifMatch != "" {
+				r.Header.Set("If-Match", c.input.ifMatch)
+			}
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("id", c.input.id)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chiCtx))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			assert.Equal(t, c.output.etag, w.Header().Get("ETag"))
+			st.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerTask_Patch(t *testing.T) {
+	type input struct {id string; ifMatch string; body string}
+	type output struct {status int; body string; etag string}
+	type testCase struct {
+		title	   string
+		input	   input
+		output	   output
+		setStorage func(mk *task.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "Patch a task: absent, null and valued fields",
+			input: input{
+				id: "1",
+				ifMatch: `"2"`,
+				body: `{
+					"description": null,
+					"status": "done"
+				}`,
+			},
+			output: output{
+				status: http.StatusOK,
+				body: `{
+					"message": "succeed to patch task",
+					"data": {
+						"id": "1",
+						"owner_id": null,
+						"title": "title",
+						"description": null,
+						"status": "done",
+						"parent_id": null,
+						"start_at": null,
+						"due_at": null,
+						"created_at": null,
+						"updated_at": null,
+						"labels": [],
+						"deleted_at": null,
+						"recurrence": null,
+						"series_id": null,
+						"occurrence": null,
+						"version": 2,
+						"project_id": null,
+						"rank": null,
+						"checklist": [],
+						"progress": {"checked": 0, "total": 0}
+					}
+				}`,
+				etag: `"2"`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("Get", "p1", "1").
+					Return(&task.Task{
+						ID: optional.Some("1"),
+						Title: optional.Some("title"),
+						Description: optional.Some("description"),
+						Status: optional.Some(task.StatusTodo),
+						Version: optional.Some(2),
+					}, nil)
+				mk.
+					On("Update", "p1", &task.Task{
+						ID: optional.Some("1"),
+						Version: optional.Some(2),
+						Title: optional.Some("title"),
+						Description: optional.None[string](),
+						Status: optional.Some(task.StatusDone),
+					}).
+					Return(nil)
+			},
+		},
+
+		// failed cases
+		{
+			title: "Failed to patch a task: decoder",
+			input: input{id: "1", ifMatch: "*", body: `{wrong decoder}`},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{
+					"data": null,
+					"message": "failed to patch task: invalid request"
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to patch a task: unknown field",
+			input: input{id: "1", ifMatch: "*", body: `{"unknown": true}`},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{
+					"data": null,
+					"message": "failed to patch task: invalid request"
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to patch a task: invalid field type",
+			input: input{id: "1", ifMatch: "*", body: `{"status": true}`},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{
+					"data": null,
+					"message": "failed to patch task: invalid request"
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to patch a task: not found",
+			input: input{id: "1", ifMatch: "*", body: `{"status": "done"}`},
+			output: output{
+				status: http.StatusNotFound,
+				body: `{
+					"data": null,
+					"message": "failed to patch task: not found"
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("Get", "p1", "1").
+					Return(&task.Task{}, task.ErrStorageNotFound)
+			},
+		},
+		{
+			title: "Failed to patch a task: validator",
+			input: input{id: "1", ifMatch: "*", body: `{"title": null}`},
+			output: output{
+				status: http.StatusUnprocessableEntity,
+				body: `{
+					"data": null,
+					"message": "failed to patch task: invalid task"
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("Get", "p1", "1").
+					Return(&task.Task{ID: optional.Some("1"), Title: optional.Some("title")}, nil)
+				mk.
+					On("Update", "p1", &task.Task{ID: optional.Some("1"), Title: optional.None[string]()}).
+					Return(task.ErrStorageInvalid)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := task.NewStorageMock()
+			c.setStorage(st)
+
+			cl := NewTaskController(st)
+			hd := cl.Patch()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPatch, "/tasks/"+c.input.id, strings.NewReader(c.input.body))
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			if c.input.ifMatch != "" {
+				r.Header.Set("If-Match", c.input.ifMatch)
+			}
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("id", c.input.id)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chiCtx))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			assert.Equal(t, c.output.etag, w.Header().Get("ETag"))
+			st.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerTask_Transition(t *testing.T) {
+	type input struct {id string; ifMatch string; body string}
+	type output struct {status int; body string; etag string}
+	type testCase struct {
+		title	   string
+		input	   input
+		output	   output
+		setStorage func(mk *task.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "Transition a task",
+			input: input{id: "1", ifMatch: "*", body: `{"status": "in_progress"}`},
+			output: output{
+				status: http.StatusOK,
+				body: `{
+					"message": "succeed to transition task",
+					"data": {
+						"id": "1",
+						"owner_id": null,
+						"title": "title",
+						"description": null,
+						"status": "in_progress",
+						"parent_id": null,
+						"start_at": null,
+						"due_at": null,
+						"created_at": null,
+						"updated_at": null,
+						"labels": [],
+						"deleted_at": null,
+						"recurrence": null,
+						"series_id": null,
+						"occurrence": null,
+						"version": null,
+						"project_id": null,
+						"rank": null,
+						"checklist": [],
+						"progress": {"checked": 0, "total": 0}
+					}
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("Get", "p1", "1").
+					Return(&task.Task{ID: optional.Some("1"), Title: optional.Some("title"), Status: optional.Some(task.StatusTodo)}, nil)
+				mk.
+					On("Update", "p1", &task.Task{ID: optional.Some("1"), Title: optional.Some("title"), Status: optional.Some(task.StatusInProgress)}).
+					Return(nil)
+			},
+		},
+
+		// failed cases
+		{
+			title: "Failed to transition a task: version mismatch",
+			input: input{id: "1", ifMatch: `"1"`, body: `{"status": "done"}`},
+			output: output{
+				status: http.StatusPreconditionFailed,
+				body: `{"data": null, "message": "failed to transition task: version mismatch"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("Get", "p1", "1").
+					Return(&task.Task{ID: optional.Some("1"), Status: optional.Some(task.StatusTodo), Version: optional.Some(2)}, nil)
+				mk.
+					On("Update", "p1", &task.Task{ID: optional.Some("1"), Status: optional.Some(task.StatusDone), Version: optional.Some(1)}).
+					Return(task.ErrStorageVersionMismatch)
+			},
+		},
+		{
+			title: "Failed to transition a task: invalid request",
+			input: input{id: "1", ifMatch: "*", body: `{"status": 1}`},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{"data": null, "message": "failed to transition task: invalid request"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to transition a task: not found",
+			input: input{id: "1", ifMatch: "*", body: `{"status": "done"}`},
+			output: output{
+				status: http.StatusNotFound,
+				body: `{"data": null, "message": "failed to transition task: not found"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("Get", "p1", "1").
+					Return(&task.Task{}, task.ErrStorageNotFound)
+			},
+		},
+		{
+			title: "Failed to transition a task: illegal transition",
+			input: input{id: "1", ifMatch: "*", body: `{"status": "done"}`},
+			output: output{
+				status: http.StatusConflict,
+				body: `{"data": null, "message": "failed to transition task: illegal transition"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("Get", "p1", "1").
+					Return(&task.Task{ID: optional.Some("1"), Title: optional.Some("title"), Status: optional.Some(task.StatusArchived)}, nil)
+				mk.
+					On("Update", "p1", mock.Anything).
+					Return(task.ErrStorageTransition)
+			},
+		},
+		{
+			title: "Failed to transition a task: open blockers",
+			input: input{id: "1", ifMatch: "*", body: `{"status": "done"}`},
+			output: output{
+				status: http.StatusUnprocessableEntity,
+				body: `{"data": null, "message": "failed to transition task: invalid task"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("Get", "p1", "1").
+					Return(&task.Task{ID: optional.Some("1"), Title: optional.Some("title"), Status: optional.Some(task.StatusTodo)}, nil)
+				mk.
+					On("Update", "p1", mock.Anything).
+					Return(task.ErrStorageOpenBlockers)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := task.NewStorageMock()
+			c.setStorage(st)
+
+			cl := NewTaskController(st)
+			hd := cl.Transition()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/tasks/"+c.input.id+"/transitions", strings.NewReader(c.input.body))
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			if c.input.ifMatch != "" {
+				r.Header.Set("If-Match", c.input.ifMatch)
+			}
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("id", c.input.id)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chiCtx))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			assert.Equal(t, c.output.etag, w.Header().Get("ETag"))
+			st.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerTask_Trash(t *testing.T) {
+	type output struct {status int; body string}
+	type testCase struct {
+		title	   string
+		output	   output
+		setStorage func(mk *task.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "List the trash",
+			output: output{
+				status: http.StatusOK,
+				body: `{
+					"message": "succeed to list tasks",
+					"data": [
+						{
+							"id": "1",
+							"owner_id": null,
+							"title": "title",
+							"description": null,
+							"status": "todo",
+							"parent_id": null,
+							"start_at": null,
+							"due_at": null,
+							"created_at": null,
+							"updated_at": null,
+							"labels": [],
+							"deleted_at": "2023-01-01T00:00:00Z",
+							"recurrence": null,
+							"series_id": null,
+							"occurrence": null,
+							"version": null,
+							"project_id": null,
+							"rank": null,
+							"checklist": [],
+							"progress": {"checked": 0, "total": 0}
+						}
+					],
+					"next": null
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("List", "p1", &task.Query{Deleted: true}).
+					Return(&task.Page{
+						Tasks: []*task.Task{
+							{
+								ID: optional.Some("1"),
+								Title: optional.Some("title"),
+								Status: optional.Some(task.StatusTodo),
+								DeletedAt: optional.Some(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)),
+							},
+						},
+						Next: optional.None[string](),
+					}, nil)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := task.NewStorageMock()
+			c.setStorage(st)
+
+			cl := NewTaskController(st)
+			hd := cl.Trash()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/tasks/trash", nil)
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			st.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerTask_Views(t *testing.T) {
+	type input struct {view func(cl *Task) http.HandlerFunc; url string}
+	type output struct {status int; body string}
+	type testCase struct {
+		title	   string
+		input	   input
+		output	   output
+		setStorage func(mk *task.StorageMock)
+	}
+
+	// 2023-01-02 01:30 UTC is still 2023-01-01 in Buenos Aires (UTC-3)
+	now := time.Date(2023, 1, 2, 1, 30, 0, 0, time.UTC)
+	page := &task.Page{Tasks: []*task.Task{}, Next: optional.None[string]()}
+	notDone := task.Condition{Field: task.FieldStatus, Operator: task.OperatorNe, Value: "done"}
+	notArchived := task.Condition{Field: task.FieldStatus, Operator: task.OperatorNe, Value: "archived"}
+	body := `{"message": "succeed to list tasks", "data": [], "next": null}`
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "List the overdue tasks",
+			input: input{view: (*Task).Overdue, url: "/tasks/overdue"},
+			output: output{status: http.StatusOK, body: body},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("List", "p1", &task.Query{
+						Filter: task.And{Filters: []task.Filter{
+							task.Condition{Field: task.FieldDueAt, Operator: task.OperatorLt, Value: now},
+							notDone,
+							notArchived,
+						}},
+						Sort: task.Sort{Field: task.FieldDueAt},
+					}).
+					Return(page, nil)
+			},
+		},
+		{
+			title: "List the tasks due today in a time zone",
+			input: input{view: (*Task).DueToday, url: "/tasks/due-today?tz=America/Argentina/Buenos_Aires"},
+			output: output{status: http.StatusOK, body: body},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("List", "p1", &task.Query{
+						Filter: task.And{Filters: []task.Filter{
+							task.Condition{Field: task.FieldDueAt, Operator: task.OperatorGte, Value: time.Date(2023, 1, 1, 3, 0, 0, 0, time.UTC)},
+							task.Condition{Field: task.FieldDueAt, Operator: task.OperatorLt, Value: time.Date(2023, 1, 2, 3, 0, 0, 0, time.UTC)},
+							notDone,
+							notArchived,
+						}},
+						Sort: task.Sort{Field: task.FieldDueAt},
+					}).
+					Return(page, nil)
+			},
+		},
+		{
+			title: "List the upcoming tasks with a filter and a sort",
+			input: input{view: (*Task).Upcoming, url: "/tasks/upcoming?days=3&title[contains]=report&sort=-due_at"},
+			output: output{status: http.StatusOK, body: body},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("List", "p1", &task.Query{
+						Filter: task.And{Filters: []task.Filter{
+							task.And{Filters: []task.Filter{
+								task.Condition{Field: task.FieldTitle, Operator: task.OperatorContains, Value: "report"},
+							}},
+							task.Condition{Field: task.FieldDueAt, Operator: task.OperatorGte, Value: time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)},
+							task.Condition{Field: task.FieldDueAt, Operator: task.OperatorLt, Value: time.Date(2023, 1, 6, 0, 0, 0, 0, time.UTC)},
+							notDone,
+							notArchived,
+						}},
+						Sort: task.Sort{Field: task.FieldDueAt, Desc: true},
+					}).
+					Return(page, nil)
+			},
+		},
+
+		// failed cases
+		{
+			title: "Failed to list the tasks due today: invalid time zone",
+			input: input{view: (*Task).DueToday, url: "/tasks/due-today?tz=Mars/Olympus"},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{"data": null, "message": "failed to list tasks: invalid time zone \"Mars/Olympus\""}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to list the upcoming tasks: invalid days",
+			input: input{view: (*Task).Upcoming, url: "/tasks/upcoming?days=0"},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{"data": null, "message": "failed to list tasks: invalid days \"0\""}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := task.NewStorageMock()
+			c.setStorage(st)
+
+			cl := NewTaskController(st)
+			cl.now = func() time.Time { return now }
+			hd := c.input.view(cl)
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, c.input.url, nil)
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			st.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerTask_Delete(t *testing.T) {
+	type input struct {id string; ifMatch string}
+	type output struct {status int; body string}
+	type testCase struct {
+		title	   string
+		input	   input
+		output	   output
+		setStorage func(mk *task.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "Delete a task",
+			input: input{id: "1", ifMatch: "*"},
+			output: output{
+				status: http.StatusOK,
+				body: `{"data": null, "message": "succeed to delete task"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("Delete", "p1", "1", optional.None[int]()).Return(nil)
+			},
+		},
+		{
+			title: "Delete a task with the version",
+			input: input{id: "1", ifMatch: `"2"`},
+			output: output{
+				status: http.StatusOK,
+				body: `{"data": null, "message": "succeed to delete task"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("Delete", "p1", "1", optional.Some(2)).Return(nil)
+			},
+		},
+
+		// failed cases
+		{
+			title: "Failed to delete a task: not found",
+			input: input{id: "1", ifMatch: "*"},
+			output: output{
+				status: http.StatusNotFound,
+				body: `{"data": null, "message": "failed to delete task: not found"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("Delete", "p1", "1", optional.None[int]()).Return(task.ErrStorageNotFound)
+			},
+		},
+		{
+			title: "Failed to delete a task: internal error",
+			input: input{id: "1", ifMatch: "*"},
+			output: output{
+				status: http.StatusInternalServerError,
+				body: `{"data": null, "message": "internal error"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("Delete", "p1", "1", optional.None[int]()).Return(task.ErrStorageInternal)
+			},
+		},
+		{
+			title: "Failed to delete a task: if-match required",
+			input: input{id: "1"},
+			output: output{
+				status: http.StatusPreconditionRequired,
+				body: `{"data": null, "message": "failed to delete task: if-match required"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to delete a task: version mismatch",
+			input: input{id: "1", ifMatch: `"2"`},
+			output: output{
+				status: http.StatusPreconditionFailed,
+				body: `{"data": null, "message": "failed to delete task: version mismatch"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("Delete", "p1", "1", optional.Some(2)).Return(task.ErrStorageVersionMismatch)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := task.NewStorageMock()
+			c.setStorage(st)
+
+			cl := NewTaskController(st)
+			hd := cl.Delete()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodDelete, "/tasks/"+c.input.id, nil)
+			// -> profile (set by the profile mapping middleware)
+			if c.input.ifMatch != "" {
+				r.Header.Set("If-Match", c.input.ifMatch)
+			}
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("id", c.input.id)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chiCtx))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			st.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerTask_Restore(t *testing.T) {
+	type input struct {id string; ifMatch string}
+	type output struct {status int; body string}
+	type testCase struct {
+		title	   string
+		input	   input
+		output	   output
+		setStorage func(mk *task.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "Restore a task",
+			input: input{id: "1", ifMatch: "*"},
+			output: output{
+				status: http.StatusOK,
+				body: `{"data": null, "message": "succeed to restore task"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("Restore", "p1", "1", optional.None[int]()).Return(nil)
+			},
+		},
+		{
+			title: "Restore a task with the version",
+			input: input{id: "1", ifMatch: `"2"`},
+			output: output{
+				status: http.StatusOK,
+				body: `{"data": null, "message": "succeed to restore task"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("Restore", "p1", "1", optional.Some(2)).Return(nil)
+			},
+		},
+
+		// failed cases
+		{
+			title: "Failed to restore a task: not found in trash",
+			input: input{id: "1", ifMatch: "*"},
+			output: output{
+				status: http.StatusNotFound,
+				body: `{"data": null, "message": "failed to restore task: not found in trash"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("Restore", "p1", "1", optional.None[int]()).Return(task.ErrStorageNotFound)
+			},
+		},
+		{
+			title: "Failed to restore a task: if-match required",
+			input: input{id: "1"},
+			output: output{
+				status: http.StatusPreconditionRequired,
+				body: `{"data": null, "message": "failed to restore task: if-match required"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to restore a task: version mismatch",
+			input: input{id: "1", ifMatch: `"2"`},
+			output: output{
+				status: http.StatusPreconditionFailed,
+				body: `{"data": null, "message": "failed to restore task: version mismatch"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("Restore", "p1", "1", optional.Some(2)).Return(task.ErrStorageVersionMismatch)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := task.NewStorageMock()
+			c.setStorage(st)
+
+			cl := NewTaskController(st)
+			hd := cl.Restore()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/tasks/"+c.input.id+"/restore", nil)
+			// -> profile (set by the profile mapping middleware)
+			if c.input.ifMatch != "" {
+				r.Header.Set("If-Match", c.input.ifMatch)
+			}
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("id", c.input.id)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chiCtx))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			st.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerTask_Move(t *testing.T) {
+	type input struct {id string; ifMatch string; body string}
+	type output struct {status int; body string; etag string}
+	type testCase struct {
+		title	   string
+		input	   input
+		output	   output
+		setStorage func(mk *task.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "Move a task between two tasks",
+			input: input{id: "1", ifMatch: `"1"`, body: `{"after_id": "2", "before_id": "3"}`},
+			output: output{
+				status: http.StatusOK,
+				body: `{
+					"message": "succeed to move task",
+					"data": {
+						"id": "1", "owner_id": "p1", "title": "title", "description": null, "status": "todo", "parent_id": null,
+						"start_at": null, "due_at": null, "created_at": null, "updated_at": null, "labels": [], "deleted_at": null,
+						"recurrence": null, "series_id": null, "occurrence": null, "version": 2, "project_id": null, "rank": "d", "checklist": [], "progress": {"checked": 0, "total": 0}
+					}
+				}`,
+				etag: `"2"`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("Move", "p1", "1", "2", "3", optional.Some(1)).Return(nil)
+				mk.On("Get", "p1", "1").Return(&task.Task{
+					ID: optional.Some("1"),
+					OwnerID: optional.Some("p1"),
+					Title: optional.Some("title"),
+					Status: optional.Some(task.StatusTodo),
+					Version: optional.Some(2),
+					Rank: optional.Some("d"),
+				}, nil)
+			},
+		},
+
+		// failed cases
+		{
+			title: "Failed to move a task: invalid request",
+			input: input{id: "1", ifMatch: "*", body: `{"after_id": 2}`},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{"data": null, "message": "failed to move task: invalid request"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to move a task: no neighbours",
+			input: input{id: "1", ifMatch: "*", body: `{}`},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{"data": null, "message": "failed to move task: invalid request"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to move a task: if-match required",
+			input: input{id: "1", body: `{"after_id": "2"}`},
+			output: output{
+				status: http.StatusPreconditionRequired,
+				body: `{"data": null, "message": "failed to move task: if-match required"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to move a task: version mismatch",
+			input: input{id: "1", ifMatch: `"1"`, body: `{"after_id": "2"}`},
+			output: output{
+				status: http.StatusPreconditionFailed,
+				body: `{"data": null, "message": "failed to move task: version mismatch"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("Move", "p1", "1", "2", "", optional.Some(1)).Return(task.ErrStorageVersionMismatch)
+			},
+		},
+		{
+			title: "Failed to move a task: not found",
+			input: input{id: "1", ifMatch: "*", body: `{"after_id": "2"}`},
+			output: output{
+				status: http.StatusNotFound,
+				body: `{"data": null, "message": "failed to move task: not found"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("Move", "p1", "1", "2", "", optional.None[int]()).Return(task.ErrStorageNotFound)
+			},
+		},
+		{
+			title: "Failed to move a task: invalid neighbours",
+			input: input{id: "1", ifMatch: "*", body: `{"before_id": "4"}`},
+			output: output{
+				status: http.StatusUnprocessableEntity,
+				body: `{"data": null, "message": "failed to move task: invalid neighbours"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("Move", "p1", "1", "", "4", optional.None[int]()).Return(task.ErrStorageInvalid)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := task.NewStorageMock()
+			c.setStorage(st)
+
+			cl := NewTaskController(st)
+			hd := cl.Move()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/tasks/"+c.input.id+"/move", strings.NewReader(c.input.body))
+			if c.input.ifMatch != "" {
+				r.Header.Set("If-Match", c.input.ifMatch)
+			}
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("id", c.input.id)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chiCtx))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			assert.Equal(t, c.output.etag, w.Header().Get("ETag"))
+			st.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerTask_AddItem(t *testing.T) {
+	type input struct {id string; ifMatch string; body string}
+	type output struct {status int; body string}
+	type testCase struct {
+		title	   string
+		input	   input
+		output	   output
+		setStorage func(mk *task.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "Add an item",
+			input: input{id: "1", ifMatch: "*", body: `{"text": " buy milk "}`},
+			output: output{
+				status: http.StatusCreated,
+				body: `{"data": {"id": "a", "text": "buy milk", "checked": false}, "message": "succeed to add item"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("AddItem", "p1", "1", &task.Item{Text: "buy milk"}, optional.None[int]()).Return(nil).Run(func(args mock.Arguments) {
+					args.Get(2).(*task.Item).ID = "a"
+				})
+			},
+		},
+
+		{
+			title: "Add an item to a task with the version",
+			input: input{id: "1", ifMatch: `"2"`, body: `{"text": "buy milk"}`},
+			output: output{
+				status: http.StatusCreated,
+				body: `{"data": {"id": "a", "text": "buy milk", "checked": false}, "message": "succeed to add item"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("AddItem", "p1", "1", &task.Item{Text: "buy milk"}, optional.Some(2)).Return(nil).Run(func(args mock.Arguments) {
+					args.Get(2).(*task.Item).ID = "a"
+				})
+			},
+		},
+		// failed cases
+		{
+			title: "Failed to add an item: invalid request",
+			input: input{id: "1", ifMatch: "*", body: `{"text": 1}`},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{"data": null, "message": "failed to add item: invalid request"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to add an item: not found",
+			input: input{id: "1", ifMatch: "*", body: `{"text": "buy milk"}`},
+			output: output{
+				status: http.StatusNotFound,
+				body: `{"data": null, "message": "failed to add item: not found"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("AddItem", "p1", "1", mock.Anything, optional.None[int]()).Return(task.ErrStorageNotFound)
+			},
+		},
+		{
+			title: "Failed to add an item: forbidden",
+			input: input{id: "1", ifMatch: "*", body: `{"text": "buy milk"}`},
+			output: output{
+				status: http.StatusForbidden,
+				body: `{"data": null, "message": "failed to add item: forbidden"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("AddItem", "p1", "1", mock.Anything, optional.None[int]()).Return(task.ErrStorageForbidden)
+			},
+		},
+		{
+			title: "Failed to add an item: invalid item",
+			input: input{id: "1", ifMatch: "*", body: `{"text": ""}`},
+			output: output{
+				status: http.StatusUnprocessableEntity,
+				body: `{"data": null, "message": "failed to add item: invalid item"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("AddItem", "p1", "1", mock.Anything, optional.None[int]()).Return(task.ErrStorageInvalid)
+			},
+		},
+		{
+			title: "Failed to add an item: if-match required",
+			input: input{id: "1", body: `{"text": "buy milk"}`},
+			output: output{
+				status: http.StatusPreconditionRequired,
+				body: `{"data": null, "message": "failed to add item: if-match required"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to add an item: version mismatch",
+			input: input{id: "1", ifMatch: `"2"`, body: `{"text": "buy milk"}`},
+			output: output{
+				status: http.StatusPreconditionFailed,
+				body: `{"data": null, "message": "failed to add item: version mismatch"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("AddItem", "p1", "1", mock.Anything, optional.Some(2)).Return(task.ErrStorageVersionMismatch)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := task.NewStorageMock()
+			c.setStorage(st)
+
+			cl := NewTaskController(st)
+			hd := cl.AddItem()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/tasks/"+c.input.id+"/checklist", strings.NewReader(c.input.body))
+			if c.input.ifMatch != "" {
+				r.Header.Set("If-Match", c.input.ifMatch)
+			}
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("id", c.input.id)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chiCtx))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			st.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerTask_EditItem(t *testing.T) {
+	type input struct {method string; path string; ifMatch string; body string}
+	type output struct {status int; body string}
+	type testCase struct {
+		title	   string
+		input	   input
+		output	   output
+		handler	   func(ct *Task) http.HandlerFunc
+		setStorage func(mk *task.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "Check an item",
+			input: input{method: http.MethodPatch, path: "/tasks/1/checklist/a", ifMatch: "*", body: `{"checked": true}`},
+			output: output{
+				status: http.StatusOK,
+				body: `{"data": null, "message": "succeed to check item"}`,
+			},
+			handler: (*Task).CheckItem,
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("CheckItem", "p1", "1", "a", true, optional.None[int]()).Return(nil)
+			},
+		},
+		{
+			title: "Move an item",
+			input: input{method: http.MethodPost, path: "/tasks/1/checklist/a/move", ifMatch: "*", body: `{"position": 0}`},
+			output: output{
+				status: http.StatusOK,
+				body: `{"data": null, "message": "succeed to move item"}`,
+			},
+			handler: (*Task).MoveItem,
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("MoveItem", "p1", "1", "a", 0, optional.None[int]()).Return(nil)
+			},
+		},
+		{
+			title: "Remove an item",
+			input: input{method: http.MethodDelete, path: "/tasks/1/checklist/a", ifMatch: "*"},
+			output: output{
+				status: http.StatusOK,
+				body: `{"data": null, "message": "succeed to remove item"}`,
+			},
+			handler: (*Task).RemoveItem,
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("RemoveItem", "p1", "1", "a", optional.None[int]()).Return(nil)
+			},
+		},
+
+		{
+			title: "Check an item of a task with the version",
+			input: input{method: http.MethodPatch, path: "/tasks/1/checklist/a", ifMatch: `"2"`, body: `{"checked": true}`},
+			output: output{
+				status: http.StatusOK,
+				body: `{"data": null, "message": "succeed to check item"}`,
+			},
+			handler: (*Task).CheckItem,
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("CheckItem", "p1", "1", "a", true, optional.Some(2)).Return(nil)
+			},
+		},
+		// failed cases
+		{
+			title: "Failed to check an item: checked required",
+			input: input{method: http.MethodPatch, path: "/tasks/1/checklist/a", ifMatch: "*", body: `{}`},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{"data": null, "message": "failed to check item: invalid request"}`,
+			},
+			handler: (*Task).CheckItem,
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to check an item: not found",
+			input: input{method: http.MethodPatch, path: "/tasks/1/checklist/a", ifMatch: "*", body: `{"checked": false}`},
+			output: output{
+				status: http.StatusNotFound,
+				body: `{"data": null, "message": "failed to check item: not found"}`,
+			},
+			handler: (*Task).CheckItem,
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("CheckItem", "p1", "1", "a", false, optional.None[int]()).Return(task.ErrStorageNotFound)
+			},
+		},
+		{
+			title: "Failed to move an item: position required",
+			input: input{method: http.MethodPost, path: "/tasks/1/checklist/a/move", ifMatch: "*", body: `{}`},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{"data": null, "message": "failed to move item: invalid request"}`,
+			},
+			handler: (*Task).MoveItem,
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to move an item: invalid position",
+			input: input{method: http.MethodPost, path: "/tasks/1/checklist/a/move", ifMatch: "*", body: `{"position": 5}`},
+			output: output{
+				status: http.StatusUnprocessableEntity,
+				body: `{"data": null, "message": "failed to move item: invalid position"}`,
+			},
+			handler: (*Task).MoveItem,
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("MoveItem", "p1", "1", "a", 5, optional.None[int]()).Return(task.ErrStorageInvalid)
+			},
+		},
+		{
+			title: "Failed to remove an item: forbidden",
+			input: input{method: http.MethodDelete, path: "/tasks/1/checklist/a", ifMatch: "*"},
+			output: output{
+				status: http.StatusForbidden,
+				body: `{"data": null, "message": "failed to remove item: forbidden"}`,
+			},
+			handler: (*Task).RemoveItem,
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("RemoveItem", "p1", "1", "a", optional.None[int]()).Return(task.ErrStorageForbidden)
+			},
+		},
+		{
+			title: "Failed to check an item: if-match required",
+			input: input{method: http.MethodPatch, path: "/tasks/1/checklist/a", body: `{"checked": true}`},
+			output: output{
+				status: http.StatusPreconditionRequired,
+				body: `{"data": null, "message": "failed to check item: if-match required"}`,
+			},
+			handler: (*Task).CheckItem,
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to check an item: version mismatch",
+			input: input{method: http.MethodPatch, path: "/tasks/1/checklist/a", ifMatch: `"2"`, body: `{"checked": true}`},
+			output: output{
+				status: http.StatusPreconditionFailed,
+				body: `{"data": null, "message": "failed to check item: version mismatch"}`,
+			},
+			handler: (*Task).CheckItem,
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("CheckItem", "p1", "1", "a", true, optional.Some(2)).Return(task.ErrStorageVersionMismatch)
+			},
+		},
+		{
+			title: "Failed to move an item: if-match required",
+			input: input{method: http.MethodPost, path: "/tasks/1/checklist/a/move", body: `{"position": 0}`},
+			output: output{
+				status: http.StatusPreconditionRequired,
+				body: `{"data": null, "message": "failed to move item: if-match required"}`,
+			},
+			handler: (*Task).MoveItem,
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to move an item: version mismatch",
+			input: input{method: http.MethodPost, path: "/tasks/1/checklist/a/move", ifMatch: `"2"`, body: `{"position": 0}`},
+			output: output{
+				status: http.StatusPreconditionFailed,
+				body: `{"data": null, "message": "failed to move item: version mismatch"}`,
+			},
+			handler: (*Task).MoveItem,
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("MoveItem", "p1", "1", "a", 0, optional.Some(2)).Return(task.ErrStorageVersionMismatch)
+			},
+		},
+		{
+			title: "Failed to remove an item: if-match required",
+			input: input{method: http.MethodDelete, path: "/tasks/1/checklist/a"},
+			output: output{
+				status: http.StatusPreconditionRequired,
+				body: `{"data": null, "message": "failed to remove item: if-match required"}`,
+			},
+			handler: (*Task).RemoveItem,
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to remove an item: version mismatch",
+			input: input{method: http.MethodDelete, path: "/tasks/1/checklist/a", ifMatch: `"2"`},
+			output: output{
+				status: http.StatusPreconditionFailed,
+				body: `{"data": null, "message": "failed to remove item: version mismatch"}`,
+			},
+			handler: (*Task).RemoveItem,
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("RemoveItem", "p1", "1", "a", optional.Some(2)).Return(task.ErrStorageVersionMismatch)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := task.NewStorageMock()
+			c.setStorage(st)
+
+			cl := NewTaskController(st)
+			hd := c.handler(cl)
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(c.input.method, c.input.path, strings.NewReader(c.input.body))
+			if c.input.ifMatch != "" {
+				r.Header.Set("If-Match", c.input.ifMatch)
+			}
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("id", "1")
+			chiCtx.URLParams.Add("item_id", "a")
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chiCtx))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			st.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerTask_AddLabel(t *testing.T) {
+	type input struct {id string; ifMatch string; body string}
+	type output struct {status int; body string}
+	type testCase struct {
+		title	   string
+		input	   input
+		output	   output
+		setStorage func(mk *task.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "Add a label (normalized)",
+			input: input{id: "1", ifMatch: "*", body: `{"label": " Backend "}`},
+			output: output{
+				status: http.StatusOK,
+				body: `{"data": null, "message": "succeed to add label"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("AddLabel", "p1", "1", "backend", optional.None[int]()).Return(nil)
+			},
+		},
+
+		{
+			title: "Add a label to a task with the version",
+			input: input{id: "1", ifMatch: `"2"`, body: `{"label": "backend"}`},
+			output: output{
+				status: http.StatusOK,
+				body: `{"data": null, "message": "succeed to add label"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("AddLabel", "p1", "1", "backend", optional.Some(2)).Return(nil)
+			},
+		},
+		// failed cases
+		{
+			title: "Failed to add a label: invalid request",
+			input: input{id: "1", ifMatch: "*", body: `{"label": 1}`},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{"data": null, "message": "failed to add label: invalid request"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to add a label: not found",
+			input: input{id: "1", ifMatch: "*", body: `{"label": "backend"}`},
+			output: output{
+				status: http.StatusNotFound,
+				body: `{"data": null, "message": "failed to add label: not found"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("AddLabel", "p1", "1", "backend", optional.None[int]()).Return(task.ErrStorageNotFound)
+			},
+		},
+		{
+			title: "Failed to add a label: invalid label",
+			input: input{id: "1", ifMatch: "*", body: `{"label": "a,b"}`},
+			output: output{
+				status: http.StatusUnprocessableEntity,
+				body: `{"data": null, "message": "failed to add label: invalid label"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("AddLabel", "p1", "1", "a,b", optional.None[int]()).Return(task.ErrStorageInvalid)
+			},
+		},
+		{
+			title: "Failed to add a label: if-match required",
+			input: input{id: "1", body: `{"label": "backend"}`},
+			output: output{
+				status: http.StatusPreconditionRequired,
+				body: `{"data": null, "message": "failed to add label: if-match required"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to add a label: invalid if-match",
+			input: input{id: "1", ifMatch: `"abc"`, body: `{"label": "backend"}`},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{"data": null, "message": "failed to add label: invalid if-match"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to add a label: version mismatch",
+			input: input{id: "1", ifMatch: `"2"`, body: `{"label": "backend"}`},
+			output: output{
+				status: http.StatusPreconditionFailed,
+				body: `{"data": null, "message": "failed to add label: version mismatch"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("AddLabel", "p1", "1", "backend", optional.Some(2)).Return(task.ErrStorageVersionMismatch)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := task.NewStorageMock()
+			c.setStorage(st)
+
+			cl := NewTaskController(st)
+			hd := cl.AddLabel()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/tasks/"+c.input.id+"/labels", strings.NewReader(c.input.body))
+			if c.input.ifMatch != "" {
+				r.Header.Set("If-Match", c.input.ifMatch)
+			}
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("id", c.input.id)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chiCtx))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			st.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerTask_RemoveLabel(t *testing.T) {
+	type input struct {id string; label string; ifMatch string}
+	type output struct {status int; body string}
+	type testCase struct {
+		title	   string
+		input	   input
+		output	   output
+		setStorage func(mk *task.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "Remove a label",
+			input: input{id: "1", label: "backend", ifMatch: "*"},
+			output: output{
+				status: http.StatusOK,
+				body: `{"data": null, "message": "succeed to remove label"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("RemoveLabel", "p1", "1", "backend", optional.None[int]()).Return(nil)
+			},
+		},
+
+		{
+			title: "Remove a label from a task with the version",
+			input: input{id: "1", label: "backend", ifMatch: `"2"`},
+			output: output{
+				status: http.StatusOK,
+				body: `{"data": null, "message": "succeed to remove label"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("RemoveLabel", "p1", "1", "backend", optional.Some(2)).Return(nil)
+			},
+		},
+		// failed cases
+		{
+			title: "Failed to remove a label: not found",
+			input: input{id: "1", label: "backend", ifMatch: "*"},
+			output: output{
+				status: http.StatusNotFound,
+				body: `{"data": null, "message": "failed to remove label: not found"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("RemoveLabel", "p1", "1", "backend", optional.None[int]()).Return(task.ErrStorageNotFound)
+			},
+		},
+		{
+			title: "Failed to remove a label: if-match required",
+			input: input{id: "1", label: "backend"},
+			output: output{
+				status: http.StatusPreconditionRequired,
+				body: `{"data": null, "message": "failed to remove label: if-match required"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to remove a label: version mismatch",
+			input: input{id: "1", label: "backend", ifMatch: `"2"`},
+			output: output{
+				status: http.StatusPreconditionFailed,
+				body: `{"data": null, "message": "failed to remove label: version mismatch"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("RemoveLabel", "p1", "1", "backend", optional.Some(2)).Return(task.ErrStorageVersionMismatch)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := task.NewStorageMock()
+			c.setStorage(st)
+
+			cl := NewTaskController(st)
+			hd := cl.RemoveLabel()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodDelete, "/tasks/"+c.input.id+"/labels/"+c.input.label, nil)
+			if c.input.ifMatch != "" {
+				r.Header.Set("If-Match", c.input.ifMatch)
+			}
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("id", c.input.id)
+			chiCtx.URLParams.Add("label", c.input.label)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chiCtx))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			st.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerTask_Labels(t *testing.T) {
+	type output struct {status int; body string}
+	type testCase struct {
+		title	   string
+		output	   output
+		setStorage func(mk *task.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "List the labels",
+			output: output{
+				status: http.StatusOK,
+				body: `{
+					"message": "succeed to list labels",
+					"data": [
+						{"name": "backend", "tasks": 2},
+						{"name": "urgent", "tasks": 1}
+					]
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("Labels", "p1").Return([]*task.Label{{Name: "backend", Tasks: 2}, {Name: "urgent", Tasks: 1}}, nil)
+			},
+		},
+
+		// failed cases
+		{
+			title: "Failed to list the labels: internal error",
+			output: output{
+				status: http.StatusInternalServerError,
+				body: `{"data": null, "message": "internal error"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("Labels", "p1").Return([]*task.Label(nil), task.ErrStorageInternal)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := task.NewStorageMock()
+			c.setStorage(st)
+
+			cl := NewTaskController(st)
+			hd := cl.Labels()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/labels", nil)
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			st.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerTask_AddDependency(t *testing.T) {
+	type input struct {id string; body string}
+	type output struct {status int; body string}
+	type testCase struct {
+		title	   string
+		input	   input
+		output	   output
+		setStorage func(mk *task.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "Add a dependency",
+			input: input{id: "1", body: `{"blocker_id": "2"}`},
+			output: output{
+				status: http.StatusOK,
+				body: `{"data": null, "message": "succeed to add dependency"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("AddDependency", "p1", "1", "2").Return(nil)
+			},
+		},
+
+		// failed cases
+		{
+			title: "Failed to add a dependency: invalid request",
+			input: input{id: "1", body: `{"blocker_id": 2}`},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{"data": null, "message": "failed to add dependency: invalid request"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to add a dependency: not found",
+			input: input{id: "1", body: `{"blocker_id": "2"}`},
+			output: output{
+				status: http.StatusNotFound,
+				body: `{"data": null, "message": "failed to add dependency: not found"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("AddDependency", "p1", "1", "2").Return(task.ErrStorageNotFound)
+			},
+		},
+		{
+			title: "Failed to add a dependency: cycle",
+			input: input{id: "1", body: `{"blocker_id": "2"}`},
+			output: output{
+				status: http.StatusConflict,
+				body: `{"data": null, "message": "failed to add dependency: cycle"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("AddDependency", "p1", "1", "2").Return(task.ErrStorageCycle)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := task.NewStorageMock()
+			c.setStorage(st)
+
+			cl := NewTaskController(st)
+			hd := cl.AddDependency()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/tasks/"+c.input.id+"/dependencies", strings.NewReader(c.input.body))
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("id", c.input.id)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chiCtx))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			st.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerTask_RemoveDependency(t *testing.T) {
+	type input struct {id string; blockerId string}
+	type output struct {status int; body string}
+	type testCase struct {
+		title	   string
+		input	   input
+		output	   output
+		setStorage func(mk *task.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "Remove a dependency",
+			input: input{id: "1", blockerId: "2"},
+			output: output{
+				status: http.StatusOK,
+				body: `{"data": null, "message": "succeed to remove dependency"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("RemoveDependency", "p1", "1", "2").Return(nil)
+			},
+		},
+
+		// failed cases
+		{
+			title: "Failed to remove a dependency: not found",
+			input: input{id: "1", blockerId: "2"},
+			output: output{
+				status: http.StatusNotFound,
+				body: `{"data": null, "message": "failed to remove dependency: not found"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("RemoveDependency", "p1", "1", "2").Return(task.ErrStorageNotFound)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := task.NewStorageMock()
+			c.setStorage(st)
+
+			cl := NewTaskController(st)
+			hd := cl.RemoveDependency()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodDelete, "/tasks/"+c.input.id+"/dependencies/"+c.input.blockerId, nil)
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("id", c.input.id)
+			chiCtx.URLParams.Add("blocker_id", c.input.blockerId)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chiCtx))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			st.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerTask_Order(t *testing.T) {
+	type input struct {query string}
+	type output struct {status int; body string}
+	type testCase struct {
+		title	   string
+		input	   input
+		output	   output
+		setStorage func(mk *task.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "Order tasks",
+			input: input{query: "id=1&id=2"},
+			output: output{
+				status: http.StatusOK,
+				body: `{
+					"message": "succeed to order tasks",
+					"data": [
+						{"id": "2", "owner_id": null, "title": "b", "description": null, "status": "todo", "parent_id": null, "start_at": null, "due_at": null, "created_at": null, "updated_at": null, "labels": [], "deleted_at": null, "recurrence": null, "series_id": null, "occurrence": null, "version": null, "project_id": null, "rank": null, "checklist": [], "progress": {"checked": 0, "total": 0}},
+						{"id": "1", "owner_id": null, "title": "a", "description": null, "status": "todo", "parent_id": null, "start_at": null, "due_at": null, "created_at": null, "updated_at": null, "labels": [], "deleted_at": null, "recurrence": null, "series_id": null, "occurrence": null, "version": null, "project_id": null, "rank": null, "checklist": [], "progress": {"checked": 0, "total": 0}}
+					]
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("Order", "p1", []string{"1", "2"}).Return([]*task.Task{
+					{ID: optional.Some("2"), Title: optional.Some("b"), Status: optional.Some(task.StatusTodo)},
+					{ID: optional.Some("1"), Title: optional.Some("a"), Status: optional.Some(task.StatusTodo)},
+				}, nil)
+			},
+		},
+
+		// failed cases
+		{
+			title: "Failed to order tasks: no ids",
+			input: input{query: ""},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{"data": null, "message": "failed to order tasks: between 1 and 100 ids are required"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to order tasks: not found",
+			input: input{query: "id=1&id=3"},
+			output: output{
+				status: http.StatusNotFound,
+				body: `{"data": null, "message": "failed to order tasks: not found"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("Order", "p1", []string{"1", "3"}).Return([]*task.Task(nil), task.ErrStorageNotFound)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := task.NewStorageMock()
+			c.setStorage(st)
+
+			cl := NewTaskController(st)
+			hd := cl.Order()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/tasks/order?"+c.input.query, nil)
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			st.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerTask_Shared(t *testing.T) {
+	type output struct {status int; body string}
+	type testCase struct {
+		title	   string
+		output	   output
+		setStorage func(mk *task.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "List the tasks shared with the profile",
+			output: output{
+				status: http.StatusOK,
+				body: `{
+					"message": "succeed to list tasks",
+					"data": [
+						{"id": "1", "owner_id": "p2", "title": "title", "description": null, "status": "todo", "parent_id": null, "start_at": null, "due_at": null, "created_at": null, "updated_at": null, "labels": [], "deleted_at": null, "recurrence": null, "series_id": null, "occurrence": null, "version": null, "project_id": null, "rank": null, "checklist": [], "progress": {"checked": 0, "total": 0}}
+					],
+					"next": null
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("Shared", "p1", &task.Query{}).
+					Return(&task.Page{
+						Tasks: []*task.Task{{ID: optional.Some("1"), OwnerID: optional.Some("p2"), Title: optional.Some("title"), Status: optional.Some(task.StatusTodo)}},
+						Next: optional.None[string](),
+					}, nil)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := task.NewStorageMock()
+			c.setStorage(st)
+
+			cl := NewTaskController(st)
+			hd := cl.Shared()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/tasks/shared", nil)
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			st.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerTask_Grant(t *testing.T) {
+	type input struct {id string; body string}
+	type output struct {status int; body string}
+	type testCase struct {
+		title	   string
+		input	   input
+		output	   output
+		setStorage func(mk *task.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "Share a task",
+			input: input{id: "1", body: `{"profile_id": "p2", "permission": "edit"}`},
+			output: output{
+				status: http.StatusOK,
+				body: `{"data": {"profile_id": "p2", "permission": "edit"}, "message": "succeed to grant access"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("Grant", "p1", "1", &task.Grant{ProfileID: "p2", Permission: task.PermissionEdit}).Return(nil)
+			},
+		},
+
+		// failed cases
+		{
+			title: "Failed to share a task: invalid request",
+			input: input{id: "1", body: `{"profile_id": 2}`},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{"data": null, "message": "failed to grant access: invalid request"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to share a task: not found",
+			input: input{id: "1", body: `{"profile_id": "p2", "permission": "read"}`},
+			output: output{
+				status: http.StatusNotFound,
+				body: `{"data": null, "message": "failed to grant access: not found"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("Grant", "p1", "1", &task.Grant{ProfileID: "p2", Permission: task.PermissionRead}).Return(task.ErrStorageNotFound)
+			},
+		},
+		{
+			title: "Failed to share a task: invalid grant",
+			input: input{id: "1", body: `{"profile_id": "p2", "permission": "admin"}`},
+			output: output{
+				status: http.StatusUnprocessableEntity,
+				body: `{"data": null, "message": "failed to grant access: invalid grant"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("Grant", "p1", "1", &task.Grant{ProfileID: "p2", Permission: "admin"}).Return(task.ErrStorageInvalid)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := task.NewStorageMock()
+			c.setStorage(st)
+
+			cl := NewTaskController(st)
+			hd := cl.Grant()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/tasks/"+c.input.id+"/grants", strings.NewReader(c.input.body))
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("id", c.input.id)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chiCtx))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			st.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerTask_Revoke(t *testing.T) {
+	type input struct {id string; profileId string}
+	type output struct {status int; body string}
+	type testCase struct {
+		title	   string
+		input	   input
+		output	   output
+		setStorage func(mk *task.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "Stop sharing a task",
+			input: input{id: "1", profileId: "p2"},
+			output: output{
+				status: http.StatusOK,
+				body: `{"data": null, "message": "succeed to revoke access"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("Revoke", "p1", "1", "p2").Return(nil)
+			},
+		},
+
+		// failed cases
+		{
+			title: "Failed to stop sharing a task: not found",
+			input: input{id: "1", profileId: "p2"},
+			output: output{
+				status: http.StatusNotFound,
+				body: `{"data": null, "message": "failed to revoke access: not found"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("Revoke", "p1", "1", "p2").Return(task.ErrStorageNotFound)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := task.NewStorageMock()
+			c.setStorage(st)
+
+			cl := NewTaskController(st)
+			hd := cl.Revoke()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodDelete, "/tasks/"+c.input.id+"/grants/"+c.input.profileId, nil)
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("id", c.input.id)
+			chiCtx.URLParams.Add("profile_id", c.input.profileId)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chiCtx))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			st.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerTask_Grants(t *testing.T) {
+	type input struct {id string}
+	type output struct {status int; body string}
+	type testCase struct {
+		title	   string
+		input	   input
+		output	   output
+		setStorage func(mk *task.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "List the grants of a task",
+			input: input{id: "1"},
+			output: output{
+				status: http.StatusOK,
+				body: `{
+					"message": "succeed to list grants",
+					"data": [
+						{"profile_id": "p2", "permission": "edit"},
+						{"profile_id": "p3", "permission": "read"}
+					]
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("Grants", "p1", "1").Return([]*task.Grant{{ProfileID: "p2", Permission: task.PermissionEdit}, {ProfileID: "p3", Permission: task.PermissionRead}}, nil)
+			},
+		},
+
+		// failed cases
+		{
+			title: "Failed to list the grants of a task: not found",
+			input: input{id: "1"},
+			output: output{
+				status: http.StatusNotFound,
+				body: `{"data": null, "message": "failed to list grants: not found"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.On("Grants", "p1", "1").Return([]*task.Grant(nil), task.ErrStorageNotFound)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := task.NewStorageMock()
+			c.setStorage(st)
+
+			cl := NewTaskController(st)
+			hd := cl.Grants()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/tasks/"+c.input.id+"/grants", nil)
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("id", c.input.id)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chiCtx))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			st.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerTask_Batch(t *testing.T) {
+	type input struct {body string}
+	type output struct {status int; body string}
+	type testCase struct {
+		title	   string
+		input	   input
+		output	   output
+		setStorage func(mk *task.StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "Run a batch atomically",
+			input: input{body: `{
+				"operations": [
+					{"op": "create", "task": {"title": "title", "status": "todo"}},
+					{"op": "update", "id": "2", "version": 3, "task": {"title": "new title"}},
+					{"op": "delete", "id": "3", "version": 2}
+				]
+			}`},
+			output: output{
+				status: http.StatusOK,
+				body: `{
+					"message": "succeed to run batch",
+					"data": [
+						{"status": 201, "data": {
+							"id": "1", "owner_id": "p1", "title": "title", "description": null, "status": "todo", "parent_id": null,
+							"start_at": null, "due_at": null, "created_at": null, "updated_at": null, "labels": [], "deleted_at": null,
+							"recurrence": null, "series_id": null, "occurrence": null, "version": 1, "project_id": null, "rank": null, "checklist": [], "progress": {"checked": 0, "total": 0}
+						}, "error": null},
+						{"status": 200, "data": {
+							"id": "2", "owner_id": null, "title": "new title", "description": null, "status": null, "parent_id": null,
+							"start_at": null, "due_at": null, "created_at": null, "updated_at": null, "labels": [], "deleted_at": null,
+							"recurrence": null, "series_id": null, "occurrence": null, "version": 4, "project_id": null, "rank": null, "checklist": [], "progress": {"checked": 0, "total": 0}
+						}, "error": null},
+						{"status": 200, "data": null, "error": null}
+					]
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("Batch", "p1", []*task.Op{
+						{Kind: task.OpCreate, Task: &task.Task{Title: optional.Some("title"), Status: optional.Some(task.StatusTodo)}},
+						{Kind: task.OpUpdate, Task: &task.Task{ID: optional.Some("2"), Title: optional.Some("new title"), Version: optional.Some(3)}},
+						{Kind: task.OpDelete, ID: "3", Version: optional.Some(2)},
+					}, task.ModeAtomic).
+					Return([]*task.Result{
+						{Task: &task.Task{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title"), Status: optional.Some(task.StatusTodo), Version: optional.Some(1)}},
+						{Task: &task.Task{ID: optional.Some("2"), Title: optional.Some("new title"), Version: optional.Some(4)}},
+						{},
+					}, nil)
+			},
+		},
+		{
+			title: "Run a batch with best effort",
+			input: input{body: `{
+				"mode": "best_effort",
+				"operations": [
+					{"op": "delete", "id": "2", "version": 1},
+					{"op": "delete", "id": "3", "version": 1}
+				]
+			}`},
+			output: output{
+				status: http.StatusOK,
+				body: `{
+					"message": "succeed to run batch",
+					"data": [
+						{"status": 404, "data": null, "error": "not found"},
+						{"status": 200, "data": null, "error": null}
+					]
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("Batch", "p1", []*task.Op{{Kind: task.OpDelete, ID: "2", Version: optional.Some(1)}, {Kind: task.OpDelete, ID: "3", Version: optional.Some(1)}}, task.ModeBestEffort).
+					Return([]*task.Result{{Err: task.ErrStorageNotFound}, {}}, nil)
+			},
+		},
+
+		// failed cases
+		{
+			title: "Failed to run batch: aborted",
+			input: input{body: `{
+				"mode": "atomic",
+				"operations": [
+					{"op": "delete", "id": "2", "version": 1},
+					{"op": "update", "id": "3", "version": 1, "task": {"title": "title"}}
+				]
+			}`},
+			output: output{
+				status: http.StatusUnprocessableEntity,
+				body: `{
+					"message": "failed to run batch: aborted",
+					"data": [
+						{"status": 424, "data": null, "error": "aborted"},
+						{"status": 412, "data": null, "error": "version mismatch"}
+					]
+				}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("Batch", "p1", mock.Anything, task.ModeAtomic).
+					Return([]*task.Result{{Err: task.ErrStorageBatchAborted}, {Err: task.ErrStorageVersionMismatch}}, task.ErrStorageBatchAborted)
+			},
+		},
+		{
+			title: "Failed to run batch: invalid request",
+			input: input{body: `{"operations": {}}`},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{"data": null, "message": "failed to run batch: invalid request"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to run batch: no operations",
+			input: input{body: `{"operations": []}`},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{"data": null, "message": "failed to run batch: invalid number of operations"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to run batch: invalid mode",
+			input: input{body: `{"mode": "eventually", "operations": [{"op": "delete", "id": "2"}]}`},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{"data": null, "message": "failed to run batch: invalid mode"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to run batch: update without id",
+			input: input{body: `{"operations": [{"op": "delete", "id": "2", "version": 1}, {"op": "update", "task": {"title": "title"}}]}`},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{"data": null, "message": "failed to run batch: invalid operation 1"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to run batch: update without version",
+			input: input{body: `{"operations": [{"op": "create", "task": {"title": "title"}}, {"op": "update", "id": "2", "task": {"title": "title"}}]}`},
+			output: output{
+				status: http.StatusPreconditionRequired,
+				body: `{"data": null, "message": "failed to run batch: version required on operation 1"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to run batch: delete without version",
+			input: input{body: `{"operations": [{"op": "delete", "id": "2"}]}`},
+			output: output{
+				status: http.StatusPreconditionRequired,
+				body: `{"data": null, "message": "failed to run batch: version required on operation 0"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to run batch: unknown operation",
+			input: input{body: `{"operations": [{"op": "restore", "id": "2"}]}`},
+			output: output{
+				status: http.StatusBadRequest,
+				body: `{"data": null, "message": "failed to run batch: invalid operation 0"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {},
+		},
+		{
+			title: "Failed to run batch: internal error",
+			input: input{body: `{"operations": [{"op": "delete", "id": "2", "version": 1}]}`},
+			output: output{
+				status: http.StatusInternalServerError,
+				body: `{"data": null, "message": "internal error"}`,
+			},
+			setStorage: func(mk *task.StorageMock) {
+				mk.
+					On("Batch", "p1", mock.Anything, task.ModeAtomic).
+					Return([]*task.Result{{Err: task.ErrStorageBatchAborted}}, task.ErrStorageInternal)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := task.NewStorageMock()
+			c.setStorage(st)
+
+			cl := NewTaskController(st)
+			hd := cl.Batch()
+
+			// act
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/tasks:batch", strings.NewReader(c.input.body))
+			// -> profile (set by the profile mapping middleware)
+			r = r.WithContext(context.WithValue(r.Context(), contexter.KeyProfileId, "p1"))
+			hd(w, r)
+
+			// assert
+			assert.Equal(t, c.output.status, w.Code)
+			assert.JSONEq(t, c.output.body, w.Body.String())
+			st.AssertExpectations(t)
+		})
+	}
+}
@@ -2,9 +2,18 @@ package main
 
 import (
 	"api/cmd/rest/application"
+	"api/internal/profiles/mapper"
+	"api/internal/profiles/storage"
+	"api/internal/profiles/validator"
+	"api/pkg/mysql/transactioner"
+	"api/pkg/sqlite"
+	"database/sql"
+	"os"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-sql-driver/mysql"
 	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
 )
 
 func main() {
@@ -13,8 +22,62 @@ func main() {
 		panic(err)
 	}
 	
+	// database
+	// -> an embedded SQLite database if a path is given, PostgreSQL if a dsn is given, MySQL otherwise
+	// -> the tasks are kept in the SQLite or PostgreSQL database too, in TASK_STORAGE_DIR (or in memory) with MySQL
+	var profileMapper mapper.ProfileMapper
+	var profilesStorage storage.ProfilesStorage
+	var taskDatabase *sql.DB
+	var taskDatabaseDriver string
+	vl := validator.NewImplProfilesValidatorDefault(nil)
+	if path := os.Getenv("SQLITE_PATH"); path != "" {
+		db, err := sqlite.Open(path)
+		if err != nil {
+			panic(err)
+		}
+		defer db.Close()
+
+		profileMapper, err = mapper.NewProfileMapperSQLite(db)
+		if err != nil {
+			panic(err)
+		}
+		st, err := storage.NewImplProfilesStorageSQLite(db)
+		if err != nil {
+			panic(err)
+		}
+		profilesStorage = storage.NewImplProfilesStorageValidator(st, vl)
+		taskDatabase, taskDatabaseDriver = db, "sqlite"
+	} else if dsn := os.Getenv("POSTGRES_DSN"); dsn != "" {
+		db, err := sql.Open("postgres", dsn)
+		if err != nil {
+			panic(err)
+		}
+		defer db.Close()
+
+		profileMapper = mapper.NewProfileMapperPostgres(db)
+		profilesStorage = storage.NewImplProfilesStorageValidator(storage.NewImplProfilesStoragePostgres(db), vl)
+		taskDatabase, taskDatabaseDriver = db, "postgres"
+	} else {
+		db, err := openMySQL()
+		if err != nil {
+			panic(err)
+		}
+		defer db.Close()
+
+		profileMapper = mapper.NewProfileMapperMySQL(db)
+		profilesStorage = storage.NewImplProfilesStorageValidator(
+			storage.NewImplProfilesStorageMySQLTx(storage.NewImplProfilesStorageMySQL(db), transactioner.NewImplTransactionerDefault(db)),
+			vl,
+		)
+	}
+
 	// app
 	config := application.NewConfigDefault()
+	config.ProfileMapper = profileMapper
+	config.ProfilesStorage = profilesStorage
+	config.TaskStorageDir = os.Getenv("TASK_STORAGE_DIR")
+	config.TaskDatabase = taskDatabase
+	config.TaskDatabaseDriver = taskDatabaseDriver
 	router := chi.NewRouter()
 
 	app := application.NewApp(config, router)
@@ -26,4 +89,18 @@ func main() {
 	if err := app.Run(); err != nil {
 		panic(err)
 	}
-}
\ No newline at end of file
+}
+
+// openMySQL opens the MySQL database of the environment.
+func openMySQL() (db *sql.DB, err error) {
+	dsn := mysql.Config{
+		User: 	os.Getenv("MYSQL_USER"),
+		Passwd: os.Getenv("MYSQL_PASSWORD"),
+		Net: 	"tcp",
+		Addr: 	os.Getenv("MYSQL_ADDR"),
+		DBName: os.Getenv("MYSQL_DATABASE"),
+		ParseTime: true,
+	}
+	db, err = sql.Open("mysql", dsn.FormatDSN())
+	return
+}
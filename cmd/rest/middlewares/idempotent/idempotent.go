@@ -0,0 +1,161 @@
+package idempotent
+
+import (
+	"api/internal/idempotency"
+	"api/internal/profiles/contexter"
+	"api/pkg/web"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// MaxKeyLength is the maximum length of an idempotency key.
+const MaxKeyLength = 255
+
+// Caller returns who makes the request (the idempotency keys are scoped to it).
+type Caller func(r *http.Request) string
+
+// CallerProfile returns the profile of the request (set by the profile mapping middleware).
+func CallerProfile(r *http.Request) string {
+	profileId, _ := r.Context().Value(contexter.KeyProfileId).(string)
+	return profileId
+}
+
+// CallerUser returns the user of the request (the User-Id header).
+func CallerUser(r *http.Request) string {
+	return r.Header.Get("User-Id")
+}
+
+// NewIdempotent returns a new Idempotent
+func NewIdempotent(st idempotency.Store, ttl time.Duration, caller Caller) *Idempotent {
+	return &Idempotent{Store: st, TTL: ttl, Caller: caller, now: time.Now}
+}
+
+// Idempotent is the middleware that makes the retries of a request with the same Idempotency-Key header safe.
+type Idempotent struct {
+	// Store keeps the responses by caller and key
+	Store idempotency.Store
+	// TTL is the time a key is kept since the first request
+	TTL time.Duration
+	// Caller returns who makes the request
+	Caller Caller
+	// now returns the current time
+	now func() time.Time
+}
+
+// Replay is a middleware that stores the first response of the requests with an Idempotency-Key header,
+// and replays it for the retries with the same payload (method, path and body).
+// - a retry with a different payload is rejected with 422, and one made while the first is in progress with 409
+// - a server error is not stored, so the request can be retried
+// - requests without the header are not changed
+type ResponseReplay struct {
+	Message string `json:"message"`
+	Data	any `json:"data"`
+	Error	bool `json:"error"`
+}
+func (id *Idempotent) Replay(hd http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// get idempotency key from headers
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" {
+			hd.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > MaxKeyLength {
+			web.JSON(w, http.StatusBadRequest, &ResponseReplay{Message: "Invalid idempotency key", Data: nil, Error: true})
+			return
+		}
+
+		// fingerprint of the payload
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			web.JSON(w, http.StatusBadRequest, &ResponseReplay{Message: "Invalid request", Data: nil, Error: true})
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := fingerprintOf(r, body)
+
+		// reserve key
+		rc := &idempotency.Record{Caller: id.Caller(r), Key: key, Fingerprint: fingerprint, ExpiresAt: id.now().Add(id.TTL)}
+		err = id.Store.Save(rc)
+		if err != nil {
+			if !errors.Is(err, idempotency.ErrStoreNotUnique) {
+				web.JSON(w, http.StatusInternalServerError, &ResponseReplay{Message: "Internal server error", Data: nil, Error: true})
+				return
+			}
+
+			id.replay(w, rc)
+			return
+		}
+
+		// next
+		rw := &recorder{ResponseWriter: w, status: http.StatusOK}
+		hd.ServeHTTP(rw, r)
+
+		// store response
+		// -> released on a server error, or if the response can not be stored (the retries would find it in progress until it expires)
+		if rw.status >= http.StatusInternalServerError {
+			id.Store.Delete(rc.Caller, rc.Key)
+			return
+		}
+		rc.Status = rw.status
+		rc.Header = w.Header().Clone()
+		rc.Body = rw.body.Bytes()
+		if err := id.Store.Complete(rc); err != nil {
+			id.Store.Delete(rc.Caller, rc.Key)
+		}
+	})
+}
+
+// replay writes the stored response of the key of the record, if it is a retry of the same request.
+func (id *Idempotent) replay(w http.ResponseWriter, rc *idempotency.Record) {
+	stored, err := id.Store.Get(rc.Caller, rc.Key)
+	if err != nil {
+		// -> the key expired or was released meanwhile
+		web.JSON(w, http.StatusConflict, &ResponseReplay{Message: "Request in progress", Data: nil, Error: true})
+		return
+	}
+
+	switch {
+	case stored.Fingerprint != rc.Fingerprint:
+		web.JSON(w, http.StatusUnprocessableEntity, &ResponseReplay{Message: "Idempotency key reused with a different payload", Data: nil, Error: true})
+	case !stored.Done():
+		web.JSON(w, http.StatusConflict, &ResponseReplay{Message: "Request in progress", Data: nil, Error: true})
+	default:
+		for k, vs := range stored.Header {
+			w.Header()[k] = vs
+		}
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.WriteHeader(stored.Status)
+		w.Write(stored.Body)
+	}
+}
+
+// fingerprintOf returns the hash of the method, the path and the body of the request.
+func fingerprintOf(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recorder is a response writer that keeps the status and the body it writes.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rw *recorder) WriteHeader(code int) {
+	rw.status = code
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recorder) Write(b []byte) (int, error) {
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
@@ -0,0 +1,227 @@
+package idempotent
+
+import (
+	"api/internal/idempotency"
+	"api/pkg/httpmock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Tests for Idempotent
+func TestIdempotent_Replay(t *testing.T) {
+	type input struct { key string; body string }
+	type output struct { code int; body string; headers http.Header }
+	type testCase struct {
+		name string
+		input input
+		output output
+		// set-up
+		setUpStoreMock func(mk *idempotency.StoreMock)
+		setUpHandlerMock func(mk *httpmock.HandlerMock)
+	}
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	fingerprint := fingerprintOf(httptest.NewRequest(http.MethodPost, "/tasks", nil), []byte(`{"title":"title"}`))
+	created := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"1"`)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"message":"created","data":null}`))
+	}
+
+	cases := []testCase{
+		// valid case
+		{
+			name: "valid case - request without key",
+			input: input{key: "", body: `{"title":"title"}`},
+			output: output{
+				code: http.StatusCreated,
+				body: `{"message":"created","data":null}`,
+				headers: http.Header{"Etag": []string{`"1"`}},
+			},
+			setUpStoreMock: func(mk *idempotency.StoreMock) {},
+			setUpHandlerMock: func(mk *httpmock.HandlerMock) {
+				(*mk).SetUpServeHTTP = created
+				mk.On("ServeHTTP", mock.Anything, mock.Anything).Return()
+			},
+		},
+		{
+			name: "valid case - first request is stored",
+			input: input{key: "key", body: `{"title":"title"}`},
+			output: output{
+				code: http.StatusCreated,
+				body: `{"message":"created","data":null}`,
+				headers: http.Header{"Etag": []string{`"1"`}},
+			},
+			setUpStoreMock: func(mk *idempotency.StoreMock) {
+				mk.On("Save", &idempotency.Record{Caller: "p1", Key: "key", Fingerprint: fingerprint, ExpiresAt: now.Add(time.Hour)}).Return(nil)
+				mk.On("Complete", &idempotency.Record{
+					Caller: "p1",
+					Key: "key",
+					Fingerprint: fingerprint,
+					Status: http.StatusCreated,
+					Header: http.Header{"Etag": []string{`"1"`}},
+					Body: []byte(`{"message":"created","data":null}`),
+					ExpiresAt: now.Add(time.Hour),
+				}).Return(nil)
+			},
+			setUpHandlerMock: func(mk *httpmock.HandlerMock) {
+				(*mk).SetUpServeHTTP = created
+				mk.On("ServeHTTP", mock.Anything, mock.Anything).Return()
+			},
+		},
+		{
+			name: "valid case - retry replays the stored response",
+			input: input{key: "key", body: `{"title":"title"}`},
+			output: output{
+				code: http.StatusCreated,
+				body: `{"message":"created","data":null}`,
+				headers: http.Header{"Etag": []string{`"1"`}, "Idempotent-Replayed": []string{"true"}},
+			},
+			setUpStoreMock: func(mk *idempotency.StoreMock) {
+				mk.On("Save", mock.Anything).Return(idempotency.ErrStoreNotUnique)
+				mk.On("Get", "p1", "key").Return(&idempotency.Record{
+					Caller: "p1",
+					Key: "key",
+					Fingerprint: fingerprint,
+					Status: http.StatusCreated,
+					Header: http.Header{"Etag": []string{`"1"`}},
+					Body: []byte(`{"message":"created","data":null}`),
+					ExpiresAt: now.Add(time.Hour),
+				}, nil)
+			},
+			setUpHandlerMock: func(mk *httpmock.HandlerMock) {},
+		},
+		{
+			name: "valid case - server error releases the key",
+			input: input{key: "key", body: `{"title":"title"}`},
+			output: output{
+				code: http.StatusInternalServerError,
+				body: `{"message":"internal error","data":null}`,
+				headers: http.Header{},
+			},
+			setUpStoreMock: func(mk *idempotency.StoreMock) {
+				mk.On("Save", mock.Anything).Return(nil)
+				mk.On("Delete", "p1", "key").Return(nil)
+			},
+			setUpHandlerMock: func(mk *httpmock.HandlerMock) {
+				(*mk).SetUpServeHTTP = func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusInternalServerError)
+					w.Write([]byte(`{"message":"internal error","data":null}`))
+				}
+				mk.On("ServeHTTP", mock.Anything, mock.Anything).Return()
+			},
+		},
+
+		// invalid case
+		{
+			name: "invalid case - key reused with a different payload",
+			input: input{key: "key", body: `{"title":"other title"}`},
+			output: output{
+				code: http.StatusUnprocessableEntity,
+				body: `{"message":"Idempotency key reused with a different payload","data":null,"error":true}`,
+				headers: http.Header{"Content-Type": []string{"application/json"}},
+			},
+			setUpStoreMock: func(mk *idempotency.StoreMock) {
+				mk.On("Save", mock.Anything).Return(idempotency.ErrStoreNotUnique)
+				mk.On("Get", "p1", "key").Return(&idempotency.Record{Caller: "p1", Key: "key", Fingerprint: fingerprint, Status: http.StatusCreated, ExpiresAt: now.Add(time.Hour)}, nil)
+			},
+			setUpHandlerMock: func(mk *httpmock.HandlerMock) {},
+		},
+		{
+			name: "invalid case - retry while the request is in progress",
+			input: input{key: "key", body: `{"title":"title"}`},
+			output: output{
+				code: http.StatusConflict,
+				body: `{"message":"Request in progress","data":null,"error":true}`,
+				headers: http.Header{"Content-Type": []string{"application/json"}},
+			},
+			setUpStoreMock: func(mk *idempotency.StoreMock) {
+				mk.On("Save", mock.Anything).Return(idempotency.ErrStoreNotUnique)
+				mk.On("Get", "p1", "key").Return(&idempotency.Record{Caller: "p1", Key: "key", Fingerprint: fingerprint, ExpiresAt: now.Add(time.Hour)}, nil)
+			},
+			setUpHandlerMock: func(mk *httpmock.HandlerMock) {},
+		},
+		{
+			name: "invalid case - key too long",
+			input: input{key: strings.Repeat("k", MaxKeyLength + 1), body: `{"title":"title"}`},
+			output: output{
+				code: http.StatusBadRequest,
+				body: `{"message":"Invalid idempotency key","data":null,"error":true}`,
+				headers: http.Header{"Content-Type": []string{"application/json"}},
+			},
+			setUpStoreMock: func(mk *idempotency.StoreMock) {},
+			setUpHandlerMock: func(mk *httpmock.HandlerMock) {},
+		},
+		{
+			name: "invalid case - response not stored releases the key",
+			input: input{key: "key", body: `{"title":"title"}`},
+			output: output{
+				code: http.StatusCreated,
+				body: `{"message":"created","data":null}`,
+				headers: http.Header{"Etag": []string{`"1"`}},
+			},
+			setUpStoreMock: func(mk *idempotency.StoreMock) {
+				mk.On("Save", mock.Anything).Return(nil)
+				mk.On("Complete", mock.Anything).Return(idempotency.ErrStoreInternal)
+				mk.On("Delete", "p1", "key").Return(nil)
+			},
+			setUpHandlerMock: func(mk *httpmock.HandlerMock) {
+				(*mk).SetUpServeHTTP = created
+				mk.On("ServeHTTP", mock.Anything, mock.Anything).Return()
+			},
+		},
+		{
+			name: "invalid case - store error",
+			input: input{key: "key", body: `{"title":"title"}`},
+			output: output{
+				code: http.StatusInternalServerError,
+				body: `{"message":"Internal server error","data":null,"error":true}`,
+				headers: http.Header{"Content-Type": []string{"application/json"}},
+			},
+			setUpStoreMock: func(mk *idempotency.StoreMock) {
+				mk.On("Save", mock.Anything).Return(idempotency.ErrStoreInternal)
+			},
+			setUpHandlerMock: func(mk *httpmock.HandlerMock) {},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			stMock := idempotency.NewStoreMock()
+			c.setUpStoreMock(stMock)
+
+			impl := NewIdempotent(stMock, time.Hour, func(r *http.Request) string { return "p1" })
+			impl.now = func() time.Time { return now }
+
+			hdMock := httpmock.NewHandlerMock()
+			c.setUpHandlerMock(hdMock)
+
+			md := impl.Replay(hdMock)
+			hd := md.(http.HandlerFunc)
+
+			// act
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(c.input.body))
+			if c.input.key != "" {
+				r.Header.Set("Idempotency-Key", c.input.key)
+			}
+			hd(rr, r)
+
+			// assert
+			assert.Equal(t, c.output.code, rr.Code)
+			assert.JSONEq(t, c.output.body, rr.Body.String())
+			assert.Equal(t, c.output.headers, rr.Header())
+			// -> expectations
+			stMock.AssertExpectations(t)
+			hdMock.AssertExpectations(t)
+		})
+	}
+}
@@ -32,7 +32,8 @@ func (mp *ProfileMapping) MapProfile(hd http.Handler) http.Handler {
 		userId := r.Header.Get("User-Id")
 
 		// map profile
-		profileId, err := mp.ProfileMapper.MapProfile(userId)
+		// -> canceled if the client goes away
+		profileId, err := mp.ProfileMapper.WithContext(r.Context()).MapProfile(userId)
 		if err != nil {
 			var code int; var body *ResponseMapProfile
 			switch {
@@ -25,6 +25,29 @@ func Ok(w http.ResponseWriter, code int, msg string, data any) {
 	json.NewEncoder(w).Encode(resp)
 }
 
+// Page is the response of a paginated collection.
+type Page struct {
+	Message string `json:"message"`
+	Data    any    `json:"data"`
+	Next    any    `json:"next"`
+}
+
+func OkPage(w http.ResponseWriter, code int, msg string, data any, next any) {
+	// set status code
+	w.WriteHeader(code)
+
+	// set headers
+	w.Header().Set("Content-Type", "application/json")
+
+	// set body
+	resp := Page{
+		Message: msg,
+		Data:    data,
+		Next:    next,
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
 func Err(w http.ResponseWriter, code int, msg string) {
 	// set status code
 	w.WriteHeader(code)
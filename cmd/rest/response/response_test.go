@@ -63,6 +63,63 @@ func TestResponse_Ok(t *testing.T) {
 	}
 }
 
+func TestResponse_OkPage(t *testing.T) {
+	type input struct {code int; msg string; data any; next any}
+	type output struct {code int; header string; response string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+	}
+
+	cases := []testCase{
+		{
+			title: "response with next page",
+			input: input{
+				code: 200,
+				msg:  "ok",
+				data: []string{"data"},
+				next: "cursor",
+			},
+			output: output{
+				code: 200,
+				header: "application/json",
+				response: `{"message":"ok","data":["data"],"next":"cursor"}`,
+			},
+		},
+		{
+			title: "response of last page",
+			input: input{
+				code: 200,
+				msg:  "ok",
+				data: []string{"data"},
+				next: nil,
+			},
+			output: output{
+				code: 200,
+				header: "application/json",
+				response: `{"message":"ok","data":["data"],"next":null}`,
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			w := httptest.NewRecorder()
+
+			// act
+			OkPage(w, c.input.code, c.input.msg, c.input.data, c.input.next)
+
+			// assert
+			assert.Equal(t, c.output.code, w.Code)
+			assert.Equal(t, c.output.header, w.Header().Get("Content-Type"))
+			assert.JSONEq(t, c.output.response, w.Body.String())
+		})
+	}
+}
+
 func TestResponse_Err(t *testing.T) {
 	type input struct {code int; msg string}
 	type output struct {code int; header string; response string}
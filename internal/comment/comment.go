@@ -0,0 +1,98 @@
+package comment
+
+import (
+	"errors"
+	"time"
+
+	"github.com/LNMMusic/optional"
+)
+
+// Interfaces
+type Comment struct {
+	ID 		  optional.Option[string]
+	// TaskID is the id of the task the comment is on
+	TaskID 	  optional.Option[string]
+	// AuthorID is the id of the profile that wrote the comment (the only one that can change it)
+	AuthorID  optional.Option[string]
+	// ParentID is the id of the comment it replies to (None if it is a top level comment)
+	ParentID  optional.Option[string]
+	Body 	  optional.Option[string]
+	// CreatedAt and UpdatedAt are set by the storage
+	CreatedAt optional.Option[time.Time]
+	UpdatedAt optional.Option[time.Time]
+}
+
+// Thread is a top level comment with its replies.
+type Thread struct {
+	Comment *Comment
+	// Replies are the replies to the comment, sorted by creation time
+	Replies []*Comment
+}
+
+// threads groups the given comments, sorted by creation time, in threads.
+// - replies to comments that are not in the list are left out
+func threads(cs []*Comment) (ths []*Thread) {
+	ths = make([]*Thread, 0)
+	byId := make(map[string]*Thread)
+	for _, c := range cs {
+		if c.ParentID.IsSome() {
+			continue
+		}
+		th := &Thread{Comment: c, Replies: make([]*Comment, 0)}
+		id, _ := c.ID.Unwrap()
+		byId[id] = th
+		ths = append(ths, th)
+	}
+	for _, c := range cs {
+		if !c.ParentID.IsSome() {
+			continue
+		}
+		parentId, _ := c.ParentID.Unwrap()
+		if th, ok := byId[parentId]; ok {
+			th.Replies = append(th.Replies, c)
+		}
+	}
+	return
+}
+
+// Storage is the interface that wraps the basic methods for a comment storage.
+// - comments are scoped to their task: the comments of other tasks are not found (ErrStorageNotFound)
+// - threads are one level deep: a reply can not be replied to
+type Storage interface {
+	// Get returns the comment with the given id.
+	Get(taskId string, id string) (c *Comment, err error)
+
+	// List returns the threads of the task, sorted by creation time.
+	List(taskId string) (ths []*Thread, err error)
+
+	// Save saves the given comment, setting its id and timestamps.
+	// - the parent must be a top level comment of the same task
+	Save(c *Comment) (err error)
+
+	// Update replaces the body of the comment with the same id.
+	// - only its author can update it, it fails with ErrStorageForbidden for other profiles
+	// - the author, the parent and the creation time are kept and the update time is set
+	Update(authorId string, c *Comment) (err error)
+
+	// Delete removes the comment with the given id, with its replies.
+	// - only its author can delete it, it fails with ErrStorageForbidden for other profiles
+	Delete(authorId string, taskId string, id string) (err error)
+}
+var (
+	ErrStorageInternal  = errors.New("storage internal error")
+	ErrStorageNotFound  = errors.New("storage comment not found")
+	ErrStorageInvalid   = errors.New("storage invalid comment")
+	ErrStorageForbidden = errors.New("storage comment forbidden")
+)
+
+// Validator is the interface that wraps the basic methods for a comment validator.
+type Validator interface {
+	// Validate validates the given comment.
+	Validate(c *Comment) (err error)
+}
+var (
+	ErrValidatorInternal 	  = errors.New("validator internal error")
+	ErrValidatorFieldRequired = errors.New("validator field required")
+	ErrValidatorFieldEmpty	  = errors.New("validator field empty")
+	ErrValidatorFieldQuality  = errors.New("validator field quality")
+)
@@ -0,0 +1,150 @@
+package comment
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	// FileComments is the name of the file of the comments in the directory of the file storage
+	FileComments = "comments.json"
+)
+
+// constructor
+// - the comments are loaded from the file in the directory (created if it does not exist)
+func NewStorageFile(dir string, vl Validator) (st *StorageFile, err error) {
+	st = &StorageFile{StorageLocal: NewStorageLocal([]*Comment{}, vl), dir: dir}
+
+	err = os.MkdirAll(dir, 0o755)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrStorageInternal, err)
+		st = nil
+		return
+	}
+	err = st.load()
+	if err != nil {
+		st = nil
+		return
+	}
+	return
+}
+
+// StorageFile is the implementation of the comment storage that keeps the comments in memory (see StorageLocal) and on disk, in a file of a directory.
+// - every change writes all the comments to the file before it returns (written aside, synced and renamed, so the file is never half written)
+// - a change that can not be written is undone in memory (it fails with ErrStorageInternal)
+// - it is safe for concurrent use: changes are applied and written one at a time, reads go to the memory
+type StorageFile struct {
+	// StorageLocal keeps the comments in memory (reads go straight to it, changes are written)
+	*StorageLocal
+	// mu serializes the changes, and their writes
+	mu sync.Mutex
+	// dir is the directory of the file
+	dir string
+}
+
+func (s *StorageFile) Save(c *Comment) (err error) {
+	err = s.change(func() error { return s.StorageLocal.Save(c) })
+	return
+}
+
+func (s *StorageFile) Update(authorId string, c *Comment) (err error) {
+	err = s.change(func() error { return s.StorageLocal.Update(authorId, c) })
+	return
+}
+
+func (s *StorageFile) Delete(authorId string, taskId string, id string) (err error) {
+	err = s.change(func() error { return s.StorageLocal.Delete(authorId, taskId, id) })
+	return
+}
+
+// change applies the change to the comments in memory, and writes all of them to the file.
+// - a change that fails is not written, one that can not be written is undone
+func (s *StorageFile) change(apply func() error) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the comments before the change (the stored comments are replaced, never changed)
+	s.StorageLocal.mu.RLock()
+	before := append([]*Comment(nil), s.db...)
+	s.StorageLocal.mu.RUnlock()
+
+	err = apply()
+	if err != nil {
+		return
+	}
+
+	err = s.write()
+	if err != nil {
+		s.StorageLocal.mu.Lock()
+		s.db = before
+		s.StorageLocal.mu.Unlock()
+		err = fmt.Errorf("%w: %v", ErrStorageInternal, err)
+		return
+	}
+	return
+}
+
+// write writes all the comments to the file.
+func (s *StorageFile) write() (err error) {
+	s.StorageLocal.mu.RLock()
+	data, err := json.Marshal(s.db)
+	s.StorageLocal.mu.RUnlock()
+	if err != nil {
+		return
+	}
+
+	path := filepath.Join(s.dir, FileComments)
+	f, err := os.OpenFile(path+".tmp", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return
+	}
+	err = os.Rename(path+".tmp", path)
+	if err != nil {
+		return
+	}
+
+	// the directory, so the file stays renamed
+	d, err := os.Open(s.dir)
+	if err != nil {
+		return
+	}
+	defer d.Close()
+	err = d.Sync()
+	return
+}
+
+// load reads the comments from the file (none if it does not exist).
+func (s *StorageFile) load() (err error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, FileComments))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			err = nil
+			return
+		}
+		err = fmt.Errorf("%w: %v", ErrStorageInternal, err)
+		return
+	}
+
+	var db []*Comment
+	err = json.Unmarshal(data, &db)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrStorageInternal, err)
+		return
+	}
+	s.db = db
+	return
+}
@@ -0,0 +1,78 @@
+package comment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/LNMMusic/optional"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests
+func TestStorageFile_Reload(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	st, err := NewStorageFile(dir, NewValidatorLocal(nil))
+	assert.NoError(t, err)
+	first := &Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), Body: optional.Some("first")}
+	assert.NoError(t, st.Save(first))
+	firstId, _ := first.ID.Unwrap()
+	reply := &Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p2"), ParentID: optional.Some(firstId), Body: optional.Some("reply")}
+	assert.NoError(t, st.Save(reply))
+	second := &Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), Body: optional.Some("second")}
+	assert.NoError(t, st.Save(second))
+	secondId, _ := second.ID.Unwrap()
+	assert.NoError(t, st.Update("p1", &Comment{ID: optional.Some(firstId), TaskID: optional.Some("t1"), Body: optional.Some("edited")}))
+	assert.NoError(t, st.Delete("p1", "t1", secondId))
+
+	// act
+	reloaded, err := NewStorageFile(dir, NewValidatorLocal(nil))
+
+	// assert
+	assert.NoError(t, err)
+	ths, errList := reloaded.List("t1")
+	assert.NoError(t, errList)
+	assert.Len(t, ths, 1)
+	assert.Equal(t, optional.Some(firstId), ths[0].Comment.ID)
+	assert.Equal(t, optional.Some("edited"), ths[0].Comment.Body)
+	assert.Equal(t, optional.Some("p1"), ths[0].Comment.AuthorID)
+	assert.True(t, ths[0].Comment.CreatedAt.IsSome())
+	assert.Len(t, ths[0].Replies, 1)
+	assert.Equal(t, optional.Some("reply"), ths[0].Replies[0].Body)
+}
+
+func TestStorageFile_FailedWrite(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	st, err := NewStorageFile(dir, NewValidatorLocal(nil))
+	assert.NoError(t, err)
+	assert.NoError(t, st.Save(&Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), Body: optional.Some("first")}))
+	// -> the file can not be written aside
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, FileComments+".tmp"), 0o755))
+
+	// act
+	err = st.Save(&Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), Body: optional.Some("second")})
+
+	// assert
+	// -> the change that could not be written is undone
+	assert.ErrorIs(t, err, ErrStorageInternal)
+	ths, errList := st.List("t1")
+	assert.NoError(t, errList)
+	assert.Len(t, ths, 1)
+	assert.Equal(t, optional.Some("first"), ths[0].Comment.Body)
+}
+
+func TestStorageFile_Corrupted(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, FileComments), []byte("[{"), 0o644))
+
+	// act
+	st, err := NewStorageFile(dir, NewValidatorLocal(nil))
+
+	// assert
+	assert.Nil(t, st)
+	assert.ErrorIs(t, err, ErrStorageInternal)
+}
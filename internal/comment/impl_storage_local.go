@@ -0,0 +1,210 @@
+package comment
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/LNMMusic/optional"
+
+	"github.com/google/uuid"
+)
+
+// constructor
+func NewStorageLocal(db []*Comment, vl Validator) *StorageLocal {
+	return &StorageLocal{db: db, vl: vl, now: time.Now, newId: newId}
+}
+
+// StorageLocal is the local implementation of the comment storage.
+// - it is safe for concurrent use: it keeps copies of the comments it saves and returns copies of them
+type StorageLocal struct {
+	// mu guards db
+	mu sync.RWMutex
+	db []*Comment
+	vl Validator
+	// now returns the current time
+	now func() time.Time
+	// newId returns the id of a new comment
+	newId func() string
+}
+
+// newId returns a random id.
+func newId() string {
+	return uuid.New().String()
+}
+
+// clone returns a copy of the comment.
+func clone(c *Comment) *Comment {
+	cp := *c
+	return &cp
+}
+
+// index returns the position of the comment with the given id, on the given task.
+func (s *StorageLocal) index(taskId string, id string) (i int, err error) {
+	for i = range s.db {
+		cId, _ := s.db[i].ID.Unwrap()
+		cTaskId, _ := s.db[i].TaskID.Unwrap()
+		if cId == id && cTaskId == taskId {
+			return
+		}
+	}
+
+	err = fmt.Errorf("%w: %v", ErrStorageNotFound, id)
+	return
+}
+
+// authorize returns the position of the comment with the given id, on the given task, written by the given author.
+func (s *StorageLocal) authorize(authorId string, taskId string, id string) (i int, err error) {
+	i, err = s.index(taskId, id)
+	if err != nil {
+		return
+	}
+
+	cAuthorId, _ := s.db[i].AuthorID.Unwrap()
+	if cAuthorId != authorId {
+		err = fmt.Errorf("%w: %v", ErrStorageForbidden, id)
+		return
+	}
+
+	return
+}
+
+// checkParent checks the parent of the comment is a top level comment of the same task.
+func (s *StorageLocal) checkParent(c *Comment) (err error) {
+	if !c.ParentID.IsSome() {
+		return
+	}
+
+	parentId, _ := c.ParentID.Unwrap()
+	taskId, _ := c.TaskID.Unwrap()
+	var i int
+	i, err = s.index(taskId, parentId)
+	if err != nil {
+		err = fmt.Errorf("%w: parent %v not found", ErrStorageInvalid, parentId)
+		return
+	}
+	if s.db[i].ParentID.IsSome() {
+		err = fmt.Errorf("%w: parent %v is a reply", ErrStorageInvalid, parentId)
+		return
+	}
+
+	return
+}
+
+func (s *StorageLocal) Get(taskId string, id string) (c *Comment, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var i int
+	i, err = s.index(taskId, id)
+	if err != nil {
+		return
+	}
+
+	c = clone(s.db[i])
+	return
+}
+
+func (s *StorageLocal) List(taskId string) (ths []*Thread, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// comments of the task, sorted by creation time
+	var cs []*Comment
+	for _, c := range s.db {
+		if cTaskId, _ := c.TaskID.Unwrap(); cTaskId == taskId {
+			cs = append(cs, clone(c))
+		}
+	}
+	sort.SliceStable(cs, func(i, j int) bool {
+		ci, _ := cs[i].CreatedAt.Unwrap()
+		cj, _ := cs[j].CreatedAt.Unwrap()
+		return ci.Before(cj)
+	})
+
+	// threads
+	ths = threads(cs)
+
+	return
+}
+
+func (s *StorageLocal) Save(c *Comment) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// validate comment
+	err = s.vl.Validate(c)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrStorageInvalid, err)
+		return
+	}
+
+	// check parent
+	err = s.checkParent(c)
+	if err != nil {
+		return
+	}
+
+	// generate id and timestamps
+	c.ID = optional.Some(s.newId())
+	now := s.now()
+	c.CreatedAt = optional.Some(now)
+	c.UpdatedAt = optional.Some(now)
+
+	// save comment
+	s.db = append(s.db, clone(c))
+	return
+}
+
+func (s *StorageLocal) Update(authorId string, c *Comment) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// update comment
+	id, _ := c.ID.Unwrap()
+	taskId, _ := c.TaskID.Unwrap()
+	var i int
+	i, err = s.authorize(authorId, taskId, id)
+	if err != nil {
+		return
+	}
+	stored := s.db[i]
+	c.AuthorID = stored.AuthorID
+	c.ParentID = stored.ParentID
+
+	// validate comment
+	err = s.vl.Validate(c)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrStorageInvalid, err)
+		return
+	}
+
+	c.CreatedAt = stored.CreatedAt
+	c.UpdatedAt = optional.Some(s.now())
+	s.db[i] = clone(c)
+	return
+}
+
+func (s *StorageLocal) Delete(authorId string, taskId string, id string) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.authorize(authorId, taskId, id)
+	if err != nil {
+		return
+	}
+
+	// remove the comment and its replies
+	db := s.db[:0]
+	for _, c := range s.db {
+		cId, _ := c.ID.Unwrap()
+		parentId, _ := c.ParentID.Unwrap()
+		if cId == id || (c.ParentID.IsSome() && parentId == id) {
+			continue
+		}
+		db = append(db, c)
+	}
+	s.db = db
+	return
+}
@@ -0,0 +1,466 @@
+package comment
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/LNMMusic/optional"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Tests
+func TestStorageLocal_Get(t *testing.T) {
+	type input struct {taskId string; id string}
+	type output struct {c *Comment; err error; errMsg string}
+	type testCase struct {
+		title		string
+		input		input
+		output		output
+		setDatabase func(db *[]*Comment)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "get a comment",
+			input: input{taskId: "t1", id: "c1"},
+			output: output{c: &Comment{ID: optional.Some("c1"), TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), Body: optional.Some("body")}},
+			setDatabase: func(db *[]*Comment) {
+				*db = []*Comment{{ID: optional.Some("c1"), TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), Body: optional.Some("body")}}
+			},
+		},
+
+		// failure cases
+		{
+			title: "get a comment of another task",
+			input: input{taskId: "t2", id: "c1"},
+			output: output{c: nil, err: ErrStorageNotFound, errMsg: "storage comment not found: c1"},
+			setDatabase: func(db *[]*Comment) {
+				*db = []*Comment{{ID: optional.Some("c1"), TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), Body: optional.Some("body")}}
+			},
+		},
+		{
+			title: "get a comment that does not exist",
+			input: input{taskId: "t1", id: "c1"},
+			output: output{c: nil, err: ErrStorageNotFound, errMsg: "storage comment not found: c1"},
+			setDatabase: func(db *[]*Comment) {},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db := []*Comment{}
+			c.setDatabase(&db)
+
+			st := NewStorageLocal(db, NewValidatorMock())
+
+			// act
+			cm, err := st.Get(c.input.taskId, c.input.id)
+
+			// assert
+			assert.Equal(t, c.output.c, cm)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestStorageLocal_List(t *testing.T) {
+	t1 := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	t3 := t2.Add(time.Hour)
+
+	type input struct {taskId string}
+	type output struct {ths []*Thread; err error}
+	type testCase struct {
+		title		string
+		input		input
+		output		output
+		setDatabase func(db *[]*Comment)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "list the threads of a task, sorted by creation time",
+			input: input{taskId: "t1"},
+			output: output{ths: []*Thread{
+				{
+					Comment: &Comment{ID: optional.Some("c1"), TaskID: optional.Some("t1"), CreatedAt: optional.Some(t1)},
+					Replies: []*Comment{
+						{ID: optional.Some("c3"), TaskID: optional.Some("t1"), ParentID: optional.Some("c1"), CreatedAt: optional.Some(t2)},
+						{ID: optional.Some("c4"), TaskID: optional.Some("t1"), ParentID: optional.Some("c1"), CreatedAt: optional.Some(t3)},
+					},
+				},
+				{
+					Comment: &Comment{ID: optional.Some("c2"), TaskID: optional.Some("t1"), CreatedAt: optional.Some(t2)},
+					Replies: []*Comment{},
+				},
+			}},
+			setDatabase: func(db *[]*Comment) {
+				*db = []*Comment{
+					{ID: optional.Some("c4"), TaskID: optional.Some("t1"), ParentID: optional.Some("c1"), CreatedAt: optional.Some(t3)},
+					{ID: optional.Some("c2"), TaskID: optional.Some("t1"), CreatedAt: optional.Some(t2)},
+					{ID: optional.Some("c5"), TaskID: optional.Some("t2"), CreatedAt: optional.Some(t1)},
+					{ID: optional.Some("c3"), TaskID: optional.Some("t1"), ParentID: optional.Some("c1"), CreatedAt: optional.Some(t2)},
+					{ID: optional.Some("c1"), TaskID: optional.Some("t1"), CreatedAt: optional.Some(t1)},
+				}
+			},
+		},
+		{
+			title: "list the threads of a task without comments",
+			input: input{taskId: "t1"},
+			output: output{ths: []*Thread{}},
+			setDatabase: func(db *[]*Comment) {
+				*db = []*Comment{{ID: optional.Some("c1"), TaskID: optional.Some("t2"), CreatedAt: optional.Some(t1)}}
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db := []*Comment{}
+			c.setDatabase(&db)
+
+			st := NewStorageLocal(db, NewValidatorMock())
+
+			// act
+			ths, err := st.List(c.input.taskId)
+
+			// assert
+			assert.Equal(t, c.output.ths, ths)
+			assert.ErrorIs(t, err, c.output.err)
+		})
+	}
+}
+
+func TestStorageLocal_Save(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type input struct {c *Comment}
+	type output struct {c *Comment; err error; errMsg string}
+	type testCase struct {
+		title		 string
+		input		 input
+		output		 output
+		setDatabase  func(db *[]*Comment)
+		setValidator func(vl *ValidatorMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "save a comment",
+			input: input{c: &Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), Body: optional.Some("body")}},
+			output: output{c: &Comment{ID: optional.Some("c1"), TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), Body: optional.Some("body"), CreatedAt: optional.Some(now), UpdatedAt: optional.Some(now)}},
+			setDatabase: func(db *[]*Comment) {},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+		{
+			title: "save a reply",
+			input: input{c: &Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), ParentID: optional.Some("c0"), Body: optional.Some("body")}},
+			output: output{c: &Comment{ID: optional.Some("c1"), TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), ParentID: optional.Some("c0"), Body: optional.Some("body"), CreatedAt: optional.Some(now), UpdatedAt: optional.Some(now)}},
+			setDatabase: func(db *[]*Comment) {
+				*db = []*Comment{{ID: optional.Some("c0"), TaskID: optional.Some("t1"), AuthorID: optional.Some("p2")}}
+			},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+
+		// failure cases
+		{
+			title: "save an invalid comment",
+			input: input{c: &Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1")}},
+			output: output{err: ErrStorageInvalid, errMsg: "storage invalid comment: validator field required: body"},
+			setDatabase: func(db *[]*Comment) {},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Validate", mock.Anything).Return(fmt.Errorf("%w: body", ErrValidatorFieldRequired))
+			},
+		},
+		{
+			title: "save a reply to a comment of another task",
+			input: input{c: &Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), ParentID: optional.Some("c0"), Body: optional.Some("body")}},
+			output: output{err: ErrStorageInvalid, errMsg: "storage invalid comment: parent c0 not found"},
+			setDatabase: func(db *[]*Comment) {
+				*db = []*Comment{{ID: optional.Some("c0"), TaskID: optional.Some("t2"), AuthorID: optional.Some("p2")}}
+			},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+		{
+			title: "save a reply to a reply",
+			input: input{c: &Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), ParentID: optional.Some("c0"), Body: optional.Some("body")}},
+			output: output{err: ErrStorageInvalid, errMsg: "storage invalid comment: parent c0 is a reply"},
+			setDatabase: func(db *[]*Comment) {
+				*db = []*Comment{
+					{ID: optional.Some("cp"), TaskID: optional.Some("t1"), AuthorID: optional.Some("p2")},
+					{ID: optional.Some("c0"), TaskID: optional.Some("t1"), AuthorID: optional.Some("p2"), ParentID: optional.Some("cp")},
+				}
+			},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db := []*Comment{}
+			c.setDatabase(&db)
+
+			vl := NewValidatorMock()
+			c.setValidator(vl)
+
+			st := NewStorageLocal(db, vl)
+			st.now = func() time.Time { return now }
+			st.newId = func() string { return "c1" }
+
+			// act
+			err := st.Save(c.input.c)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+				return
+			}
+			assert.Equal(t, c.output.c, c.input.c)
+			vl.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStorageLocal_Update(t *testing.T) {
+	created := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := created.Add(time.Hour)
+
+	type input struct {authorId string; c *Comment}
+	type output struct {c *Comment; err error; errMsg string}
+	type testCase struct {
+		title		 string
+		input		 input
+		output		 output
+		setDatabase  func(db *[]*Comment)
+		setValidator func(vl *ValidatorMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "update a reply, keeping its author and parent",
+			input: input{authorId: "p1", c: &Comment{ID: optional.Some("c1"), TaskID: optional.Some("t1"), Body: optional.Some("edited")}},
+			output: output{c: &Comment{ID: optional.Some("c1"), TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), ParentID: optional.Some("c0"), Body: optional.Some("edited"), CreatedAt: optional.Some(created), UpdatedAt: optional.Some(now)}},
+			setDatabase: func(db *[]*Comment) {
+				*db = []*Comment{{ID: optional.Some("c1"), TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), ParentID: optional.Some("c0"), Body: optional.Some("body"), CreatedAt: optional.Some(created), UpdatedAt: optional.Some(created)}}
+			},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+
+		// failure cases
+		{
+			title: "update a comment of another author",
+			input: input{authorId: "p2", c: &Comment{ID: optional.Some("c1"), TaskID: optional.Some("t1"), Body: optional.Some("edited")}},
+			output: output{err: ErrStorageForbidden, errMsg: "storage comment forbidden: c1"},
+			setDatabase: func(db *[]*Comment) {
+				*db = []*Comment{{ID: optional.Some("c1"), TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), Body: optional.Some("body")}}
+			},
+			setValidator: func(vl *ValidatorMock) {},
+		},
+		{
+			title: "update a comment that does not exist",
+			input: input{authorId: "p1", c: &Comment{ID: optional.Some("c1"), TaskID: optional.Some("t1"), Body: optional.Some("edited")}},
+			output: output{err: ErrStorageNotFound, errMsg: "storage comment not found: c1"},
+			setDatabase: func(db *[]*Comment) {},
+			setValidator: func(vl *ValidatorMock) {},
+		},
+		{
+			title: "update a comment with an invalid body",
+			input: input{authorId: "p1", c: &Comment{ID: optional.Some("c1"), TaskID: optional.Some("t1"), Body: optional.Some("")}},
+			output: output{err: ErrStorageInvalid, errMsg: "storage invalid comment: validator field empty: body"},
+			setDatabase: func(db *[]*Comment) {
+				*db = []*Comment{{ID: optional.Some("c1"), TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), Body: optional.Some("body")}}
+			},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Validate", mock.Anything).Return(fmt.Errorf("%w: body", ErrValidatorFieldEmpty))
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db := []*Comment{}
+			c.setDatabase(&db)
+
+			vl := NewValidatorMock()
+			c.setValidator(vl)
+
+			st := NewStorageLocal(db, vl)
+			st.now = func() time.Time { return now }
+
+			// act
+			err := st.Update(c.input.authorId, c.input.c)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+				return
+			}
+			assert.Equal(t, c.output.c, c.input.c)
+			assert.Equal(t, c.output.c, st.db[0])
+			vl.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStorageLocal_Delete(t *testing.T) {
+	type input struct {authorId string; taskId string; id string}
+	type output struct {db []*Comment; err error; errMsg string}
+	type testCase struct {
+		title		string
+		input		input
+		output		output
+		setDatabase func(db *[]*Comment)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "delete a comment with its replies",
+			input: input{authorId: "p1", taskId: "t1", id: "c1"},
+			output: output{db: []*Comment{{ID: optional.Some("c2"), TaskID: optional.Some("t1"), AuthorID: optional.Some("p2")}}},
+			setDatabase: func(db *[]*Comment) {
+				*db = []*Comment{
+					{ID: optional.Some("c1"), TaskID: optional.Some("t1"), AuthorID: optional.Some("p1")},
+					{ID: optional.Some("c2"), TaskID: optional.Some("t1"), AuthorID: optional.Some("p2")},
+					{ID: optional.Some("c3"), TaskID: optional.Some("t1"), AuthorID: optional.Some("p2"), ParentID: optional.Some("c1")},
+				}
+			},
+		},
+
+		// failure cases
+		{
+			title: "delete a comment of another author",
+			input: input{authorId: "p2", taskId: "t1", id: "c1"},
+			output: output{db: []*Comment{{ID: optional.Some("c1"), TaskID: optional.Some("t1"), AuthorID: optional.Some("p1")}}, err: ErrStorageForbidden, errMsg: "storage comment forbidden: c1"},
+			setDatabase: func(db *[]*Comment) {
+				*db = []*Comment{{ID: optional.Some("c1"), TaskID: optional.Some("t1"), AuthorID: optional.Some("p1")}}
+			},
+		},
+		{
+			title: "delete a comment that does not exist",
+			input: input{authorId: "p1", taskId: "t1", id: "c1"},
+			output: output{db: []*Comment{}, err: ErrStorageNotFound, errMsg: "storage comment not found: c1"},
+			setDatabase: func(db *[]*Comment) {},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db := []*Comment{}
+			c.setDatabase(&db)
+
+			st := NewStorageLocal(db, NewValidatorMock())
+
+			// act
+			err := st.Delete(c.input.authorId, c.input.taskId, c.input.id)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			assert.Equal(t, c.output.db, st.db)
+		})
+	}
+}
+
+func TestStorageLocal_Clone(t *testing.T) {
+	// arrange
+	st := NewStorageLocal([]*Comment{}, NewValidatorLocal(nil))
+	c := &Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), Body: optional.Some("body")}
+	assert.NoError(t, st.Save(c))
+	id, _ := c.ID.Unwrap()
+
+	// act
+	c.Body = optional.Some("saved")
+	got, errGet := st.Get("t1", id)
+	got.Body = optional.Some("got")
+	ths, errList := st.List("t1")
+	ths[0].Comment.Body = optional.Some("listed")
+	again, errAgain := st.Get("t1", id)
+
+	// assert
+	// -> the stored comment is not changed through the comments given or returned
+	assert.NoError(t, errGet)
+	assert.NoError(t, errList)
+	assert.NoError(t, errAgain)
+	assert.Equal(t, optional.Some("body"), again.Body)
+}
+
+func TestStorageLocal_Concurrent(t *testing.T) {
+	// run with the race detector (go test -race) to catch unguarded accesses
+	const n = 50
+
+	// arrange
+	db := []*Comment{{ID: optional.Some("0"), TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), Body: optional.Some("body")}}
+	st := NewStorageLocal(db, NewValidatorLocal(nil))
+
+	// act
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(3)
+		// writers: reply to the comment
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = st.Save(&Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p2"), ParentID: optional.Some("0"), Body: optional.Some(fmt.Sprintf("reply %d", i))})
+		}(i)
+		// writers: edit the same comment
+		go func(i int) {
+			defer wg.Done()
+			_ = st.Update("p1", &Comment{ID: optional.Some("0"), TaskID: optional.Some("t1"), Body: optional.Some(fmt.Sprintf("body %d", i))})
+		}(i)
+		// readers: list the comments and get the same comment
+		go func() {
+			defer wg.Done()
+			ths, _ := st.List("t1")
+			for _, th := range ths {
+				_, _ = th.Comment.Body.Unwrap()
+			}
+			_, _ = st.Get("t1", "0")
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	for i := 0; i < n; i++ {
+		assert.NoError(t, errs[i])
+	}
+	ths, err := st.List("t1")
+	assert.NoError(t, err)
+	assert.Len(t, ths, 1)
+	assert.Len(t, ths[0].Replies, n)
+}
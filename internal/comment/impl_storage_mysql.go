@@ -0,0 +1,425 @@
+package comment
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/LNMMusic/optional"
+	"github.com/google/uuid"
+)
+
+// constructor
+func NewStorageMySQL(db *sql.DB, vl Validator) *StorageMySQL {
+	return &StorageMySQL{db: db, vl: vl, queryGetState: QueryGetCommentState}
+}
+
+// StorageMySQL is an implementation with MySQL of the Storage interface.
+// - task_comments table: task_id references tasks (id) and parent_id references task_comments (id), both on delete cascade
+// - times are scanned as time (parseTime=true on the dsn) and stored in UTC
+const (
+	QueryGetComment = `SELECT id, task_id, author_id, parent_id, body, created_at, updated_at FROM task_comments WHERE id = ? AND task_id = ?`
+	QueryListComments = `SELECT id, task_id, author_id, parent_id, body, created_at, updated_at FROM task_comments WHERE task_id = ? ORDER BY created_at, id`
+	QuerySaveComment = `INSERT INTO task_comments (id, task_id, author_id, parent_id, body, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	// -> rows affected must count the matched rows (clientFoundRows=true on the dsn)
+	QueryUpdateComment = `UPDATE task_comments SET body = ?, updated_at = ? WHERE id = ? AND task_id = ?`
+	// -> the replies are removed on cascade
+	QueryDeleteComment = `DELETE FROM task_comments WHERE id = ? AND task_id = ?`
+	// -> the author and the parent of the comment, locked until the end of the transaction
+	QueryGetCommentState = `SELECT author_id, parent_id, created_at FROM task_comments WHERE id = ? AND task_id = ? FOR UPDATE`
+)
+
+// CommentMySQL is the MySQL representation of a comment. (internal Data Transfer Object)
+type CommentMySQL struct {
+	ID 		  sql.NullString
+	TaskID 	  sql.NullString
+	AuthorID  sql.NullString
+	ParentID  sql.NullString
+	Body 	  sql.NullString
+	CreatedAt sql.NullTime
+	UpdatedAt sql.NullTime
+}
+
+// fields returns the destination of the columns selected by the queries.
+func (c *CommentMySQL) fields() []any {
+	return []any{&c.ID, &c.TaskID, &c.AuthorID, &c.ParentID, &c.Body, &c.CreatedAt, &c.UpdatedAt}
+}
+
+// serialize returns the comment represented by the dto.
+func (c *CommentMySQL) serialize() (cm *Comment) {
+	cm = &Comment{}
+	if c.ID.Valid {
+		cm.ID = optional.Some(c.ID.String)
+	}
+	if c.TaskID.Valid {
+		cm.TaskID = optional.Some(c.TaskID.String)
+	}
+	if c.AuthorID.Valid {
+		cm.AuthorID = optional.Some(c.AuthorID.String)
+	}
+	if c.ParentID.Valid {
+		cm.ParentID = optional.Some(c.ParentID.String)
+	}
+	if c.Body.Valid {
+		cm.Body = optional.Some(c.Body.String)
+	}
+	if c.CreatedAt.Valid {
+		cm.CreatedAt = optional.Some(c.CreatedAt.Time)
+	}
+	if c.UpdatedAt.Valid {
+		cm.UpdatedAt = optional.Some(c.UpdatedAt.Time)
+	}
+	return
+}
+
+// deserialize returns the dto of the given comment.
+func deserialize(cm *Comment) (c CommentMySQL) {
+	if id, err := cm.ID.Unwrap(); err == nil {
+		c.ID = sql.NullString{String: id, Valid: true}
+	}
+	if taskId, err := cm.TaskID.Unwrap(); err == nil {
+		c.TaskID = sql.NullString{String: taskId, Valid: true}
+	}
+	if authorId, err := cm.AuthorID.Unwrap(); err == nil {
+		c.AuthorID = sql.NullString{String: authorId, Valid: true}
+	}
+	if parentId, err := cm.ParentID.Unwrap(); err == nil {
+		c.ParentID = sql.NullString{String: parentId, Valid: true}
+	}
+	if body, err := cm.Body.Unwrap(); err == nil {
+		c.Body = sql.NullString{String: body, Valid: true}
+	}
+	if createdAt, err := cm.CreatedAt.Unwrap(); err == nil {
+		c.CreatedAt = sql.NullTime{Time: createdAt, Valid: true}
+	}
+	if updatedAt, err := cm.UpdatedAt.Unwrap(); err == nil {
+		c.UpdatedAt = sql.NullTime{Time: updatedAt, Valid: true}
+	}
+	return
+}
+
+// StorageMySQL is the MySQL implementation of the comment storage.
+type StorageMySQL struct {
+	// db is the database connection.
+	db *sql.DB
+	// vl is the validator of the comments.
+	vl Validator
+	// bind rewrites the ? placeholders of the statements (nil keeps them, see NewStoragePostgres).
+	bind func(query string) string
+	// queryGetState reads the state of a comment and locks it (see NewStorageSQLite, SQLite can not lock a row).
+	queryGetState string
+}
+
+// statement returns the given statement with the placeholders of the database.
+func (s *StorageMySQL) statement(query string) string {
+	if s.bind == nil {
+		return query
+	}
+	return s.bind(query)
+}
+
+// Get returns the comment with the given id.
+func (s *StorageMySQL) Get(taskId string, id string) (c *Comment, err error) {
+	var commentMySQL CommentMySQL
+	err = queryRow(s.db, s.statement(QueryGetComment), []any{id, taskId}, commentMySQL.fields()...)
+	if err != nil {
+		return
+	}
+
+	// serialize
+	c = commentMySQL.serialize()
+	return
+}
+
+// List returns the threads of the task.
+func (s *StorageMySQL) List(taskId string) (ths []*Thread, err error) {
+	// comments sorted by creation time (the parents before their replies)
+	var cs []*Comment
+	err = queryRows(s.db, s.statement(QueryListComments), []any{taskId}, func(rows *sql.Rows) (err error) {
+		var commentMySQL CommentMySQL
+		err = rows.Scan(commentMySQL.fields()...)
+		if err != nil {
+			return
+		}
+		cs = append(cs, commentMySQL.serialize())
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	// threads
+	ths = threads(cs)
+
+	return
+}
+
+// Save saves the given comment.
+func (s *StorageMySQL) Save(c *Comment) (err error) {
+	// validate
+	err = s.vl.Validate(c)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInvalid, "validate")
+		return
+	}
+
+	// deserialize
+	commentMySQL := deserialize(c)
+
+	// default values
+	commentMySQL.ID = sql.NullString{String: uuid.New().String(), Valid: true}
+	now := time.Now().UTC()
+	commentMySQL.CreatedAt = sql.NullTime{Time: now, Valid: true}
+	commentMySQL.UpdatedAt = sql.NullTime{Time: now, Valid: true}
+
+	// execute statements
+	err = s.transaction(func(tx *sql.Tx) (err error) {
+		// check parent: a top level comment of the same task
+		if commentMySQL.ParentID.Valid {
+			var parent CommentMySQL
+			err = queryRow(tx, s.statement(s.queryGetState), []any{commentMySQL.ParentID, commentMySQL.TaskID}, &parent.AuthorID, &parent.ParentID, &parent.CreatedAt)
+			if err != nil {
+				if errors.Is(err, ErrStorageNotFound) {
+					err = fmt.Errorf("%w: %s", ErrStorageInvalid, "parent")
+				}
+				return
+			}
+			if parent.ParentID.Valid {
+				err = fmt.Errorf("%w: %s", ErrStorageInvalid, "parent reply")
+				return
+			}
+		}
+
+		var rowsAffected int64
+		rowsAffected, err = execN(tx, s.statement(QuerySaveComment), commentMySQL.ID, commentMySQL.TaskID, commentMySQL.AuthorID, commentMySQL.ParentID, commentMySQL.Body, commentMySQL.CreatedAt, commentMySQL.UpdatedAt)
+		if err != nil {
+			return
+		}
+		if rowsAffected != 1 {
+			err = fmt.Errorf("%w: %s", ErrStorageInternal, "rows affected")
+			return
+		}
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	// set default values
+	c.ID = optional.Some(commentMySQL.ID.String)
+	c.CreatedAt = optional.Some(now)
+	c.UpdatedAt = optional.Some(now)
+
+	return
+}
+
+// Update replaces the body of the comment with the same id as the given comment.
+func (s *StorageMySQL) Update(authorId string, c *Comment) (err error) {
+	// deserialize
+	commentMySQL := deserialize(c)
+	now := time.Now().UTC()
+
+	// execute statements
+	var stored CommentMySQL
+	err = s.transaction(func(tx *sql.Tx) (err error) {
+		// check author
+		stored, err = s.authorize(tx, authorId, commentMySQL)
+		if err != nil {
+			return
+		}
+
+		// validate (with the stored author and parent)
+		c.AuthorID = optional.Some(stored.AuthorID.String)
+		c.ParentID = optional.None[string]()
+		if stored.ParentID.Valid {
+			c.ParentID = optional.Some(stored.ParentID.String)
+		}
+		err = s.vl.Validate(c)
+		if err != nil {
+			err = fmt.Errorf("%w: %s", ErrStorageInvalid, "validate")
+			return
+		}
+
+		err = exec(tx, s.statement(QueryUpdateComment), commentMySQL.Body, now, commentMySQL.ID, commentMySQL.TaskID)
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	// set default values
+	c.CreatedAt = optional.Some(stored.CreatedAt.Time)
+	c.UpdatedAt = optional.Some(now)
+
+	return
+}
+
+// Delete removes the comment with the given id, with its replies.
+func (s *StorageMySQL) Delete(authorId string, taskId string, id string) (err error) {
+	commentMySQL := CommentMySQL{ID: sql.NullString{String: id, Valid: true}, TaskID: sql.NullString{String: taskId, Valid: true}}
+
+	// execute statements
+	err = s.transaction(func(tx *sql.Tx) (err error) {
+		// check author
+		_, err = s.authorize(tx, authorId, commentMySQL)
+		if err != nil {
+			return
+		}
+
+		err = exec(tx, s.statement(QueryDeleteComment), commentMySQL.ID, commentMySQL.TaskID)
+		return
+	})
+	return
+}
+
+// authorize returns the state of the stored comment, that must be written by the given author.
+func (s *StorageMySQL) authorize(tx *sql.Tx, authorId string, commentMySQL CommentMySQL) (stored CommentMySQL, err error) {
+	err = queryRow(tx, s.statement(s.queryGetState), []any{commentMySQL.ID, commentMySQL.TaskID}, &stored.AuthorID, &stored.ParentID, &stored.CreatedAt)
+	if err != nil {
+		return
+	}
+	if stored.AuthorID.String != authorId {
+		err = fmt.Errorf("%w: %s", ErrStorageForbidden, "author")
+		return
+	}
+
+	return
+}
+
+// queryRow executes the given query and scans its single row into the destination.
+func queryRow(db preparer, query string, args []any, dest ...any) (err error) {
+	// prepare statement
+	var stmt *sql.Stmt
+	stmt, err = db.Prepare(query)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "prepare")
+		return
+	}
+	defer stmt.Close()
+
+	// execute statement
+	err = stmt.QueryRow(args...).Scan(dest...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = fmt.Errorf("%w: %s", ErrStorageNotFound, "query row")
+			return
+		}
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "query row")
+		return
+	}
+
+	return
+}
+
+// queryRows executes the given query and scans each of its rows with the given function.
+func queryRows(db preparer, query string, args []any, scan func(rows *sql.Rows) (err error)) (err error) {
+	// prepare statement
+	var stmt *sql.Stmt
+	stmt, err = db.Prepare(query)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "prepare")
+		return
+	}
+	defer stmt.Close()
+
+	// execute statement
+	var rows *sql.Rows
+	rows, err = stmt.Query(args...)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "query")
+		return
+	}
+	defer rows.Close()
+
+	// scan
+	for rows.Next() {
+		err = scan(rows)
+		if err != nil {
+			err = fmt.Errorf("%w: %s", ErrStorageInternal, "scan")
+			return
+		}
+	}
+	if err = rows.Err(); err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "rows")
+		return
+	}
+
+	return
+}
+
+// transaction runs the given operation in a transaction.
+// - the transaction is committed if the operation succeeds and rolled back otherwise
+func (s *StorageMySQL) transaction(op func(tx *sql.Tx) (err error)) (err error) {
+	var tx *sql.Tx
+	tx, err = s.db.Begin()
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "begin")
+		return
+	}
+	defer func () {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+		if err != nil {
+			err = fmt.Errorf("%w: %s", ErrStorageInternal, "commit")
+		}
+	}()
+
+	err = op(tx)
+	return
+}
+
+// preparer prepares statements (a database or a transaction).
+type preparer interface {
+	Prepare(query string) (*sql.Stmt, error)
+}
+
+// exec executes the given statement over a single comment.
+// - no rows affected means the comment was not found
+func exec(db preparer, query string, args ...any) (err error) {
+	var rowsAffected int64
+	rowsAffected, err = execN(db, query, args...)
+	if err != nil {
+		return
+	}
+
+	// check rows affected
+	if rowsAffected != 1 {
+		err = fmt.Errorf("%w: %s", ErrStorageNotFound, "rows affected")
+		return
+	}
+
+	return
+}
+
+// execN executes the given statement and returns the amount of rows affected.
+func execN(db preparer, query string, args ...any) (n int64, err error) {
+	// prepare statement
+	var stmt *sql.Stmt
+	stmt, err = db.Prepare(query)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "prepare")
+		return
+	}
+	defer stmt.Close()
+
+	// execute statement
+	var result sql.Result
+	result, err = stmt.Exec(args...)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "exec")
+		return
+	}
+
+	// check result
+	n, err = result.RowsAffected()
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "result rows affected")
+		return
+	}
+
+	return
+}
@@ -0,0 +1,532 @@
+package comment
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/LNMMusic/optional"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Tests
+func TestStorageMySQL_Get(t *testing.T) {
+	type input struct {taskId string; id string}
+	type output struct {c *Comment; err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		string
+		input  		input
+		output 		output
+		// process
+		setDatabase func(mk sqlmock.Sqlmock)
+	}
+
+	cols := []string{"id", "task_id", "author_id", "parent_id", "body", "created_at", "updated_at"}
+	cases := []testCase{
+		// success cases
+		{
+			title: "reply",
+			input: input{taskId: "t1", id: "c1"},
+			output: output{c: &Comment{ID: optional.Some("c1"), TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), ParentID: optional.Some("c0"), Body: optional.Some("body")}},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows(cols).AddRow("c1", "t1", "p1", "c0", "body", nil, nil)
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetComment)).
+					ExpectQuery().WithArgs("c1", "t1").
+					WillReturnRows(rows)
+			},
+		},
+
+		// failure cases
+		{
+			title: "not found",
+			input: input{taskId: "t1", id: "c1"},
+			output: output{c: nil, err: ErrStorageNotFound, errMsg: "storage comment not found: query row"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetComment)).
+					ExpectQuery().WithArgs("c1", "t1").
+					WillReturnError(sql.ErrNoRows)
+			},
+		},
+		{
+			title: "prepare error",
+			input: input{taskId: "t1", id: "c1"},
+			output: output{c: nil, err: ErrStorageInternal, errMsg: "storage internal error: prepare"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetComment)).
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStorageMySQL(db, NewValidatorMock())
+
+			// act
+			cm, err := st.Get(c.input.taskId, c.input.id)
+
+			// assert
+			assert.Equal(t, c.output.c, cm)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorageMySQL_List(t *testing.T) {
+	t1 := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+
+	type input struct {taskId string}
+	type output struct {ths []*Thread; err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		string
+		input  		input
+		output 		output
+		// process
+		setDatabase func(mk sqlmock.Sqlmock)
+	}
+
+	cols := []string{"id", "task_id", "author_id", "parent_id", "body", "created_at", "updated_at"}
+	cases := []testCase{
+		// success cases
+		{
+			title: "threads",
+			input: input{taskId: "t1"},
+			output: output{ths: []*Thread{
+				{
+					Comment: &Comment{ID: optional.Some("c1"), TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), Body: optional.Some("first"), CreatedAt: optional.Some(t1), UpdatedAt: optional.Some(t1)},
+					Replies: []*Comment{{ID: optional.Some("c3"), TaskID: optional.Some("t1"), AuthorID: optional.Some("p2"), ParentID: optional.Some("c1"), Body: optional.Some("reply"), CreatedAt: optional.Some(t2), UpdatedAt: optional.Some(t2)}},
+				},
+				{
+					Comment: &Comment{ID: optional.Some("c2"), TaskID: optional.Some("t1"), AuthorID: optional.Some("p2"), Body: optional.Some("second"), CreatedAt: optional.Some(t2), UpdatedAt: optional.Some(t2)},
+					Replies: []*Comment{},
+				},
+			}},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows(cols).
+					AddRow("c1", "t1", "p1", nil, "first", t1, t1).
+					AddRow("c2", "t1", "p2", nil, "second", t2, t2).
+					AddRow("c3", "t1", "p2", "c1", "reply", t2, t2)
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryListComments)).
+					ExpectQuery().WithArgs("t1").
+					WillReturnRows(rows)
+			},
+		},
+		{
+			title: "no comments",
+			input: input{taskId: "t1"},
+			output: output{ths: []*Thread{}},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryListComments)).
+					ExpectQuery().WithArgs("t1").
+					WillReturnRows(sqlmock.NewRows(cols))
+			},
+		},
+
+		// failure cases
+		{
+			title: "query error",
+			input: input{taskId: "t1"},
+			output: output{ths: nil, err: ErrStorageInternal, errMsg: "storage internal error: query"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryListComments)).
+					ExpectQuery().WithArgs("t1").
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStorageMySQL(db, NewValidatorMock())
+
+			// act
+			ths, err := st.List(c.input.taskId)
+
+			// assert
+			assert.Equal(t, c.output.ths, ths)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorageMySQL_Save(t *testing.T) {
+	type input struct {c *Comment}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+		setValidator func(mk *ValidatorMock)
+	}
+
+	stateCols := []string{"author_id", "parent_id", "created_at"}
+	cases := []testCase{
+		// success cases
+		{
+			title: "comment",
+			input: input{c: &Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), Body: optional.Some("body")}},
+			output: output{err: nil},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QuerySaveComment)).
+					ExpectExec().WithArgs(sqlmock.AnyArg(), "t1", "p1", nil, "body", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectCommit()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+		{
+			title: "reply",
+			input: input{c: &Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), ParentID: optional.Some("c0"), Body: optional.Some("body")}},
+			output: output{err: nil},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetCommentState)).
+					ExpectQuery().WithArgs("c0", "t1").
+					WillReturnRows(sqlmock.NewRows(stateCols).AddRow("p2", nil, nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QuerySaveComment)).
+					ExpectExec().WithArgs(sqlmock.AnyArg(), "t1", "p1", "c0", "body", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectCommit()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+
+		// failure cases
+		{
+			title: "invalid comment",
+			input: input{c: &Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1")}},
+			output: output{err: ErrStorageInvalid, errMsg: "storage invalid comment: validate"},
+			setDatabase: func(mk sqlmock.Sqlmock) {},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.Anything).Return(ErrValidatorFieldRequired)
+			},
+		},
+		{
+			title: "parent not found",
+			input: input{c: &Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), ParentID: optional.Some("c0"), Body: optional.Some("body")}},
+			output: output{err: ErrStorageInvalid, errMsg: "storage invalid comment: parent"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetCommentState)).
+					ExpectQuery().WithArgs("c0", "t1").
+					WillReturnError(sql.ErrNoRows)
+				mk.ExpectRollback()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+		{
+			title: "parent is a reply",
+			input: input{c: &Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), ParentID: optional.Some("c0"), Body: optional.Some("body")}},
+			output: output{err: ErrStorageInvalid, errMsg: "storage invalid comment: parent reply"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetCommentState)).
+					ExpectQuery().WithArgs("c0", "t1").
+					WillReturnRows(sqlmock.NewRows(stateCols).AddRow("p2", "cp", nil))
+				mk.ExpectRollback()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+		{
+			title: "exec error",
+			input: input{c: &Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), Body: optional.Some("body")}},
+			output: output{err: ErrStorageInternal, errMsg: "storage internal error: exec"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QuerySaveComment)).
+					ExpectExec().
+					WillReturnError(sql.ErrConnDone)
+				mk.ExpectRollback()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			vl := NewValidatorMock()
+			c.setValidator(vl)
+
+			st := NewStorageMySQL(db, vl)
+
+			// act
+			err = st.Save(c.input.c)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			} else {
+				assert.True(t, c.input.c.ID.IsSome())
+				assert.True(t, c.input.c.CreatedAt.IsSome())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+			vl.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStorageMySQL_Update(t *testing.T) {
+	created := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type input struct {authorId string; c *Comment}
+	type output struct {c *Comment; err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+		setValidator func(mk *ValidatorMock)
+	}
+
+	stateCols := []string{"author_id", "parent_id", "created_at"}
+	cases := []testCase{
+		// success cases
+		{
+			title: "reply",
+			input: input{authorId: "p1", c: &Comment{ID: optional.Some("c1"), TaskID: optional.Some("t1"), Body: optional.Some("edited")}},
+			output: output{c: &Comment{ID: optional.Some("c1"), TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), ParentID: optional.Some("c0"), Body: optional.Some("edited"), CreatedAt: optional.Some(created)}},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetCommentState)).
+					ExpectQuery().WithArgs("c1", "t1").
+					WillReturnRows(sqlmock.NewRows(stateCols).AddRow("p1", "c0", created))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryUpdateComment)).
+					ExpectExec().WithArgs("edited", sqlmock.AnyArg(), "c1", "t1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectCommit()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+
+		// failure cases
+		{
+			title: "another author",
+			input: input{authorId: "p2", c: &Comment{ID: optional.Some("c1"), TaskID: optional.Some("t1"), Body: optional.Some("edited")}},
+			output: output{err: ErrStorageForbidden, errMsg: "storage comment forbidden: author"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetCommentState)).
+					ExpectQuery().WithArgs("c1", "t1").
+					WillReturnRows(sqlmock.NewRows(stateCols).AddRow("p1", nil, created))
+				mk.ExpectRollback()
+			},
+			setValidator: func(mk *ValidatorMock) {},
+		},
+		{
+			title: "not found",
+			input: input{authorId: "p1", c: &Comment{ID: optional.Some("c1"), TaskID: optional.Some("t1"), Body: optional.Some("edited")}},
+			output: output{err: ErrStorageNotFound, errMsg: "storage comment not found: query row"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetCommentState)).
+					ExpectQuery().WithArgs("c1", "t1").
+					WillReturnError(sql.ErrNoRows)
+				mk.ExpectRollback()
+			},
+			setValidator: func(mk *ValidatorMock) {},
+		},
+		{
+			title: "invalid comment",
+			input: input{authorId: "p1", c: &Comment{ID: optional.Some("c1"), TaskID: optional.Some("t1"), Body: optional.Some("")}},
+			output: output{err: ErrStorageInvalid, errMsg: "storage invalid comment: validate"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetCommentState)).
+					ExpectQuery().WithArgs("c1", "t1").
+					WillReturnRows(sqlmock.NewRows(stateCols).AddRow("p1", nil, created))
+				mk.ExpectRollback()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.Anything).Return(ErrValidatorFieldEmpty)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			vl := NewValidatorMock()
+			c.setValidator(vl)
+
+			st := NewStorageMySQL(db, vl)
+
+			// act
+			err = st.Update(c.input.authorId, c.input.c)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			} else {
+				assert.True(t, c.input.c.UpdatedAt.IsSome())
+				c.input.c.UpdatedAt = optional.None[time.Time]()
+				assert.Equal(t, c.output.c, c.input.c)
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+			vl.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStorageMySQL_Delete(t *testing.T) {
+	type input struct {authorId string; taskId string; id string}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		string
+		input  		input
+		output 		output
+		// process
+		setDatabase func(mk sqlmock.Sqlmock)
+	}
+
+	stateCols := []string{"author_id", "parent_id", "created_at"}
+	cases := []testCase{
+		// success cases
+		{
+			title: "comment",
+			input: input{authorId: "p1", taskId: "t1", id: "c1"},
+			output: output{err: nil},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetCommentState)).
+					ExpectQuery().WithArgs("c1", "t1").
+					WillReturnRows(sqlmock.NewRows(stateCols).AddRow("p1", nil, nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryDeleteComment)).
+					ExpectExec().WithArgs("c1", "t1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectCommit()
+			},
+		},
+
+		// failure cases
+		{
+			title: "another author",
+			input: input{authorId: "p2", taskId: "t1", id: "c1"},
+			output: output{err: ErrStorageForbidden, errMsg: "storage comment forbidden: author"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetCommentState)).
+					ExpectQuery().WithArgs("c1", "t1").
+					WillReturnRows(sqlmock.NewRows(stateCols).AddRow("p1", nil, nil))
+				mk.ExpectRollback()
+			},
+		},
+		{
+			title: "begin error",
+			input: input{authorId: "p1", taskId: "t1", id: "c1"},
+			output: output{err: ErrStorageInternal, errMsg: "storage internal error: begin"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin().WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStorageMySQL(db, NewValidatorMock())
+
+			// act
+			err = st.Delete(c.input.authorId, c.input.taskId, c.input.id)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package comment
+
+import (
+	"database/sql"
+
+	"api/pkg/postgres"
+)
+
+// constructor
+// - the task_comments table is created with the tables of the tasks (see task.SchemaPostgres)
+func NewStoragePostgres(db *sql.DB, vl Validator) *StoragePostgres {
+	s := NewStorageMySQL(db, vl)
+	s.bind = postgres.Bind
+	return &StoragePostgres{StorageMySQL: s}
+}
+
+// StoragePostgres is the implementation of the comment storage on PostgreSQL.
+// - it runs the statements of the MySQL storage, with the placeholders numbered ($1, $2, ...)
+type StoragePostgres struct {
+	// StorageMySQL runs the statements
+	*StorageMySQL
+}
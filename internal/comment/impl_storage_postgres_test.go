@@ -0,0 +1,40 @@
+package comment
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/LNMMusic/optional"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Tests
+func TestStoragePostgres_Update(t *testing.T) {
+	// arrange
+	// -> the statements are matched as they are sent
+	db, mk, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	mk.ExpectBegin()
+	mk.
+		ExpectPrepare(`SELECT author_id, parent_id, created_at FROM task_comments WHERE id = $1 AND task_id = $2 FOR UPDATE`).
+		ExpectQuery().WithArgs("c1", "t1").
+		WillReturnRows(sqlmock.NewRows([]string{"author_id", "parent_id", "created_at"}).AddRow("p1", nil, nil))
+	mk.
+		ExpectPrepare(`UPDATE task_comments SET body = $1, updated_at = $2 WHERE id = $3 AND task_id = $4`).
+		ExpectExec().WithArgs("edited", sqlmock.AnyArg(), "c1", "t1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mk.ExpectCommit()
+
+	vl := NewValidatorMock()
+	vl.On("Validate", mock.Anything).Return(nil)
+	st := NewStoragePostgres(db, vl)
+
+	// act
+	err = st.Update("p1", &Comment{ID: optional.Some("c1"), TaskID: optional.Some("t1"), Body: optional.Some("edited")})
+
+	// assert
+	assert.NoError(t, err)
+	assert.NoError(t, mk.ExpectationsWereMet())
+}
@@ -0,0 +1,25 @@
+package comment
+
+import (
+	"database/sql"
+)
+
+// QueryGetCommentStateSQLite reads the state of a comment on SQLite, that has no row locks.
+// - the transactions hold the only connection (see sqlite.Open), there is nothing else to lock
+const QueryGetCommentStateSQLite = `SELECT author_id, parent_id, created_at FROM task_comments WHERE id = ? AND task_id = ?`
+
+// constructor
+// - the task_comments table is created with the tables of the tasks (see task.SchemaSQLite)
+func NewStorageSQLite(db *sql.DB, vl Validator) *StorageSQLite {
+	s := NewStorageMySQL(db, vl)
+	s.queryGetState = QueryGetCommentStateSQLite
+	return &StorageSQLite{StorageMySQL: s}
+}
+
+// StorageSQLite is the implementation of the comment storage on an embedded SQLite database (see sqlite.Open).
+// - it runs the statements of the MySQL storage, but the one that locks a comment
+// - the replies of a comment are removed with it on cascade (the foreign keys are enforced)
+type StorageSQLite struct {
+	// StorageMySQL runs the statements
+	*StorageMySQL
+}
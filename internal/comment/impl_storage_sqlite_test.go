@@ -0,0 +1,85 @@
+package comment
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"api/internal/task"
+	"api/pkg/sqlite"
+
+	"github.com/LNMMusic/optional"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newStorageSQLite returns a SQLite storage on a new database file, with the tables of the tasks and a task t1.
+func newStorageSQLite(t *testing.T) (st *StorageSQLite, db *sql.DB) {
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "tasks.db"))
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(task.SchemaSQLite)
+	assert.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO tasks (id, owner_id) VALUES ('t1', 'p1')`)
+	assert.NoError(t, err)
+
+	st = NewStorageSQLite(db, NewValidatorLocal(nil))
+	return
+}
+
+// Tests
+func TestStorageSQLite_Threads(t *testing.T) {
+	// arrange
+	st, _ := newStorageSQLite(t)
+	first := &Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), Body: optional.Some("first")}
+	assert.NoError(t, st.Save(first))
+	firstId, _ := first.ID.Unwrap()
+	reply := &Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p2"), ParentID: optional.Some(firstId), Body: optional.Some("reply")}
+	assert.NoError(t, st.Save(reply))
+	replyId, _ := reply.ID.Unwrap()
+
+	// act
+	err := st.Update("p1", &Comment{ID: optional.Some(firstId), TaskID: optional.Some("t1"), Body: optional.Some("edited")})
+
+	// assert
+	assert.NoError(t, err)
+	ths, err := st.List("t1")
+	assert.NoError(t, err)
+	assert.Len(t, ths, 1)
+	assert.Equal(t, optional.Some("edited"), ths[0].Comment.Body)
+	assert.Len(t, ths[0].Replies, 1)
+	// -> a reply can not be replied to, nor changed by another profile
+	err = st.Save(&Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), ParentID: optional.Some(replyId), Body: optional.Some("reply")})
+	assert.ErrorIs(t, err, ErrStorageInvalid)
+	err = st.Update("p1", &Comment{ID: optional.Some(replyId), TaskID: optional.Some("t1"), Body: optional.Some("edited")})
+	assert.ErrorIs(t, err, ErrStorageForbidden)
+}
+
+func TestStorageSQLite_Delete(t *testing.T) {
+	// arrange
+	st, db := newStorageSQLite(t)
+	first := &Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), Body: optional.Some("first")}
+	assert.NoError(t, st.Save(first))
+	firstId, _ := first.ID.Unwrap()
+	assert.NoError(t, st.Save(&Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p2"), ParentID: optional.Some(firstId), Body: optional.Some("reply")}))
+	second := &Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), Body: optional.Some("second")}
+	assert.NoError(t, st.Save(second))
+
+	// act
+	err := st.Delete("p1", "t1", firstId)
+
+	// assert
+	// -> the replies are removed with the comment, and the comments with their task
+	assert.NoError(t, err)
+	ths, err := st.List("t1")
+	assert.NoError(t, err)
+	assert.Len(t, ths, 1)
+	assert.Equal(t, second.ID, ths[0].Comment.ID)
+	assert.Len(t, ths[0].Replies, 0)
+	_, err = db.Exec(`DELETE FROM tasks WHERE id = 't1'`)
+	assert.NoError(t, err)
+	ths, err = st.List("t1")
+	assert.NoError(t, err)
+	assert.Len(t, ths, 0)
+}
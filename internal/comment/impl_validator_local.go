@@ -0,0 +1,78 @@
+package comment
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultMaxBody is the default maximum length of the body of a comment, in characters.
+	DefaultMaxBody = 2000
+)
+
+// ValidatorConfig is the configuration of the local validator.
+type ValidatorConfig struct {
+	// MaxBody is the maximum length of the body of a comment, in characters.
+	MaxBody int
+}
+
+// constructor
+// - cfg is optional (nil for the default config)
+func NewValidatorLocal(cfg *ValidatorConfig) *ValidatorLocal {
+	// default config
+	maxBody := DefaultMaxBody
+	if cfg != nil {
+		if cfg.MaxBody > 0 {
+			maxBody = cfg.MaxBody
+		}
+	}
+
+	return &ValidatorLocal{maxBody: maxBody}
+}
+
+// ValidatorLocal is the local implementation of the comment validator.
+type ValidatorLocal struct {
+	// maxBody is the maximum length of the body, in characters
+	maxBody int
+}
+
+func (v *ValidatorLocal) Validate(c *Comment) (err error) {
+	// check required fields (non nullable)
+	if !c.TaskID.IsSome() {
+		err = fmt.Errorf("%w: task_id", ErrValidatorFieldRequired)
+		return
+	}
+	if !c.AuthorID.IsSome() {
+		err = fmt.Errorf("%w: author_id", ErrValidatorFieldRequired)
+		return
+	}
+	if !c.Body.IsSome() {
+		err = fmt.Errorf("%w: body", ErrValidatorFieldRequired)
+		return
+	}
+
+	// check empty fields and quality values (non nullable)
+	// -> safe to not check err, due to the previous check
+	body, _ := c.Body.Unwrap()
+	if strings.TrimSpace(body) == "" {
+		err = fmt.Errorf("%w: body", ErrValidatorFieldEmpty)
+		return
+	}
+	if utf8.RuneCountInString(body) > v.maxBody {
+		err = fmt.Errorf("%w: body longer than %d characters", ErrValidatorFieldQuality, v.maxBody)
+		return
+	}
+
+	// check parent: a comment can not reply to itself
+	if c.ParentID.IsSome() {
+		parentId, _ := c.ParentID.Unwrap()
+		id, _ := c.ID.Unwrap()
+		if parentId == "" || parentId == id {
+			err = fmt.Errorf("%w: parent_id", ErrValidatorFieldQuality)
+			return
+		}
+	}
+
+	return
+}
@@ -0,0 +1,97 @@
+package comment
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/LNMMusic/optional"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests
+func TestValidatorLocal_Validate(t *testing.T) {
+	type input struct {c *Comment}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+		// cfg is the validator config (nil for the default one)
+		cfg    *ValidatorConfig
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "valid comment",
+			input: input{c: &Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), Body: optional.Some("body")}},
+			output: output{err: nil, errMsg: ""},
+		},
+		{
+			title: "valid reply",
+			input: input{c: &Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), ParentID: optional.Some("c1"), Body: optional.Some("body")}},
+			output: output{err: nil, errMsg: ""},
+		},
+		{
+			title: "valid comment with the maximum length (in characters)",
+			input: input{c: &Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), Body: optional.Some(strings.Repeat("ñ", 10))}},
+			output: output{err: nil, errMsg: ""},
+			cfg: &ValidatorConfig{MaxBody: 10},
+		},
+
+		// failure cases
+		{
+			title: "invalid comment - task_id required",
+			input: input{c: &Comment{AuthorID: optional.Some("p1"), Body: optional.Some("body")}},
+			output: output{err: ErrValidatorFieldRequired, errMsg: "validator field required: task_id"},
+		},
+		{
+			title: "invalid comment - author_id required",
+			input: input{c: &Comment{TaskID: optional.Some("t1"), Body: optional.Some("body")}},
+			output: output{err: ErrValidatorFieldRequired, errMsg: "validator field required: author_id"},
+		},
+		{
+			title: "invalid comment - body required",
+			input: input{c: &Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1")}},
+			output: output{err: ErrValidatorFieldRequired, errMsg: "validator field required: body"},
+		},
+		{
+			title: "invalid comment - body empty",
+			input: input{c: &Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), Body: optional.Some(" \n\t")}},
+			output: output{err: ErrValidatorFieldEmpty, errMsg: "validator field empty: body"},
+		},
+		{
+			title: "invalid comment - body too long (default config)",
+			input: input{c: &Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), Body: optional.Some(strings.Repeat("a", DefaultMaxBody + 1))}},
+			output: output{err: ErrValidatorFieldQuality, errMsg: "validator field quality: body longer than 2000 characters"},
+		},
+		{
+			title: "invalid comment - body too long",
+			input: input{c: &Comment{TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), Body: optional.Some(strings.Repeat("ñ", 11))}},
+			output: output{err: ErrValidatorFieldQuality, errMsg: "validator field quality: body longer than 10 characters"},
+			cfg: &ValidatorConfig{MaxBody: 10},
+		},
+		{
+			title: "invalid comment - reply to itself",
+			input: input{c: &Comment{ID: optional.Some("c1"), TaskID: optional.Some("t1"), AuthorID: optional.Some("p1"), ParentID: optional.Some("c1"), Body: optional.Some("body")}},
+			output: output{err: ErrValidatorFieldQuality, errMsg: "validator field quality: parent_id"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			vl := NewValidatorLocal(c.cfg)
+
+			// act
+			err := vl.Validate(c.input.c)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+		})
+	}
+}
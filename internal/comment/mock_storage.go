@@ -0,0 +1,54 @@
+package comment
+
+import "github.com/stretchr/testify/mock"
+
+// constructor
+func NewStorageMock() *StorageMock {
+	mk := &StorageMock{}
+	mk.SetComment = func(c *Comment) {}
+	return mk
+}
+
+// StorageMock is a mock implementation of the comment storage.
+type StorageMock struct {
+	mock.Mock
+	SetComment func(c *Comment)
+}
+
+func (m *StorageMock) Get(taskId string, id string) (c *Comment, err error) {
+	args := m.Called(taskId, id)
+	c = args.Get(0).(*Comment)
+	err = args.Error(1)
+	return
+}
+
+func (m *StorageMock) List(taskId string) (ths []*Thread, err error) {
+	args := m.Called(taskId)
+	ths = args.Get(0).([]*Thread)
+	err = args.Error(1)
+	return
+}
+
+func (m *StorageMock) Save(c *Comment) (err error) {
+	args := m.Called(c)
+
+	m.SetComment(c)
+
+	err = args.Error(0)
+	return
+}
+
+func (m *StorageMock) Update(authorId string, c *Comment) (err error) {
+	args := m.Called(authorId, c)
+
+	m.SetComment(c)
+
+	err = args.Error(0)
+	return
+}
+
+func (m *StorageMock) Delete(authorId string, taskId string, id string) (err error) {
+	args := m.Called(authorId, taskId, id)
+	err = args.Error(0)
+	return
+}
@@ -0,0 +1,18 @@
+package comment
+
+import "github.com/stretchr/testify/mock"
+
+// constructor
+func NewValidatorMock() *ValidatorMock {
+	return &ValidatorMock{}
+}
+
+// ValidatorMock is the mock implementation of the comment validator.
+type ValidatorMock struct {
+	mock.Mock
+}
+
+func (v *ValidatorMock) Validate(c *Comment) (err error) {
+	args := v.Called(c)
+	return args.Error(0)
+}
@@ -0,0 +1,54 @@
+package idempotency
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Record is a request made with an idempotency key, and its response once it is done.
+type Record struct {
+	// Caller is who made the request (the key is scoped to it)
+	Caller 		string
+	Key 		string
+	// Fingerprint is the hash of the request, a retry must have the same one
+	Fingerprint string
+	// Status is the status code of the response (0 while the request is in progress)
+	Status 		int
+	Header 		http.Header
+	Body 		[]byte
+	// ExpiresAt is the time the key can be used again
+	ExpiresAt 	time.Time
+}
+
+// Done returns whether the response of the request is stored.
+func (rc *Record) Done() bool {
+	return rc.Status != 0
+}
+
+// Store is the interface that wraps the basic methods for a store of idempotency keys.
+// - the records are scoped to the caller: the same key of two callers are two records
+// - an expired record is as if it was not stored
+type Store interface {
+	// Get returns the record of the key of the caller.
+	Get(caller string, key string) (rc *Record, err error)
+
+	// Save reserves the key of the record, with the request in progress.
+	// - it fails with ErrStoreNotUnique if the key of the caller is already reserved
+	Save(rc *Record) (err error)
+
+	// Complete stores the response of the record (status, header and body) for the retries of the request.
+	Complete(rc *Record) (err error)
+
+	// Delete releases the key of the caller, so the request can be made again.
+	Delete(caller string, key string) (err error)
+
+	// Purge removes the expired records of every caller, returning how many.
+	Purge() (n int, err error)
+}
+
+var (
+	ErrStoreInternal  = errors.New("idempotency store internal error")
+	ErrStoreNotFound  = errors.New("idempotency key not found")
+	ErrStoreNotUnique = errors.New("idempotency key not unique")
+)
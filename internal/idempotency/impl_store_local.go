@@ -0,0 +1,118 @@
+package idempotency
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// constructor
+func NewStoreLocal() *StoreLocal {
+	return &StoreLocal{rcs: make(map[string]*Record), now: time.Now}
+}
+
+// StoreLocal is the local implementation of the store of idempotency keys.
+// - it is safe for concurrent use (the retries of a request may run at the same time)
+type StoreLocal struct {
+	mu  sync.Mutex
+	// rcs are the records, by caller and key (see id)
+	rcs map[string]*Record
+	// now returns the current time
+	now func() time.Time
+}
+
+// id returns the id of the key of the caller.
+func id(caller string, key string) string {
+	return caller + "\x00" + key
+}
+
+// lookup returns the record of the key of the caller, if it is not expired.
+func (s *StoreLocal) lookup(caller string, key string) (rc *Record, err error) {
+	rc, ok := s.rcs[id(caller, key)]
+	if !ok || !rc.ExpiresAt.After(s.now()) {
+		rc = nil
+		err = fmt.Errorf("%w: %s", ErrStoreNotFound, key)
+		return
+	}
+	return
+}
+
+func (s *StoreLocal) Get(caller string, key string) (rc *Record, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var stored *Record
+	stored, err = s.lookup(caller, key)
+	if err != nil {
+		return
+	}
+
+	rc = copyRecord(stored)
+	return
+}
+
+func (s *StoreLocal) Save(rc *Record) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, e := s.lookup(rc.Caller, rc.Key); e == nil {
+		err = fmt.Errorf("%w: %s", ErrStoreNotUnique, rc.Key)
+		return
+	}
+
+	// an expired record is replaced
+	s.rcs[id(rc.Caller, rc.Key)] = &Record{Caller: rc.Caller, Key: rc.Key, Fingerprint: rc.Fingerprint, ExpiresAt: rc.ExpiresAt}
+	return
+}
+
+func (s *StoreLocal) Complete(rc *Record) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var stored *Record
+	stored, err = s.lookup(rc.Caller, rc.Key)
+	if err != nil {
+		return
+	}
+
+	cp := copyRecord(rc)
+	stored.Status = cp.Status
+	stored.Header = cp.Header
+	stored.Body = cp.Body
+	return
+}
+
+func (s *StoreLocal) Delete(caller string, key string) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.rcs, id(caller, key))
+	return
+}
+
+func (s *StoreLocal) Purge() (n int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for k, rc := range s.rcs {
+		if !rc.ExpiresAt.After(now) {
+			delete(s.rcs, k)
+			n++
+		}
+	}
+	return
+}
+
+// copyRecord returns a copy of the record that does not share its header nor its body.
+func copyRecord(rc *Record) (cp *Record) {
+	cp = &Record{}
+	*cp = *rc
+	if rc.Header != nil {
+		cp.Header = rc.Header.Clone()
+	}
+	if rc.Body != nil {
+		cp.Body = append([]byte(nil), rc.Body...)
+	}
+	return
+}
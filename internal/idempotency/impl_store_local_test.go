@@ -0,0 +1,222 @@
+package idempotency
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests
+func TestStoreLocal_Get(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type input struct {caller string; key string}
+	type output struct {rc *Record; err error; errMsg string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+	}
+
+	rcs := map[string]*Record{
+		id("p1", "key"): {Caller: "p1", Key: "key", Fingerprint: "f", Status: http.StatusCreated, Header: http.Header{"Etag": {`"1"`}}, Body: []byte(`{}`), ExpiresAt: now.Add(time.Hour)},
+		id("p1", "old"): {Caller: "p1", Key: "old", Fingerprint: "f", Status: http.StatusCreated, ExpiresAt: now},
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "get the record of a key",
+			input: input{caller: "p1", key: "key"},
+			output: output{rc: &Record{Caller: "p1", Key: "key", Fingerprint: "f", Status: http.StatusCreated, Header: http.Header{"Etag": {`"1"`}}, Body: []byte(`{}`), ExpiresAt: now.Add(time.Hour)}},
+		},
+
+		// failure cases
+		{
+			title: "key of another caller",
+			input: input{caller: "p2", key: "key"},
+			output: output{err: ErrStoreNotFound, errMsg: "idempotency key not found: key"},
+		},
+		{
+			title: "expired key",
+			input: input{caller: "p1", key: "old"},
+			output: output{err: ErrStoreNotFound, errMsg: "idempotency key not found: old"},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := NewStoreLocal()
+			st.rcs = rcs
+			st.now = func() time.Time { return now }
+
+			// act
+			rc, err := st.Get(c.input.caller, c.input.key)
+
+			// assert
+			assert.Equal(t, c.output.rc, rc)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestStoreLocal_Save(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type input struct {rc *Record}
+	type output struct {rcs map[string]*Record; err error; errMsg string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+		rcs    map[string]*Record
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "reserve a key",
+			input: input{rc: &Record{Caller: "p1", Key: "key", Fingerprint: "f", ExpiresAt: now.Add(time.Hour)}},
+			output: output{rcs: map[string]*Record{
+				id("p1", "key"): {Caller: "p1", Key: "key", Fingerprint: "f", ExpiresAt: now.Add(time.Hour)},
+				id("p2", "key"): {Caller: "p2", Key: "key", Fingerprint: "g", ExpiresAt: now.Add(time.Hour)},
+			}},
+			rcs: map[string]*Record{id("p2", "key"): {Caller: "p2", Key: "key", Fingerprint: "g", ExpiresAt: now.Add(time.Hour)}},
+		},
+		{
+			title: "reserve an expired key",
+			input: input{rc: &Record{Caller: "p1", Key: "key", Fingerprint: "f", ExpiresAt: now.Add(time.Hour)}},
+			output: output{rcs: map[string]*Record{id("p1", "key"): {Caller: "p1", Key: "key", Fingerprint: "f", ExpiresAt: now.Add(time.Hour)}}},
+			rcs: map[string]*Record{id("p1", "key"): {Caller: "p1", Key: "key", Fingerprint: "g", Status: http.StatusCreated, ExpiresAt: now.Add(-time.Minute)}},
+		},
+
+		// failure cases
+		{
+			title: "key already reserved",
+			input: input{rc: &Record{Caller: "p1", Key: "key", Fingerprint: "f", ExpiresAt: now.Add(time.Hour)}},
+			output: output{
+				rcs: map[string]*Record{id("p1", "key"): {Caller: "p1", Key: "key", Fingerprint: "g", ExpiresAt: now.Add(time.Minute)}},
+				err: ErrStoreNotUnique,
+				errMsg: "idempotency key not unique: key",
+			},
+			rcs: map[string]*Record{id("p1", "key"): {Caller: "p1", Key: "key", Fingerprint: "g", ExpiresAt: now.Add(time.Minute)}},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := NewStoreLocal()
+			st.rcs = c.rcs
+			st.now = func() time.Time { return now }
+
+			// act
+			err := st.Save(c.input.rc)
+
+			// assert
+			assert.Equal(t, c.output.rcs, st.rcs)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestStoreLocal_Complete(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type input struct {rc *Record}
+	type output struct {rcs map[string]*Record; err error; errMsg string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+		rcs    map[string]*Record
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "store the response of a key",
+			input: input{rc: &Record{Caller: "p1", Key: "key", Status: http.StatusCreated, Header: http.Header{"Etag": {`"1"`}}, Body: []byte(`{}`)}},
+			output: output{rcs: map[string]*Record{
+				id("p1", "key"): {Caller: "p1", Key: "key", Fingerprint: "f", Status: http.StatusCreated, Header: http.Header{"Etag": {`"1"`}}, Body: []byte(`{}`), ExpiresAt: now.Add(time.Hour)},
+			}},
+			rcs: map[string]*Record{id("p1", "key"): {Caller: "p1", Key: "key", Fingerprint: "f", ExpiresAt: now.Add(time.Hour)}},
+		},
+
+		// failure cases
+		{
+			title: "key not reserved",
+			input: input{rc: &Record{Caller: "p1", Key: "key", Status: http.StatusCreated}},
+			output: output{rcs: map[string]*Record{}, err: ErrStoreNotFound, errMsg: "idempotency key not found: key"},
+			rcs: map[string]*Record{},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := NewStoreLocal()
+			st.rcs = c.rcs
+			st.now = func() time.Time { return now }
+
+			// act
+			err := st.Complete(c.input.rc)
+
+			// assert
+			assert.Equal(t, c.output.rcs, st.rcs)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestStoreLocal_Delete(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// arrange
+	st := NewStoreLocal()
+	st.now = func() time.Time { return now }
+	st.rcs = map[string]*Record{id("p1", "key"): {Caller: "p1", Key: "key", ExpiresAt: now.Add(time.Hour)}}
+
+	// act
+	err := st.Delete("p1", "key")
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]*Record{}, st.rcs)
+}
+
+func TestStoreLocal_Purge(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// arrange
+	st := NewStoreLocal()
+	st.now = func() time.Time { return now }
+	st.rcs = map[string]*Record{
+		id("p1", "live"): {Caller: "p1", Key: "live", ExpiresAt: now.Add(time.Hour)},
+		id("p1", "expired"): {Caller: "p1", Key: "expired", ExpiresAt: now},
+		id("p2", "expired"): {Caller: "p2", Key: "expired", Status: http.StatusCreated, ExpiresAt: now.Add(-time.Hour)},
+	}
+
+	// act
+	n, err := st.Purge()
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, map[string]*Record{id("p1", "live"): {Caller: "p1", Key: "live", ExpiresAt: now.Add(time.Hour)}}, st.rcs)
+}
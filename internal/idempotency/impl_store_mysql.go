@@ -0,0 +1,171 @@
+package idempotency
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// constructor
+func NewStoreMySQL(db *sql.DB) *StoreMySQL {
+	return &StoreMySQL{db: db, now: time.Now}
+}
+
+// StoreMySQL is an implementation with MySQL of the Store interface.
+// - idempotency_keys table (caller, idem_key, fingerprint, status, header, body, expires_at), with (caller, idem_key) as primary key
+// - the header is stored as json and the times in UTC
+const (
+	QueryGetKey 	   = `SELECT caller, idem_key, fingerprint, status, header, body, expires_at FROM idempotency_keys WHERE caller = ? AND idem_key = ? AND expires_at > ?`
+	QueryDeleteExpired = `DELETE FROM idempotency_keys WHERE caller = ? AND idem_key = ? AND expires_at <= ?`
+	QuerySaveKey 	   = `INSERT INTO idempotency_keys (caller, idem_key, fingerprint, status, header, body, expires_at) VALUES (?, ?, ?, 0, NULL, NULL, ?)`
+	// -> rows affected must count the matched rows (clientFoundRows=true on the dsn)
+	QueryCompleteKey   = `UPDATE idempotency_keys SET status = ?, header = ?, body = ? WHERE caller = ? AND idem_key = ? AND expires_at > ?`
+	QueryDeleteKey 	   = `DELETE FROM idempotency_keys WHERE caller = ? AND idem_key = ?`
+	QueryPurgeKeys 	   = `DELETE FROM idempotency_keys WHERE expires_at <= ?`
+)
+
+type StoreMySQL struct {
+	// db is the database connection.
+	db *sql.DB
+	// now returns the current time
+	now func() time.Time
+}
+
+// Get returns the record of the key of the caller.
+func (s *StoreMySQL) Get(caller string, key string) (rc *Record, err error) {
+	// prepare statement
+	var stmt *sql.Stmt
+	stmt, err = s.db.Prepare(QueryGetKey)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStoreInternal, "prepare")
+		return
+	}
+	defer stmt.Close()
+
+	// execute query
+	var r Record
+	var header, body []byte
+	err = stmt.QueryRow(caller, key, s.now().UTC()).Scan(&r.Caller, &r.Key, &r.Fingerprint, &r.Status, &header, &body, &r.ExpiresAt)
+	if err != nil {
+		switch {
+			case errors.Is(err, sql.ErrNoRows):
+				err = fmt.Errorf("%w: %s", ErrStoreNotFound, key)
+			default:
+				err = fmt.Errorf("%w: %s", ErrStoreInternal, "scan")
+		}
+		return
+	}
+
+	// serialize
+	if header != nil {
+		err = json.Unmarshal(header, &r.Header)
+		if err != nil {
+			err = fmt.Errorf("%w: %s", ErrStoreInternal, "unmarshal")
+			return
+		}
+	}
+	r.Body = body
+
+	rc = &r
+	return
+}
+
+// Save reserves the key of the record, replacing it if it is expired.
+func (s *StoreMySQL) Save(rc *Record) (err error) {
+	_, err = execN(s.db, QueryDeleteExpired, rc.Caller, rc.Key, s.now().UTC())
+	if err != nil {
+		return
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = execN(s.db, QuerySaveKey, rc.Caller, rc.Key, rc.Fingerprint, rc.ExpiresAt.UTC())
+	if err != nil {
+		return
+	}
+	if rowsAffected != 1 {
+		err = fmt.Errorf("%w: %s", ErrStoreInternal, "rows affected")
+		return
+	}
+
+	return
+}
+
+// Complete stores the response of the record.
+func (s *StoreMySQL) Complete(rc *Record) (err error) {
+	// deserialize
+	var header []byte
+	header, err = json.Marshal(rc.Header)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStoreInternal, "marshal")
+		return
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = execN(s.db, QueryCompleteKey, rc.Status, header, rc.Body, rc.Caller, rc.Key, s.now().UTC())
+	if err != nil {
+		return
+	}
+	if rowsAffected != 1 {
+		err = fmt.Errorf("%w: %s", ErrStoreNotFound, rc.Key)
+		return
+	}
+
+	return
+}
+
+// Delete releases the key of the caller.
+func (s *StoreMySQL) Delete(caller string, key string) (err error) {
+	_, err = execN(s.db, QueryDeleteKey, caller, key)
+	return
+}
+
+// Purge removes the expired records.
+func (s *StoreMySQL) Purge() (n int, err error) {
+	var rowsAffected int64
+	rowsAffected, err = execN(s.db, QueryPurgeKeys, s.now().UTC())
+	if err != nil {
+		return
+	}
+
+	n = int(rowsAffected)
+	return
+}
+
+// execN executes the given statement and returns the amount of rows affected.
+// - a duplicate entry fails with ErrStoreNotUnique
+func execN(db *sql.DB, query string, args ...any) (n int64, err error) {
+	// prepare statement
+	var stmt *sql.Stmt
+	stmt, err = db.Prepare(query)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStoreInternal, "prepare")
+		return
+	}
+	defer stmt.Close()
+
+	// execute statement
+	var result sql.Result
+	result, err = stmt.Exec(args...)
+	if err != nil {
+		errMySQL, ok := err.(*mysql.MySQLError)
+		if ok && errMySQL.Number == 1062 {
+			err = fmt.Errorf("%w: %s", ErrStoreNotUnique, "duplicate entry")
+			return
+		}
+		err = fmt.Errorf("%w: %s", ErrStoreInternal, "exec")
+		return
+	}
+
+	// rows affected
+	n, err = result.RowsAffected()
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStoreInternal, "rows affected")
+		return
+	}
+
+	return
+}
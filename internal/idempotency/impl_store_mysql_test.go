@@ -0,0 +1,308 @@
+package idempotency
+
+import (
+	"database/sql"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests
+func TestStoreMySQL_Get(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type output struct {rc *Record; err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+	}
+
+	columns := []string{"caller", "idem_key", "fingerprint", "status", "header", "body", "expires_at"}
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "get the record of a key",
+			output: output{rc: &Record{Caller: "p1", Key: "key", Fingerprint: "f", Status: http.StatusCreated, Header: http.Header{"Etag": {`"1"`}}, Body: []byte(`{}`), ExpiresAt: now.Add(time.Hour)}},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetKey)).
+					ExpectQuery().WithArgs("p1", "key", now).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow("p1", "key", "f", http.StatusCreated, []byte(`{"Etag":["\"1\""]}`), []byte(`{}`), now.Add(time.Hour)))
+			},
+		},
+		{
+			title: "get the record of a key in progress",
+			output: output{rc: &Record{Caller: "p1", Key: "key", Fingerprint: "f", ExpiresAt: now.Add(time.Hour)}},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetKey)).
+					ExpectQuery().WithArgs("p1", "key", now).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow("p1", "key", "f", 0, nil, nil, now.Add(time.Hour)))
+			},
+		},
+
+		// failure cases
+		{
+			title: "key not found",
+			output: output{err: ErrStoreNotFound, errMsg: "idempotency key not found: key"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetKey)).
+					ExpectQuery().WithArgs("p1", "key", now).
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+		},
+		{
+			title: "prepare statement error",
+			output: output{err: ErrStoreInternal, errMsg: "idempotency store internal error: prepare"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryGetKey)).WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStoreMySQL(db)
+			st.now = func() time.Time { return now }
+
+			// act
+			var rc *Record
+			rc, err = st.Get("p1", "key")
+
+			// assert
+			assert.Equal(t, c.output.rc, rc)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStoreMySQL_Save(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+	}
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "reserve a key",
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryDeleteExpired)).
+					ExpectExec().WithArgs("p1", "key", now).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QuerySaveKey)).
+					ExpectExec().WithArgs("p1", "key", "f", now.Add(time.Hour)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+
+		// failure cases
+		{
+			title: "key already reserved",
+			output: output{err: ErrStoreNotUnique, errMsg: "idempotency key not unique: duplicate entry"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryDeleteExpired)).
+					ExpectExec().WithArgs("p1", "key", now).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QuerySaveKey)).
+					ExpectExec().WithArgs("p1", "key", "f", now.Add(time.Hour)).
+					WillReturnError(&mysql.MySQLError{Number: 1062, Message: "duplicate entry"})
+			},
+		},
+		{
+			title: "execute statement error",
+			output: output{err: ErrStoreInternal, errMsg: "idempotency store internal error: exec"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryDeleteExpired)).
+					ExpectExec().WithArgs("p1", "key", now).
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStoreMySQL(db)
+			st.now = func() time.Time { return now }
+
+			// act
+			err = st.Save(&Record{Caller: "p1", Key: "key", Fingerprint: "f", ExpiresAt: now.Add(time.Hour)})
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStoreMySQL_Complete(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+	}
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "store the response of a key",
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryCompleteKey)).
+					ExpectExec().WithArgs(http.StatusCreated, []byte(`{"Etag":["\"1\""]}`), []byte(`{}`), "p1", "key", now).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+
+		// failure cases
+		{
+			title: "key not reserved",
+			output: output{err: ErrStoreNotFound, errMsg: "idempotency key not found: key"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryCompleteKey)).
+					ExpectExec().WithArgs(http.StatusCreated, []byte(`{"Etag":["\"1\""]}`), []byte(`{}`), "p1", "key", now).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStoreMySQL(db)
+			st.now = func() time.Time { return now }
+
+			// act
+			err = st.Complete(&Record{Caller: "p1", Key: "key", Status: http.StatusCreated, Header: http.Header{"Etag": {`"1"`}}, Body: []byte(`{}`)})
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStoreMySQL_Purge(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type output struct {n int; err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+	}
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "remove the expired keys",
+			output: output{n: 2},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryPurgeKeys)).
+					ExpectExec().WithArgs(now).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+		},
+
+		// failure cases
+		{
+			title: "database error",
+			output: output{err: ErrStoreInternal, errMsg: "idempotency store internal error: exec"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryPurgeKeys)).
+					ExpectExec().WithArgs(now).
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStoreMySQL(db)
+			st.now = func() time.Time { return now }
+
+			// act
+			n, err := st.Purge()
+
+			// assert
+			assert.Equal(t, c.output.n, n)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
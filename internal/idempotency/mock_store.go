@@ -0,0 +1,45 @@
+package idempotency
+
+import "github.com/stretchr/testify/mock"
+
+// constructor
+func NewStoreMock() *StoreMock {
+	return &StoreMock{}
+}
+
+// StoreMock is a mock implementation of the store of idempotency keys.
+type StoreMock struct {
+	mock.Mock
+}
+
+func (m *StoreMock) Get(caller string, key string) (rc *Record, err error) {
+	args := m.Called(caller, key)
+	rc = args.Get(0).(*Record)
+	err = args.Error(1)
+	return
+}
+
+func (m *StoreMock) Save(rc *Record) (err error) {
+	args := m.Called(rc)
+	err = args.Error(0)
+	return
+}
+
+func (m *StoreMock) Complete(rc *Record) (err error) {
+	args := m.Called(rc)
+	err = args.Error(0)
+	return
+}
+
+func (m *StoreMock) Delete(caller string, key string) (err error) {
+	args := m.Called(caller, key)
+	err = args.Error(0)
+	return
+}
+
+func (m *StoreMock) Purge() (n int, err error) {
+	args := m.Called()
+	n = args.Int(0)
+	err = args.Error(1)
+	return
+}
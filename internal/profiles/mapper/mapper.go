@@ -1,10 +1,16 @@
 package mapper
 
-import "errors"
+import (
+	"context"
+	"errors"
+)
 
 type ProfileMapper interface {
 	// MapProfile maps user id to profile id
 	MapProfile(userId string) (profileId string, err error)
+
+	// WithContext returns the mapper that maps with the given context, canceled when the context is done
+	WithContext(ctx context.Context) (mp ProfileMapper)
 }
 
 var (
@@ -1,6 +1,10 @@
 package mapper
 
-import "github.com/stretchr/testify/mock"
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
 
 // NewProfileMapperMock returns a new ProfileMapperMock
 func NewProfileMapperMock() *ProfileMapperMock {
@@ -18,4 +22,10 @@ func (m *ProfileMapperMock) MapProfile(userId string) (profileId string, err err
 	profileId = args.String(0)
 	err = args.Error(1)
 	return
+}
+
+// WithContext returns the mock itself (the context is not recorded)
+func (m *ProfileMapperMock) WithContext(ctx context.Context) (mp ProfileMapper) {
+	mp = m
+	return
 }
\ No newline at end of file
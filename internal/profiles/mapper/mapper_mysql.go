@@ -1,6 +1,7 @@
 package mapper
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -8,13 +9,23 @@ import (
 
 // NewProfileMapperMySQL returns a new instance of the MySQL mapper
 func NewProfileMapperMySQL(db *sql.DB) *ProfileMapperMySQL {
-	return &ProfileMapperMySQL{db: db}
+	return &ProfileMapperMySQL{db: db, ctx: context.Background()}
 }
 
 // MapperMySQL is the MySQL implementation of the mapper interface
 type ProfileMapperMySQL struct {
 	// db is the database connection
 	db *sql.DB
+	// ctx is the context the statements are run with (see WithContext)
+	ctx context.Context
+}
+
+// WithContext returns a copy of the mapper that runs its statements with the given context.
+func (impl *ProfileMapperMySQL) WithContext(ctx context.Context) (mp ProfileMapper) {
+	cp := *impl
+	cp.ctx = ctx
+	mp = &cp
+	return
 }
 
 func (impl *ProfileMapperMySQL) MapProfile(userId string) (profileId string, err error) {
@@ -23,14 +34,14 @@ func (impl *ProfileMapperMySQL) MapProfile(userId string) (profileId string, err
 
 	// prepare
 	var stmt *sql.Stmt
-	stmt, err = impl.db.Prepare(query)
+	stmt, err = impl.db.PrepareContext(impl.ctx, query)
 	if err != nil {
 		err = fmt.Errorf("%w. %s", ErrProfileMapperInternal, err.Error())
 		return
 	}
 
 	// execute
-	row := stmt.QueryRow(userId)
+	row := stmt.QueryRowContext(impl.ctx, userId)
 	if row.Err() != nil {
 		switch {
 		case errors.Is(row.Err(), sql.ErrNoRows):
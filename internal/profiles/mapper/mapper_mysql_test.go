@@ -1,6 +1,7 @@
 package mapper
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"testing"
@@ -139,4 +140,26 @@ func TestProfileMapperMySQL_MapProfile(t *testing.T) {
 			assert.NoError(t, mk.ExpectationsWereMet())
 		})
 	}
-}
\ No newline at end of file
+}
+func TestProfileMapperMySQL_WithContext(t *testing.T) {
+	// arrange
+	db, mk, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	impl := NewProfileMapperMySQL(db)
+
+	// -> the request was canceled before the profile is mapped
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// act
+	profileId, err := impl.WithContext(ctx).MapProfile("user-id-1")
+
+	// assert
+	assert.Equal(t, "", profileId)
+	assert.ErrorIs(t, err, ErrProfileMapperInternal)
+	assert.EqualError(t, err, "mapper: internal mapper error. context canceled")
+	// -> nothing was sent to the database
+	assert.NoError(t, mk.ExpectationsWereMet())
+}
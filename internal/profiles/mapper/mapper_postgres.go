@@ -0,0 +1,57 @@
+package mapper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// NewProfileMapperPostgres returns a new instance of the PostgreSQL mapper
+func NewProfileMapperPostgres(db *sql.DB) *ProfileMapperPostgres {
+	return &ProfileMapperPostgres{db: db, ctx: context.Background()}
+}
+
+// ProfileMapperPostgres is the PostgreSQL implementation of the mapper interface
+type ProfileMapperPostgres struct {
+	// db is the database connection
+	db *sql.DB
+	// ctx is the context the statements are run with (see WithContext)
+	ctx context.Context
+}
+
+// WithContext returns a copy of the mapper that runs its statements with the given context.
+func (impl *ProfileMapperPostgres) WithContext(ctx context.Context) (mp ProfileMapper) {
+	cp := *impl
+	cp.ctx = ctx
+	mp = &cp
+	return
+}
+
+func (impl *ProfileMapperPostgres) MapProfile(userId string) (profileId string, err error) {
+	// query
+	query := "SELECT id FROM profiles WHERE user_id = $1"
+
+	// prepare
+	var stmt *sql.Stmt
+	stmt, err = impl.db.PrepareContext(impl.ctx, query)
+	if err != nil {
+		err = fmt.Errorf("%w. %s", ErrProfileMapperInternal, err.Error())
+		return
+	}
+	defer stmt.Close()
+
+	// execute and scan
+	err = stmt.QueryRowContext(impl.ctx, userId).Scan(&profileId)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			err = fmt.Errorf("%w. %s", ErrProfileMapperNotFound, err.Error())
+		default:
+			err = fmt.Errorf("%w. %s", ErrProfileMapperInternal, err.Error())
+		}
+		return
+	}
+
+	return
+}
@@ -0,0 +1,66 @@
+package mapper
+
+import (
+	"api/internal/profiles/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// NewProfileMapperSQLite returns a new instance of the SQLite mapper
+// - the profiles table is created on the database if it does not exist (see storage.SchemaSQLite)
+func NewProfileMapperSQLite(db *sql.DB) (impl *ProfileMapperSQLite, err error) {
+	_, err = db.Exec(storage.SchemaSQLite)
+	if err != nil {
+		err = fmt.Errorf("%w. %s", ErrProfileMapperInternal, err.Error())
+		return
+	}
+
+	impl = &ProfileMapperSQLite{db: db, ctx: context.Background()}
+	return
+}
+
+// ProfileMapperSQLite is the SQLite implementation of the mapper interface
+type ProfileMapperSQLite struct {
+	// db is the database connection
+	db *sql.DB
+	// ctx is the context the statements are run with (see WithContext)
+	ctx context.Context
+}
+
+// WithContext returns a copy of the mapper that runs its statements with the given context.
+func (impl *ProfileMapperSQLite) WithContext(ctx context.Context) (mp ProfileMapper) {
+	cp := *impl
+	cp.ctx = ctx
+	mp = &cp
+	return
+}
+
+func (impl *ProfileMapperSQLite) MapProfile(userId string) (profileId string, err error) {
+	// query
+	query := "SELECT id FROM profiles WHERE user_id = ?"
+
+	// prepare
+	var stmt *sql.Stmt
+	stmt, err = impl.db.PrepareContext(impl.ctx, query)
+	if err != nil {
+		err = fmt.Errorf("%w. %s", ErrProfileMapperInternal, err.Error())
+		return
+	}
+	defer stmt.Close()
+
+	// execute and scan
+	err = stmt.QueryRowContext(impl.ctx, userId).Scan(&profileId)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			err = fmt.Errorf("%w. %s", ErrProfileMapperNotFound, err.Error())
+		default:
+			err = fmt.Errorf("%w. %s", ErrProfileMapperInternal, err.Error())
+		}
+		return
+	}
+
+	return
+}
@@ -0,0 +1,63 @@
+package mapper
+
+import (
+	"api/pkg/sqlite"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests for ProfileMapperSQLite implementation
+func TestProfileMapperSQLite_MapProfile(t *testing.T) {
+	type input struct { userId string }
+	type output struct { profileId string; err error; errMsg string }
+	type testCase struct {
+		name string
+		input input
+		output output
+	}
+
+	cases := []testCase{
+		// valid cases
+		// -> profile found
+		{
+			name: "valid case - profile found",
+			input: input{ userId: "user-id-1" },
+			output: output{ profileId: "profile-id-1", err: nil, errMsg: "" },
+		},
+
+		// error cases
+		// -> profile not found
+		{
+			name: "error case - profile not found",
+			input: input{ userId: "user-id-2" },
+			output: output{ profileId: "", err: ErrProfileMapperNotFound, errMsg: "mapper: mapper not found. sql: no rows in result set" },
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			db, err := sqlite.Open(filepath.Join(t.TempDir(), "profiles.db"))
+			assert.NoError(t, err)
+			defer db.Close()
+
+			impl, err := NewProfileMapperSQLite(db)
+			assert.NoError(t, err)
+			_, err = db.Exec("INSERT INTO profiles (id, user_id) VALUES ('profile-id-1', 'user-id-1')")
+			assert.NoError(t, err)
+
+			// act
+			profileId, err := impl.MapProfile(c.input.userId)
+
+			// assert
+			assert.Equal(t, c.output.profileId, profileId)
+			assert.ErrorIs(t, err, c.output.err)
+			if c.output.err != nil {
+				assert.EqualError(t, err, c.output.errMsg)
+			}
+		})
+	}
+}
@@ -18,4 +18,6 @@ type Profile struct {
 	Phone   optional.Option[string]
 	// Address is the address of the user
 	Address optional.Option[string]
+	// Version is the revision of the profile, increased by every update (set by the storage)
+	Version optional.Option[int]
 }
\ No newline at end of file
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"api/internal/profiles"
+	"context"
 	"errors"
 )
 
@@ -12,6 +13,14 @@ type ProfilesStorage interface {
 
 	// ActivateProfile
 	ActivateProfile(pf *profiles.Profile) (err error)
+
+	// UpdateProfile updates the name, email, phone and address of a profile
+	// - it is only updated if its version is still the stored one, or it fails with ErrStorageVersionMismatch
+	UpdateProfile(pf *profiles.Profile) (err error)
+
+	// WithContext returns the storage that runs its methods with the given context
+	// - they are canceled when the context is done (e.g. the client of the request went away)
+	WithContext(ctx context.Context) (st ProfilesStorage)
 }
 
 var (
@@ -19,4 +28,5 @@ var (
 	ErrStorageInvalidProfile = errors.New("storage: invalid profile")
 	ErrStorageNotFound		 = errors.New("storage: profile not found")
 	ErrStorageNotUnique	     = errors.New("storage: profile not unique")
+	ErrStorageVersionMismatch = errors.New("storage: profile version mismatch")
 )
\ No newline at end of file
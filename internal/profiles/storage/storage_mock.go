@@ -2,6 +2,7 @@ package storage
 
 import (
 	"api/internal/profiles"
+	"context"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -29,4 +30,17 @@ func (mk *ImplProfilesStorageMock) ActivateProfile(pf *profiles.Profile) (err er
 	args := mk.Called(pf)
 	err = args.Error(0)
 	return
+}
+
+// UpdateProfile provides a mock function with given fields: pf
+func (mk *ImplProfilesStorageMock) UpdateProfile(pf *profiles.Profile) (err error) {
+	args := mk.Called(pf)
+	err = args.Error(0)
+	return
+}
+
+// WithContext returns the mock itself (the context is not recorded)
+func (mk *ImplProfilesStorageMock) WithContext(ctx context.Context) (st ProfilesStorage) {
+	st = mk
+	return
 }
\ No newline at end of file
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"api/internal/profiles"
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -18,11 +19,13 @@ type ProfileMySQL struct {
 	Email   sql.NullString
 	Phone   sql.NullString
 	Address sql.NullString
+	Version sql.NullInt64
 }
 
 func NewImplProfilesStorageMySQL(db *sql.DB) (s *ImplProfilesStorageMySQL) {
 	s = &ImplProfilesStorageMySQL{
 		db: db,
+		ctx: context.Background(),
 	}
 	return
 }
@@ -31,23 +34,33 @@ func NewImplProfilesStorageMySQL(db *sql.DB) (s *ImplProfilesStorageMySQL) {
 type ImplProfilesStorageMySQL struct {
 	// db is the database connection
 	db *sql.DB
+	// ctx is the context the statements are run with (see WithContext)
+	ctx context.Context
+}
+
+// WithContext returns a copy of the storage that runs its statements with the given context.
+func (s *ImplProfilesStorageMySQL) WithContext(ctx context.Context) (st ProfilesStorage) {
+	cp := *s
+	cp.ctx = ctx
+	st = &cp
+	return
 }
 
 // GetProfileById returns a profile by its id
 func (s *ImplProfilesStorageMySQL) GetProfileById(id string) (pf *profiles.Profile, err error) {
 	// query
-	query := "SELECT id, user_id, name, email, phone, address FROM profiles WHERE id = ?"
+	query := "SELECT id, user_id, name, email, phone, address, version FROM profiles WHERE id = ?"
 
 	// prepare statement
 	var stmt *sql.Stmt
-	stmt, err = s.db.Prepare(query)
+	stmt, err = s.db.PrepareContext(s.ctx, query)
 	if err != nil {
 		err = fmt.Errorf("%w. %s", ErrStorageInternal, err.Error())
 		return
 	}
 
 	// execute query
-	row := stmt.QueryRow(id)
+	row := stmt.QueryRowContext(s.ctx, id)
 	if row.Err() != nil {
 		switch {
 		case errors.Is(row.Err(), sql.ErrNoRows):
@@ -60,7 +73,7 @@ func (s *ImplProfilesStorageMySQL) GetProfileById(id string) (pf *profiles.Profi
 
 	// scan row
 	var pfMySQL ProfileMySQL
-	err = row.Scan(&pfMySQL.ID, &pfMySQL.UserID, &pfMySQL.Name, &pfMySQL.Email, &pfMySQL.Phone, &pfMySQL.Address)
+	err = row.Scan(&pfMySQL.ID, &pfMySQL.UserID, &pfMySQL.Name, &pfMySQL.Email, &pfMySQL.Phone, &pfMySQL.Address, &pfMySQL.Version)
 	if err != nil {
 		err = fmt.Errorf("%w. %s", ErrStorageInternal, err.Error())
 		return
@@ -86,6 +99,9 @@ func (s *ImplProfilesStorageMySQL) GetProfileById(id string) (pf *profiles.Profi
 	if pfMySQL.Address.Valid {
 		pf.Address = optional.Some(pfMySQL.Address.String)
 	}
+	if pfMySQL.Version.Valid {
+		pf.Version = optional.Some(int(pfMySQL.Version.Int64))
+	}
 
 	return
 }
@@ -124,7 +140,7 @@ func (s *ImplProfilesStorageMySQL) ActivateProfile(pf *profiles.Profile) (err er
 
 	// prepare statement
 	var stmt *sql.Stmt
-	stmt, err = s.db.Prepare(query)
+	stmt, err = s.db.PrepareContext(s.ctx, query)
 	if err != nil {
 		err = fmt.Errorf("%w. %s", ErrStorageInternal, err.Error())
 		return
@@ -132,7 +148,7 @@ func (s *ImplProfilesStorageMySQL) ActivateProfile(pf *profiles.Profile) (err er
 
 	// execute query
 	var result sql.Result
-	result, err = stmt.Exec(pfMySQL.ID, pfMySQL.UserID, pfMySQL.Name, pfMySQL.Email, pfMySQL.Phone, pfMySQL.Address)
+	result, err = stmt.ExecContext(s.ctx, pfMySQL.ID, pfMySQL.UserID, pfMySQL.Name, pfMySQL.Email, pfMySQL.Phone, pfMySQL.Address)
 	if err != nil {
 		errMySQL, ok := err.(*mysql.MySQLError)
 		if ok {
@@ -162,5 +178,89 @@ func (s *ImplProfilesStorageMySQL) ActivateProfile(pf *profiles.Profile) (err er
 		return
 	}
 
+	// set default values
+	// -> the version column defaults to 1
+	pf.Version = optional.Some(1)
+
+	return
+}
+
+// UpdateProfile
+func (s *ImplProfilesStorageMySQL) UpdateProfile(pf *profiles.Profile) (err error) {
+	// deserialize profiles.Profile to ProfileMySQL
+	var pfMySQL ProfileMySQL
+	if pf.ID.IsSome() {
+		pfMySQL.ID.String, _ = pf.ID.Unwrap()
+		pfMySQL.ID.Valid = true
+	}
+	if pf.Name.IsSome() {
+		pfMySQL.Name.String, _ = pf.Name.Unwrap()
+		pfMySQL.Name.Valid = true
+	}
+	if pf.Email.IsSome() {
+		pfMySQL.Email.String, _ = pf.Email.Unwrap()
+		pfMySQL.Email.Valid = true
+	}
+	if pf.Phone.IsSome() {
+		pfMySQL.Phone.String, _ = pf.Phone.Unwrap()
+		pfMySQL.Phone.Valid = true
+	}
+	if pf.Address.IsSome() {
+		pfMySQL.Address.String, _ = pf.Address.Unwrap()
+		pfMySQL.Address.Valid = true
+	}
+	if pf.Version.IsSome() {
+		version, _ := pf.Version.Unwrap()
+		pfMySQL.Version = sql.NullInt64{Int64: int64(version), Valid: true}
+	}
+	// -> the update is conditional on the version
+	if !pfMySQL.Version.Valid {
+		err = fmt.Errorf("%w. %s", ErrStorageInvalidProfile, "version is required")
+		return
+	}
+
+	// query
+	query := "UPDATE profiles SET name = ?, email = ?, phone = ?, address = ?, version = version + 1 WHERE id = ? AND version = ?"
+
+	// prepare statement
+	var stmt *sql.Stmt
+	stmt, err = s.db.PrepareContext(s.ctx, query)
+	if err != nil {
+		err = fmt.Errorf("%w. %s", ErrStorageInternal, err.Error())
+		return
+	}
+	defer stmt.Close()
+
+	// execute query
+	var result sql.Result
+	result, err = stmt.ExecContext(s.ctx, pfMySQL.Name, pfMySQL.Email, pfMySQL.Phone, pfMySQL.Address, pfMySQL.ID, pfMySQL.Version)
+	if err != nil {
+		err = fmt.Errorf("%w. %s", ErrStorageInternal, err.Error())
+		return
+	}
+
+	// check affected rows
+	var affectedRows int64
+	affectedRows, err = result.RowsAffected()
+	if err != nil {
+		err = fmt.Errorf("%w. %s", ErrStorageInternal, err.Error())
+		return
+	}
+
+	// -> no rows affected means the profile does not exist or was updated meanwhile
+	if affectedRows != 1 {
+		var stored *profiles.Profile
+		stored, err = s.GetProfileById(pfMySQL.ID.String)
+		if err != nil {
+			return
+		}
+		version, _ := stored.Version.Unwrap()
+		err = fmt.Errorf("%w. %s", ErrStorageVersionMismatch, fmt.Sprintf("stored version %d", version))
+		return
+	}
+
+	// set values
+	pf.Version = optional.Some(int(pfMySQL.Version.Int64) + 1)
+
 	return
 }
\ No newline at end of file
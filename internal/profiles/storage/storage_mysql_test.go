@@ -2,10 +2,12 @@ package storage
 
 import (
 	"api/internal/profiles"
+	"context"
 	"database/sql"
 	"errors"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/LNMMusic/optional"
@@ -38,14 +40,15 @@ func TestImplProfilesStorageMySQL_GetProfileById(t *testing.T) {
 					Email: optional.Some("johndoe@gmail.com"),
 					Phone: optional.Some("1234567890"),
 					Address: optional.Some("address"),
+					Version: optional.Some(1),
 				},
 				err: nil, errMsg: "",
 			},
 			setUpDB: func (mk sqlmock.Sqlmock) {
 				// query
-				query := "SELECT id, user_id, name, email, phone, address FROM profiles WHERE id = ?"
+				query := "SELECT id, user_id, name, email, phone, address, version FROM profiles WHERE id = ?"
 				
-				cols := []string{"id", "user_id", "name", "email", "phone", "address"}
+				cols := []string{"id", "user_id", "name", "email", "phone", "address", "version"}
 				rows := sqlmock.NewRows(cols)
 				rows.AddRow(
 					sql.NullString{String: "id", Valid: true},
@@ -54,6 +57,7 @@ func TestImplProfilesStorageMySQL_GetProfileById(t *testing.T) {
 					sql.NullString{String: "johndoe@gmail.com", Valid: true},
 					sql.NullString{String: "1234567890", Valid: true},
 					sql.NullString{String: "address", Valid: true},
+					sql.NullInt64{Int64: 1, Valid: true},
 				)
 
 				// expectations
@@ -75,7 +79,7 @@ func TestImplProfilesStorageMySQL_GetProfileById(t *testing.T) {
 			},
 			setUpDB: func (mk sqlmock.Sqlmock) {
 				// query
-				query := "SELECT id, user_id, name, email, phone, address FROM profiles WHERE id = ?"
+				query := "SELECT id, user_id, name, email, phone, address, version FROM profiles WHERE id = ?"
 
 				// expectations
 				mk.
@@ -94,7 +98,7 @@ func TestImplProfilesStorageMySQL_GetProfileById(t *testing.T) {
 			},
 			setUpDB: func (mk sqlmock.Sqlmock) {
 				// query
-				query := "SELECT id, user_id, name, email, phone, address FROM profiles WHERE id = ?"
+				query := "SELECT id, user_id, name, email, phone, address, version FROM profiles WHERE id = ?"
 
 				// expectations
 				mk.
@@ -113,7 +117,7 @@ func TestImplProfilesStorageMySQL_GetProfileById(t *testing.T) {
 			},
 			setUpDB: func (mk sqlmock.Sqlmock) {
 				// query
-				query := "SELECT id, user_id, name, email, phone, address FROM profiles WHERE id = ?"
+				query := "SELECT id, user_id, name, email, phone, address, version FROM profiles WHERE id = ?"
 
 				// expectations
 				mk.
@@ -150,6 +154,36 @@ func TestImplProfilesStorageMySQL_GetProfileById(t *testing.T) {
 	}
 }
 
+func TestImplProfilesStorageMySQL_WithContext(t *testing.T) {
+	// arrange
+	db, mk, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	// -> the query outlasts the deadline of the request
+	query := "SELECT id, user_id, name, email, phone, address, version FROM profiles WHERE id = ?"
+	mk.
+		ExpectPrepare(regexp.QuoteMeta(query)).
+		ExpectQuery().WithArgs("id").
+		WillDelayFor(time.Second).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name", "email", "phone", "address", "version"}))
+
+	impl := NewImplProfilesStorageMySQL(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// act
+	pf, err := impl.WithContext(ctx).GetProfileById("id")
+
+	// assert
+	assert.Nil(t, pf)
+	assert.ErrorIs(t, err, ErrStorageInternal)
+	assert.EqualError(t, err, "storage: internal storage error. canceling query due to user request")
+	// -> expectations
+	assert.NoError(t, mk.ExpectationsWereMet())
+}
+
 func TestImplProfilesStorageMySQL_ActiveProfile(t *testing.T) {
 	type input struct { pf *profiles.Profile }
 	type output struct { err error; errMsg string }
@@ -329,4 +363,147 @@ func TestImplProfilesStorageMySQL_ActiveProfile(t *testing.T) {
 			assert.NoError(t, mk.ExpectationsWereMet())
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestImplProfilesStorageMySQL_UpdateProfile(t *testing.T) {
+	type input struct { pf *profiles.Profile }
+	type output struct { version optional.Option[int]; err error; errMsg string }
+	type testCase struct {
+		name string
+		input input
+		output output
+		// set-up
+		setUpDB func (mk sqlmock.Sqlmock)
+	}
+
+	// queries
+	query := "UPDATE profiles SET name = ?, email = ?, phone = ?, address = ?, version = version + 1 WHERE id = ? AND version = ?"
+	queryGet := "SELECT id, user_id, name, email, phone, address, version FROM profiles WHERE id = ?"
+	cols := []string{"id", "user_id", "name", "email", "phone", "address", "version"}
+
+	cases := []testCase{
+		// valid cases
+		{
+			name: "valid case - success",
+			input: input{
+				pf: &profiles.Profile{
+					ID: optional.Some("id"),
+					Name: optional.Some("name"),
+					Email: optional.Some("johndoe@gmail.com"),
+					Version: optional.Some(2),
+				},
+			},
+			output: output{version: optional.Some(3), err: nil, errMsg: ""},
+			setUpDB: func (mk sqlmock.Sqlmock) {
+				// expectations
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(query)).
+					ExpectExec().WithArgs(
+						sql.NullString{String: "name", Valid: true},
+						sql.NullString{String: "johndoe@gmail.com", Valid: true},
+						sql.NullString{},
+						sql.NullString{},
+						sql.NullString{String: "id", Valid: true},
+						sql.NullInt64{Int64: 2, Valid: true},
+					).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+
+		// invalid cases
+		// -> version required
+		{
+			name: "invalid case - version required",
+			input: input{pf: &profiles.Profile{ID: optional.Some("id")}},
+			output: output{
+				version: optional.None[int](),
+				err: ErrStorageInvalidProfile, errMsg: "storage: invalid profile. version is required",
+			},
+			setUpDB: func (mk sqlmock.Sqlmock) {},
+		},
+		// -> stale version
+		{
+			name: "invalid case - version mismatch",
+			input: input{pf: &profiles.Profile{ID: optional.Some("id"), Version: optional.Some(2)}},
+			output: output{
+				version: optional.Some(2),
+				err: ErrStorageVersionMismatch, errMsg: "storage: profile version mismatch. stored version 3",
+			},
+			setUpDB: func (mk sqlmock.Sqlmock) {
+				// expectations
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(query)).
+					ExpectExec().
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				rows := sqlmock.NewRows(cols)
+				rows.AddRow("id", "user_id", nil, nil, nil, nil, 3)
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(queryGet)).
+					ExpectQuery().WithArgs("id").
+					WillReturnRows(rows)
+			},
+		},
+		// -> profile not found
+		{
+			name: "invalid case - not found",
+			input: input{pf: &profiles.Profile{ID: optional.Some("id"), Version: optional.Some(2)}},
+			output: output{
+				version: optional.Some(2),
+				err: ErrStorageNotFound, errMsg: "storage: profile not found. sql: no rows in result set",
+			},
+			setUpDB: func (mk sqlmock.Sqlmock) {
+				// expectations
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(query)).
+					ExpectExec().
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(queryGet)).
+					ExpectQuery().WithArgs("id").
+					WillReturnError(sql.ErrNoRows)
+			},
+		},
+		// -> exec error
+		{
+			name: "invalid case - exec internal error",
+			input: input{pf: &profiles.Profile{ID: optional.Some("id"), Version: optional.Some(2)}},
+			output: output{
+				version: optional.Some(2),
+				err: ErrStorageInternal, errMsg: "storage: internal storage error. sql: exec error",
+			},
+			setUpDB: func (mk sqlmock.Sqlmock) {
+				// expectations
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(query)).
+					ExpectExec().
+					WillReturnError(errors.New("sql: exec error"))
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			c.setUpDB(mk)
+
+			impl := NewImplProfilesStorageMySQL(db)
+
+			// act
+			err = impl.UpdateProfile(c.input.pf)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if c.output.err != nil {
+				assert.EqualError(t, err, c.output.errMsg)
+			}
+			assert.Equal(t, c.output.version, c.input.pf.Version)
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
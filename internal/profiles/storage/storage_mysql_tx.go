@@ -3,6 +3,7 @@ package storage
 import (
 	"api/internal/profiles"
 	"api/pkg/mysql/transactioner"
+	"context"
 	"errors"
 	"fmt"
 )
@@ -23,6 +24,15 @@ type ImplProfilesStorageMySQLTx struct {
 	tr transactioner.Transactioner
 }
 
+// WithContext returns a copy of the storage that runs its transactions, and the methods of the wrapped storage, with the given context
+func (s *ImplProfilesStorageMySQLTx) WithContext(ctx context.Context) (st ProfilesStorage) {
+	cp := *s
+	cp.st = s.st.WithContext(ctx)
+	cp.tr = s.tr.WithContext(ctx)
+	st = &cp
+	return
+}
+
 // GetProfileById returns a profile by its userId
 func (s *ImplProfilesStorageMySQLTx) GetProfileById(id string) (pf *profiles.Profile, err error) {
 	// run operation
@@ -68,5 +78,29 @@ func (s *ImplProfilesStorageMySQLTx) ActivateProfile(pf *profiles.Profile) (err
 		return
 	}
 
+	return
+}
+
+// UpdateProfile
+func (s *ImplProfilesStorageMySQLTx) UpdateProfile(pf *profiles.Profile) (err error) {
+	// run operation
+	e := s.tr.Do(func() (e error) {
+		// update from storage (wrapping process)
+		err = s.st.UpdateProfile(pf)
+		if err != nil {
+			e = err
+		}
+		return
+	})
+	if e != nil {
+		switch {
+		case errors.Is(e, transactioner.ErrTransactionOperation):
+			return
+		default:
+			err = fmt.Errorf("%w. %s", ErrStorageInternal, e.Error())
+		}
+		return
+	}
+
 	return
 }
\ No newline at end of file
@@ -213,4 +213,84 @@ func TestImplProfilesStorageMySQLTx_ActivateProfile(t *testing.T) {
 			tr.AssertExpectations(t)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestImplProfilesStorageMySQLTx_UpdateProfile(t *testing.T) {
+	type input struct { pf *profiles.Profile }
+	type output struct { err error; errMsg string }
+	type testCase struct {
+		name string
+		input input
+		output output
+		// set-up
+		setUpStorage func(mk *ImplProfilesStorageMock)
+		setUpTransactioner func(mk *transactioner.ImplTransactionerMock)
+	}
+
+	cases := []testCase{
+		// valid cases
+		{
+			name: "valid case",
+			input: input{ pf: &profiles.Profile{} },
+			output: output{ err: nil, errMsg: "" },
+			setUpStorage: func(mk *ImplProfilesStorageMock) {
+				mk.On("UpdateProfile", &profiles.Profile{}).Return(nil)
+			},
+			setUpTransactioner: func(mk *transactioner.ImplTransactionerMock) {
+				mk.On("Do", mock.Anything).Return(nil)
+			},
+		},
+
+		// invalid cases
+		// -> operation error
+		{
+			name: "operation error - version mismatch",
+			input: input{ pf: &profiles.Profile{} },
+			output: output{ err: ErrStorageVersionMismatch, errMsg: "storage: profile version mismatch" },
+			setUpStorage: func(mk *ImplProfilesStorageMock) {
+				mk.On("UpdateProfile", &profiles.Profile{}).Return(ErrStorageVersionMismatch)
+			},
+			setUpTransactioner: func(mk *transactioner.ImplTransactionerMock) {
+				mk.On("Do", mock.Anything).Return(transactioner.ErrTransactionOperation)
+			},
+		},
+		// -> default error
+		{
+			name: "default error - commit transaction",
+			input: input{ pf: &profiles.Profile{} },
+			output: output{ err: ErrStorageInternal, errMsg: "storage: internal storage error. transactioner: cannot commit transaction" },
+			setUpStorage: func(mk *ImplProfilesStorageMock) {
+				mk.On("UpdateProfile", &profiles.Profile{}).Return(nil)
+			},
+			setUpTransactioner: func(mk *transactioner.ImplTransactionerMock) {
+				mk.On("Do", mock.Anything).Return(transactioner.ErrTransactionCommit)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			st := NewImplProfilesStorageMock()
+			c.setUpStorage(st)
+
+			tr := transactioner.NewImplTransactionerMock()
+			c.setUpTransactioner(tr)
+
+			impl := NewImplProfilesStorageMySQLTx(st, tr)
+
+			// act
+			err := impl.UpdateProfile(c.input.pf)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if c.output.err != nil {
+				assert.EqualError(t, err, c.output.errMsg)
+			}
+			// -> expectations
+			st.AssertExpectations(t)
+			tr.AssertExpectations(t)
+		})
+	}
+}
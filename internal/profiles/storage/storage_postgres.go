@@ -0,0 +1,253 @@
+package storage
+
+import (
+	"api/internal/profiles"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/LNMMusic/optional"
+	"github.com/lib/pq"
+)
+
+// NewImplProfilesStoragePostgres returns the PostgreSQL storage of the profiles
+func NewImplProfilesStoragePostgres(db *sql.DB) (s *ImplProfilesStoragePostgres) {
+	s = &ImplProfilesStoragePostgres{
+		db: db,
+		ctx: context.Background(),
+	}
+	return
+}
+
+// ImplProfilesStoragePostgres is the implementation of the Storage interface for PostgreSQL
+// - the rows are scanned into the MySQL dto (same columns)
+type ImplProfilesStoragePostgres struct {
+	// db is the database connection
+	db *sql.DB
+	// ctx is the context the statements are run with (see WithContext)
+	ctx context.Context
+}
+
+// WithContext returns a copy of the storage that runs its statements with the given context.
+func (s *ImplProfilesStoragePostgres) WithContext(ctx context.Context) (st ProfilesStorage) {
+	cp := *s
+	cp.ctx = ctx
+	st = &cp
+	return
+}
+
+// GetProfileById returns a profile by its id
+func (s *ImplProfilesStoragePostgres) GetProfileById(id string) (pf *profiles.Profile, err error) {
+	// query
+	query := "SELECT id, user_id, name, email, phone, address, version FROM profiles WHERE id = $1"
+
+	// prepare statement
+	var stmt *sql.Stmt
+	stmt, err = s.db.PrepareContext(s.ctx, query)
+	if err != nil {
+		err = fmt.Errorf("%w. %s", ErrStorageInternal, err.Error())
+		return
+	}
+	defer stmt.Close()
+
+	// execute query and scan row
+	var pfPostgres ProfileMySQL
+	err = stmt.QueryRowContext(s.ctx, id).Scan(&pfPostgres.ID, &pfPostgres.UserID, &pfPostgres.Name, &pfPostgres.Email, &pfPostgres.Phone, &pfPostgres.Address, &pfPostgres.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			err = fmt.Errorf("%w. %s", ErrStorageNotFound, err.Error())
+		default:
+			err = fmt.Errorf("%w. %s", ErrStorageInternal, err.Error())
+		}
+		return
+	}
+
+	// serialize ProfileMySQL to profiles.Profile
+	pf = new(profiles.Profile)
+	if pfPostgres.ID.Valid {
+		pf.ID = optional.Some(pfPostgres.ID.String)
+	}
+	if pfPostgres.UserID.Valid {
+		pf.UserID = optional.Some(pfPostgres.UserID.String)
+	}
+	if pfPostgres.Name.Valid {
+		pf.Name = optional.Some(pfPostgres.Name.String)
+	}
+	if pfPostgres.Email.Valid {
+		pf.Email = optional.Some(pfPostgres.Email.String)
+	}
+	if pfPostgres.Phone.Valid {
+		pf.Phone = optional.Some(pfPostgres.Phone.String)
+	}
+	if pfPostgres.Address.Valid {
+		pf.Address = optional.Some(pfPostgres.Address.String)
+	}
+	if pfPostgres.Version.Valid {
+		pf.Version = optional.Some(int(pfPostgres.Version.Int64))
+	}
+
+	return
+}
+
+// ActivateProfile
+func (s *ImplProfilesStoragePostgres) ActivateProfile(pf *profiles.Profile) (err error) {
+	// deserialize profiles.Profile to ProfileMySQL
+	var pfPostgres ProfileMySQL
+	if pf.ID.IsSome() {
+		pfPostgres.ID.String, _ = pf.ID.Unwrap()
+		pfPostgres.ID.Valid = true
+	}
+	if pf.UserID.IsSome() {
+		pfPostgres.UserID.String, _ = pf.UserID.Unwrap()
+		pfPostgres.UserID.Valid = true
+	}
+	if pf.Name.IsSome() {
+		pfPostgres.Name.String, _ = pf.Name.Unwrap()
+		pfPostgres.Name.Valid = true
+	}
+	if pf.Email.IsSome() {
+		pfPostgres.Email.String, _ = pf.Email.Unwrap()
+		pfPostgres.Email.Valid = true
+	}
+	if pf.Phone.IsSome() {
+		pfPostgres.Phone.String, _ = pf.Phone.Unwrap()
+		pfPostgres.Phone.Valid = true
+	}
+	if pf.Address.IsSome() {
+		pfPostgres.Address.String, _ = pf.Address.Unwrap()
+		pfPostgres.Address.Valid = true
+	}
+
+	// query
+	query := "INSERT INTO profiles (id, user_id, name, email, phone, address) VALUES ($1, $2, $3, $4, $5, $6)"
+
+	// prepare statement
+	var stmt *sql.Stmt
+	stmt, err = s.db.PrepareContext(s.ctx, query)
+	if err != nil {
+		err = fmt.Errorf("%w. %s", ErrStorageInternal, err.Error())
+		return
+	}
+	defer stmt.Close()
+
+	// execute query
+	var result sql.Result
+	result, err = stmt.ExecContext(s.ctx, pfPostgres.ID, pfPostgres.UserID, pfPostgres.Name, pfPostgres.Email, pfPostgres.Phone, pfPostgres.Address)
+	if err != nil {
+		// -> unique_violation: the id or the user already has a profile
+		errPostgres, ok := err.(*pq.Error)
+		if ok {
+			switch errPostgres.Code {
+			case "23505":
+				err = fmt.Errorf("%w. %s", ErrStorageNotUnique, err.Error())
+			default:
+				err = fmt.Errorf("%w. %s", ErrStorageInternal, err.Error())
+			}
+			return
+		}
+
+		err = fmt.Errorf("%w. %s", ErrStorageInternal, err.Error())
+		return
+	}
+
+	// check affected rows
+	var affectedRows int64
+	affectedRows, err = result.RowsAffected()
+	if err != nil {
+		err = fmt.Errorf("%w. %s", ErrStorageInternal, err.Error())
+		return
+	}
+
+	if affectedRows != 1 {
+		err = fmt.Errorf("%w. %s", ErrStorageInternal, "rows affected != 1")
+		return
+	}
+
+	// set default values
+	// -> the version column defaults to 1
+	pf.Version = optional.Some(1)
+
+	return
+}
+
+// UpdateProfile
+func (s *ImplProfilesStoragePostgres) UpdateProfile(pf *profiles.Profile) (err error) {
+	// deserialize profiles.Profile to ProfileMySQL
+	var pfPostgres ProfileMySQL
+	if pf.ID.IsSome() {
+		pfPostgres.ID.String, _ = pf.ID.Unwrap()
+		pfPostgres.ID.Valid = true
+	}
+	if pf.Name.IsSome() {
+		pfPostgres.Name.String, _ = pf.Name.Unwrap()
+		pfPostgres.Name.Valid = true
+	}
+	if pf.Email.IsSome() {
+		pfPostgres.Email.String, _ = pf.Email.Unwrap()
+		pfPostgres.Email.Valid = true
+	}
+	if pf.Phone.IsSome() {
+		pfPostgres.Phone.String, _ = pf.Phone.Unwrap()
+		pfPostgres.Phone.Valid = true
+	}
+	if pf.Address.IsSome() {
+		pfPostgres.Address.String, _ = pf.Address.Unwrap()
+		pfPostgres.Address.Valid = true
+	}
+	if pf.Version.IsSome() {
+		version, _ := pf.Version.Unwrap()
+		pfPostgres.Version = sql.NullInt64{Int64: int64(version), Valid: true}
+	}
+	// -> the update is conditional on the version
+	if !pfPostgres.Version.Valid {
+		err = fmt.Errorf("%w. %s", ErrStorageInvalidProfile, "version is required")
+		return
+	}
+
+	// query
+	query := "UPDATE profiles SET name = $1, email = $2, phone = $3, address = $4, version = version + 1 WHERE id = $5 AND version = $6"
+
+	// prepare statement
+	var stmt *sql.Stmt
+	stmt, err = s.db.PrepareContext(s.ctx, query)
+	if err != nil {
+		err = fmt.Errorf("%w. %s", ErrStorageInternal, err.Error())
+		return
+	}
+	defer stmt.Close()
+
+	// execute query
+	var result sql.Result
+	result, err = stmt.ExecContext(s.ctx, pfPostgres.Name, pfPostgres.Email, pfPostgres.Phone, pfPostgres.Address, pfPostgres.ID, pfPostgres.Version)
+	if err != nil {
+		err = fmt.Errorf("%w. %s", ErrStorageInternal, err.Error())
+		return
+	}
+
+	// check affected rows
+	var affectedRows int64
+	affectedRows, err = result.RowsAffected()
+	if err != nil {
+		err = fmt.Errorf("%w. %s", ErrStorageInternal, err.Error())
+		return
+	}
+
+	// -> no rows affected means the profile does not exist or was updated meanwhile
+	if affectedRows != 1 {
+		var stored *profiles.Profile
+		stored, err = s.GetProfileById(pfPostgres.ID.String)
+		if err != nil {
+			return
+		}
+		version, _ := stored.Version.Unwrap()
+		err = fmt.Errorf("%w. %s", ErrStorageVersionMismatch, fmt.Sprintf("stored version %d", version))
+		return
+	}
+
+	// set values
+	pf.Version = optional.Some(int(pfPostgres.Version.Int64) + 1)
+
+	return
+}
\ No newline at end of file
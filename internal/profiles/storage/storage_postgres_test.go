@@ -0,0 +1,477 @@
+package storage
+
+import (
+	"api/internal/profiles"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/LNMMusic/optional"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests for ImplProfilesStoragePostgres
+func TestImplProfilesStoragePostgres_GetProfileById(t *testing.T) {
+	type input struct { id string }
+	type output struct { pf *profiles.Profile; err error; errMsg string }
+	type test struct {
+		name string
+		input input
+		output output
+		// set-up
+		setUpDB func (mk sqlmock.Sqlmock)
+	}
+
+	cases := []test{
+		// valid cases
+		{
+			name: "valid case - found",
+			input: input{id: "id"},
+			output: output{
+				pf: &profiles.Profile{
+					ID: optional.Some("id"),
+					UserID: optional.Some("user_id"),
+					Name: optional.Some("name"),
+					Email: optional.Some("johndoe@gmail.com"),
+					Phone: optional.Some("1234567890"),
+					Address: optional.Some("address"),
+					Version: optional.Some(1),
+				},
+				err: nil, errMsg: "",
+			},
+			setUpDB: func (mk sqlmock.Sqlmock) {
+				// query
+				query := "SELECT id, user_id, name, email, phone, address, version FROM profiles WHERE id = $1"
+				
+				cols := []string{"id", "user_id", "name", "email", "phone", "address", "version"}
+				rows := sqlmock.NewRows(cols)
+				rows.AddRow(
+					sql.NullString{String: "id", Valid: true},
+					sql.NullString{String: "user_id", Valid: true},
+					sql.NullString{String: "name", Valid: true},
+					sql.NullString{String: "johndoe@gmail.com", Valid: true},
+					sql.NullString{String: "1234567890", Valid: true},
+					sql.NullString{String: "address", Valid: true},
+					sql.NullInt64{Int64: 1, Valid: true},
+				)
+
+				// expectations
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(query)).
+					ExpectQuery().WithArgs("id").
+					WillReturnRows(rows)
+			},
+		},
+
+		// invalid cases
+		// -> query error. no rows
+		{
+			name: "invalid case - not found",
+			input: input{id: "id"},
+			output: output{
+				pf: nil,
+				err: ErrStorageNotFound, errMsg: "storage: profile not found. sql: no rows in result set",
+			},
+			setUpDB: func (mk sqlmock.Sqlmock) {
+				// query
+				query := "SELECT id, user_id, name, email, phone, address, version FROM profiles WHERE id = $1"
+
+				// expectations
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(query)).
+					ExpectQuery().WithArgs("id").
+					WillReturnError(sql.ErrNoRows)
+			},
+		},
+		// -> query error. internal error
+		{
+			name: "invalid case - scan internal error",
+			input: input{id: "id"},
+			output: output{
+				pf: nil,
+				err: ErrStorageInternal, errMsg: "storage: internal storage error. sql: internal error",
+			},
+			setUpDB: func (mk sqlmock.Sqlmock) {
+				// query
+				query := "SELECT id, user_id, name, email, phone, address, version FROM profiles WHERE id = $1"
+
+				// expectations
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(query)).
+					ExpectQuery().WithArgs("id").
+					WillReturnError(errors.New("sql: internal error"))
+			},
+		},
+		// -> prepare error
+		{
+			name: "invalid case - prepare internal error",
+			input: input{id: "id"},
+			output: output{
+				pf: nil,
+				err: ErrStorageInternal, errMsg: "storage: internal storage error. sql: prepare error",
+			},
+			setUpDB: func (mk sqlmock.Sqlmock) {
+				// query
+				query := "SELECT id, user_id, name, email, phone, address, version FROM profiles WHERE id = $1"
+
+				// expectations
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(query)).
+					WillReturnError(errors.New("sql: prepare error"))
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			c.setUpDB(mk)
+
+			impl := NewImplProfilesStoragePostgres(db)
+
+			// act
+			pf, err := impl.GetProfileById(c.input.id)
+
+			// assert
+			assert.Equal(t, c.output.pf, pf)
+			assert.ErrorIs(t, err, c.output.err)
+			if c.output.err != nil {
+				assert.EqualError(t, err, c.output.errMsg)
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestImplProfilesStoragePostgres_ActiveProfile(t *testing.T) {
+	type input struct { pf *profiles.Profile }
+	type output struct { err error; errMsg string }
+	type testCase struct {
+		name string
+		input input
+		output output
+		// set-up
+		setUpDB func (mk sqlmock.Sqlmock)
+	}
+
+	cases := []testCase{
+		// valid cases
+		{
+			name: "valid case - success",
+			input: input{
+				pf: &profiles.Profile{
+					ID: optional.Some("id"),
+					UserID: optional.Some("user_id"),
+					Name: optional.Some("name"),
+					Email: optional.Some("johndoe@gmail.com"),
+					Phone: optional.Some("1234567890"),
+					Address: optional.Some("address"),
+				},
+			},
+			output: output{err: nil, errMsg: ""},
+			setUpDB: func (mk sqlmock.Sqlmock) {
+				// query
+				query := "INSERT INTO profiles (id, user_id, name, email, phone, address) VALUES ($1, $2, $3, $4, $5, $6)"
+
+				// expectations
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(query)).
+					ExpectExec().WithArgs(
+						sql.NullString{String: "id", Valid: true},
+						sql.NullString{String: "user_id", Valid: true},
+						sql.NullString{String: "name", Valid: true},
+						sql.NullString{String: "johndoe@gmail.com", Valid: true},
+						sql.NullString{String: "1234567890", Valid: true},
+						sql.NullString{String: "address", Valid: true},
+					).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+
+		// invalid cases
+		// -> prepare error
+		{
+			name: "invalid case - prepare internal error",
+			input: input{pf: &profiles.Profile{}},
+			output: output{
+				err: ErrStorageInternal, errMsg: "storage: internal storage error. sql: prepare error",
+			},
+			setUpDB: func (mk sqlmock.Sqlmock) {
+				// query
+				query := "INSERT INTO profiles (id, user_id, name, email, phone, address) VALUES ($1, $2, $3, $4, $5, $6)"
+
+				// expectations
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(query)).
+					WillReturnError(errors.New("sql: prepare error"))
+			},
+		},
+		// -> exec error. default error
+		{
+			name: "invalid case - exec internal error",
+			input: input{pf: &profiles.Profile{}},
+			output: output{
+				err: ErrStorageInternal, errMsg: "storage: internal storage error. sql: exec error",
+			},
+			setUpDB: func (mk sqlmock.Sqlmock) {
+				// query
+				query := "INSERT INTO profiles (id, user_id, name, email, phone, address) VALUES ($1, $2, $3, $4, $5, $6)"
+
+				// expectations
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(query)).
+					ExpectExec().
+					WillReturnError(errors.New("sql: exec error"))
+			},
+		},
+		// -> exec error. postgres error - unique violation
+		{
+			name: "invalid case - exec duplicate entry error",
+			input: input{pf: &profiles.Profile{}},
+			output: output{
+				err: ErrStorageNotUnique, errMsg: "storage: profile not unique. pq: duplicate key value violates unique constraint",
+			},
+			setUpDB: func (mk sqlmock.Sqlmock) {
+				// query
+				query := "INSERT INTO profiles (id, user_id, name, email, phone, address) VALUES ($1, $2, $3, $4, $5, $6)"
+
+				// expectations
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(query)).
+					ExpectExec().
+					WillReturnError(&pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint"})
+			},
+		},
+		// -> exec error. postgres error - other
+		{
+			name: "invalid case - exec postgres error",
+			input: input{pf: &profiles.Profile{}},
+			output: output{
+				err: ErrStorageInternal, errMsg: "storage: internal storage error. pq: other error",
+			},
+			setUpDB: func (mk sqlmock.Sqlmock) {
+				// query
+				query := "INSERT INTO profiles (id, user_id, name, email, phone, address) VALUES ($1, $2, $3, $4, $5, $6)"
+
+				// expectations
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(query)).
+					ExpectExec().
+					WillReturnError(&pq.Error{Code: "23502", Message: "other error"})
+			},
+		},
+		// -> result error.
+		{
+			name: "invalid case - result error",
+			input: input{pf: &profiles.Profile{}},
+			output: output{
+				err: ErrStorageInternal, errMsg: "storage: internal storage error. sql: result error",
+			},
+			setUpDB: func (mk sqlmock.Sqlmock) {
+				// query
+				query := "INSERT INTO profiles (id, user_id, name, email, phone, address) VALUES ($1, $2, $3, $4, $5, $6)"
+
+				// expectations
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(query)).
+					ExpectExec().
+					WillReturnResult(sqlmock.NewErrorResult(errors.New("sql: result error")))
+			},
+		},
+		// -> result error. rows affected != 1
+		{
+			name: "invalid case - result error. rows affected != 1",
+			input: input{pf: &profiles.Profile{}},
+			output: output{
+				err: ErrStorageInternal, errMsg: "storage: internal storage error. rows affected != 1",
+			},
+			setUpDB: func (mk sqlmock.Sqlmock) {
+				// query
+				query := "INSERT INTO profiles (id, user_id, name, email, phone, address) VALUES ($1, $2, $3, $4, $5, $6)"
+
+				// expectations
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(query)).
+					ExpectExec().
+					WillReturnResult(sqlmock.NewResult(1, 0))
+			},
+		},
+	}
+	
+	// run tests
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			c.setUpDB(mk)
+
+			impl := NewImplProfilesStoragePostgres(db)
+
+			// act
+			err = impl.ActivateProfile(c.input.pf)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if c.output.err != nil {
+				assert.EqualError(t, err, c.output.errMsg)
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestImplProfilesStoragePostgres_UpdateProfile(t *testing.T) {
+	type input struct { pf *profiles.Profile }
+	type output struct { version optional.Option[int]; err error; errMsg string }
+	type testCase struct {
+		name string
+		input input
+		output output
+		// set-up
+		setUpDB func (mk sqlmock.Sqlmock)
+	}
+
+	// queries
+	query := "UPDATE profiles SET name = $1, email = $2, phone = $3, address = $4, version = version + 1 WHERE id = $5 AND version = $6"
+	queryGet := "SELECT id, user_id, name, email, phone, address, version FROM profiles WHERE id = $1"
+	cols := []string{"id", "user_id", "name", "email", "phone", "address", "version"}
+
+	cases := []testCase{
+		// valid cases
+		{
+			name: "valid case - success",
+			input: input{
+				pf: &profiles.Profile{
+					ID: optional.Some("id"),
+					Name: optional.Some("name"),
+					Email: optional.Some("johndoe@gmail.com"),
+					Version: optional.Some(2),
+				},
+			},
+			output: output{version: optional.Some(3), err: nil, errMsg: ""},
+			setUpDB: func (mk sqlmock.Sqlmock) {
+				// expectations
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(query)).
+					ExpectExec().WithArgs(
+						sql.NullString{String: "name", Valid: true},
+						sql.NullString{String: "johndoe@gmail.com", Valid: true},
+						sql.NullString{},
+						sql.NullString{},
+						sql.NullString{String: "id", Valid: true},
+						sql.NullInt64{Int64: 2, Valid: true},
+					).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+
+		// invalid cases
+		// -> version required
+		{
+			name: "invalid case - version required",
+			input: input{pf: &profiles.Profile{ID: optional.Some("id")}},
+			output: output{
+				version: optional.None[int](),
+				err: ErrStorageInvalidProfile, errMsg: "storage: invalid profile. version is required",
+			},
+			setUpDB: func (mk sqlmock.Sqlmock) {},
+		},
+		// -> stale version
+		{
+			name: "invalid case - version mismatch",
+			input: input{pf: &profiles.Profile{ID: optional.Some("id"), Version: optional.Some(2)}},
+			output: output{
+				version: optional.Some(2),
+				err: ErrStorageVersionMismatch, errMsg: "storage: profile version mismatch. stored version 3",
+			},
+			setUpDB: func (mk sqlmock.Sqlmock) {
+				// expectations
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(query)).
+					ExpectExec().
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				rows := sqlmock.NewRows(cols)
+				rows.AddRow("id", "user_id", nil, nil, nil, nil, 3)
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(queryGet)).
+					ExpectQuery().WithArgs("id").
+					WillReturnRows(rows)
+			},
+		},
+		// -> profile not found
+		{
+			name: "invalid case - not found",
+			input: input{pf: &profiles.Profile{ID: optional.Some("id"), Version: optional.Some(2)}},
+			output: output{
+				version: optional.Some(2),
+				err: ErrStorageNotFound, errMsg: "storage: profile not found. sql: no rows in result set",
+			},
+			setUpDB: func (mk sqlmock.Sqlmock) {
+				// expectations
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(query)).
+					ExpectExec().
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(queryGet)).
+					ExpectQuery().WithArgs("id").
+					WillReturnError(sql.ErrNoRows)
+			},
+		},
+		// -> exec error
+		{
+			name: "invalid case - exec internal error",
+			input: input{pf: &profiles.Profile{ID: optional.Some("id"), Version: optional.Some(2)}},
+			output: output{
+				version: optional.Some(2),
+				err: ErrStorageInternal, errMsg: "storage: internal storage error. sql: exec error",
+			},
+			setUpDB: func (mk sqlmock.Sqlmock) {
+				// expectations
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(query)).
+					ExpectExec().
+					WillReturnError(errors.New("sql: exec error"))
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			c.setUpDB(mk)
+
+			impl := NewImplProfilesStoragePostgres(db)
+
+			// act
+			err = impl.UpdateProfile(c.input.pf)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if c.output.err != nil {
+				assert.EqualError(t, err, c.output.errMsg)
+			}
+			assert.Equal(t, c.output.version, c.input.pf.Version)
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package storage
+
+import (
+	"api/internal/profiles"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/LNMMusic/optional"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// SchemaSQLite creates the profiles table, if it does not exist
+// - a user has a single profile (user_id is unique)
+const SchemaSQLite = `CREATE TABLE IF NOT EXISTS profiles (
+	id TEXT PRIMARY KEY,
+	user_id TEXT NOT NULL UNIQUE,
+	name TEXT,
+	email TEXT,
+	phone TEXT,
+	address TEXT,
+	version INTEGER NOT NULL DEFAULT 1
+)`
+
+// NewImplProfilesStorageSQLite returns the SQLite storage of the profiles
+// - the schema is created on the database if it does not exist (see SchemaSQLite)
+func NewImplProfilesStorageSQLite(db *sql.DB) (s *ImplProfilesStorageSQLite, err error) {
+	_, err = db.Exec(SchemaSQLite)
+	if err != nil {
+		err = fmt.Errorf("%w. %s", ErrStorageInternal, err.Error())
+		return
+	}
+
+	s = &ImplProfilesStorageSQLite{
+		db: db,
+		ctx: context.Background(),
+	}
+	return
+}
+
+// ImplProfilesStorageSQLite is the implementation of the Storage interface for an embedded SQLite database
+// - the rows are scanned into the MySQL dto (same columns)
+// - it is not to be wrapped in ImplProfilesStorageMySQLTx: its statements run on the database, whose only connection the transaction holds (see sqlite.Open)
+type ImplProfilesStorageSQLite struct {
+	// db is the database connection
+	db *sql.DB
+	// ctx is the context the statements are run with (see WithContext)
+	ctx context.Context
+}
+
+// WithContext returns a copy of the storage that runs its statements with the given context.
+func (s *ImplProfilesStorageSQLite) WithContext(ctx context.Context) (st ProfilesStorage) {
+	cp := *s
+	cp.ctx = ctx
+	st = &cp
+	return
+}
+
+// GetProfileById returns a profile by its id
+func (s *ImplProfilesStorageSQLite) GetProfileById(id string) (pf *profiles.Profile, err error) {
+	// query
+	query := "SELECT id, user_id, name, email, phone, address, version FROM profiles WHERE id = ?"
+
+	// prepare statement
+	var stmt *sql.Stmt
+	stmt, err = s.db.PrepareContext(s.ctx, query)
+	if err != nil {
+		err = fmt.Errorf("%w. %s", ErrStorageInternal, err.Error())
+		return
+	}
+	defer stmt.Close()
+
+	// execute query and scan row
+	var pfSQLite ProfileMySQL
+	err = stmt.QueryRowContext(s.ctx, id).Scan(&pfSQLite.ID, &pfSQLite.UserID, &pfSQLite.Name, &pfSQLite.Email, &pfSQLite.Phone, &pfSQLite.Address, &pfSQLite.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			err = fmt.Errorf("%w. %s", ErrStorageNotFound, err.Error())
+		default:
+			err = fmt.Errorf("%w. %s", ErrStorageInternal, err.Error())
+		}
+		return
+	}
+
+	// serialize ProfileMySQL to profiles.Profile
+	pf = new(profiles.Profile)
+	if pfSQLite.ID.Valid {
+		pf.ID = optional.Some(pfSQLite.ID.String)
+	}
+	if pfSQLite.UserID.Valid {
+		pf.UserID = optional.Some(pfSQLite.UserID.String)
+	}
+	if pfSQLite.Name.Valid {
+		pf.Name = optional.Some(pfSQLite.Name.String)
+	}
+	if pfSQLite.Email.Valid {
+		pf.Email = optional.Some(pfSQLite.Email.String)
+	}
+	if pfSQLite.Phone.Valid {
+		pf.Phone = optional.Some(pfSQLite.Phone.String)
+	}
+	if pfSQLite.Address.Valid {
+		pf.Address = optional.Some(pfSQLite.Address.String)
+	}
+	if pfSQLite.Version.Valid {
+		pf.Version = optional.Some(int(pfSQLite.Version.Int64))
+	}
+
+	return
+}
+
+// ActivateProfile
+func (s *ImplProfilesStorageSQLite) ActivateProfile(pf *profiles.Profile) (err error) {
+	// deserialize profiles.Profile to ProfileMySQL
+	var pfSQLite ProfileMySQL
+	if pf.ID.IsSome() {
+		pfSQLite.ID.String, _ = pf.ID.Unwrap()
+		pfSQLite.ID.Valid = true
+	}
+	if pf.UserID.IsSome() {
+		pfSQLite.UserID.String, _ = pf.UserID.Unwrap()
+		pfSQLite.UserID.Valid = true
+	}
+	if pf.Name.IsSome() {
+		pfSQLite.Name.String, _ = pf.Name.Unwrap()
+		pfSQLite.Name.Valid = true
+	}
+	if pf.Email.IsSome() {
+		pfSQLite.Email.String, _ = pf.Email.Unwrap()
+		pfSQLite.Email.Valid = true
+	}
+	if pf.Phone.IsSome() {
+		pfSQLite.Phone.String, _ = pf.Phone.Unwrap()
+		pfSQLite.Phone.Valid = true
+	}
+	if pf.Address.IsSome() {
+		pfSQLite.Address.String, _ = pf.Address.Unwrap()
+		pfSQLite.Address.Valid = true
+	}
+
+	// query
+	query := "INSERT INTO profiles (id, user_id, name, email, phone, address) VALUES (?, ?, ?, ?, ?, ?)"
+
+	// prepare statement
+	var stmt *sql.Stmt
+	stmt, err = s.db.PrepareContext(s.ctx, query)
+	if err != nil {
+		err = fmt.Errorf("%w. %s", ErrStorageInternal, err.Error())
+		return
+	}
+	defer stmt.Close()
+
+	// execute query
+	var result sql.Result
+	result, err = stmt.ExecContext(s.ctx, pfSQLite.ID, pfSQLite.UserID, pfSQLite.Name, pfSQLite.Email, pfSQLite.Phone, pfSQLite.Address)
+	if err != nil {
+		// -> the id or the user already has a profile
+		var errSQLite *sqlite.Error
+		if errors.As(err, &errSQLite) {
+			switch errSQLite.Code() {
+			case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+				err = fmt.Errorf("%w. %s", ErrStorageNotUnique, err.Error())
+			default:
+				err = fmt.Errorf("%w. %s", ErrStorageInternal, err.Error())
+			}
+			return
+		}
+
+		err = fmt.Errorf("%w. %s", ErrStorageInternal, err.Error())
+		return
+	}
+
+	// check affected rows
+	var affectedRows int64
+	affectedRows, err = result.RowsAffected()
+	if err != nil {
+		err = fmt.Errorf("%w. %s", ErrStorageInternal, err.Error())
+		return
+	}
+
+	if affectedRows != 1 {
+		err = fmt.Errorf("%w. %s", ErrStorageInternal, "rows affected != 1")
+		return
+	}
+
+	// set default values
+	// -> the version column defaults to 1
+	pf.Version = optional.Some(1)
+
+	return
+}
+
+// UpdateProfile
+func (s *ImplProfilesStorageSQLite) UpdateProfile(pf *profiles.Profile) (err error) {
+	// deserialize profiles.Profile to ProfileMySQL
+	var pfSQLite ProfileMySQL
+	if pf.ID.IsSome() {
+		pfSQLite.ID.String, _ = pf.ID.Unwrap()
+		pfSQLite.ID.Valid = true
+	}
+	if pf.Name.IsSome() {
+		pfSQLite.Name.String, _ = pf.Name.Unwrap()
+		pfSQLite.Name.Valid = true
+	}
+	if pf.Email.IsSome() {
+		pfSQLite.Email.String, _ = pf.Email.Unwrap()
+		pfSQLite.Email.Valid = true
+	}
+	if pf.Phone.IsSome() {
+		pfSQLite.Phone.String, _ = pf.Phone.Unwrap()
+		pfSQLite.Phone.Valid = true
+	}
+	if pf.Address.IsSome() {
+		pfSQLite.Address.String, _ = pf.Address.Unwrap()
+		pfSQLite.Address.Valid = true
+	}
+	if pf.Version.IsSome() {
+		version, _ := pf.Version.Unwrap()
+		pfSQLite.Version = sql.NullInt64{Int64: int64(version), Valid: true}
+	}
+	// -> the update is conditional on the version
+	if !pfSQLite.Version.Valid {
+		err = fmt.Errorf("%w. %s", ErrStorageInvalidProfile, "version is required")
+		return
+	}
+
+	// query
+	query := "UPDATE profiles SET name = ?, email = ?, phone = ?, address = ?, version = version + 1 WHERE id = ? AND version = ?"
+
+	// prepare statement
+	var stmt *sql.Stmt
+	stmt, err = s.db.PrepareContext(s.ctx, query)
+	if err != nil {
+		err = fmt.Errorf("%w. %s", ErrStorageInternal, err.Error())
+		return
+	}
+	defer stmt.Close()
+
+	// execute query
+	var result sql.Result
+	result, err = stmt.ExecContext(s.ctx, pfSQLite.Name, pfSQLite.Email, pfSQLite.Phone, pfSQLite.Address, pfSQLite.ID, pfSQLite.Version)
+	if err != nil {
+		err = fmt.Errorf("%w. %s", ErrStorageInternal, err.Error())
+		return
+	}
+
+	// check affected rows
+	var affectedRows int64
+	affectedRows, err = result.RowsAffected()
+	if err != nil {
+		err = fmt.Errorf("%w. %s", ErrStorageInternal, err.Error())
+		return
+	}
+
+	// -> no rows affected means the profile does not exist or was updated meanwhile
+	if affectedRows != 1 {
+		var stored *profiles.Profile
+		stored, err = s.GetProfileById(pfSQLite.ID.String)
+		if err != nil {
+			return
+		}
+		version, _ := stored.Version.Unwrap()
+		err = fmt.Errorf("%w. %s", ErrStorageVersionMismatch, fmt.Sprintf("stored version %d", version))
+		return
+	}
+
+	// set values
+	pf.Version = optional.Some(int(pfSQLite.Version.Int64) + 1)
+
+	return
+}
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"api/internal/profiles"
+	"api/pkg/sqlite"
+	"path/filepath"
+	"testing"
+
+	"github.com/LNMMusic/optional"
+	"github.com/stretchr/testify/assert"
+)
+
+// newImplProfilesStorageSQLite returns a SQLite storage on a new database file
+func newImplProfilesStorageSQLite(t *testing.T) *ImplProfilesStorageSQLite {
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "profiles.db"))
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	impl, err := NewImplProfilesStorageSQLite(db)
+	assert.NoError(t, err)
+	return impl
+}
+
+// Tests for ImplProfilesStorageSQLite
+func TestImplProfilesStorageSQLite_ActivateProfile(t *testing.T) {
+	type input struct { pf *profiles.Profile }
+	type output struct { err error; errMsg string }
+	type test struct {
+		name string
+		input input
+		output output
+		// set-up
+		setUpDB func (impl *ImplProfilesStorageSQLite)
+	}
+
+	cases := []test{
+		// valid cases
+		{
+			name: "valid case - success",
+			input: input{pf: &profiles.Profile{ID: optional.Some("id"), UserID: optional.Some("user_id"), Name: optional.Some("name")}},
+			output: output{err: nil, errMsg: ""},
+			setUpDB: func (impl *ImplProfilesStorageSQLite) {},
+		},
+
+		// invalid cases
+		// -> the id is taken
+		{
+			name: "invalid case - id not unique",
+			input: input{pf: &profiles.Profile{ID: optional.Some("id"), UserID: optional.Some("user_id_2")}},
+			output: output{err: ErrStorageNotUnique, errMsg: "storage: profile not unique. constraint failed: UNIQUE constraint failed: profiles.id (1555)"},
+			setUpDB: func (impl *ImplProfilesStorageSQLite) {
+				impl.ActivateProfile(&profiles.Profile{ID: optional.Some("id"), UserID: optional.Some("user_id")})
+			},
+		},
+		// -> the user already has a profile
+		{
+			name: "invalid case - user not unique",
+			input: input{pf: &profiles.Profile{ID: optional.Some("id_2"), UserID: optional.Some("user_id")}},
+			output: output{err: ErrStorageNotUnique, errMsg: "storage: profile not unique. constraint failed: UNIQUE constraint failed: profiles.user_id (2067)"},
+			setUpDB: func (impl *ImplProfilesStorageSQLite) {
+				impl.ActivateProfile(&profiles.Profile{ID: optional.Some("id"), UserID: optional.Some("user_id")})
+			},
+		},
+		// -> the user is required
+		{
+			name: "invalid case - user required",
+			input: input{pf: &profiles.Profile{ID: optional.Some("id")}},
+			output: output{err: ErrStorageInternal, errMsg: "storage: internal storage error. constraint failed: NOT NULL constraint failed: profiles.user_id (1299)"},
+			setUpDB: func (impl *ImplProfilesStorageSQLite) {},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			impl := newImplProfilesStorageSQLite(t)
+			c.setUpDB(impl)
+
+			// act
+			err := impl.ActivateProfile(c.input.pf)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if c.output.err != nil {
+				assert.EqualError(t, err, c.output.errMsg)
+				return
+			}
+			assert.Equal(t, optional.Some(1), c.input.pf.Version)
+		})
+	}
+}
+
+func TestImplProfilesStorageSQLite_GetProfileById(t *testing.T) {
+	// arrange
+	impl := newImplProfilesStorageSQLite(t)
+	pf := &profiles.Profile{
+		ID: optional.Some("id"),
+		UserID: optional.Some("user_id"),
+		Name: optional.Some("name"),
+		Email: optional.Some("johndoe@gmail.com"),
+		Phone: optional.Some("1234567890"),
+		Address: optional.Some("address"),
+	}
+	assert.NoError(t, impl.ActivateProfile(pf))
+
+	// act
+	stored, err := impl.GetProfileById("id")
+	_, errNotFound := impl.GetProfileById("unknown")
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, pf, stored)
+	assert.ErrorIs(t, errNotFound, ErrStorageNotFound)
+	assert.EqualError(t, errNotFound, "storage: profile not found. sql: no rows in result set")
+}
+
+func TestImplProfilesStorageSQLite_UpdateProfile(t *testing.T) {
+	// arrange
+	impl := newImplProfilesStorageSQLite(t)
+	assert.NoError(t, impl.ActivateProfile(&profiles.Profile{ID: optional.Some("id"), UserID: optional.Some("user_id"), Name: optional.Some("name")}))
+
+	// act
+	pf := &profiles.Profile{ID: optional.Some("id"), Name: optional.Some("new name"), Version: optional.Some(1)}
+	err := impl.UpdateProfile(pf)
+	errMismatch := impl.UpdateProfile(&profiles.Profile{ID: optional.Some("id"), Name: optional.Some("stale"), Version: optional.Some(1)})
+	errNotFound := impl.UpdateProfile(&profiles.Profile{ID: optional.Some("unknown"), Version: optional.Some(1)})
+	errInvalid := impl.UpdateProfile(&profiles.Profile{ID: optional.Some("id")})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, optional.Some(2), pf.Version)
+	assert.ErrorIs(t, errMismatch, ErrStorageVersionMismatch)
+	assert.EqualError(t, errMismatch, "storage: profile version mismatch. stored version 2")
+	assert.ErrorIs(t, errNotFound, ErrStorageNotFound)
+	assert.ErrorIs(t, errInvalid, ErrStorageInvalidProfile)
+	stored, err := impl.GetProfileById("id")
+	assert.NoError(t, err)
+	assert.Equal(t, optional.Some("new name"), stored.Name)
+}
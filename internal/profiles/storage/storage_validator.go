@@ -2,6 +2,7 @@ package storage
 
 import (
 	"api/internal/profiles"
+	"context"
 	"api/internal/profiles/validator"
 	"fmt"
 )
@@ -23,6 +24,14 @@ type ImplProfilesStorageValidator struct {
 	vl validator.ProfilesValidator
 }
 
+// WithContext returns a copy of the storage that runs the methods of the wrapped storage with the given context
+func (impl *ImplProfilesStorageValidator) WithContext(ctx context.Context) (st ProfilesStorage) {
+	cp := *impl
+	cp.st = impl.st.WithContext(ctx)
+	st = &cp
+	return
+}
+
 // GetProfileById returns a profile by its userId
 func (impl *ImplProfilesStorageValidator) GetProfileById(id string) (pf *profiles.Profile, err error) {
 	pf, err = impl.st.GetProfileById(id)
@@ -42,3 +51,17 @@ func (impl *ImplProfilesStorageValidator) ActivateProfile(pf *profiles.Profile)
 	err = impl.st.ActivateProfile(pf)
 	return 
 }
+
+// UpdateProfile
+func (impl *ImplProfilesStorageValidator) UpdateProfile(pf *profiles.Profile) (err error) {
+	// validate profile
+	err = impl.vl.Validate(pf)
+	if err != nil {
+		err = fmt.Errorf("%w. %s", ErrStorageInvalidProfile, err.Error())
+		return
+	}
+
+	// update profile
+	err = impl.st.UpdateProfile(pf)
+	return
+}
@@ -150,4 +150,82 @@ func TestImplProfilesStorageValidator_ActivateProfile(t *testing.T) {
 			vl.AssertExpectations(t)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestImplProfilesStorageValidator_UpdateProfile(t *testing.T) {
+	type input struct { pf *profiles.Profile }
+	type output struct { err error; errMsg string }
+	type testCase struct {
+		name string
+		input input
+		output output
+		// set-up
+		setUpStorage func(mk *ImplProfilesStorageMock)
+		setUpValidator func(mk *validator.ImplProfilesValidatorMock)
+	}
+
+	cases := []testCase{
+		// valid cases
+		{
+			name: "valid case",
+			input: input{ pf: &profiles.Profile{} },
+			output: output{ err: nil, errMsg: "" },
+			setUpStorage: func(mk *ImplProfilesStorageMock) {
+				mk.On("UpdateProfile", &profiles.Profile{}).Return(nil)
+			},
+			setUpValidator: func(mk *validator.ImplProfilesValidatorMock) {
+				mk.On("Validate", &profiles.Profile{}).Return(nil)
+			},
+		},
+
+		// invalid cases
+		// -> validator
+		{
+			name: "validator error",
+			input: input{ pf: &profiles.Profile{} },
+			output: output{ err: ErrStorageInvalidProfile, errMsg: "storage: invalid profile. validator: invalid profile" },
+			setUpStorage: func(mk *ImplProfilesStorageMock) {},
+			setUpValidator: func(mk *validator.ImplProfilesValidatorMock) {
+				mk.On("Validate", &profiles.Profile{}).Return(validator.ErrValidatorInvalidProfile)
+			},
+		},
+		// -> storage
+		{
+			name: "storage error",
+			input: input{ pf: &profiles.Profile{} },
+			output: output{ err: ErrStorageVersionMismatch, errMsg: "storage: profile version mismatch" },
+			setUpStorage: func(mk *ImplProfilesStorageMock) {
+				mk.On("UpdateProfile", &profiles.Profile{}).Return(ErrStorageVersionMismatch)
+			},
+			setUpValidator: func(mk *validator.ImplProfilesValidatorMock) {
+				mk.On("Validate", &profiles.Profile{}).Return(nil)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			st := NewImplProfilesStorageMock()
+			c.setUpStorage(st)
+
+			vl := validator.NewImplProfilesValidatorMock()
+			c.setUpValidator(vl)
+
+			impl := NewImplProfilesStorageValidator(st, vl)
+
+			// act
+			err := impl.UpdateProfile(c.input.pf)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if c.output.err != nil {
+				assert.EqualError(t, err, c.output.errMsg)
+			}
+			// -> expectations
+			st.AssertExpectations(t)
+			vl.AssertExpectations(t)
+		})
+	}
+}
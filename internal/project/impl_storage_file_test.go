@@ -0,0 +1,79 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/LNMMusic/optional"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests
+func TestStorageFile_Reload(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	st, err := NewStorageFile(dir, NewValidatorLocal(nil))
+	assert.NoError(t, err)
+	inbox := &Project{OwnerID: optional.Some("p1"), Name: optional.Some("inbox")}
+	assert.NoError(t, st.Save(inbox))
+	work := &Project{OwnerID: optional.Some("p1"), Name: optional.Some("work")}
+	assert.NoError(t, st.Save(work))
+	home := &Project{OwnerID: optional.Some("p1"), Name: optional.Some("home")}
+	assert.NoError(t, st.Save(home))
+	inboxId, _ := inbox.ID.Unwrap()
+	workId, _ := work.ID.Unwrap()
+	homeId, _ := home.ID.Unwrap()
+	assert.NoError(t, st.Update("p1", &Project{ID: optional.Some(workId), Name: optional.Some("office")}))
+	assert.NoError(t, st.Delete("p1", homeId))
+
+	// act
+	reloaded, err := NewStorageFile(dir, NewValidatorLocal(nil))
+
+	// assert
+	assert.NoError(t, err)
+	ps, errList := reloaded.List("p1")
+	assert.NoError(t, errList)
+	assert.Len(t, ps, 2)
+	assert.Equal(t, optional.Some(inboxId), ps[0].ID)
+	assert.Equal(t, optional.Some("inbox"), ps[0].Name)
+	assert.Equal(t, optional.Some(workId), ps[1].ID)
+	assert.Equal(t, optional.Some("office"), ps[1].Name)
+	assert.Equal(t, optional.Some("p1"), ps[1].OwnerID)
+	assert.True(t, ps[1].CreatedAt.IsSome())
+}
+
+func TestStorageFile_FailedWrite(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	st, err := NewStorageFile(dir, NewValidatorLocal(nil))
+	assert.NoError(t, err)
+	assert.NoError(t, st.Save(&Project{OwnerID: optional.Some("p1"), Name: optional.Some("inbox")}))
+	// -> the file can not be written aside
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, FileProjects+".tmp"), 0o755))
+
+	// act
+	err = st.Save(&Project{OwnerID: optional.Some("p1"), Name: optional.Some("work")})
+
+	// assert
+	// -> the change that could not be written is undone
+	assert.ErrorIs(t, err, ErrStorageInternal)
+	ps, errList := st.List("p1")
+	assert.NoError(t, errList)
+	assert.Len(t, ps, 1)
+	assert.Equal(t, optional.Some("inbox"), ps[0].Name)
+}
+
+func TestStorageFile_Corrupted(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, FileProjects), []byte("[{"), 0o644))
+
+	// act
+	st, err := NewStorageFile(dir, NewValidatorLocal(nil))
+
+	// assert
+	assert.Nil(t, st)
+	assert.ErrorIs(t, err, ErrStorageInternal)
+}
@@ -0,0 +1,157 @@
+package project
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/LNMMusic/optional"
+
+	"github.com/google/uuid"
+)
+
+// constructor
+func NewStorageLocal(db []*Project, vl Validator) *StorageLocal {
+	return &StorageLocal{db: db, vl: vl, now: time.Now, newId: newId}
+}
+
+// StorageLocal is the local implementation of the project storage.
+// - it is safe for concurrent use: it keeps copies of the projects it saves and returns copies of them
+type StorageLocal struct {
+	// mu guards db
+	mu sync.RWMutex
+	db []*Project
+	vl Validator
+	// now returns the current time
+	now func() time.Time
+	// newId returns the id of a new project
+	newId func() string
+}
+
+// newId returns a random id.
+func newId() string {
+	return uuid.New().String()
+}
+
+// clone returns a copy of the project.
+func clone(p *Project) *Project {
+	c := *p
+	return &c
+}
+
+// index returns the position of the project with the given id, owned by the given profile.
+func (s *StorageLocal) index(profileId string, id string) (i int, err error) {
+	for i = range s.db {
+		pId, _ := s.db[i].ID.Unwrap()
+		pOwnerId, _ := s.db[i].OwnerID.Unwrap()
+		if pId == id && pOwnerId == profileId {
+			return
+		}
+	}
+
+	err = fmt.Errorf("%w: %v", ErrStorageNotFound, id)
+	return
+}
+
+func (s *StorageLocal) Get(profileId string, id string) (p *Project, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var i int
+	i, err = s.index(profileId, id)
+	if err != nil {
+		return
+	}
+
+	p = clone(s.db[i])
+	return
+}
+
+func (s *StorageLocal) List(profileId string) (ps []*Project, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// projects of the profile, sorted by name (and id)
+	ps = make([]*Project, 0)
+	for _, p := range s.db {
+		if pOwnerId, _ := p.OwnerID.Unwrap(); pOwnerId == profileId {
+			ps = append(ps, clone(p))
+		}
+	}
+	sort.SliceStable(ps, func(i, j int) bool {
+		ni, _ := ps[i].Name.Unwrap()
+		nj, _ := ps[j].Name.Unwrap()
+		if ni != nj {
+			return ni < nj
+		}
+		idi, _ := ps[i].ID.Unwrap()
+		idj, _ := ps[j].ID.Unwrap()
+		return idi < idj
+	})
+
+	return
+}
+
+func (s *StorageLocal) Save(p *Project) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// validate project
+	err = s.vl.Validate(p)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrStorageInvalid, err)
+		return
+	}
+
+	// generate id and timestamps
+	p.ID = optional.Some(s.newId())
+	now := s.now()
+	p.CreatedAt = optional.Some(now)
+	p.UpdatedAt = optional.Some(now)
+
+	// save project
+	s.db = append(s.db, clone(p))
+	return
+}
+
+func (s *StorageLocal) Update(profileId string, p *Project) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// update project
+	id, _ := p.ID.Unwrap()
+	var i int
+	i, err = s.index(profileId, id)
+	if err != nil {
+		return
+	}
+	stored := s.db[i]
+	p.OwnerID = stored.OwnerID
+
+	// validate project
+	err = s.vl.Validate(p)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrStorageInvalid, err)
+		return
+	}
+
+	p.CreatedAt = stored.CreatedAt
+	p.UpdatedAt = optional.Some(s.now())
+	s.db[i] = clone(p)
+	return
+}
+
+func (s *StorageLocal) Delete(profileId string, id string) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var i int
+	i, err = s.index(profileId, id)
+	if err != nil {
+		return
+	}
+
+	s.db = append(s.db[:i], s.db[i+1:]...)
+	return
+}
@@ -0,0 +1,335 @@
+package project
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/LNMMusic/optional"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Tests
+func TestStorageLocal_Get(t *testing.T) {
+	type input struct {profileId string; id string}
+	type output struct {p *Project; err error; errMsg string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+	}
+
+	db := []*Project{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Name: optional.Some("inbox")}}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "get a project",
+			input: input{profileId: "p1", id: "1"},
+			output: output{p: &Project{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Name: optional.Some("inbox")}},
+		},
+
+		// failure cases
+		{
+			title: "get a project of another profile",
+			input: input{profileId: "p2", id: "1"},
+			output: output{err: ErrStorageNotFound, errMsg: "storage project not found: 1"},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := NewStorageLocal(db, NewValidatorMock())
+
+			// act
+			p, err := st.Get(c.input.profileId, c.input.id)
+
+			// assert
+			assert.Equal(t, c.output.p, p)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestStorageLocal_List(t *testing.T) {
+	// arrange
+	db := []*Project{
+		{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Name: optional.Some("work")},
+		{ID: optional.Some("2"), OwnerID: optional.Some("p2"), Name: optional.Some("home")},
+		{ID: optional.Some("4"), OwnerID: optional.Some("p1"), Name: optional.Some("inbox")},
+		{ID: optional.Some("3"), OwnerID: optional.Some("p1"), Name: optional.Some("inbox")},
+	}
+	st := NewStorageLocal(db, NewValidatorMock())
+
+	// act
+	ps, err := st.List("p1")
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []*Project{
+		{ID: optional.Some("3"), OwnerID: optional.Some("p1"), Name: optional.Some("inbox")},
+		{ID: optional.Some("4"), OwnerID: optional.Some("p1"), Name: optional.Some("inbox")},
+		{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Name: optional.Some("work")},
+	}, ps)
+}
+
+func TestStorageLocal_Save(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type input struct {p *Project}
+	type output struct {p *Project; err error; errMsg string}
+	type testCase struct {
+		title		 string
+		input		 input
+		output		 output
+		setValidator func(vl *ValidatorMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "save a project",
+			input: input{p: &Project{OwnerID: optional.Some("p1"), Name: optional.Some("inbox")}},
+			output: output{p: &Project{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Name: optional.Some("inbox"), CreatedAt: optional.Some(now), UpdatedAt: optional.Some(now)}},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+
+		// failure cases
+		{
+			title: "save an invalid project",
+			input: input{p: &Project{OwnerID: optional.Some("p1")}},
+			output: output{err: ErrStorageInvalid, errMsg: "storage invalid project: validator field required: name"},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Validate", mock.Anything).Return(fmt.Errorf("%w: name", ErrValidatorFieldRequired))
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			vl := NewValidatorMock()
+			c.setValidator(vl)
+
+			st := NewStorageLocal([]*Project{}, vl)
+			st.now = func() time.Time { return now }
+			st.newId = func() string { return "1" }
+
+			// act
+			err := st.Save(c.input.p)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+				assert.Empty(t, st.db)
+				return
+			}
+			assert.Equal(t, c.output.p, c.input.p)
+			assert.Equal(t, []*Project{c.output.p}, st.db)
+			vl.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStorageLocal_Update(t *testing.T) {
+	created := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := created.Add(time.Hour)
+
+	type input struct {profileId string; p *Project}
+	type output struct {db []*Project; err error; errMsg string}
+	type testCase struct {
+		title		 string
+		input		 input
+		output		 output
+		setValidator func(vl *ValidatorMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "rename a project",
+			input: input{profileId: "p1", p: &Project{ID: optional.Some("1"), Name: optional.Some("work"), Description: optional.Some("description")}},
+			output: output{db: []*Project{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Name: optional.Some("work"), Description: optional.Some("description"), CreatedAt: optional.Some(created), UpdatedAt: optional.Some(now)}}},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+
+		// failure cases
+		{
+			title: "update a project of another profile",
+			input: input{profileId: "p2", p: &Project{ID: optional.Some("1"), Name: optional.Some("work")}},
+			output: output{
+				db: []*Project{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Name: optional.Some("inbox"), CreatedAt: optional.Some(created), UpdatedAt: optional.Some(created)}},
+				err: ErrStorageNotFound,
+				errMsg: "storage project not found: 1",
+			},
+			setValidator: func(vl *ValidatorMock) {},
+		},
+		{
+			title: "update an invalid project",
+			input: input{profileId: "p1", p: &Project{ID: optional.Some("1")}},
+			output: output{
+				db: []*Project{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Name: optional.Some("inbox"), CreatedAt: optional.Some(created), UpdatedAt: optional.Some(created)}},
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid project: validator field required: name",
+			},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Validate", mock.Anything).Return(fmt.Errorf("%w: name", ErrValidatorFieldRequired))
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db := []*Project{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Name: optional.Some("inbox"), CreatedAt: optional.Some(created), UpdatedAt: optional.Some(created)}}
+
+			vl := NewValidatorMock()
+			c.setValidator(vl)
+
+			st := NewStorageLocal(db, vl)
+			st.now = func() time.Time { return now }
+
+			// act
+			err := st.Update(c.input.profileId, c.input.p)
+
+			// assert
+			assert.Equal(t, c.output.db, st.db)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			vl.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStorageLocal_Delete(t *testing.T) {
+	type input struct {profileId string; id string}
+	type output struct {db []*Project; err error; errMsg string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "delete a project",
+			input: input{profileId: "p1", id: "1"},
+			output: output{db: []*Project{{ID: optional.Some("2"), OwnerID: optional.Some("p1")}}},
+		},
+
+		// failure cases
+		{
+			title: "delete a project of another profile",
+			input: input{profileId: "p2", id: "1"},
+			output: output{
+				db: []*Project{{ID: optional.Some("1"), OwnerID: optional.Some("p1")}, {ID: optional.Some("2"), OwnerID: optional.Some("p1")}},
+				err: ErrStorageNotFound,
+				errMsg: "storage project not found: 1",
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db := []*Project{{ID: optional.Some("1"), OwnerID: optional.Some("p1")}, {ID: optional.Some("2"), OwnerID: optional.Some("p1")}}
+			st := NewStorageLocal(db, NewValidatorMock())
+
+			// act
+			err := st.Delete(c.input.profileId, c.input.id)
+
+			// assert
+			assert.Equal(t, c.output.db, st.db)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestStorageLocal_Clone(t *testing.T) {
+	// arrange
+	st := NewStorageLocal([]*Project{}, NewValidatorLocal(nil))
+	p := &Project{OwnerID: optional.Some("p1"), Name: optional.Some("inbox")}
+	assert.NoError(t, st.Save(p))
+	id, _ := p.ID.Unwrap()
+
+	// act
+	p.Name = optional.Some("saved")
+	got, errGet := st.Get("p1", id)
+	got.Name = optional.Some("got")
+	ps, errList := st.List("p1")
+	ps[0].Name = optional.Some("listed")
+	again, errAgain := st.Get("p1", id)
+
+	// assert
+	// -> the stored project is not changed through the projects given or returned
+	assert.NoError(t, errGet)
+	assert.NoError(t, errList)
+	assert.NoError(t, errAgain)
+	assert.Equal(t, optional.Some("inbox"), again.Name)
+}
+
+func TestStorageLocal_Concurrent(t *testing.T) {
+	// run with the race detector (go test -race) to catch unguarded accesses
+	const n = 50
+
+	// arrange
+	db := []*Project{{ID: optional.Some("0"), OwnerID: optional.Some("p1"), Name: optional.Some("inbox")}}
+	st := NewStorageLocal(db, NewValidatorLocal(nil))
+
+	// act
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(3)
+		// writers: save a project
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = st.Save(&Project{OwnerID: optional.Some("p1"), Name: optional.Some(fmt.Sprintf("project %d", i))})
+		}(i)
+		// writers: rename the same project
+		go func(i int) {
+			defer wg.Done()
+			_ = st.Update("p1", &Project{ID: optional.Some("0"), Name: optional.Some(fmt.Sprintf("inbox %d", i))})
+		}(i)
+		// readers: list the projects and get the same project
+		go func() {
+			defer wg.Done()
+			ps, _ := st.List("p1")
+			for _, p := range ps {
+				_, _ = p.Name.Unwrap()
+			}
+			_, _ = st.Get("p1", "0")
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	for i := 0; i < n; i++ {
+		assert.NoError(t, errs[i])
+	}
+	ps, err := st.List("p1")
+	assert.NoError(t, err)
+	assert.Len(t, ps, n+1)
+}
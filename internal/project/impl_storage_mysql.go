@@ -0,0 +1,342 @@
+package project
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/LNMMusic/optional"
+	"github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
+)
+
+// constructor
+func NewStorageMySQL(db *sql.DB, vl Validator) *StorageMySQL {
+	return &StorageMySQL{db: db, vl: vl, foreignKey: foreignKeyMySQL}
+}
+
+// StorageMySQL is an implementation with MySQL of the Storage interface.
+// - projects table (id, owner_id, name, description, created_at, updated_at), with owner_id indexed
+// - tasks.project_id references projects (id) on delete restrict: a project with tasks (in the trash too) can not be removed
+// - times are scanned as time (parseTime=true on the dsn) and stored in UTC
+const (
+	QueryGetProject = `SELECT id, owner_id, name, description, created_at, updated_at FROM projects WHERE id = ? AND owner_id = ?`
+	QueryListProjects = `SELECT id, owner_id, name, description, created_at, updated_at FROM projects WHERE owner_id = ? ORDER BY name, id`
+	QuerySaveProject = `INSERT INTO projects (id, owner_id, name, description, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`
+	// -> rows affected must count the matched rows (clientFoundRows=true on the dsn)
+	QueryUpdateProject = `UPDATE projects SET name = ?, description = ?, updated_at = ? WHERE id = ? AND owner_id = ?`
+	QueryDeleteProject = `DELETE FROM projects WHERE id = ? AND owner_id = ?`
+)
+
+// ProjectMySQL is the MySQL representation of a project. (internal Data Transfer Object)
+type ProjectMySQL struct {
+	ID 			sql.NullString
+	OwnerID 	sql.NullString
+	Name 		sql.NullString
+	Description sql.NullString
+	CreatedAt 	sql.NullTime
+	UpdatedAt 	sql.NullTime
+}
+
+// fields returns the destination of the columns selected by the queries.
+func (p *ProjectMySQL) fields() []any {
+	return []any{&p.ID, &p.OwnerID, &p.Name, &p.Description, &p.CreatedAt, &p.UpdatedAt}
+}
+
+// serialize returns the project represented by the dto.
+func (p *ProjectMySQL) serialize() (pj *Project) {
+	pj = &Project{}
+	if p.ID.Valid {
+		pj.ID = optional.Some(p.ID.String)
+	}
+	if p.OwnerID.Valid {
+		pj.OwnerID = optional.Some(p.OwnerID.String)
+	}
+	if p.Name.Valid {
+		pj.Name = optional.Some(p.Name.String)
+	}
+	if p.Description.Valid {
+		pj.Description = optional.Some(p.Description.String)
+	}
+	if p.CreatedAt.Valid {
+		pj.CreatedAt = optional.Some(p.CreatedAt.Time)
+	}
+	if p.UpdatedAt.Valid {
+		pj.UpdatedAt = optional.Some(p.UpdatedAt.Time)
+	}
+	return
+}
+
+// deserialize returns the dto of the given project.
+func deserialize(pj *Project) (p ProjectMySQL) {
+	if id, err := pj.ID.Unwrap(); err == nil {
+		p.ID = sql.NullString{String: id, Valid: true}
+	}
+	if ownerId, err := pj.OwnerID.Unwrap(); err == nil {
+		p.OwnerID = sql.NullString{String: ownerId, Valid: true}
+	}
+	if name, err := pj.Name.Unwrap(); err == nil {
+		p.Name = sql.NullString{String: name, Valid: true}
+	}
+	if description, err := pj.Description.Unwrap(); err == nil {
+		p.Description = sql.NullString{String: description, Valid: true}
+	}
+	if createdAt, err := pj.CreatedAt.Unwrap(); err == nil {
+		p.CreatedAt = sql.NullTime{Time: createdAt, Valid: true}
+	}
+	if updatedAt, err := pj.UpdatedAt.Unwrap(); err == nil {
+		p.UpdatedAt = sql.NullTime{Time: updatedAt, Valid: true}
+	}
+	return
+}
+
+// StorageMySQL is the MySQL implementation of the project storage.
+type StorageMySQL struct {
+	// db is the database connection.
+	db *sql.DB
+	// vl is the validator of the projects.
+	vl Validator
+	// bind rewrites the ? placeholders of the statements (nil keeps them, see NewStoragePostgres).
+	bind func(query string) string
+	// foreignKey reports whether the error of a statement is the violation of a foreign key, in the database.
+	foreignKey func(err error) bool
+}
+
+// statement returns the given statement with the placeholders of the database.
+func (s *StorageMySQL) statement(query string) string {
+	if s.bind == nil {
+		return query
+	}
+	return s.bind(query)
+}
+
+// Get returns the project with the given id.
+func (s *StorageMySQL) Get(profileId string, id string) (p *Project, err error) {
+	var projectMySQL ProjectMySQL
+	err = queryRow(s.db, s.statement(QueryGetProject), []any{id, profileId}, projectMySQL.fields()...)
+	if err != nil {
+		return
+	}
+
+	// serialize
+	p = projectMySQL.serialize()
+	return
+}
+
+// List returns the projects of the profile, sorted by name.
+func (s *StorageMySQL) List(profileId string) (ps []*Project, err error) {
+	ps = make([]*Project, 0)
+	err = queryRows(s.db, s.statement(QueryListProjects), []any{profileId}, func(rows *sql.Rows) (err error) {
+		var projectMySQL ProjectMySQL
+		err = rows.Scan(projectMySQL.fields()...)
+		if err != nil {
+			return
+		}
+		ps = append(ps, projectMySQL.serialize())
+		return
+	})
+	if err != nil {
+		ps = nil
+		return
+	}
+
+	return
+}
+
+// Save saves the given project.
+func (s *StorageMySQL) Save(p *Project) (err error) {
+	// validate
+	err = s.vl.Validate(p)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInvalid, "validate")
+		return
+	}
+
+	// deserialize
+	projectMySQL := deserialize(p)
+
+	// default values
+	projectMySQL.ID = sql.NullString{String: uuid.New().String(), Valid: true}
+	now := time.Now().UTC()
+	projectMySQL.CreatedAt = sql.NullTime{Time: now, Valid: true}
+	projectMySQL.UpdatedAt = sql.NullTime{Time: now, Valid: true}
+
+	// execute statement
+	var rowsAffected int64
+	rowsAffected, err = s.execN(s.statement(QuerySaveProject), projectMySQL.ID, projectMySQL.OwnerID, projectMySQL.Name, projectMySQL.Description, projectMySQL.CreatedAt, projectMySQL.UpdatedAt)
+	if err != nil {
+		return
+	}
+	if rowsAffected != 1 {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "rows affected")
+		return
+	}
+
+	// set default values
+	p.ID = optional.Some(projectMySQL.ID.String)
+	p.CreatedAt = optional.Some(now)
+	p.UpdatedAt = optional.Some(now)
+
+	return
+}
+
+// Update replaces the name and the description of the project with the same id as the given project.
+func (s *StorageMySQL) Update(profileId string, p *Project) (err error) {
+	// validate (with the profile as the owner, the query is scoped to it)
+	p.OwnerID = optional.Some(profileId)
+	err = s.vl.Validate(p)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInvalid, "validate")
+		return
+	}
+
+	// deserialize
+	projectMySQL := deserialize(p)
+	now := time.Now().UTC()
+
+	// execute statement
+	err = s.exec(s.statement(QueryUpdateProject), projectMySQL.Name, projectMySQL.Description, now, projectMySQL.ID, profileId)
+	if err != nil {
+		return
+	}
+
+	// set default values
+	var stored ProjectMySQL
+	err = queryRow(s.db, s.statement(QueryGetProject), []any{projectMySQL.ID, profileId}, stored.fields()...)
+	if err != nil {
+		return
+	}
+	p.CreatedAt = optional.Some(stored.CreatedAt.Time)
+	p.UpdatedAt = optional.Some(now)
+
+	return
+}
+
+// Delete removes the project with the given id.
+func (s *StorageMySQL) Delete(profileId string, id string) (err error) {
+	err = s.exec(s.statement(QueryDeleteProject), id, profileId)
+	return
+}
+
+// queryRow executes the given query and scans its single row into the destination.
+func queryRow(db *sql.DB, query string, args []any, dest ...any) (err error) {
+	// prepare statement
+	var stmt *sql.Stmt
+	stmt, err = db.Prepare(query)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "prepare")
+		return
+	}
+	defer stmt.Close()
+
+	// execute statement
+	err = stmt.QueryRow(args...).Scan(dest...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = fmt.Errorf("%w: %s", ErrStorageNotFound, "query row")
+			return
+		}
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "query row")
+		return
+	}
+
+	return
+}
+
+// queryRows executes the given query and scans each of its rows with the given function.
+func queryRows(db *sql.DB, query string, args []any, scan func(rows *sql.Rows) (err error)) (err error) {
+	// prepare statement
+	var stmt *sql.Stmt
+	stmt, err = db.Prepare(query)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "prepare")
+		return
+	}
+	defer stmt.Close()
+
+	// execute statement
+	var rows *sql.Rows
+	rows, err = stmt.Query(args...)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "query")
+		return
+	}
+	defer rows.Close()
+
+	// scan
+	for rows.Next() {
+		err = scan(rows)
+		if err != nil {
+			err = fmt.Errorf("%w: %s", ErrStorageInternal, "scan")
+			return
+		}
+	}
+	if err = rows.Err(); err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "rows")
+		return
+	}
+
+	return
+}
+
+// exec executes the given statement over a single project.
+// - no rows affected means the project was not found
+func (s *StorageMySQL) exec(query string, args ...any) (err error) {
+	var rowsAffected int64
+	rowsAffected, err = s.execN(query, args...)
+	if err != nil {
+		return
+	}
+
+	// check rows affected
+	if rowsAffected != 1 {
+		err = fmt.Errorf("%w: %s", ErrStorageNotFound, "rows affected")
+		return
+	}
+
+	return
+}
+
+// execN executes the given statement and returns the amount of rows affected.
+// - a project referenced by tasks (foreign key) fails with ErrStorageNotEmpty
+func (s *StorageMySQL) execN(query string, args ...any) (n int64, err error) {
+	// prepare statement
+	var stmt *sql.Stmt
+	stmt, err = s.db.Prepare(query)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "prepare")
+		return
+	}
+	defer stmt.Close()
+
+	// execute statement
+	var result sql.Result
+	result, err = stmt.Exec(args...)
+	if err != nil {
+		if s.foreignKey(err) {
+			err = fmt.Errorf("%w: %s", ErrStorageNotEmpty, "foreign key")
+			return
+		}
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "exec")
+		return
+	}
+
+	// check result
+	n, err = result.RowsAffected()
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "result rows affected")
+		return
+	}
+
+	return
+}
+
+// foreignKeyMySQL reports whether the error is the violation of a foreign key in MySQL (error 1451, a referenced row).
+func foreignKeyMySQL(err error) (ok bool) {
+	var errMySQL *mysql.MySQLError
+	if errors.As(err, &errMySQL) {
+		ok = errMySQL.Number == 1451
+	}
+	return
+}
@@ -0,0 +1,438 @@
+package project
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/LNMMusic/optional"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Tests
+func TestStorageMySQL_Get(t *testing.T) {
+	type input struct {profileId string; id string}
+	type output struct {p *Project; err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		string
+		input  		input
+		output 		output
+		// process
+		setDatabase func(mk sqlmock.Sqlmock)
+	}
+
+	cols := []string{"id", "owner_id", "name", "description", "created_at", "updated_at"}
+	cases := []testCase{
+		// success cases
+		{
+			title: "project",
+			input: input{profileId: "p1", id: "1"},
+			output: output{p: &Project{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Name: optional.Some("inbox")}},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows(cols).AddRow("1", "p1", "inbox", nil, nil, nil)
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetProject)).
+					ExpectQuery().WithArgs("1", "p1").
+					WillReturnRows(rows)
+			},
+		},
+
+		// failure cases
+		{
+			title: "not found",
+			input: input{profileId: "p1", id: "1"},
+			output: output{p: nil, err: ErrStorageNotFound, errMsg: "storage project not found: query row"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetProject)).
+					ExpectQuery().WithArgs("1", "p1").
+					WillReturnError(sql.ErrNoRows)
+			},
+		},
+		{
+			title: "prepare error",
+			input: input{profileId: "p1", id: "1"},
+			output: output{p: nil, err: ErrStorageInternal, errMsg: "storage internal error: prepare"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetProject)).
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStorageMySQL(db, NewValidatorMock())
+
+			// act
+			p, err := st.Get(c.input.profileId, c.input.id)
+
+			// assert
+			assert.Equal(t, c.output.p, p)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorageMySQL_List(t *testing.T) {
+	t1 := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type input struct {profileId string}
+	type output struct {ps []*Project; err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		string
+		input  		input
+		output 		output
+		// process
+		setDatabase func(mk sqlmock.Sqlmock)
+	}
+
+	cols := []string{"id", "owner_id", "name", "description", "created_at", "updated_at"}
+	cases := []testCase{
+		// success cases
+		{
+			title: "projects",
+			input: input{profileId: "p1"},
+			output: output{ps: []*Project{
+				{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Name: optional.Some("inbox"), CreatedAt: optional.Some(t1), UpdatedAt: optional.Some(t1)},
+				{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Name: optional.Some("work"), Description: optional.Some("description"), CreatedAt: optional.Some(t1), UpdatedAt: optional.Some(t1)},
+			}},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows(cols).
+					AddRow("2", "p1", "inbox", nil, t1, t1).
+					AddRow("1", "p1", "work", "description", t1, t1)
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryListProjects)).
+					ExpectQuery().WithArgs("p1").
+					WillReturnRows(rows)
+			},
+		},
+		{
+			title: "no projects",
+			input: input{profileId: "p1"},
+			output: output{ps: []*Project{}},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryListProjects)).
+					ExpectQuery().WithArgs("p1").
+					WillReturnRows(sqlmock.NewRows(cols))
+			},
+		},
+
+		// failure cases
+		{
+			title: "query error",
+			input: input{profileId: "p1"},
+			output: output{ps: nil, err: ErrStorageInternal, errMsg: "storage internal error: query"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryListProjects)).
+					ExpectQuery().WithArgs("p1").
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStorageMySQL(db, NewValidatorMock())
+
+			// act
+			ps, err := st.List(c.input.profileId)
+
+			// assert
+			assert.Equal(t, c.output.ps, ps)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorageMySQL_Save(t *testing.T) {
+	type input struct {p *Project}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+		setValidator func(mk *ValidatorMock)
+	}
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "project",
+			input: input{p: &Project{OwnerID: optional.Some("p1"), Name: optional.Some("inbox")}},
+			output: output{err: nil},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QuerySaveProject)).
+					ExpectExec().WithArgs(sqlmock.AnyArg(), "p1", "inbox", nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+
+		// failure cases
+		{
+			title: "invalid project",
+			input: input{p: &Project{OwnerID: optional.Some("p1")}},
+			output: output{err: ErrStorageInvalid, errMsg: "storage invalid project: validate"},
+			setDatabase: func(mk sqlmock.Sqlmock) {},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.Anything).Return(ErrValidatorFieldRequired)
+			},
+		},
+		{
+			title: "exec error",
+			input: input{p: &Project{OwnerID: optional.Some("p1"), Name: optional.Some("inbox")}},
+			output: output{err: ErrStorageInternal, errMsg: "storage internal error: exec"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QuerySaveProject)).
+					ExpectExec().
+					WillReturnError(sql.ErrConnDone)
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			vl := NewValidatorMock()
+			c.setValidator(vl)
+
+			st := NewStorageMySQL(db, vl)
+
+			// act
+			err = st.Save(c.input.p)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			} else {
+				assert.True(t, c.input.p.ID.IsSome())
+				assert.True(t, c.input.p.CreatedAt.IsSome())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+			vl.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStorageMySQL_Update(t *testing.T) {
+	created := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type input struct {profileId string; p *Project}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+		setValidator func(mk *ValidatorMock)
+	}
+
+	cols := []string{"id", "owner_id", "name", "description", "created_at", "updated_at"}
+	cases := []testCase{
+		// success cases
+		{
+			title: "rename",
+			input: input{profileId: "p1", p: &Project{ID: optional.Some("1"), Name: optional.Some("work")}},
+			output: output{err: nil},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryUpdateProject)).
+					ExpectExec().WithArgs("work", nil, sqlmock.AnyArg(), "1", "p1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetProject)).
+					ExpectQuery().WithArgs("1", "p1").
+					WillReturnRows(sqlmock.NewRows(cols).AddRow("1", "p1", "work", nil, created, created))
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+
+		// failure cases
+		{
+			title: "not found",
+			input: input{profileId: "p2", p: &Project{ID: optional.Some("1"), Name: optional.Some("work")}},
+			output: output{err: ErrStorageNotFound, errMsg: "storage project not found: rows affected"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryUpdateProject)).
+					ExpectExec().WithArgs("work", nil, sqlmock.AnyArg(), "1", "p2").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+		{
+			title: "invalid project",
+			input: input{profileId: "p1", p: &Project{ID: optional.Some("1")}},
+			output: output{err: ErrStorageInvalid, errMsg: "storage invalid project: validate"},
+			setDatabase: func(mk sqlmock.Sqlmock) {},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.Anything).Return(ErrValidatorFieldRequired)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			vl := NewValidatorMock()
+			c.setValidator(vl)
+
+			st := NewStorageMySQL(db, vl)
+
+			// act
+			err = st.Update(c.input.profileId, c.input.p)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			} else {
+				assert.Equal(t, optional.Some(created), c.input.p.CreatedAt)
+				assert.Equal(t, optional.Some(c.input.profileId), c.input.p.OwnerID)
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+			vl.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStorageMySQL_Delete(t *testing.T) {
+	type input struct {profileId string; id string}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		string
+		input  		input
+		output 		output
+		// process
+		setDatabase func(mk sqlmock.Sqlmock)
+	}
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "project",
+			input: input{profileId: "p1", id: "1"},
+			output: output{err: nil},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryDeleteProject)).
+					ExpectExec().WithArgs("1", "p1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+
+		// failure cases
+		{
+			title: "not found",
+			input: input{profileId: "p1", id: "1"},
+			output: output{err: ErrStorageNotFound, errMsg: "storage project not found: rows affected"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryDeleteProject)).
+					ExpectExec().WithArgs("1", "p1").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		{
+			title: "project with tasks",
+			input: input{profileId: "p1", id: "1"},
+			output: output{err: ErrStorageNotEmpty, errMsg: "storage project not empty: foreign key"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryDeleteProject)).
+					ExpectExec().WithArgs("1", "p1").
+					WillReturnError(&mysql.MySQLError{Number: 1451})
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStorageMySQL(db, NewValidatorMock())
+
+			// act
+			err = st.Delete(c.input.profileId, c.input.id)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package project
+
+import (
+	"database/sql"
+	"errors"
+
+	"api/pkg/postgres"
+
+	"github.com/lib/pq"
+)
+
+// constructor
+// - the projects table is created with the tables of the tasks (see task.SchemaPostgres)
+func NewStoragePostgres(db *sql.DB, vl Validator) *StoragePostgres {
+	s := NewStorageMySQL(db, vl)
+	s.bind = postgres.Bind
+	s.foreignKey = foreignKeyPostgres
+	return &StoragePostgres{StorageMySQL: s}
+}
+
+// StoragePostgres is the implementation of the project storage on PostgreSQL.
+// - it runs the statements of the MySQL storage, with the placeholders numbered ($1, $2, ...)
+// - a project referenced by tasks fails to be removed with ErrStorageNotEmpty, on the SQLSTATE 23503 (foreign_key_violation)
+type StoragePostgres struct {
+	// StorageMySQL runs the statements
+	*StorageMySQL
+}
+
+// foreignKeyPostgres reports whether the error is the violation of a foreign key in PostgreSQL (SQLSTATE 23503, foreign_key_violation).
+func foreignKeyPostgres(err error) (ok bool) {
+	var errPostgres *pq.Error
+	if errors.As(err, &errPostgres) {
+		ok = errPostgres.Code == "23503"
+	}
+	return
+}
@@ -0,0 +1,105 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/LNMMusic/optional"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Tests
+func TestStoragePostgres_Save(t *testing.T) {
+	// arrange
+	// -> the statements are matched as they are sent
+	db, mk, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	mk.
+		ExpectPrepare(`INSERT INTO projects (id, owner_id, name, description, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6)`).
+		ExpectExec().WithArgs(sqlmock.AnyArg(), "p1", "work", nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	vl := NewValidatorMock()
+	vl.On("Validate", mock.Anything).Return(nil)
+	st := NewStoragePostgres(db, vl)
+	p := &Project{OwnerID: optional.Some("p1"), Name: optional.Some("work")}
+
+	// act
+	err = st.Save(p)
+
+	// assert
+	assert.NoError(t, err)
+	assert.True(t, p.ID.IsSome())
+	assert.NoError(t, mk.ExpectationsWereMet())
+}
+
+func TestStoragePostgres_Delete(t *testing.T) {
+	type input struct {profileId string; id string}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		string
+		input  		input
+		output 		output
+		// process
+		setDatabase func(mk sqlmock.Sqlmock)
+	}
+
+	// -> the statements are matched as they are sent
+	deleteProject := `DELETE FROM projects WHERE id = $1 AND owner_id = $2`
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "project",
+			input: input{profileId: "p1", id: "1"},
+			output: output{err: nil},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(deleteProject).
+					ExpectExec().WithArgs("1", "p1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+
+		// failure cases
+		{
+			title: "project with tasks",
+			input: input{profileId: "p1", id: "1"},
+			output: output{err: ErrStorageNotEmpty, errMsg: "storage project not empty: foreign key"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(deleteProject).
+					ExpectExec().WithArgs("1", "p1").
+					WillReturnError(&pq.Error{Code: "23503"})
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStoragePostgres(db, NewValidatorMock())
+
+			// act
+			err = st.Delete(c.input.profileId, c.input.id)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
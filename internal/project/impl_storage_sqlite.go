@@ -0,0 +1,38 @@
+package project
+
+import (
+	"database/sql"
+	"errors"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// constructor
+// - the projects table is created with the tables of the tasks (see task.SchemaSQLite)
+func NewStorageSQLite(db *sql.DB, vl Validator) *StorageSQLite {
+	s := NewStorageMySQL(db, vl)
+	s.foreignKey = foreignKeySQLite
+	return &StorageSQLite{StorageMySQL: s}
+}
+
+// StorageSQLite is the implementation of the project storage on an embedded SQLite database (see sqlite.Open).
+// - it runs the statements of the MySQL storage as they are
+// - a project referenced by tasks fails to be removed with ErrStorageNotEmpty (the foreign keys are enforced)
+type StorageSQLite struct {
+	// StorageMySQL runs the statements
+	*StorageMySQL
+}
+
+// foreignKeySQLite reports whether the error is the violation of a foreign key in SQLite.
+// - the restricted removals fail as triggers
+func foreignKeySQLite(err error) (ok bool) {
+	var errSQLite *sqlite.Error
+	if errors.As(err, &errSQLite) {
+		switch errSQLite.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY, sqlite3.SQLITE_CONSTRAINT_TRIGGER:
+			ok = true
+		}
+	}
+	return
+}
@@ -0,0 +1,40 @@
+package project
+
+import (
+	"path/filepath"
+	"testing"
+
+	"api/pkg/sqlite"
+
+	"github.com/LNMMusic/optional"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests
+func TestStorageSQLite_Delete(t *testing.T) {
+	// arrange
+	// -> the statements run next to the tasks of the projects
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "projects.db"))
+	assert.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE projects (id TEXT PRIMARY KEY, owner_id TEXT NOT NULL, name TEXT NOT NULL, description TEXT, created_at DATETIME, updated_at DATETIME);
+	CREATE TABLE tasks (id TEXT PRIMARY KEY, project_id TEXT REFERENCES projects (id) ON DELETE RESTRICT)`)
+	assert.NoError(t, err)
+	st := NewStorageSQLite(db, NewValidatorLocal(nil))
+	p := &Project{OwnerID: optional.Some("p1"), Name: optional.Some("work")}
+	assert.NoError(t, st.Save(p))
+	id, _ := p.ID.Unwrap()
+	_, err = db.Exec(`INSERT INTO tasks (id, project_id) VALUES ('1', ?)`, id)
+	assert.NoError(t, err)
+
+	// act
+	stored, errGet := st.Get("p1", id)
+	errDelete := st.Delete("p1", id)
+
+	// assert
+	// -> a project with tasks is not removed
+	assert.NoError(t, errGet)
+	assert.Equal(t, optional.Some("work"), stored.Name)
+	assert.ErrorIs(t, errDelete, ErrStorageNotEmpty)
+}
@@ -0,0 +1,80 @@
+package project
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultMaxName is the default maximum length of the name of a project, in characters.
+	DefaultMaxName = 100
+	// DefaultMaxDescription is the default maximum length of the description of a project, in characters.
+	DefaultMaxDescription = 1000
+)
+
+// ValidatorConfig is the configuration of the local validator.
+type ValidatorConfig struct {
+	// MaxName is the maximum length of the name of a project, in characters.
+	MaxName int
+	// MaxDescription is the maximum length of the description of a project, in characters.
+	MaxDescription int
+}
+
+// constructor
+// - cfg is optional (nil for the default config)
+func NewValidatorLocal(cfg *ValidatorConfig) *ValidatorLocal {
+	// default config
+	maxName := DefaultMaxName
+	maxDescription := DefaultMaxDescription
+	if cfg != nil {
+		if cfg.MaxName > 0 {
+			maxName = cfg.MaxName
+		}
+		if cfg.MaxDescription > 0 {
+			maxDescription = cfg.MaxDescription
+		}
+	}
+
+	return &ValidatorLocal{maxName: maxName, maxDescription: maxDescription}
+}
+
+// ValidatorLocal is the local implementation of the project validator.
+type ValidatorLocal struct {
+	// maxName is the maximum length of the name, in characters
+	maxName int
+	// maxDescription is the maximum length of the description, in characters
+	maxDescription int
+}
+
+func (v *ValidatorLocal) Validate(p *Project) (err error) {
+	// check required fields (non nullable)
+	if !p.OwnerID.IsSome() {
+		err = fmt.Errorf("%w: owner_id", ErrValidatorFieldRequired)
+		return
+	}
+	if !p.Name.IsSome() {
+		err = fmt.Errorf("%w: name", ErrValidatorFieldRequired)
+		return
+	}
+
+	// check empty fields and quality values (non nullable)
+	// -> safe to not check err, due to the previous check
+	name, _ := p.Name.Unwrap()
+	if strings.TrimSpace(name) == "" {
+		err = fmt.Errorf("%w: name", ErrValidatorFieldEmpty)
+		return
+	}
+	if utf8.RuneCountInString(name) > v.maxName {
+		err = fmt.Errorf("%w: name longer than %d characters", ErrValidatorFieldQuality, v.maxName)
+		return
+	}
+
+	// check quality values (nullable)
+	if description, e := p.Description.Unwrap(); e == nil && utf8.RuneCountInString(description) > v.maxDescription {
+		err = fmt.Errorf("%w: description longer than %d characters", ErrValidatorFieldQuality, v.maxDescription)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,87 @@
+package project
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/LNMMusic/optional"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests
+func TestValidatorLocal_Validate(t *testing.T) {
+	type input struct {p *Project}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+		// cfg is the validator config (nil for the default one)
+		cfg    *ValidatorConfig
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "valid project",
+			input: input{p: &Project{OwnerID: optional.Some("p1"), Name: optional.Some("inbox")}},
+			output: output{err: nil, errMsg: ""},
+		},
+		{
+			title: "valid project with description",
+			input: input{p: &Project{OwnerID: optional.Some("p1"), Name: optional.Some("inbox"), Description: optional.Some("description")}},
+			output: output{err: nil, errMsg: ""},
+		},
+		{
+			title: "valid project with the maximum length (in characters)",
+			input: input{p: &Project{OwnerID: optional.Some("p1"), Name: optional.Some(strings.Repeat("ñ", 10)), Description: optional.Some(strings.Repeat("ñ", 20))}},
+			output: output{err: nil, errMsg: ""},
+			cfg: &ValidatorConfig{MaxName: 10, MaxDescription: 20},
+		},
+
+		// failure cases
+		{
+			title: "invalid project - owner_id required",
+			input: input{p: &Project{Name: optional.Some("inbox")}},
+			output: output{err: ErrValidatorFieldRequired, errMsg: "validator field required: owner_id"},
+		},
+		{
+			title: "invalid project - name required",
+			input: input{p: &Project{OwnerID: optional.Some("p1")}},
+			output: output{err: ErrValidatorFieldRequired, errMsg: "validator field required: name"},
+		},
+		{
+			title: "invalid project - name empty",
+			input: input{p: &Project{OwnerID: optional.Some("p1"), Name: optional.Some(" \n\t")}},
+			output: output{err: ErrValidatorFieldEmpty, errMsg: "validator field empty: name"},
+		},
+		{
+			title: "invalid project - name too long (default config)",
+			input: input{p: &Project{OwnerID: optional.Some("p1"), Name: optional.Some(strings.Repeat("a", DefaultMaxName + 1))}},
+			output: output{err: ErrValidatorFieldQuality, errMsg: "validator field quality: name longer than 100 characters"},
+		},
+		{
+			title: "invalid project - description too long",
+			input: input{p: &Project{OwnerID: optional.Some("p1"), Name: optional.Some("inbox"), Description: optional.Some(strings.Repeat("ñ", 21))}},
+			output: output{err: ErrValidatorFieldQuality, errMsg: "validator field quality: description longer than 20 characters"},
+			cfg: &ValidatorConfig{MaxDescription: 20},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			vl := NewValidatorLocal(c.cfg)
+
+			// act
+			err := vl.Validate(c.input.p)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package project
+
+import "github.com/stretchr/testify/mock"
+
+// constructor
+func NewStorageMock() *StorageMock {
+	mk := &StorageMock{}
+	mk.SetProject = func(p *Project) {}
+	return mk
+}
+
+// StorageMock is a mock implementation of the project storage.
+type StorageMock struct {
+	mock.Mock
+	SetProject func(p *Project)
+}
+
+func (m *StorageMock) Get(profileId string, id string) (p *Project, err error) {
+	args := m.Called(profileId, id)
+	p = args.Get(0).(*Project)
+	err = args.Error(1)
+	return
+}
+
+func (m *StorageMock) List(profileId string) (ps []*Project, err error) {
+	args := m.Called(profileId)
+	ps = args.Get(0).([]*Project)
+	err = args.Error(1)
+	return
+}
+
+func (m *StorageMock) Save(p *Project) (err error) {
+	args := m.Called(p)
+
+	m.SetProject(p)
+
+	err = args.Error(0)
+	return
+}
+
+func (m *StorageMock) Update(profileId string, p *Project) (err error) {
+	args := m.Called(profileId, p)
+
+	m.SetProject(p)
+
+	err = args.Error(0)
+	return
+}
+
+func (m *StorageMock) Delete(profileId string, id string) (err error) {
+	args := m.Called(profileId, id)
+	err = args.Error(0)
+	return
+}
@@ -0,0 +1,18 @@
+package project
+
+import "github.com/stretchr/testify/mock"
+
+// constructor
+func NewValidatorMock() *ValidatorMock {
+	return &ValidatorMock{}
+}
+
+// ValidatorMock is the mock implementation of the project validator.
+type ValidatorMock struct {
+	mock.Mock
+}
+
+func (v *ValidatorMock) Validate(p *Project) (err error) {
+	args := v.Called(p)
+	return args.Error(0)
+}
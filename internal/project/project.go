@@ -0,0 +1,62 @@
+package project
+
+import (
+	"errors"
+	"time"
+
+	"github.com/LNMMusic/optional"
+)
+
+// Interfaces
+type Project struct {
+	ID 			optional.Option[string]
+	// OwnerID is the id of the profile that owns the project (required to save it, then kept by the storage)
+	OwnerID 	optional.Option[string]
+	Name 		optional.Option[string]
+	Description optional.Option[string]
+	// CreatedAt and UpdatedAt are set by the storage
+	CreatedAt 	optional.Option[time.Time]
+	UpdatedAt 	optional.Option[time.Time]
+}
+
+// Storage is the interface that wraps the basic methods for a project storage.
+// - projects are scoped to the profile that owns them: the projects of other profiles are not found (ErrStorageNotFound)
+type Storage interface {
+	// Get returns the project with the given id.
+	Get(profileId string, id string) (p *Project, err error)
+
+	// List returns the projects of the profile, sorted by name.
+	List(profileId string) (ps []*Project, err error)
+
+	// Save saves the given project, setting its id and timestamps.
+	// - the project must have an owner
+	Save(p *Project) (err error)
+
+	// Update replaces the name and the description of the project with the same id.
+	// - the owner and the creation time are kept and the update time is set
+	Update(profileId string, p *Project) (err error)
+
+	// Delete removes the project with the given id.
+	// - a project with tasks can not be removed: the storages that keep the tasks too (MySQL) fail with ErrStorageNotEmpty,
+	// for the rest it is up to the caller to check it
+	Delete(profileId string, id string) (err error)
+}
+var (
+	ErrStorageInternal = errors.New("storage internal error")
+	ErrStorageNotFound = errors.New("storage project not found")
+	ErrStorageInvalid  = errors.New("storage invalid project")
+	// ErrStorageNotEmpty is returned when removing a project that still has tasks
+	ErrStorageNotEmpty = errors.New("storage project not empty")
+)
+
+// Validator is the interface that wraps the basic methods for a project validator.
+type Validator interface {
+	// Validate validates the given project.
+	Validate(p *Project) (err error)
+}
+var (
+	ErrValidatorInternal 	  = errors.New("validator internal error")
+	ErrValidatorFieldRequired = errors.New("validator field required")
+	ErrValidatorFieldEmpty	  = errors.New("validator field empty")
+	ErrValidatorFieldQuality  = errors.New("validator field quality")
+)
@@ -0,0 +1,126 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/LNMMusic/optional"
+)
+
+// OpKind is the kind of an operation of a batch.
+type OpKind string
+
+const (
+	OpCreate OpKind = "create"
+	OpUpdate OpKind = "update"
+	OpDelete OpKind = "delete"
+)
+
+// Op is an operation of a batch.
+type Op struct {
+	Kind OpKind
+	// Task is the task to create or update (nil for a delete)
+	Task *Task
+	// ID is the id of the task to delete
+	ID 	 string
+	// Version is the version the task to delete must still have (None for any, the task of an update has its own)
+	Version optional.Option[int]
+}
+
+// Mode is the way the operations of a batch are run.
+type Mode string
+
+const (
+	// ModeAtomic runs the operations in a single transaction: all of them are applied, or none
+	ModeAtomic 	   Mode = "atomic"
+	// ModeBestEffort runs every operation on its own: the ones that fail do not stop the rest
+	ModeBestEffort Mode = "best_effort"
+)
+
+// MaxBatch is the maximum number of operations of a batch.
+const MaxBatch = 100
+
+// Result is the result of an operation of a batch.
+type Result struct {
+	// Task is the created or updated task (nil for a delete or if the operation failed)
+	Task *Task
+	// Err is the error of the operation (nil if it succeeded)
+	Err  error
+}
+
+var (
+	// ErrStorageBatchAborted is returned when an atomic batch is not applied because one of its operations failed
+	ErrStorageBatchAborted = errors.New("storage batch aborted")
+)
+
+// checkBatch checks the number of operations and the mode of a batch.
+func checkBatch(ops []*Op, mode Mode) (err error) {
+	if len(ops) > MaxBatch {
+		err = fmt.Errorf("%w: batch of %d operations (max %d)", ErrStorageInvalid, len(ops), MaxBatch)
+		return
+	}
+	if mode != ModeAtomic && mode != ModeBestEffort {
+		err = fmt.Errorf("%w: batch mode %v", ErrStorageInvalid, mode)
+		return
+	}
+	return
+}
+
+// apply runs the operation on the storage, on behalf of the profile (the owner of the tasks it creates).
+func apply(st Storage, profileId string, op *Op) (rs *Result) {
+	rs = &Result{}
+	if op.Kind != OpDelete && op.Task == nil {
+		rs.Err = fmt.Errorf("%w: operation %v without task", ErrStorageInvalid, op.Kind)
+		return
+	}
+	switch op.Kind {
+	case OpCreate:
+		op.Task.OwnerID = optional.Some(profileId)
+		rs.Err = st.Save(op.Task)
+	case OpUpdate:
+		rs.Err = st.Update(profileId, op.Task)
+	case OpDelete:
+		rs.Err = st.Delete(profileId, op.ID, op.Version)
+	default:
+		rs.Err = fmt.Errorf("%w: operation %v", ErrStorageInvalid, op.Kind)
+	}
+	if rs.Err == nil && op.Kind != OpDelete {
+		rs.Task = op.Task
+	}
+	return
+}
+
+// runBatch runs every operation on its own, in order.
+func runBatch(st Storage, profileId string, ops []*Op) (rs []*Result) {
+	rs = make([]*Result, len(ops))
+	for i, op := range ops {
+		rs[i] = apply(st, profileId, op)
+	}
+	return
+}
+
+// runAtomic runs the operations in order until one of them fails.
+// - the failed operation keeps its error and the rest are marked with ErrStorageBatchAborted
+// - the error is the one of the failed operation, wrapped by ErrStorageBatchAborted (the caller must undo the applied ones)
+func runAtomic(st Storage, profileId string, ops []*Op) (rs []*Result, err error) {
+	rs = make([]*Result, len(ops))
+	for i, op := range ops {
+		rs[i] = apply(st, profileId, op)
+		if rs[i].Err != nil {
+			err = fmt.Errorf("%w: operation %d: %v", ErrStorageBatchAborted, i, rs[i].Err)
+			abort(rs, i)
+			return
+		}
+	}
+	return
+}
+
+// abort marks every result but the failed one with ErrStorageBatchAborted (failed is -1 if none failed).
+func abort(rs []*Result, failed int) {
+	for i := range rs {
+		if i == failed {
+			continue
+		}
+		rs[i] = &Result{Err: ErrStorageBatchAborted}
+	}
+}
@@ -0,0 +1,91 @@
+package task
+
+import "fmt"
+
+// Item is an item of the checklist of a task.
+type Item struct {
+	// ID is the id of the item, unique in its task (set by the storage)
+	ID 		string `json:"id"`
+	Text 	string `json:"text"`
+	Checked bool   `json:"checked"`
+}
+
+// Progress returns how many items of the checklist of the task are checked, out of the total.
+func (t *Task) Progress() (checked int, total int) {
+	for _, it := range t.Checklist {
+		if it.Checked {
+			checked++
+		}
+	}
+	total = len(t.Checklist)
+	return
+}
+
+// The checklist operations return a new checklist, so the stored one is only replaced once the task is validated with it.
+
+// addItem returns the checklist with the item at the end.
+func addItem(items []Item, item Item) []Item {
+	return append(append(make([]Item, 0, len(items)+1), items...), item)
+}
+
+// checkItem returns the checklist with the item with the given id checked or unchecked.
+func checkItem(items []Item, itemId string, checked bool) (edited []Item, err error) {
+	i, err := findItem(items, itemId)
+	if err != nil {
+		return
+	}
+
+	edited = append([]Item(nil), items...)
+	edited[i].Checked = checked
+	return
+}
+
+// moveItem returns the checklist with the item with the given id at the given position (from zero), shifting the rest.
+func moveItem(items []Item, itemId string, position int) (edited []Item, err error) {
+	i, err := findItem(items, itemId)
+	if err != nil {
+		return
+	}
+	if position < 0 || position >= len(items) {
+		err = fmt.Errorf("%w: item position %d", ErrStorageInvalid, position)
+		return
+	}
+
+	edited = make([]Item, 0, len(items))
+	for j, it := range items {
+		if j == i {
+			continue
+		}
+		if len(edited) == position {
+			edited = append(edited, items[i])
+		}
+		edited = append(edited, it)
+	}
+	if len(edited) == position {
+		edited = append(edited, items[i])
+	}
+	return
+}
+
+// removeItem returns the checklist without the item with the given id.
+func removeItem(items []Item, itemId string) (edited []Item, err error) {
+	i, err := findItem(items, itemId)
+	if err != nil {
+		return
+	}
+
+	edited = append(append(make([]Item, 0, len(items)-1), items[:i]...), items[i+1:]...)
+	return
+}
+
+// findItem returns the position of the item with the given id in the checklist.
+func findItem(items []Item, itemId string) (i int, err error) {
+	for i = range items {
+		if items[i].ID == itemId {
+			return
+		}
+	}
+
+	err = fmt.Errorf("%w: item %v", ErrStorageNotFound, itemId)
+	return
+}
@@ -0,0 +1,121 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests
+func TestMoveItem(t *testing.T) {
+	type input struct {itemId string; position int}
+	type output struct {ids []string; err error; errMsg string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+	}
+
+	items := []Item{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "move an item to the start",
+			input: input{itemId: "c", position: 0},
+			output: output{ids: []string{"c", "a", "b", "d"}},
+		},
+		{
+			title: "move an item to the end",
+			input: input{itemId: "a", position: 3},
+			output: output{ids: []string{"b", "c", "d", "a"}},
+		},
+		{
+			title: "move an item down",
+			input: input{itemId: "b", position: 2},
+			output: output{ids: []string{"a", "c", "b", "d"}},
+		},
+		{
+			title: "move an item to its own position",
+			input: input{itemId: "b", position: 1},
+			output: output{ids: []string{"a", "b", "c", "d"}},
+		},
+
+		// failure cases
+		{
+			title: "item not found",
+			input: input{itemId: "e", position: 0},
+			output: output{err: ErrStorageNotFound, errMsg: "storage task not found: item e"},
+		},
+		{
+			title: "position out of the checklist",
+			input: input{itemId: "a", position: 4},
+			output: output{err: ErrStorageInvalid, errMsg: "storage invalid task: item position 4"},
+		},
+		{
+			title: "negative position",
+			input: input{itemId: "a", position: -1},
+			output: output{err: ErrStorageInvalid, errMsg: "storage invalid task: item position -1"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// act
+			edited, err := moveItem(items, c.input.itemId, c.input.position)
+
+			// assert
+			var ids []string
+			for _, it := range edited {
+				ids = append(ids, it.ID)
+			}
+			assert.Equal(t, c.output.ids, ids)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> the checklist is not changed in place
+			assert.Equal(t, []Item{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}}, items)
+		})
+	}
+}
+
+func TestCheckItem(t *testing.T) {
+	// arrange
+	items := []Item{{ID: "a", Text: "milk"}, {ID: "b", Text: "bread"}}
+
+	// act
+	edited, err := checkItem(items, "b", true)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []Item{{ID: "a", Text: "milk"}, {ID: "b", Text: "bread", Checked: true}}, edited)
+	assert.False(t, items[1].Checked)
+}
+
+func TestRemoveItem(t *testing.T) {
+	// arrange
+	items := []Item{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+
+	// act
+	edited, err := removeItem(items, "b")
+	_, errNotFound := removeItem(items, "d")
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []Item{{ID: "a"}, {ID: "c"}}, edited)
+	assert.Equal(t, []Item{{ID: "a"}, {ID: "b"}, {ID: "c"}}, items)
+	assert.ErrorIs(t, errNotFound, ErrStorageNotFound)
+}
+
+func TestTask_Progress(t *testing.T) {
+	// arrange
+	ts := &Task{Checklist: []Item{{ID: "a", Checked: true}, {ID: "b"}, {ID: "c", Checked: true}}}
+
+	// act
+	checked, total := ts.Progress()
+
+	// assert
+	assert.Equal(t, 2, checked)
+	assert.Equal(t, 3, total)
+}
@@ -0,0 +1,65 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+)
+
+// topological returns the given ids (without duplicates) sorted so that every task comes after the tasks that block it.
+// - blockers are the blockers of each task by id, the ones out of the ids are walked through to keep the order of the tasks they link
+// - the order is deterministic: tasks and blockers are visited by id
+func topological(ids []string, blockers map[string][]string) (order []string, err error) {
+	set := make(map[string]bool, len(ids))
+	sorted := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !set[id] {
+			set[id] = true
+			sorted = append(sorted, id)
+		}
+	}
+	sort.Strings(sorted)
+
+	// depth first, a task is added once all of its blockers are
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[string]int)
+	var visit func(id string) (err error)
+	visit = func(id string) (err error) {
+		switch state[id] {
+		case visited:
+			return
+		case visiting:
+			err = fmt.Errorf("%w: %v", ErrStorageCycle, id)
+			return
+		}
+		state[id] = visiting
+
+		bs := append([]string{}, blockers[id]...)
+		sort.Strings(bs)
+		for _, b := range bs {
+			err = visit(b)
+			if err != nil {
+				return
+			}
+		}
+
+		state[id] = visited
+		if set[id] {
+			order = append(order, id)
+		}
+		return
+	}
+
+	order = make([]string, 0, len(sorted))
+	for _, id := range sorted {
+		err = visit(id)
+		if err != nil {
+			order = nil
+			return
+		}
+	}
+
+	return
+}
@@ -0,0 +1,76 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests
+func TestTopological(t *testing.T) {
+	type input struct {ids []string; blockers map[string][]string}
+	type output struct {order []string; err error; errMsg string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "tasks without dependencies are sorted by id",
+			input: input{ids: []string{"c", "a", "b"}},
+			output: output{order: []string{"a", "b", "c"}},
+		},
+		{
+			title: "tasks come after their blockers",
+			input: input{
+				ids: []string{"a", "b", "c"},
+				blockers: map[string][]string{"a": {"c"}, "c": {"b"}},
+			},
+			output: output{order: []string{"b", "c", "a"}},
+		},
+		{
+			title: "blockers out of the ids are walked through",
+			input: input{
+				ids: []string{"a", "b"},
+				blockers: map[string][]string{"a": {"x"}, "x": {"b"}},
+			},
+			output: output{order: []string{"b", "a"}},
+		},
+		{
+			title: "duplicated ids are ordered once",
+			input: input{ids: []string{"a", "a"}},
+			output: output{order: []string{"a"}},
+		},
+
+		// failure cases
+		{
+			title: "cycle",
+			input: input{
+				ids: []string{"a"},
+				blockers: map[string][]string{"a": {"b"}, "b": {"a"}},
+			},
+			output: output{
+				err: ErrStorageCycle,
+				errMsg: "storage invalid task: cycle: a",
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// act
+			order, err := topological(c.input.ids, c.input.blockers)
+
+			// assert
+			assert.Equal(t, c.output.order, order)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+		})
+	}
+}
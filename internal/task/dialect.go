@@ -0,0 +1,103 @@
+package task
+
+import (
+	"context"
+	"database/sql"
+)
+
+// statements of the MySQL storage written in standard SQL, for the databases that can not join in a delete or an update, nor have ON DUPLICATE KEY
+const (
+	queryRemoveTaskLabel = `DELETE FROM task_labels WHERE task_id = ? AND label = ? AND task_id IN (SELECT id FROM tasks WHERE deleted_at IS NULL)`
+	queryRemoveTaskDependency = `DELETE FROM task_dependencies WHERE task_id = ? AND blocker_id = ? AND task_id IN (SELECT id FROM tasks WHERE owner_id = ? AND deleted_at IS NULL)`
+	queryCompleteDescendants = `WITH RECURSIVE descendants (id) AS (SELECT id FROM tasks WHERE parent_id = ? AND deleted_at IS NULL UNION ALL SELECT tasks.id FROM tasks JOIN descendants ON tasks.parent_id = descendants.id WHERE tasks.deleted_at IS NULL) UPDATE tasks SET status = 'done', updated_at = ?, version = version + 1 WHERE id IN (SELECT id FROM descendants) AND status NOT IN ('done', 'archived')`
+	querySaveTaskGrant = `INSERT INTO task_grants (task_id, profile_id, permission) VALUES (?, ?, ?) ON CONFLICT (task_id, profile_id) DO UPDATE SET permission = excluded.permission`
+)
+
+// dialect writes the statements of the SQL storage, written for MySQL, for a database (see StorageMySQL.dl).
+// - a nil dialect keeps the statements as they are (see dialectMySQL for the statements built on the fly)
+type dialect struct {
+	// queries replaces the statements the database writes another way, by their MySQL text (see newDialect)
+	queries map[string]string
+	// lock is the clause that locks the rows read by a statement until the end of the transaction
+	lock string
+	// labels is the column of the labels of a task, a comma separated list sorted by name
+	labels string
+	// like is the operator that matches a text with a pattern (\ escapes the wildcards), case insensitive as the columns of MySQL (see where)
+	like string
+	// equal is the condition that matches a text column (%s) with a text, case insensitive as the columns of MySQL (see where)
+	equal string
+	// asc and desc are the directions of the sort, nulls first when ascending and last when descending as in MySQL (see listQuery)
+	asc  string
+	desc string
+	// bind rewrites the ? placeholders of the statements (nil keeps them)
+	bind func(query string) string
+}
+
+// dialectMySQL writes the statements for MySQL, as they are.
+var dialectMySQL = newDialect(&dialect{
+	queries: map[string]string{},
+	lock: 	 lockMySQL,
+	labels:  columnLabels,
+	like: 	 "LIKE ?",
+	equal: 	 "%s = ?",
+	asc: 	 "ASC",
+	desc: 	 "DESC",
+})
+
+// newDialect returns the dialect, with the statements that select the labels of the tasks or lock rows written with its labels column and its lock clause.
+func newDialect(dl *dialect) *dialect {
+	listTasks := querySelectTask + dl.labels + ` FROM tasks`
+	for query, q := range map[string]string{
+		QueryGetTask: 		 querySelectTask + dl.labels + queryFromGetTask,
+		QueryListTasks: 	 listTasks,
+		QueryTree: 			 queryWithSubtree + listTasks + queryWhereSubtree,
+		QueryGetTaskLocked:  querySelectTask + dl.labels + queryFromGetTaskLocked + dl.lock,
+		QueryGetTaskState: 	 queryGetTaskState + dl.lock,
+		QueryGetTaskVersion: queryGetTaskVersion + dl.lock,
+		QueryGetTaskRank: 	 queryGetTaskRank + dl.lock,
+		QueryListOwnerRanks: queryListOwnerRanks + dl.lock,
+		QueryGetTaskOwner: 	 queryGetTaskOwner + dl.lock,
+	} {
+		dl.queries[query] = q
+	}
+	return dl
+}
+
+// query returns the statement in the dialect, its placeholders left as they are.
+func (dl *dialect) query(query string) string {
+	if dl == nil {
+		return query
+	}
+	if q, ok := dl.queries[query]; ok {
+		query = q
+	}
+	return query
+}
+
+// rewrite returns the statement in the dialect.
+func (dl *dialect) rewrite(query string) string {
+	query = dl.query(query)
+	if dl != nil && dl.bind != nil {
+		query = dl.bind(query)
+	}
+	return query
+}
+
+// statements returns the database (or the transaction) that prepares the statements in the dialect.
+func (dl *dialect) statements(db preparer) preparer {
+	if _, ok := db.(dialectPreparer); dl == nil || ok {
+		return db
+	}
+	return dialectPreparer{db: db, dl: dl}
+}
+
+// dialectPreparer prepares the statements in its dialect.
+type dialectPreparer struct {
+	db preparer
+	dl *dialect
+}
+
+// PrepareContext prepares the statement rewritten in the dialect.
+func (p dialectPreparer) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return p.db.PrepareContext(ctx, p.dl.rewrite(query))
+}
@@ -0,0 +1,170 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests
+func TestDialect_Rewrite(t *testing.T) {
+	type input struct {dl *dialect; query string}
+	type output struct {query string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+	}
+
+	cases := []testCase{
+		// mysql
+		{
+			title: "mysql keeps the statements",
+			input: input{dl: dialectMySQL, query: QueryGetTaskVersion},
+			output: output{query: `SELECT version FROM tasks WHERE id = ? FOR UPDATE`},
+		},
+		{
+			title: "mysql keeps the statements with the labels",
+			input: input{dl: dialectMySQL, query: QueryGetTaskLocked},
+			output: output{query: QueryGetTaskLocked},
+		},
+		{
+			title: "nil dialect keeps the statements",
+			input: input{dl: nil, query: QuerySaveTaskLabel},
+			output: output{query: `INSERT IGNORE INTO task_labels (task_id, label) VALUES (?, ?)`},
+		},
+		// sqlite
+		{
+			title: "sqlite locks nothing",
+			input: input{dl: dialectSQLite, query: QueryGetTaskVersion},
+			output: output{query: `SELECT version FROM tasks WHERE id = ?`},
+		},
+		{
+			title: "sqlite ignores the duplicated labels",
+			input: input{dl: dialectSQLite, query: QuerySaveTaskLabel},
+			output: output{query: `INSERT OR IGNORE INTO task_labels (task_id, label) VALUES (?, ?)`},
+		},
+		{
+			title: "sqlite counts the characters of the ranks",
+			input: input{dl: dialectSQLite, query: QueryListUnbalancedOwners},
+			output: output{query: `SELECT DISTINCT owner_id FROM tasks WHERE rank_key IS NULL OR LENGTH(rank_key) > ?`},
+		},
+		{
+			title: "sqlite sorts the labels before concatenating them",
+			input: input{dl: dialectSQLite, query: QueryGetTaskLocked},
+			output: output{query: `SELECT id, owner_id, title, description, status, parent_id, start_at, due_at, created_at, updated_at, deleted_at, recurrence, series_id, occurrence, version, project_id, rank_key, checklist, (SELECT GROUP_CONCAT(label) FROM (SELECT label FROM task_labels WHERE task_labels.task_id = tasks.id ORDER BY label)) AS labels FROM tasks WHERE id = ?`},
+		},
+		// postgres
+		{
+			title: "postgres locks the tasks",
+			input: input{dl: dialectPostgres, query: QueryGetTaskState},
+			output: output{query: `SELECT tasks.owner_id, task_grants.permission, tasks.status, tasks.series_id, tasks.occurrence, tasks.version, tasks.rank_key, tasks.checklist, tasks.created_at, tasks.deleted_at FROM tasks LEFT JOIN task_grants ON task_grants.task_id = tasks.id AND task_grants.profile_id = $1 WHERE tasks.id = $2 AND tasks.deleted_at IS NULL FOR UPDATE OF tasks`},
+		},
+		{
+			title: "postgres ignores the conflicting labels",
+			input: input{dl: dialectPostgres, query: QuerySaveTaskLabel},
+			output: output{query: `INSERT INTO task_labels (task_id, label) VALUES ($1, $2) ON CONFLICT DO NOTHING`},
+		},
+		{
+			title: "postgres counts the ancestors without booleans",
+			input: input{dl: dialectPostgres, query: QueryTaskAncestors},
+			output: output{query: `WITH RECURSIVE ancestors (id, parent_id) AS (SELECT id, parent_id FROM tasks WHERE id = $1 AND owner_id = $2 UNION ALL SELECT tasks.id, tasks.parent_id FROM tasks JOIN ancestors ON tasks.id = ancestors.parent_id) SELECT COUNT(*), COALESCE(SUM(CASE WHEN id = $3 THEN 1 ELSE 0 END), 0) FROM ancestors`},
+		},
+		{
+			title: "postgres keeps the statements written in standard sql",
+			input: input{dl: dialectPostgres, query: QueryPurgeTasks},
+			output: output{query: `DELETE FROM tasks WHERE deleted_at IS NOT NULL AND deleted_at < $1`},
+		},
+	}
+
+	// run cases
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			// ...
+
+			// act
+			query := c.input.dl.rewrite(c.input.query)
+
+			// assert
+			assert.Equal(t, c.output.query, query)
+		})
+	}
+}
+
+func TestListQuery(t *testing.T) {
+	type input struct {dl *dialect; query *Query}
+	type output struct {q string; args []any}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+	}
+
+	// -> by due date, latest first, the tasks whose title contains the text and have the label
+	query := &Query{
+		Filter: And{Filters: []Filter{
+			Condition{Field: FieldTitle, Operator: OperatorContains, Value: "50%"},
+			Condition{Field: FieldLabels, Operator: OperatorEq, Value: "home"},
+		}},
+		Sort: Sort{Field: FieldDueAt, Desc: true},
+	}
+
+	cases := []testCase{
+		{
+			title: "mysql",
+			input: input{dl: dialectMySQL, query: query},
+			output: output{
+				q: QueryListTasks + ` WHERE owner_id = ? AND deleted_at IS NULL AND (title LIKE ? AND id IN (SELECT task_id FROM task_labels WHERE label = ?)) ORDER BY due_at DESC, id LIMIT ?`,
+				args: []any{"p1", `%50\%%`, "home"},
+			},
+		},
+		{
+			title: "nil dialect as mysql",
+			input: input{dl: nil, query: &Query{Sort: Sort{Field: FieldDueAt}}},
+			output: output{
+				q: QueryListTasks + ` WHERE owner_id = ? AND deleted_at IS NULL ORDER BY due_at ASC, id LIMIT ?`,
+				args: []any{"p1"},
+			},
+		},
+		{
+			title: "sqlite",
+			input: input{dl: dialectSQLite, query: query},
+			output: output{
+				q: querySelectTask + columnLabelsSQLite + ` FROM tasks WHERE owner_id = ? AND deleted_at IS NULL AND (title LIKE ? ESCAPE '\' AND id IN (SELECT task_id FROM task_labels WHERE label = ? COLLATE NOCASE)) ORDER BY due_at DESC, id LIMIT ?`,
+				args: []any{"p1", `%50\%%`, "home"},
+			},
+		},
+		{
+			title: "postgres",
+			input: input{dl: dialectPostgres, query: query},
+			output: output{
+				q: querySelectTask + columnLabelsPostgres + ` FROM tasks WHERE owner_id = ? AND deleted_at IS NULL AND (title ILIKE ? AND id IN (SELECT task_id FROM task_labels WHERE LOWER(label) = LOWER(?))) ORDER BY due_at DESC NULLS LAST, id LIMIT ?`,
+				args: []any{"p1", `%50\%%`, "home"},
+			},
+		},
+		{
+			title: "postgres ascending",
+			input: input{dl: dialectPostgres, query: &Query{Sort: Sort{Field: FieldDueAt}}},
+			output: output{
+				q: querySelectTask + columnLabelsPostgres + ` FROM tasks WHERE owner_id = ? AND deleted_at IS NULL ORDER BY due_at ASC NULLS FIRST, id LIMIT ?`,
+				args: []any{"p1"},
+			},
+		},
+	}
+
+	// run cases
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			// ...
+
+			// act
+			q, args := listQuery(c.input.dl, scopeOwned, "p1", c.input.query, nil)
+
+			// assert
+			assert.Equal(t, c.output.q, q)
+			assert.Equal(t, c.output.args, args)
+		})
+	}
+}
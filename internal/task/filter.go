@@ -0,0 +1,354 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Field is a field of a task that can be used to filter or sort tasks.
+type Field string
+
+const (
+	FieldTitle 		 Field = "title"
+	FieldDescription Field = "description"
+	FieldStatus 	 Field = "status"
+	FieldDueAt 		 Field = "due_at"
+	FieldStartAt 	 Field = "start_at"
+	FieldCreatedAt 	 Field = "created_at"
+	FieldUpdatedAt 	 Field = "updated_at"
+	FieldLabels 	 Field = "labels"
+	FieldParentID 	 Field = "parent_id"
+	FieldSeriesID 	 Field = "series_id"
+	FieldProjectID 	 Field = "project_id"
+	// FieldRank is the order set by hand (it can only be sorted by)
+	FieldRank 		 Field = "rank"
+)
+
+// Operator is the comparison applied by a condition between a field and a value.
+type Operator string
+
+const (
+	OperatorEq 		 Operator = "eq"
+	OperatorNe 		 Operator = "ne"
+	OperatorContains Operator = "contains"
+	OperatorLt 		 Operator = "lt"
+	OperatorLte 	 Operator = "lte"
+	OperatorGt 		 Operator = "gt"
+	OperatorGte 	 Operator = "gte"
+)
+
+// kind is the type of the values of a field.
+type kind int
+
+const (
+	kindString kind = iota
+	// kindStatus is a string restricted to the known statuses
+	kindStatus
+	kindTime
+	// kindLabels is a set of labels, eq matches if the set has the label
+	kindLabels
+)
+
+// fieldSpec describes how a field can be queried.
+type fieldSpec struct {
+	kind 	  kind
+	operators []Operator
+	sortable  bool
+	// nullable fields may have no value (nil goes first)
+	nullable  bool
+}
+
+// fieldSpecs are the fields that can be queried.
+var fieldSpecs = map[Field]fieldSpec{
+	FieldTitle: 	  {kind: kindString, operators: []Operator{OperatorEq, OperatorContains}, sortable: true},
+	FieldDescription: {kind: kindString, operators: []Operator{OperatorEq, OperatorContains}},
+	FieldStatus: 	  {kind: kindStatus, operators: []Operator{OperatorEq, OperatorNe}, sortable: true},
+	FieldDueAt: 	  {kind: kindTime, operators: timeOperators, sortable: true, nullable: true},
+	FieldStartAt: 	  {kind: kindTime, operators: timeOperators, sortable: true, nullable: true},
+	FieldCreatedAt:   {kind: kindTime, operators: timeOperators, sortable: true, nullable: true},
+	FieldUpdatedAt:   {kind: kindTime, operators: timeOperators, sortable: true, nullable: true},
+	FieldLabels: 	  {kind: kindLabels, operators: []Operator{OperatorEq}},
+	FieldParentID: 	  {kind: kindString, operators: []Operator{OperatorEq}},
+	FieldSeriesID: 	  {kind: kindString, operators: []Operator{OperatorEq}},
+	FieldProjectID:   {kind: kindString, operators: []Operator{OperatorEq}},
+	FieldRank: 		  {kind: kindString, sortable: true, nullable: true},
+}
+
+// timeOperators are the operators supported by the time fields.
+var timeOperators = []Operator{OperatorLt, OperatorLte, OperatorGt, OperatorGte}
+
+// Filter is a node of the filter AST.
+// - And: matches if all of its filters match
+// - Condition: matches if the field of the task compares with the value
+type Filter interface {
+	filter()
+}
+
+// And is the conjunction of filters.
+type And struct {
+	Filters []Filter
+}
+
+// Condition compares a field of the task with a value.
+// - Value is a string or a time.Time, depending on the field (a label or a status is a string)
+type Condition struct {
+	Field 	 Field
+	Operator Operator
+	Value 	 any
+}
+
+func (And) filter() {}
+func (Condition) filter() {}
+
+// Sort is the order of a list of tasks (by id if the field is empty).
+// - ties are always broken by id
+type Sort struct {
+	Field Field
+	Desc  bool
+}
+
+// ParseFilter parses the given params into a filter.
+// - field=value compares with the eq operator
+// - field[operator]=value compares with the given operator
+// - no params is no filter (nil)
+func ParseFilter(params map[string][]string) (f Filter, err error) {
+	if len(params) == 0 {
+		return
+	}
+
+	// sort keys to keep the filter deterministic
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	and := And{Filters: make([]Filter, 0, len(params))}
+	for _, key := range keys {
+		// key: field and operator
+		field, op := Field(key), OperatorEq
+		if i := strings.Index(key, "["); i != -1 && strings.HasSuffix(key, "]") {
+			field, op = Field(key[:i]), Operator(key[i+1:len(key)-1])
+		}
+
+		// value
+		for _, raw := range params[key] {
+			var cond Condition
+			cond, err = parseCondition(field, op, raw)
+			if err != nil {
+				return
+			}
+			and.Filters = append(and.Filters, cond)
+		}
+	}
+
+	f = and
+	return
+}
+
+// parseCondition parses the raw value of the condition, based on the kind of the field.
+func parseCondition(field Field, op Operator, raw string) (cond Condition, err error) {
+	cond = Condition{Field: field, Operator: op, Value: raw}
+
+	spec, ok := fieldSpecs[field]
+	if !ok {
+		err = fmt.Errorf("%w: %s", ErrQueryUnknownField, field)
+		return
+	}
+	switch spec.kind {
+	case kindStatus:
+		if !Status(raw).Valid() {
+			err = fmt.Errorf("%w: %s must be a known status", ErrQueryInvalidValue, field)
+			return
+		}
+	case kindTime:
+		cond.Value, err = parseTime(raw)
+		if err != nil {
+			err = fmt.Errorf("%w: %s must be a RFC 3339 time or a date", ErrQueryInvalidValue, field)
+			return
+		}
+	case kindLabels:
+		cond.Value = NormalizeLabel(raw)
+	}
+
+	err = checkCondition(cond)
+	return
+}
+
+// parseTime parses a RFC 3339 time or a date (midnight UTC).
+func parseTime(raw string) (t time.Time, err error) {
+	t, err = time.Parse(time.RFC3339, raw)
+	if err != nil {
+		t, err = time.Parse("2006-01-02", raw)
+	}
+	return
+}
+
+// ParseSort parses the given sort param: field (ascending) or -field (descending).
+func ParseSort(param string) (s Sort, err error) {
+	if param == "" {
+		return
+	}
+
+	s.Field = Field(strings.TrimPrefix(param, "-"))
+	s.Desc = strings.HasPrefix(param, "-")
+
+	err = checkSort(s)
+	return
+}
+
+// checkFilter checks the filter is well formed.
+func checkFilter(f Filter) (err error) {
+	switch f := f.(type) {
+	case nil:
+	case And:
+		for _, sub := range f.Filters {
+			err = checkFilter(sub)
+			if err != nil {
+				return
+			}
+		}
+	case Condition:
+		err = checkCondition(f)
+	default:
+		err = fmt.Errorf("%w: %T", ErrQueryUnknownOperator, f)
+	}
+	return
+}
+
+// checkCondition checks the field, the operator and the value of the condition match.
+func checkCondition(cond Condition) (err error) {
+	spec, ok := fieldSpecs[cond.Field]
+	if !ok {
+		err = fmt.Errorf("%w: %s", ErrQueryUnknownField, cond.Field)
+		return
+	}
+
+	supported := false
+	for _, op := range spec.operators {
+		if op == cond.Operator {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		err = fmt.Errorf("%w: %s for %s", ErrQueryUnknownOperator, cond.Operator, cond.Field)
+		return
+	}
+
+	err = checkValue(cond.Field, cond.Value)
+	return
+}
+
+// checkValue checks the value is of the kind of the field.
+func checkValue(field Field, v any) (err error) {
+	ok := false
+	switch fieldSpecs[field].kind {
+	case kindString, kindLabels, kindStatus:
+		_, ok = v.(string)
+	case kindTime:
+		_, ok = v.(time.Time)
+	}
+	if !ok {
+		err = fmt.Errorf("%w: %s", ErrQueryInvalidValue, field)
+		return
+	}
+
+	return
+}
+
+// checkSort checks the field of the sort is sortable.
+func checkSort(s Sort) (err error) {
+	if s.Field == "" {
+		return
+	}
+
+	spec, ok := fieldSpecs[s.Field]
+	if !ok {
+		err = fmt.Errorf("%w: %s", ErrQueryUnknownField, s.Field)
+		return
+	}
+	if !spec.sortable {
+		err = fmt.Errorf("%w: %s is not sortable", ErrQueryInvalidValue, s.Field)
+		return
+	}
+
+	return
+}
+
+// value returns the value of the field of the task (nil if it is None).
+// - labels are returned as a []string
+func value(task *Task, field Field) (v any) {
+	switch field {
+	case FieldTitle:
+		v = task.Title.Value
+	case FieldDescription:
+		v = task.Description.Value
+	case FieldStatus:
+		if status, e := task.Status.Unwrap(); e == nil {
+			v = string(status)
+		}
+	case FieldDueAt:
+		v = task.DueAt.Value
+	case FieldStartAt:
+		v = task.StartAt.Value
+	case FieldCreatedAt:
+		v = task.CreatedAt.Value
+	case FieldUpdatedAt:
+		v = task.UpdatedAt.Value
+	case FieldLabels:
+		v = task.Labels
+	case FieldParentID:
+		v = task.ParentID.Value
+	case FieldSeriesID:
+		v = task.SeriesID.Value
+	case FieldProjectID:
+		v = task.ProjectID.Value
+	case FieldRank:
+		v = task.Rank.Value
+	}
+
+	// dereference
+	switch p := v.(type) {
+	case *string:
+		if p == nil {
+			return nil
+		}
+		v = *p
+	case *time.Time:
+		if p == nil {
+			return nil
+		}
+		v = *p
+	}
+	return
+}
+
+// compare compares two values of the same kind (nil goes first).
+func compare(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	switch a := a.(type) {
+	case string:
+		return strings.Compare(a, b.(string))
+	case time.Time:
+		switch {
+		case a.Equal(b.(time.Time)):
+			return 0
+		case a.Before(b.(time.Time)):
+			return -1
+		default:
+			return 1
+		}
+	}
+	return 0
+}
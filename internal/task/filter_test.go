@@ -0,0 +1,164 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests
+func TestParseFilter(t *testing.T) {
+	type input struct {params map[string][]string}
+	type output struct {f Filter; err error; errMsg string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "no params",
+			input: input{params: map[string][]string{}},
+			output: output{f: nil},
+		},
+		{
+			title: "eq and contains conditions",
+			input: input{params: map[string][]string{
+				"title[contains]": {"report"},
+				"status": {"done"},
+				"description[eq]": {"description"},
+			}},
+			output: output{f: And{Filters: []Filter{
+				Condition{Field: FieldDescription, Operator: OperatorEq, Value: "description"},
+				Condition{Field: FieldStatus, Operator: OperatorEq, Value: "done"},
+				Condition{Field: FieldTitle, Operator: OperatorContains, Value: "report"},
+			}}},
+		},
+		{
+			title: "ne condition",
+			input: input{params: map[string][]string{"status[ne]": {"archived"}}},
+			output: output{f: And{Filters: []Filter{
+				Condition{Field: FieldStatus, Operator: OperatorNe, Value: "archived"},
+			}}},
+		},
+		{
+			title: "labels are normalized",
+			input: input{params: map[string][]string{"labels": {"Backend", " urgent "}}},
+			output: output{f: And{Filters: []Filter{
+				Condition{Field: FieldLabels, Operator: OperatorEq, Value: "backend"},
+				Condition{Field: FieldLabels, Operator: OperatorEq, Value: "urgent"},
+			}}},
+		},
+		{
+			title: "time range conditions",
+			input: input{params: map[string][]string{
+				"due_at[gte]": {"2023-01-01T10:00:00Z"},
+				"due_at[lt]": {"2023-01-02"},
+			}},
+			output: output{f: And{Filters: []Filter{
+				Condition{Field: FieldDueAt, Operator: OperatorGte, Value: time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)},
+				Condition{Field: FieldDueAt, Operator: OperatorLt, Value: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)},
+			}}},
+		},
+
+		// failure cases
+		{
+			title: "unknown field",
+			input: input{params: map[string][]string{"owner": {"me"}}},
+			output: output{err: ErrQueryUnknownField, errMsg: "query unknown field: owner"},
+		},
+		{
+			title: "unknown operator",
+			input: input{params: map[string][]string{"title[gt]": {"a"}}},
+			output: output{err: ErrQueryUnknownOperator, errMsg: "query unknown operator: gt for title"},
+		},
+		{
+			title: "unsupported operator for the field",
+			input: input{params: map[string][]string{"status[contains]": {"done"}}},
+			output: output{err: ErrQueryUnknownOperator, errMsg: "query unknown operator: contains for status"},
+		},
+		{
+			title: "invalid value",
+			input: input{params: map[string][]string{"status": {"finished"}}},
+			output: output{err: ErrQueryInvalidValue, errMsg: "query invalid value: status must be a known status"},
+		},
+		{
+			title: "invalid time",
+			input: input{params: map[string][]string{"due_at[lt]": {"tomorrow"}}},
+			output: output{err: ErrQueryInvalidValue, errMsg: "query invalid value: due_at must be a RFC 3339 time or a date"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// act
+			f, err := ParseFilter(c.input.params)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+				return
+			}
+			assert.Equal(t, c.output.f, f)
+		})
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	type input struct {param string}
+	type output struct {s Sort; err error; errMsg string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "default sort",
+			input: input{param: ""},
+			output: output{s: Sort{}},
+		},
+		{
+			title: "ascending sort",
+			input: input{param: "title"},
+			output: output{s: Sort{Field: FieldTitle}},
+		},
+		{
+			title: "descending sort",
+			input: input{param: "-title"},
+			output: output{s: Sort{Field: FieldTitle, Desc: true}},
+		},
+
+		// failure cases
+		{
+			title: "unknown field",
+			input: input{param: "-owner"},
+			output: output{s: Sort{Field: "owner", Desc: true}, err: ErrQueryUnknownField, errMsg: "query unknown field: owner"},
+		},
+		{
+			title: "field not sortable",
+			input: input{param: "description"},
+			output: output{s: Sort{Field: FieldDescription}, err: ErrQueryInvalidValue, errMsg: "query invalid value: description is not sortable"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// act
+			s, err := ParseSort(c.input.param)
+
+			// assert
+			assert.Equal(t, c.output.s, s)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package task
+
+import "fmt"
+
+// Permission is the access to a task that its owner grants to another profile.
+type Permission string
+
+const (
+	// PermissionRead lets the profile get the task (with its subtasks).
+	PermissionRead Permission = "read"
+	// PermissionEdit lets the profile update the task and its labels too.
+	PermissionEdit Permission = "edit"
+)
+
+// Allows returns whether the permission includes the wanted one (edit includes read).
+func (p Permission) Allows(want Permission) bool {
+	return p == want || p == PermissionEdit
+}
+
+// Grant is the access to a task given by its owner to another profile (the task is assigned to it).
+type Grant struct {
+	// ProfileID is the id of the profile the task is shared with
+	ProfileID  string
+	Permission Permission
+}
+
+// granted returns the access of the profile to a task of the given owner, shared with it with the given permission (empty if it is not shared).
+// - the owner has full access
+func granted(profileId string, ownerId string, permission Permission) Permission {
+	if profileId == ownerId {
+		return PermissionEdit
+	}
+	return permission
+}
+
+// checkGrant checks the grant can be given on a task of the given owner.
+func checkGrant(ownerId string, grant *Grant) (err error) {
+	switch {
+	case grant.ProfileID == "":
+		err = fmt.Errorf("%w: grant profile required", ErrStorageInvalid)
+	case grant.ProfileID == ownerId:
+		err = fmt.Errorf("%w: grant to the owner", ErrStorageInvalid)
+	case grant.Permission != PermissionRead && grant.Permission != PermissionEdit:
+		err = fmt.Errorf("%w: grant permission %q", ErrStorageInvalid, grant.Permission)
+	}
+	return
+}
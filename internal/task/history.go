@@ -0,0 +1,95 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Action is the kind of change of a history entry.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+)
+
+// Change is the change of a field of a task, with the values as json (null when the field is not set).
+type Change struct {
+	Field string
+	From  json.RawMessage
+	To    json.RawMessage
+}
+
+// Entry is a change of a task made by a profile, in the history of the task.
+type Entry struct {
+	TaskID 	  string
+	// ProfileID is the id of the profile that made the change
+	ProfileID string
+	Action 	  Action
+	// Changes are the changed fields, in the order of the fields of the task
+	Changes   []Change
+	At 		  time.Time
+}
+
+// History is the interface that wraps the basic methods for the storage of the history of the tasks.
+type History interface {
+	// Record adds the given entry to the history of its task.
+	Record(e *Entry) (err error)
+
+	// List returns the history of the task with the given id, from the oldest entry.
+	List(taskId string) (es []*Entry, err error)
+
+	// WithContext returns the history that runs its methods with the given context.
+	// - the statements of a database history are canceled when the context is done
+	WithContext(ctx context.Context) (hs History)
+}
+
+// diff returns the fields that changed between the given versions of a task (created and updated times are left out).
+func diff(before *Task, after *Task) (cs []Change, err error) {
+	fields := []struct{name string; from any; to any}{
+		{"title", before.Title, after.Title},
+		{"description", before.Description, after.Description},
+		{"status", before.Status, after.Status},
+		{"parent_id", before.ParentID, after.ParentID},
+		{"start_at", before.StartAt, after.StartAt},
+		{"due_at", before.DueAt, after.DueAt},
+		{"labels", labelsOf(before), labelsOf(after)},
+		{"recurrence", before.Recurrence, after.Recurrence},
+		{"project_id", before.ProjectID, after.ProjectID},
+		{"checklist", checklistOf(before), checklistOf(after)},
+	}
+
+	for _, f := range fields {
+		var from, to []byte
+		from, err = json.Marshal(f.from)
+		if err != nil {
+			return
+		}
+		to, err = json.Marshal(f.to)
+		if err != nil {
+			return
+		}
+		if !bytes.Equal(from, to) {
+			cs = append(cs, Change{Field: f.name, From: from, To: to})
+		}
+	}
+	return
+}
+
+// labelsOf returns the labels of the task (no labels is an empty list).
+func labelsOf(ts *Task) []string {
+	if ts.Labels == nil {
+		return []string{}
+	}
+	return ts.Labels
+}
+
+// checklistOf returns the checklist of the task (no items is an empty list).
+func checklistOf(ts *Task) []Item {
+	if ts.Checklist == nil {
+		return []Item{}
+	}
+	return ts.Checklist
+}
@@ -0,0 +1,43 @@
+package task
+
+import (
+	"context"
+	"sync"
+)
+
+// constructor
+func NewHistoryLocal() *HistoryLocal {
+	return &HistoryLocal{entries: make(map[string][]*Entry)}
+}
+
+// HistoryLocal is the local implementation of the history of the tasks.
+// - it is safe for concurrent use: the entries are recorded one at a time, and listed as a copy
+type HistoryLocal struct {
+	// mu guards entries
+	mu sync.RWMutex
+	// entries are the entries of each task, by task id (from the oldest)
+	entries map[string][]*Entry
+}
+
+func (h *HistoryLocal) Record(e *Entry) (err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries[e.TaskID] = append(h.entries[e.TaskID], e)
+	return
+}
+
+// WithContext returns the history itself: its methods run in memory, there is nothing to cancel.
+func (h *HistoryLocal) WithContext(ctx context.Context) (hs History) {
+	hs = h
+	return
+}
+
+func (h *HistoryLocal) List(taskId string) (es []*Entry, err error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	es = make([]*Entry, 0, len(h.entries[taskId]))
+	es = append(es, h.entries[taskId]...)
+	return
+}
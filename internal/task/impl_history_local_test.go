@@ -0,0 +1,81 @@
+package task
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/LNMMusic/optional"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests
+func TestHistoryLocal_List(t *testing.T) {
+	// arrange
+	h := NewHistoryLocal()
+	assert.NoError(t, h.Record(&Entry{TaskID: "1", Action: ActionCreate}))
+
+	// act
+	es, err := h.List("1")
+	es[0] = &Entry{TaskID: "1", Action: ActionUpdate}
+	es = append(es, &Entry{TaskID: "1", Action: ActionUpdate})
+	again, errAgain := h.List("1")
+
+	// assert
+	// -> the history is not changed through the entries listed
+	assert.NoError(t, err)
+	assert.NoError(t, errAgain)
+	assert.Equal(t, []*Entry{{TaskID: "1", Action: ActionCreate}}, again)
+}
+
+func TestHistoryLocal_Concurrent(t *testing.T) {
+	// run with the race detector (go test -race) to catch unguarded accesses
+	const n = 50
+
+	// arrange
+	db := []*Task{{ID: optional.Some("0"), OwnerID: optional.Some("p1"), Title: optional.Some("shared"), Status: optional.Some(StatusTodo), Version: optional.Some(1)}}
+	h := NewHistoryLocal()
+	st := NewStorageHistory(NewStorageLocal(db, NewValidatorLocal(nil), nil), h)
+
+	// act
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	labeled := make([]bool, n)
+	for i := 0; i < n; i++ {
+		wg.Add(3)
+		// writers: save a task (its creation is recorded)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = st.Save(&Task{OwnerID: optional.Some("p1"), Title: optional.Some(fmt.Sprintf("title %d", i)), Status: optional.Some(StatusTodo)})
+		}(i)
+		// writers: change the same task
+		go func(i int) {
+			defer wg.Done()
+			// -> some are rejected past the labels a task can have
+			labeled[i] = st.AddLabel("p1", "0", fmt.Sprintf("label-%d", i), optional.None[int]()) == nil
+		}(i)
+		// readers: list the history of the same task
+		go func() {
+			defer wg.Done()
+			es, _ := h.List("0")
+			for _, e := range es {
+				_ = e.Action
+			}
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	var changes int
+	for i := 0; i < n; i++ {
+		assert.NoError(t, errs[i])
+		if labeled[i] {
+			changes++
+		}
+	}
+	es, err := h.List("0")
+	assert.NoError(t, err)
+	assert.Len(t, es, changes)
+	assert.Len(t, h.entries, n+1)
+}
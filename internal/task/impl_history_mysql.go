@@ -0,0 +1,127 @@
+package task
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// constructor
+func NewHistoryMySQL(db *sql.DB) *HistoryMySQL {
+	return &HistoryMySQL{db: db, ctx: context.Background()}
+}
+
+// HistoryMySQL is an implementation with MySQL of the History interface.
+// - task_history table (id, task_id, profile_id, action, changes, created_at), with id auto incremented and task_id referencing tasks (id) on delete cascade
+// - the changes are stored as a json list of {"field", "from", "to"} (sent as text, as a json column takes it)
+const (
+	QuerySaveTaskHistory = `INSERT INTO task_history (task_id, profile_id, action, changes, created_at) VALUES (?, ?, ?, ?, ?)`
+	QueryListTaskHistory = `SELECT task_id, profile_id, action, changes, created_at FROM task_history WHERE task_id = ? ORDER BY created_at, id`
+)
+
+// ChangeMySQL is the MySQL representation of a change. (internal Data Transfer Object)
+type ChangeMySQL struct {
+	Field string 		  `json:"field"`
+	From  json.RawMessage `json:"from"`
+	To 	  json.RawMessage `json:"to"`
+}
+
+type HistoryMySQL struct {
+	// db is the database connection.
+	db *sql.DB
+	// tx is the transaction the statements are run on (nil to run them on the database, see on).
+	tx preparer
+	// ctx is the context the statements are run with (see WithContext).
+	ctx context.Context
+	// dl adapts the statements to the database (nil for MySQL, see dialect).
+	dl *dialect
+}
+
+// WithContext returns a copy of the history that runs its statements with the given context.
+func (h *HistoryMySQL) WithContext(ctx context.Context) (hs History) {
+	cp := *h
+	cp.ctx = ctx
+	hs = &cp
+	return
+}
+
+// on returns a copy of the history that records the entries in the given transaction (of a task storage on the same database).
+func (h *HistoryMySQL) on(tx preparer) (hs History) {
+	cp := *h
+	cp.tx = tx
+	hs = &cp
+	return
+}
+
+// conn returns the transaction the history is bound to, or the database.
+func (h *HistoryMySQL) conn() (db preparer) {
+	if h.tx != nil {
+		db = h.tx
+		return
+	}
+	db = h.dl.statements(h.db)
+	return
+}
+
+// Record adds the given entry to the history of its task.
+func (h *HistoryMySQL) Record(e *Entry) (err error) {
+	// deserialize
+	cs := make([]ChangeMySQL, 0, len(e.Changes))
+	for _, c := range e.Changes {
+		cs = append(cs, ChangeMySQL{Field: c.Field, From: c.From, To: c.To})
+	}
+	var changes []byte
+	changes, err = json.Marshal(cs)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "marshal")
+		return
+	}
+
+	// execute statement
+	var rowsAffected int64
+	rowsAffected, err = execN(h.ctx, h.conn(), QuerySaveTaskHistory, e.TaskID, e.ProfileID, string(e.Action), string(changes), e.At.UTC())
+	if err != nil {
+		return
+	}
+	if rowsAffected != 1 {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "rows affected")
+		return
+	}
+
+	return
+}
+
+// List returns the history of the task with the given id, from the oldest entry.
+func (h *HistoryMySQL) List(taskId string) (es []*Entry, err error) {
+	es = make([]*Entry, 0)
+	err = queryRows(h.ctx, h.conn(), QueryListTaskHistory, []any{taskId}, func(rows *sql.Rows) (err error) {
+		var e Entry
+		var action string
+		var changes []byte
+		err = rows.Scan(&e.TaskID, &e.ProfileID, &action, &changes, &e.At)
+		if err != nil {
+			return
+		}
+		e.Action = Action(action)
+
+		// serialize
+		var cs []ChangeMySQL
+		err = json.Unmarshal(changes, &cs)
+		if err != nil {
+			return
+		}
+		for _, c := range cs {
+			e.Changes = append(e.Changes, Change{Field: c.Field, From: c.From, To: c.To})
+		}
+
+		es = append(es, &e)
+		return
+	})
+	if err != nil {
+		es = nil
+		return
+	}
+
+	return
+}
@@ -0,0 +1,191 @@
+package task
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests
+func TestHistoryMySQL_Record(t *testing.T) {
+	at := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type input struct {e *Entry}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		string
+		input  		input
+		output 		output
+		// process
+		setDatabase func(mk sqlmock.Sqlmock)
+	}
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "entry",
+			input: input{e: &Entry{TaskID: "1", ProfileID: "p1", Action: ActionUpdate, Changes: []Change{{Field: "status", From: json.RawMessage(`"done"`), To: json.RawMessage(`"todo"`)}}, At: at}},
+			output: output{err: nil},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QuerySaveTaskHistory)).
+					ExpectExec().WithArgs("1", "p1", "update", `[{"field":"status","from":"done","to":"todo"}]`, at).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+
+		// failure cases
+		{
+			title: "exec error",
+			input: input{e: &Entry{TaskID: "1", ProfileID: "p1", Action: ActionCreate, At: at}},
+			output: output{err: ErrStorageInternal, errMsg: "storage internal error: exec"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QuerySaveTaskHistory)).
+					ExpectExec().
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			hs := NewHistoryMySQL(db)
+
+			// act
+			err = hs.Record(c.input.e)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestHistoryMySQL_List(t *testing.T) {
+	at := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type input struct {taskId string}
+	type output struct {es []*Entry; err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		string
+		input  		input
+		output 		output
+		// process
+		setDatabase func(mk sqlmock.Sqlmock)
+	}
+
+	cols := []string{"task_id", "profile_id", "action", "changes", "created_at"}
+	cases := []testCase{
+		// success cases
+		{
+			title: "entries",
+			input: input{taskId: "1"},
+			output: output{es: []*Entry{
+				{TaskID: "1", ProfileID: "p1", Action: ActionCreate, Changes: []Change{{Field: "title", From: json.RawMessage(`null`), To: json.RawMessage(`"title"`)}}, At: at},
+				{TaskID: "1", ProfileID: "p2", Action: ActionUpdate, Changes: []Change{{Field: "status", From: json.RawMessage(`"done"`), To: json.RawMessage(`"todo"`)}}, At: at},
+			}},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows(cols).
+					AddRow("1", "p1", "create", []byte(`[{"field":"title","from":null,"to":"title"}]`), at).
+					AddRow("1", "p2", "update", `[{"field":"status","from":"done","to":"todo"}]`, at)
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryListTaskHistory)).
+					ExpectQuery().WithArgs("1").
+					WillReturnRows(rows)
+			},
+		},
+		{
+			title: "no entries",
+			input: input{taskId: "1"},
+			output: output{es: []*Entry{}},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryListTaskHistory)).
+					ExpectQuery().WithArgs("1").
+					WillReturnRows(sqlmock.NewRows(cols))
+			},
+		},
+
+		// failure cases
+		{
+			title: "invalid changes",
+			input: input{taskId: "1"},
+			output: output{es: nil, err: ErrStorageInternal, errMsg: "storage internal error: scan"},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows(cols).AddRow("1", "p1", "create", []byte(`invalid`), at)
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryListTaskHistory)).
+					ExpectQuery().WithArgs("1").
+					WillReturnRows(rows)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			hs := NewHistoryMySQL(db)
+
+			// act
+			es, err := hs.List(c.input.taskId)
+
+			// assert
+			assert.Equal(t, c.output.es, es)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestHistoryMySQL_WithContext(t *testing.T) {
+	// arrange
+	db, mk, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	hs := NewHistoryMySQL(db).WithContext(ctx)
+
+	// act
+	// -> the request was canceled: nothing is sent to the database
+	errRecord := hs.Record(&Entry{TaskID: "1", ProfileID: "p1", Action: ActionCreate, At: time.Now()})
+	es, errList := hs.List("1")
+
+	// assert
+	assert.ErrorIs(t, errRecord, ErrStorageInternal)
+	assert.ErrorIs(t, errList, ErrStorageInternal)
+	assert.Nil(t, es)
+	assert.NoError(t, mk.ExpectationsWereMet())
+}
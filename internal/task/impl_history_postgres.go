@@ -0,0 +1,18 @@
+package task
+
+import "database/sql"
+
+// constructor
+// - the task_history table is created with the tables of the tasks (see SchemaPostgres)
+func NewHistoryPostgres(db *sql.DB) *HistoryPostgres {
+	h := NewHistoryMySQL(db)
+	h.dl = dialectPostgres
+	return &HistoryPostgres{HistoryMySQL: h}
+}
+
+// HistoryPostgres is the implementation of the history on PostgreSQL.
+// - it runs the statements of the MySQL history, rewritten for PostgreSQL (see dialectPostgres)
+type HistoryPostgres struct {
+	// HistoryMySQL runs the statements
+	*HistoryMySQL
+}
@@ -0,0 +1,41 @@
+package task
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests
+func TestHistoryPostgres_RecordList(t *testing.T) {
+	// arrange
+	// -> the statements are matched as they are sent
+	at := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	db, mk, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	mk.
+		ExpectPrepare(`INSERT INTO task_history (task_id, profile_id, action, changes, created_at) VALUES ($1, $2, $3, $4, $5)`).
+		ExpectExec().WithArgs("1", "p1", "update", `[{"field":"status","from":"done","to":"todo"}]`, at).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mk.
+		ExpectPrepare(`SELECT task_id, profile_id, action, changes, created_at FROM task_history WHERE task_id = $1 ORDER BY created_at, id`).
+		ExpectQuery().WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"task_id", "profile_id", "action", "changes", "created_at"}).AddRow("1", "p1", "update", []byte(`[{"field": "status", "from": "done", "to": "todo"}]`), at))
+
+	h := NewHistoryPostgres(db)
+	e := &Entry{TaskID: "1", ProfileID: "p1", Action: ActionUpdate, Changes: []Change{{Field: "status", From: json.RawMessage(`"done"`), To: json.RawMessage(`"todo"`)}}, At: at}
+
+	// act
+	errRecord := h.Record(e)
+	es, errList := h.List("1")
+
+	// assert
+	assert.NoError(t, errRecord)
+	assert.NoError(t, errList)
+	assert.Equal(t, []*Entry{e}, es)
+	assert.NoError(t, mk.ExpectationsWereMet())
+}
@@ -0,0 +1,19 @@
+package task
+
+import "database/sql"
+
+// constructor
+// - the task_history table is created with the tables of the tasks (see SchemaSQLite, NewStorageSQLite)
+func NewHistorySQLite(db *sql.DB) *HistorySQLite {
+	h := NewHistoryMySQL(db)
+	h.dl = dialectSQLite
+	return &HistorySQLite{HistoryMySQL: h}
+}
+
+// HistorySQLite is the implementation of the history on an embedded SQLite database (see sqlite.Open).
+// - it runs the statements of the MySQL history, rewritten for SQLite (see dialectSQLite)
+// - the changes are stored as json text
+type HistorySQLite struct {
+	// HistoryMySQL runs the statements
+	*HistoryMySQL
+}
@@ -0,0 +1,108 @@
+package task
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/LNMMusic/optional"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests
+func TestHistorySQLite_List(t *testing.T) {
+	// arrange
+	// -> the history is kept next to the tasks, in the tables of the storage
+	st := newStorageSQLite(t, nil)
+	id := saveSQLite(t, st, "p1", "title")
+	h := NewHistorySQLite(st.db)
+	at := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, h.Record(&Entry{TaskID: id, ProfileID: "p1", Action: ActionCreate, At: at}))
+	assert.NoError(t, h.Record(&Entry{TaskID: id, ProfileID: "p2", Action: ActionUpdate, Changes: []Change{{Field: "status", From: json.RawMessage(`"todo"`), To: json.RawMessage(`"done"`)}}, At: at.Add(time.Hour)}))
+
+	// act
+	es, err := h.List(id)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, es, 2)
+	assert.Equal(t, ActionCreate, es[0].Action)
+	assert.Equal(t, "p1", es[0].ProfileID)
+	assert.True(t, at.Equal(es[0].At))
+	assert.Equal(t, ActionUpdate, es[1].Action)
+	assert.Equal(t, []Change{{Field: "status", From: json.RawMessage(`"todo"`), To: json.RawMessage(`"done"`)}}, es[1].Changes)
+	assert.True(t, at.Add(time.Hour).Equal(es[1].At))
+}
+
+func TestHistorySQLite_Recorded(t *testing.T) {
+	// arrange
+	// -> the entries are recorded in the transaction of the writes
+	st := newStorageSQLite(t, &Config{Hierarchy: HierarchyCascade})
+	h := NewHistorySQLite(st.db)
+	impl := NewStorageHistory(st, h)
+	parent := &Task{OwnerID: optional.Some("p1"), Title: optional.Some("parent"), Status: optional.Some(StatusTodo)}
+	assert.NoError(t, impl.Save(parent))
+	parentId, _ := parent.ID.Unwrap()
+	child := &Task{OwnerID: optional.Some("p1"), Title: optional.Some("child"), Status: optional.Some(StatusTodo), ParentID: optional.Some(parentId)}
+	assert.NoError(t, impl.Save(child))
+	childId, _ := child.ID.Unwrap()
+
+	// act
+	err := impl.Update("p1", &Task{ID: optional.Some(parentId), Title: optional.Some("parent"), Status: optional.Some(StatusDone)})
+
+	// assert
+	// -> the subtask completed in cascade is recorded too
+	assert.NoError(t, err)
+	es, err := h.List(childId)
+	assert.NoError(t, err)
+	assert.Len(t, es, 2)
+	assert.Equal(t, ActionCreate, es[0].Action)
+	assert.Equal(t, ActionUpdate, es[1].Action)
+	assert.Equal(t, "p1", es[1].ProfileID)
+	assert.Equal(t, []Change{{Field: "status", From: json.RawMessage(`"todo"`), To: json.RawMessage(`"done"`)}}, es[1].Changes)
+}
+
+func TestHistorySQLite_RecordedBatch(t *testing.T) {
+	// arrange
+	st := newStorageSQLite(t, nil)
+	h := NewHistorySQLite(st.db)
+	impl := NewStorageHistory(st, h)
+	id := saveSQLite(t, st, "p1", "title")
+	ops := []*Op{
+		{Kind: OpUpdate, Task: &Task{ID: optional.Some(id), Title: optional.Some("title a"), Status: optional.Some(StatusTodo)}},
+		{Kind: OpUpdate, Task: &Task{ID: optional.Some(id), Title: optional.Some("title b"), Status: optional.Some(StatusTodo)}},
+	}
+
+	// act
+	_, err := impl.Batch("p1", ops, ModeAtomic)
+
+	// assert
+	// -> each operation is recorded with its own changes
+	assert.NoError(t, err)
+	es, err := h.List(id)
+	assert.NoError(t, err)
+	assert.Len(t, es, 2)
+	assert.Equal(t, []Change{{Field: "title", From: json.RawMessage(`"title"`), To: json.RawMessage(`"title a"`)}}, es[0].Changes)
+	assert.Equal(t, []Change{{Field: "title", From: json.RawMessage(`"title a"`), To: json.RawMessage(`"title b"`)}}, es[1].Changes)
+}
+
+func TestHistorySQLite_RecordFails(t *testing.T) {
+	// arrange
+	st := newStorageSQLite(t, nil)
+	impl := NewStorageHistory(st, NewHistorySQLite(st.db))
+	id := saveSQLite(t, st, "p1", "title")
+	_, err := st.db.Exec(`DROP TABLE task_history`)
+	assert.NoError(t, err)
+
+	// act
+	err = impl.Update("p1", &Task{ID: optional.Some(id), Title: optional.Some("new title"), Status: optional.Some(StatusTodo)})
+
+	// assert
+	// -> the write that could not be recorded is rolled back
+	assert.ErrorIs(t, err, ErrStorageInternal)
+	ts, err := st.Get("p1", id)
+	assert.NoError(t, err)
+	assert.Equal(t, optional.Some("title"), ts.Title)
+	assert.Equal(t, optional.Some(1), ts.Version)
+}
@@ -0,0 +1,101 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// constructor
+// - the storage hands its writes to the history if it can (see recordable), or it is left as it is
+func NewStorageHistory(st Storage, hs History) *StorageHistory {
+	impl := &StorageHistory{hs: hs, now: time.Now}
+	impl.Storage = st
+	if rs, ok := st.(recordable); ok {
+		impl.Storage = rs.recording(impl.record)
+	}
+	return impl
+}
+
+// StorageHistory is the implementation of the Storage interface that records the changes of the tasks in a History.
+// - every write that creates or updates a task (labels and checklist included) is recorded, with the changed fields and the profile that made it;
+// so are the subtasks a write completes in cascade and the next occurrence of a recurring task it completes, as changed by the same profile
+// - the wrapped storage hands every write with the task before and after it, taken while the write holds the task: under the lock of
+// a local storage, from the rows locked in the transaction of a database storage (a database history records them in that transaction)
+// - the operations of a batch are recorded one by one, each with its own changes
+// - a write is kept with its entries or not at all: if they can not be recorded, the write is undone and fails with ErrStorageInternal
+// - moves, rebalances and the trash do not change the recorded fields: they are not recorded
+type StorageHistory struct {
+	// Storage is the storage implementation (to be wrapped), handing its writes to the history
+	Storage
+
+	// hs is the history of the tasks
+	hs History
+	// now returns the current time
+	now func() time.Time
+}
+
+// write is the change of a task by a write to a storage.
+type write struct {
+	// before is the task before the write (nil if the write created it)
+	before *Task
+	after  *Task
+}
+
+// recorder records the writes a storage made on behalf of the profile, before they are kept.
+// - ctx is the context of the writes, and tx their transaction for a database storage (nil for the rest)
+type recorder func(ctx context.Context, tx preparer, profileId string, ws []*write) (err error)
+
+// recordable is a storage that hands its writes to a recorder.
+type recordable interface {
+	Storage
+	// recording returns the storage that hands its writes to the recorder (it fails the writes the recorder fails)
+	recording(rc recorder) (st Storage)
+}
+
+// transactional is a history that can record its entries in the transaction of the writes of a database storage.
+type transactional interface {
+	on(tx preparer) (hs History)
+}
+
+// WithContext returns a copy of the storage that runs the methods of the wrapped storage with the given context.
+// - the wrapped storage hands its writes with the context, the history records them with it
+func (impl *StorageHistory) WithContext(ctx context.Context) (st Storage) {
+	cp := *impl
+	cp.Storage = impl.Storage.WithContext(ctx)
+	st = &cp
+	return
+}
+
+// record adds the changes of the writes to the history of their tasks (nothing for a write without changes).
+func (impl *StorageHistory) record(ctx context.Context, tx preparer, profileId string, ws []*write) (err error) {
+	hs := impl.hs.WithContext(ctx)
+	if th, ok := hs.(transactional); ok && tx != nil {
+		hs = th.on(tx)
+	}
+
+	for _, w := range ws {
+		before, action := w.before, ActionUpdate
+		if before == nil {
+			before, action = &Task{}, ActionCreate
+		}
+
+		var cs []Change
+		cs, err = diff(before, w.after)
+		if err != nil {
+			err = fmt.Errorf("%w: history: %v", ErrStorageInternal, err)
+			return
+		}
+		if len(cs) == 0 {
+			continue
+		}
+
+		id, _ := w.after.ID.Unwrap()
+		err = hs.Record(&Entry{TaskID: id, ProfileID: profileId, Action: action, Changes: cs, At: impl.now()})
+		if err != nil {
+			err = fmt.Errorf("%w: history: %v", ErrStorageInternal, err)
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,463 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/LNMMusic/optional"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Tests
+func TestStorageHistory_Save(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type input struct {task *Task}
+	type output struct {es []*Entry; err error; errMsg string}
+	type testCase struct {
+		title		string
+		input		input
+		output		output
+		setStorage	func(mk *StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "record the creation of a task by its owner",
+			input: input{task: &Task{OwnerID: optional.Some("p1"), Title: optional.Some("title"), Status: optional.Some(StatusTodo), Labels: []string{"backend"}}},
+			output: output{es: []*Entry{{
+				TaskID: "1",
+				ProfileID: "p1",
+				Action: ActionCreate,
+				Changes: []Change{
+					{Field: "title", From: json.RawMessage(`null`), To: json.RawMessage(`"title"`)},
+					{Field: "status", From: json.RawMessage(`null`), To: json.RawMessage(`"todo"`)},
+					{Field: "labels", From: json.RawMessage(`[]`), To: json.RawMessage(`["backend"]`)},
+				},
+				At: now,
+			}}},
+			setStorage: func(mk *StorageMock) {
+				mk.On("Save", mock.Anything).Return(nil)
+				mk.Writes = []*write{{after: &Task{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title"), Status: optional.Some(StatusTodo), Labels: []string{"backend"}, CreatedAt: optional.Some(now), UpdatedAt: optional.Some(now)}}}
+			},
+		},
+
+		// failure cases
+		{
+			title: "nothing is recorded if the task is not saved",
+			input: input{task: &Task{OwnerID: optional.Some("p1")}},
+			output: output{es: []*Entry{}, err: ErrStorageInvalid, errMsg: "storage invalid task"},
+			setStorage: func(mk *StorageMock) {
+				mk.On("Save", mock.Anything).Return(ErrStorageInvalid)
+				mk.Writes = []*write{{after: &Task{ID: optional.Some("1"), Title: optional.Some("title")}}}
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := NewStorageMock()
+			c.setStorage(st)
+
+			hs := NewHistoryLocal()
+
+			impl := NewStorageHistory(st, hs)
+			impl.now = func() time.Time { return now }
+
+			// act
+			err := impl.Save(c.input.task)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			es, _ := hs.List("1")
+			assert.Equal(t, c.output.es, es)
+			st.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStorageHistory_WithContext(t *testing.T) {
+	// arrange
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	st := NewStorageMock()
+	st.On("Save", mock.Anything).Return(nil)
+	st.Writes = []*write{{after: &Task{ID: optional.Some("1"), Title: optional.Some("title")}}}
+
+	hs := NewHistoryLocal()
+
+	impl := NewStorageHistory(st, hs)
+	impl.now = func() time.Time { return now }
+
+	// act
+	// -> the storage bound to the context still records the changes
+	err := impl.WithContext(context.Background()).Save(&Task{OwnerID: optional.Some("p1"), Title: optional.Some("title")})
+
+	// assert
+	assert.NoError(t, err)
+	es, _ := hs.List("1")
+	assert.Len(t, es, 1)
+	st.AssertExpectations(t)
+}
+
+func TestStorageHistory_WithContextHistory(t *testing.T) {
+	// arrange
+	db, mk, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	st := NewStorageMock()
+	st.On("Save", mock.Anything).Return(nil)
+	st.Writes = []*write{{after: &Task{ID: optional.Some("1"), Title: optional.Some("title")}}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	impl := NewStorageHistory(st, NewHistoryMySQL(db))
+
+	// act
+	// -> the history is bound to the context too: the entry of a canceled request is not sent to the database
+	err = impl.WithContext(ctx).Save(&Task{OwnerID: optional.Some("p1"), Title: optional.Some("title")})
+
+	// assert
+	assert.ErrorIs(t, err, ErrStorageInternal)
+	assert.NoError(t, mk.ExpectationsWereMet())
+	st.AssertExpectations(t)
+}
+
+func TestStorageHistory_Update(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type input struct {task *Task}
+	type output struct {es []*Entry; err error; errMsg string}
+	type testCase struct {
+		title		string
+		input		input
+		output		output
+		setStorage	func(mk *StorageMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "record the changed fields and who changed them",
+			input: input{task: &Task{ID: optional.Some("1"), Title: optional.Some("title"), Status: optional.Some(StatusTodo)}},
+			output: output{es: []*Entry{{
+				TaskID: "1",
+				ProfileID: "p1",
+				Action: ActionUpdate,
+				Changes: []Change{{Field: "status", From: json.RawMessage(`"done"`), To: json.RawMessage(`"todo"`)}},
+				At: now,
+			}}},
+			setStorage: func(mk *StorageMock) {
+				mk.On("Update", "p1", mock.Anything).Return(nil)
+				mk.Writes = []*write{{
+					before: &Task{ID: optional.Some("1"), Title: optional.Some("title"), Status: optional.Some(StatusDone)},
+					after: &Task{ID: optional.Some("1"), Title: optional.Some("title"), Status: optional.Some(StatusTodo), UpdatedAt: optional.Some(now)},
+				}}
+			},
+		},
+		{
+			title: "nothing is recorded without changes",
+			input: input{task: &Task{ID: optional.Some("1"), Title: optional.Some("title")}},
+			output: output{es: []*Entry{}},
+			setStorage: func(mk *StorageMock) {
+				mk.On("Update", "p1", mock.Anything).Return(nil)
+				mk.Writes = []*write{{before: &Task{ID: optional.Some("1"), Title: optional.Some("title")}, after: &Task{ID: optional.Some("1"), Title: optional.Some("title"), Version: optional.Some(2)}}}
+			},
+		},
+
+		// failure cases
+		{
+			title: "nothing is recorded if the task is not updated",
+			input: input{task: &Task{ID: optional.Some("1"), Title: optional.Some("title")}},
+			output: output{es: []*Entry{}, err: ErrStorageForbidden, errMsg: "storage task forbidden"},
+			setStorage: func(mk *StorageMock) {
+				mk.On("Update", "p1", mock.Anything).Return(ErrStorageForbidden)
+				mk.Writes = []*write{{before: &Task{ID: optional.Some("1")}, after: &Task{ID: optional.Some("1"), Title: optional.Some("title")}}}
+			},
+		},
+		{
+			title: "task not found",
+			input: input{task: &Task{ID: optional.Some("1"), Title: optional.Some("title")}},
+			output: output{es: []*Entry{}, err: ErrStorageNotFound, errMsg: "storage task not found"},
+			setStorage: func(mk *StorageMock) {
+				mk.On("Update", "p1", mock.Anything).Return(ErrStorageNotFound)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := NewStorageMock()
+			c.setStorage(st)
+
+			hs := NewHistoryLocal()
+
+			impl := NewStorageHistory(st, hs)
+			impl.now = func() time.Time { return now }
+
+			// act
+			err := impl.Update("p1", c.input.task)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			es, _ := hs.List("1")
+			assert.Equal(t, c.output.es, es)
+			st.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStorageHistory_UpdateCascade(t *testing.T) {
+	// arrange
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	st := NewStorageMock()
+	st.On("Update", "p1", mock.Anything).Return(nil)
+	st.Writes = []*write{
+		{before: &Task{ID: optional.Some("1"), Status: optional.Some(StatusTodo)}, after: &Task{ID: optional.Some("1"), Status: optional.Some(StatusDone)}},
+		{before: &Task{ID: optional.Some("2"), ParentID: optional.Some("1"), Status: optional.Some(StatusTodo)}, after: &Task{ID: optional.Some("2"), ParentID: optional.Some("1"), Status: optional.Some(StatusDone)}},
+	}
+
+	hs := NewHistoryLocal()
+
+	impl := NewStorageHistory(st, hs)
+	impl.now = func() time.Time { return now }
+
+	// act
+	err := impl.Update("p1", &Task{ID: optional.Some("1"), Status: optional.Some(StatusDone)})
+
+	// assert
+	// -> the subtask completed in cascade is recorded as changed by the same profile
+	assert.NoError(t, err)
+	for _, id := range []string{"1", "2"} {
+		es, _ := hs.List(id)
+		assert.Equal(t, []*Entry{{
+			TaskID: id,
+			ProfileID: "p1",
+			Action: ActionUpdate,
+			Changes: []Change{{Field: "status", From: json.RawMessage(`"todo"`), To: json.RawMessage(`"done"`)}},
+			At: now,
+		}}, es)
+	}
+	st.AssertExpectations(t)
+}
+
+func TestStorageHistory_AddLabel(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		title		string
+		output		output
+		setStorage	func(mk *StorageMock)
+		setHistory	func(mk *HistoryMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "record the change of labels",
+			setStorage: func(mk *StorageMock) {
+				mk.On("AddLabel", "p1", "1", "backend", optional.None[int]()).Return(nil)
+				mk.Writes = []*write{{before: &Task{ID: optional.Some("1")}, after: &Task{ID: optional.Some("1"), Labels: []string{"backend"}}}}
+			},
+			setHistory: func(mk *HistoryMock) {
+				mk.On("Record", &Entry{
+					TaskID: "1",
+					ProfileID: "p1",
+					Action: ActionUpdate,
+					Changes: []Change{{Field: "labels", From: json.RawMessage(`[]`), To: json.RawMessage(`["backend"]`)}},
+					At: now,
+				}).Return(nil)
+			},
+		},
+
+		// failure cases
+		{
+			title: "history error",
+			output: output{err: ErrStorageInternal, errMsg: "storage internal error: history: storage internal error"},
+			setStorage: func(mk *StorageMock) {
+				mk.On("AddLabel", "p1", "1", "backend", optional.None[int]()).Return(nil)
+				mk.Writes = []*write{{before: &Task{ID: optional.Some("1")}, after: &Task{ID: optional.Some("1"), Labels: []string{"backend"}}}}
+			},
+			setHistory: func(mk *HistoryMock) {
+				mk.On("Record", mock.Anything).Return(ErrStorageInternal)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := NewStorageMock()
+			c.setStorage(st)
+
+			hs := NewHistoryMock()
+			c.setHistory(hs)
+
+			impl := NewStorageHistory(st, hs)
+			impl.now = func() time.Time { return now }
+
+			// act
+			err := impl.AddLabel("p1", "1", "backend", optional.None[int]())
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			st.AssertExpectations(t)
+			hs.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStorageHistory_CheckItem(t *testing.T) {
+	// arrange
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	st := NewStorageMock()
+	st.On("CheckItem", "p1", "1", "a", true, optional.None[int]()).Return(nil)
+	st.Writes = []*write{{
+		before: &Task{ID: optional.Some("1"), Checklist: []Item{{ID: "a", Text: "milk"}}},
+		after: &Task{ID: optional.Some("1"), Checklist: []Item{{ID: "a", Text: "milk", Checked: true}}},
+	}}
+
+	hs := NewHistoryMock()
+	hs.On("Record", &Entry{
+		TaskID: "1",
+		ProfileID: "p1",
+		Action: ActionUpdate,
+		Changes: []Change{{
+			Field: "checklist",
+			From: json.RawMessage(`[{"id":"a","text":"milk","checked":false}]`),
+			To: json.RawMessage(`[{"id":"a","text":"milk","checked":true}]`),
+		}},
+		At: now,
+	}).Return(nil)
+
+	impl := NewStorageHistory(st, hs)
+	impl.now = func() time.Time { return now }
+
+	// act
+	err := impl.CheckItem("p1", "1", "a", true, optional.None[int]())
+
+	// assert
+	assert.NoError(t, err)
+	st.AssertExpectations(t)
+	hs.AssertExpectations(t)
+}
+
+func TestStorageHistory_Batch(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type input struct {ops []*Op; mode Mode}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		title		string
+		input		input
+		output		output
+		setStorage	func(mk *StorageMock)
+		setHistory	func(mk *HistoryMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "record each operation of an atomic batch with its own changes",
+			input: input{
+				ops: []*Op{
+					{Kind: OpCreate, Task: &Task{Title: optional.Some("title")}},
+					{Kind: OpUpdate, Task: &Task{ID: optional.Some("1"), Title: optional.Some("new title")}},
+					{Kind: OpDelete, ID: "3"},
+				},
+				mode: ModeAtomic,
+			},
+			setStorage: func(mk *StorageMock) {
+				mk.On("Batch", "p1", mock.Anything, ModeAtomic).Return([]*Result{
+					{Task: &Task{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Title: optional.Some("title")}},
+					{Task: &Task{ID: optional.Some("1"), Title: optional.Some("new title")}},
+					{},
+				}, nil)
+				mk.Writes = []*write{
+					{after: &Task{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Title: optional.Some("title")}},
+					{before: &Task{ID: optional.Some("1"), Title: optional.Some("title")}, after: &Task{ID: optional.Some("1"), Title: optional.Some("new title")}},
+				}
+			},
+			setHistory: func(mk *HistoryMock) {
+				mk.On("Record", &Entry{
+					TaskID: "2",
+					ProfileID: "p1",
+					Action: ActionCreate,
+					Changes: []Change{{Field: "title", From: json.RawMessage(`null`), To: json.RawMessage(`"title"`)}},
+					At: now,
+				}).Return(nil)
+				mk.On("Record", &Entry{
+					TaskID: "1",
+					ProfileID: "p1",
+					Action: ActionUpdate,
+					Changes: []Change{{Field: "title", From: json.RawMessage(`"title"`), To: json.RawMessage(`"new title"`)}},
+					At: now,
+				}).Return(nil)
+			},
+		},
+
+		// failure cases
+		{
+			title: "nothing is recorded if the atomic batch is aborted",
+			input: input{
+				ops: []*Op{{Kind: OpCreate, Task: &Task{Title: optional.Some("title")}}},
+				mode: ModeAtomic,
+			},
+			output: output{err: ErrStorageBatchAborted, errMsg: "storage batch aborted"},
+			setStorage: func(mk *StorageMock) {
+				mk.On("Batch", "p1", mock.Anything, ModeAtomic).Return([]*Result{{Err: ErrStorageInvalid}}, ErrStorageBatchAborted)
+				mk.Writes = []*write{{after: &Task{ID: optional.Some("2"), Title: optional.Some("title")}}}
+			},
+			setHistory: func(mk *HistoryMock) {},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := NewStorageMock()
+			c.setStorage(st)
+
+			hs := NewHistoryMock()
+			c.setHistory(hs)
+
+			impl := NewStorageHistory(st, hs)
+			impl.now = func() time.Time { return now }
+
+			// act
+			_, err := impl.Batch("p1", c.input.ops, c.input.mode)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			st.AssertExpectations(t)
+			hs.AssertExpectations(t)
+		})
+	}
+}
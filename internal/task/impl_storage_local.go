@@ -1,7 +1,15 @@
 package task
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"api/internal/project"
 
 	"github.com/LNMMusic/optional"
 
@@ -9,31 +17,564 @@ import (
 )
 
 // constructor
-func NewStorageLocal(db []*Task, vl Validator) *StorageLocal {
-	return &StorageLocal{db: db, vl: vl}
+// - cfg is optional (nil for the default config)
+func NewStorageLocal(db []*Task, vl Validator, cfg *Config) *StorageLocal {
+	s := &StorageLocal{state: state{db: db, deps: make(map[string][]string), grants: make(map[string][]*Grant)}, vl: vl, cfg: newConfig(cfg), now: time.Now, newId: newId}
+	s.reindex()
+	return s
 }
 
 
 // StorageLocal is the local implementation of the task storage.
+// - it is safe for concurrent use: changes hold the lock of the storage, one at a time, and reads share it, so readers
+// only wait for the change in progress (a change costs as much as the tasks it touches, not all of them)
+// - a change that fails is undone: the tasks it touched are put back as they were before it
+// - the tasks it returns are copies, so callers can change them
 type StorageLocal struct {
-	db []*Task
-	vl Validator
+	// state are the tasks of the storage
+	state
+	// mu guards the tasks: changes hold it, reads share it
+	mu  sync.RWMutex
+	vl  Validator
+	cfg *Config
+	// now returns the current time
+	now func() time.Time
+	// newId returns the id of a new task
+	newId func() string
+	// undo are the tasks as they were before the change in progress touched them, in the order they were touched (see touch)
+	undo []*image
+	// touched are the ids of the tasks in undo (nil out of a change)
+	touched map[string]bool
+	// commit is called with the tasks a change touched, before the lock is released (nil if there is nothing to call)
+	// - if it fails, the change is undone and fails with its error (see StorageWAL)
+	commit func(ims []*image) (err error)
+	// rec is handed the writes of each change made on behalf of a profile, before it is committed (nil if they are not recorded)
+	// - if it fails, the change is undone and fails with its error (see StorageHistory)
+	rec recorder
+	// by is the profile the change in progress is made on behalf of ("" for the changes of the storage itself)
+	by string
+	// writes are the writes of the change in progress, for rec (see step)
+	writes []*write
+	// mark is the position in undo where the operation in progress started
+	mark int
+}
+
+// state are the tasks of the storage, with their index, dependencies and grants.
+type state struct {
+	db  []*Task
+	// ids are the positions of the tasks in db, by task id
+	ids map[string]int
+	// deps are the ids of the blockers of each task, by task id (sorted)
+	deps map[string][]string
+	// grants are the grants of each task, by task id (sorted by profile id)
+	grants map[string][]*Grant
+}
+
+// newId returns a random id.
+func newId() string {
+	return uuid.New().String()
+}
+
+// reindex rebuilds the index of the tasks, after they are replaced or removed.
+func (s *state) reindex() {
+	s.ids = make(map[string]int, len(s.db))
+	for i, t := range s.db {
+		id, _ := t.ID.Unwrap()
+		s.ids[id] = i
+	}
+}
+
+// image is the state of a task before a change touched it, to undo the change.
+type image struct {
+	id 	   string
+	// task is a copy of the task (nil if the change created it)
+	task   *Task
+	// at is the position of the task
+	at 	   int
+	deps   []string
+	grants []*Grant
+}
+
+// insert appends the task to the tasks, and indexes it.
+func (s *StorageLocal) insert(task *Task) {
+	id, _ := task.ID.Unwrap()
+	s.touch(id)
+	s.ids[id] = len(s.db)
+	s.db = append(s.db, task)
+}
+
+// touch keeps the state of the task with the given id (its dependencies and grants included) before the change in progress changes it.
+// - it must be called before the task is changed; only the first call of a change keeps a state
+func (s *StorageLocal) touch(id string) {
+	if s.touched == nil || s.touched[id] {
+		return
+	}
+	s.touched[id] = true
+
+	im := &image{id: id, deps: append([]string(nil), s.deps[id]...), grants: copyGrants(s.grants[id])}
+	if i, ok := s.ids[id]; ok {
+		im.task, im.at = clone(s.db[i]), i
+	}
+	s.undo = append(s.undo, im)
 }
 
-func (s *StorageLocal) Get(id string) (ts *Task, err error) {
+// rollback puts the tasks back as they were in the given images.
+func (s *StorageLocal) rollback(ims []*image) {
+	// the tasks the change removed, put back where they were
+	var removed []*image
+	created := false
+	for k := len(ims) - 1; k >= 0; k-- {
+		im := ims[k]
+		delete(s.deps, im.id)
+		if len(im.deps) > 0 {
+			s.deps[im.id] = im.deps
+		}
+		delete(s.grants, im.id)
+		if len(im.grants) > 0 {
+			s.grants[im.id] = im.grants
+		}
+
+		i, ok := s.ids[im.id]
+		switch {
+			case im.task == nil && ok:
+				s.db[i] = nil
+				created = true
+			case im.task == nil:
+			case ok:
+				s.db[i] = im.task
+			default:
+				removed = append(removed, im)
+		}
+	}
+	if !created && len(removed) == 0 {
+		return
+	}
+
+	db := make([]*Task, 0, len(s.db)+len(removed))
 	for _, t := range s.db {
-		tId, _ := t.ID.Unwrap()
-		if tId == id {
-			ts = t
-			return
+		if t != nil {
+			db = append(db, t)
+		}
+	}
+	sort.Slice(removed, func(i, j int) bool { return removed[i].at < removed[j].at })
+	for _, im := range removed {
+		at := im.at
+		if at > len(db) {
+			at = len(db)
 		}
+		db = append(db[:at], append([]*Task{im.task}, db[at:]...)...)
+	}
+	s.db = db
+	s.reindex()
+}
+
+// copyGrants returns a copy of the grants.
+func copyGrants(gs []*Grant) (cp []*Grant) {
+	for _, g := range gs {
+		cp = append(cp, &Grant{ProfileID: g.ProfileID, Permission: g.Permission})
+	}
+	return
+}
+
+// index returns the position of the task with the given id, owned by the given profile
+// - deleted: whether the task must be in the trash or not
+func (s *state) index(profileId string, id string, deleted bool) (i int, err error) {
+	i, ok := s.ids[id]
+	if ok && owned(s.db[i], profileId) && s.db[i].DeletedAt.IsSome() == deleted {
+		return
 	}
 
 	err = fmt.Errorf("%w: %v", ErrStorageNotFound, id)
 	return
 }
 
+// authorize returns the position of the task with the given id (not in the trash), that the profile can access with the wanted permission.
+func (s *state) authorize(profileId string, id string, want Permission) (i int, err error) {
+	i, ok := s.ids[id]
+	if !ok || s.db[i].DeletedAt.IsSome() {
+		err = fmt.Errorf("%w: %v", ErrStorageNotFound, id)
+		return
+	}
+
+	ownerId, _ := s.db[i].OwnerID.Unwrap()
+	switch p := granted(profileId, ownerId, s.permission(id, profileId)); {
+	case p == "":
+		err = fmt.Errorf("%w: %v", ErrStorageNotFound, id)
+	case !p.Allows(want):
+		err = fmt.Errorf("%w: %v %s", ErrStorageForbidden, id, want)
+	}
+	return
+}
+
+// permission returns the permission granted to the profile on the task with the given id (empty if it is not shared with it).
+func (s *state) permission(id string, profileId string) Permission {
+	for _, g := range s.grants[id] {
+		if g.ProfileID == profileId {
+			return g.Permission
+		}
+	}
+	return ""
+}
+
+// owned returns whether the task is owned by the given profile.
+func owned(t *Task, profileId string) bool {
+	ownerId, e := t.OwnerID.Unwrap()
+	return e == nil && ownerId == profileId
+}
+
+// lookup returns the task with the given id, in the trash or not (nil if it does not exist)
+func (s *state) lookup(id string) *Task {
+	if i, ok := s.ids[id]; ok {
+		return s.db[i]
+	}
+	return nil
+}
+
+// children returns the subtasks of the task with the given id (not in the trash), sorted by id.
+func (s *state) children(id string) (ts []*Task) {
+	for _, t := range s.db {
+		if parentId, e := t.ParentID.Unwrap(); e == nil && parentId == id && !t.DeletedAt.IsSome() {
+			ts = append(ts, t)
+		}
+	}
+	sort.Slice(ts, func(i, j int) bool {
+		return order(ts[i], nil, ts[j], Sort{}) < 0
+	})
+	return
+}
+
+// checkParent checks the parent of the task exists (with the same owner) and it is not the task itself or one of its subtasks.
+func (s *StorageLocal) checkParent(task *Task) (err error) {
+	parentId, e := task.ParentID.Unwrap()
+	if e != nil {
+		return
+	}
+	id, _ := task.ID.Unwrap()
+	ownerId, _ := task.OwnerID.Unwrap()
+
+	parent := s.lookup(parentId)
+	if parent == nil || !owned(parent, ownerId) {
+		err = fmt.Errorf("%w: parent %v not found", ErrStorageInvalid, parentId)
+		return
+	}
+
+	// walk up the ancestors
+	for ancestor := parent; ancestor != nil; {
+		ancestorId, _ := ancestor.ID.Unwrap()
+		if ancestorId == id {
+			err = fmt.Errorf("%w: %v", ErrStorageCycle, id)
+			return
+		}
+
+		ancestorParentId, e := ancestor.ParentID.Unwrap()
+		if e != nil {
+			break
+		}
+		ancestor = s.lookup(ancestorParentId)
+	}
+
+	return
+}
+
+// checkProject checks the project of the task exists (with the same owner), if the projects are configured.
+func (s *StorageLocal) checkProject(task *Task) (err error) {
+	projectId, e := task.ProjectID.Unwrap()
+	if e != nil || s.cfg.Projects == nil {
+		return
+	}
+	ownerId, _ := task.OwnerID.Unwrap()
+
+	_, e = s.cfg.Projects.Get(ownerId, projectId)
+	if e != nil {
+		if errors.Is(e, project.ErrStorageNotFound) {
+			err = fmt.Errorf("%w: project %v not found", ErrStorageInvalid, projectId)
+			return
+		}
+		err = fmt.Errorf("%w: project %v", ErrStorageInternal, projectId)
+		return
+	}
+
+	return
+}
+
+// complete applies the hierarchy rule to the subtasks of the given task, when it is completed (moved to done).
+// - it must be called before the task is replaced, to leave the storage as it is on failure
+func (s *StorageLocal) complete(task *Task) (err error) {
+	if status, _ := task.Status.Unwrap(); status != StatusDone {
+		return
+	}
+	id, _ := task.ID.Unwrap()
+
+	switch s.cfg.Hierarchy {
+	case HierarchyRestrict:
+		for _, child := range s.children(id) {
+			if status, _ := child.Status.Unwrap(); !status.Closed() {
+				childId, _ := child.ID.Unwrap()
+				err = fmt.Errorf("%w: %v", ErrStorageOpenChildren, childId)
+				return
+			}
+		}
+	case HierarchyCascade:
+		s.cascade(id, s.now())
+	}
+
+	return
+}
+
+// cascade moves the open subtasks of the task with the given id to done, down the whole subtree.
+func (s *StorageLocal) cascade(id string, now time.Time) {
+	for _, child := range s.children(id) {
+		childId, _ := child.ID.Unwrap()
+		if status, _ := child.Status.Unwrap(); !status.Closed() {
+			s.touch(childId)
+			child.Status = optional.Some(StatusDone)
+			child.UpdatedAt = optional.Some(now)
+			version, _ := child.Version.Unwrap()
+			child.Version = optional.Some(version + 1)
+		}
+		s.cascade(childId, now)
+	}
+}
+
+// checkTransition checks the workflow allows the change of status from the stored task to the given one.
+func (s *StorageLocal) checkTransition(stored *Task, task *Task) (err error) {
+	from, e := stored.Status.Unwrap()
+	if e != nil {
+		return
+	}
+	to, _ := task.Status.Unwrap()
+	if from == to {
+		return
+	}
+
+	err = s.vl.Transition(from, to)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrStorageTransition, err)
+		return
+	}
+	return
+}
+
+// checkBlockers checks the task has no open blockers (not in the trash), when it is completed (moved to done).
+func (s *StorageLocal) checkBlockers(task *Task) (err error) {
+	if status, _ := task.Status.Unwrap(); status != StatusDone {
+		return
+	}
+	id, _ := task.ID.Unwrap()
+
+	for _, blockerId := range s.deps[id] {
+		blocker := s.lookup(blockerId)
+		if blocker == nil || blocker.DeletedAt.IsSome() {
+			continue
+		}
+		if status, _ := blocker.Status.Unwrap(); !status.Closed() {
+			err = fmt.Errorf("%w: %v", ErrStorageOpenBlockers, blockerId)
+			return
+		}
+	}
+	return
+}
+
+// blockedBy returns whether the task with the given id is blocked by the task with the blocker id, directly or not.
+func (s *StorageLocal) blockedBy(id string, blockerId string) bool {
+	visited := make(map[string]bool)
+	pending := []string{id}
+	for len(pending) > 0 {
+		id, pending = pending[len(pending)-1], pending[:len(pending)-1]
+		for _, b := range s.deps[id] {
+			if b == blockerId {
+				return true
+			}
+			if !visited[b] {
+				visited[b] = true
+				pending = append(pending, b)
+			}
+		}
+	}
+	return false
+}
+
+// WithContext returns the storage itself: its methods run in memory, there is nothing to cancel.
+func (s *StorageLocal) WithContext(ctx context.Context) (st Storage) {
+	st = s
+	return
+}
+
+func (s *StorageLocal) Get(profileId string, id string) (ts *Task, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ts, err = s.get(profileId, id)
+	return
+}
+
+// get returns a copy of the task with the given id, that the profile can read.
+func (s *state) get(profileId string, id string) (ts *Task, err error) {
+	var i int
+	i, err = s.authorize(profileId, id, PermissionRead)
+	if err != nil {
+		return
+	}
+
+	// copy: changes to the task must go through Update
+	ts = clone(s.db[i])
+	return
+}
+
+// clone returns a copy of the task that does not share its labels or its checklist with it.
+func clone(t *Task) *Task {
+	cp := *t
+	if cp.Labels != nil {
+		cp.Labels = append(make([]string, 0, len(cp.Labels)), cp.Labels...)
+	}
+	if cp.Checklist != nil {
+		cp.Checklist = append(make([]Item, 0, len(cp.Checklist)), cp.Checklist...)
+	}
+	return &cp
+}
+
+func (s *StorageLocal) List(profileId string, query *Query) (pg *Page, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pg, err = s.list(query, func(t *Task) bool {
+		return owned(t, profileId)
+	})
+	return
+}
+
+func (s *StorageLocal) Shared(profileId string, query *Query) (pg *Page, err error) {
+	if query.Deleted {
+		err = fmt.Errorf("%w: trash not shared", ErrStorageInvalidQuery)
+		return
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pg, err = s.list(query, func(t *Task) bool {
+		id, _ := t.ID.Unwrap()
+		return s.permission(id, profileId) != ""
+	})
+	return
+}
+
+// list returns the page of tasks in scope that matches the given query (the lock must be held).
+func (s *StorageLocal) list(query *Query, scope func(t *Task) bool) (pg *Page, err error) {
+	// query
+	var size int
+	size, err = checkQuery(query)
+	if err != nil {
+		return
+	}
+	var c *cursor
+	c, err = decodeCursor(query.Cursor, query.Sort)
+	if err != nil {
+		return
+	}
+
+	// filter tasks (copies, the caller owns the page)
+	ts := make([]*Task, 0, len(s.db))
+	for _, t := range s.db {
+		if scope(t) && t.DeletedAt.IsSome() == query.Deleted && match(t, query.Filter) {
+			ts = append(ts, clone(t))
+		}
+	}
+
+	// sort tasks (same order as the cursor)
+	sort.Slice(ts, func(i, j int) bool {
+		return order(ts[i], value(ts[j], query.Sort.Field), ts[j], query.Sort) < 0
+	})
+	if c != nil {
+		after := sort.Search(len(ts), func(i int) bool {
+			return order(ts[i], c.Key, &Task{ID: optional.Some(c.ID)}, query.Sort) > 0
+		})
+		ts = ts[after:]
+	}
+
+	// page
+	pg = &Page{Tasks: ts, Next: optional.None[string]()}
+	if len(ts) > size {
+		pg.Tasks = ts[:size]
+		pg.Next = optional.Some(encodeCursor(ts[size-1], query.Sort))
+	}
+
+	return
+}
+
+// match evaluates the filter over the given task.
+func match(t *Task, f Filter) bool {
+	switch f := f.(type) {
+	case And:
+		for _, sub := range f.Filters {
+			if !match(t, sub) {
+				return false
+			}
+		}
+	case Condition:
+		v := value(t, f.Field)
+		switch f.Operator {
+		case OperatorEq:
+			if labels, ok := v.([]string); ok {
+				return hasLabel(labels, f.Value.(string))
+			}
+			return v != nil && compare(v, f.Value) == 0
+		case OperatorNe:
+			return v != nil && compare(v, f.Value) != 0
+		case OperatorContains:
+			s, _ := v.(string)
+			return v != nil && strings.Contains(strings.ToLower(s), strings.ToLower(f.Value.(string)))
+		case OperatorLt:
+			return v != nil && compare(v, f.Value) < 0
+		case OperatorLte:
+			return v != nil && compare(v, f.Value) <= 0
+		case OperatorGt:
+			return v != nil && compare(v, f.Value) > 0
+		case OperatorGte:
+			return v != nil && compare(v, f.Value) >= 0
+		}
+		return false
+	}
+	return true
+}
+
+// hasLabel returns whether the labels have the given label.
+func hasLabel(labels []string, label string) bool {
+	for _, l := range labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// order compares the task with the position given by a key and an id, based on the sort.
+func order(t *Task, key any, ref *Task, s Sort) (cmp int) {
+	if s.Field != "" {
+		cmp = compare(value(t, s.Field), key)
+		if s.Desc {
+			cmp = -cmp
+		}
+	}
+	if cmp == 0 {
+		tId, _ := t.ID.Unwrap()
+		refId, _ := ref.ID.Unwrap()
+		cmp = strings.Compare(tId, refId)
+	}
+	return
+}
+
 func (s *StorageLocal) Save(task *Task) (err error) {
+	ownerId, _ := task.OwnerID.Unwrap()
+	s.lock(ownerId)
+	defer s.unlock(&err)
+
+	err = s.save(task)
+	return
+}
+
+// save stores the task, with the lock held.
+func (s *StorageLocal) save(task *Task) (err error) {
 	// validate task
 	err = s.vl.Validate(task)
 	if err != nil {
@@ -41,12 +582,828 @@ func (s *StorageLocal) Save(task *Task) (err error) {
 		return
 	}
 
-	// generate id
-	id := uuid.New().String()
-	task.ID = optional.Some(id)
+	// check owner, parent and project
+	if !task.OwnerID.IsSome() {
+		err = fmt.Errorf("%w: owner required", ErrStorageInvalid)
+		return
+	}
+	err = s.checkParent(task)
+	if err != nil {
+		return
+	}
+	err = s.checkProject(task)
+	if err != nil {
+		return
+	}
 
-	// save task
-	s.db = append(s.db, task)
+	// generate id and timestamps
+	task.ID = optional.Some(s.newId())
+	now := s.now()
+	task.CreatedAt = optional.Some(now)
+	task.UpdatedAt = optional.Some(now)
+	task.Version = optional.Some(1)
+	ownerId, _ := task.OwnerID.Unwrap()
+	task.Rank = optional.Some(rankAfter(s.lastRank(ownerId)))
+	sort.Strings(task.Labels)
+	joinSeries(task, optional.None[string](), optional.None[int]())
+
+	// save task (a copy: the caller keeps its own)
+	s.insert(clone(task))
+	return
+}
+
+func (s *StorageLocal) Update(profileId string, task *Task) (err error) {
+	s.lock(profileId)
+	defer s.unlock(&err)
+
+	err = s.update(profileId, task)
+	return
+}
+
+// update replaces the task, with the lock held.
+func (s *StorageLocal) update(profileId string, task *Task) (err error) {
+	// validate task
+	err = s.vl.Validate(task)
+	if err != nil {
+		err  = fmt.Errorf("%w: %v", ErrStorageInvalid, err)
+		return
+	}
+
+	// update task
+	id, _ := task.ID.Unwrap()
+	var i int
+	i, err = s.authorize(profileId, id, PermissionEdit)
+	if err != nil {
+		return
+	}
+	task.OwnerID = s.db[i].OwnerID
+
+	// check version
+	err = s.checkVersion(i, task.Version)
+	if err != nil {
+		return
+	}
+	version, _ := s.db[i].Version.Unwrap()
+
+	// check transition, parent, project, blockers and subtasks
+	err = s.checkTransition(s.db[i], task)
+	if err != nil {
+		return
+	}
+	err = s.checkParent(task)
+	if err != nil {
+		return
+	}
+	err = s.checkProject(task)
+	if err != nil {
+		return
+	}
+	err = s.checkBlockers(task)
+	if err != nil {
+		return
+	}
+	err = s.complete(task)
+	if err != nil {
+		return
+	}
+
+	// next occurrence of a recurring task that is completed
+	stored := s.db[i]
+	joinSeries(task, stored.SeriesID, stored.Occurrence)
+	var next *Task
+	if completes(stored.Status, task.Status) {
+		next, _, err = nextOccurrence(task)
+		if err != nil {
+			return
+		}
+	}
+
+	now := s.now()
+	task.CreatedAt = stored.CreatedAt
+	task.UpdatedAt = optional.Some(now)
+	task.Version = optional.Some(version + 1)
+	task.Rank = stored.Rank
+	task.Checklist = stored.Checklist
+	sort.Strings(task.Labels)
+	s.touch(id)
+	s.db[i] = clone(task)
+	if next != nil {
+		ownerId, _ := task.OwnerID.Unwrap()
+		next.ID = optional.Some(s.newId())
+		next.CreatedAt = optional.Some(now)
+		next.UpdatedAt = optional.Some(now)
+		next.Version = optional.Some(1)
+		next.Rank = optional.Some(rankAfter(s.lastRank(ownerId)))
+		s.insert(next)
+	}
+	return
+}
+
+func (s *StorageLocal) Delete(profileId string, id string, version optional.Option[int]) (err error) {
+	s.lock(profileId)
+	defer s.unlock(&err)
+
+	err = s.delete(profileId, id, version)
 	return
 }
-	
\ No newline at end of file
+
+// delete moves the task to the trash, with the lock held.
+func (s *StorageLocal) delete(profileId string, id string, version optional.Option[int]) (err error) {
+	var i int
+	i, err = s.index(profileId, id, false)
+	if err != nil {
+		return
+	}
+	err = s.checkVersion(i, version)
+	if err != nil {
+		return
+	}
+
+	// move task to the trash
+	s.touch(id)
+	s.db[i].DeletedAt = optional.Some(s.now())
+	return
+}
+
+func (s *StorageLocal) Restore(profileId string, id string, version optional.Option[int]) (err error) {
+	s.lock(profileId)
+	defer s.unlock(&err)
+
+	var i int
+	i, err = s.index(profileId, id, true)
+	if err != nil {
+		return
+	}
+	err = s.checkVersion(i, version)
+	if err != nil {
+		return
+	}
+
+	// move task out of the trash
+	s.touch(id)
+	s.db[i].DeletedAt = optional.None[time.Time]()
+	return
+}
+
+func (s *StorageLocal) Purge(before time.Time) (n int, err error) {
+	s.lock("")
+	defer s.unlock(&err)
+
+	db := make([]*Task, 0, len(s.db))
+	purged := make(map[string]bool)
+	for _, t := range s.db {
+		deletedAt, e := t.DeletedAt.Unwrap()
+		if e == nil && deletedAt.Before(before) {
+			id, _ := t.ID.Unwrap()
+			purged[id] = true
+			s.touch(id)
+			n++
+			continue
+		}
+		db = append(db, t)
+	}
+
+	// subtasks of the purged tasks become top level tasks
+	for _, t := range db {
+		if parentId, e := t.ParentID.Unwrap(); e == nil && purged[parentId] {
+			id, _ := t.ID.Unwrap()
+			s.touch(id)
+			t.ParentID = optional.None[string]()
+		}
+	}
+
+	// grants of the purged tasks are removed
+	for id := range purged {
+		delete(s.grants, id)
+	}
+
+	// dependencies on the purged tasks are removed
+	for id, blockers := range s.deps {
+		if purged[id] {
+			delete(s.deps, id)
+			continue
+		}
+		var kept []string
+		for _, b := range blockers {
+			if !purged[b] {
+				kept = append(kept, b)
+			}
+		}
+		if len(kept) < len(blockers) {
+			s.touch(id)
+			s.deps[id] = kept
+		}
+	}
+
+	s.db = db
+	s.reindex()
+	return
+}
+
+func (s *StorageLocal) Tree(profileId string, id string) (nd *Node, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var ts *Task
+	ts, err = s.get(profileId, id)
+	if err != nil {
+		return
+	}
+
+	nd = s.node(ts)
+	return
+}
+
+// node returns the node of the given task, with its subtasks.
+func (s *state) node(ts *Task) (nd *Node) {
+	id, _ := ts.ID.Unwrap()
+	nd = &Node{Task: ts, Children: []*Node{}}
+	for _, child := range s.children(id) {
+		nd.Children = append(nd.Children, s.node(clone(child)))
+	}
+	return
+}
+
+func (s *StorageLocal) AddLabel(profileId string, id string, label string, version optional.Option[int]) (err error) {
+	s.lock(profileId)
+	defer s.unlock(&err)
+
+	var i int
+	i, err = s.authorize(profileId, id, PermissionEdit)
+	if err != nil {
+		return
+	}
+	err = s.checkVersion(i, version)
+	if err != nil {
+		return
+	}
+	if hasLabel(s.db[i].Labels, label) {
+		return
+	}
+
+	// validate the task with the label
+	ts := *s.db[i]
+	ts.Labels = append(append(make([]string, 0, len(ts.Labels)+1), ts.Labels...), label)
+	sort.Strings(ts.Labels)
+	err = s.vl.Validate(&ts)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrStorageInvalid, err)
+		return
+	}
+
+	s.touch(id)
+	s.db[i].Labels = ts.Labels
+	s.bump(i)
+	return
+}
+
+func (s *StorageLocal) RemoveLabel(profileId string, id string, label string, version optional.Option[int]) (err error) {
+	s.lock(profileId)
+	defer s.unlock(&err)
+
+	var i int
+	i, err = s.authorize(profileId, id, PermissionEdit)
+	if err != nil {
+		return
+	}
+	err = s.checkVersion(i, version)
+	if err != nil {
+		return
+	}
+	if !hasLabel(s.db[i].Labels, label) {
+		err = fmt.Errorf("%w: %v label %v", ErrStorageNotFound, id, label)
+		return
+	}
+
+	labels := make([]string, 0, len(s.db[i].Labels)-1)
+	for _, l := range s.db[i].Labels {
+		if l != label {
+			labels = append(labels, l)
+		}
+	}
+	s.touch(id)
+	s.db[i].Labels = labels
+	s.bump(i)
+	return
+}
+
+// checkVersion checks the task at the given position still has the given version (any version if None).
+func (s *StorageLocal) checkVersion(i int, version optional.Option[int]) (err error) {
+	stored, _ := s.db[i].Version.Unwrap()
+	if v, e := version.Unwrap(); e == nil && v != stored {
+		id, _ := s.db[i].ID.Unwrap()
+		err = fmt.Errorf("%w: %v %d", ErrStorageVersionMismatch, id, v)
+		return
+	}
+	return
+}
+
+// bump increases the version of the task at the given position (it must be touched already).
+func (s *StorageLocal) bump(i int) {
+	version, _ := s.db[i].Version.Unwrap()
+	s.db[i].Version = optional.Some(version + 1)
+}
+
+func (s *StorageLocal) Labels(profileId string) (ls []*Label, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// count the tasks of each label
+	counts := make(map[string]int)
+	for _, t := range s.db {
+		if !owned(t, profileId) || t.DeletedAt.IsSome() {
+			continue
+		}
+		for _, label := range t.Labels {
+			counts[label]++
+		}
+	}
+
+	ls = make([]*Label, 0, len(counts))
+	for name, n := range counts {
+		ls = append(ls, &Label{Name: name, Tasks: n})
+	}
+	sort.Slice(ls, func(i, j int) bool { return ls[i].Name < ls[j].Name })
+	return
+}
+
+func (s *StorageLocal) AddDependency(profileId string, id string, blockerId string) (err error) {
+	s.lock(profileId)
+	defer s.unlock(&err)
+
+	_, err = s.index(profileId, id, false)
+	if err != nil {
+		return
+	}
+	_, err = s.index(profileId, blockerId, false)
+	if err != nil {
+		return
+	}
+	for _, b := range s.deps[id] {
+		if b == blockerId {
+			return
+		}
+	}
+
+	// the blocker can not be the task or be blocked by it
+	if id == blockerId || s.blockedBy(blockerId, id) {
+		err = fmt.Errorf("%w: %v blocked by %v", ErrStorageCycle, id, blockerId)
+		return
+	}
+
+	s.touch(id)
+	s.deps[id] = append(s.deps[id], blockerId)
+	sort.Strings(s.deps[id])
+	return
+}
+
+func (s *StorageLocal) RemoveDependency(profileId string, id string, blockerId string) (err error) {
+	s.lock(profileId)
+	defer s.unlock(&err)
+
+	_, err = s.index(profileId, id, false)
+	if err != nil {
+		return
+	}
+
+	for i, b := range s.deps[id] {
+		if b == blockerId {
+			s.touch(id)
+			s.deps[id] = append(s.deps[id][:i], s.deps[id][i+1:]...)
+			return
+		}
+	}
+
+	err = fmt.Errorf("%w: %v blocker %v", ErrStorageNotFound, id, blockerId)
+	return
+}
+
+func (s *StorageLocal) Order(profileId string, ids []string) (ts []*Task, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, id := range ids {
+		_, err = s.index(profileId, id, false)
+		if err != nil {
+			return
+		}
+	}
+
+	var order []string
+	order, err = topological(ids, s.deps)
+	if err != nil {
+		return
+	}
+
+	ts = make([]*Task, 0, len(order))
+	for _, id := range order {
+		i, _ := s.index(profileId, id, false)
+		ts = append(ts, clone(s.db[i]))
+	}
+	return
+}
+
+func (s *StorageLocal) Grant(profileId string, id string, grant *Grant) (err error) {
+	s.lock(profileId)
+	defer s.unlock(&err)
+
+	_, err = s.index(profileId, id, false)
+	if err != nil {
+		return
+	}
+	err = checkGrant(profileId, grant)
+	if err != nil {
+		return
+	}
+
+	// replace the permission of the profile, if it already has one
+	for _, g := range s.grants[id] {
+		if g.ProfileID == grant.ProfileID {
+			s.touch(id)
+			g.Permission = grant.Permission
+			return
+		}
+	}
+	s.touch(id)
+	s.grants[id] = append(s.grants[id], &Grant{ProfileID: grant.ProfileID, Permission: grant.Permission})
+	sort.Slice(s.grants[id], func(i, j int) bool { return s.grants[id][i].ProfileID < s.grants[id][j].ProfileID })
+	return
+}
+
+func (s *StorageLocal) Revoke(profileId string, id string, granteeId string) (err error) {
+	s.lock(profileId)
+	defer s.unlock(&err)
+
+	_, err = s.index(profileId, id, false)
+	if err != nil {
+		return
+	}
+
+	for i, g := range s.grants[id] {
+		if g.ProfileID == granteeId {
+			s.touch(id)
+			s.grants[id] = append(s.grants[id][:i], s.grants[id][i+1:]...)
+			return
+		}
+	}
+
+	err = fmt.Errorf("%w: %v grant %v", ErrStorageNotFound, id, granteeId)
+	return
+}
+
+func (s *StorageLocal) Grants(profileId string, id string) (gs []*Grant, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, err = s.index(profileId, id, false)
+	if err != nil {
+		return
+	}
+
+	gs = make([]*Grant, 0, len(s.grants[id]))
+	gs = append(gs, copyGrants(s.grants[id])...)
+	return
+}
+
+func (s *StorageLocal) Batch(profileId string, ops []*Op, mode Mode) (rs []*Result, err error) {
+	err = checkBatch(ops, mode)
+	if err != nil {
+		return
+	}
+	if mode == ModeBestEffort {
+		rs = runBatch(s, profileId, ops)
+		return
+	}
+
+	// the lock is held for the whole batch: if an operation fails, the batch fails and is undone as a single change
+	s.lock(profileId)
+	defer s.unlock(&err)
+
+	rs, err = runAtomic(locked{s}, profileId, ops)
+	return
+}
+
+// locked is the storage the operations of an atomic batch run on, that already holds the lock.
+// - only the operations of a batch (Save, Update and Delete) can be called on it
+// - each operation hands its own writes (see step)
+type locked struct {
+	*StorageLocal
+}
+
+func (l locked) Save(task *Task) (err error) {
+	err = l.save(task)
+	if err == nil {
+		l.step()
+	}
+	return
+}
+
+func (l locked) Update(profileId string, task *Task) (err error) {
+	err = l.update(profileId, task)
+	if err == nil {
+		l.step()
+	}
+	return
+}
+
+func (l locked) Delete(profileId string, id string, version optional.Option[int]) (err error) {
+	err = l.delete(profileId, id, version)
+	if err == nil {
+		l.step()
+	}
+	return
+}
+
+func (s *StorageLocal) Move(profileId string, id string, afterId string, beforeId string, version optional.Option[int]) (err error) {
+	s.lock(profileId)
+	defer s.unlock(&err)
+
+	if afterId == "" && beforeId == "" {
+		err = fmt.Errorf("%w: %v neighbour required", ErrStorageInvalid, id)
+		return
+	}
+	var i int
+	i, err = s.index(profileId, id, false)
+	if err != nil {
+		return
+	}
+	err = s.checkVersion(i, version)
+	if err != nil {
+		return
+	}
+
+	// bounds: the ranks of the neighbours, or the ranks right next to the only one given
+	var lower, upper string
+	if afterId != "" {
+		lower, err = s.neighbourRank(profileId, id, afterId)
+		if err != nil {
+			return
+		}
+	}
+	if beforeId != "" {
+		upper, err = s.neighbourRank(profileId, id, beforeId)
+		if err != nil {
+			return
+		}
+	}
+	switch {
+		case beforeId == "":
+			upper = s.adjacentRank(profileId, id, lower, 1)
+		case afterId == "":
+			lower = s.adjacentRank(profileId, id, upper, -1)
+	}
+
+	var rank string
+	rank, err = rankBetween(lower, upper)
+	if err != nil {
+		return
+	}
+	s.touch(id)
+	s.db[i].Rank = optional.Some(rank)
+	s.bump(i)
+	return
+}
+
+// neighbourRank returns the rank of the neighbour with the given id of the moved task.
+// - the neighbour must be another task of the profile, not in the trash, with a rank
+func (s *StorageLocal) neighbourRank(profileId string, id string, neighbourId string) (rank string, err error) {
+	if neighbourId == id {
+		err = fmt.Errorf("%w: %v next to itself", ErrStorageInvalid, id)
+		return
+	}
+	i, e := s.index(profileId, neighbourId, false)
+	if e != nil {
+		err = fmt.Errorf("%w: neighbour %v not found", ErrStorageInvalid, neighbourId)
+		return
+	}
+	rank, e = s.db[i].Rank.Unwrap()
+	if e != nil {
+		err = fmt.Errorf("%w: neighbour %v not ranked", ErrStorageInvalid, neighbourId)
+		return
+	}
+	return
+}
+
+// adjacentRank returns the closest rank to the given one among the tasks of the profile but the one with the given id,
+// after it (dir 1) or before it (dir -1), or empty if there is none.
+func (s *StorageLocal) adjacentRank(profileId string, id string, rank string, dir int) (adjacent string) {
+	for _, t := range s.db {
+		tId, _ := t.ID.Unwrap()
+		r, e := t.Rank.Unwrap()
+		if e != nil || tId == id || !owned(t, profileId) {
+			continue
+		}
+		if strings.Compare(r, rank) == dir && (adjacent == "" || strings.Compare(r, adjacent) == -dir) {
+			adjacent = r
+		}
+	}
+	return
+}
+
+// lastRank returns the highest rank of the tasks of the profile (empty if none is ranked).
+func (s *StorageLocal) lastRank(profileId string) (rank string) {
+	for _, t := range s.db {
+		if r, e := t.Rank.Unwrap(); e == nil && owned(t, profileId) && r > rank {
+			rank = r
+		}
+	}
+	return
+}
+
+func (s *StorageLocal) Rebalance() (n int, err error) {
+	s.lock("")
+	defer s.unlock(&err)
+
+	// tasks of each profile, and the profiles with a task to rank again
+	tasks := make(map[string][]*Task)
+	unbalanced := make(map[string]bool)
+	for _, t := range s.db {
+		ownerId, _ := t.OwnerID.Unwrap()
+		tasks[ownerId] = append(tasks[ownerId], t)
+		if r, e := t.Rank.Unwrap(); e != nil || len(r) > MaxRankLength {
+			unbalanced[ownerId] = true
+		}
+	}
+
+	for ownerId := range unbalanced {
+		ts := tasks[ownerId]
+		sort.Slice(ts, func(i, j int) bool {
+			ri, _ := ts[i].Rank.Unwrap()
+			rj, _ := ts[j].Rank.Unwrap()
+			if ri != rj {
+				return ri < rj
+			}
+			idI, _ := ts[i].ID.Unwrap()
+			idJ, _ := ts[j].ID.Unwrap()
+			return idI < idJ
+		})
+		for k, rank := range rankSpread(len(ts)) {
+			id, _ := ts[k].ID.Unwrap()
+			s.touch(id)
+			ts[k].Rank = optional.Some(rank)
+		}
+		n += len(ts)
+	}
+	return
+}
+
+func (s *StorageLocal) AddItem(profileId string, id string, item *Item, version optional.Option[int]) (err error) {
+	s.lock(profileId)
+	defer s.unlock(&err)
+
+	var i int
+	i, err = s.authorize(profileId, id, PermissionEdit)
+	if err != nil {
+		return
+	}
+	err = s.checkVersion(i, version)
+	if err != nil {
+		return
+	}
+
+	it := *item
+	it.ID = s.newId()
+	err = s.checklist(i, addItem(s.db[i].Checklist, it))
+	if err != nil {
+		return
+	}
+	item.ID = it.ID
+	return
+}
+
+func (s *StorageLocal) CheckItem(profileId string, id string, itemId string, checked bool, version optional.Option[int]) (err error) {
+	err = s.editItem(profileId, id, version, func(items []Item) ([]Item, error) { return checkItem(items, itemId, checked) })
+	return
+}
+
+func (s *StorageLocal) MoveItem(profileId string, id string, itemId string, position int, version optional.Option[int]) (err error) {
+	err = s.editItem(profileId, id, version, func(items []Item) ([]Item, error) { return moveItem(items, itemId, position) })
+	return
+}
+
+func (s *StorageLocal) RemoveItem(profileId string, id string, itemId string, version optional.Option[int]) (err error) {
+	err = s.editItem(profileId, id, version, func(items []Item) ([]Item, error) { return removeItem(items, itemId) })
+	return
+}
+
+// editItem replaces the checklist of the task with the given id and version with the one returned by the given edit.
+func (s *StorageLocal) editItem(profileId string, id string, version optional.Option[int], edit func(items []Item) ([]Item, error)) (err error) {
+	s.lock(profileId)
+	defer s.unlock(&err)
+
+	var i int
+	i, err = s.authorize(profileId, id, PermissionEdit)
+	if err != nil {
+		return
+	}
+	err = s.checkVersion(i, version)
+	if err != nil {
+		return
+	}
+
+	var items []Item
+	items, err = edit(s.db[i].Checklist)
+	if err != nil {
+		return
+	}
+	err = s.checklist(i, items)
+	return
+}
+
+// checklist validates the task at the given position with the given checklist, and replaces its checklist with it.
+func (s *StorageLocal) checklist(i int, items []Item) (err error) {
+	ts := *s.db[i]
+	ts.Checklist = items
+	err = s.vl.Validate(&ts)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrStorageInvalid, err)
+		return
+	}
+
+	id, _ := s.db[i].ID.Unwrap()
+	s.touch(id)
+	s.db[i].Checklist = items
+	s.bump(i)
+	return
+}
+
+// Snapshot returns a copy of the tasks (in the trash too), taken at once.
+// - it shares the lock with the other readers: it only waits for the change in progress
+func (s *StorageLocal) Snapshot() (db []*Task) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	db = s.snapshot()
+	return
+}
+
+// snapshot returns a copy of the tasks, that the operations on the storage do not change.
+func (s *state) snapshot() (db []*Task) {
+	db = make([]*Task, len(s.db))
+	for i, t := range s.db {
+		cp := *t
+		cp.Labels = append([]string(nil), t.Labels...)
+		cp.Checklist = append([]Item(nil), t.Checklist...)
+		db[i] = &cp
+	}
+	return
+}
+
+// lock takes the lock of the changes, and starts a change on behalf of the given profile.
+func (s *StorageLocal) lock(profileId string) {
+	s.mu.Lock()
+	s.touched = make(map[string]bool)
+	s.by = profileId
+}
+
+// step ends an operation of the change in progress: the tasks it touched are kept as writes, as they were before it and are now.
+// - the next operation touches them again, so each operation has its own writes (and rollback undoes them in reverse)
+// - the changes of the storage itself are not kept, nor the tasks an operation removed
+func (s *StorageLocal) step() {
+	if s.rec == nil || s.by == "" {
+		return
+	}
+
+	for _, im := range s.undo[s.mark:] {
+		i, ok := s.ids[im.id]
+		if !ok {
+			continue
+		}
+		s.writes = append(s.writes, &write{before: im.task, after: clone(s.db[i])})
+	}
+	s.mark = len(s.undo)
+	s.touched = make(map[string]bool)
+}
+
+// recording sets the recorder of the writes, and returns the storage itself.
+func (s *StorageLocal) recording(rc recorder) (st Storage) {
+	s.mu.Lock()
+	s.rec = rc
+	s.mu.Unlock()
+
+	st = s
+	return
+}
+
+// unlock ends the change made with the lock held, and releases it.
+// - the writes of a change that did not fail are recorded (see rec), then it is committed (see commit);
+// a change that failed, or could not be recorded or committed, is undone
+func (s *StorageLocal) unlock(err *error) {
+	if *err == nil {
+		s.step()
+		if len(s.writes) > 0 {
+			*err = s.rec(context.Background(), nil, s.by, s.writes)
+		}
+	}
+	if *err == nil && len(s.undo) > 0 && s.commit != nil {
+		*err = s.commit(s.undo)
+	}
+	if *err != nil {
+		s.rollback(s.undo)
+	}
+
+	s.undo = nil
+	s.touched = nil
+	s.by, s.writes, s.mark = "", nil, 0
+	s.mu.Unlock()
+}
@@ -1,12 +1,18 @@
 package task
 
 import (
+	"context"
 	"fmt"
+	"sync"
 	"testing"
+	"time"
+
+	"api/internal/project"
 
 	"github.com/LNMMusic/optional"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 // Tests
@@ -19,19 +25,32 @@ func TestStorageLocal_Get(t *testing.T) {
 		output		 output
 		setDatabase  func(db *[]*Task)
 		setValidator func(vl *ValidatorMock)
+		// grants are the grants of the tasks (none if nil)
+		grants 		 map[string][]*Grant
 	}
 
 	cases := []testCase{
 		// succeed cases
+		{
+			title: "get a task shared with the profile",
+			input: input{id: "1"},
+			output: output{task: &Task{ID: optional.Some("1"), OwnerID: optional.Some("p2"), Title: optional.Some("title")}},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p2"), Title: optional.Some("title")}}
+			},
+			setValidator: func(vl *ValidatorMock) {},
+			grants: map[string][]*Grant{"1": {{ProfileID: "p1", Permission: PermissionRead}}},
+		},
 		{
 			title: "get a task",
 			input: input{id: "1"},
 			output: output{
 				task: &Task{
 					ID: optional.Some("1"),
+					OwnerID: optional.Some("p1"),
 					Title: optional.Some("title"),
 					Description: optional.Some("description"),
-					Completed: optional.Some(true),
+					Status: optional.Some(StatusDone),
 				},
 				err: nil,
 				errMsg: "",
@@ -40,9 +59,10 @@ func TestStorageLocal_Get(t *testing.T) {
 				*db = []*Task{
 					{
 						ID: optional.Some("1"),
+						OwnerID: optional.Some("p1"),
 						Title: optional.Some("title"),
 						Description: optional.Some("description"),
-						Completed: optional.Some(true),
+						Status: optional.Some(StatusDone),
 					},
 				}
 			},
@@ -50,6 +70,19 @@ func TestStorageLocal_Get(t *testing.T) {
 		},
 
 		// fail cases
+		{
+			title: "get a task of another profile",
+			input: input{id: "1"},
+			output: output{
+				task: nil,
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: 1",
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p2"), Title: optional.Some("title")}}
+			},
+			setValidator: func(vl *ValidatorMock) {},
+		},
 		{
 			title: "get a task that does not exist",
 			input: input{id: "1"},
@@ -73,10 +106,13 @@ func TestStorageLocal_Get(t *testing.T) {
 			vl := NewValidatorMock()
 			c.setValidator(vl)
 
-			st := NewStorageLocal(db, vl)
+			st := NewStorageLocal(db, vl, nil)
+			if c.grants != nil {
+				st.grants = c.grants
+			}
 
 			// act
-			task, err := st.Get(c.input.id)
+			task, err := st.Get("p1", c.input.id)
 
 			// assert
 			assert.Equal(t, c.output.task, task)
@@ -89,9 +125,235 @@ func TestStorageLocal_Get(t *testing.T) {
 	}
 }
 
+func TestStorageLocal_List(t *testing.T) {
+	type input struct {query *Query}
+	type output struct {pg *Page; err error; errMsg string}
+	type testCase struct {
+		title		 string
+		input		 input
+		output		 output
+		setDatabase  func(db *[]*Task)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "list the first page (without the tasks of other profiles)",
+			input: input{query: &Query{Size: 2}},
+			output: output{
+				pg: &Page{
+					Tasks: []*Task{
+						{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title 1")},
+						{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Title: optional.Some("title 2")},
+					},
+					Next: optional.Some(encodeCursor(&Task{ID: optional.Some("2")}, Sort{})),
+				},
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{
+					{ID: optional.Some("3"), OwnerID: optional.Some("p1"), Title: optional.Some("title 3")},
+					{ID: optional.Some("0"), OwnerID: optional.Some("p2"), Title: optional.Some("title 0")},
+					{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title 1")},
+					{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Title: optional.Some("title 2")},
+				}
+			},
+		},
+		{
+			title: "list the last page",
+			input: input{query: &Query{Cursor: encodeCursor(&Task{ID: optional.Some("2")}, Sort{}), Size: 2}},
+			output: output{
+				pg: &Page{
+					Tasks: []*Task{
+						{ID: optional.Some("3"), OwnerID: optional.Some("p1"), Title: optional.Some("title 3")},
+					},
+					Next: optional.None[string](),
+				},
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{
+					{ID: optional.Some("3"), OwnerID: optional.Some("p1"), Title: optional.Some("title 3")},
+					{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title 1")},
+					{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Title: optional.Some("title 2")},
+				}
+			},
+		},
+		{
+			title: "list the trash",
+			input: input{query: &Query{Deleted: true}},
+			output: output{
+				pg: &Page{
+					Tasks: []*Task{
+						{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Title: optional.Some("title 2"), DeletedAt: optional.Some(time.Unix(0, 0))},
+					},
+					Next: optional.None[string](),
+				},
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{
+					{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title 1")},
+					{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Title: optional.Some("title 2"), DeletedAt: optional.Some(time.Unix(0, 0))},
+				}
+			},
+		},
+		{
+			title: "list filtered and sorted tasks after a cursor",
+			input: input{query: &Query{
+				Cursor: encodeCursor(&Task{ID: optional.Some("3"), Title: optional.Some("task c")}, Sort{Field: FieldTitle, Desc: true}),
+				Filter: And{Filters: []Filter{
+					Condition{Field: FieldStatus, Operator: OperatorNe, Value: "done"},
+					Condition{Field: FieldTitle, Operator: OperatorContains, Value: "TASK"},
+				}},
+				Sort: Sort{Field: FieldTitle, Desc: true},
+			}},
+			output: output{
+				pg: &Page{
+					Tasks: []*Task{
+						{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Title: optional.Some("task b"), Status: optional.Some(StatusTodo)},
+						{ID: optional.Some("4"), OwnerID: optional.Some("p1"), Title: optional.Some("task a"), Status: optional.Some(StatusTodo)},
+					},
+					Next: optional.None[string](),
+				},
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{
+					{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("task d"), Status: optional.Some(StatusTodo)},
+					{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Title: optional.Some("task b"), Status: optional.Some(StatusTodo)},
+					{ID: optional.Some("3"), OwnerID: optional.Some("p1"), Title: optional.Some("task c"), Status: optional.Some(StatusTodo)},
+					{ID: optional.Some("4"), OwnerID: optional.Some("p1"), Title: optional.Some("task a"), Status: optional.Some(StatusTodo)},
+					{ID: optional.Some("5"), OwnerID: optional.Some("p1"), Title: optional.Some("task a"), Status: optional.Some(StatusDone)},
+					{ID: optional.Some("6"), OwnerID: optional.Some("p1"), Title: optional.Some("other"), Status: optional.Some(StatusTodo)},
+				}
+			},
+		},
+		{
+			title: "list tasks due before a time sorted by due date after a cursor",
+			input: input{query: &Query{
+				Cursor: encodeCursor(&Task{ID: optional.Some("1"), DueAt: optional.Some(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))}, Sort{Field: FieldDueAt}),
+				Filter: Condition{Field: FieldDueAt, Operator: OperatorLt, Value: time.Date(2023, 1, 4, 0, 0, 0, 0, time.UTC)},
+				Sort: Sort{Field: FieldDueAt},
+			}},
+			output: output{
+				pg: &Page{
+					Tasks: []*Task{
+						{ID: optional.Some("3"), OwnerID: optional.Some("p1"), DueAt: optional.Some(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC))},
+						{ID: optional.Some("2"), OwnerID: optional.Some("p1"), DueAt: optional.Some(time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC))},
+					},
+					Next: optional.None[string](),
+				},
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{
+					{ID: optional.Some("1"), OwnerID: optional.Some("p1"), DueAt: optional.Some(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))},
+					{ID: optional.Some("2"), OwnerID: optional.Some("p1"), DueAt: optional.Some(time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC))},
+					{ID: optional.Some("3"), OwnerID: optional.Some("p1"), DueAt: optional.Some(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC))},
+					{ID: optional.Some("4"), OwnerID: optional.Some("p1")},
+					{ID: optional.Some("5"), OwnerID: optional.Some("p1"), DueAt: optional.Some(time.Date(2023, 1, 5, 0, 0, 0, 0, time.UTC))},
+				}
+			},
+		},
+		{
+			title: "list tasks sorted by descending due date (no due date goes last)",
+			input: input{query: &Query{Sort: Sort{Field: FieldDueAt, Desc: true}}},
+			output: output{
+				pg: &Page{
+					Tasks: []*Task{
+						{ID: optional.Some("2"), OwnerID: optional.Some("p1"), DueAt: optional.Some(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC))},
+						{ID: optional.Some("1"), OwnerID: optional.Some("p1"), DueAt: optional.Some(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))},
+						{ID: optional.Some("3"), OwnerID: optional.Some("p1")},
+					},
+					Next: optional.None[string](),
+				},
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{
+					{ID: optional.Some("3"), OwnerID: optional.Some("p1")},
+					{ID: optional.Some("1"), OwnerID: optional.Some("p1"), DueAt: optional.Some(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))},
+					{ID: optional.Some("2"), OwnerID: optional.Some("p1"), DueAt: optional.Some(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC))},
+				}
+			},
+		},
+		{
+			title: "list tasks with all the given labels",
+			input: input{query: &Query{Filter: And{Filters: []Filter{
+				Condition{Field: FieldLabels, Operator: OperatorEq, Value: "backend"},
+				Condition{Field: FieldLabels, Operator: OperatorEq, Value: "urgent"},
+			}}}},
+			output: output{
+				pg: &Page{
+					Tasks: []*Task{
+						{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Labels: []string{"backend", "urgent"}},
+					},
+					Next: optional.None[string](),
+				},
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{
+					{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Labels: []string{"backend"}},
+					{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Labels: []string{"backend", "urgent"}},
+					{ID: optional.Some("3"), OwnerID: optional.Some("p1")},
+				}
+			},
+		},
+		{
+			title: "list an empty storage",
+			input: input{query: &Query{}},
+			output: output{
+				pg: &Page{Tasks: []*Task{}, Next: optional.None[string]()},
+			},
+			setDatabase: func(db *[]*Task) {},
+		},
+
+		// fail cases
+		{
+			title: "list with an invalid cursor",
+			input: input{query: &Query{Cursor: "invalid"}},
+			output: output{
+				pg: nil,
+				err: ErrStorageInvalidQuery,
+				errMsg: "storage invalid query: cursor",
+			},
+			setDatabase: func(db *[]*Task) {},
+		},
+		{
+			title: "list with a size over the maximum",
+			input: input{query: &Query{Size: MaxPageSize + 1}},
+			output: output{
+				pg: nil,
+				err: ErrStorageInvalidQuery,
+				errMsg: "storage invalid query: size",
+			},
+			setDatabase: func(db *[]*Task) {},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db := []*Task{}
+			c.setDatabase(&db)
+
+			vl := NewValidatorMock()
+
+			st := NewStorageLocal(db, vl, nil)
+
+			// act
+			pg, err := st.List("p1", c.input.query)
+
+			// assert
+			assert.Equal(t, c.output.pg, pg)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			vl.AssertExpectations(t)
+		})
+	}
+}
+
 func TestStorageLocal_Save(t *testing.T) {
 	type input struct {task *Task}
-	type output struct {err error; errMsg string}
+	type output struct {err error; errMsg string; series optional.Option[string]; occurrence optional.Option[int]}
 	type testCase struct {
 		title		 string
 		input		 input
@@ -107,9 +369,10 @@ func TestStorageLocal_Save(t *testing.T) {
 			input: input{
 				task: &Task{
 					ID: optional.None[string](),
+					OwnerID: optional.Some("p1"),
 					Title: optional.Some("title"),
 					Description: optional.Some("description"),
-					Completed: optional.Some(true),
+					Status: optional.Some(StatusDone),
 				},
 			},
 			output: output{
@@ -120,22 +383,47 @@ func TestStorageLocal_Save(t *testing.T) {
 			setValidator: func(vl *ValidatorMock) {
 				vl.On("Validate", &Task{
 					ID: optional.None[string](),
+					OwnerID: optional.Some("p1"),
 					Title: optional.Some("title"),
 					Description: optional.Some("description"),
-					Completed: optional.Some(true),
+					Status: optional.Some(StatusDone),
 				}).Return(nil)
 			},
 		},
 
+		{
+			title: "save a recurring task starting its own series",
+			input: input{
+				task: &Task{
+					OwnerID: optional.Some("p1"),
+					Title: optional.Some("title"),
+					Status: optional.Some(StatusTodo),
+					DueAt: optional.Some(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)),
+					Recurrence: optional.Some("FREQ=DAILY"),
+				},
+			},
+			output: output{
+				err: nil,
+				errMsg: "",
+				series: optional.Some("1"),
+				occurrence: optional.Some(1),
+			},
+			setDatabase: func(db *[]*Task) {},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+
 		// failure cases
 		{
 			title: "save an invalid task",
 			input: input{
 				task: &Task{
 					ID: optional.None[string](),
+					OwnerID: optional.Some("p1"),
 					Title: optional.None[string](),
 					Description: optional.Some("description"),
-					Completed: optional.Some(true),
+					Status: optional.Some(StatusDone),
 				},
 			},
 			output: output{
@@ -146,12 +434,57 @@ func TestStorageLocal_Save(t *testing.T) {
 			setValidator: func(vl *ValidatorMock) {
 				vl.On("Validate", &Task{
 					ID: optional.None[string](),
+					OwnerID: optional.Some("p1"),
 					Title: optional.None[string](),
 					Description: optional.Some("description"),
-					Completed: optional.Some(true),
+					Status: optional.Some(StatusDone),
 				}).Return(fmt.Errorf("validation failed: title: is required"))
 			},
 		},
+		{
+			title: "save a task without owner",
+			input: input{
+				task: &Task{Title: optional.Some("title")},
+			},
+			output: output{
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: owner required",
+			},
+			setDatabase: func(db *[]*Task) {},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Validate", &Task{Title: optional.Some("title")}).Return(nil)
+			},
+		},
+		{
+			title: "save a subtask of a task of another profile",
+			input: input{
+				task: &Task{OwnerID: optional.Some("p1"), Title: optional.Some("title"), ParentID: optional.Some("1")},
+			},
+			output: output{
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: parent 1 not found",
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p2")}}
+			},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+		{
+			title: "save a subtask of a task that does not exist",
+			input: input{
+				task: &Task{OwnerID: optional.Some("p1"), Title: optional.Some("title"), ParentID: optional.Some("1")},
+			},
+			output: output{
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: parent 1 not found",
+			},
+			setDatabase: func(db *[]*Task) {},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Validate", &Task{OwnerID: optional.Some("p1"), Title: optional.Some("title"), ParentID: optional.Some("1")}).Return(nil)
+			},
+		},
 	}
 
 	// run tests
@@ -164,7 +497,8 @@ func TestStorageLocal_Save(t *testing.T) {
 			vl := NewValidatorMock()
 			c.setValidator(vl)
 
-			st := NewStorageLocal(db, vl)
+			st := NewStorageLocal(db, vl, nil)
+			st.newId = func() string { return "1" }
 
 			// act
 			err := st.Save(c.input.task)
@@ -173,8 +507,2380 @@ func TestStorageLocal_Save(t *testing.T) {
 			assert.ErrorIs(t, err, c.output.err)
 			if err != nil {
 				assert.Equal(t, c.output.errMsg, err.Error())
+			} else {
+				assert.Equal(t, c.output.series, c.input.task.SeriesID)
+				assert.Equal(t, c.output.occurrence, c.input.task.Occurrence)
 			}
 			vl.AssertExpectations(t)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestStorageLocal_SaveProject(t *testing.T) {
+	type input struct {task *Task}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "save a task in a project of the owner",
+			input: input{task: &Task{OwnerID: optional.Some("p1"), Title: optional.Some("title"), ProjectID: optional.Some("pj1")}},
+			output: output{err: nil},
+		},
+		{
+			title: "save a task without project",
+			input: input{task: &Task{OwnerID: optional.Some("p1"), Title: optional.Some("title")}},
+			output: output{err: nil},
+		},
+
+		// failure cases
+		{
+			title: "save a task in a project of another profile",
+			input: input{task: &Task{OwnerID: optional.Some("p2"), Title: optional.Some("title"), ProjectID: optional.Some("pj1")}},
+			output: output{err: ErrStorageInvalid, errMsg: "storage invalid task: project pj1 not found"},
+		},
+		{
+			title: "save a task in a project that does not exist",
+			input: input{task: &Task{OwnerID: optional.Some("p1"), Title: optional.Some("title"), ProjectID: optional.Some("pj2")}},
+			output: output{err: ErrStorageInvalid, errMsg: "storage invalid task: project pj2 not found"},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			projects := project.NewStorageLocal([]*project.Project{{ID: optional.Some("pj1"), OwnerID: optional.Some("p1"), Name: optional.Some("inbox")}}, project.NewValidatorMock())
+
+			vl := NewValidatorMock()
+			vl.On("Validate", mock.Anything).Return(nil)
+
+			st := NewStorageLocal([]*Task{}, vl, &Config{Projects: projects})
+
+			// act
+			err := st.Save(c.input.task)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+				assert.Empty(t, st.db)
+				return
+			}
+			assert.Len(t, st.db, 1)
+		})
+	}
+}
+
+func TestStorageLocal_Update(t *testing.T) {
+	type input struct {task *Task}
+	type output struct {db []*Task; err error; errMsg string}
+	type testCase struct {
+		title		 string
+		input		 input
+		output		 output
+		setDatabase  func(db *[]*Task)
+		setValidator func(vl *ValidatorMock)
+		// cfg is the storage config (nil for the default one)
+		cfg 		 *Config
+		// deps are the blockers of the tasks (none if nil)
+		deps 		 map[string][]string
+		// grants are the grants of the tasks (none if nil)
+		grants 		 map[string][]*Grant
+	}
+
+	created := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "update a task",
+			input: input{
+				task: &Task{
+					ID: optional.Some("1"),
+					OwnerID: optional.Some("p1"),
+					Title: optional.Some("new title"),
+					Description: optional.None[string](),
+					Status: optional.Some(StatusDone),
+				},
+			},
+			output: output{
+				db: []*Task{
+					{
+						ID: optional.Some("1"),
+						OwnerID: optional.Some("p1"),
+						Title: optional.Some("new title"),
+						Description: optional.None[string](),
+						Status: optional.Some(StatusDone),
+						CreatedAt: optional.Some(created),
+						UpdatedAt: optional.Some(now),
+						Version: optional.Some(1),
+					},
+				},
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{
+					{
+						ID: optional.Some("1"),
+						OwnerID: optional.Some("p1"),
+						Title: optional.Some("title"),
+						Description: optional.Some("description"),
+						Status: optional.Some(StatusTodo),
+						CreatedAt: optional.Some(created),
+						UpdatedAt: optional.Some(created),
+					},
+				}
+			},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Transition", StatusTodo, StatusDone).Return(nil)
+				vl.On("Validate", &Task{
+					ID: optional.Some("1"),
+					OwnerID: optional.Some("p1"),
+					Title: optional.Some("new title"),
+					Description: optional.None[string](),
+					Status: optional.Some(StatusDone),
+				}).Return(nil)
+			},
+		},
+		{
+			title: "complete a task cascading down to its subtasks",
+			input: input{
+				task: &Task{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title"), Status: optional.Some(StatusDone)},
+			},
+			output: output{
+				db: []*Task{
+					{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title"), Status: optional.Some(StatusDone), UpdatedAt: optional.Some(now), Version: optional.Some(1)},
+					{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Status: optional.Some(StatusDone), ParentID: optional.Some("1"), UpdatedAt: optional.Some(now), Version: optional.Some(1)},
+					{ID: optional.Some("3"), OwnerID: optional.Some("p1"), Status: optional.Some(StatusDone), ParentID: optional.Some("2"), UpdatedAt: optional.Some(now), Version: optional.Some(1)},
+					{ID: optional.Some("4"), OwnerID: optional.Some("p1"), Status: optional.Some(StatusTodo)},
+				},
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{
+					{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title"), Status: optional.Some(StatusTodo)},
+					{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Status: optional.Some(StatusTodo), ParentID: optional.Some("1")},
+					{ID: optional.Some("3"), OwnerID: optional.Some("p1"), Status: optional.Some(StatusTodo), ParentID: optional.Some("2")},
+					{ID: optional.Some("4"), OwnerID: optional.Some("p1"), Status: optional.Some(StatusTodo)},
+				}
+			},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Transition", StatusTodo, StatusDone).Return(nil)
+				vl.On("Validate", mock.Anything).Return(nil)
+			},
+			cfg: &Config{Hierarchy: HierarchyCascade},
+		},
+
+		{
+			title: "complete a recurring task creating its next occurrence",
+			input: input{
+				task: &Task{
+					ID: optional.Some("1"),
+					OwnerID: optional.Some("p1"),
+					Title: optional.Some("title"),
+					Status: optional.Some(StatusDone),
+					DueAt: optional.Some(created),
+					Recurrence: optional.Some("FREQ=WEEKLY;COUNT=3"),
+				},
+			},
+			output: output{
+				db: []*Task{
+					{
+						ID: optional.Some("1"),
+						OwnerID: optional.Some("p1"),
+						Title: optional.Some("title"),
+						Status: optional.Some(StatusDone),
+						DueAt: optional.Some(created),
+						UpdatedAt: optional.Some(now),
+						Version: optional.Some(1),
+						Recurrence: optional.Some("FREQ=WEEKLY;COUNT=3"),
+						SeriesID: optional.Some("0"),
+						Occurrence: optional.Some(2),
+					},
+					{
+						ID: optional.Some("2"),
+						OwnerID: optional.Some("p1"),
+						Title: optional.Some("title"),
+						Status: optional.Some(StatusTodo),
+						DueAt: optional.Some(created.AddDate(0, 0, 7)),
+						CreatedAt: optional.Some(now),
+						UpdatedAt: optional.Some(now),
+						Version: optional.Some(1),
+						Labels: []string{},
+						DeletedAt: optional.None[time.Time](),
+						Recurrence: optional.Some("FREQ=WEEKLY;COUNT=3"),
+						SeriesID: optional.Some("0"),
+						Occurrence: optional.Some(3),
+						Rank: optional.Some("i"),
+					},
+				},
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{
+					{
+						ID: optional.Some("1"),
+						OwnerID: optional.Some("p1"),
+						Title: optional.Some("title"),
+						Status: optional.Some(StatusTodo),
+						DueAt: optional.Some(created),
+						Recurrence: optional.Some("FREQ=WEEKLY;COUNT=3"),
+						SeriesID: optional.Some("0"),
+						Occurrence: optional.Some(2),
+					},
+				}
+			},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Transition", StatusTodo, StatusDone).Return(nil)
+				vl.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+		{
+			title: "complete the last occurrence of a series",
+			input: input{
+				task: &Task{
+					ID: optional.Some("1"),
+					OwnerID: optional.Some("p1"),
+					Status: optional.Some(StatusDone),
+					DueAt: optional.Some(created),
+					Recurrence: optional.Some("FREQ=WEEKLY;COUNT=3"),
+				},
+			},
+			output: output{
+				db: []*Task{
+					{
+						ID: optional.Some("1"),
+						OwnerID: optional.Some("p1"),
+						Status: optional.Some(StatusDone),
+						DueAt: optional.Some(created),
+						UpdatedAt: optional.Some(now),
+						Version: optional.Some(1),
+						Recurrence: optional.Some("FREQ=WEEKLY;COUNT=3"),
+						SeriesID: optional.Some("0"),
+						Occurrence: optional.Some(3),
+					},
+				},
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{
+					{
+						ID: optional.Some("1"),
+						OwnerID: optional.Some("p1"),
+						Status: optional.Some(StatusTodo),
+						DueAt: optional.Some(created),
+						Recurrence: optional.Some("FREQ=WEEKLY;COUNT=3"),
+						SeriesID: optional.Some("0"),
+						Occurrence: optional.Some(3),
+					},
+				}
+			},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Transition", StatusTodo, StatusDone).Return(nil)
+				vl.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+
+		{
+			title: "update a task shared with the profile with edit permission (the owner is kept)",
+			input: input{
+				task: &Task{ID: optional.Some("1"), Title: optional.Some("new title")},
+			},
+			output: output{
+				db: []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p2"), Title: optional.Some("new title"), CreatedAt: optional.Some(created), UpdatedAt: optional.Some(now), Version: optional.Some(1)}},
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p2"), Title: optional.Some("title"), CreatedAt: optional.Some(created), UpdatedAt: optional.Some(created)}}
+			},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Validate", mock.Anything).Return(nil)
+			},
+			grants: map[string][]*Grant{"1": {{ProfileID: "p1", Permission: PermissionEdit}}},
+		},
+		{
+			title: "update a task from its current version",
+			input: input{task: &Task{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("new title"), Version: optional.Some(3)}},
+			output: output{
+				db: []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("new title"), CreatedAt: optional.Some(created), UpdatedAt: optional.Some(now), Version: optional.Some(4)}},
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title"), CreatedAt: optional.Some(created), Version: optional.Some(3)}}
+			},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+
+		// failure cases
+		{
+			title: "update a task from a stale version",
+			input: input{task: &Task{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("new title"), Version: optional.Some(2)}},
+			output: output{
+				db: []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title"), Version: optional.Some(3)}},
+				err: ErrStorageVersionMismatch,
+				errMsg: "storage task version mismatch: 1 2",
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title"), Version: optional.Some(3)}}
+			},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+		{
+			title: "update a task making it a subtask of its own subtask",
+			input: input{
+				task: &Task{ID: optional.Some("1"), OwnerID: optional.Some("p1"), ParentID: optional.Some("3")},
+			},
+			output: output{
+				db: []*Task{
+					{ID: optional.Some("1"), OwnerID: optional.Some("p1")},
+					{ID: optional.Some("2"), OwnerID: optional.Some("p1"), ParentID: optional.Some("1")},
+					{ID: optional.Some("3"), OwnerID: optional.Some("p1"), ParentID: optional.Some("2")},
+				},
+				err: ErrStorageCycle,
+				errMsg: "storage invalid task: cycle: 1",
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{
+					{ID: optional.Some("1"), OwnerID: optional.Some("p1")},
+					{ID: optional.Some("2"), OwnerID: optional.Some("p1"), ParentID: optional.Some("1")},
+					{ID: optional.Some("3"), OwnerID: optional.Some("p1"), ParentID: optional.Some("2")},
+				}
+			},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+		{
+			title: "complete a task with open subtasks",
+			input: input{
+				task: &Task{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Status: optional.Some(StatusDone)},
+			},
+			output: output{
+				db: []*Task{
+					{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Status: optional.Some(StatusTodo)},
+					{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Status: optional.Some(StatusDone), ParentID: optional.Some("1")},
+					{ID: optional.Some("3"), OwnerID: optional.Some("p1"), Status: optional.Some(StatusTodo), ParentID: optional.Some("1")},
+				},
+				err: ErrStorageOpenChildren,
+				errMsg: "storage invalid task: open children: 3",
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{
+					{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Status: optional.Some(StatusTodo)},
+					{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Status: optional.Some(StatusDone), ParentID: optional.Some("1")},
+					{ID: optional.Some("3"), OwnerID: optional.Some("p1"), Status: optional.Some(StatusTodo), ParentID: optional.Some("1")},
+				}
+			},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Transition", StatusTodo, StatusDone).Return(nil)
+				vl.On("Validate", mock.Anything).Return(nil)
+			},
+			cfg: &Config{Hierarchy: HierarchyRestrict},
+		},
+		{
+			title: "complete a task blocked by an open task",
+			input: input{
+				task: &Task{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("a"), Status: optional.Some(StatusDone)},
+			},
+			output: output{
+				db: []*Task{
+					{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("a"), Status: optional.Some(StatusTodo)},
+					{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Title: optional.Some("b"), Status: optional.Some(StatusTodo)},
+				},
+				err: ErrStorageOpenBlockers,
+				errMsg: "storage invalid task: open blockers: 2",
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{
+					{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("a"), Status: optional.Some(StatusTodo)},
+					{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Title: optional.Some("b"), Status: optional.Some(StatusTodo)},
+				}
+			},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Transition", StatusTodo, StatusDone).Return(nil)
+				vl.On("Validate", mock.Anything).Return(nil)
+			},
+			deps: map[string][]string{"1": {"2"}},
+		},
+		{
+			title: "update a task with a transition the workflow does not allow",
+			input: input{
+				task: &Task{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title"), Status: optional.Some(StatusDone)},
+			},
+			output: output{
+				db: []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title"), Status: optional.Some(StatusArchived)}},
+				err: ErrStorageTransition,
+				errMsg: "storage invalid task: transition: validator transition not allowed: archived to done",
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title"), Status: optional.Some(StatusArchived)}}
+			},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Validate", mock.Anything).Return(nil)
+				vl.On("Transition", StatusArchived, StatusDone).
+					Return(fmt.Errorf("%w: %s to %s", ErrValidatorTransition, StatusArchived, StatusDone))
+			},
+		},
+		{
+			title: "update an invalid task",
+			input: input{
+				task: &Task{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.None[string]()},
+			},
+			output: output{
+				db: []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title")}},
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: validation failed: title: is required",
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title")}}
+			},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Validate", &Task{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.None[string]()}).
+					Return(fmt.Errorf("validation failed: title: is required"))
+			},
+		},
+		{
+			title: "update a task shared with the profile with read permission",
+			input: input{
+				task: &Task{ID: optional.Some("1"), Title: optional.Some("new title")},
+			},
+			output: output{
+				db: []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p2"), Title: optional.Some("title")}},
+				err: ErrStorageForbidden,
+				errMsg: "storage task forbidden: 1 edit",
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p2"), Title: optional.Some("title")}}
+			},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Validate", mock.Anything).Return(nil)
+			},
+			grants: map[string][]*Grant{"1": {{ProfileID: "p1", Permission: PermissionRead}}},
+		},
+		{
+			title: "update a task of another profile",
+			input: input{
+				task: &Task{ID: optional.Some("1"), Title: optional.Some("new title")},
+			},
+			output: output{
+				db: []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p2"), Title: optional.Some("title")}},
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: 1",
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p2"), Title: optional.Some("title")}}
+			},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+		{
+			title: "update a task that does not exist",
+			input: input{
+				task: &Task{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Title: optional.Some("title")},
+			},
+			output: output{
+				db: []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title")}},
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: 2",
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title")}}
+			},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Validate", &Task{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Title: optional.Some("title")}).Return(nil)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db := []*Task{}
+			c.setDatabase(&db)
+
+			vl := NewValidatorMock()
+			c.setValidator(vl)
+
+			st := NewStorageLocal(db, vl, c.cfg)
+			st.now = func() time.Time { return now }
+			st.newId = func() string { return "2" }
+			if c.deps != nil {
+				st.deps = c.deps
+			}
+			if c.grants != nil {
+				st.grants = c.grants
+			}
+
+			// act
+			err := st.Update("p1", c.input.task)
+
+			// assert
+			assert.Equal(t, c.output.db, st.db)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			vl.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStorageLocal_Delete(t *testing.T) {
+	type input struct {id string; version optional.Option[int]}
+	type output struct {db []*Task; err error; errMsg string}
+	type testCase struct {
+		title		 string
+		input		 input
+		output		 output
+		setDatabase  func(db *[]*Task)
+	}
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "delete a task",
+			input: input{id: "1"},
+			output: output{
+				db: []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), DeletedAt: optional.Some(now)}},
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1")}}
+			},
+		},
+
+		// failure cases
+		{
+			title: "delete a task already in the trash",
+			input: input{id: "1"},
+			output: output{
+				db: []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), DeletedAt: optional.Some(now.Add(-time.Hour))}},
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: 1",
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), DeletedAt: optional.Some(now.Add(-time.Hour))}}
+			},
+		},
+		{
+			title: "delete a task with another version",
+			input: input{id: "1", version: optional.Some(1)},
+			output: output{
+				db: []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Version: optional.Some(2)}},
+				err: ErrStorageVersionMismatch,
+				errMsg: "storage task version mismatch: 1 1",
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Version: optional.Some(2)}}
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db := []*Task{}
+			c.setDatabase(&db)
+
+			st := NewStorageLocal(db, NewValidatorMock(), nil)
+			st.now = func() time.Time { return now }
+
+			// act
+			err := st.Delete("p1", c.input.id, c.input.version)
+
+			// assert
+			assert.Equal(t, c.output.db, st.db)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestStorageLocal_Restore(t *testing.T) {
+	type input struct {id string; version optional.Option[int]}
+	type output struct {db []*Task; err error; errMsg string}
+	type testCase struct {
+		title		 string
+		input		 input
+		output		 output
+		setDatabase  func(db *[]*Task)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "restore a task",
+			input: input{id: "1"},
+			output: output{
+				db: []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), DeletedAt: optional.None[time.Time]()}},
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), DeletedAt: optional.Some(time.Unix(0, 0))}}
+			},
+		},
+
+		// failure cases
+		{
+			title: "restore a task that is not in the trash",
+			input: input{id: "1"},
+			output: output{
+				db: []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1")}},
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: 1",
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1")}}
+			},
+		},
+		{
+			title: "restore a task with another version",
+			input: input{id: "1", version: optional.Some(1)},
+			output: output{
+				db: []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Version: optional.Some(2), DeletedAt: optional.Some(time.Unix(0, 0))}},
+				err: ErrStorageVersionMismatch,
+				errMsg: "storage task version mismatch: 1 1",
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Version: optional.Some(2), DeletedAt: optional.Some(time.Unix(0, 0))}}
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db := []*Task{}
+			c.setDatabase(&db)
+
+			st := NewStorageLocal(db, NewValidatorMock(), nil)
+
+			// act
+			err := st.Restore("p1", c.input.id, c.input.version)
+
+			// assert
+			assert.Equal(t, c.output.db, st.db)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestStorageLocal_Purge(t *testing.T) {
+	type input struct {before time.Time}
+	type output struct {n int; db []*Task}
+	type testCase struct {
+		title		 string
+		input		 input
+		output		 output
+		setDatabase  func(db *[]*Task)
+	}
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := []testCase{
+		{
+			title: "purge the tasks deleted before the retention",
+			input: input{before: now},
+			output: output{
+				n: 1,
+				db: []*Task{
+					{ID: optional.Some("1"), OwnerID: optional.Some("p1")},
+					{ID: optional.Some("3"), OwnerID: optional.Some("p1"), DeletedAt: optional.Some(now.Add(time.Hour))},
+				},
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{
+					{ID: optional.Some("1"), OwnerID: optional.Some("p1")},
+					{ID: optional.Some("2"), OwnerID: optional.Some("p1"), DeletedAt: optional.Some(now.Add(-time.Hour))},
+					{ID: optional.Some("3"), OwnerID: optional.Some("p1"), DeletedAt: optional.Some(now.Add(time.Hour))},
+				}
+			},
+		},
+		{
+			title: "purge a task with subtasks",
+			input: input{before: now},
+			output: output{
+				n: 1,
+				db: []*Task{{ID: optional.Some("2"), OwnerID: optional.Some("p1"), ParentID: optional.None[string]()}},
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{
+					{ID: optional.Some("1"), OwnerID: optional.Some("p1"), DeletedAt: optional.Some(now.Add(-time.Hour))},
+					{ID: optional.Some("2"), OwnerID: optional.Some("p1"), ParentID: optional.Some("1")},
+				}
+			},
+		},
+		{
+			title: "purge an empty trash",
+			input: input{before: now},
+			output: output{n: 0, db: []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1")}}},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1")}}
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db := []*Task{}
+			c.setDatabase(&db)
+
+			st := NewStorageLocal(db, NewValidatorMock(), nil)
+
+			// act
+			n, err := st.Purge(c.input.before)
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, c.output.n, n)
+			assert.Equal(t, c.output.db, st.db)
+		})
+	}
+}
+
+func TestStorageLocal_AddLabel(t *testing.T) {
+	type input struct {id string; label string; version optional.Option[int]}
+	type output struct {db []*Task; err error; errMsg string}
+	type testCase struct {
+		title		 string
+		input		 input
+		output		 output
+		setDatabase  func(db *[]*Task)
+		setValidator func(vl *ValidatorMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "add a label",
+			input: input{id: "1", label: "backend"},
+			output: output{db: []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Labels: []string{"backend", "urgent"}, Version: optional.Some(1)}}},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Labels: []string{"urgent"}}}
+			},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Validate", &Task{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Labels: []string{"backend", "urgent"}}).Return(nil)
+			},
+		},
+		{
+			title: "add a label the task already has",
+			input: input{id: "1", label: "urgent"},
+			output: output{db: []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Labels: []string{"urgent"}}}},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Labels: []string{"urgent"}}}
+			},
+			setValidator: func(vl *ValidatorMock) {},
+		},
+
+		// failure cases
+		{
+			title: "add an invalid label",
+			input: input{id: "1", label: "a,b"},
+			output: output{
+				db: []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1")}},
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: validator field quality: label \"a,b\"",
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1")}}
+			},
+			setValidator: func(vl *ValidatorMock) {
+				vl.On("Validate", &Task{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Labels: []string{"a,b"}}).
+					Return(fmt.Errorf("%w: label %q", ErrValidatorFieldQuality, "a,b"))
+			},
+		},
+		{
+			title: "add a label to a task in the trash",
+			input: input{id: "1", label: "backend"},
+			output: output{
+				db: []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), DeletedAt: optional.Some(time.Unix(0, 0))}},
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: 1",
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), DeletedAt: optional.Some(time.Unix(0, 0))}}
+			},
+			setValidator: func(vl *ValidatorMock) {},
+		},
+		{
+			title: "add a label to a task with another version",
+			input: input{id: "1", label: "backend", version: optional.Some(1)},
+			output: output{
+				db: []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Version: optional.Some(2)}},
+				err: ErrStorageVersionMismatch,
+				errMsg: "storage task version mismatch: 1 1",
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Version: optional.Some(2)}}
+			},
+			setValidator: func(vl *ValidatorMock) {},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db := []*Task{}
+			c.setDatabase(&db)
+
+			vl := NewValidatorMock()
+			c.setValidator(vl)
+
+			st := NewStorageLocal(db, vl, nil)
+
+			// act
+			err := st.AddLabel("p1", c.input.id, c.input.label, c.input.version)
+
+			// assert
+			assert.Equal(t, c.output.db, st.db)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			vl.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStorageLocal_RemoveLabel(t *testing.T) {
+	type input struct {id string; label string; version optional.Option[int]}
+	type output struct {db []*Task; err error; errMsg string}
+	type testCase struct {
+		title		 string
+		input		 input
+		output		 output
+		setDatabase  func(db *[]*Task)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "remove a label",
+			input: input{id: "1", label: "backend"},
+			output: output{db: []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Labels: []string{"urgent"}, Version: optional.Some(1)}}},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Labels: []string{"backend", "urgent"}}}
+			},
+		},
+
+		// failure cases
+		{
+			title: "remove a label the task does not have",
+			input: input{id: "1", label: "backend"},
+			output: output{
+				db: []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Labels: []string{"urgent"}}},
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: 1 label backend",
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Labels: []string{"urgent"}}}
+			},
+		},
+		{
+			title: "remove a label from a task with another version",
+			input: input{id: "1", label: "urgent", version: optional.Some(1)},
+			output: output{
+				db: []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Labels: []string{"urgent"}, Version: optional.Some(2)}},
+				err: ErrStorageVersionMismatch,
+				errMsg: "storage task version mismatch: 1 1",
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Labels: []string{"urgent"}, Version: optional.Some(2)}}
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db := []*Task{}
+			c.setDatabase(&db)
+
+			st := NewStorageLocal(db, NewValidatorMock(), nil)
+
+			// act
+			err := st.RemoveLabel("p1", c.input.id, c.input.label, c.input.version)
+
+			// assert
+			assert.Equal(t, c.output.db, st.db)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestStorageLocal_Labels(t *testing.T) {
+	type output struct {ls []*Label}
+	type testCase struct {
+		title		 string
+		output		 output
+		setDatabase  func(db *[]*Task)
+	}
+
+	cases := []testCase{
+		{
+			title: "labels with usage counts (tasks in the trash are not counted)",
+			output: output{ls: []*Label{{Name: "backend", Tasks: 2}, {Name: "urgent", Tasks: 1}}},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{
+					{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Labels: []string{"backend", "urgent"}},
+					{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Labels: []string{"backend"}},
+					{ID: optional.Some("3"), OwnerID: optional.Some("p1"), Labels: []string{"old"}, DeletedAt: optional.Some(time.Unix(0, 0))},
+				}
+			},
+		},
+		{
+			title: "no labels",
+			output: output{ls: []*Label{}},
+			setDatabase: func(db *[]*Task) {},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db := []*Task{}
+			c.setDatabase(&db)
+
+			st := NewStorageLocal(db, NewValidatorMock(), nil)
+
+			// act
+			ls, err := st.Labels("p1")
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, c.output.ls, ls)
+		})
+	}
+}
+
+func TestStorageLocal_Tree(t *testing.T) {
+	type input struct {id string}
+	type output struct {nd *Node; err error; errMsg string}
+	type testCase struct {
+		title		 string
+		input		 input
+		output		 output
+		setDatabase  func(db *[]*Task)
+	}
+
+	// tasks: 1 -> (2 -> 4, 3), 5 in the trash
+	tasks := []*Task{
+		{ID: optional.Some("1"), OwnerID: optional.Some("p1")},
+		{ID: optional.Some("3"), OwnerID: optional.Some("p1"), ParentID: optional.Some("1")},
+		{ID: optional.Some("2"), OwnerID: optional.Some("p1"), ParentID: optional.Some("1")},
+		{ID: optional.Some("4"), OwnerID: optional.Some("p1"), ParentID: optional.Some("2")},
+		{ID: optional.Some("5"), OwnerID: optional.Some("p1"), ParentID: optional.Some("1"), DeletedAt: optional.Some(time.Unix(0, 0))},
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "get the subtree of a task",
+			input: input{id: "1"},
+			output: output{nd: &Node{
+				Task: tasks[0],
+				Children: []*Node{
+					{Task: tasks[2], Children: []*Node{{Task: tasks[3], Children: []*Node{}}}},
+					{Task: tasks[1], Children: []*Node{}},
+				},
+			}},
+			setDatabase: func(db *[]*Task) { *db = tasks },
+		},
+		{
+			title: "get the subtree of a leaf task",
+			input: input{id: "4"},
+			output: output{nd: &Node{Task: tasks[3], Children: []*Node{}}},
+			setDatabase: func(db *[]*Task) { *db = tasks },
+		},
+
+		// failure cases
+		{
+			title: "get the subtree of a task in the trash",
+			input: input{id: "5"},
+			output: output{err: ErrStorageNotFound, errMsg: "storage task not found: 5"},
+			setDatabase: func(db *[]*Task) { *db = tasks },
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db := []*Task{}
+			c.setDatabase(&db)
+
+			st := NewStorageLocal(db, NewValidatorMock(), nil)
+
+			// act
+			nd, err := st.Tree("p1", c.input.id)
+
+			// assert
+			assert.Equal(t, c.output.nd, nd)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestStorageLocal_AddDependency(t *testing.T) {
+	type input struct {id string; blockerId string}
+	type output struct {deps map[string][]string; err error; errMsg string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+		deps   map[string][]string
+	}
+
+	db := []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1")}, {ID: optional.Some("2"), OwnerID: optional.Some("p1")}, {ID: optional.Some("3"), OwnerID: optional.Some("p1")}, {ID: optional.Some("4"), OwnerID: optional.Some("p1"), DeletedAt: optional.Some(time.Unix(0, 0))}}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "add a dependency",
+			input: input{id: "1", blockerId: "2"},
+			output: output{deps: map[string][]string{"1": {"2", "3"}}},
+			deps: map[string][]string{"1": {"3"}},
+		},
+		{
+			title: "add a dependency the task already has",
+			input: input{id: "1", blockerId: "2"},
+			output: output{deps: map[string][]string{"1": {"2"}}},
+			deps: map[string][]string{"1": {"2"}},
+		},
+
+		// failure cases
+		{
+			title: "add a dependency on the task itself",
+			input: input{id: "1", blockerId: "1"},
+			output: output{
+				deps: map[string][]string{},
+				err: ErrStorageCycle,
+				errMsg: "storage invalid task: cycle: 1 blocked by 1",
+			},
+			deps: map[string][]string{},
+		},
+		{
+			title: "add a dependency that makes a cycle",
+			input: input{id: "1", blockerId: "3"},
+			output: output{
+				deps: map[string][]string{"3": {"2"}, "2": {"1"}},
+				err: ErrStorageCycle,
+				errMsg: "storage invalid task: cycle: 1 blocked by 3",
+			},
+			deps: map[string][]string{"3": {"2"}, "2": {"1"}},
+		},
+		{
+			title: "add a dependency on a task in the trash",
+			input: input{id: "1", blockerId: "4"},
+			output: output{
+				deps: map[string][]string{},
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: 4",
+			},
+			deps: map[string][]string{},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := NewStorageLocal(db, NewValidatorMock(), nil)
+			st.deps = c.deps
+
+			// act
+			err := st.AddDependency("p1", c.input.id, c.input.blockerId)
+
+			// assert
+			assert.Equal(t, c.output.deps, st.deps)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestStorageLocal_RemoveDependency(t *testing.T) {
+	type input struct {id string; blockerId string}
+	type output struct {deps map[string][]string; err error; errMsg string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+		deps   map[string][]string
+	}
+
+	db := []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1")}, {ID: optional.Some("2"), OwnerID: optional.Some("p1")}, {ID: optional.Some("3"), OwnerID: optional.Some("p1")}}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "remove a dependency",
+			input: input{id: "1", blockerId: "2"},
+			output: output{deps: map[string][]string{"1": {"3"}}},
+			deps: map[string][]string{"1": {"2", "3"}},
+		},
+
+		// failure cases
+		{
+			title: "remove a dependency the task does not have",
+			input: input{id: "1", blockerId: "2"},
+			output: output{
+				deps: map[string][]string{"1": {"3"}},
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: 1 blocker 2",
+			},
+			deps: map[string][]string{"1": {"3"}},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := NewStorageLocal(db, NewValidatorMock(), nil)
+			st.deps = c.deps
+
+			// act
+			err := st.RemoveDependency("p1", c.input.id, c.input.blockerId)
+
+			// assert
+			assert.Equal(t, c.output.deps, st.deps)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestStorageLocal_Order(t *testing.T) {
+	type input struct {ids []string}
+	type output struct {ts []*Task; err error; errMsg string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+	}
+
+	db := []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1")}, {ID: optional.Some("2"), OwnerID: optional.Some("p1")}, {ID: optional.Some("3"), OwnerID: optional.Some("p1")}, {ID: optional.Some("4"), OwnerID: optional.Some("p1")}}
+	// 1 <- 4 <- 2 (4 blocks 1 and 2 blocks 4)
+	deps := map[string][]string{"1": {"4"}, "4": {"2"}}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "order tasks linked through a task out of the set",
+			input: input{ids: []string{"1", "3", "2"}},
+			output: output{ts: []*Task{db[1], db[0], db[2]}},
+		},
+		{
+			title: "order no tasks",
+			input: input{ids: []string{}},
+			output: output{ts: []*Task{}},
+		},
+
+		// failure cases
+		{
+			title: "order a task that does not exist",
+			input: input{ids: []string{"1", "5"}},
+			output: output{
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: 5",
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := NewStorageLocal(db, NewValidatorMock(), nil)
+			st.deps = deps
+
+			// act
+			ts, err := st.Order("p1", c.input.ids)
+
+			// assert
+			assert.Equal(t, c.output.ts, ts)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestStorageLocal_Grant(t *testing.T) {
+	type input struct {id string; grant *Grant}
+	type output struct {grants map[string][]*Grant; err error; errMsg string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+		grants map[string][]*Grant
+	}
+
+	db := []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1")}, {ID: optional.Some("2"), OwnerID: optional.Some("p2")}}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "share a task",
+			input: input{id: "1", grant: &Grant{ProfileID: "p3", Permission: PermissionRead}},
+			output: output{grants: map[string][]*Grant{"1": {{ProfileID: "p2", Permission: PermissionEdit}, {ProfileID: "p3", Permission: PermissionRead}}}},
+			grants: map[string][]*Grant{"1": {{ProfileID: "p2", Permission: PermissionEdit}}},
+		},
+		{
+			title: "replace the permission of a profile",
+			input: input{id: "1", grant: &Grant{ProfileID: "p2", Permission: PermissionRead}},
+			output: output{grants: map[string][]*Grant{"1": {{ProfileID: "p2", Permission: PermissionRead}}}},
+			grants: map[string][]*Grant{"1": {{ProfileID: "p2", Permission: PermissionEdit}}},
+		},
+
+		// failure cases
+		{
+			title: "share a task of another profile",
+			input: input{id: "2", grant: &Grant{ProfileID: "p3", Permission: PermissionRead}},
+			output: output{
+				grants: map[string][]*Grant{},
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: 2",
+			},
+			grants: map[string][]*Grant{},
+		},
+		{
+			title: "share a task with its owner",
+			input: input{id: "1", grant: &Grant{ProfileID: "p1", Permission: PermissionRead}},
+			output: output{
+				grants: map[string][]*Grant{},
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: grant to the owner",
+			},
+			grants: map[string][]*Grant{},
+		},
+		{
+			title: "share a task with an unknown permission",
+			input: input{id: "1", grant: &Grant{ProfileID: "p2", Permission: "admin"}},
+			output: output{
+				grants: map[string][]*Grant{},
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: grant permission \"admin\"",
+			},
+			grants: map[string][]*Grant{},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := NewStorageLocal(db, NewValidatorMock(), nil)
+			st.grants = c.grants
+
+			// act
+			err := st.Grant("p1", c.input.id, c.input.grant)
+
+			// assert
+			assert.Equal(t, c.output.grants, st.grants)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestStorageLocal_Revoke(t *testing.T) {
+	type input struct {id string; granteeId string}
+	type output struct {grants map[string][]*Grant; err error; errMsg string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+		grants map[string][]*Grant
+	}
+
+	db := []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1")}}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "stop sharing a task",
+			input: input{id: "1", granteeId: "p2"},
+			output: output{grants: map[string][]*Grant{"1": {{ProfileID: "p3", Permission: PermissionRead}}}},
+			grants: map[string][]*Grant{"1": {{ProfileID: "p2", Permission: PermissionEdit}, {ProfileID: "p3", Permission: PermissionRead}}},
+		},
+
+		// failure cases
+		{
+			title: "stop sharing a task not shared with the profile",
+			input: input{id: "1", granteeId: "p2"},
+			output: output{
+				grants: map[string][]*Grant{"1": {{ProfileID: "p3", Permission: PermissionRead}}},
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: 1 grant p2",
+			},
+			grants: map[string][]*Grant{"1": {{ProfileID: "p3", Permission: PermissionRead}}},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := NewStorageLocal(db, NewValidatorMock(), nil)
+			st.grants = c.grants
+
+			// act
+			err := st.Revoke("p1", c.input.id, c.input.granteeId)
+
+			// assert
+			assert.Equal(t, c.output.grants, st.grants)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestStorageLocal_Grants(t *testing.T) {
+	type input struct {profileId string; id string}
+	type output struct {gs []*Grant; err error; errMsg string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+	}
+
+	db := []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1")}, {ID: optional.Some("2"), OwnerID: optional.Some("p1")}}
+	grants := map[string][]*Grant{"1": {{ProfileID: "p2", Permission: PermissionEdit}, {ProfileID: "p3", Permission: PermissionRead}}}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "grants of a task",
+			input: input{profileId: "p1", id: "1"},
+			output: output{gs: []*Grant{{ProfileID: "p2", Permission: PermissionEdit}, {ProfileID: "p3", Permission: PermissionRead}}},
+		},
+		{
+			title: "grants of a task not shared",
+			input: input{profileId: "p1", id: "2"},
+			output: output{gs: []*Grant{}},
+		},
+
+		// failure cases
+		{
+			title: "grants of a task shared with the profile",
+			input: input{profileId: "p2", id: "1"},
+			output: output{
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: 1",
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := NewStorageLocal(db, NewValidatorMock(), nil)
+			st.grants = grants
+
+			// act
+			gs, err := st.Grants(c.input.profileId, c.input.id)
+
+			// assert
+			assert.Equal(t, c.output.gs, gs)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestStorageLocal_Shared(t *testing.T) {
+	type input struct {query *Query}
+	type output struct {pg *Page; err error; errMsg string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+	}
+
+	deleted := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	db := []*Task{
+		{ID: optional.Some("1"), OwnerID: optional.Some("p2"), Title: optional.Some("title 1")},
+		{ID: optional.Some("2"), OwnerID: optional.Some("p2"), Title: optional.Some("title 2")},
+		{ID: optional.Some("3"), OwnerID: optional.Some("p3"), Title: optional.Some("title 3")},
+		{ID: optional.Some("4"), OwnerID: optional.Some("p3"), Title: optional.Some("title 4"), DeletedAt: optional.Some(deleted)},
+		{ID: optional.Some("5"), OwnerID: optional.Some("p1"), Title: optional.Some("title 5")},
+	}
+	grants := map[string][]*Grant{
+		"1": {{ProfileID: "p1", Permission: PermissionRead}},
+		"2": {{ProfileID: "p4", Permission: PermissionRead}},
+		"3": {{ProfileID: "p1", Permission: PermissionEdit}},
+		"4": {{ProfileID: "p1", Permission: PermissionEdit}},
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "tasks shared with the profile",
+			input: input{query: &Query{}},
+			output: output{pg: &Page{Tasks: []*Task{db[0], db[2]}, Next: optional.None[string]()}},
+		},
+		{
+			title: "filtered tasks shared with the profile",
+			input: input{query: &Query{Filter: Condition{Field: FieldTitle, Operator: OperatorEq, Value: "title 3"}}},
+			output: output{pg: &Page{Tasks: []*Task{db[2]}, Next: optional.None[string]()}},
+		},
+
+		// failure cases
+		{
+			title: "trash",
+			input: input{query: &Query{Deleted: true}},
+			output: output{
+				err: ErrStorageInvalidQuery,
+				errMsg: "storage invalid query: trash not shared",
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			st := NewStorageLocal(db, NewValidatorMock(), nil)
+			st.grants = grants
+
+			// act
+			pg, err := st.Shared("p1", c.input.query)
+
+			// assert
+			assert.Equal(t, c.output.pg, pg)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestStorageLocal_Batch(t *testing.T) {
+	type input struct {ops []*Op; mode Mode}
+	type output struct {db []*Task; errs []string; err error; errMsg string}
+	type testCase struct {
+		title		 string
+		input		 input
+		output		 output
+		setDatabase  func(db *[]*Task)
+	}
+
+	now := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "apply a batch atomically",
+			input: input{
+				ops: []*Op{
+					{Kind: OpCreate, Task: &Task{Title: optional.Some("new task")}},
+					{Kind: OpUpdate, Task: &Task{ID: optional.Some("1"), Title: optional.Some("new title")}},
+					{Kind: OpDelete, ID: "2"},
+				},
+				mode: ModeAtomic,
+			},
+			output: output{
+				db: []*Task{
+					{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("new title"), UpdatedAt: optional.Some(now), Version: optional.Some(2)},
+					{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Title: optional.Some("title"), DeletedAt: optional.Some(now), Version: optional.Some(1)},
+					{ID: optional.Some("3"), OwnerID: optional.Some("p1"), Title: optional.Some("new task"), CreatedAt: optional.Some(now), UpdatedAt: optional.Some(now), Version: optional.Some(1), Rank: optional.Some("i")},
+				},
+				errs: []string{"", "", ""},
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{
+					{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title"), Version: optional.Some(1)},
+					{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Title: optional.Some("title"), Version: optional.Some(1)},
+				}
+			},
+		},
+		{
+			title: "apply a batch with best effort",
+			input: input{
+				ops: []*Op{
+					{Kind: OpUpdate, Task: &Task{ID: optional.Some("9"), Title: optional.Some("new title")}},
+					{Kind: OpDelete, ID: "2"},
+				},
+				mode: ModeBestEffort,
+			},
+			output: output{
+				db: []*Task{
+					{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title"), Version: optional.Some(1)},
+					{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Title: optional.Some("title"), DeletedAt: optional.Some(now), Version: optional.Some(1)},
+				},
+				errs: []string{"storage task not found: 9", ""},
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{
+					{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title"), Version: optional.Some(1)},
+					{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Title: optional.Some("title"), Version: optional.Some(1)},
+				}
+			},
+		},
+
+		// failure cases
+		{
+			title: "abort an atomic batch with an operation that fails",
+			input: input{
+				ops: []*Op{
+					{Kind: OpCreate, Task: &Task{Title: optional.Some("new task")}},
+					{Kind: OpDelete, ID: "2"},
+					{Kind: OpUpdate, Task: &Task{ID: optional.Some("1"), Title: optional.Some("new title"), Version: optional.Some(3)}},
+					{Kind: OpDelete, ID: "1"},
+				},
+				mode: ModeAtomic,
+			},
+			output: output{
+				db: []*Task{
+					{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title"), Version: optional.Some(1)},
+					{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Title: optional.Some("title"), Labels: []string{"backend"}, Version: optional.Some(1)},
+				},
+				errs: []string{"storage batch aborted", "storage batch aborted", "storage task version mismatch: 1 3", "storage batch aborted"},
+				err: ErrStorageBatchAborted,
+				errMsg: "storage batch aborted: operation 2: storage task version mismatch: 1 3",
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{
+					{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title"), Version: optional.Some(1)},
+					{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Title: optional.Some("title"), Labels: []string{"backend"}, Version: optional.Some(1)},
+				}
+			},
+		},
+		{
+			title: "batch with an unknown mode",
+			input: input{ops: []*Op{{Kind: OpDelete, ID: "1"}}, mode: "eventually"},
+			output: output{
+				db: []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1")}},
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: batch mode eventually",
+			},
+			setDatabase: func(db *[]*Task) {
+				*db = []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1")}}
+			},
+		},
+		{
+			title: "batch with too many operations",
+			input: input{ops: make([]*Op, MaxBatch + 1), mode: ModeAtomic},
+			output: output{
+				db: []*Task{},
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: batch of 101 operations (max 100)",
+			},
+			setDatabase: func(db *[]*Task) {},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db := []*Task{}
+			c.setDatabase(&db)
+
+			vl := NewValidatorMock()
+			vl.On("Validate", mock.Anything).Return(nil)
+
+			st := NewStorageLocal(db, vl, nil)
+			st.now = func() time.Time { return now }
+			st.newId = func() string { return "3" }
+
+			// act
+			rs, err := st.Batch("p1", c.input.ops, c.input.mode)
+
+			// assert
+			assert.Equal(t, c.output.db, st.db)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			var errs []string
+			for _, r := range rs {
+				if r.Err != nil {
+					errs = append(errs, r.Err.Error())
+					continue
+				}
+				errs = append(errs, "")
+			}
+			assert.Equal(t, c.output.errs, errs)
+		})
+	}
+}
+
+func TestStorageLocal_Move(t *testing.T) {
+	type input struct {id string; afterId string; beforeId string; version optional.Option[int]}
+	type output struct {rank string; version int; err error; errMsg string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "move a task between two tasks",
+			input: input{id: "3", afterId: "1", beforeId: "2"},
+			output: output{rank: "d", version: 2},
+		},
+		{
+			title: "move a task to the top",
+			input: input{id: "3", beforeId: "1"},
+			output: output{rank: "4", version: 2},
+		},
+		{
+			title: "move a task right after another one",
+			input: input{id: "1", afterId: "2"},
+			output: output{rank: "m", version: 2},
+		},
+		{
+			title: "move a task at its version",
+			input: input{id: "1", afterId: "2", version: optional.Some(1)},
+			output: output{rank: "m", version: 2},
+		},
+		{
+			title: "move a task to the bottom (before the tasks in the trash)",
+			input: input{id: "1", afterId: "3"},
+			output: output{rank: "s", version: 2},
+		},
+
+		// failure cases
+		{
+			title: "move a task without neighbours",
+			input: input{id: "1"},
+			output: output{
+				rank: "9",
+				version: 1,
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: 1 neighbour required",
+			},
+		},
+		{
+			title: "move a task at another version",
+			input: input{id: "1", afterId: "2", version: optional.Some(2)},
+			output: output{
+				rank: "9",
+				version: 1,
+				err: ErrStorageVersionMismatch,
+				errMsg: "storage task version mismatch: 1 2",
+			},
+		},
+		{
+			title: "move a task of another profile",
+			input: input{id: "4", afterId: "1"},
+			output: output{
+				rank: "i",
+				version: 1,
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: 4",
+			},
+		},
+		{
+			title: "move a task next to a task of another profile",
+			input: input{id: "1", afterId: "4"},
+			output: output{
+				rank: "9",
+				version: 1,
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: neighbour 4 not found",
+			},
+		},
+		{
+			title: "move a task next to a task in the trash",
+			input: input{id: "1", afterId: "5"},
+			output: output{
+				rank: "9",
+				version: 1,
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: neighbour 5 not found",
+			},
+		},
+		{
+			title: "move a task next to a task without rank",
+			input: input{id: "1", beforeId: "6"},
+			output: output{
+				rank: "9",
+				version: 1,
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: neighbour 6 not ranked",
+			},
+		},
+		{
+			title: "move a task next to itself",
+			input: input{id: "1", afterId: "1"},
+			output: output{
+				rank: "9",
+				version: 1,
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: 1 next to itself",
+			},
+		},
+		{
+			title: "move a task between neighbours out of order",
+			input: input{id: "1", afterId: "3", beforeId: "2"},
+			output: output{
+				rank: "9",
+				version: 1,
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: rank \"r\" not before \"i\"",
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db := []*Task{
+				{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Rank: optional.Some("9"), Version: optional.Some(1)},
+				{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Rank: optional.Some("i"), Version: optional.Some(1)},
+				{ID: optional.Some("3"), OwnerID: optional.Some("p1"), Rank: optional.Some("r"), Version: optional.Some(1)},
+				{ID: optional.Some("4"), OwnerID: optional.Some("p2"), Rank: optional.Some("i"), Version: optional.Some(1)},
+				{ID: optional.Some("5"), OwnerID: optional.Some("p1"), Rank: optional.Some("u"), Version: optional.Some(1), DeletedAt: optional.Some(time.Unix(0, 0))},
+				{ID: optional.Some("6"), OwnerID: optional.Some("p1"), Version: optional.Some(1)},
+			}
+			st := NewStorageLocal(db, NewValidatorMock(), nil)
+
+			// act
+			err := st.Move("p1", c.input.id, c.input.afterId, c.input.beforeId, c.input.version)
+
+			// assert
+			ts := st.lookup(c.input.id)
+			rank, _ := ts.Rank.Unwrap()
+			version, _ := ts.Version.Unwrap()
+			assert.Equal(t, c.output.rank, rank)
+			assert.Equal(t, c.output.version, version)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestStorageLocal_Rebalance(t *testing.T) {
+	// arrange
+	db := []*Task{
+		{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Rank: optional.Some("iiiiiiiiiiiii"), Version: optional.Some(1)},
+		{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Rank: optional.Some("i"), Version: optional.Some(1)},
+		{ID: optional.Some("3"), OwnerID: optional.Some("p1"), Version: optional.Some(1), DeletedAt: optional.Some(time.Unix(0, 0))},
+		{ID: optional.Some("4"), OwnerID: optional.Some("p2"), Rank: optional.Some("ii"), Version: optional.Some(1)},
+	}
+	st := NewStorageLocal(db, NewValidatorMock(), nil)
+
+	// act
+	n, err := st.Rebalance()
+
+	// assert
+	// -> the order is kept (without rank first), only for the profiles with a task to rank again
+	ranks := make(map[string]string)
+	for _, ts := range st.db {
+		id, _ := ts.ID.Unwrap()
+		ranks[id], _ = ts.Rank.Unwrap()
+		assert.Equal(t, optional.Some(1), ts.Version)
+	}
+	assert.Equal(t, map[string]string{"1": "r", "2": "i", "3": "9", "4": "ii"}, ranks)
+	assert.Equal(t, 3, n)
+	assert.NoError(t, err)
+}
+
+func TestStorageLocal_AddItem(t *testing.T) {
+	type input struct {profileId string; id string; item *Item}
+	type output struct {checklist []Item; version int; err error; errMsg string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+		setValidator func(mk *ValidatorMock)
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "add an item",
+			input: input{profileId: "p1", id: "1", item: &Item{Text: "bread"}},
+			output: output{checklist: []Item{{ID: "a", Text: "milk"}, {ID: "b", Text: "bread"}}, version: 2},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+		{
+			title: "add an item to a task shared with edit permission",
+			input: input{profileId: "p2", id: "1", item: &Item{Text: "bread", Checked: true}},
+			output: output{checklist: []Item{{ID: "a", Text: "milk"}, {ID: "b", Text: "bread", Checked: true}}, version: 2},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+
+		// failure cases
+		{
+			title: "invalid item",
+			input: input{profileId: "p1", id: "1", item: &Item{Text: ""}},
+			output: output{
+				checklist: []Item{{ID: "a", Text: "milk"}},
+				version: 1,
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: validator field empty: item",
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.Anything).Return(fmt.Errorf("%w: item", ErrValidatorFieldEmpty))
+			},
+		},
+		{
+			title: "task shared with read permission",
+			input: input{profileId: "p3", id: "1", item: &Item{Text: "bread"}},
+			output: output{
+				checklist: []Item{{ID: "a", Text: "milk"}},
+				version: 1,
+				err: ErrStorageForbidden,
+				errMsg: "storage task forbidden: 1 edit",
+			},
+			setValidator: func(mk *ValidatorMock) {},
+		},
+		{
+			title: "task not found",
+			input: input{profileId: "p4", id: "1", item: &Item{Text: "bread"}},
+			output: output{
+				checklist: []Item{{ID: "a", Text: "milk"}},
+				version: 1,
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: 1",
+			},
+			setValidator: func(mk *ValidatorMock) {},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db := []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Version: optional.Some(1), Checklist: []Item{{ID: "a", Text: "milk"}}}}
+			vl := NewValidatorMock()
+			c.setValidator(vl)
+			st := NewStorageLocal(db, vl, nil)
+			st.newId = func() string { return "b" }
+			st.grants = map[string][]*Grant{"1": {{ProfileID: "p2", Permission: PermissionEdit}, {ProfileID: "p3", Permission: PermissionRead}}}
+
+			// act
+			err := st.AddItem(c.input.profileId, c.input.id, c.input.item, optional.None[int]())
+
+			// assert
+			version, _ := st.db[0].Version.Unwrap()
+			assert.Equal(t, c.output.checklist, st.db[0].Checklist)
+			assert.Equal(t, c.output.version, version)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+				return
+			}
+			assert.Equal(t, "b", c.input.item.ID)
+			vl.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStorageLocal_EditItem(t *testing.T) {
+	type output struct {checklist []Item; version int; err error; errMsg string}
+	type testCase struct {
+		title  string
+		edit   func(st *StorageLocal) (err error)
+		output output
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "check an item",
+			edit: func(st *StorageLocal) error { return st.CheckItem("p1", "1", "b", true, optional.None[int]()) },
+			output: output{checklist: []Item{{ID: "a", Text: "milk", Checked: true}, {ID: "b", Text: "bread", Checked: true}, {ID: "c", Text: "eggs"}}, version: 2},
+		},
+		{
+			title: "uncheck an item",
+			edit: func(st *StorageLocal) error { return st.CheckItem("p1", "1", "a", false, optional.None[int]()) },
+			output: output{checklist: []Item{{ID: "a", Text: "milk"}, {ID: "b", Text: "bread"}, {ID: "c", Text: "eggs"}}, version: 2},
+		},
+		{
+			title: "move an item",
+			edit: func(st *StorageLocal) error { return st.MoveItem("p1", "1", "c", 0, optional.None[int]()) },
+			output: output{checklist: []Item{{ID: "c", Text: "eggs"}, {ID: "a", Text: "milk", Checked: true}, {ID: "b", Text: "bread"}}, version: 2},
+		},
+		{
+			title: "check an item of a task with the version",
+			edit: func(st *StorageLocal) error { return st.CheckItem("p1", "1", "b", true, optional.Some(1)) },
+			output: output{checklist: []Item{{ID: "a", Text: "milk", Checked: true}, {ID: "b", Text: "bread", Checked: true}, {ID: "c", Text: "eggs"}}, version: 2},
+		},
+		{
+			title: "remove an item",
+			edit: func(st *StorageLocal) error { return st.RemoveItem("p1", "1", "a", optional.None[int]()) },
+			output: output{checklist: []Item{{ID: "b", Text: "bread"}, {ID: "c", Text: "eggs"}}, version: 2},
+		},
+
+		// failure cases
+		{
+			title: "check an item not found",
+			edit: func(st *StorageLocal) error { return st.CheckItem("p1", "1", "d", true, optional.None[int]()) },
+			output: output{
+				checklist: []Item{{ID: "a", Text: "milk", Checked: true}, {ID: "b", Text: "bread"}, {ID: "c", Text: "eggs"}},
+				version: 1,
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: item d",
+			},
+		},
+		{
+			title: "move an item out of the checklist",
+			edit: func(st *StorageLocal) error { return st.MoveItem("p1", "1", "a", 3, optional.None[int]()) },
+			output: output{
+				checklist: []Item{{ID: "a", Text: "milk", Checked: true}, {ID: "b", Text: "bread"}, {ID: "c", Text: "eggs"}},
+				version: 1,
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: item position 3",
+			},
+		},
+		{
+			title: "remove an item of a task of another profile",
+			edit: func(st *StorageLocal) error { return st.RemoveItem("p2", "1", "a", optional.None[int]()) },
+			output: output{
+				checklist: []Item{{ID: "a", Text: "milk", Checked: true}, {ID: "b", Text: "bread"}, {ID: "c", Text: "eggs"}},
+				version: 1,
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: 1",
+			},
+		},
+		{
+			title: "check an item of a task with another version",
+			edit: func(st *StorageLocal) error { return st.CheckItem("p1", "1", "b", true, optional.Some(2)) },
+			output: output{
+				checklist: []Item{{ID: "a", Text: "milk", Checked: true}, {ID: "b", Text: "bread"}, {ID: "c", Text: "eggs"}},
+				version: 1,
+				err: ErrStorageVersionMismatch,
+				errMsg: "storage task version mismatch: 1 2",
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db := []*Task{{
+				ID: optional.Some("1"),
+				OwnerID: optional.Some("p1"),
+				Version: optional.Some(1),
+				Checklist: []Item{{ID: "a", Text: "milk", Checked: true}, {ID: "b", Text: "bread"}, {ID: "c", Text: "eggs"}},
+			}}
+			vl := NewValidatorMock()
+			vl.On("Validate", mock.Anything).Return(nil)
+			st := NewStorageLocal(db, vl, nil)
+
+			// act
+			err := c.edit(st)
+
+			// assert
+			version, _ := st.db[0].Version.Unwrap()
+			assert.Equal(t, c.output.checklist, st.db[0].Checklist)
+			assert.Equal(t, c.output.version, version)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestStorageLocal_Snapshot(t *testing.T) {
+	// arrange
+	db := []*Task{
+		{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title 1"), Version: optional.Some(1), Labels: []string{"backend"}},
+		{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Title: optional.Some("title 2"), Version: optional.Some(1), DeletedAt: optional.Some(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))},
+	}
+	vl := NewValidatorMock()
+	vl.On("Validate", mock.Anything).Return(nil)
+	st := NewStorageLocal(db, vl, nil)
+
+	// act
+	snapshot := st.Snapshot()
+	err := st.AddLabel("p1", "1", "urgent", optional.None[int]())
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, snapshot, 2)
+	assert.Equal(t, []string{"backend"}, snapshot[0].Labels)
+	assert.Equal(t, optional.Some(1), snapshot[0].Version)
+	assert.Equal(t, []string{"backend", "urgent"}, st.db[0].Labels)
+}
+
+func TestStorageLocal_Index(t *testing.T) {
+	// arrange
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	db := []*Task{
+		{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title 1"), DeletedAt: optional.Some(now.AddDate(0, 0, -5))},
+		{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Title: optional.Some("title 2")},
+	}
+	vl := NewValidatorMock()
+	vl.On("Validate", mock.Anything).Return(nil)
+	st := NewStorageLocal(db, vl, nil)
+	st.newId = func() string { return "3" }
+
+	// act
+	_, errPurge := st.Purge(now)
+	errSave := st.Save(&Task{OwnerID: optional.Some("p1"), Title: optional.Some("title 3")})
+	ts2, errGet2 := st.Get("p1", "2")
+	ts3, errGet3 := st.Get("p1", "3")
+	_, errGet1 := st.Get("p1", "1")
+
+	// assert
+	assert.NoError(t, errPurge)
+	assert.NoError(t, errSave)
+	assert.NoError(t, errGet2)
+	assert.Equal(t, optional.Some("title 2"), ts2.Title)
+	assert.NoError(t, errGet3)
+	assert.Equal(t, optional.Some("title 3"), ts3.Title)
+	assert.ErrorIs(t, errGet1, ErrStorageNotFound)
+	assert.Equal(t, map[string]int{"2": 0, "3": 1}, st.ids)
+}
+
+func TestStorageLocal_Concurrent(t *testing.T) {
+	// run with the race detector (go test -race) to catch unguarded accesses
+	const n = 50
+
+	// arrange
+	db := []*Task{{ID: optional.Some("0"), OwnerID: optional.Some("p1"), Title: optional.Some("shared"), Status: optional.Some(StatusTodo), Version: optional.Some(1)}}
+	st := NewStorageLocal(db, NewValidatorLocal(nil), nil)
+
+	// act
+	var wg sync.WaitGroup
+	ids := make([]string, n)
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(3)
+		// writers: save a task and read it back
+		go func(i int) {
+			defer wg.Done()
+			ts := &Task{OwnerID: optional.Some("p1"), Title: optional.Some(fmt.Sprintf("title %d", i)), Status: optional.Some(StatusTodo)}
+			errs[i] = st.Save(ts)
+			if errs[i] != nil {
+				return
+			}
+			ids[i], _ = ts.ID.Unwrap()
+			_, errs[i] = st.Get("p1", ids[i])
+		}(i)
+		// writers: change the same task
+		go func(i int) {
+			defer wg.Done()
+			_ = st.AddLabel("p1", "0", fmt.Sprintf("label-%d", i%10), optional.None[int]())
+		}(i)
+		// readers: list the tasks and get the same task
+		go func() {
+			defer wg.Done()
+			pg, err := st.List("p1", &Query{})
+			if err == nil {
+				for _, ts := range pg.Tasks {
+					_, _ = ts.Title.Unwrap()
+				}
+			}
+			_, _ = st.Get("p1", "0")
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	for i := 0; i < n; i++ {
+		assert.NoError(t, errs[i])
+		ts, err := st.Get("p1", ids[i])
+		assert.NoError(t, err)
+		assert.Equal(t, optional.Some(fmt.Sprintf("title %d", i)), ts.Title)
+	}
+	assert.Len(t, st.Snapshot(), n+1)
+	ts, err := st.Get("p1", "0")
+	assert.NoError(t, err)
+	assert.Len(t, ts.Labels, 10)
+}
+
+func TestStorageLocal_Readers(t *testing.T) {
+	// arrange
+	db := []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title"), Status: optional.Some(StatusTodo), Version: optional.Some(1)}}
+	st := NewStorageLocal(db, NewValidatorLocal(nil), nil)
+
+	// a reader that holds the lock (a long listing)
+	st.mu.RLock()
+	done := make(chan error)
+	go func() {
+		_, err := st.Get("p1", "1")
+		done <- err
+	}()
+
+	// act
+	// -> another reader does not wait for it
+	var err error
+	select {
+	case err = <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the reader waited for the other reader")
+	}
+	st.mu.RUnlock()
+
+	// assert
+	assert.NoError(t, err)
+}
+
+func TestStorageLocal_Undo(t *testing.T) {
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	type testCase struct {
+		title  string
+		change func(st *StorageLocal) error
+	}
+
+	cases := []testCase{
+		{
+			title: "save",
+			change: func(st *StorageLocal) error {
+				return st.Save(&Task{OwnerID: optional.Some("p1"), Title: optional.Some("title 4"), Status: optional.Some(StatusTodo)})
+			},
+		},
+		{
+			title: "update that completes the subtasks",
+			change: func(st *StorageLocal) error {
+				return st.Update("p1", &Task{ID: optional.Some("2"), Title: optional.Some("title 2"), Status: optional.Some(StatusDone), Version: optional.Some(1)})
+			},
+		},
+		{
+			title: "purge of a task with subtasks, dependencies and grants",
+			change: func(st *StorageLocal) error {
+				_, err := st.Purge(now)
+				return err
+			},
+		},
+		{
+			title: "grant",
+			change: func(st *StorageLocal) error {
+				return st.Grant("p1", "2", &Grant{ProfileID: "p2", Permission: PermissionEdit})
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db := []*Task{
+				{ID: optional.Some("1"), OwnerID: optional.Some("p1"), Title: optional.Some("title 1"), Status: optional.Some(StatusTodo), Version: optional.Some(1), DeletedAt: optional.Some(now.AddDate(0, 0, -1))},
+				{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Title: optional.Some("title 2"), Status: optional.Some(StatusTodo), Version: optional.Some(1)},
+				{ID: optional.Some("3"), OwnerID: optional.Some("p1"), ParentID: optional.Some("2"), Title: optional.Some("title 3"), Status: optional.Some(StatusTodo), Version: optional.Some(1)},
+				{ID: optional.Some("5"), OwnerID: optional.Some("p1"), ParentID: optional.Some("1"), Title: optional.Some("title 5"), Status: optional.Some(StatusTodo), Version: optional.Some(1)},
+			}
+			st := NewStorageLocal(db, NewValidatorLocal(nil), &Config{Hierarchy: HierarchyCascade})
+			st.deps = map[string][]string{"1": {"2"}, "2": {"1"}, "3": {"1", "2"}}
+			st.grants = map[string][]*Grant{"1": {{ProfileID: "p2", Permission: PermissionRead}}, "2": {{ProfileID: "p2", Permission: PermissionRead}}}
+			st.newId = func() string { return "4" }
+			st.now = func() time.Time { return now }
+			db, deps, grants := st.Snapshot(), copyDeps(st.deps), copyAllGrants(st.grants)
+			st.commit = func(ims []*image) error { return ErrStorageInternal }
+
+			// act
+			err := c.change(st)
+
+			// assert
+			// -> the change that could not be committed is undone
+			assert.ErrorIs(t, err, ErrStorageInternal)
+			assert.Equal(t, db, st.Snapshot())
+			assert.Equal(t, deps, st.deps)
+			assert.Equal(t, grants, st.grants)
+			assert.Equal(t, map[string]int{"1": 0, "2": 1, "3": 2, "5": 3}, st.ids)
+		})
+	}
+}
+
+func TestStorageLocal_Recording(t *testing.T) {
+	type output struct {ws []string; err error}
+	type testCase struct {
+		title  string
+		change func(st *StorageLocal) error
+		output output
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "update that completes the subtasks",
+			change: func(st *StorageLocal) error {
+				return st.Update("p1", &Task{ID: optional.Some("2"), Title: optional.Some("title 2"), Status: optional.Some(StatusDone)})
+			},
+			output: output{ws: []string{"p1 3: title 3 todo -> title 3 done", "p1 2: title 2 todo -> title 2 done"}},
+		},
+		{
+			title: "save",
+			change: func(st *StorageLocal) error {
+				return st.Save(&Task{OwnerID: optional.Some("p2"), Title: optional.Some("title 4"), Status: optional.Some(StatusTodo)})
+			},
+			output: output{ws: []string{"p2 4: created -> title 4 todo"}},
+		},
+		{
+			title: "add a label",
+			change: func(st *StorageLocal) error {
+				return st.AddLabel("p1", "2", "backend", optional.None[int]())
+			},
+			output: output{ws: []string{"p1 2: title 2 todo -> title 2 todo"}},
+		},
+		{
+			title: "move to the trash",
+			change: func(st *StorageLocal) error {
+				return st.Delete("p1", "2", optional.None[int]())
+			},
+			// -> handed as any write (the history finds no recorded field changed)
+			output: output{ws: []string{"p1 2: title 2 todo -> title 2 todo"}},
+		},
+		{
+			title: "atomic batch that updates a task twice",
+			change: func(st *StorageLocal) error {
+				_, err := st.Batch("p1", []*Op{
+					{Kind: OpUpdate, Task: &Task{ID: optional.Some("2"), Title: optional.Some("title a"), Status: optional.Some(StatusTodo)}},
+					{Kind: OpUpdate, Task: &Task{ID: optional.Some("2"), Title: optional.Some("title b"), Status: optional.Some(StatusTodo)}},
+				}, ModeAtomic)
+				return err
+			},
+			// -> each operation has its own changes
+			output: output{ws: []string{"p1 2: title 2 todo -> title a todo", "p1 2: title a todo -> title b todo"}},
+		},
+		{
+			title: "changes of the storage itself",
+			change: func(st *StorageLocal) error {
+				_, err := st.Rebalance()
+				return err
+			},
+		},
+
+		// failure cases
+		{
+			title: "update of a task that does not exist",
+			change: func(st *StorageLocal) error {
+				return st.Update("p1", &Task{ID: optional.Some("9"), Title: optional.Some("title 9"), Status: optional.Some(StatusTodo)})
+			},
+			output: output{err: ErrStorageNotFound},
+		},
+		{
+			title: "aborted batch",
+			change: func(st *StorageLocal) error {
+				_, err := st.Batch("p1", []*Op{
+					{Kind: OpUpdate, Task: &Task{ID: optional.Some("2"), Title: optional.Some("title a"), Status: optional.Some(StatusTodo)}},
+					{Kind: OpUpdate, Task: &Task{ID: optional.Some("9"), Title: optional.Some("title b"), Status: optional.Some(StatusTodo)}},
+				}, ModeAtomic)
+				return err
+			},
+			output: output{err: ErrStorageBatchAborted},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db := []*Task{
+				{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Title: optional.Some("title 2"), Status: optional.Some(StatusTodo)},
+				{ID: optional.Some("3"), OwnerID: optional.Some("p1"), ParentID: optional.Some("2"), Title: optional.Some("title 3"), Status: optional.Some(StatusTodo)},
+			}
+			st := NewStorageLocal(db, NewValidatorLocal(nil), &Config{Hierarchy: HierarchyCascade})
+			st.newId = func() string { return "4" }
+			var ws []string
+			st.recording(func(ctx context.Context, tx preparer, profileId string, w []*write) error {
+				for _, w := range w {
+					id, _ := w.after.ID.Unwrap()
+					ws = append(ws, fmt.Sprintf("%s %s: %s -> %s", profileId, id, describe(w.before), describe(w.after)))
+				}
+				return nil
+			})
+
+			// act
+			err := c.change(st)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			assert.Equal(t, c.output.ws, ws)
+		})
+	}
+}
+
+func TestStorageLocal_RecordingFails(t *testing.T) {
+	// arrange
+	db := []*Task{
+		{ID: optional.Some("2"), OwnerID: optional.Some("p1"), Title: optional.Some("title 2"), Status: optional.Some(StatusTodo)},
+		{ID: optional.Some("3"), OwnerID: optional.Some("p1"), ParentID: optional.Some("2"), Title: optional.Some("title 3"), Status: optional.Some(StatusTodo)},
+	}
+	before := []*Task{clone(db[0]), clone(db[1])}
+	st := NewStorageLocal(db, NewValidatorLocal(nil), &Config{Hierarchy: HierarchyCascade})
+	st.recording(func(ctx context.Context, tx preparer, profileId string, ws []*write) error { return ErrStorageInternal })
+
+	// act
+	err := st.Update("p1", &Task{ID: optional.Some("2"), Title: optional.Some("title 2"), Status: optional.Some(StatusDone)})
+
+	// assert
+	// -> the write that could not be recorded is undone, the subtasks completed in cascade too
+	assert.ErrorIs(t, err, ErrStorageInternal)
+	assert.Equal(t, before, st.db)
+}
+
+// describe returns the title and the status of the task ("created" if there is no task).
+func describe(ts *Task) string {
+	if ts == nil {
+		return "created"
+	}
+	title, _ := ts.Title.Unwrap()
+	status, _ := ts.Status.Unwrap()
+	return fmt.Sprintf("%s %s", title, status)
+}
+
+// copyDeps returns a copy of the dependencies.
+func copyDeps(deps map[string][]string) map[string][]string {
+	cp := make(map[string][]string, len(deps))
+	for id, blockers := range deps {
+		cp[id] = append([]string(nil), blockers...)
+	}
+	return cp
+}
+
+// copyAllGrants returns a copy of the grants of every task.
+func copyAllGrants(grants map[string][]*Grant) map[string][]*Grant {
+	cp := make(map[string][]*Grant, len(grants))
+	for id, gs := range grants {
+		cp[id] = copyGrants(gs)
+	}
+	return cp
+}
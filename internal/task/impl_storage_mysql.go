@@ -1,44 +1,362 @@
 package task
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"api/pkg/mysql/transactioner"
 
 	"github.com/LNMMusic/optional"
 	"github.com/google/uuid"
 )
 
 // constructor
-func NewStorageMySQL(db *sql.DB, vl Validator) *StorageMySQL {
-	return &StorageMySQL{db: db, vl: vl}
+// - cfg is optional (nil for the default config)
+func NewStorageMySQL(db *sql.DB, vl Validator, cfg *Config) *StorageMySQL {
+	return &StorageMySQL{db: db, tr: transactioner.NewImplTransactionerDefault(db), ctx: context.Background(), vl: vl, cfg: newConfig(cfg)}
 }
 
 // StorageMySQL is an implementation with MySQL of the Storage interface.
+// - times are scanned as time (parseTime=true on the dsn) and stored in UTC
+const (
+	QueryGetTask = querySelectTask + columnLabels + queryFromGetTask
+	// -> completed with the where, order by and limit clauses of the query
+	QueryListTasks = querySelectTask + columnLabels + ` FROM tasks`
+	QuerySaveTask = `INSERT INTO tasks (id, owner_id, title, description, status, parent_id, start_at, due_at, created_at, updated_at, recurrence, series_id, occurrence, project_id, rank_key, checklist, version) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 1)`
+	// -> rows affected must count the matched rows (clientFoundRows=true on the dsn)
+	QueryUpdateTask = `UPDATE tasks SET title = ?, description = ?, status = ?, parent_id = ?, start_at = ?, due_at = ?, updated_at = ?, recurrence = ?, series_id = ?, occurrence = ?, project_id = ?, version = version + 1 WHERE id = ? AND version = ? AND deleted_at IS NULL`
+	// -> the owner, the permission granted to the profile, the status, the series, the rank, the checklist and the creation and deletion times of the task, locked until the end of the transaction
+	QueryGetTaskState = queryGetTaskState + lockMySQL
+	QueryDeleteTask = `UPDATE tasks SET deleted_at = ? WHERE id = ? AND owner_id = ? AND deleted_at IS NULL`
+	QueryRestoreTask = `UPDATE tasks SET deleted_at = NULL WHERE id = ? AND owner_id = ? AND deleted_at IS NOT NULL`
+	// -> the writes conditional on a version lock the task until the end of their transaction
+	QueryGetTaskVersion = queryGetTaskVersion + lockMySQL
+	QueryPurgeTasks = `DELETE FROM tasks WHERE deleted_at IS NOT NULL AND deleted_at < ?`
+	// labels: many to many relation on the task_labels join table (task_id, label), removed on cascade with the task
+	// -> a label change increases the version of the task
+	QueryTouchTask = `UPDATE tasks SET version = version + 1 WHERE id = ?`
+	QuerySaveTaskLabel = `INSERT IGNORE INTO task_labels (task_id, label) VALUES (?, ?)`
+	QueryClearTaskLabels = `DELETE FROM task_labels WHERE task_id = ?`
+	QueryRemoveTaskLabel = `DELETE task_labels FROM task_labels JOIN tasks ON tasks.id = task_labels.task_id WHERE task_labels.task_id = ? AND task_labels.label = ? AND tasks.deleted_at IS NULL`
+	QueryListLabels = `SELECT task_labels.label, COUNT(*) FROM task_labels JOIN tasks ON tasks.id = task_labels.task_id WHERE tasks.owner_id = ? AND tasks.deleted_at IS NULL GROUP BY task_labels.label ORDER BY task_labels.label`
+	// hierarchy: parent_id references tasks (id) on delete set null
+	// -> the amount of ancestors from the parent (zero if it does not exist or it has another owner) and how many of them are the task
+	QueryTaskAncestors = `WITH RECURSIVE ancestors (id, parent_id) AS (SELECT id, parent_id FROM tasks WHERE id = ? AND owner_id = ? UNION ALL SELECT tasks.id, tasks.parent_id FROM tasks JOIN ancestors ON tasks.id = ancestors.parent_id) SELECT COUNT(*), COALESCE(SUM(id = ?), 0) FROM ancestors`
+	QueryCountOpenChildren = `SELECT COUNT(*) FROM tasks WHERE parent_id = ? AND deleted_at IS NULL AND status NOT IN ('done', 'archived')`
+	QueryCompleteDescendants = `WITH RECURSIVE descendants (id) AS (SELECT id FROM tasks WHERE parent_id = ? AND deleted_at IS NULL UNION ALL SELECT tasks.id FROM tasks JOIN descendants ON tasks.parent_id = descendants.id WHERE tasks.deleted_at IS NULL) UPDATE tasks JOIN descendants ON tasks.id = descendants.id SET tasks.status = 'done', tasks.updated_at = ?, tasks.version = tasks.version + 1 WHERE tasks.status NOT IN ('done', 'archived')`
+	// dependencies: task_dependencies join table (task_id, blocker_id), both referencing tasks (id) on delete cascade
+	QuerySaveTaskDependency = `INSERT IGNORE INTO task_dependencies (task_id, blocker_id) VALUES (?, ?)`
+	QueryRemoveTaskDependency = `DELETE task_dependencies FROM task_dependencies JOIN tasks ON tasks.id = task_dependencies.task_id WHERE task_dependencies.task_id = ? AND task_dependencies.blocker_id = ? AND tasks.owner_id = ? AND tasks.deleted_at IS NULL`
+	// -> the owner of the task, locked until the end of the transaction
+	QueryGetTaskOwner = queryGetTaskOwner + lockMySQL
+	// -> how many times the task is among the blockers of the blocker, directly or not
+	QueryTaskBlockers = `WITH RECURSIVE blockers (id) AS (SELECT blocker_id FROM task_dependencies WHERE task_id = ? UNION SELECT task_dependencies.blocker_id FROM task_dependencies JOIN blockers ON task_dependencies.task_id = blockers.id) SELECT COUNT(*) FROM blockers WHERE id = ?`
+	QueryCountOpenBlockers = `SELECT COUNT(*) FROM task_dependencies JOIN tasks ON tasks.id = task_dependencies.blocker_id WHERE task_dependencies.task_id = ? AND tasks.deleted_at IS NULL AND tasks.status NOT IN ('done', 'archived')`
+	// -> completed with the placeholders of the ids, the dependencies reachable from the tasks
+	QueryListDependencies = `WITH RECURSIVE dependencies (task_id, blocker_id) AS (SELECT task_id, blocker_id FROM task_dependencies WHERE task_id IN (%s) UNION SELECT task_dependencies.task_id, task_dependencies.blocker_id FROM task_dependencies JOIN dependencies ON task_dependencies.task_id = dependencies.blocker_id) SELECT task_id, blocker_id FROM dependencies`
+	// recurrence: series_id references tasks (id) on delete set null, the first task of the series
+	// projects: project_id references projects (id) on delete restrict
+	// -> zero if the project does not exist or it has another owner
+	QueryCountTaskProject = `SELECT COUNT(*) FROM projects WHERE id = ? AND owner_id = ?`
+	// ranks: rank_key column (rank is a reserved word), indexed with owner_id, compared with a binary collation (see rank.go)
+	QueryLastTaskRank = `SELECT MAX(rank_key) FROM tasks WHERE owner_id = ?`
+	QueryGetTaskRank = queryGetTaskRank + lockMySQL
+	// -> the closest rank after or before the given one, among the tasks of the profile but the moved one
+	QueryNextTaskRank = `SELECT MIN(rank_key) FROM tasks WHERE owner_id = ? AND id <> ? AND rank_key > ?`
+	QueryPrevTaskRank = `SELECT MAX(rank_key) FROM tasks WHERE owner_id = ? AND id <> ? AND rank_key < ?`
+	QueryMoveTask = `UPDATE tasks SET rank_key = ?, version = version + 1 WHERE id = ?`
+	QueryListUnbalancedOwners = `SELECT DISTINCT owner_id FROM tasks WHERE rank_key IS NULL OR CHAR_LENGTH(rank_key) > ?`
+	QueryListOwnerRanks = queryListOwnerRanks + lockMySQL
+	QueryRankTask = `UPDATE tasks SET rank_key = ? WHERE id = ?`
+	// checklist: checklist column (JSON NULL), the array of the items of the task in order
+	QueryUpdateTaskChecklist = `UPDATE tasks SET checklist = ?, version = version + 1 WHERE id = ?`
+	// grants: task_grants table (task_id, profile_id, permission), removed on cascade with the task
+	// -> the owner of the task and the permission granted to the profile
+	QueryGetTaskAccess = `SELECT tasks.owner_id, task_grants.permission FROM tasks LEFT JOIN task_grants ON task_grants.task_id = tasks.id AND task_grants.profile_id = ? WHERE tasks.id = ? AND tasks.deleted_at IS NULL`
+	QuerySaveTaskGrant = `INSERT INTO task_grants (task_id, profile_id, permission) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE permission = VALUES(permission)`
+	QueryRemoveTaskGrant = `DELETE FROM task_grants WHERE task_id = ? AND profile_id = ?`
+	QueryListTaskGrants = `SELECT profile_id, permission FROM task_grants WHERE task_id = ? ORDER BY profile_id`
+	QueryGetTaskLocked = querySelectTask + columnLabels + queryFromGetTaskLocked + lockMySQL
+	QueryListOpenDescendants = `WITH RECURSIVE descendants (id) AS (SELECT id FROM tasks WHERE parent_id = ? AND deleted_at IS NULL UNION ALL SELECT tasks.id FROM tasks JOIN descendants ON tasks.parent_id = descendants.id WHERE tasks.deleted_at IS NULL) SELECT id FROM tasks WHERE id IN (SELECT id FROM descendants) AND status NOT IN ('done', 'archived') ORDER BY id`
+	QueryTree = queryWithSubtree + QueryListTasks + queryWhereSubtree
+)
+
+// parts of the statements that select the labels of the tasks or lock the rows they read, written with the labels column and the lock clause
+// of each database (see newDialect)
+const (
+	// -> followed by the labels column
+	querySelectTask = `SELECT id, owner_id, title, description, status, parent_id, start_at, due_at, created_at, updated_at, deleted_at, recurrence, series_id, occurrence, version, project_id, rank_key, checklist, `
+	queryFromGetTask = ` FROM tasks WHERE id = ? AND deleted_at IS NULL AND ` + condAccess
+	queryFromGetTaskLocked = ` FROM tasks WHERE id = ?`
+	// -> around the statement that lists the tasks
+	queryWithSubtree = `WITH RECURSIVE subtree (id) AS (SELECT id FROM tasks WHERE id = ? AND deleted_at IS NULL AND ` + condAccess + ` UNION ALL SELECT tasks.id FROM tasks JOIN subtree ON tasks.parent_id = subtree.id WHERE tasks.deleted_at IS NULL) `
+	queryWhereSubtree = ` WHERE id IN (SELECT id FROM subtree) ORDER BY id`
+	// -> followed by the lock clause
+	queryGetTaskState = `SELECT tasks.owner_id, task_grants.permission, tasks.status, tasks.series_id, tasks.occurrence, tasks.version, tasks.rank_key, tasks.checklist, tasks.created_at, tasks.deleted_at FROM tasks LEFT JOIN task_grants ON task_grants.task_id = tasks.id AND task_grants.profile_id = ? WHERE tasks.id = ? AND tasks.deleted_at IS NULL`
+	queryGetTaskVersion = `SELECT version FROM tasks WHERE id = ?`
+	queryGetTaskRank = `SELECT rank_key FROM tasks WHERE id = ? AND owner_id = ? AND deleted_at IS NULL`
+	queryListOwnerRanks = `SELECT id FROM tasks WHERE owner_id = ? ORDER BY rank_key, id`
+	queryGetTaskOwner = `SELECT owner_id FROM tasks WHERE id = ? AND deleted_at IS NULL`
+)
+
+// lockMySQL locks the rows read by a statement until the end of the transaction.
+const lockMySQL = ` FOR UPDATE`
+
+// condAccess matches the tasks the profile owns or that are shared with it (the profile is its two arguments).
+const condAccess = `(tasks.owner_id = ? OR EXISTS (SELECT 1 FROM task_grants WHERE task_grants.task_id = tasks.id AND task_grants.profile_id = ?))`
+
+// scopes match the tasks the profile owns and the ones shared with it (the profile is their argument).
 const (
-	QueryGetTask = `SELECT id, title, description, completed FROM tasks WHERE id = ?`
-	QuerySaveTask = `INSERT INTO tasks (id, title, description, completed) VALUES (?, ?, ?, ?)`
+	scopeOwned  = "owner_id = ?"
+	scopeShared = "id IN (SELECT task_id FROM task_grants WHERE profile_id = ?)"
 )
 
+// columnLabels selects the labels of the task as a comma separated list, sorted by name.
+const columnLabels = `(SELECT GROUP_CONCAT(label ORDER BY label) FROM task_labels WHERE task_labels.task_id = tasks.id) AS labels`
+
 // TaskMySQL is the MySQL representation of a task. (internal Data Transfer Object)
 type TaskMySQL struct {
 	ID 			sql.NullString
+	OwnerID 	sql.NullString
 	Title 		sql.NullString
 	Description sql.NullString
-	Completed 	sql.NullBool
+	Status 		sql.NullString
+	ParentID 	sql.NullString
+	StartAt 	sql.NullTime
+	DueAt 		sql.NullTime
+	CreatedAt 	sql.NullTime
+	UpdatedAt 	sql.NullTime
+	DeletedAt 	sql.NullTime
+	Recurrence 	sql.NullString
+	SeriesID 	sql.NullString
+	Occurrence 	sql.NullInt64
+	Version 	sql.NullInt64
+	ProjectID 	sql.NullString
+	Rank 		sql.NullString
+	Checklist 	ChecklistMySQL
+	// Labels is the comma separated list of labels
+	Labels 		sql.NullString
+}
+
+// fields returns the destination of the columns selected by the queries.
+func (t *TaskMySQL) fields() []any {
+	return []any{&t.ID, &t.OwnerID, &t.Title, &t.Description, &t.Status, &t.ParentID, &t.StartAt, &t.DueAt, &t.CreatedAt, &t.UpdatedAt, &t.DeletedAt, &t.Recurrence, &t.SeriesID, &t.Occurrence, &t.Version, &t.ProjectID, &t.Rank, &t.Checklist, &t.Labels}
+}
+
+// serialize returns the task represented by the dto.
+func (t *TaskMySQL) serialize() (ts *Task) {
+	ts = &Task{}
+	if t.ID.Valid {
+		ts.ID = optional.Some(t.ID.String)
+	}
+	if t.OwnerID.Valid {
+		ts.OwnerID = optional.Some(t.OwnerID.String)
+	}
+	if t.Title.Valid {
+		ts.Title = optional.Some(t.Title.String)
+	}
+	if t.Description.Valid {
+		ts.Description = optional.Some(t.Description.String)
+	}
+	if t.Status.Valid {
+		ts.Status = optional.Some(Status(t.Status.String))
+	}
+	if t.ParentID.Valid {
+		ts.ParentID = optional.Some(t.ParentID.String)
+	}
+	if t.StartAt.Valid {
+		ts.StartAt = optional.Some(t.StartAt.Time)
+	}
+	if t.DueAt.Valid {
+		ts.DueAt = optional.Some(t.DueAt.Time)
+	}
+	if t.CreatedAt.Valid {
+		ts.CreatedAt = optional.Some(t.CreatedAt.Time)
+	}
+	if t.UpdatedAt.Valid {
+		ts.UpdatedAt = optional.Some(t.UpdatedAt.Time)
+	}
+	if t.DeletedAt.Valid {
+		ts.DeletedAt = optional.Some(t.DeletedAt.Time)
+	}
+	if t.Recurrence.Valid {
+		ts.Recurrence = optional.Some(t.Recurrence.String)
+	}
+	if t.SeriesID.Valid {
+		ts.SeriesID = optional.Some(t.SeriesID.String)
+	}
+	if t.Occurrence.Valid {
+		ts.Occurrence = optional.Some(int(t.Occurrence.Int64))
+	}
+	if t.Version.Valid {
+		ts.Version = optional.Some(int(t.Version.Int64))
+	}
+	if t.ProjectID.Valid {
+		ts.ProjectID = optional.Some(t.ProjectID.String)
+	}
+	if t.Rank.Valid {
+		ts.Rank = optional.Some(t.Rank.String)
+	}
+	ts.Checklist = t.Checklist
+	if t.Labels.Valid && t.Labels.String != "" {
+		ts.Labels = strings.Split(t.Labels.String, ",")
+	}
+	return
+}
+
+// deserialize returns the dto of the given task.
+func deserialize(task *Task) (taskMySQL TaskMySQL) {
+	if task.ID.IsSome() {
+		taskMySQL.ID.String, _ = task.ID.Unwrap()
+		taskMySQL.ID.Valid = true
+	}
+	if task.OwnerID.IsSome() {
+		taskMySQL.OwnerID.String, _ = task.OwnerID.Unwrap()
+		taskMySQL.OwnerID.Valid = true
+	}
+	if task.Title.IsSome() {
+		taskMySQL.Title.String, _ = task.Title.Unwrap()
+		taskMySQL.Title.Valid = true
+	}
+	if task.Description.IsSome() {
+		taskMySQL.Description.String, _ = task.Description.Unwrap()
+		taskMySQL.Description.Valid = true
+	}
+	if task.Status.IsSome() {
+		status, _ := task.Status.Unwrap()
+		taskMySQL.Status.String = string(status)
+		taskMySQL.Status.Valid = true
+	}
+	if task.ParentID.IsSome() {
+		taskMySQL.ParentID.String, _ = task.ParentID.Unwrap()
+		taskMySQL.ParentID.Valid = true
+	}
+	if task.StartAt.IsSome() {
+		taskMySQL.StartAt.Time, _ = task.StartAt.Unwrap()
+		taskMySQL.StartAt.Time = taskMySQL.StartAt.Time.UTC()
+		taskMySQL.StartAt.Valid = true
+	}
+	if task.DueAt.IsSome() {
+		taskMySQL.DueAt.Time, _ = task.DueAt.Unwrap()
+		taskMySQL.DueAt.Time = taskMySQL.DueAt.Time.UTC()
+		taskMySQL.DueAt.Valid = true
+	}
+	if task.CreatedAt.IsSome() {
+		taskMySQL.CreatedAt.Time, _ = task.CreatedAt.Unwrap()
+		taskMySQL.CreatedAt.Time = taskMySQL.CreatedAt.Time.UTC()
+		taskMySQL.CreatedAt.Valid = true
+	}
+	if task.UpdatedAt.IsSome() {
+		taskMySQL.UpdatedAt.Time, _ = task.UpdatedAt.Unwrap()
+		taskMySQL.UpdatedAt.Time = taskMySQL.UpdatedAt.Time.UTC()
+		taskMySQL.UpdatedAt.Valid = true
+	}
+	if task.DeletedAt.IsSome() {
+		taskMySQL.DeletedAt.Time, _ = task.DeletedAt.Unwrap()
+		taskMySQL.DeletedAt.Time = taskMySQL.DeletedAt.Time.UTC()
+		taskMySQL.DeletedAt.Valid = true
+	}
+	if task.Recurrence.IsSome() {
+		taskMySQL.Recurrence.String, _ = task.Recurrence.Unwrap()
+		taskMySQL.Recurrence.Valid = true
+	}
+	if task.SeriesID.IsSome() {
+		taskMySQL.SeriesID.String, _ = task.SeriesID.Unwrap()
+		taskMySQL.SeriesID.Valid = true
+	}
+	if task.Occurrence.IsSome() {
+		occurrence, _ := task.Occurrence.Unwrap()
+		taskMySQL.Occurrence = sql.NullInt64{Int64: int64(occurrence), Valid: true}
+	}
+	if task.Version.IsSome() {
+		version, _ := task.Version.Unwrap()
+		taskMySQL.Version = sql.NullInt64{Int64: int64(version), Valid: true}
+	}
+	if task.ProjectID.IsSome() {
+		taskMySQL.ProjectID.String, _ = task.ProjectID.Unwrap()
+		taskMySQL.ProjectID.Valid = true
+	}
+	if task.Rank.IsSome() {
+		taskMySQL.Rank.String, _ = task.Rank.Unwrap()
+		taskMySQL.Rank.Valid = true
+	}
+	taskMySQL.Checklist = task.Checklist
+	if len(task.Labels) > 0 {
+		taskMySQL.Labels.String = strings.Join(task.Labels, ",")
+		taskMySQL.Labels.Valid = true
+	}
+	return
+}
+
+// ChecklistMySQL is the MySQL representation of a checklist, as a JSON array (NULL without items).
+type ChecklistMySQL []Item
+
+// Scan decodes the JSON array of the checklist.
+func (c *ChecklistMySQL) Scan(src any) (err error) {
+	*c = nil
+	var data []byte
+	switch src := src.(type) {
+	case nil:
+		return
+	case []byte:
+		data = src
+	case string:
+		data = []byte(src)
+	default:
+		err = fmt.Errorf("checklist of type %T", src)
+		return
+	}
+
+	err = json.Unmarshal(data, (*[]Item)(c))
+	return
+}
+
+// Value encodes the checklist as a JSON array.
+func (c ChecklistMySQL) Value() (v driver.Value, err error) {
+	if len(c) == 0 {
+		return
+	}
+
+	var data []byte
+	data, err = json.Marshal([]Item(c))
+	if err != nil {
+		return
+	}
+	v = string(data)
+	return
 }
 
 type StorageMySQL struct {
 	// db is the database connection.
 	db *sql.DB
+	// tr runs the atomic batches in a transaction.
+	tr transactioner.Transactioner
+	// tx is the transaction the statements are run on (nil to run them on the database, see on).
+	tx preparer
+	// ctx is the context the statements are run with (see WithContext).
+	ctx context.Context
+	// dl adapts the statements to the database (nil for MySQL, see dialect).
+	dl *dialect
 	// vl is the task validator.
 	vl Validator
+	// cfg is the storage config.
+	cfg *Config
+	// rec is handed the writes in their transaction, before it is committed (nil if they are not recorded, see recording).
+	rec recorder
 }
 
 // Get returns the task with the given id.
-func (s *StorageMySQL) Get(id string) (ts *Task, err error) {
+func (s *StorageMySQL) Get(profileId string, id string) (ts *Task, err error) {
 	// prepare statement
 	var stmt *sql.Stmt
-	stmt, err = s.db.Prepare(QueryGetTask)
+	stmt, err = s.conn().PrepareContext(s.ctx, QueryGetTask)
 	if err != nil {
 		err = fmt.Errorf("%w: %s", ErrStorageInternal, "prepare")
 		return
@@ -47,7 +365,7 @@ func (s *StorageMySQL) Get(id string) (ts *Task, err error) {
 
 	// execute statement
 	var taskMySQL TaskMySQL
-	err = stmt.QueryRow(id).Scan(&taskMySQL.ID, &taskMySQL.Title, &taskMySQL.Description, &taskMySQL.Completed)
+	err = stmt.QueryRowContext(s.ctx, id, profileId, profileId).Scan(taskMySQL.fields()...)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			err = fmt.Errorf("%w: %s", ErrStorageNotFound, "query row")
@@ -58,88 +376,307 @@ func (s *StorageMySQL) Get(id string) (ts *Task, err error) {
 	}
 
 	// serialize
-	ts = &Task{}
-	if taskMySQL.ID.Valid {
-		ts.ID = optional.Some(taskMySQL.ID.String)
+	ts = taskMySQL.serialize()
+
+	return
+}
+
+// List returns the page of tasks that matches the given query.
+func (s *StorageMySQL) List(profileId string, query *Query) (pg *Page, err error) {
+	pg, err = s.list(scopeOwned, profileId, query)
+	return
+}
+
+// Shared returns the page of tasks shared with the profile (not in the trash) that matches the given query.
+func (s *StorageMySQL) Shared(profileId string, query *Query) (pg *Page, err error) {
+	if query.Deleted {
+		err = fmt.Errorf("%w: %s", ErrStorageInvalidQuery, "trash")
+		return
+	}
+
+	pg, err = s.list(scopeShared, profileId, query)
+	return
+}
+
+// list returns the page of tasks in the scope of the profile that matches the given query.
+func (s *StorageMySQL) list(scope string, profileId string, query *Query) (pg *Page, err error) {
+	// query
+	var size int
+	size, err = checkQuery(query)
+	if err != nil {
+		return
+	}
+	var c *cursor
+	c, err = decodeCursor(query.Cursor, query.Sort)
+	if err != nil {
+		return
+	}
+
+	// build statement
+	q, args := listQuery(s.dl, scope, profileId, query, c)
+	// -> one more task than the page size, to know if there is a next page
+	args = append(args, size+1)
+
+	// prepare statement
+	var stmt *sql.Stmt
+	stmt, err = s.conn().PrepareContext(s.ctx, q)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "prepare")
+		return
+	}
+	defer stmt.Close()
+
+	// execute statement
+	var rows *sql.Rows
+	rows, err = stmt.QueryContext(s.ctx, args...)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "query")
+		return
+	}
+	defer rows.Close()
+
+	// serialize
+	ts := make([]*Task, 0, size+1)
+	for rows.Next() {
+		var taskMySQL TaskMySQL
+		err = rows.Scan(taskMySQL.fields()...)
+		if err != nil {
+			err = fmt.Errorf("%w: %s", ErrStorageInternal, "scan")
+			return
+		}
+		ts = append(ts, taskMySQL.serialize())
+	}
+	if err = rows.Err(); err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "rows")
+		return
+	}
+
+	// page
+	pg = &Page{Tasks: ts, Next: optional.None[string]()}
+	if len(ts) > size {
+		pg.Tasks = ts[:size]
+		pg.Next = optional.Some(encodeCursor(ts[size-1], query.Sort))
+	}
+
+	return
+}
+
+// columns are the columns of the fields that can be queried.
+var columns = map[Field]string{
+	FieldTitle: 	  "title",
+	FieldDescription: "description",
+	FieldStatus: 	  "status",
+	FieldDueAt: 	  "due_at",
+	FieldStartAt: 	  "start_at",
+	FieldCreatedAt:   "created_at",
+	FieldUpdatedAt:   "updated_at",
+	FieldParentID: 	  "parent_id",
+	FieldSeriesID: 	  "series_id",
+	FieldProjectID:   "project_id",
+	FieldRank: 		  "rank_key",
+}
+
+// listQuery returns the statement that lists the tasks in the scope of the profile that match the query after the cursor, and its arguments.
+// - the statement is written in the dialect (MySQL if nil), the limit argument is left to the caller
+func listQuery(dl *dialect, scope string, profileId string, query *Query, c *cursor) (q string, args []any) {
+	if dl == nil {
+		dl = dialectMySQL
 	}
-	if taskMySQL.Title.Valid {
-		ts.Title = optional.Some(taskMySQL.Title.String)
+
+	// where
+	conds := []string{scope, "deleted_at IS NULL"}
+	args = append(args, profileId)
+	if query.Deleted {
+		conds[1] = "deleted_at IS NOT NULL"
+	}
+	if cond, condArgs := where(dl, query.Filter); cond != "" {
+		conds = append(conds, cond)
+		args = append(args, condArgs...)
 	}
-	if taskMySQL.Description.Valid {
-		ts.Description = optional.Some(taskMySQL.Description.String)
+	if c != nil {
+		// keyset: after the sort key of the cursor, ties broken by id
+		switch {
+		case query.Sort.Field == "":
+			conds = append(conds, "id > ?")
+			args = append(args, c.ID)
+		case c.Key == nil:
+			// -> nulls go first when ascending and last when descending
+			col := columns[query.Sort.Field]
+			cond := fmt.Sprintf("((%s IS NULL AND id > ?) OR %s IS NOT NULL)", col, col)
+			if query.Sort.Desc {
+				cond = fmt.Sprintf("(%s IS NULL AND id > ?)", col)
+			}
+			conds = append(conds, cond)
+			args = append(args, c.ID)
+		default:
+			col, op := columns[query.Sort.Field], ">"
+			if query.Sort.Desc {
+				op = "<"
+			}
+			cond := fmt.Sprintf("(%s %s ? OR (%s = ? AND id > ?))", col, op, col)
+			if query.Sort.Desc && fieldSpecs[query.Sort.Field].nullable {
+				cond = fmt.Sprintf("(%s %s ? OR (%s = ? AND id > ?) OR %s IS NULL)", col, op, col, col)
+			}
+			conds = append(conds, cond)
+			args = append(args, c.Key, c.Key, c.ID)
+		}
 	}
-	if taskMySQL.Completed.Valid {
-		ts.Completed = optional.Some(taskMySQL.Completed.Bool)
+
+	// order by
+	order := "id"
+	if query.Sort.Field != "" {
+		dir := dl.asc
+		if query.Sort.Desc {
+			dir = dl.desc
+		}
+		order = fmt.Sprintf("%s %s, id", columns[query.Sort.Field], dir)
 	}
 
+	q = fmt.Sprintf("%s WHERE %s ORDER BY %s LIMIT ?", dl.query(QueryListTasks), strings.Join(conds, " AND "), order)
+	return
+}
+
+// where returns the sql condition of the filter (parameterized) in the dialect and its arguments.
+// - labels and texts are matched case insensitive, as the local storage does
+func where(dl *dialect, f Filter) (cond string, args []any) {
+	switch f := f.(type) {
+	case And:
+		conds := make([]string, 0, len(f.Filters))
+		for _, sub := range f.Filters {
+			subCond, subArgs := where(dl, sub)
+			if subCond == "" {
+				continue
+			}
+			conds = append(conds, subCond)
+			args = append(args, subArgs...)
+		}
+		if len(conds) > 0 {
+			cond = "(" + strings.Join(conds, " AND ") + ")"
+		}
+	case Condition:
+		switch {
+		case f.Field == FieldLabels:
+			cond = "id IN (SELECT task_id FROM task_labels WHERE " + fmt.Sprintf(dl.equal, "label") + ")"
+			args = append(args, f.Value)
+			return
+		}
+		switch f.Operator {
+		case OperatorEq:
+			cond = columns[f.Field] + " = ?"
+			args = append(args, argument(f.Value))
+		case OperatorNe:
+			cond = columns[f.Field] + " <> ?"
+			args = append(args, argument(f.Value))
+		case OperatorContains:
+			cond = columns[f.Field] + " " + dl.like
+			args = append(args, "%"+likeEscaper.Replace(f.Value.(string))+"%")
+		case OperatorLt, OperatorLte, OperatorGt, OperatorGte:
+			cond = columns[f.Field] + " " + comparators[f.Operator] + " ?"
+			args = append(args, argument(f.Value))
+		}
+	}
 	return
 }
 
+// argument returns the argument of the value of a condition, a time in UTC as the stored ones.
+func argument(v any) any {
+	if t, ok := v.(time.Time); ok {
+		return t.UTC()
+	}
+	return v
+}
+
+// comparators are the sql comparison operators of the range operators.
+var comparators = map[Operator]string{
+	OperatorLt:  "<",
+	OperatorLte: "<=",
+	OperatorGt:  ">",
+	OperatorGte: ">=",
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 // Save saves the given task.
 func (s *StorageMySQL) Save(task *Task) (err error) {
 	// validate
 	err = s.vl.Validate(task)
 	if err != nil {
-		err = fmt.Errorf("%w: %s", ErrStorageInvalid, "validate")
+		err = fmt.Errorf("%w: %v", ErrStorageInvalid, err)
 		return
 	}
 	
 	// deserialize
-	var taskMySQL TaskMySQL
-	if task.Title.IsSome() {
-		taskMySQL.Title.String, _ = task.Title.Unwrap()
-		taskMySQL.Title.Valid = true
-	}
-	if task.Description.IsSome() {
-		taskMySQL.Description.String, _ = task.Description.Unwrap()
-		taskMySQL.Description.Valid = true
-	}
-	if task.Completed.IsSome() {
-		taskMySQL.Completed.Bool, _ = task.Completed.Unwrap()
-		taskMySQL.Completed.Valid = true
+	taskMySQL := deserialize(task)
+	if !taskMySQL.OwnerID.Valid {
+		err = fmt.Errorf("%w: %s", ErrStorageInvalid, "owner")
+		return
 	}
 		
 	// default values
 	taskMySQL.ID.String = uuid.New().String()
 	taskMySQL.ID.Valid = true
-	
-	// prepare transaction
-	var tx *sql.Tx
-	tx, err = s.db.Begin()
-	if err != nil {
-		err = fmt.Errorf("%w: %s", ErrStorageInternal, "begin")
-		return
+	now := time.Now().UTC()
+	taskMySQL.CreatedAt = sql.NullTime{Time: now, Valid: true}
+	taskMySQL.UpdatedAt = sql.NullTime{Time: now, Valid: true}
+	// -> a recurring task starts its own series
+	if taskMySQL.Recurrence.Valid {
+		taskMySQL.SeriesID = taskMySQL.ID
+		taskMySQL.Occurrence = sql.NullInt64{Int64: 1, Valid: true}
 	}
-	defer func () {
+	
+	// execute statements
+	var rank string
+	err = s.transaction(func(tx preparer) (err error) {
+		var pd *pending
+		pd, err = s.begin(tx, taskMySQL.OwnerID.String)
 		if err != nil {
-			tx.Rollback()
 			return
 		}
-		err = tx.Commit()
-	}()
+		defer s.record(tx, pd, &err)
+		pd.create(taskMySQL.ID.String)
 
-	// prepare statement
-	var stmt *sql.Stmt
-	stmt, err = s.db.Prepare(QuerySaveTask)
+		err = checkParent(s.ctx, tx, taskMySQL)
+		if err != nil {
+			return
+		}
+		err = checkProject(s.ctx, tx, taskMySQL)
+		if err != nil {
+			return
+		}
+
+		rank, err = insert(s.ctx, tx, taskMySQL, task.Labels)
+		return
+	})
 	if err != nil {
-		err = fmt.Errorf("%w: %s", ErrStorageInternal, "prepare")
 		return
 	}
-	defer stmt.Close()
 
-	// execute statement
-	var result sql.Result
-	result, err = stmt.Exec(taskMySQL.ID, taskMySQL.Title, taskMySQL.Description, taskMySQL.Completed)
+	// set default values
+	task.ID = optional.Some(taskMySQL.ID.String)
+	task.CreatedAt = optional.Some(now)
+	task.UpdatedAt = optional.Some(now)
+	task.Version = optional.Some(1)
+	task.Rank = optional.Some(rank)
+	sort.Strings(task.Labels)
+	joinSeries(task, optional.None[string](), optional.None[int]())
+
+	return
+}
+
+// insert inserts the given task with its labels, ranked after the rest of the tasks of the owner, and returns its rank.
+func insert(ctx context.Context, tx preparer, taskMySQL TaskMySQL, labels []string) (rank string, err error) {
+	var last sql.NullString
+	err = queryRow(ctx, tx, QueryLastTaskRank, []any{taskMySQL.OwnerID}, &last)
 	if err != nil {
-		err = fmt.Errorf("%w: %s", ErrStorageInternal, "exec")
 		return
 	}
+	rank = rankAfter(last.String)
+	taskMySQL.Rank = sql.NullString{String: rank, Valid: true}
 
-	// check result
 	var rowsAffected int64
-	rowsAffected, err = result.RowsAffected()
+	rowsAffected, err = execN(ctx, tx, QuerySaveTask, taskMySQL.ID, taskMySQL.OwnerID, taskMySQL.Title, taskMySQL.Description, taskMySQL.Status, taskMySQL.ParentID, taskMySQL.StartAt, taskMySQL.DueAt, taskMySQL.CreatedAt, taskMySQL.UpdatedAt, taskMySQL.Recurrence, taskMySQL.SeriesID, taskMySQL.Occurrence, taskMySQL.ProjectID, taskMySQL.Rank, taskMySQL.Checklist)
 	if err != nil {
-		err = fmt.Errorf("%w: %s", ErrStorageInternal, "result rows affected")
 		return
 	}
 
@@ -149,5 +686,1225 @@ func (s *StorageMySQL) Save(task *Task) (err error) {
 		return
 	}
 
+	err = saveLabels(ctx, tx, taskMySQL.ID.String, labels)
+	return
+}
+
+// Update replaces the task with the same id as the given task.
+func (s *StorageMySQL) Update(profileId string, task *Task) (err error) {
+	// validate
+	err = s.vl.Validate(task)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrStorageInvalid, err)
+		return
+	}
+
+	// deserialize
+	taskMySQL := deserialize(task)
+	now := time.Now().UTC()
+	taskMySQL.UpdatedAt = sql.NullTime{Time: now, Valid: true}
+
+	// execute statements
+	var version int
+	err = s.transaction(func(tx preparer) (err error) {
+		// -> the subtasks completed in cascade are written too
+		ids := []string{taskMySQL.ID.String}
+		if s.rec != nil && taskMySQL.Status.String == string(StatusDone) && s.cfg.Hierarchy == HierarchyCascade {
+			var open []string
+			open, err = s.openDescendants(tx, taskMySQL.ID.String)
+			if err != nil {
+				return
+			}
+			ids = append(ids, open...)
+		}
+		var pd *pending
+		pd, err = s.begin(tx, profileId, ids...)
+		if err != nil {
+			return
+		}
+		defer s.record(tx, pd, &err)
+
+		// stored state of the task, locked until the end of the transaction
+		var stored TaskMySQL
+		var permission sql.NullString
+		err = queryRow(s.ctx, tx, QueryGetTaskState, []any{profileId, taskMySQL.ID.String}, &stored.OwnerID, &permission, &stored.Status, &stored.SeriesID, &stored.Occurrence, &stored.Version, &stored.Rank, &stored.Checklist, &stored.CreatedAt, &stored.DeletedAt)
+		if err != nil {
+			return
+		}
+		err = authorize(profileId, stored.OwnerID, permission, PermissionEdit)
+		if err != nil {
+			return
+		}
+		// -> a task without version is updated from the stored one
+		if taskMySQL.Version.Valid && taskMySQL.Version.Int64 != stored.Version.Int64 {
+			err = fmt.Errorf("%w: %s", ErrStorageVersionMismatch, "version")
+			return
+		}
+		// -> the owner, the rank, the checklist and the times but the update are kept
+		storedTask := stored.serialize()
+		task.OwnerID = optional.Some(stored.OwnerID.String)
+		taskMySQL.OwnerID = stored.OwnerID
+		task.Rank = storedTask.Rank
+		task.Checklist = stored.Checklist
+		task.CreatedAt = storedTask.CreatedAt
+		task.DeletedAt = storedTask.DeletedAt
+
+		// check transition, parent, project, blockers and subtasks
+		err = s.checkTransition(stored, taskMySQL)
+		if err != nil {
+			return
+		}
+		err = checkParent(s.ctx, tx, taskMySQL)
+		if err != nil {
+			return
+		}
+		err = checkProject(s.ctx, tx, taskMySQL)
+		if err != nil {
+			return
+		}
+		err = checkBlockers(s.ctx, tx, taskMySQL)
+		if err != nil {
+			return
+		}
+		err = s.complete(tx, taskMySQL)
+		if err != nil {
+			return
+		}
+
+		// -> the task stays in its series
+		joinSeries(task, storedTask.SeriesID, storedTask.Occurrence)
+		series := deserialize(task)
+		taskMySQL.SeriesID, taskMySQL.Occurrence = series.SeriesID, series.Occurrence
+
+		err = exec(s.ctx, tx, QueryUpdateTask, taskMySQL.Title, taskMySQL.Description, taskMySQL.Status, taskMySQL.ParentID, taskMySQL.StartAt, taskMySQL.DueAt, taskMySQL.UpdatedAt, taskMySQL.Recurrence, taskMySQL.SeriesID, taskMySQL.Occurrence, taskMySQL.ProjectID, taskMySQL.ID, stored.Version)
+		if err != nil {
+			return
+		}
+		version = int(stored.Version.Int64) + 1
+
+		// -> labels are replaced
+		_, err = execN(s.ctx, tx, QueryClearTaskLabels, taskMySQL.ID)
+		if err != nil {
+			return
+		}
+		err = saveLabels(s.ctx, tx, taskMySQL.ID.String, task.Labels)
+		if err != nil {
+			return
+		}
+
+		// next occurrence of a recurring task that is completed
+		if !completes(storedTask.Status, task.Status) {
+			return
+		}
+		var next *Task
+		next, _, err = nextOccurrence(task)
+		if err != nil || next == nil {
+			return
+		}
+		nextMySQL := deserialize(next)
+		nextMySQL.ID = sql.NullString{String: uuid.New().String(), Valid: true}
+		nextMySQL.CreatedAt = taskMySQL.UpdatedAt
+		nextMySQL.UpdatedAt = taskMySQL.UpdatedAt
+		pd.create(nextMySQL.ID.String)
+		_, err = insert(s.ctx, tx, nextMySQL, next.Labels)
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	task.UpdatedAt = optional.Some(now)
+	task.Version = optional.Some(version)
+	sort.Strings(task.Labels)
 	return
 }
+
+// Delete moves the task with the given id to the trash.
+// - the version is checked once the task is moved (it is not changed by it), so a task of another profile is not found either way
+func (s *StorageMySQL) Delete(profileId string, id string, version optional.Option[int]) (err error) {
+	err = s.transaction(func(tx preparer) (err error) {
+		err = exec(s.ctx, tx, QueryDeleteTask, time.Now().UTC(), id, profileId)
+		if err != nil {
+			return
+		}
+		err = checkVersion(s.ctx, tx, id, version)
+		return
+	})
+	return
+}
+
+// Restore moves the task with the given id out of the trash.
+// - the version is checked as in Delete
+func (s *StorageMySQL) Restore(profileId string, id string, version optional.Option[int]) (err error) {
+	err = s.transaction(func(tx preparer) (err error) {
+		err = exec(s.ctx, tx, QueryRestoreTask, id, profileId)
+		if err != nil {
+			return
+		}
+		err = checkVersion(s.ctx, tx, id, version)
+		return
+	})
+	return
+}
+
+// Purge removes for good the tasks moved to the trash before the given time.
+func (s *StorageMySQL) Purge(before time.Time) (n int, err error) {
+	var rowsAffected int64
+	rowsAffected, err = execN(s.ctx, s.conn(), QueryPurgeTasks, before.UTC())
+	if err != nil {
+		return
+	}
+
+	n = int(rowsAffected)
+	return
+}
+
+// AddLabel adds the given label to the task with the given id.
+func (s *StorageMySQL) AddLabel(profileId string, id string, label string, version optional.Option[int]) (err error) {
+	err = s.transaction(func(tx preparer) (err error) {
+		var pd *pending
+		pd, err = s.begin(tx, profileId, id)
+		if err != nil {
+			return
+		}
+		defer s.record(tx, pd, &err)
+
+		st := s.on(tx)
+
+		// get task
+		err = st.access(profileId, id, PermissionEdit)
+		if err != nil {
+			return
+		}
+		err = checkVersion(s.ctx, tx, id, version)
+		if err != nil {
+			return
+		}
+		var ts *Task
+		ts, err = st.Get(profileId, id)
+		if err != nil {
+			return
+		}
+		if hasLabel(ts.Labels, label) {
+			return
+		}
+
+		// validate the task with the label
+		ts.Labels = append(ts.Labels, label)
+		err = s.vl.Validate(ts)
+		if err != nil {
+			err = fmt.Errorf("%w: %v", ErrStorageInvalid, err)
+			return
+		}
+
+		// execute statements
+		// -> a label added meanwhile is ignored
+		var rowsAffected int64
+		rowsAffected, err = execN(s.ctx, tx, QuerySaveTaskLabel, id, label)
+		if err != nil || rowsAffected == 0 {
+			return
+		}
+		err = exec(s.ctx, tx, QueryTouchTask, id)
+		return
+	})
+	return
+}
+
+// RemoveLabel removes the given label from the task with the given id.
+func (s *StorageMySQL) RemoveLabel(profileId string, id string, label string, version optional.Option[int]) (err error) {
+	err = s.transaction(func(tx preparer) (err error) {
+		var pd *pending
+		pd, err = s.begin(tx, profileId, id)
+		if err != nil {
+			return
+		}
+		defer s.record(tx, pd, &err)
+
+		err = s.on(tx).access(profileId, id, PermissionEdit)
+		if err != nil {
+			return
+		}
+		err = checkVersion(s.ctx, tx, id, version)
+		if err != nil {
+			return
+		}
+
+		err = exec(s.ctx, tx, QueryRemoveTaskLabel, id, label)
+		if err != nil {
+			return
+		}
+		err = exec(s.ctx, tx, QueryTouchTask, id)
+		return
+	})
+	return
+}
+
+// Labels returns the labels in use by the tasks (not in the trash), sorted by name.
+func (s *StorageMySQL) Labels(profileId string) (ls []*Label, err error) {
+	// prepare statement
+	var stmt *sql.Stmt
+	stmt, err = s.conn().PrepareContext(s.ctx, QueryListLabels)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "prepare")
+		return
+	}
+	defer stmt.Close()
+
+	// execute statement
+	var rows *sql.Rows
+	rows, err = stmt.QueryContext(s.ctx, profileId)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "query")
+		return
+	}
+	defer rows.Close()
+
+	// serialize
+	ls = make([]*Label, 0)
+	for rows.Next() {
+		var lb Label
+		err = rows.Scan(&lb.Name, &lb.Tasks)
+		if err != nil {
+			ls, err = nil, fmt.Errorf("%w: %s", ErrStorageInternal, "scan")
+			return
+		}
+		ls = append(ls, &lb)
+	}
+	if err = rows.Err(); err != nil {
+		ls, err = nil, fmt.Errorf("%w: %s", ErrStorageInternal, "rows")
+		return
+	}
+
+	return
+}
+
+// Tree returns the task with the given id and all of its subtasks (not in the trash).
+func (s *StorageMySQL) Tree(profileId string, id string) (nd *Node, err error) {
+	// prepare statement
+	var stmt *sql.Stmt
+	stmt, err = s.conn().PrepareContext(s.ctx, QueryTree)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "prepare")
+		return
+	}
+	defer stmt.Close()
+
+	// execute statement
+	var rows *sql.Rows
+	rows, err = stmt.QueryContext(s.ctx, id, profileId, profileId)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "query")
+		return
+	}
+	defer rows.Close()
+
+	// serialize
+	// -> tasks come sorted by id, so the children of each node are too
+	nodes := make(map[string]*Node)
+	ts := make([]*Task, 0)
+	for rows.Next() {
+		var taskMySQL TaskMySQL
+		err = rows.Scan(taskMySQL.fields()...)
+		if err != nil {
+			err = fmt.Errorf("%w: %s", ErrStorageInternal, "scan")
+			return
+		}
+		ts = append(ts, taskMySQL.serialize())
+		nodes[taskMySQL.ID.String] = &Node{Task: ts[len(ts)-1], Children: []*Node{}}
+	}
+	if err = rows.Err(); err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "rows")
+		return
+	}
+
+	// build the tree
+	nd = nodes[id]
+	if nd == nil {
+		err = fmt.Errorf("%w: %s", ErrStorageNotFound, "query")
+		return
+	}
+	for _, t := range ts {
+		tId, _ := t.ID.Unwrap()
+		parentId, _ := t.ParentID.Unwrap()
+		if parent, ok := nodes[parentId]; ok && tId != id {
+			parent.Children = append(parent.Children, nodes[tId])
+		}
+	}
+
+	return
+}
+
+// AddDependency makes the task with the given id blocked by the task with the blocker id.
+func (s *StorageMySQL) AddDependency(profileId string, id string, blockerId string) (err error) {
+	// execute statements
+	err = s.transaction(func(tx preparer) (err error) {
+		// check tasks
+		// -> both are locked in the order of their ids, so they keep their owner until the dependency is saved, and the dependencies
+		// added at the same time between them wait for each other instead of deadlocking or making a cycle
+		ids := []string{id, blockerId}
+		sort.Strings(ids)
+		for _, lockId := range ids {
+			err = s.ownLocked(tx, profileId, lockId)
+			if err != nil {
+				return
+			}
+		}
+		if id == blockerId {
+			err = fmt.Errorf("%w: %s", ErrStorageCycle, "blocker")
+			return
+		}
+
+		// -> the blocker can not be blocked by the task
+		var cycles int
+		err = queryRow(s.ctx, tx, QueryTaskBlockers, []any{blockerId, id}, &cycles)
+		if err != nil {
+			return
+		}
+		if cycles > 0 {
+			err = fmt.Errorf("%w: %s", ErrStorageCycle, "blockers")
+			return
+		}
+
+		// -> an existing dependency is ignored
+		_, err = execN(s.ctx, tx, QuerySaveTaskDependency, id, blockerId)
+		return
+	})
+	return
+}
+
+// RemoveDependency makes the task with the given id no longer blocked by the task with the blocker id.
+func (s *StorageMySQL) RemoveDependency(profileId string, id string, blockerId string) (err error) {
+	err = exec(s.ctx, s.conn(), QueryRemoveTaskDependency, id, blockerId, profileId)
+	return
+}
+
+// Order returns the tasks with the given ids (not in the trash), every task after its blockers.
+func (s *StorageMySQL) Order(profileId string, ids []string) (ts []*Task, err error) {
+	if len(ids) == 0 {
+		ts = []*Task{}
+		return
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	args := make([]any, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	// tasks
+	tasks := make(map[string]*Task, len(ids))
+	err = queryRows(s.ctx, s.conn(), s.dl.query(QueryListTasks)+" WHERE owner_id = ? AND deleted_at IS NULL AND id IN ("+placeholders+")", append([]any{profileId}, args...), func(rows *sql.Rows) (err error) {
+		var taskMySQL TaskMySQL
+		err = rows.Scan(taskMySQL.fields()...)
+		if err != nil {
+			return
+		}
+		tasks[taskMySQL.ID.String] = taskMySQL.serialize()
+		return
+	})
+	if err != nil {
+		return
+	}
+	for _, id := range ids {
+		if _, ok := tasks[id]; !ok {
+			err = fmt.Errorf("%w: %s", ErrStorageNotFound, "query")
+			return
+		}
+	}
+
+	// dependencies
+	blockers := make(map[string][]string)
+	err = queryRows(s.ctx, s.conn(), fmt.Sprintf(QueryListDependencies, placeholders), args, func(rows *sql.Rows) (err error) {
+		var id, blockerId string
+		err = rows.Scan(&id, &blockerId)
+		if err != nil {
+			return
+		}
+		blockers[id] = append(blockers[id], blockerId)
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	// order
+	var order []string
+	order, err = topological(ids, blockers)
+	if err != nil {
+		return
+	}
+	ts = make([]*Task, 0, len(order))
+	for _, id := range order {
+		ts = append(ts, tasks[id])
+	}
+	return
+}
+
+// Grant shares the task with the given id with the profile of the grant.
+func (s *StorageMySQL) Grant(profileId string, id string, grant *Grant) (err error) {
+	err = s.own(profileId, id)
+	if err != nil {
+		return
+	}
+	err = checkGrant(profileId, grant)
+	if err != nil {
+		return
+	}
+
+	// execute statement
+	// -> the permission of an existing grant is replaced
+	_, err = execN(s.ctx, s.conn(), QuerySaveTaskGrant, id, grant.ProfileID, string(grant.Permission))
+	return
+}
+
+// Revoke stops sharing the task with the given id with the grantee.
+func (s *StorageMySQL) Revoke(profileId string, id string, granteeId string) (err error) {
+	err = s.own(profileId, id)
+	if err != nil {
+		return
+	}
+
+	err = exec(s.ctx, s.conn(), QueryRemoveTaskGrant, id, granteeId)
+	return
+}
+
+// Grants returns the grants of the task with the given id, sorted by profile id.
+func (s *StorageMySQL) Grants(profileId string, id string) (gs []*Grant, err error) {
+	err = s.own(profileId, id)
+	if err != nil {
+		return
+	}
+
+	gs = make([]*Grant, 0)
+	err = queryRows(s.ctx, s.conn(), QueryListTaskGrants, []any{id}, func(rows *sql.Rows) (err error) {
+		var g Grant
+		err = rows.Scan(&g.ProfileID, &g.Permission)
+		if err != nil {
+			return
+		}
+		gs = append(gs, &g)
+		return
+	})
+	if err != nil {
+		gs = nil
+		return
+	}
+	return
+}
+
+// Batch runs the operations on behalf of the profile, an atomic batch in a single transaction.
+func (s *StorageMySQL) Batch(profileId string, ops []*Op, mode Mode) (rs []*Result, err error) {
+	err = checkBatch(ops, mode)
+	if err != nil {
+		return
+	}
+	if mode == ModeBestEffort {
+		rs = runBatch(s, profileId, ops)
+		return
+	}
+
+	// the error of the operations is kept (the transactioner wraps it)
+	var aborted error
+	e := s.tr.WithContext(s.ctx).DoTx(func(tx *sql.Tx) (err error) {
+		rs, aborted = runAtomic(s.on(tx), profileId, ops)
+		err = aborted
+		return
+	})
+	switch {
+	case aborted != nil:
+		err = aborted
+	case e != nil:
+		// begin, commit or rollback failed: nothing was applied
+		rs = make([]*Result, len(ops))
+		abort(rs, -1)
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "batch")
+	}
+	return
+}
+
+// Move ranks the task with the given id between its neighbours, in a transaction that locks them.
+func (s *StorageMySQL) Move(profileId string, id string, afterId string, beforeId string, version optional.Option[int]) (err error) {
+	if afterId == "" && beforeId == "" {
+		err = fmt.Errorf("%w: %s", ErrStorageInvalid, "neighbour required")
+		return
+	}
+	if afterId == id || beforeId == id {
+		err = fmt.Errorf("%w: %s", ErrStorageInvalid, "neighbour")
+		return
+	}
+
+	// execute statements
+	err = s.transaction(func(tx preparer) (err error) {
+		var stored sql.NullString
+		err = queryRow(s.ctx, tx, QueryGetTaskRank, []any{id, profileId}, &stored)
+		if err != nil {
+			return
+		}
+		err = checkVersion(s.ctx, tx, id, version)
+		if err != nil {
+			return
+		}
+
+		// bounds: the ranks of the neighbours, or the ranks right next to the only one given
+		var lower, upper string
+		if afterId != "" {
+			lower, err = neighbourRank(s.ctx, tx, profileId, afterId)
+			if err != nil {
+				return
+			}
+		}
+		if beforeId != "" {
+			upper, err = neighbourRank(s.ctx, tx, profileId, beforeId)
+			if err != nil {
+				return
+			}
+		}
+		var adjacent sql.NullString
+		switch {
+			case beforeId == "":
+				err = queryRow(s.ctx, tx, QueryNextTaskRank, []any{profileId, id, lower}, &adjacent)
+				upper = adjacent.String
+			case afterId == "":
+				err = queryRow(s.ctx, tx, QueryPrevTaskRank, []any{profileId, id, upper}, &adjacent)
+				lower = adjacent.String
+		}
+		if err != nil {
+			return
+		}
+
+		var rank string
+		rank, err = rankBetween(lower, upper)
+		if err != nil {
+			return
+		}
+		err = exec(s.ctx, tx, QueryMoveTask, rank, id)
+		return
+	})
+	return
+}
+
+// neighbourRank returns the rank of the neighbour with the given id of a moved task, locked until the end of the transaction.
+// - the neighbour must be a task of the profile, not in the trash, with a rank
+func neighbourRank(ctx context.Context, tx preparer, profileId string, id string) (rank string, err error) {
+	var stored sql.NullString
+	err = queryRow(ctx, tx, QueryGetTaskRank, []any{id, profileId}, &stored)
+	if err != nil {
+		if errors.Is(err, ErrStorageNotFound) {
+			err = fmt.Errorf("%w: %s", ErrStorageInvalid, "neighbour not found")
+		}
+		return
+	}
+	if !stored.Valid {
+		err = fmt.Errorf("%w: %s", ErrStorageInvalid, "neighbour not ranked")
+		return
+	}
+
+	rank = stored.String
+	return
+}
+
+// Rebalance spreads the ranks of the tasks of each unbalanced profile, a transaction per profile.
+func (s *StorageMySQL) Rebalance() (n int, err error) {
+	var owners []string
+	err = queryRows(s.ctx, s.conn(), QueryListUnbalancedOwners, []any{MaxRankLength}, func(rows *sql.Rows) (err error) {
+		var ownerId string
+		err = rows.Scan(&ownerId)
+		if err != nil {
+			return
+		}
+		owners = append(owners, ownerId)
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	for _, ownerId := range owners {
+		// -> the tasks without rank go first
+		var ids []string
+		err = s.transaction(func(tx preparer) (err error) {
+			err = queryRows(s.ctx, tx, QueryListOwnerRanks, []any{ownerId}, func(rows *sql.Rows) (err error) {
+				var id string
+				err = rows.Scan(&id)
+				if err != nil {
+					return
+				}
+				ids = append(ids, id)
+				return
+			})
+			if err != nil {
+				return
+			}
+
+			for i, rank := range rankSpread(len(ids)) {
+				_, err = execN(s.ctx, tx, QueryRankTask, rank, ids[i])
+				if err != nil {
+					return
+				}
+			}
+			return
+		})
+		if err != nil {
+			return
+		}
+		n += len(ids)
+	}
+	return
+}
+
+// AddItem adds the given item to the end of the checklist of the task with the given id.
+func (s *StorageMySQL) AddItem(profileId string, id string, item *Item, version optional.Option[int]) (err error) {
+	it := *item
+	it.ID = uuid.New().String()
+	err = s.editItem(profileId, id, version, func(items []Item) ([]Item, error) { return addItem(items, it), nil })
+	if err != nil {
+		return
+	}
+
+	item.ID = it.ID
+	return
+}
+
+// CheckItem checks or unchecks the item with the given id of the checklist of the task.
+func (s *StorageMySQL) CheckItem(profileId string, id string, itemId string, checked bool, version optional.Option[int]) (err error) {
+	err = s.editItem(profileId, id, version, func(items []Item) ([]Item, error) { return checkItem(items, itemId, checked) })
+	return
+}
+
+// MoveItem moves the item with the given id of the checklist of the task to the given position.
+func (s *StorageMySQL) MoveItem(profileId string, id string, itemId string, position int, version optional.Option[int]) (err error) {
+	err = s.editItem(profileId, id, version, func(items []Item) ([]Item, error) { return moveItem(items, itemId, position) })
+	return
+}
+
+// RemoveItem removes the item with the given id from the checklist of the task.
+func (s *StorageMySQL) RemoveItem(profileId string, id string, itemId string, version optional.Option[int]) (err error) {
+	err = s.editItem(profileId, id, version, func(items []Item) ([]Item, error) { return removeItem(items, itemId) })
+	return
+}
+
+// editItem replaces the checklist of the task with the given id and version with the one returned by the given edit, in a transaction that locks the task.
+func (s *StorageMySQL) editItem(profileId string, id string, version optional.Option[int], edit func(items []Item) ([]Item, error)) (err error) {
+	err = s.transaction(func(tx preparer) (err error) {
+		var pd *pending
+		pd, err = s.begin(tx, profileId, id)
+		if err != nil {
+			return
+		}
+		defer s.record(tx, pd, &err)
+
+		var stored TaskMySQL
+		var permission sql.NullString
+		err = queryRow(s.ctx, tx, QueryGetTaskState, []any{profileId, id}, &stored.OwnerID, &permission, &stored.Status, &stored.SeriesID, &stored.Occurrence, &stored.Version, &stored.Rank, &stored.Checklist, &stored.CreatedAt, &stored.DeletedAt)
+		if err != nil {
+			return
+		}
+		err = authorize(profileId, stored.OwnerID, permission, PermissionEdit)
+		if err != nil {
+			return
+		}
+		if v, e := version.Unwrap(); e == nil && int64(v) != stored.Version.Int64 {
+			err = fmt.Errorf("%w: %s", ErrStorageVersionMismatch, "version")
+			return
+		}
+
+		// validate the task with the checklist
+		var ts *Task
+		ts, err = s.on(tx).Get(profileId, id)
+		if err != nil {
+			return
+		}
+		ts.Checklist, err = edit(stored.Checklist)
+		if err != nil {
+			return
+		}
+		err = s.vl.Validate(ts)
+		if err != nil {
+			err = fmt.Errorf("%w: %v", ErrStorageInvalid, err)
+			return
+		}
+
+		err = exec(s.ctx, tx, QueryUpdateTaskChecklist, ChecklistMySQL(ts.Checklist), id)
+		return
+	})
+	return
+}
+
+// access checks the profile can access the task with the given id (not in the trash) with the wanted permission.
+func (s *StorageMySQL) access(profileId string, id string, want Permission) (err error) {
+	var ownerId, permission sql.NullString
+	err = queryRow(s.ctx, s.conn(), QueryGetTaskAccess, []any{profileId, id}, &ownerId, &permission)
+	if err != nil {
+		return
+	}
+
+	err = authorize(profileId, ownerId, permission, want)
+	return
+}
+
+// own checks the profile owns the task with the given id (not in the trash).
+func (s *StorageMySQL) own(profileId string, id string) (err error) {
+	var ownerId, permission sql.NullString
+	err = queryRow(s.ctx, s.conn(), QueryGetTaskAccess, []any{profileId, id}, &ownerId, &permission)
+	if err != nil {
+		return
+	}
+
+	if ownerId.String != profileId {
+		err = fmt.Errorf("%w: %s", ErrStorageNotFound, "owner")
+		return
+	}
+	return
+}
+
+// ownLocked checks the task with the given id (not in the trash) is owned by the profile, locking it until the end of the transaction.
+func (s *StorageMySQL) ownLocked(tx preparer, profileId string, id string) (err error) {
+	var ownerId sql.NullString
+	err = queryRow(s.ctx, tx, QueryGetTaskOwner, []any{id}, &ownerId)
+	if err != nil {
+		return
+	}
+
+	if ownerId.String != profileId {
+		err = fmt.Errorf("%w: %s", ErrStorageNotFound, "owner")
+		return
+	}
+	return
+}
+
+// authorize checks the profile can access with the wanted permission a task of the given owner, shared with it with the given permission (null if it is not).
+func authorize(profileId string, ownerId sql.NullString, permission sql.NullString, want Permission) (err error) {
+	switch p := granted(profileId, ownerId.String, Permission(permission.String)); {
+	case p == "":
+		err = fmt.Errorf("%w: %s", ErrStorageNotFound, "access")
+	case !p.Allows(want):
+		err = fmt.Errorf("%w: %s", ErrStorageForbidden, want)
+	}
+	return
+}
+
+// checkVersion checks the task with the given id still has the given version (any version if None), locking it until the end of the transaction.
+func checkVersion(ctx context.Context, tx preparer, id string, version optional.Option[int]) (err error) {
+	v, e := version.Unwrap()
+	if e != nil {
+		return
+	}
+
+	var stored sql.NullInt64
+	err = queryRow(ctx, tx, QueryGetTaskVersion, []any{id}, &stored)
+	if err != nil {
+		return
+	}
+	if stored.Int64 != int64(v) {
+		err = fmt.Errorf("%w: %s", ErrStorageVersionMismatch, "version")
+		return
+	}
+	return
+}
+
+// checkParent checks the parent of the task exists (with the same owner) and it is not the task itself or one of its subtasks.
+func checkParent(ctx context.Context, tx preparer, taskMySQL TaskMySQL) (err error) {
+	if !taskMySQL.ParentID.Valid {
+		return
+	}
+
+	// execute statement
+	var ancestors, cycles int
+	err = queryRow(ctx, tx, QueryTaskAncestors, []any{taskMySQL.ParentID.String, taskMySQL.OwnerID.String, taskMySQL.ID.String}, &ancestors, &cycles)
+	if err != nil {
+		return
+	}
+
+	// check ancestors
+	switch {
+	case ancestors == 0:
+		err = fmt.Errorf("%w: %s", ErrStorageInvalid, "parent not found")
+	case cycles > 0:
+		err = fmt.Errorf("%w: %s", ErrStorageCycle, "ancestors")
+	}
+	return
+}
+
+// checkProject checks the project of the task exists and belongs to the owner of the task.
+func checkProject(ctx context.Context, tx preparer, taskMySQL TaskMySQL) (err error) {
+	if !taskMySQL.ProjectID.Valid {
+		return
+	}
+
+	// execute statement
+	var count int
+	err = queryRow(ctx, tx, QueryCountTaskProject, []any{taskMySQL.ProjectID.String, taskMySQL.OwnerID.String}, &count)
+	if err != nil {
+		return
+	}
+
+	// check project
+	if count == 0 {
+		err = fmt.Errorf("%w: %s", ErrStorageInvalid, "project not found")
+		return
+	}
+
+	return
+}
+
+// checkTransition checks the workflow allows the change of status from the stored task to the given one.
+func (s *StorageMySQL) checkTransition(stored TaskMySQL, taskMySQL TaskMySQL) (err error) {
+	from := stored.Status
+	if !from.Valid || from.String == taskMySQL.Status.String {
+		return
+	}
+
+	// validate
+	err = s.vl.Transition(Status(from.String), Status(taskMySQL.Status.String))
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageTransition, "validate")
+		return
+	}
+
+	return
+}
+
+// checkBlockers checks the task has no open blockers (not in the trash), when it is completed (moved to done).
+func checkBlockers(ctx context.Context, tx preparer, taskMySQL TaskMySQL) (err error) {
+	if taskMySQL.Status.String != string(StatusDone) {
+		return
+	}
+
+	// execute statement
+	var open int
+	err = queryRow(ctx, tx, QueryCountOpenBlockers, []any{taskMySQL.ID.String}, &open)
+	if err != nil {
+		return
+	}
+	if open > 0 {
+		err = fmt.Errorf("%w: %s", ErrStorageOpenBlockers, "count")
+		return
+	}
+
+	return
+}
+
+// complete applies the hierarchy rule to the subtasks of the given task, when it is completed (moved to done).
+func (s *StorageMySQL) complete(tx preparer, taskMySQL TaskMySQL) (err error) {
+	if taskMySQL.Status.String != string(StatusDone) {
+		return
+	}
+
+	switch s.cfg.Hierarchy {
+	case HierarchyRestrict:
+		var open int
+		err = queryRow(s.ctx, tx, QueryCountOpenChildren, []any{taskMySQL.ID.String}, &open)
+		if err != nil {
+			return
+		}
+		if open > 0 {
+			err = fmt.Errorf("%w: %s", ErrStorageOpenChildren, "count")
+			return
+		}
+	case HierarchyCascade:
+		_, err = execN(s.ctx, tx, QueryCompleteDescendants, taskMySQL.ID.String, taskMySQL.UpdatedAt)
+	}
+	return
+}
+
+// pending are the writes of a transaction in progress, to hand to the recorder of the storage once they are done (see begin).
+type pending struct {
+	profileId string
+	// ids are the ids of the tasks written, and befores the tasks before the writes (nil if not found)
+	ids 	  []string
+	befores   []*Task
+	// created are the ids of the tasks the writes create
+	created   []string
+}
+
+// create adds the id of a task the writes create (nothing if they are not recorded).
+func (pd *pending) create(id string) {
+	if pd == nil {
+		return
+	}
+	pd.created = append(pd.created, id)
+}
+
+// begin starts the writes on behalf of the profile to the tasks with the given ids: they are read and locked in the transaction before them.
+// - nil if the writes are not recorded
+func (s *StorageMySQL) begin(tx preparer, profileId string, ids ...string) (pd *pending, err error) {
+	if s.rec == nil {
+		return
+	}
+
+	pd = &pending{profileId: profileId, ids: ids, befores: make([]*Task, len(ids))}
+	for i, id := range ids {
+		pd.befores[i], err = s.locked(tx, id)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// record hands the writes that did not fail to the recorder, with the tasks before and after them (read from the transaction).
+// - the writes fail with the error of the recorder
+func (s *StorageMySQL) record(tx preparer, pd *pending, err *error) {
+	if pd == nil || *err != nil {
+		return
+	}
+
+	var ws []*write
+	ids := append(append([]string(nil), pd.ids...), pd.created...)
+	for i, id := range ids {
+		after, e := s.locked(tx, id)
+		if e != nil {
+			*err = e
+			return
+		}
+		if after == nil {
+			continue
+		}
+
+		w := &write{after: after}
+		if i < len(pd.befores) {
+			w.before = pd.befores[i]
+		}
+		ws = append(ws, w)
+	}
+	if len(ws) == 0 {
+		return
+	}
+
+	*err = s.rec(s.ctx, tx, pd.profileId, ws)
+}
+
+// locked returns the task with the given id, locked until the end of the transaction (nil if not found).
+// - the task is read as it is stored, whoever can access it and in the trash too
+func (s *StorageMySQL) locked(tx preparer, id string) (ts *Task, err error) {
+	var taskMySQL TaskMySQL
+	err = queryRow(s.ctx, tx, QueryGetTaskLocked, []any{id}, taskMySQL.fields()...)
+	if err != nil {
+		if errors.Is(err, ErrStorageNotFound) {
+			err = nil
+		}
+		return
+	}
+
+	ts = taskMySQL.serialize()
+	return
+}
+
+// openDescendants returns the ids of the subtasks of the task with the given id that are not completed, sorted.
+func (s *StorageMySQL) openDescendants(tx preparer, id string) (ids []string, err error) {
+	err = queryRows(s.ctx, tx, QueryListOpenDescendants, []any{id}, func(rows *sql.Rows) (err error) {
+		var descendantId string
+		err = rows.Scan(&descendantId)
+		if err != nil {
+			return
+		}
+		ids = append(ids, descendantId)
+		return
+	})
+	return
+}
+
+// queryRow executes the given query and scans its single row into the destination.
+func queryRow(ctx context.Context, db preparer, query string, args []any, dest ...any) (err error) {
+	// prepare statement
+	var stmt *sql.Stmt
+	stmt, err = db.PrepareContext(ctx, query)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "prepare")
+		return
+	}
+	defer stmt.Close()
+
+	// execute statement
+	err = stmt.QueryRowContext(ctx, args...).Scan(dest...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = fmt.Errorf("%w: %s", ErrStorageNotFound, "query row")
+			return
+		}
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "query row")
+		return
+	}
+
+	return
+}
+
+// queryRows executes the given query and scans each of its rows with the given function.
+func queryRows(ctx context.Context, db preparer, query string, args []any, scan func(rows *sql.Rows) (err error)) (err error) {
+	// prepare statement
+	var stmt *sql.Stmt
+	stmt, err = db.PrepareContext(ctx, query)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "prepare")
+		return
+	}
+	defer stmt.Close()
+
+	// execute statement
+	var rows *sql.Rows
+	rows, err = stmt.QueryContext(ctx, args...)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "query")
+		return
+	}
+	defer rows.Close()
+
+	// scan
+	for rows.Next() {
+		err = scan(rows)
+		if err != nil {
+			err = fmt.Errorf("%w: %s", ErrStorageInternal, "scan")
+			return
+		}
+	}
+	if err = rows.Err(); err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "rows")
+		return
+	}
+
+	return
+}
+
+// saveLabels saves the labels of the task with the given id.
+func saveLabels(ctx context.Context, tx preparer, id string, labels []string) (err error) {
+	if len(labels) == 0 {
+		return
+	}
+
+	// prepare statement
+	var stmt *sql.Stmt
+	stmt, err = tx.PrepareContext(ctx, QuerySaveTaskLabel)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "prepare")
+		return
+	}
+	defer stmt.Close()
+
+	// execute statement (once per label)
+	for _, label := range labels {
+		_, err = stmt.ExecContext(ctx, id, label)
+		if err != nil {
+			err = fmt.Errorf("%w: %s", ErrStorageInternal, "exec")
+			return
+		}
+	}
+
+	return
+}
+
+// transaction runs the given operation in a transaction.
+// - the transaction is committed if the operation succeeds and rolled back otherwise
+// - bound to a transaction, the operation is run on it (committed or rolled back by its owner)
+func (s *StorageMySQL) transaction(op func(tx preparer) (err error)) (err error) {
+	if s.tx != nil {
+		err = op(s.tx)
+		return
+	}
+
+	var tx *sql.Tx
+	tx, err = s.db.BeginTx(s.ctx, nil)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "begin")
+		return
+	}
+	defer func () {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+		if err != nil {
+			err = fmt.Errorf("%w: %s", ErrStorageInternal, "commit")
+		}
+	}()
+
+	err = op(s.dl.statements(tx))
+	return
+}
+
+// WithContext returns a copy of the storage that runs its statements with the given context.
+// - bound to a transaction, the copy runs them on it too
+func (s *StorageMySQL) WithContext(ctx context.Context) (st Storage) {
+	cp := *s
+	cp.ctx = ctx
+	st = &cp
+	return
+}
+
+// recording returns a copy of the storage that hands its writes to the recorder, in their transaction.
+// - the copies bound to a transaction hand them too, so each operation of an atomic batch hands its own
+func (s *StorageMySQL) recording(rc recorder) (st Storage) {
+	cp := *s
+	cp.rec = rc
+	st = &cp
+	return
+}
+
+// on returns a copy of the storage that runs its statements on the given transaction.
+func (s *StorageMySQL) on(tx preparer) (st *StorageMySQL) {
+	cp := *s
+	cp.tx = s.dl.statements(tx)
+	st = &cp
+	return
+}
+
+// conn returns the transaction the storage is bound to, or the database.
+func (s *StorageMySQL) conn() (db preparer) {
+	if s.tx != nil {
+		db = s.tx
+		return
+	}
+	db = s.dl.statements(s.db)
+	return
+}
+
+// preparer prepares statements (a database or a transaction).
+type preparer interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// exec executes the given statement over a single task.
+// - no rows affected means the task was not found
+func exec(ctx context.Context, db preparer, query string, args ...any) (err error) {
+	var rowsAffected int64
+	rowsAffected, err = execN(ctx, db, query, args...)
+	if err != nil {
+		return
+	}
+
+	// check rows affected
+	if rowsAffected != 1 {
+		err = fmt.Errorf("%w: %s", ErrStorageNotFound, "rows affected")
+		return
+	}
+
+	return
+}
+
+// execN executes the given statement and returns the amount of rows affected.
+func execN(ctx context.Context, db preparer, query string, args ...any) (n int64, err error) {
+	// prepare statement
+	var stmt *sql.Stmt
+	stmt, err = db.PrepareContext(ctx, query)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "prepare")
+		return
+	}
+	defer stmt.Close()
+
+	// execute statement
+	var result sql.Result
+	result, err = stmt.ExecContext(ctx, args...)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "exec")
+		return
+	}
+
+	// check result
+	n, err = result.RowsAffected()
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "result rows affected")
+		return
+	}
+
+	return
+}
\ No newline at end of file
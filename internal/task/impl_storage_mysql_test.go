@@ -1,13 +1,17 @@
 package task
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/LNMMusic/optional"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 // Tests
@@ -34,25 +38,42 @@ func TestStorageMySQL_Get(t *testing.T) {
 					ID: optional.Some("id"),
 					Title: optional.Some("title"),
 					Description: optional.Some("description"),
-					Completed: optional.Some(true),
+					Status: optional.Some(StatusDone),
+					Labels: []string{"backend", "urgent"},
+					Version: optional.Some(3),
 				},
 				err: nil,
 			},
 			setDatabase: func(mk sqlmock.Sqlmock) {
 				// rows
-				cols := []string{"id", "title", "description", "completed"}
+				cols := []string{"id", "owner_id", "title", "description", "status", "parent_id", "start_at", "due_at", "created_at", "updated_at", "deleted_at", "recurrence", "series_id", "occurrence", "version", "project_id", "rank_key", "checklist", "labels"}
 				rows := sqlmock.NewRows(cols)
 				rows.AddRow(
 					sql.NullString{String: "id", Valid: true},
+					sql.NullString{},
 					sql.NullString{String: "title", Valid: true},
 					sql.NullString{String: "description", Valid: true},
-					sql.NullBool{Bool: true, Valid: true},
+					sql.NullString{String: "done", Valid: true},
+					sql.NullString{},
+					sql.NullTime{},
+					sql.NullTime{},
+					sql.NullTime{},
+					sql.NullTime{},
+					sql.NullTime{},
+					sql.NullString{},
+					sql.NullString{},
+					sql.NullInt64{},
+					sql.NullInt64{Int64: 3, Valid: true},
+					sql.NullString{},
+					sql.NullString{},
+					nil,
+					sql.NullString{String: "backend,urgent", Valid: true},
 				)
 
 				// mock
 				mk.
 					ExpectPrepare(regexp.QuoteMeta(QueryGetTask)).
-					ExpectQuery().WithArgs("id").
+					ExpectQuery().WithArgs("id", "p1", "p1").
 					WillReturnRows(rows)
 			},
 			setValidator: func(mk *ValidatorMock) {},
@@ -65,24 +86,39 @@ func TestStorageMySQL_Get(t *testing.T) {
 					ID: optional.Some("id"),
 					Title: optional.None[string](),
 					Description: optional.None[string](),
-					Completed: optional.None[bool](),
+					Status: optional.None[Status](),
 				},
 			},
 			setDatabase: func(mk sqlmock.Sqlmock) {
 				// rows
-				cols := []string{"id", "title", "description", "completed"}
+				cols := []string{"id", "owner_id", "title", "description", "status", "parent_id", "start_at", "due_at", "created_at", "updated_at", "deleted_at", "recurrence", "series_id", "occurrence", "version", "project_id", "rank_key", "checklist", "labels"}
 				rows := sqlmock.NewRows(cols)
 				rows.AddRow(
 					sql.NullString{String: "id", Valid: true},
+					sql.NullString{},
+					sql.NullString{String: "", Valid: false},
 					sql.NullString{String: "", Valid: false},
 					sql.NullString{String: "", Valid: false},
-					sql.NullBool{Bool: false, Valid: false},
+					sql.NullString{},
+					sql.NullTime{},
+					sql.NullTime{},
+					sql.NullTime{},
+					sql.NullTime{},
+					sql.NullTime{},
+					sql.NullString{},
+					sql.NullString{},
+					sql.NullInt64{},
+					sql.NullInt64{},
+					sql.NullString{},
+					sql.NullString{},
+					nil,
+					sql.NullString{},
 				)
 
 				// mock
 				mk.
 					ExpectPrepare(regexp.QuoteMeta(QueryGetTask)).
-					ExpectQuery().WithArgs("id").
+					ExpectQuery().WithArgs("id", "p1", "p1").
 					WillReturnRows(rows)
 			},
 			setValidator: func(mk *ValidatorMock) {},
@@ -95,24 +131,39 @@ func TestStorageMySQL_Get(t *testing.T) {
 					ID: optional.Some("id"),
 					Title: optional.Some("title"),
 					Description: optional.None[string](),
-					Completed: optional.None[bool](),
+					Status: optional.None[Status](),
 				},
 			},
 			setDatabase: func(mk sqlmock.Sqlmock) {
 				// rows
-				cols := []string{"id", "title", "description", "completed"}
+				cols := []string{"id", "owner_id", "title", "description", "status", "parent_id", "start_at", "due_at", "created_at", "updated_at", "deleted_at", "recurrence", "series_id", "occurrence", "version", "project_id", "rank_key", "checklist", "labels"}
 				rows := sqlmock.NewRows(cols)
 				rows.AddRow(
 					sql.NullString{String: "id", Valid: true},
+					sql.NullString{},
 					sql.NullString{String: "title", Valid: true},
 					sql.NullString{String: "", Valid: false},
-					sql.NullBool{Bool: false, Valid: false},
+					sql.NullString{String: "", Valid: false},
+					sql.NullString{},
+					sql.NullTime{},
+					sql.NullTime{},
+					sql.NullTime{},
+					sql.NullTime{},
+					sql.NullTime{},
+					sql.NullString{},
+					sql.NullString{},
+					sql.NullInt64{},
+					sql.NullInt64{},
+					sql.NullString{},
+					sql.NullString{},
+					nil,
+					sql.NullString{},
 				)
 
 				// mock
 				mk.
 					ExpectPrepare(regexp.QuoteMeta(QueryGetTask)).
-					ExpectQuery().WithArgs("id").
+					ExpectQuery().WithArgs("id", "p1", "p1").
 					WillReturnRows(rows)
 			},
 			setValidator: func(mk *ValidatorMock) {},
@@ -147,7 +198,7 @@ func TestStorageMySQL_Get(t *testing.T) {
 				// mock
 				mk.
 					ExpectPrepare(regexp.QuoteMeta(QueryGetTask)).
-					ExpectQuery().WithArgs("id").
+					ExpectQuery().WithArgs("id", "p1", "p1").
 					WillReturnError(sql.ErrNoRows)
 			},
 			setValidator: func(mk *ValidatorMock) {},
@@ -164,7 +215,7 @@ func TestStorageMySQL_Get(t *testing.T) {
 				// mock
 				mk.
 					ExpectPrepare(regexp.QuoteMeta(QueryGetTask)).
-					ExpectQuery().WithArgs("id").
+					ExpectQuery().WithArgs("id", "p1", "p1").
 					WillReturnError(sql.ErrConnDone)
 			},
 			setValidator: func(mk *ValidatorMock) {},
@@ -183,10 +234,10 @@ func TestStorageMySQL_Get(t *testing.T) {
 			vl := NewValidatorMock()
 			c.setValidator(vl)
 
-			st := NewStorageMySQL(db, vl)
+			st := NewStorageMySQL(db, vl, nil)
 
 			// act
-			ts, err := st.Get(c.input.id)
+			ts, err := st.Get("p1", c.input.id)
 
 			// assert
 			assert.Equal(t, c.output.ts, ts)
@@ -201,9 +252,84 @@ func TestStorageMySQL_Get(t *testing.T) {
 	}
 }
 
-func TestStorageMySQL_Save(t *testing.T) {
-	type input struct {ts *Task}
+func TestStorageMySQL_WithContext(t *testing.T) {
 	type output struct {err error; errMsg string}
+	type testCase struct {
+		// io
+		title  string
+		output output
+		// process
+		act    func(st Storage) (err error)
+	}
+
+	cases := []testCase{
+		// failure cases
+		// -> the request was canceled: nothing is sent to the database
+		{
+			title: "get with a canceled context",
+			output: output{
+				err: ErrStorageInternal,
+				errMsg: "storage internal error: prepare",
+			},
+			act: func(st Storage) (err error) {
+				_, err = st.Get("p1", "id")
+				return
+			},
+		},
+		{
+			title: "move with a canceled context",
+			output: output{
+				err: ErrStorageInternal,
+				errMsg: "storage internal error: begin",
+			},
+			act: func(st Storage) (err error) {
+				err = st.Move("p1", "3", "1", "", optional.None[int]())
+				return
+			},
+		},
+		{
+			title: "atomic batch with a canceled context",
+			output: output{
+				err: ErrStorageInternal,
+				errMsg: "storage internal error: batch",
+			},
+			act: func(st Storage) (err error) {
+				_, err = st.Batch("p1", []*Op{{Kind: OpDelete, ID: "1"}}, ModeAtomic)
+				return
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			st := NewStorageMySQL(db, NewValidatorMock(), nil).WithContext(ctx)
+
+			// act
+			err = c.act(st)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorageMySQL_List(t *testing.T) {
+	type input struct {query *Query}
+	type output struct {pg *Page; err error; errMsg string}
 	type testCase struct {
 		// io
 		title  		 string
@@ -211,236 +337,608 @@ func TestStorageMySQL_Save(t *testing.T) {
 		output 		 output
 		// process
 		setDatabase  func(mk sqlmock.Sqlmock)
-		setValidator func(mk *ValidatorMock)
 	}
 
 	cases := []testCase{
 		// success cases
 		{
-			title: "full task",
-			input: input{ts: &Task{
-				ID: optional.None[string](),
-				Title: optional.Some("title"),
-				Description: optional.Some("description"),
-				Completed: optional.Some(true),
-			}},
-			output: output{err: nil, errMsg: ""},
+			title: "first page with next page",
+			input: input{query: &Query{Size: 1}},
+			output: output{
+				pg: &Page{
+					Tasks: []*Task{
+						{
+							ID: optional.Some("1"),
+							Title: optional.Some("title"),
+							Description: optional.None[string](),
+							Status: optional.Some(StatusDone),
+						},
+					},
+					Next: optional.Some(encodeCursor(&Task{ID: optional.Some("1")}, Sort{})),
+				},
+			},
 			setDatabase: func(mk sqlmock.Sqlmock) {
+				// rows
+				cols := []string{"id", "owner_id", "title", "description", "status", "parent_id", "start_at", "due_at", "created_at", "updated_at", "deleted_at", "recurrence", "series_id", "occurrence", "version", "project_id", "rank_key", "checklist", "labels"}
+				rows := sqlmock.NewRows(cols)
+				rows.AddRow("1", nil, "title", nil, "done", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+				rows.AddRow("2", nil, "title", nil, "todo", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
 				// mock
-				// -> begin
-				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryListTasks + " WHERE owner_id = ? AND deleted_at IS NULL ORDER BY id LIMIT ?")).
+					ExpectQuery().WithArgs("p1", 2).
+					WillReturnRows(rows)
+			},
+		},
+		{
+			title: "last page",
+			input: input{query: &Query{Cursor: encodeCursor(&Task{ID: optional.Some("1")}, Sort{})}},
+			output: output{
+				pg: &Page{
+					Tasks: []*Task{
+						{
+							ID: optional.Some("2"),
+							Title: optional.Some("title"),
+							Description: optional.None[string](),
+							Status: optional.Some(StatusTodo),
+						},
+					},
+					Next: optional.None[string](),
+				},
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				// rows
+				cols := []string{"id", "owner_id", "title", "description", "status", "parent_id", "start_at", "due_at", "created_at", "updated_at", "deleted_at", "recurrence", "series_id", "occurrence", "version", "project_id", "rank_key", "checklist", "labels"}
+				rows := sqlmock.NewRows(cols)
+				rows.AddRow("2", nil, "title", nil, "todo", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 
-				// -> stmt
+				// mock
 				mk.
-					ExpectPrepare(regexp.QuoteMeta(QuerySaveTask)).
-					ExpectExec().WithArgs(
-						sqlmock.AnyArg(),
-						sql.NullString{String: "title", Valid: true},
-						sql.NullString{String: "description", Valid: true},
-						sql.NullBool{Bool: true, Valid: true},							
-					).
-					WillReturnResult(sqlmock.NewResult(1, 1))
-				
-				// -> commit
-				mk.ExpectCommit()
+					ExpectPrepare(regexp.QuoteMeta(QueryListTasks + " WHERE owner_id = ? AND deleted_at IS NULL AND id > ? ORDER BY id LIMIT ?")).
+					ExpectQuery().WithArgs("p1", "1", DefaultPageSize+1).
+					WillReturnRows(rows)
 			},
-			setValidator: func(mk *ValidatorMock) {
+		},
+		{
+			title: "trash",
+			input: input{query: &Query{Deleted: true}},
+			output: output{
+				pg: &Page{
+					Tasks: []*Task{
+						{
+							ID: optional.Some("1"),
+							Title: optional.Some("title"),
+							Description: optional.None[string](),
+							Status: optional.Some(StatusDone),
+							DeletedAt: optional.Some(time.Unix(0, 0)),
+						},
+					},
+					Next: optional.None[string](),
+				},
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				// rows
+				cols := []string{"id", "owner_id", "title", "description", "status", "parent_id", "start_at", "due_at", "created_at", "updated_at", "deleted_at", "recurrence", "series_id", "occurrence", "version", "project_id", "rank_key", "checklist", "labels"}
+				rows := sqlmock.NewRows(cols)
+				rows.AddRow("1", nil, "title", nil, "done", nil, nil, nil, nil, nil, time.Unix(0, 0), nil, nil, nil, nil, nil, nil, nil, nil)
+
+				// mock
 				mk.
-					On("Validate", &Task{
-						ID: optional.None[string](),
-						Title: optional.Some("title"),
-						Description: optional.Some("description"),
-						Completed: optional.Some(true),
-					}).
-					Return(nil)
+					ExpectPrepare(regexp.QuoteMeta(QueryListTasks + " WHERE owner_id = ? AND deleted_at IS NOT NULL ORDER BY id LIMIT ?")).
+					ExpectQuery().WithArgs("p1", DefaultPageSize+1).
+					WillReturnRows(rows)
 			},
 		},
 
-		// failure cases
-		// -> validator
 		{
-			title: "validator error",
-			input: input{ts: &Task{
-				ID: optional.None[string](),
-				Title: optional.None[string](),
-				Description: optional.None[string](),
-				Completed: optional.None[bool](),
+			title: "filtered and sorted page after a cursor",
+			input: input{query: &Query{
+				Cursor: encodeCursor(&Task{ID: optional.Some("1"), Title: optional.Some("b")}, Sort{Field: FieldTitle, Desc: true}),
+				Filter: And{Filters: []Filter{
+					Condition{Field: FieldStatus, Operator: OperatorNe, Value: "done"},
+					Condition{Field: FieldDescription, Operator: OperatorContains, Value: "50%"},
+				}},
+				Sort: Sort{Field: FieldTitle, Desc: true},
 			}},
 			output: output{
-				err: ErrStorageInvalid,
-				errMsg: "storage invalid task: validate",
+				pg: &Page{
+					Tasks: []*Task{
+						{
+							ID: optional.Some("2"),
+							Title: optional.Some("a"),
+							Description: optional.Some("50% done"),
+							Status: optional.Some(StatusTodo),
+						},
+					},
+					Next: optional.None[string](),
+				},
 			},
-			setDatabase: func(mk sqlmock.Sqlmock) {},
-			setValidator: func(mk *ValidatorMock) {
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				// rows
+				cols := []string{"id", "owner_id", "title", "description", "status", "parent_id", "start_at", "due_at", "created_at", "updated_at", "deleted_at", "recurrence", "series_id", "occurrence", "version", "project_id", "rank_key", "checklist", "labels"}
+				rows := sqlmock.NewRows(cols)
+				rows.AddRow("2", nil, "a", "50% done", "todo", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+				// mock
 				mk.
-					On("Validate", &Task{
-						ID: optional.None[string](),
-						Title: optional.None[string](),
-						Description: optional.None[string](),
-						Completed: optional.None[bool](),
-					}).
-					Return(ErrStorageInvalid)
+					ExpectPrepare(regexp.QuoteMeta(QueryListTasks + " WHERE owner_id = ? AND deleted_at IS NULL AND (status <> ? AND description LIKE ?) AND (title < ? OR (title = ? AND id > ?)) ORDER BY title DESC, id LIMIT ?")).
+					ExpectQuery().WithArgs("p1", "done", `%50\%%`, "b", "b", "1", DefaultPageSize+1).
+					WillReturnRows(rows)
 			},
 		},
-		// -> database
 		{
-			title: "init transaction error",
-			input: input{ts: &Task{
-				ID: optional.None[string](),
-				Title: optional.Some("title"),
-				Description: optional.Some("description"),
-				Completed: optional.Some(true),
-			}},
+			title: "filtered by labels",
+			input: input{query: &Query{Filter: And{Filters: []Filter{
+				Condition{Field: FieldLabels, Operator: OperatorEq, Value: "backend"},
+				Condition{Field: FieldLabels, Operator: OperatorEq, Value: "urgent"},
+			}}}},
 			output: output{
-				err: ErrStorageInternal,
-				errMsg: "storage internal error: begin",
+				pg: &Page{
+					Tasks: []*Task{
+						{ID: optional.Some("1"), Title: optional.Some("title"), Status: optional.Some(StatusTodo), Labels: []string{"backend", "urgent"}},
+					},
+					Next: optional.None[string](),
+				},
 			},
 			setDatabase: func(mk sqlmock.Sqlmock) {
+				// rows
+				cols := []string{"id", "owner_id", "title", "description", "status", "parent_id", "start_at", "due_at", "created_at", "updated_at", "deleted_at", "recurrence", "series_id", "occurrence", "version", "project_id", "rank_key", "checklist", "labels"}
+				rows := sqlmock.NewRows(cols)
+				rows.AddRow("1", nil, "title", nil, "todo", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, "backend,urgent")
+
 				// mock
-				mk.ExpectBegin().WillReturnError(sql.ErrConnDone)
-			},
-			setValidator: func(mk *ValidatorMock) {
 				mk.
-					On("Validate", &Task{
-						ID: optional.None[string](),
-						Title: optional.Some("title"),
-						Description: optional.Some("description"),
-						Completed: optional.Some(true),
-					}).
-					Return(nil)
+					ExpectPrepare(regexp.QuoteMeta(QueryListTasks + " WHERE owner_id = ? AND deleted_at IS NULL AND (id IN (SELECT task_id FROM task_labels WHERE label = ?) AND id IN (SELECT task_id FROM task_labels WHERE label = ?)) ORDER BY id LIMIT ?")).
+					ExpectQuery().WithArgs("p1", "backend", "urgent", DefaultPageSize+1).
+					WillReturnRows(rows)
 			},
 		},
 		{
-			title: "prepare statement error",
-			input: input{ts: &Task{
-				ID: optional.None[string](),
-				Title: optional.Some("title"),
-				Description: optional.Some("description"),
-				Completed: optional.Some(true),
+			title: "due range sorted by descending due date after a cursor",
+			input: input{query: &Query{
+				Cursor: encodeCursor(&Task{ID: optional.Some("1"), DueAt: optional.Some(time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC))}, Sort{Field: FieldDueAt, Desc: true}),
+				Filter: Condition{Field: FieldDueAt, Operator: OperatorGte, Value: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+				Sort: Sort{Field: FieldDueAt, Desc: true},
 			}},
 			output: output{
-				err: ErrStorageInternal,
-				errMsg: "storage internal error: prepare",
+				pg: &Page{
+					Tasks: []*Task{
+						{ID: optional.Some("2"), Title: optional.Some("title"), Status: optional.Some(StatusTodo), DueAt: optional.Some(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC))},
+					},
+					Next: optional.None[string](),
+				},
 			},
 			setDatabase: func(mk sqlmock.Sqlmock) {
-				// mock
-				// -> begin
-				mk.ExpectBegin()
-
-				// -> stmt
-				mk.
-					ExpectPrepare(regexp.QuoteMeta(QuerySaveTask)).
-					WillReturnError(sql.ErrConnDone)
+				// rows
+				cols := []string{"id", "owner_id", "title", "description", "status", "parent_id", "start_at", "due_at", "created_at", "updated_at", "deleted_at", "recurrence", "series_id", "occurrence", "version", "project_id", "rank_key", "checklist", "labels"}
+				rows := sqlmock.NewRows(cols)
+				rows.AddRow("2", nil, "title", nil, "todo", nil, nil, time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 
-				// -> commit
-				mk.ExpectRollback()
-			},
-			setValidator: func(mk *ValidatorMock) {
+				// mock
+				due := time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)
 				mk.
-					On("Validate", &Task{
-						ID: optional.None[string](),
-						Title: optional.Some("title"),
-						Description: optional.Some("description"),
-						Completed: optional.Some(true),
-					}).
-					Return(nil)
+					ExpectPrepare(regexp.QuoteMeta(QueryListTasks + " WHERE owner_id = ? AND deleted_at IS NULL AND due_at >= ? AND (due_at < ? OR (due_at = ? AND id > ?) OR due_at IS NULL) ORDER BY due_at DESC, id LIMIT ?")).
+					ExpectQuery().WithArgs("p1", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), due, due, "1", DefaultPageSize+1).
+					WillReturnRows(rows)
 			},
 		},
 		{
-			title: "execute statement error",
-			input: input{ts: &Task{
-				ID: optional.None[string](),
-				Title: optional.Some("title"),
-				Description: optional.Some("description"),
-				Completed: optional.Some(true),
+			title: "sorted by due date after a cursor without due date",
+			input: input{query: &Query{
+				Cursor: encodeCursor(&Task{ID: optional.Some("1")}, Sort{Field: FieldDueAt}),
+				Sort: Sort{Field: FieldDueAt},
 			}},
 			output: output{
-				err: ErrStorageInternal,
-				errMsg: "storage internal error: exec",
+				pg: &Page{Tasks: []*Task{}, Next: optional.None[string]()},
 			},
 			setDatabase: func(mk sqlmock.Sqlmock) {
-				// mock
-				// -> begin
-				mk.ExpectBegin()
+				// rows
+				cols := []string{"id", "owner_id", "title", "description", "status", "parent_id", "start_at", "due_at", "created_at", "updated_at", "deleted_at", "recurrence", "series_id", "occurrence", "version", "project_id", "rank_key", "checklist", "labels"}
+				rows := sqlmock.NewRows(cols)
 
-				// -> stmt
+				// mock
 				mk.
-					ExpectPrepare(regexp.QuoteMeta(QuerySaveTask)).
-					ExpectExec().WithArgs(
-						sqlmock.AnyArg(),
-						sql.NullString{String: "title", Valid: true},
-						sql.NullString{String: "description", Valid: true},
-						sql.NullBool{Bool: true, Valid: true},							
-					).
-					WillReturnError(sql.ErrConnDone)
+					ExpectPrepare(regexp.QuoteMeta(QueryListTasks + " WHERE owner_id = ? AND deleted_at IS NULL AND ((due_at IS NULL AND id > ?) OR due_at IS NOT NULL) ORDER BY due_at ASC, id LIMIT ?")).
+					ExpectQuery().WithArgs("p1", "1", DefaultPageSize+1).
+					WillReturnRows(rows)
+			},
+		},
 
-				// -> commit
-				mk.ExpectRollback()
+		// failure cases
+		{
+			title: "invalid cursor",
+			input: input{query: &Query{Cursor: "invalid"}},
+			output: output{
+				pg: nil,
+				err: ErrStorageInvalidQuery,
+				errMsg: "storage invalid query: cursor",
 			},
-			setValidator: func(mk *ValidatorMock) {
+			setDatabase: func(mk sqlmock.Sqlmock) {},
+		},
+		{
+			title: "prepare error",
+			input: input{query: &Query{}},
+			output: output{
+				pg: nil,
+				err: ErrStorageInternal,
+				errMsg: "storage internal error: prepare",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				// mock
 				mk.
-					On("Validate", &Task{
-						ID: optional.None[string](),
-						Title: optional.Some("title"),
-						Description: optional.Some("description"),
-						Completed: optional.Some(true),
-					}).
-					Return(nil)
+					ExpectPrepare(regexp.QuoteMeta(QueryListTasks + " WHERE owner_id = ? AND deleted_at IS NULL ORDER BY id LIMIT ?")).
+					WillReturnError(sql.ErrConnDone)
 			},
 		},
 		{
-			title: "rows affected error",
-			input: input{ts: &Task{
-				ID: optional.None[string](),
-				Title: optional.Some("title"),
-				Description: optional.Some("description"),
-				Completed: optional.Some(true),
-			}},
+			title: "query error",
+			input: input{query: &Query{}},
 			output: output{
+				pg: nil,
 				err: ErrStorageInternal,
-				errMsg: "storage internal error: result rows affected",
+				errMsg: "storage internal error: query",
 			},
 			setDatabase: func(mk sqlmock.Sqlmock) {
 				// mock
-				// -> begin
-				mk.ExpectBegin()
-
-				// -> stmt
 				mk.
-					ExpectPrepare(regexp.QuoteMeta(QuerySaveTask)).
-					ExpectExec().WithArgs(
-						sqlmock.AnyArg(),
+					ExpectPrepare(regexp.QuoteMeta(QueryListTasks + " WHERE owner_id = ? AND deleted_at IS NULL ORDER BY id LIMIT ?")).
+					ExpectQuery().WithArgs("p1", DefaultPageSize+1).
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+		{
+			title: "unknown filter field",
+			input: input{query: &Query{Filter: Condition{Field: "unknown", Operator: OperatorEq, Value: "value"}}},
+			output: output{
+				pg: nil,
+				err: ErrStorageInvalidQuery,
+				errMsg: "storage invalid query: query unknown field: unknown",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {},
+		},
+		{
+			title: "rows error",
+			input: input{query: &Query{}},
+			output: output{
+				pg: nil,
+				err: ErrStorageInternal,
+				errMsg: "storage internal error: rows",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				// rows
+				cols := []string{"id", "owner_id", "title", "description", "status", "parent_id", "start_at", "due_at", "created_at", "updated_at", "deleted_at", "recurrence", "series_id", "occurrence", "version", "project_id", "rank_key", "checklist", "labels"}
+				rows := sqlmock.NewRows(cols)
+				rows.AddRow("1", nil, "title", nil, "done", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+				rows.RowError(0, sql.ErrConnDone)
+
+				// mock
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryListTasks + " WHERE owner_id = ? AND deleted_at IS NULL ORDER BY id LIMIT ?")).
+					ExpectQuery().WithArgs("p1", DefaultPageSize+1).
+					WillReturnRows(rows)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			vl := NewValidatorMock()
+
+			st := NewStorageMySQL(db, vl, nil)
+
+			// act
+			pg, err := st.List("p1", c.input.query)
+
+			// assert
+			assert.Equal(t, c.output.pg, pg)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+			vl.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStorageMySQL_Save(t *testing.T) {
+	type input struct {ts *Task}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+		setValidator func(mk *ValidatorMock)
+	}
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "full task",
+			input: input{ts: &Task{
+				ID: optional.None[string](),
+				OwnerID: optional.Some("p1"),
+				Title: optional.Some("title"),
+				Description: optional.Some("description"),
+				Status: optional.Some(StatusDone),
+			}},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				// mock
+				// -> begin
+				mk.ExpectBegin()
+
+				// -> stmt
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryLastTaskRank)).
+					ExpectQuery().WithArgs(sql.NullString{String: "p1", Valid: true}).
+					WillReturnRows(sqlmock.NewRows([]string{"rank"}).AddRow(nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QuerySaveTask)).
+					ExpectExec().WithArgs(
+						sqlmock.AnyArg(),
+						sql.NullString{String: "p1", Valid: true},
 						sql.NullString{String: "title", Valid: true},
 						sql.NullString{String: "description", Valid: true},
-						sql.NullBool{Bool: true, Valid: true},							
+						sql.NullString{String: "done", Valid: true},
+						sql.NullString{},
+						sql.NullTime{},
+						sql.NullTime{},
+						sqlmock.AnyArg(),
+						sqlmock.AnyArg(),
+						sql.NullString{},
+						sql.NullString{},
+						sql.NullInt64{},
+						sql.NullString{},
+						sql.NullString{String: "i", Valid: true},
+						nil,
 					).
-					WillReturnResult(sqlmock.NewErrorResult(sql.ErrConnDone))
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				
+				// -> commit
+				mk.ExpectCommit()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.
+					On("Validate", &Task{
+						ID: optional.None[string](),
+						OwnerID: optional.Some("p1"),
+						Title: optional.Some("title"),
+						Description: optional.Some("description"),
+						Status: optional.Some(StatusDone),
+					}).
+					Return(nil)
+			},
+		},
+
+		{
+			title: "task with labels",
+			input: input{ts: &Task{
+				ID: optional.None[string](),
+				OwnerID: optional.Some("p1"),
+				Title: optional.Some("title"),
+				Status: optional.Some(StatusTodo),
+				Labels: []string{"urgent", "backend"},
+			}},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				// mock
+				// -> begin
+				mk.ExpectBegin()
+
+				// -> stmt
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryLastTaskRank)).
+					ExpectQuery().WithArgs(sql.NullString{String: "p1", Valid: true}).
+					WillReturnRows(sqlmock.NewRows([]string{"rank"}).AddRow(nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QuerySaveTask)).
+					ExpectExec().
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				stmt := mk.ExpectPrepare(regexp.QuoteMeta(QuerySaveTaskLabel))
+				stmt.ExpectExec().WithArgs(sqlmock.AnyArg(), "urgent").WillReturnResult(sqlmock.NewResult(0, 1))
+				stmt.ExpectExec().WithArgs(sqlmock.AnyArg(), "backend").WillReturnResult(sqlmock.NewResult(0, 1))
+
+				// -> commit
+				mk.ExpectCommit()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+		{
+			title: "subtask",
+			input: input{ts: &Task{
+				ID: optional.None[string](),
+				OwnerID: optional.Some("p1"),
+				Title: optional.Some("title"),
+				Status: optional.Some(StatusTodo),
+				ParentID: optional.Some("parent"),
+			}},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				// mock
+				// -> begin
+				mk.ExpectBegin()
+
+				// -> stmt
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryTaskAncestors)).
+					ExpectQuery().WithArgs("parent", "p1", sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"ancestors", "cycles"}).AddRow(1, 0))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryLastTaskRank)).
+					ExpectQuery().WithArgs(sql.NullString{String: "p1", Valid: true}).
+					WillReturnRows(sqlmock.NewRows([]string{"rank"}).AddRow(nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QuerySaveTask)).
+					ExpectExec().
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				// -> commit
+				mk.ExpectCommit()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+		{
+			title: "task in a project",
+			input: input{ts: &Task{
+				ID: optional.None[string](),
+				OwnerID: optional.Some("p1"),
+				Title: optional.Some("title"),
+				Status: optional.Some(StatusTodo),
+				ProjectID: optional.Some("project"),
+			}},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				// mock
+				// -> begin
+				mk.ExpectBegin()
+
+				// -> stmt
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryCountTaskProject)).
+					ExpectQuery().WithArgs("project", "p1").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryLastTaskRank)).
+					ExpectQuery().WithArgs(sql.NullString{String: "p1", Valid: true}).
+					WillReturnRows(sqlmock.NewRows([]string{"rank"}).AddRow(nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QuerySaveTask)).
+					ExpectExec().
+					WillReturnResult(sqlmock.NewResult(1, 1))
 
 				// -> commit
+				mk.ExpectCommit()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+		// failure cases
+		// -> validator
+		{
+			title: "validator error",
+			input: input{ts: &Task{
+				ID: optional.None[string](),
+				OwnerID: optional.Some("p1"),
+				Title: optional.None[string](),
+				Description: optional.None[string](),
+				Status: optional.None[Status](),
+			}},
+			output: output{
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: storage invalid task",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {},
+			setValidator: func(mk *ValidatorMock) {
+				mk.
+					On("Validate", &Task{
+						ID: optional.None[string](),
+						OwnerID: optional.Some("p1"),
+						Title: optional.None[string](),
+						Description: optional.None[string](),
+						Status: optional.None[Status](),
+					}).
+					Return(ErrStorageInvalid)
+			},
+		},
+		{
+			title: "owner required",
+			input: input{ts: &Task{Title: optional.Some("title"), Status: optional.Some(StatusTodo)}},
+			output: output{
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: owner",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {},
+			setValidator: func(mk *ValidatorMock) {
+				mk.
+					On("Validate", &Task{Title: optional.Some("title"), Status: optional.Some(StatusTodo)}).
+					Return(nil)
+			},
+		},
+		{
+			title: "project not found",
+			input: input{ts: &Task{
+				ID: optional.None[string](),
+				OwnerID: optional.Some("p1"),
+				Title: optional.Some("title"),
+				Status: optional.Some(StatusTodo),
+				ProjectID: optional.Some("project"),
+			}},
+			output: output{
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: project not found",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryCountTaskProject)).
+					ExpectQuery().WithArgs("project", "p1").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 				mk.ExpectRollback()
 			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+		// -> database
+		{
+			title: "init transaction error",
+			input: input{ts: &Task{
+				ID: optional.None[string](),
+				OwnerID: optional.Some("p1"),
+				Title: optional.Some("title"),
+				Description: optional.Some("description"),
+				Status: optional.Some(StatusDone),
+			}},
+			output: output{
+				err: ErrStorageInternal,
+				errMsg: "storage internal error: begin",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				// mock
+				mk.ExpectBegin().WillReturnError(sql.ErrConnDone)
+			},
 			setValidator: func(mk *ValidatorMock) {
 				mk.
 					On("Validate", &Task{
 						ID: optional.None[string](),
+						OwnerID: optional.Some("p1"),
 						Title: optional.Some("title"),
 						Description: optional.Some("description"),
-						Completed: optional.Some(true),
+						Status: optional.Some(StatusDone),
 					}).
 					Return(nil)
 			},
 		},
 		{
-			title: "rows affected not 1",
+			title: "prepare statement error",
 			input: input{ts: &Task{
 				ID: optional.None[string](),
+				OwnerID: optional.Some("p1"),
 				Title: optional.Some("title"),
 				Description: optional.Some("description"),
-				Completed: optional.Some(true),
+				Status: optional.Some(StatusDone),
 			}},
 			output: output{
 				err: ErrStorageInternal,
-				errMsg: "storage internal error: rows affected",
+				errMsg: "storage internal error: prepare",
 			},
 			setDatabase: func(mk sqlmock.Sqlmock) {
 				// mock
@@ -448,15 +946,13 @@ func TestStorageMySQL_Save(t *testing.T) {
 				mk.ExpectBegin()
 
 				// -> stmt
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryLastTaskRank)).
+					ExpectQuery().WithArgs(sql.NullString{String: "p1", Valid: true}).
+					WillReturnRows(sqlmock.NewRows([]string{"rank"}).AddRow(nil))
 				mk.
 					ExpectPrepare(regexp.QuoteMeta(QuerySaveTask)).
-					ExpectExec().WithArgs(
-						sqlmock.AnyArg(),
-						sql.NullString{String: "title", Valid: true},
-						sql.NullString{String: "description", Valid: true},
-						sql.NullBool{Bool: true, Valid: true},							
-					).
-					WillReturnResult(sqlmock.NewResult(1, 0))
+					WillReturnError(sql.ErrConnDone)
 
 				// -> commit
 				mk.ExpectRollback()
@@ -465,28 +961,209 @@ func TestStorageMySQL_Save(t *testing.T) {
 				mk.
 					On("Validate", &Task{
 						ID: optional.None[string](),
+						OwnerID: optional.Some("p1"),
 						Title: optional.Some("title"),
 						Description: optional.Some("description"),
-						Completed: optional.Some(true),
+						Status: optional.Some(StatusDone),
 					}).
 					Return(nil)
 			},
 		},
-	}
-
-	// run tests
-	for _, c := range cases {
-		t.Run(c.title, func(t *testing.T) {
-			// arrange
-			db, mk, err := sqlmock.New()
-			assert.NoError(t, err)
-			defer db.Close()
-			c.setDatabase(mk)
+		{
+			title: "execute statement error",
+			input: input{ts: &Task{
+				ID: optional.None[string](),
+				OwnerID: optional.Some("p1"),
+				Title: optional.Some("title"),
+				Description: optional.Some("description"),
+				Status: optional.Some(StatusDone),
+			}},
+			output: output{
+				err: ErrStorageInternal,
+				errMsg: "storage internal error: exec",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				// mock
+				// -> begin
+				mk.ExpectBegin()
+
+				// -> stmt
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryLastTaskRank)).
+					ExpectQuery().WithArgs(sql.NullString{String: "p1", Valid: true}).
+					WillReturnRows(sqlmock.NewRows([]string{"rank"}).AddRow(nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QuerySaveTask)).
+					ExpectExec().WithArgs(
+						sqlmock.AnyArg(),
+						sql.NullString{String: "p1", Valid: true},
+						sql.NullString{String: "title", Valid: true},
+						sql.NullString{String: "description", Valid: true},
+						sql.NullString{String: "done", Valid: true},
+						sql.NullString{},
+						sql.NullTime{},
+						sql.NullTime{},
+						sqlmock.AnyArg(),
+						sqlmock.AnyArg(),
+						sql.NullString{},
+						sql.NullString{},
+						sql.NullInt64{},
+						sql.NullString{},
+						sql.NullString{String: "i", Valid: true},
+						nil,
+					).
+					WillReturnError(sql.ErrConnDone)
+
+				// -> commit
+				mk.ExpectRollback()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.
+					On("Validate", &Task{
+						ID: optional.None[string](),
+						OwnerID: optional.Some("p1"),
+						Title: optional.Some("title"),
+						Description: optional.Some("description"),
+						Status: optional.Some(StatusDone),
+					}).
+					Return(nil)
+			},
+		},
+		{
+			title: "rows affected error",
+			input: input{ts: &Task{
+				ID: optional.None[string](),
+				OwnerID: optional.Some("p1"),
+				Title: optional.Some("title"),
+				Description: optional.Some("description"),
+				Status: optional.Some(StatusDone),
+			}},
+			output: output{
+				err: ErrStorageInternal,
+				errMsg: "storage internal error: result rows affected",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				// mock
+				// -> begin
+				mk.ExpectBegin()
+
+				// -> stmt
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryLastTaskRank)).
+					ExpectQuery().WithArgs(sql.NullString{String: "p1", Valid: true}).
+					WillReturnRows(sqlmock.NewRows([]string{"rank"}).AddRow(nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QuerySaveTask)).
+					ExpectExec().WithArgs(
+						sqlmock.AnyArg(),
+						sql.NullString{String: "p1", Valid: true},
+						sql.NullString{String: "title", Valid: true},
+						sql.NullString{String: "description", Valid: true},
+						sql.NullString{String: "done", Valid: true},
+						sql.NullString{},
+						sql.NullTime{},
+						sql.NullTime{},
+						sqlmock.AnyArg(),
+						sqlmock.AnyArg(),
+						sql.NullString{},
+						sql.NullString{},
+						sql.NullInt64{},
+						sql.NullString{},
+						sql.NullString{String: "i", Valid: true},
+						nil,
+					).
+					WillReturnResult(sqlmock.NewErrorResult(sql.ErrConnDone))
+
+				// -> commit
+				mk.ExpectRollback()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.
+					On("Validate", &Task{
+						ID: optional.None[string](),
+						OwnerID: optional.Some("p1"),
+						Title: optional.Some("title"),
+						Description: optional.Some("description"),
+						Status: optional.Some(StatusDone),
+					}).
+					Return(nil)
+			},
+		},
+		{
+			title: "rows affected not 1",
+			input: input{ts: &Task{
+				ID: optional.None[string](),
+				OwnerID: optional.Some("p1"),
+				Title: optional.Some("title"),
+				Description: optional.Some("description"),
+				Status: optional.Some(StatusDone),
+			}},
+			output: output{
+				err: ErrStorageInternal,
+				errMsg: "storage internal error: rows affected",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				// mock
+				// -> begin
+				mk.ExpectBegin()
+
+				// -> stmt
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryLastTaskRank)).
+					ExpectQuery().WithArgs(sql.NullString{String: "p1", Valid: true}).
+					WillReturnRows(sqlmock.NewRows([]string{"rank"}).AddRow(nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QuerySaveTask)).
+					ExpectExec().WithArgs(
+						sqlmock.AnyArg(),
+						sql.NullString{String: "p1", Valid: true},
+						sql.NullString{String: "title", Valid: true},
+						sql.NullString{String: "description", Valid: true},
+						sql.NullString{String: "done", Valid: true},
+						sql.NullString{},
+						sql.NullTime{},
+						sql.NullTime{},
+						sqlmock.AnyArg(),
+						sqlmock.AnyArg(),
+						sql.NullString{},
+						sql.NullString{},
+						sql.NullInt64{},
+						sql.NullString{},
+						sql.NullString{String: "i", Valid: true},
+						nil,
+					).
+					WillReturnResult(sqlmock.NewResult(1, 0))
+
+				// -> commit
+				mk.ExpectRollback()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.
+					On("Validate", &Task{
+						ID: optional.None[string](),
+						OwnerID: optional.Some("p1"),
+						Title: optional.Some("title"),
+						Description: optional.Some("description"),
+						Status: optional.Some(StatusDone),
+					}).
+					Return(nil)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
 
 			vl := NewValidatorMock()
 			c.setValidator(vl)
 
-			st := NewStorageMySQL(db, vl)
+			st := NewStorageMySQL(db, vl, nil)
 
 			// act
 			err = st.Save(c.input.ts)
@@ -501,4 +1178,2575 @@ func TestStorageMySQL_Save(t *testing.T) {
 			vl.AssertExpectations(t)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestStorageMySQL_Update(t *testing.T) {
+	type input struct {ts *Task}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+		setValidator func(mk *ValidatorMock)
+		// cfg is the storage config (nil for the default one)
+		cfg 		 *Config
+	}
+
+	ts := &Task{
+		ID: optional.Some("id"),
+		Title: optional.Some("title"),
+		Description: optional.None[string](),
+		Status: optional.Some(StatusDone),
+	}
+	subtask := &Task{
+		ID: optional.Some("id"),
+		Title: optional.Some("title"),
+		Status: optional.Some(StatusTodo),
+		ParentID: optional.Some("parent"),
+	}
+	completed := &Task{
+		ID: optional.Some("id"),
+		Title: optional.Some("title"),
+		Status: optional.Some(StatusDone),
+	}
+	labeled := &Task{
+		ID: optional.Some("id"),
+		Title: optional.Some("title"),
+		Status: optional.Some(StatusDone),
+		Labels: []string{"backend", "urgent"},
+	}
+	recurring := &Task{
+		ID: optional.Some("id"),
+		Title: optional.Some("title"),
+		Status: optional.Some(StatusDone),
+		DueAt: optional.Some(time.Date(2023, 1, 31, 9, 0, 0, 0, time.UTC)),
+		Recurrence: optional.Some("FREQ=MONTHLY"),
+	}
+	stale := &Task{
+		ID: optional.Some("id"),
+		Title: optional.Some("title"),
+		Status: optional.Some(StatusDone),
+		Version: optional.Some(2),
+	}
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "full task",
+			input: input{ts: ts},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskState)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission", "status", "series_id", "occurrence", "version", "rank_key", "checklist", "created_at", "deleted_at"}).AddRow("p1", nil, "done", nil, nil, 1, nil, nil, nil, nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryCountOpenBlockers)).
+					ExpectQuery().WithArgs("id").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryUpdateTask)).
+					ExpectExec().WithArgs(
+						sql.NullString{String: "title", Valid: true},
+						sql.NullString{String: "", Valid: false},
+						sql.NullString{String: "done", Valid: true},
+						sql.NullString{},
+						sql.NullTime{},
+						sql.NullTime{},
+						sqlmock.AnyArg(),
+						sql.NullString{},
+						sql.NullString{},
+						sql.NullInt64{},
+						sql.NullString{},
+						sql.NullString{String: "id", Valid: true},
+						sql.NullInt64{Int64: 1, Valid: true},
+					).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryClearTaskLabels)).
+					ExpectExec().WithArgs(sql.NullString{String: "id", Valid: true}).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mk.ExpectCommit()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", ts).Return(nil)
+			},
+		},
+		{
+			title: "task with labels",
+			input: input{ts: labeled},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskState)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission", "status", "series_id", "occurrence", "version", "rank_key", "checklist", "created_at", "deleted_at"}).AddRow("p1", nil, "done", nil, nil, 1, nil, nil, nil, nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryCountOpenBlockers)).
+					ExpectQuery().WithArgs("id").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryUpdateTask)).
+					ExpectExec().
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryClearTaskLabels)).
+					ExpectExec().WithArgs(sql.NullString{String: "id", Valid: true}).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				stmt := mk.ExpectPrepare(regexp.QuoteMeta(QuerySaveTaskLabel))
+				stmt.ExpectExec().WithArgs("id", "backend").WillReturnResult(sqlmock.NewResult(0, 1))
+				stmt.ExpectExec().WithArgs("id", "urgent").WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectCommit()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", labeled).Return(nil)
+			},
+		},
+		{
+			title: "complete a task cascading down to its subtasks",
+			input: input{ts: completed},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskState)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission", "status", "series_id", "occurrence", "version", "rank_key", "checklist", "created_at", "deleted_at"}).AddRow("p1", nil, "done", nil, nil, 1, nil, nil, nil, nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryCountOpenBlockers)).
+					ExpectQuery().WithArgs("id").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryCompleteDescendants)).
+					ExpectExec().WithArgs("id", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryUpdateTask)).
+					ExpectExec().
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryClearTaskLabels)).
+					ExpectExec().
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mk.ExpectCommit()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", completed).Return(nil)
+			},
+			cfg: &Config{Hierarchy: HierarchyCascade},
+		},
+		{
+			title: "complete a recurring task saving its next occurrence",
+			input: input{ts: recurring},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskState)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission", "status", "series_id", "occurrence", "version", "rank_key", "checklist", "created_at", "deleted_at"}).AddRow("p1", nil, "todo", "series", 1, 1, nil, nil, nil, nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryCountOpenBlockers)).
+					ExpectQuery().WithArgs("id").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryUpdateTask)).
+					ExpectExec().WithArgs(
+						sql.NullString{String: "title", Valid: true},
+						sql.NullString{},
+						sql.NullString{String: "done", Valid: true},
+						sql.NullString{},
+						sql.NullTime{},
+						sql.NullTime{Time: time.Date(2023, 1, 31, 9, 0, 0, 0, time.UTC), Valid: true},
+						sqlmock.AnyArg(),
+						sql.NullString{String: "FREQ=MONTHLY", Valid: true},
+						sql.NullString{String: "series", Valid: true},
+						sql.NullInt64{Int64: 1, Valid: true},
+						sql.NullString{},
+						sql.NullString{String: "id", Valid: true},
+						sql.NullInt64{Int64: 1, Valid: true},
+					).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryClearTaskLabels)).
+					ExpectExec().
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryLastTaskRank)).
+					ExpectQuery().WithArgs(sql.NullString{String: "p1", Valid: true}).
+					WillReturnRows(sqlmock.NewRows([]string{"rank"}).AddRow(nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QuerySaveTask)).
+					ExpectExec().WithArgs(
+						sqlmock.AnyArg(),
+						sql.NullString{String: "p1", Valid: true},
+						sql.NullString{String: "title", Valid: true},
+						sql.NullString{},
+						sql.NullString{String: "todo", Valid: true},
+						sql.NullString{},
+						sql.NullTime{},
+						sql.NullTime{Time: time.Date(2023, 3, 31, 9, 0, 0, 0, time.UTC), Valid: true},
+						sqlmock.AnyArg(),
+						sqlmock.AnyArg(),
+						sql.NullString{String: "FREQ=MONTHLY", Valid: true},
+						sql.NullString{String: "series", Valid: true},
+						sql.NullInt64{Int64: 2, Valid: true},
+						sql.NullString{},
+						sql.NullString{String: "i", Valid: true},
+						nil,
+					).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectCommit()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", recurring).Return(nil)
+				mk.On("Transition", StatusTodo, StatusDone).Return(nil)
+			},
+		},
+
+		// failure cases
+		{
+			title: "stale version",
+			input: input{ts: stale},
+			output: output{
+				err: ErrStorageVersionMismatch,
+				errMsg: "storage task version mismatch: version",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskState)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission", "status", "series_id", "occurrence", "version", "rank_key", "checklist", "created_at", "deleted_at"}).AddRow("p1", nil, "done", nil, nil, 3, nil, nil, nil, nil))
+				mk.ExpectRollback()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", stale).Return(nil)
+			},
+		},
+		{
+			title: "subtask of its own subtask",
+			input: input{ts: subtask},
+			output: output{
+				err: ErrStorageCycle,
+				errMsg: "storage invalid task: cycle: ancestors",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskState)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission", "status", "series_id", "occurrence", "version", "rank_key", "checklist", "created_at", "deleted_at"}).AddRow("p1", nil, "todo", nil, nil, 1, nil, nil, nil, nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryTaskAncestors)).
+					ExpectQuery().WithArgs("parent", "p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"ancestors", "cycles"}).AddRow(2, 1))
+				mk.ExpectRollback()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", subtask).Return(nil)
+			},
+		},
+		{
+			title: "subtask of a task that does not exist",
+			input: input{ts: subtask},
+			output: output{
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: parent not found",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskState)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission", "status", "series_id", "occurrence", "version", "rank_key", "checklist", "created_at", "deleted_at"}).AddRow("p1", nil, "todo", nil, nil, 1, nil, nil, nil, nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryTaskAncestors)).
+					ExpectQuery().WithArgs("parent", "p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"ancestors", "cycles"}).AddRow(0, 0))
+				mk.ExpectRollback()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", subtask).Return(nil)
+			},
+		},
+		{
+			title: "complete a task blocked by open tasks",
+			input: input{ts: completed},
+			output: output{
+				err: ErrStorageOpenBlockers,
+				errMsg: "storage invalid task: open blockers: count",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskState)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission", "status", "series_id", "occurrence", "version", "rank_key", "checklist", "created_at", "deleted_at"}).AddRow("p1", nil, "done", nil, nil, 1, nil, nil, nil, nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryCountOpenBlockers)).
+					ExpectQuery().WithArgs("id").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mk.ExpectRollback()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", completed).Return(nil)
+			},
+		},
+		{
+			title: "complete a task with open subtasks",
+			input: input{ts: completed},
+			output: output{
+				err: ErrStorageOpenChildren,
+				errMsg: "storage invalid task: open children: count",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskState)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission", "status", "series_id", "occurrence", "version", "rank_key", "checklist", "created_at", "deleted_at"}).AddRow("p1", nil, "done", nil, nil, 1, nil, nil, nil, nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryCountOpenBlockers)).
+					ExpectQuery().WithArgs("id").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryCountOpenChildren)).
+					ExpectQuery().WithArgs("id").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mk.ExpectRollback()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", completed).Return(nil)
+			},
+			cfg: &Config{Hierarchy: HierarchyRestrict},
+		},
+		{
+			title: "validator error",
+			input: input{ts: ts},
+			output: output{
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: validator field required",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", ts).Return(ErrValidatorFieldRequired)
+			},
+		},
+		{
+			title: "prepare statement error",
+			input: input{ts: ts},
+			output: output{
+				err: ErrStorageInternal,
+				errMsg: "storage internal error: prepare",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskState)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission", "status", "series_id", "occurrence", "version", "rank_key", "checklist", "created_at", "deleted_at"}).AddRow("p1", nil, "done", nil, nil, 1, nil, nil, nil, nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryCountOpenBlockers)).
+					ExpectQuery().WithArgs("id").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryUpdateTask)).
+					WillReturnError(sql.ErrConnDone)
+				mk.ExpectRollback()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", ts).Return(nil)
+			},
+		},
+		{
+			title: "execute statement error",
+			input: input{ts: ts},
+			output: output{
+				err: ErrStorageInternal,
+				errMsg: "storage internal error: exec",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskState)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission", "status", "series_id", "occurrence", "version", "rank_key", "checklist", "created_at", "deleted_at"}).AddRow("p1", nil, "done", nil, nil, 1, nil, nil, nil, nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryCountOpenBlockers)).
+					ExpectQuery().WithArgs("id").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryUpdateTask)).
+					ExpectExec().
+					WillReturnError(sql.ErrConnDone)
+				mk.ExpectRollback()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", ts).Return(nil)
+			},
+		},
+		{
+			title: "task shared with read permission",
+			input: input{ts: ts},
+			output: output{
+				err: ErrStorageForbidden,
+				errMsg: "storage task forbidden: edit",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskState)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission", "status", "series_id", "occurrence", "version", "rank_key", "checklist", "created_at", "deleted_at"}).AddRow("p2", "read", "todo", nil, nil, 1, nil, nil, nil, nil))
+				mk.ExpectRollback()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", ts).Return(nil)
+			},
+		},
+		{
+			title: "non existing task",
+			input: input{ts: ts},
+			output: output{
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: query row",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskState)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission", "status", "series_id", "occurrence", "version"}))
+				mk.ExpectRollback()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", ts).Return(nil)
+			},
+		},
+		{
+			title: "transition the workflow does not allow",
+			input: input{ts: ts},
+			output: output{
+				err: ErrStorageTransition,
+				errMsg: "storage invalid task: transition: validate",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskState)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission", "status", "series_id", "occurrence", "version", "rank_key", "checklist", "created_at", "deleted_at"}).AddRow("p1", nil, "archived", nil, nil, 1, nil, nil, nil, nil))
+				mk.ExpectRollback()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", ts).Return(nil)
+				mk.On("Transition", StatusArchived, StatusDone).Return(ErrValidatorTransition)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			vl := NewValidatorMock()
+			c.setValidator(vl)
+
+			st := NewStorageMySQL(db, vl, c.cfg)
+
+			// act
+			// -> on a copy, as the storage sets the version of the task
+			ts := *c.input.ts
+			err = st.Update("p1", &ts)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+			vl.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStorageMySQL_UpdateKeepsCreatedAt(t *testing.T) {
+	// arrange
+	db, mk, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	createdAt := time.Date(2023, 1, 1, 9, 0, 0, 0, time.UTC)
+	mk.ExpectBegin()
+	mk.
+		ExpectPrepare(regexp.QuoteMeta(QueryGetTaskState)).
+		ExpectQuery().WithArgs("p1", "id").
+		WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission", "status", "series_id", "occurrence", "version", "rank_key", "checklist", "created_at", "deleted_at"}).AddRow("p1", nil, "todo", nil, nil, 1, "i", nil, createdAt, nil))
+	mk.
+		ExpectPrepare(regexp.QuoteMeta(QueryUpdateTask)).
+		ExpectExec().
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mk.
+		ExpectPrepare(regexp.QuoteMeta(QueryClearTaskLabels)).
+		ExpectExec().WithArgs(sql.NullString{String: "id", Valid: true}).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mk.ExpectCommit()
+
+	vl := NewValidatorMock()
+	vl.On("Validate", mock.Anything).Return(nil)
+	st := NewStorageMySQL(db, vl, nil)
+
+	// act
+	// -> the task comes from the request, without the times set by the storage
+	ts := &Task{ID: optional.Some("id"), Title: optional.Some("title"), Status: optional.Some(StatusTodo)}
+	err = st.Update("p1", ts)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, optional.Some(createdAt), ts.CreatedAt)
+	assert.Equal(t, optional.None[time.Time](), ts.DeletedAt)
+	assert.True(t, ts.UpdatedAt.IsSome())
+	assert.NoError(t, mk.ExpectationsWereMet())
+}
+
+func TestStorageMySQL_Delete(t *testing.T) {
+	type input struct {id string; version optional.Option[int]}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+	}
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "delete a task",
+			input: input{id: "id"},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryDeleteTask)).
+					ExpectExec().WithArgs(sqlmock.AnyArg(), "id", "p1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectCommit()
+			},
+		},
+
+		// failure cases
+		{
+			title: "non existing task",
+			input: input{id: "id"},
+			output: output{
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: rows affected",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryDeleteTask)).
+					ExpectExec().WithArgs(sqlmock.AnyArg(), "id", "p1").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mk.ExpectRollback()
+			},
+		},
+		{
+			title: "execute statement error",
+			input: input{id: "id"},
+			output: output{
+				err: ErrStorageInternal,
+				errMsg: "storage internal error: exec",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryDeleteTask)).
+					ExpectExec().WithArgs(sqlmock.AnyArg(), "id", "p1").
+					WillReturnError(sql.ErrConnDone)
+				mk.ExpectRollback()
+			},
+		},
+		{
+			title: "task with another version",
+			input: input{id: "id", version: optional.Some(1)},
+			output: output{
+				err: ErrStorageVersionMismatch,
+				errMsg: "storage task version mismatch: version",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryDeleteTask)).
+					ExpectExec().WithArgs(sqlmock.AnyArg(), "id", "p1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskVersion)).
+					ExpectQuery().WithArgs("id").
+					WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+				mk.ExpectRollback()
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStorageMySQL(db, NewValidatorMock(), nil)
+
+			// act
+			err = st.Delete("p1", c.input.id, c.input.version)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorageMySQL_Restore(t *testing.T) {
+	type input struct {id string; version optional.Option[int]}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+	}
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "restore a task",
+			input: input{id: "id"},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryRestoreTask)).
+					ExpectExec().WithArgs("id", "p1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectCommit()
+			},
+		},
+
+		// failure cases
+		{
+			title: "task not in the trash",
+			input: input{id: "id"},
+			output: output{
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: rows affected",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryRestoreTask)).
+					ExpectExec().WithArgs("id", "p1").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mk.ExpectRollback()
+			},
+		},
+		{
+			title: "task with another version",
+			input: input{id: "id", version: optional.Some(1)},
+			output: output{
+				err: ErrStorageVersionMismatch,
+				errMsg: "storage task version mismatch: version",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryRestoreTask)).
+					ExpectExec().WithArgs("id", "p1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskVersion)).
+					ExpectQuery().WithArgs("id").
+					WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+				mk.ExpectRollback()
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStorageMySQL(db, NewValidatorMock(), nil)
+
+			// act
+			err = st.Restore("p1", c.input.id, c.input.version)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorageMySQL_Purge(t *testing.T) {
+	type input struct {before time.Time}
+	type output struct {n int; err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+	}
+
+	before := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := []testCase{
+		// success cases
+		{
+			title: "purge the trash",
+			input: input{before: before},
+			output: output{n: 3},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryPurgeTasks)).
+					ExpectExec().WithArgs(before).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+			},
+		},
+
+		// failure cases
+		{
+			title: "prepare statement error",
+			input: input{before: before},
+			output: output{
+				err: ErrStorageInternal,
+				errMsg: "storage internal error: prepare",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryPurgeTasks)).
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+		{
+			title: "execute statement error",
+			input: input{before: before},
+			output: output{
+				err: ErrStorageInternal,
+				errMsg: "storage internal error: exec",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryPurgeTasks)).
+					ExpectExec().WithArgs(before).
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStorageMySQL(db, NewValidatorMock(), nil)
+
+			// act
+			n, err := st.Purge(c.input.before)
+
+			// assert
+			assert.Equal(t, c.output.n, n)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorageMySQL_AddLabel(t *testing.T) {
+	type input struct {id string; label string; version optional.Option[int]}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+		setValidator func(mk *ValidatorMock)
+	}
+
+	// rows of the task
+	rows := func() *sqlmock.Rows {
+		cols := []string{"id", "owner_id", "title", "description", "status", "parent_id", "start_at", "due_at", "created_at", "updated_at", "deleted_at", "recurrence", "series_id", "occurrence", "version", "project_id", "rank_key", "checklist", "labels"}
+		return sqlmock.NewRows(cols).AddRow("id", nil, "title", nil, "todo", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, "backend")
+	}
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "add a label",
+			input: input{id: "id", label: "urgent"},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskAccess)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission"}).AddRow("p1", nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTask)).
+					ExpectQuery().WithArgs("id", "p1", "p1").
+					WillReturnRows(rows())
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QuerySaveTaskLabel)).
+					ExpectExec().WithArgs("id", "urgent").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryTouchTask)).
+					ExpectExec().WithArgs("id").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectCommit()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+		{
+			title: "add a label the task already has",
+			input: input{id: "id", label: "backend"},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskAccess)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission"}).AddRow("p1", nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTask)).
+					ExpectQuery().WithArgs("id", "p1", "p1").
+					WillReturnRows(rows())
+				mk.ExpectCommit()
+			},
+			setValidator: func(mk *ValidatorMock) {},
+		},
+
+		// failure cases
+		{
+			title: "non existing task",
+			input: input{id: "id", label: "urgent"},
+			output: output{
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: query row",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskAccess)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnError(sql.ErrNoRows)
+				mk.ExpectRollback()
+			},
+			setValidator: func(mk *ValidatorMock) {},
+		},
+		{
+			title: "invalid label",
+			input: input{id: "id", label: "a,b"},
+			output: output{
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: validator field quality",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskAccess)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission"}).AddRow("p1", nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTask)).
+					ExpectQuery().WithArgs("id", "p1", "p1").
+					WillReturnRows(rows())
+				mk.ExpectRollback()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.Anything).Return(ErrValidatorFieldQuality)
+			},
+		},
+		{
+			title: "task with another version",
+			input: input{id: "id", label: "urgent", version: optional.Some(1)},
+			output: output{
+				err: ErrStorageVersionMismatch,
+				errMsg: "storage task version mismatch: version",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskAccess)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission"}).AddRow("p1", nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskVersion)).
+					ExpectQuery().WithArgs("id").
+					WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+				mk.ExpectRollback()
+			},
+			setValidator: func(mk *ValidatorMock) {},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			vl := NewValidatorMock()
+			c.setValidator(vl)
+
+			st := NewStorageMySQL(db, vl, nil)
+
+			// act
+			err = st.AddLabel("p1", c.input.id, c.input.label, c.input.version)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+			vl.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStorageMySQL_RemoveLabel(t *testing.T) {
+	type input struct {id string; label string; version optional.Option[int]}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+	}
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "remove a label",
+			input: input{id: "id", label: "backend"},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskAccess)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission"}).AddRow("p1", nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryRemoveTaskLabel)).
+					ExpectExec().WithArgs("id", "backend").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryTouchTask)).
+					ExpectExec().WithArgs("id").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectCommit()
+			},
+		},
+
+		// failure cases
+		{
+			title: "label not found",
+			input: input{id: "id", label: "backend"},
+			output: output{
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: rows affected",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskAccess)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission"}).AddRow("p1", nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryRemoveTaskLabel)).
+					ExpectExec().WithArgs("id", "backend").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mk.ExpectRollback()
+			},
+		},
+		{
+			title: "task with another version",
+			input: input{id: "id", label: "backend", version: optional.Some(1)},
+			output: output{
+				err: ErrStorageVersionMismatch,
+				errMsg: "storage task version mismatch: version",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskAccess)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission"}).AddRow("p1", nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskVersion)).
+					ExpectQuery().WithArgs("id").
+					WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+				mk.ExpectRollback()
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStorageMySQL(db, NewValidatorMock(), nil)
+
+			// act
+			err = st.RemoveLabel("p1", c.input.id, c.input.label, c.input.version)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorageMySQL_Labels(t *testing.T) {
+	type output struct {ls []*Label; err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+	}
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "labels with usage counts",
+			output: output{ls: []*Label{{Name: "backend", Tasks: 2}, {Name: "urgent", Tasks: 1}}},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"label", "count"}).
+					AddRow("backend", 2).
+					AddRow("urgent", 1)
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryListLabels)).
+					ExpectQuery().WithArgs("p1").
+					WillReturnRows(rows)
+			},
+		},
+
+		// failure cases
+		{
+			title: "query error",
+			output: output{
+				err: ErrStorageInternal,
+				errMsg: "storage internal error: query",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryListLabels)).
+					ExpectQuery().WithArgs("p1").
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStorageMySQL(db, NewValidatorMock(), nil)
+
+			// act
+			ls, err := st.Labels("p1")
+
+			// assert
+			assert.Equal(t, c.output.ls, ls)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorageMySQL_Tree(t *testing.T) {
+	type input struct {id string}
+	type output struct {nd *Node; err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+	}
+
+	cols := []string{"id", "owner_id", "title", "description", "status", "parent_id", "start_at", "due_at", "created_at", "updated_at", "deleted_at", "recurrence", "series_id", "occurrence", "version", "project_id", "rank_key", "checklist", "labels"}
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "subtree of a task",
+			input: input{id: "1"},
+			output: output{nd: &Node{
+				Task: &Task{ID: optional.Some("1"), Title: optional.Some("a")},
+				Children: []*Node{
+					{
+						Task: &Task{ID: optional.Some("2"), Title: optional.Some("b"), ParentID: optional.Some("1")},
+						Children: []*Node{
+							{Task: &Task{ID: optional.Some("4"), Title: optional.Some("d"), ParentID: optional.Some("2")}, Children: []*Node{}},
+						},
+					},
+					{Task: &Task{ID: optional.Some("3"), Title: optional.Some("c"), ParentID: optional.Some("1")}, Children: []*Node{}},
+				},
+			}},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				// rows
+				rows := sqlmock.NewRows(cols).
+					AddRow("1", nil, "a", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).
+					AddRow("2", nil, "b", nil, nil, "1", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).
+					AddRow("3", nil, "c", nil, nil, "1", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).
+					AddRow("4", nil, "d", nil, nil, "2", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+				// mock
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryTree)).
+					ExpectQuery().WithArgs("1", "p1", "p1").
+					WillReturnRows(rows)
+			},
+		},
+
+		// failure cases
+		{
+			title: "non existing task",
+			input: input{id: "1"},
+			output: output{
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: query",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryTree)).
+					ExpectQuery().WithArgs("1", "p1", "p1").
+					WillReturnRows(sqlmock.NewRows(cols))
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStorageMySQL(db, NewValidatorMock(), nil)
+
+			// act
+			nd, err := st.Tree("p1", c.input.id)
+
+			// assert
+			assert.Equal(t, c.output.nd, nd)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorageMySQL_AddDependency(t *testing.T) {
+	type input struct {id string; blockerId string}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+	}
+
+	// lock expects the tasks to be locked in the given order, owned by the profile
+	lock := func(mk sqlmock.Sqlmock, ids ...string) {
+		for _, id := range ids {
+			mk.
+				ExpectPrepare(regexp.QuoteMeta(QueryGetTaskOwner)).
+				ExpectQuery().WithArgs(id).
+				WillReturnRows(sqlmock.NewRows([]string{"owner_id"}).AddRow("p1"))
+		}
+	}
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "add a dependency",
+			input: input{id: "1", blockerId: "2"},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				lock(mk, "1", "2")
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryTaskBlockers)).
+					ExpectQuery().WithArgs("2", "1").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QuerySaveTaskDependency)).
+					ExpectExec().WithArgs("1", "2").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectCommit()
+			},
+		},
+		{
+			title: "add a dependency on a task with a lower id",
+			input: input{id: "2", blockerId: "1"},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				// -> the tasks are locked in the order of their ids
+				lock(mk, "1", "2")
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryTaskBlockers)).
+					ExpectQuery().WithArgs("1", "2").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QuerySaveTaskDependency)).
+					ExpectExec().WithArgs("2", "1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectCommit()
+			},
+		},
+
+		// failure cases
+		{
+			title: "non existing blocker",
+			input: input{id: "1", blockerId: "2"},
+			output: output{
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: query row",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				lock(mk, "1")
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskOwner)).
+					ExpectQuery().WithArgs("2").
+					WillReturnError(sql.ErrNoRows)
+				mk.ExpectRollback()
+			},
+		},
+		{
+			title: "blocker of another profile",
+			input: input{id: "1", blockerId: "2"},
+			output: output{
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: owner",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				lock(mk, "1")
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskOwner)).
+					ExpectQuery().WithArgs("2").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id"}).AddRow("p2"))
+				mk.ExpectRollback()
+			},
+		},
+		{
+			title: "dependency on the task itself",
+			input: input{id: "1", blockerId: "1"},
+			output: output{
+				err: ErrStorageCycle,
+				errMsg: "storage invalid task: cycle: blocker",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				lock(mk, "1", "1")
+				mk.ExpectRollback()
+			},
+		},
+		{
+			title: "dependency that makes a cycle",
+			input: input{id: "1", blockerId: "2"},
+			output: output{
+				err: ErrStorageCycle,
+				errMsg: "storage invalid task: cycle: blockers",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				lock(mk, "1", "2")
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryTaskBlockers)).
+					ExpectQuery().WithArgs("2", "1").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mk.ExpectRollback()
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStorageMySQL(db, NewValidatorMock(), nil)
+
+			// act
+			err = st.AddDependency("p1", c.input.id, c.input.blockerId)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorageMySQL_RemoveDependency(t *testing.T) {
+	type input struct {id string; blockerId string}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+	}
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "remove a dependency",
+			input: input{id: "1", blockerId: "2"},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryRemoveTaskDependency)).
+					ExpectExec().WithArgs("1", "2", "p1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+
+		// failure cases
+		{
+			title: "non existing dependency",
+			input: input{id: "1", blockerId: "2"},
+			output: output{
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: rows affected",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryRemoveTaskDependency)).
+					ExpectExec().WithArgs("1", "2", "p1").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStorageMySQL(db, NewValidatorMock(), nil)
+
+			// act
+			err = st.RemoveDependency("p1", c.input.id, c.input.blockerId)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorageMySQL_Order(t *testing.T) {
+	type input struct {ids []string}
+	type output struct {ts []*Task; err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+	}
+
+	cols := []string{"id", "owner_id", "title", "description", "status", "parent_id", "start_at", "due_at", "created_at", "updated_at", "deleted_at", "recurrence", "series_id", "occurrence", "version", "project_id", "rank_key", "checklist", "labels"}
+	queryTasks := QueryListTasks + " WHERE owner_id = ? AND deleted_at IS NULL AND id IN (?, ?)"
+	queryDependencies := fmt.Sprintf(QueryListDependencies, "?, ?")
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "order tasks linked through a task out of the set",
+			input: input{ids: []string{"1", "2"}},
+			output: output{ts: []*Task{
+				{ID: optional.Some("2"), Title: optional.Some("b")},
+				{ID: optional.Some("1"), Title: optional.Some("a")},
+			}},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(queryTasks)).
+					ExpectQuery().WithArgs("p1", "1", "2").
+					WillReturnRows(sqlmock.NewRows(cols).
+						AddRow("1", nil, "a", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).
+						AddRow("2", nil, "b", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(queryDependencies)).
+					ExpectQuery().WithArgs("1", "2").
+					WillReturnRows(sqlmock.NewRows([]string{"task_id", "blocker_id"}).
+						AddRow("1", "3").
+						AddRow("3", "2"))
+			},
+		},
+
+		// failure cases
+		{
+			title: "non existing task",
+			input: input{ids: []string{"1", "2"}},
+			output: output{
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: query",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(queryTasks)).
+					ExpectQuery().WithArgs("p1", "1", "2").
+					WillReturnRows(sqlmock.NewRows(cols).
+						AddRow("1", nil, "a", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil))
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStorageMySQL(db, NewValidatorMock(), nil)
+
+			// act
+			ts, err := st.Order("p1", c.input.ids)
+
+			// assert
+			assert.Equal(t, c.output.ts, ts)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorageMySQL_Grant(t *testing.T) {
+	type input struct {id string; grant *Grant}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+	}
+
+	// access expects the task to be found, with its owner
+	access := func(mk sqlmock.Sqlmock, ownerId string) {
+		mk.
+			ExpectPrepare(regexp.QuoteMeta(QueryGetTaskAccess)).
+			ExpectQuery().WithArgs("p1", "id").
+			WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission"}).AddRow(ownerId, nil))
+	}
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "share a task",
+			input: input{id: "id", grant: &Grant{ProfileID: "p2", Permission: PermissionEdit}},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				access(mk, "p1")
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QuerySaveTaskGrant)).
+					ExpectExec().WithArgs("id", "p2", "edit").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+
+		// failure cases
+		{
+			title: "task of another profile",
+			input: input{id: "id", grant: &Grant{ProfileID: "p3", Permission: PermissionRead}},
+			output: output{
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: owner",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				access(mk, "p2")
+			},
+		},
+		{
+			title: "share a task with its owner",
+			input: input{id: "id", grant: &Grant{ProfileID: "p1", Permission: PermissionRead}},
+			output: output{
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: grant to the owner",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				access(mk, "p1")
+			},
+		},
+		{
+			title: "execute statement error",
+			input: input{id: "id", grant: &Grant{ProfileID: "p2", Permission: PermissionRead}},
+			output: output{
+				err: ErrStorageInternal,
+				errMsg: "storage internal error: exec",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				access(mk, "p1")
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QuerySaveTaskGrant)).
+					ExpectExec().WithArgs("id", "p2", "read").
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStorageMySQL(db, NewValidatorMock(), nil)
+
+			// act
+			err = st.Grant("p1", c.input.id, c.input.grant)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorageMySQL_Revoke(t *testing.T) {
+	type input struct {id string; granteeId string}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+	}
+
+	// access expects the task to be found, owned by the profile
+	access := func(mk sqlmock.Sqlmock) {
+		mk.
+			ExpectPrepare(regexp.QuoteMeta(QueryGetTaskAccess)).
+			ExpectQuery().WithArgs("p1", "id").
+			WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission"}).AddRow("p1", nil))
+	}
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "stop sharing a task",
+			input: input{id: "id", granteeId: "p2"},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				access(mk)
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryRemoveTaskGrant)).
+					ExpectExec().WithArgs("id", "p2").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+
+		// failure cases
+		{
+			title: "task not shared with the profile",
+			input: input{id: "id", granteeId: "p2"},
+			output: output{
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: rows affected",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				access(mk)
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryRemoveTaskGrant)).
+					ExpectExec().WithArgs("id", "p2").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		{
+			title: "non existing task",
+			input: input{id: "id", granteeId: "p2"},
+			output: output{
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: query row",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskAccess)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnError(sql.ErrNoRows)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStorageMySQL(db, NewValidatorMock(), nil)
+
+			// act
+			err = st.Revoke("p1", c.input.id, c.input.granteeId)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorageMySQL_Grants(t *testing.T) {
+	type input struct {id string}
+	type output struct {gs []*Grant; err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+	}
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "grants of a task",
+			input: input{id: "id"},
+			output: output{gs: []*Grant{{ProfileID: "p2", Permission: PermissionEdit}, {ProfileID: "p3", Permission: PermissionRead}}},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskAccess)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission"}).AddRow("p1", nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryListTaskGrants)).
+					ExpectQuery().WithArgs("id").
+					WillReturnRows(sqlmock.NewRows([]string{"profile_id", "permission"}).AddRow("p2", "edit").AddRow("p3", "read"))
+			},
+		},
+
+		// failure cases
+		{
+			title: "task shared with the profile",
+			input: input{id: "id"},
+			output: output{
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: owner",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskAccess)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission"}).AddRow("p2", "edit"))
+			},
+		},
+		{
+			title: "query error",
+			input: input{id: "id"},
+			output: output{
+				err: ErrStorageInternal,
+				errMsg: "storage internal error: query",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskAccess)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission"}).AddRow("p1", nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryListTaskGrants)).
+					ExpectQuery().WithArgs("id").
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStorageMySQL(db, NewValidatorMock(), nil)
+
+			// act
+			gs, err := st.Grants("p1", c.input.id)
+
+			// assert
+			assert.Equal(t, c.output.gs, gs)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorageMySQL_Shared(t *testing.T) {
+	type input struct {query *Query}
+	type output struct {pg *Page; err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+	}
+
+	cols := []string{"id", "owner_id", "title", "description", "status", "parent_id", "start_at", "due_at", "created_at", "updated_at", "deleted_at", "recurrence", "series_id", "occurrence", "version", "project_id", "rank_key", "checklist", "labels"}
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "tasks shared with the profile",
+			input: input{query: &Query{}},
+			output: output{
+				pg: &Page{
+					Tasks: []*Task{{ID: optional.Some("1"), OwnerID: optional.Some("p2"), Title: optional.Some("title")}},
+					Next: optional.None[string](),
+				},
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryListTasks + " WHERE id IN (SELECT task_id FROM task_grants WHERE profile_id = ?) AND deleted_at IS NULL ORDER BY id LIMIT ?")).
+					ExpectQuery().WithArgs("p1", DefaultPageSize+1).
+					WillReturnRows(sqlmock.NewRows(cols).AddRow("1", "p2", "title", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil))
+			},
+		},
+
+		// failure cases
+		{
+			title: "trash",
+			input: input{query: &Query{Deleted: true}},
+			output: output{
+				err: ErrStorageInvalidQuery,
+				errMsg: "storage invalid query: trash",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStorageMySQL(db, NewValidatorMock(), nil)
+
+			// act
+			pg, err := st.Shared("p1", c.input.query)
+
+			// assert
+			assert.Equal(t, c.output.pg, pg)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorageMySQL_Batch(t *testing.T) {
+	type input struct {ops []*Op; mode Mode}
+	type output struct {errs []string; err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+	}
+
+	ops := []*Op{{Kind: OpDelete, ID: "1"}, {Kind: OpDelete, ID: "2"}}
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "apply a batch in a transaction",
+			input: input{ops: ops, mode: ModeAtomic},
+			output: output{errs: []string{"", ""}},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryDeleteTask)).
+					ExpectExec().WithArgs(sqlmock.AnyArg(), "1", "p1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryDeleteTask)).
+					ExpectExec().WithArgs(sqlmock.AnyArg(), "2", "p1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectCommit()
+			},
+		},
+		{
+			title: "apply a batch with best effort",
+			input: input{ops: ops, mode: ModeBestEffort},
+			output: output{errs: []string{"storage task not found: rows affected", ""}},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				// -> each operation runs in its own transaction
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryDeleteTask)).
+					ExpectExec().WithArgs(sqlmock.AnyArg(), "1", "p1").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mk.ExpectRollback()
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryDeleteTask)).
+					ExpectExec().WithArgs(sqlmock.AnyArg(), "2", "p1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectCommit()
+			},
+		},
+
+		// failure cases
+		{
+			title: "abort the transaction with an operation that fails",
+			input: input{ops: ops, mode: ModeAtomic},
+			output: output{
+				errs: []string{"storage batch aborted", "storage task not found: rows affected"},
+				err: ErrStorageBatchAborted,
+				errMsg: "storage batch aborted: operation 1: storage task not found: rows affected",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryDeleteTask)).
+					ExpectExec().WithArgs(sqlmock.AnyArg(), "1", "p1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryDeleteTask)).
+					ExpectExec().WithArgs(sqlmock.AnyArg(), "2", "p1").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mk.ExpectRollback()
+			},
+		},
+		{
+			title: "begin transaction error",
+			input: input{ops: ops, mode: ModeAtomic},
+			output: output{
+				errs: []string{"storage batch aborted", "storage batch aborted"},
+				err: ErrStorageInternal,
+				errMsg: "storage internal error: batch",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin().WillReturnError(sql.ErrConnDone)
+			},
+		},
+		{
+			title: "commit transaction error",
+			input: input{ops: ops, mode: ModeAtomic},
+			output: output{
+				errs: []string{"storage batch aborted", "storage batch aborted"},
+				err: ErrStorageInternal,
+				errMsg: "storage internal error: batch",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryDeleteTask)).
+					ExpectExec().WithArgs(sqlmock.AnyArg(), "1", "p1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryDeleteTask)).
+					ExpectExec().WithArgs(sqlmock.AnyArg(), "2", "p1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectCommit().WillReturnError(sql.ErrConnDone)
+			},
+		},
+		{
+			title: "batch with an unknown mode",
+			input: input{ops: ops, mode: "eventually"},
+			output: output{
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: batch mode eventually",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStorageMySQL(db, NewValidatorMock(), nil)
+
+			// act
+			var rs []*Result
+			rs, err = st.Batch("p1", c.input.ops, c.input.mode)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			var errs []string
+			for _, r := range rs {
+				if r.Err != nil {
+					errs = append(errs, r.Err.Error())
+					continue
+				}
+				errs = append(errs, "")
+			}
+			assert.Equal(t, c.output.errs, errs)
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorageMySQL_Move(t *testing.T) {
+	type input struct {id string; afterId string; beforeId string; version optional.Option[int]}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+	}
+
+	// rank returns the rows of the rank of a task
+	rank := func(r any) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"rank_key"}).AddRow(r)
+	}
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "move a task between two tasks",
+			input: input{id: "3", afterId: "1", beforeId: "2"},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryGetTaskRank)).ExpectQuery().WithArgs("3", "p1").WillReturnRows(rank("r"))
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryGetTaskRank)).ExpectQuery().WithArgs("1", "p1").WillReturnRows(rank("9"))
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryGetTaskRank)).ExpectQuery().WithArgs("2", "p1").WillReturnRows(rank("i"))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryMoveTask)).
+					ExpectExec().WithArgs("d", "3").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectCommit()
+			},
+		},
+		{
+			title: "move a task right after another one",
+			input: input{id: "1", afterId: "2"},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryGetTaskRank)).ExpectQuery().WithArgs("1", "p1").WillReturnRows(rank("9"))
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryGetTaskRank)).ExpectQuery().WithArgs("2", "p1").WillReturnRows(rank("i"))
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryNextTaskRank)).ExpectQuery().WithArgs("p1", "1", "i").WillReturnRows(rank("r"))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryMoveTask)).
+					ExpectExec().WithArgs("m", "1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectCommit()
+			},
+		},
+		{
+			title: "move a task at its version",
+			input: input{id: "1", afterId: "2", version: optional.Some(1)},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryGetTaskRank)).ExpectQuery().WithArgs("1", "p1").WillReturnRows(rank("9"))
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryGetTaskVersion)).ExpectQuery().WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryGetTaskRank)).ExpectQuery().WithArgs("2", "p1").WillReturnRows(rank("i"))
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryNextTaskRank)).ExpectQuery().WithArgs("p1", "1", "i").WillReturnRows(rank("r"))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryMoveTask)).
+					ExpectExec().WithArgs("m", "1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectCommit()
+			},
+		},
+		{
+			title: "move a task to the top",
+			input: input{id: "3", beforeId: "1"},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryGetTaskRank)).ExpectQuery().WithArgs("3", "p1").WillReturnRows(rank("r"))
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryGetTaskRank)).ExpectQuery().WithArgs("1", "p1").WillReturnRows(rank("9"))
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryPrevTaskRank)).ExpectQuery().WithArgs("p1", "3", "9").WillReturnRows(rank(nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryMoveTask)).
+					ExpectExec().WithArgs("4", "3").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectCommit()
+			},
+		},
+
+		// failure cases
+		{
+			title: "move a task without neighbours",
+			input: input{id: "1"},
+			output: output{
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: neighbour required",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {},
+		},
+		{
+			title: "move a task next to itself",
+			input: input{id: "1", beforeId: "1"},
+			output: output{
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: neighbour",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {},
+		},
+		{
+			title: "task not found",
+			input: input{id: "1", afterId: "2"},
+			output: output{
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: query row",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryGetTaskRank)).ExpectQuery().WithArgs("1", "p1").WillReturnRows(sqlmock.NewRows([]string{"rank_key"}))
+				mk.ExpectRollback()
+			},
+		},
+		{
+			title: "version mismatch",
+			input: input{id: "1", afterId: "2", version: optional.Some(2)},
+			output: output{
+				err: ErrStorageVersionMismatch,
+				errMsg: "storage task version mismatch: version",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryGetTaskRank)).ExpectQuery().WithArgs("1", "p1").WillReturnRows(rank("9"))
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryGetTaskVersion)).ExpectQuery().WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
+				mk.ExpectRollback()
+			},
+		},
+		{
+			title: "neighbour not found",
+			input: input{id: "1", afterId: "4"},
+			output: output{
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: neighbour not found",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryGetTaskRank)).ExpectQuery().WithArgs("1", "p1").WillReturnRows(rank("9"))
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryGetTaskRank)).ExpectQuery().WithArgs("4", "p1").WillReturnRows(sqlmock.NewRows([]string{"rank_key"}))
+				mk.ExpectRollback()
+			},
+		},
+		{
+			title: "neighbour not ranked",
+			input: input{id: "1", beforeId: "6"},
+			output: output{
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: neighbour not ranked",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryGetTaskRank)).ExpectQuery().WithArgs("1", "p1").WillReturnRows(rank("9"))
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryGetTaskRank)).ExpectQuery().WithArgs("6", "p1").WillReturnRows(rank(nil))
+				mk.ExpectRollback()
+			},
+		},
+		{
+			title: "neighbours out of order",
+			input: input{id: "1", afterId: "3", beforeId: "2"},
+			output: output{
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: rank \"r\" not before \"i\"",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryGetTaskRank)).ExpectQuery().WithArgs("1", "p1").WillReturnRows(rank("9"))
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryGetTaskRank)).ExpectQuery().WithArgs("3", "p1").WillReturnRows(rank("r"))
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryGetTaskRank)).ExpectQuery().WithArgs("2", "p1").WillReturnRows(rank("i"))
+				mk.ExpectRollback()
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStorageMySQL(db, NewValidatorMock(), nil)
+
+			// act
+			err = st.Move("p1", c.input.id, c.input.afterId, c.input.beforeId, c.input.version)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorageMySQL_Rebalance(t *testing.T) {
+	type output struct {n int; err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+	}
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "rebalance the tasks of a profile",
+			output: output{n: 3},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryListUnbalancedOwners)).
+					ExpectQuery().WithArgs(MaxRankLength).
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id"}).AddRow("p1"))
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryListOwnerRanks)).
+					ExpectQuery().WithArgs("p1").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("3").AddRow("2").AddRow("1"))
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryRankTask)).ExpectExec().WithArgs("9", "3").WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryRankTask)).ExpectExec().WithArgs("i", "2").WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryRankTask)).ExpectExec().WithArgs("r", "1").WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectCommit()
+			},
+		},
+		{
+			title: "nothing to rebalance",
+			output: output{n: 0},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryListUnbalancedOwners)).
+					ExpectQuery().WithArgs(MaxRankLength).
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id"}))
+			},
+		},
+
+		// failure cases
+		{
+			title: "query error",
+			output: output{
+				err: ErrStorageInternal,
+				errMsg: "storage internal error: query",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryListUnbalancedOwners)).
+					ExpectQuery().WithArgs(MaxRankLength).
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+		{
+			title: "execute statement error",
+			output: output{
+				err: ErrStorageInternal,
+				errMsg: "storage internal error: exec",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryListUnbalancedOwners)).
+					ExpectQuery().WithArgs(MaxRankLength).
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id"}).AddRow("p1"))
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryListOwnerRanks)).
+					ExpectQuery().WithArgs("p1").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1"))
+				mk.ExpectPrepare(regexp.QuoteMeta(QueryRankTask)).ExpectExec().WithArgs("i", "1").WillReturnError(sql.ErrConnDone)
+				mk.ExpectRollback()
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStorageMySQL(db, NewValidatorMock(), nil)
+
+			// act
+			n, err := st.Rebalance()
+
+			// assert
+			assert.Equal(t, c.output.n, n)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorageMySQL_AddItem(t *testing.T) {
+	type input struct {id string; item *Item}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+		setValidator func(mk *ValidatorMock)
+	}
+
+	// rows of the state and of the task, with its checklist
+	state := func(ownerId string, permission any) *sqlmock.Rows {
+		cols := []string{"owner_id", "permission", "status", "series_id", "occurrence", "version", "rank_key", "checklist", "created_at", "deleted_at"}
+		return sqlmock.NewRows(cols).AddRow(ownerId, permission, "todo", nil, nil, 1, "i", `[{"id": "a", "text": "milk", "checked": true}]`, nil, nil)
+	}
+	rows := func() *sqlmock.Rows {
+		cols := []string{"id", "owner_id", "title", "description", "status", "parent_id", "start_at", "due_at", "created_at", "updated_at", "deleted_at", "recurrence", "series_id", "occurrence", "version", "project_id", "rank_key", "checklist", "labels"}
+		return sqlmock.NewRows(cols).AddRow("id", "p1", "title", nil, "todo", nil, nil, nil, nil, nil, nil, nil, nil, nil, 1, nil, "i", `[{"id": "a", "text": "milk", "checked": true}]`, nil)
+	}
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "add an item",
+			input: input{id: "id", item: &Item{Text: "bread"}},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskState)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(state("p1", nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTask)).
+					ExpectQuery().WithArgs("id", "p1", "p1").
+					WillReturnRows(rows())
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryUpdateTaskChecklist)).
+					ExpectExec().WithArgs(sqlmock.AnyArg(), "id").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectCommit()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.MatchedBy(func(ts *Task) bool {
+					return len(ts.Checklist) == 2 && ts.Checklist[0] == Item{ID: "a", Text: "milk", Checked: true} && ts.Checklist[1].Text == "bread"
+				})).Return(nil)
+			},
+		},
+
+		// failure cases
+		{
+			title: "task shared with read permission",
+			input: input{id: "id", item: &Item{Text: "bread"}},
+			output: output{
+				err: ErrStorageForbidden,
+				errMsg: "storage task forbidden: edit",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskState)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(state("p2", "read"))
+				mk.ExpectRollback()
+			},
+			setValidator: func(mk *ValidatorMock) {},
+		},
+		{
+			title: "invalid item",
+			input: input{id: "id", item: &Item{Text: ""}},
+			output: output{
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: validator field empty",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskState)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(state("p1", nil))
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTask)).
+					ExpectQuery().WithArgs("id", "p1", "p1").
+					WillReturnRows(rows())
+				mk.ExpectRollback()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.Anything).Return(ErrValidatorFieldEmpty)
+			},
+		},
+		{
+			title: "task not found",
+			input: input{id: "id", item: &Item{Text: "bread"}},
+			output: output{
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: query row",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(regexp.QuoteMeta(QueryGetTaskState)).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission", "status", "series_id", "occurrence", "version", "rank_key", "checklist", "created_at", "deleted_at"}))
+				mk.ExpectRollback()
+			},
+			setValidator: func(mk *ValidatorMock) {},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			vl := NewValidatorMock()
+			c.setValidator(vl)
+			st := NewStorageMySQL(db, vl, nil)
+
+			// act
+			err = st.AddItem("p1", c.input.id, c.input.item, optional.None[int]())
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+				assert.Empty(t, c.input.item.ID)
+			} else {
+				assert.NotEmpty(t, c.input.item.ID)
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+			vl.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStorageMySQL_MoveItem(t *testing.T) {
+	// arrange
+	db, mk, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	checklist := `[{"id":"a","text":"milk","checked":true},{"id":"b","text":"bread","checked":false}]`
+	mk.ExpectBegin()
+	mk.
+		ExpectPrepare(regexp.QuoteMeta(QueryGetTaskState)).
+		ExpectQuery().WithArgs("p1", "id").
+		WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission", "status", "series_id", "occurrence", "version", "rank_key", "checklist", "created_at", "deleted_at"}).AddRow("p1", nil, "todo", nil, nil, 1, "i", checklist, nil, nil))
+	mk.
+		ExpectPrepare(regexp.QuoteMeta(QueryGetTask)).
+		ExpectQuery().WithArgs("id", "p1", "p1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "title", "description", "status", "parent_id", "start_at", "due_at", "created_at", "updated_at", "deleted_at", "recurrence", "series_id", "occurrence", "version", "project_id", "rank_key", "checklist", "labels"}).
+			AddRow("id", "p1", "title", nil, "todo", nil, nil, nil, nil, nil, nil, nil, nil, nil, 1, nil, "i", checklist, nil))
+	// -> the checklist is stored as a JSON array, in the new order
+	mk.
+		ExpectPrepare(regexp.QuoteMeta(QueryUpdateTaskChecklist)).
+		ExpectExec().WithArgs(`[{"id":"b","text":"bread","checked":false},{"id":"a","text":"milk","checked":true}]`, "id").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mk.ExpectCommit()
+
+	vl := NewValidatorMock()
+	vl.On("Validate", mock.Anything).Return(nil)
+	st := NewStorageMySQL(db, vl, nil)
+
+	// act
+	err = st.MoveItem("p1", "id", "b", 0, optional.Some(1))
+
+	// assert
+	assert.NoError(t, err)
+	assert.NoError(t, mk.ExpectationsWereMet())
+}
+
+func TestStorageMySQL_MoveItemVersionMismatch(t *testing.T) {
+	// arrange
+	db, mk, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	checklist := `[{"id":"a","text":"milk","checked":true},{"id":"b","text":"bread","checked":false}]`
+	mk.ExpectBegin()
+	mk.
+		ExpectPrepare(regexp.QuoteMeta(QueryGetTaskState)).
+		ExpectQuery().WithArgs("p1", "id").
+		WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission", "status", "series_id", "occurrence", "version", "rank_key", "checklist", "created_at", "deleted_at"}).AddRow("p1", nil, "todo", nil, nil, 2, "i", checklist, nil, nil))
+	mk.ExpectRollback()
+
+	st := NewStorageMySQL(db, NewValidatorMock(), nil)
+
+	// act
+	err = st.MoveItem("p1", "id", "b", 0, optional.Some(1))
+
+	// assert
+	// -> the checklist is left as it is
+	assert.ErrorIs(t, err, ErrStorageVersionMismatch)
+	assert.NoError(t, mk.ExpectationsWereMet())
+}
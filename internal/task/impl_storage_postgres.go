@@ -0,0 +1,128 @@
+package task
+
+import (
+	"database/sql"
+
+	"api/pkg/postgres"
+)
+
+// SchemaPostgres creates the tables of the PostgreSQL storage, if they do not exist.
+// - the tables are the ones of the MySQL storage, with timestamptz times, a jsonb checklist and rank_key in the "C" collation (compared byte by byte, see rank.go)
+// - task_history is the table of the history of the tasks (see HistoryPostgres) and task_comments the one of their comments (see comment.StoragePostgres)
+// - the storage does not create them: the application runs the schema on the database before (see cmd/rest/application)
+const SchemaPostgres = `
+CREATE TABLE IF NOT EXISTS projects (
+	id TEXT PRIMARY KEY,
+	owner_id TEXT NOT NULL,
+	name TEXT NOT NULL,
+	description TEXT,
+	created_at TIMESTAMPTZ,
+	updated_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_projects_owner ON projects (owner_id);
+CREATE TABLE IF NOT EXISTS tasks (
+	id TEXT PRIMARY KEY,
+	owner_id TEXT NOT NULL,
+	title TEXT,
+	description TEXT,
+	status TEXT,
+	parent_id TEXT REFERENCES tasks (id) ON DELETE SET NULL,
+	start_at TIMESTAMPTZ,
+	due_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ,
+	updated_at TIMESTAMPTZ,
+	deleted_at TIMESTAMPTZ,
+	recurrence TEXT,
+	series_id TEXT REFERENCES tasks (id) ON DELETE SET NULL,
+	occurrence INTEGER,
+	version INTEGER NOT NULL DEFAULT 1,
+	project_id TEXT REFERENCES projects (id) ON DELETE RESTRICT,
+	rank_key TEXT COLLATE "C",
+	checklist JSONB
+);
+CREATE INDEX IF NOT EXISTS idx_tasks_owner_rank ON tasks (owner_id, rank_key);
+CREATE INDEX IF NOT EXISTS idx_tasks_parent ON tasks (parent_id);
+CREATE TABLE IF NOT EXISTS task_labels (
+	task_id TEXT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+	label TEXT NOT NULL,
+	PRIMARY KEY (task_id, label)
+);
+CREATE TABLE IF NOT EXISTS task_dependencies (
+	task_id TEXT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+	blocker_id TEXT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+	PRIMARY KEY (task_id, blocker_id)
+);
+CREATE TABLE IF NOT EXISTS task_grants (
+	task_id TEXT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+	profile_id TEXT NOT NULL,
+	permission TEXT NOT NULL,
+	PRIMARY KEY (task_id, profile_id)
+);
+CREATE TABLE IF NOT EXISTS task_history (
+	id BIGSERIAL PRIMARY KEY,
+	task_id TEXT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+	profile_id TEXT,
+	action TEXT NOT NULL,
+	changes JSONB,
+	created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_task_history_task ON task_history (task_id);
+CREATE TABLE IF NOT EXISTS task_comments (
+	id TEXT PRIMARY KEY,
+	task_id TEXT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+	author_id TEXT NOT NULL,
+	parent_id TEXT REFERENCES task_comments (id) ON DELETE CASCADE,
+	body TEXT,
+	created_at TIMESTAMPTZ,
+	updated_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_task_comments_task ON task_comments (task_id, created_at);`
+
+// dialectPostgres writes the statements of the MySQL storage for PostgreSQL (on the tables of SchemaPostgres).
+var dialectPostgres = newDialect(&dialect{
+	// -> PostgreSQL can not join in a delete or an update, nor has INSERT IGNORE or ON DUPLICATE KEY
+	queries: map[string]string{
+		QuerySaveTaskLabel: 	   querySaveTaskLabelPostgres,
+		QuerySaveTaskDependency:   querySaveTaskDependencyPostgres,
+		QueryRemoveTaskLabel: 	   queryRemoveTaskLabel,
+		QueryRemoveTaskDependency: queryRemoveTaskDependency,
+		QueryCompleteDescendants:  queryCompleteDescendants,
+		QuerySaveTaskGrant: 	   querySaveTaskGrant,
+		QueryTaskAncestors: 	   queryTaskAncestorsPostgres,
+	},
+	// -> the nullable side of the join with the grants can not be locked
+	lock: 	" FOR UPDATE OF tasks",
+	labels: columnLabelsPostgres,
+	// -> LIKE is case sensitive
+	like: 	"ILIKE ?",
+	// -> = is case sensitive
+	equal: 	"LOWER(%s) = LOWER(?)",
+	// -> nulls go last when ascending by default: they are placed as in MySQL, the way the cursor expects
+	asc: 	"ASC NULLS FIRST",
+	desc: 	"DESC NULLS LAST",
+	bind: 	postgres.Bind,
+})
+
+// statements of the MySQL storage written for PostgreSQL.
+const (
+	querySaveTaskLabelPostgres = `INSERT INTO task_labels (task_id, label) VALUES (?, ?) ON CONFLICT DO NOTHING`
+	querySaveTaskDependencyPostgres = `INSERT INTO task_dependencies (task_id, blocker_id) VALUES (?, ?) ON CONFLICT DO NOTHING`
+	// -> booleans are not numbers
+	queryTaskAncestorsPostgres = `WITH RECURSIVE ancestors (id, parent_id) AS (SELECT id, parent_id FROM tasks WHERE id = ? AND owner_id = ? UNION ALL SELECT tasks.id, tasks.parent_id FROM tasks JOIN ancestors ON tasks.id = ancestors.parent_id) SELECT COUNT(*), COALESCE(SUM(CASE WHEN id = ? THEN 1 ELSE 0 END), 0) FROM ancestors`
+	columnLabelsPostgres = `(SELECT STRING_AGG(label, ',' ORDER BY label) FROM task_labels WHERE task_labels.task_id = tasks.id) AS labels`
+)
+
+// constructor
+// - cfg is optional (nil for the default config)
+func NewStoragePostgres(db *sql.DB, vl Validator, cfg *Config) *StoragePostgres {
+	s := NewStorageMySQL(db, vl, cfg)
+	s.dl = dialectPostgres
+	return &StoragePostgres{StorageMySQL: s}
+}
+
+// StoragePostgres is the implementation of the task storage on PostgreSQL.
+// - it runs the statements of the MySQL storage, rewritten for PostgreSQL (see dialectPostgres), so it behaves the same way
+type StoragePostgres struct {
+	// StorageMySQL runs the statements
+	*StorageMySQL
+}
@@ -0,0 +1,673 @@
+package task
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/LNMMusic/optional"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Tests
+func TestStoragePostgres_Get(t *testing.T) {
+	type input struct {id string}
+	type output struct {ts *Task; err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+	}
+
+	// -> the statements are matched as they are sent
+	getTask := `SELECT id, owner_id, title, description, status, parent_id, start_at, due_at, created_at, updated_at, deleted_at, recurrence, series_id, occurrence, version, project_id, rank_key, checklist, (SELECT STRING_AGG(label, ',' ORDER BY label) FROM task_labels WHERE task_labels.task_id = tasks.id) AS labels FROM tasks WHERE id = $1 AND deleted_at IS NULL AND (tasks.owner_id = $2 OR EXISTS (SELECT 1 FROM task_grants WHERE task_grants.task_id = tasks.id AND task_grants.profile_id = $3))`
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "task with labels and checklist",
+			input: input{id: "id"},
+			output: output{
+				ts: &Task{
+					ID: optional.Some("id"),
+					OwnerID: optional.Some("p1"),
+					Title: optional.Some("title"),
+					Status: optional.Some(StatusTodo),
+					Labels: []string{"backend", "urgent"},
+					Version: optional.Some(2),
+					Checklist: []Item{{ID: "i1", Text: "item"}},
+				},
+				err: nil,
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				cols := []string{"id", "owner_id", "title", "description", "status", "parent_id", "start_at", "due_at", "created_at", "updated_at", "deleted_at", "recurrence", "series_id", "occurrence", "version", "project_id", "rank_key", "checklist", "labels"}
+				rows := sqlmock.NewRows(cols).AddRow("id", "p1", "title", nil, "todo", nil, nil, nil, nil, nil, nil, nil, nil, nil, 2, nil, nil, []byte(`[{"id":"i1","text":"item","checked":false}]`), "backend,urgent")
+				mk.
+					ExpectPrepare(getTask).
+					ExpectQuery().WithArgs("id", "p1", "p1").
+					WillReturnRows(rows)
+			},
+		},
+
+		// failure cases
+		{
+			title: "non existing task",
+			input: input{id: "id"},
+			output: output{
+				ts: nil,
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: query row",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(getTask).
+					ExpectQuery().WithArgs("id", "p1", "p1").
+					WillReturnError(sql.ErrNoRows)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStoragePostgres(db, NewValidatorMock(), nil)
+
+			// act
+			ts, err := st.Get("p1", c.input.id)
+
+			// assert
+			assert.Equal(t, c.output.ts, ts)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStoragePostgres_List(t *testing.T) {
+	type input struct {query *Query}
+	type output struct {pg *Page; err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+	}
+
+	// -> the statements are matched as they are sent
+	listTasks := `SELECT id, owner_id, title, description, status, parent_id, start_at, due_at, created_at, updated_at, deleted_at, recurrence, series_id, occurrence, version, project_id, rank_key, checklist, (SELECT STRING_AGG(label, ',' ORDER BY label) FROM task_labels WHERE task_labels.task_id = tasks.id) AS labels FROM tasks`
+	cols := []string{"id", "owner_id", "title", "description", "status", "parent_id", "start_at", "due_at", "created_at", "updated_at", "deleted_at", "recurrence", "series_id", "occurrence", "version", "project_id", "rank_key", "checklist", "labels"}
+	due := time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "filtered and sorted by due date after a cursor",
+			input: input{query: &Query{
+				Cursor: encodeCursor(&Task{ID: optional.Some("1"), DueAt: optional.Some(due)}, Sort{Field: FieldDueAt}),
+				Filter: Condition{Field: FieldTitle, Operator: OperatorContains, Value: "Report"},
+				Sort: Sort{Field: FieldDueAt},
+			}},
+			output: output{
+				pg: &Page{
+					Tasks: []*Task{
+						{ID: optional.Some("2"), Title: optional.Some("weekly report"), Status: optional.Some(StatusTodo), DueAt: optional.Some(time.Date(2023, 1, 4, 0, 0, 0, 0, time.UTC))},
+					},
+					Next: optional.None[string](),
+				},
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows(cols).AddRow("2", nil, "weekly report", nil, "todo", nil, nil, time.Date(2023, 1, 4, 0, 0, 0, 0, time.UTC), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+				// -> nulls first when ascending, as the cursor expects; the text filters ignore the case
+				mk.
+					ExpectPrepare(listTasks + ` WHERE owner_id = $1 AND deleted_at IS NULL AND title ILIKE $2 AND (due_at > $3 OR (due_at = $4 AND id > $5)) ORDER BY due_at ASC NULLS FIRST, id LIMIT $6`).
+					ExpectQuery().WithArgs("p1", "%Report%", due, due, "1", DefaultPageSize+1).
+					WillReturnRows(rows)
+			},
+		},
+		{
+			title: "sorted by descending due date after a cursor without due date",
+			input: input{query: &Query{
+				Cursor: encodeCursor(&Task{ID: optional.Some("1")}, Sort{Field: FieldDueAt, Desc: true}),
+				Sort: Sort{Field: FieldDueAt, Desc: true},
+				Size: 1,
+			}},
+			output: output{
+				pg: &Page{
+					Tasks: []*Task{
+						{ID: optional.Some("2"), Title: optional.Some("title"), Status: optional.Some(StatusTodo)},
+					},
+					Next: optional.Some(encodeCursor(&Task{ID: optional.Some("2")}, Sort{Field: FieldDueAt, Desc: true})),
+				},
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows(cols).
+					AddRow("2", nil, "title", nil, "todo", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).
+					AddRow("3", nil, "title", nil, "todo", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+				// -> nulls last when descending, after the tasks with a due date
+				mk.
+					ExpectPrepare(listTasks + ` WHERE owner_id = $1 AND deleted_at IS NULL AND (due_at IS NULL AND id > $2) ORDER BY due_at DESC NULLS LAST, id LIMIT $3`).
+					ExpectQuery().WithArgs("p1", "1", 2).
+					WillReturnRows(rows)
+			},
+		},
+
+		// failure cases
+		{
+			title: "query error",
+			input: input{query: &Query{}},
+			output: output{
+				pg: nil,
+				err: ErrStorageInternal,
+				errMsg: "storage internal error: query",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.
+					ExpectPrepare(listTasks + ` WHERE owner_id = $1 AND deleted_at IS NULL ORDER BY id LIMIT $2`).
+					ExpectQuery().WithArgs("p1", DefaultPageSize+1).
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStoragePostgres(db, NewValidatorMock(), nil)
+
+			// act
+			pg, err := st.List("p1", c.input.query)
+
+			// assert
+			assert.Equal(t, c.output.pg, pg)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStoragePostgres_Save(t *testing.T) {
+	type input struct {ts *Task}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+	}
+
+	// -> the statements are matched as they are sent
+	lastRank := `SELECT MAX(rank_key) FROM tasks WHERE owner_id = $1`
+	saveTask := `INSERT INTO tasks (id, owner_id, title, description, status, parent_id, start_at, due_at, created_at, updated_at, recurrence, series_id, occurrence, project_id, rank_key, checklist, version) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, 1)`
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "task with labels",
+			input: input{ts: &Task{OwnerID: optional.Some("p1"), Title: optional.Some("title"), Status: optional.Some(StatusTodo), Labels: []string{"urgent", "backend"}}},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(lastRank).
+					ExpectQuery().WithArgs(sql.NullString{String: "p1", Valid: true}).
+					WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow("i"))
+				mk.
+					ExpectPrepare(saveTask).
+					ExpectExec().WithArgs(
+						sqlmock.AnyArg(),
+						sql.NullString{String: "p1", Valid: true},
+						sql.NullString{String: "title", Valid: true},
+						sql.NullString{},
+						sql.NullString{String: "todo", Valid: true},
+						sql.NullString{},
+						sql.NullTime{},
+						sql.NullTime{},
+						sqlmock.AnyArg(),
+						sqlmock.AnyArg(),
+						sql.NullString{},
+						sql.NullString{},
+						sql.NullInt64{},
+						sql.NullString{},
+						sql.NullString{String: "j", Valid: true},
+						nil,
+					).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				stmt := mk.ExpectPrepare(`INSERT INTO task_labels (task_id, label) VALUES ($1, $2) ON CONFLICT DO NOTHING`)
+				stmt.ExpectExec().WithArgs(sqlmock.AnyArg(), "urgent").WillReturnResult(sqlmock.NewResult(0, 1))
+				stmt.ExpectExec().WithArgs(sqlmock.AnyArg(), "backend").WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectCommit()
+			},
+		},
+
+		// failure cases
+		{
+			title: "non existing project",
+			input: input{ts: &Task{OwnerID: optional.Some("p1"), Title: optional.Some("title"), Status: optional.Some(StatusTodo), ProjectID: optional.Some("project")}},
+			output: output{
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: project not found",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(`SELECT COUNT(*) FROM projects WHERE id = $1 AND owner_id = $2`).
+					ExpectQuery().WithArgs("project", "p1").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mk.ExpectRollback()
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			vl := NewValidatorMock()
+			vl.On("Validate", mock.Anything).Return(nil)
+
+			st := NewStoragePostgres(db, vl, nil)
+
+			// act
+			err = st.Save(c.input.ts)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+			vl.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStoragePostgres_Update(t *testing.T) {
+	type input struct {ts *Task}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+	}
+
+	// -> the task is locked in the transaction, the statements are matched as they are sent
+	getState := `SELECT tasks.owner_id, task_grants.permission, tasks.status, tasks.series_id, tasks.occurrence, tasks.version, tasks.rank_key, tasks.checklist, tasks.created_at, tasks.deleted_at FROM tasks LEFT JOIN task_grants ON task_grants.task_id = tasks.id AND task_grants.profile_id = $1 WHERE tasks.id = $2 AND tasks.deleted_at IS NULL FOR UPDATE OF tasks`
+	state := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"owner_id", "permission", "status", "series_id", "occurrence", "version", "rank_key", "checklist", "created_at", "deleted_at"}).AddRow("p1", nil, "todo", nil, nil, 1, "i", nil, nil, nil)
+	}
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "task with labels",
+			input: input{ts: &Task{ID: optional.Some("id"), Title: optional.Some("title"), Status: optional.Some(StatusInProgress), Labels: []string{"backend"}}},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(getState).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(state())
+				mk.
+					ExpectPrepare(`UPDATE tasks SET title = $1, description = $2, status = $3, parent_id = $4, start_at = $5, due_at = $6, updated_at = $7, recurrence = $8, series_id = $9, occurrence = $10, project_id = $11, version = version + 1 WHERE id = $12 AND version = $13 AND deleted_at IS NULL`).
+					ExpectExec().WithArgs(
+						sql.NullString{String: "title", Valid: true},
+						sql.NullString{},
+						sql.NullString{String: "in_progress", Valid: true},
+						sql.NullString{},
+						sql.NullTime{},
+						sql.NullTime{},
+						sqlmock.AnyArg(),
+						sql.NullString{},
+						sql.NullString{},
+						sql.NullInt64{},
+						sql.NullString{},
+						sql.NullString{String: "id", Valid: true},
+						sql.NullInt64{Int64: 1, Valid: true},
+					).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.
+					ExpectPrepare(`DELETE FROM task_labels WHERE task_id = $1`).
+					ExpectExec().WithArgs(sql.NullString{String: "id", Valid: true}).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.
+					ExpectPrepare(`INSERT INTO task_labels (task_id, label) VALUES ($1, $2) ON CONFLICT DO NOTHING`).
+					ExpectExec().WithArgs("id", "backend").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectCommit()
+			},
+		},
+
+		// failure cases
+		{
+			title: "version mismatch",
+			input: input{ts: &Task{ID: optional.Some("id"), Title: optional.Some("title"), Status: optional.Some(StatusTodo), Version: optional.Some(2)}},
+			output: output{
+				err: ErrStorageVersionMismatch,
+				errMsg: "storage task version mismatch: version",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(getState).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(state())
+				mk.ExpectRollback()
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			vl := NewValidatorMock()
+			vl.On("Validate", mock.Anything).Return(nil)
+			vl.On("Transition", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+			st := NewStoragePostgres(db, vl, nil)
+
+			// act
+			err = st.Update("p1", c.input.ts)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+			vl.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStoragePostgres_AddLabel(t *testing.T) {
+	type input struct {id string; label string}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+		setValidator func(mk *ValidatorMock)
+	}
+
+	// rows of the task
+	rows := func() *sqlmock.Rows {
+		cols := []string{"id", "owner_id", "title", "description", "status", "parent_id", "start_at", "due_at", "created_at", "updated_at", "deleted_at", "recurrence", "series_id", "occurrence", "version", "project_id", "rank_key", "checklist", "labels"}
+		return sqlmock.NewRows(cols).AddRow("id", nil, "title", nil, "todo", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, "backend")
+	}
+	// -> the statements are matched as they are sent
+	getAccess := `SELECT tasks.owner_id, task_grants.permission FROM tasks LEFT JOIN task_grants ON task_grants.task_id = tasks.id AND task_grants.profile_id = $1 WHERE tasks.id = $2 AND tasks.deleted_at IS NULL`
+	getTask := `SELECT id, owner_id, title, description, status, parent_id, start_at, due_at, created_at, updated_at, deleted_at, recurrence, series_id, occurrence, version, project_id, rank_key, checklist, (SELECT STRING_AGG(label, ',' ORDER BY label) FROM task_labels WHERE task_labels.task_id = tasks.id) AS labels FROM tasks WHERE id = $1 AND deleted_at IS NULL AND (tasks.owner_id = $2 OR EXISTS (SELECT 1 FROM task_grants WHERE task_grants.task_id = tasks.id AND task_grants.profile_id = $3))`
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "add a label",
+			input: input{id: "id", label: "urgent"},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(getAccess).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission"}).AddRow("p1", nil))
+				mk.
+					ExpectPrepare(getTask).
+					ExpectQuery().WithArgs("id", "p1", "p1").
+					WillReturnRows(rows())
+				mk.
+					ExpectPrepare(`INSERT INTO task_labels (task_id, label) VALUES ($1, $2) ON CONFLICT DO NOTHING`).
+					ExpectExec().WithArgs("id", "urgent").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.
+					ExpectPrepare(`UPDATE tasks SET version = version + 1 WHERE id = $1`).
+					ExpectExec().WithArgs("id").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectCommit()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+		{
+			title: "add a label added meanwhile",
+			input: input{id: "id", label: "urgent"},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(getAccess).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnRows(sqlmock.NewRows([]string{"owner_id", "permission"}).AddRow("p1", nil))
+				mk.
+					ExpectPrepare(getTask).
+					ExpectQuery().WithArgs("id", "p1", "p1").
+					WillReturnRows(rows())
+				mk.
+					ExpectPrepare(`INSERT INTO task_labels (task_id, label) VALUES ($1, $2) ON CONFLICT DO NOTHING`).
+					ExpectExec().WithArgs("id", "urgent").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mk.ExpectCommit()
+			},
+			setValidator: func(mk *ValidatorMock) {
+				mk.On("Validate", mock.Anything).Return(nil)
+			},
+		},
+
+		// failure cases
+		{
+			title: "non existing task",
+			input: input{id: "id", label: "urgent"},
+			output: output{
+				err: ErrStorageNotFound,
+				errMsg: "storage task not found: query row",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(getAccess).
+					ExpectQuery().WithArgs("p1", "id").
+					WillReturnError(sql.ErrNoRows)
+				mk.ExpectRollback()
+			},
+			setValidator: func(mk *ValidatorMock) {},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			vl := NewValidatorMock()
+			c.setValidator(vl)
+
+			st := NewStoragePostgres(db, vl, nil)
+
+			// act
+			err = st.AddLabel("p1", c.input.id, c.input.label, optional.None[int]())
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+			vl.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStoragePostgres_Move(t *testing.T) {
+	type input struct {id string; afterId string; beforeId string}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		// io
+		title  		 string
+		input  		 input
+		output 		 output
+		// process
+		setDatabase  func(mk sqlmock.Sqlmock)
+	}
+
+	// -> the ranks are locked in the transaction, the statements are matched as they are sent
+	getRank := `SELECT rank_key FROM tasks WHERE id = $1 AND owner_id = $2 AND deleted_at IS NULL FOR UPDATE OF tasks`
+
+	cases := []testCase{
+		// success cases
+		{
+			title: "move a task after another one",
+			input: input{id: "3", afterId: "1"},
+			output: output{err: nil, errMsg: ""},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(getRank).
+					ExpectQuery().WithArgs("3", "p1").
+					WillReturnRows(sqlmock.NewRows([]string{"rank_key"}).AddRow("y"))
+				mk.
+					ExpectPrepare(getRank).
+					ExpectQuery().WithArgs("1", "p1").
+					WillReturnRows(sqlmock.NewRows([]string{"rank_key"}).AddRow("i"))
+				mk.
+					ExpectPrepare(`SELECT MIN(rank_key) FROM tasks WHERE owner_id = $1 AND id <> $2 AND rank_key > $3`).
+					ExpectQuery().WithArgs("p1", "3", "i").
+					WillReturnRows(sqlmock.NewRows([]string{"min"}).AddRow("q"))
+				mk.
+					ExpectPrepare(`UPDATE tasks SET rank_key = $1, version = version + 1 WHERE id = $2`).
+					ExpectExec().WithArgs("m", "3").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectCommit()
+			},
+		},
+
+		// failure cases
+		{
+			title: "non existing neighbour",
+			input: input{id: "3", afterId: "1"},
+			output: output{
+				err: ErrStorageInvalid,
+				errMsg: "storage invalid task: neighbour not found",
+			},
+			setDatabase: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.
+					ExpectPrepare(getRank).
+					ExpectQuery().WithArgs("3", "p1").
+					WillReturnRows(sqlmock.NewRows([]string{"rank_key"}).AddRow("y"))
+				mk.
+					ExpectPrepare(getRank).
+					ExpectQuery().WithArgs("1", "p1").
+					WillReturnError(sql.ErrNoRows)
+				mk.ExpectRollback()
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setDatabase(mk)
+
+			st := NewStoragePostgres(db, NewValidatorMock(), nil)
+
+			// act
+			err = st.Move("p1", c.input.id, c.input.afterId, c.input.beforeId, optional.None[int]())
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStoragePostgres_Batch(t *testing.T) {
+	// arrange
+	// -> the transactioner runs the atomic batch, its statements are rewritten too
+	db, mk, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	mk.ExpectBegin()
+	mk.
+		ExpectPrepare(`UPDATE tasks SET deleted_at = $1 WHERE id = $2 AND owner_id = $3 AND deleted_at IS NULL`).
+		ExpectExec().WithArgs(sqlmock.AnyArg(), "1", "p1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mk.
+		ExpectPrepare(`UPDATE tasks SET deleted_at = $1 WHERE id = $2 AND owner_id = $3 AND deleted_at IS NULL`).
+		ExpectExec().WithArgs(sqlmock.AnyArg(), "2", "p1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mk.ExpectRollback()
+
+	st := NewStoragePostgres(db, NewValidatorMock(), nil)
+
+	// act
+	rs, err := st.Batch("p1", []*Op{{Kind: OpDelete, ID: "1"}, {Kind: OpDelete, ID: "2"}}, ModeAtomic)
+
+	// assert
+	assert.ErrorIs(t, err, ErrStorageBatchAborted)
+	assert.Len(t, rs, 2)
+	assert.ErrorIs(t, rs[1].Err, ErrStorageNotFound)
+	assert.NoError(t, mk.ExpectationsWereMet())
+}
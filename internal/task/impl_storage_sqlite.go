@@ -0,0 +1,136 @@
+package task
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// SchemaSQLite creates the tables of the SQLite storage, if they do not exist.
+// - the tables are the ones of the MySQL storage, with the same relations (foreign keys must be enforced, see sqlite.Open)
+// - projects is the table of the projects of the tasks (see project.StorageSQLite), task_history the one of their history (see HistorySQLite)
+// and task_comments the one of their comments (see comment.StorageSQLite)
+const SchemaSQLite = `
+CREATE TABLE IF NOT EXISTS projects (
+	id TEXT PRIMARY KEY,
+	owner_id TEXT NOT NULL,
+	name TEXT NOT NULL,
+	description TEXT,
+	created_at DATETIME,
+	updated_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_projects_owner ON projects (owner_id);
+CREATE TABLE IF NOT EXISTS tasks (
+	id TEXT PRIMARY KEY,
+	owner_id TEXT NOT NULL,
+	title TEXT,
+	description TEXT,
+	status TEXT,
+	parent_id TEXT REFERENCES tasks (id) ON DELETE SET NULL,
+	start_at DATETIME,
+	due_at DATETIME,
+	created_at DATETIME,
+	updated_at DATETIME,
+	deleted_at DATETIME,
+	recurrence TEXT,
+	series_id TEXT REFERENCES tasks (id) ON DELETE SET NULL,
+	occurrence INTEGER,
+	version INTEGER NOT NULL DEFAULT 1,
+	project_id TEXT REFERENCES projects (id) ON DELETE RESTRICT,
+	rank_key TEXT,
+	checklist TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_tasks_owner_rank ON tasks (owner_id, rank_key);
+CREATE INDEX IF NOT EXISTS idx_tasks_parent ON tasks (parent_id);
+CREATE TABLE IF NOT EXISTS task_labels (
+	task_id TEXT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+	label TEXT NOT NULL,
+	PRIMARY KEY (task_id, label)
+);
+CREATE TABLE IF NOT EXISTS task_dependencies (
+	task_id TEXT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+	blocker_id TEXT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+	PRIMARY KEY (task_id, blocker_id)
+);
+CREATE TABLE IF NOT EXISTS task_grants (
+	task_id TEXT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+	profile_id TEXT NOT NULL,
+	permission TEXT NOT NULL,
+	PRIMARY KEY (task_id, profile_id)
+);
+CREATE TABLE IF NOT EXISTS task_history (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	task_id TEXT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+	profile_id TEXT,
+	action TEXT NOT NULL,
+	changes TEXT,
+	created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_task_history_task ON task_history (task_id);
+CREATE TABLE IF NOT EXISTS task_comments (
+	id TEXT PRIMARY KEY,
+	task_id TEXT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+	author_id TEXT NOT NULL,
+	parent_id TEXT REFERENCES task_comments (id) ON DELETE CASCADE,
+	body TEXT,
+	created_at DATETIME,
+	updated_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_task_comments_task ON task_comments (task_id, created_at);`
+
+// dialectSQLite writes the statements of the MySQL storage for SQLite.
+var dialectSQLite = newDialect(&dialect{
+	// -> SQLite can not join in a delete or an update, nor has INSERT IGNORE or ON DUPLICATE KEY
+	queries: map[string]string{
+		QuerySaveTaskLabel: 	   querySaveTaskLabelSQLite,
+		QuerySaveTaskDependency:   querySaveTaskDependencySQLite,
+		QueryRemoveTaskLabel: 	   queryRemoveTaskLabel,
+		QueryRemoveTaskDependency: queryRemoveTaskDependency,
+		QueryCompleteDescendants:  queryCompleteDescendants,
+		QuerySaveTaskGrant: 	   querySaveTaskGrant,
+		QueryListUnbalancedOwners: queryListUnbalancedOwnersSQLite,
+	},
+	// -> the transactions hold the only connection (see sqlite.Open), there is nothing else to lock
+	lock: 	"",
+	labels: columnLabelsSQLite,
+	// -> LIKE has no escape character by default
+	like: 	`LIKE ? ESCAPE '\'`,
+	// -> = is case sensitive
+	equal: 	"%s = ? COLLATE NOCASE",
+	// -> nulls are the smallest values, as in MySQL
+	asc: 	"ASC",
+	desc: 	"DESC",
+})
+
+// statements of the MySQL storage written for SQLite.
+const (
+	querySaveTaskLabelSQLite = `INSERT OR IGNORE INTO task_labels (task_id, label) VALUES (?, ?)`
+	querySaveTaskDependencySQLite = `INSERT OR IGNORE INTO task_dependencies (task_id, blocker_id) VALUES (?, ?)`
+	// -> LENGTH counts the characters of a text
+	queryListUnbalancedOwnersSQLite = `SELECT DISTINCT owner_id FROM tasks WHERE rank_key IS NULL OR LENGTH(rank_key) > ?`
+	// -> group_concat has no order by: the labels are sorted before
+	columnLabelsSQLite = `(SELECT GROUP_CONCAT(label) FROM (SELECT label FROM task_labels WHERE task_labels.task_id = tasks.id ORDER BY label)) AS labels`
+)
+
+// constructor
+// - the schema is created on the database if it does not exist (see SchemaSQLite)
+// - cfg is optional (nil for the default config)
+func NewStorageSQLite(db *sql.DB, vl Validator, cfg *Config) (st *StorageSQLite, err error) {
+	_, err = db.Exec(SchemaSQLite)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrStorageInternal, "schema")
+		return
+	}
+
+	s := NewStorageMySQL(db, vl, cfg)
+	s.dl = dialectSQLite
+	st = &StorageSQLite{StorageMySQL: s}
+	return
+}
+
+// StorageSQLite is the implementation of the task storage on an embedded SQLite database (see sqlite.Open).
+// - it runs the statements of the MySQL storage, rewritten for SQLite (see dialectSQLite), so it behaves the same way
+// - times are stored as text in UTC, in a format that compares in order
+type StorageSQLite struct {
+	// StorageMySQL runs the statements
+	*StorageMySQL
+}
@@ -0,0 +1,322 @@
+package task
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"api/pkg/sqlite"
+
+	"github.com/LNMMusic/optional"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newStorageSQLite returns a SQLite storage on a new database file.
+func newStorageSQLite(t *testing.T, cfg *Config) (st *StorageSQLite) {
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "tasks.db"))
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	st, err = NewStorageSQLite(db, NewValidatorLocal(nil), cfg)
+	assert.NoError(t, err)
+	return
+}
+
+// saveSQLite saves a task of the profile with the given title and returns its id.
+func saveSQLite(t *testing.T, st *StorageSQLite, profileId string, title string) (id string) {
+	ts := &Task{OwnerID: optional.Some(profileId), Title: optional.Some(title), Status: optional.Some(StatusTodo)}
+	assert.NoError(t, st.Save(ts))
+	id, _ = ts.ID.Unwrap()
+	return
+}
+
+// idsSQLite returns the ids of the given tasks.
+func idsSQLite(ts []*Task) (ids []string) {
+	for _, t := range ts {
+		id, _ := t.ID.Unwrap()
+		ids = append(ids, id)
+	}
+	return
+}
+
+// Tests
+func TestStorageSQLite_Schema(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	db, err := sqlite.Open(filepath.Join(dir, "tasks.db"))
+	assert.NoError(t, err)
+	defer db.Close()
+	st, err := NewStorageSQLite(db, NewValidatorLocal(nil), nil)
+	assert.NoError(t, err)
+	id := saveSQLite(t, st, "p1", "title")
+
+	// act
+	// -> the schema is only created the first time
+	reopened, err := NewStorageSQLite(db, NewValidatorLocal(nil), nil)
+
+	// assert
+	assert.NoError(t, err)
+	ts, err := reopened.Get("p1", id)
+	assert.NoError(t, err)
+	assert.Equal(t, optional.Some("title"), ts.Title)
+}
+
+func TestStorageSQLite_SaveGet(t *testing.T) {
+	// arrange
+	st := newStorageSQLite(t, nil)
+	dueAt := time.Date(2024, 1, 10, 12, 30, 0, 0, time.UTC)
+	ts := &Task{
+		OwnerID: optional.Some("p1"),
+		Title: optional.Some("title"),
+		Status: optional.Some(StatusTodo),
+		DueAt: optional.Some(dueAt),
+		Labels: []string{"work", "backend"},
+	}
+
+	// act
+	err := st.Save(ts)
+
+	// assert
+	assert.NoError(t, err)
+	id, _ := ts.ID.Unwrap()
+	stored, err := st.Get("p1", id)
+	assert.NoError(t, err)
+	assert.Equal(t, optional.Some("title"), stored.Title)
+	assert.Equal(t, optional.Some(1), stored.Version)
+	assert.Equal(t, ts.Rank, stored.Rank)
+	assert.Equal(t, []string{"backend", "work"}, stored.Labels)
+	storedDueAt, _ := stored.DueAt.Unwrap()
+	assert.True(t, dueAt.Equal(storedDueAt))
+	_, err = st.Get("p2", id)
+	assert.ErrorIs(t, err, ErrStorageNotFound)
+}
+
+func TestStorageSQLite_List(t *testing.T) {
+	// arrange
+	st := newStorageSQLite(t, nil)
+	for i, title := range []string{"buy milk", "buy 100% juice", "call mom"} {
+		ts := &Task{
+			OwnerID: optional.Some("p1"),
+			Title: optional.Some(title),
+			Status: optional.Some(StatusTodo),
+			DueAt: optional.Some(time.Date(2024, 1, 10+i, 0, 0, 0, 0, time.UTC)),
+		}
+		assert.NoError(t, st.Save(ts))
+	}
+	saveSQLite(t, st, "p2", "buy bread")
+
+	// act
+	// -> times are compared in UTC, whatever their zone
+	local := time.FixedZone("local", -3*60*60)
+	query := &Query{
+		Size: 1,
+		Filter: And{Filters: []Filter{
+			Condition{Field: FieldTitle, Operator: OperatorContains, Value: "buy"},
+			Condition{Field: FieldDueAt, Operator: OperatorLte, Value: time.Date(2024, 1, 10, 21, 0, 0, 0, local)},
+		}},
+		Sort: Sort{Field: FieldDueAt, Desc: true},
+	}
+	first, err := st.List("p1", query)
+	assert.NoError(t, err)
+	query.Cursor, _ = first.Next.Unwrap()
+	second, err := st.List("p1", query)
+	assert.NoError(t, err)
+	literal, err := st.List("p1", &Query{Filter: Condition{Field: FieldTitle, Operator: OperatorContains, Value: "100%"}})
+	assert.NoError(t, err)
+
+	// assert
+	assert.Len(t, first.Tasks, 1)
+	assert.Equal(t, optional.Some("buy 100% juice"), first.Tasks[0].Title)
+	assert.Len(t, second.Tasks, 1)
+	assert.Equal(t, optional.Some("buy milk"), second.Tasks[0].Title)
+	assert.False(t, second.Next.IsSome())
+	assert.Len(t, literal.Tasks, 1)
+}
+
+func TestStorageSQLite_Update(t *testing.T) {
+	// arrange
+	st := newStorageSQLite(t, nil)
+	id := saveSQLite(t, st, "p1", "title")
+
+	// act
+	ts := &Task{ID: optional.Some(id), Title: optional.Some("new title"), Status: optional.Some(StatusInProgress), Version: optional.Some(1), Labels: []string{"work"}}
+	err := st.Update("p1", ts)
+	stale := &Task{ID: optional.Some(id), Title: optional.Some("stale"), Status: optional.Some(StatusInProgress), Version: optional.Some(1)}
+	errStale := st.Update("p1", stale)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, optional.Some(2), ts.Version)
+	assert.ErrorIs(t, errStale, ErrStorageVersionMismatch)
+	stored, err := st.Get("p1", id)
+	assert.NoError(t, err)
+	assert.Equal(t, optional.Some("new title"), stored.Title)
+	assert.Equal(t, []string{"work"}, stored.Labels)
+}
+
+func TestStorageSQLite_CompleteCascade(t *testing.T) {
+	// arrange
+	st := newStorageSQLite(t, &Config{Hierarchy: HierarchyCascade})
+	parentId := saveSQLite(t, st, "p1", "parent")
+	child := &Task{OwnerID: optional.Some("p1"), Title: optional.Some("child"), Status: optional.Some(StatusTodo), ParentID: optional.Some(parentId)}
+	assert.NoError(t, st.Save(child))
+	childId, _ := child.ID.Unwrap()
+
+	// act
+	err := st.Update("p1", &Task{ID: optional.Some(parentId), Title: optional.Some("parent"), Status: optional.Some(StatusDone)})
+
+	// assert
+	assert.NoError(t, err)
+	nd, err := st.Tree("p1", parentId)
+	assert.NoError(t, err)
+	assert.Len(t, nd.Children, 1)
+	assert.Equal(t, optional.Some(StatusDone), nd.Children[0].Task.Status)
+	assert.Equal(t, optional.Some(childId), nd.Children[0].Task.ID)
+	// -> a task can not be the parent of its parent
+	err = st.Update("p1", &Task{ID: optional.Some(parentId), Title: optional.Some("parent"), Status: optional.Some(StatusDone), ParentID: optional.Some(childId)})
+	assert.ErrorIs(t, err, ErrStorageCycle)
+}
+
+func TestStorageSQLite_TrashAndPurge(t *testing.T) {
+	// arrange
+	st := newStorageSQLite(t, nil)
+	id := saveSQLite(t, st, "p1", "title")
+	assert.NoError(t, st.AddLabel("p1", id, "work", optional.None[int]()))
+
+	// act
+	assert.NoError(t, st.Delete("p1", id, optional.None[int]()))
+	trash, err := st.List("p1", &Query{Deleted: true})
+	assert.NoError(t, err)
+	n, err := st.Purge(time.Now().Add(time.Minute))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, trash.Tasks, 1)
+	assert.Equal(t, 1, n)
+	ls, err := st.Labels("p1")
+	assert.NoError(t, err)
+	assert.Empty(t, ls)
+}
+
+func TestStorageSQLite_Labels(t *testing.T) {
+	// arrange
+	st := newStorageSQLite(t, nil)
+	id1 := saveSQLite(t, st, "p1", "title 1")
+	id2 := saveSQLite(t, st, "p1", "title 2")
+
+	// act
+	assert.NoError(t, st.AddLabel("p1", id1, "work", optional.None[int]()))
+	assert.NoError(t, st.AddLabel("p1", id1, "work", optional.None[int]()))
+	assert.NoError(t, st.AddLabel("p1", id2, "work", optional.None[int]()))
+	assert.NoError(t, st.AddLabel("p1", id2, "home", optional.None[int]()))
+	assert.NoError(t, st.RemoveLabel("p1", id2, "work", optional.None[int]()))
+	ls, err := st.Labels("p1")
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []*Label{{Name: "home", Tasks: 1}, {Name: "work", Tasks: 1}}, ls)
+	ts, err := st.Get("p1", id1)
+	assert.NoError(t, err)
+	assert.Equal(t, optional.Some(2), ts.Version)
+}
+
+func TestStorageSQLite_Dependencies(t *testing.T) {
+	// arrange
+	st := newStorageSQLite(t, nil)
+	id1 := saveSQLite(t, st, "p1", "title 1")
+	id2 := saveSQLite(t, st, "p1", "title 2")
+	id3 := saveSQLite(t, st, "p1", "title 3")
+
+	// act
+	assert.NoError(t, st.AddDependency("p1", id1, id2))
+	assert.NoError(t, st.AddDependency("p1", id2, id3))
+	errCycle := st.AddDependency("p1", id3, id1)
+	ts, err := st.Order("p1", []string{id1, id2, id3})
+
+	// assert
+	assert.ErrorIs(t, errCycle, ErrStorageCycle)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{id3, id2, id1}, idsSQLite(ts))
+	assert.NoError(t, st.RemoveDependency("p1", id1, id2))
+	assert.ErrorIs(t, st.RemoveDependency("p1", id1, id2), ErrStorageNotFound)
+}
+
+func TestStorageSQLite_Grants(t *testing.T) {
+	// arrange
+	st := newStorageSQLite(t, nil)
+	id := saveSQLite(t, st, "p1", "title")
+
+	// act
+	assert.NoError(t, st.Grant("p1", id, &Grant{ProfileID: "p2", Permission: PermissionRead}))
+	// -> the permission is replaced
+	assert.NoError(t, st.Grant("p1", id, &Grant{ProfileID: "p2", Permission: PermissionEdit}))
+	gs, err := st.Grants("p1", id)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []*Grant{{ProfileID: "p2", Permission: PermissionEdit}}, gs)
+	pg, err := st.Shared("p2", &Query{})
+	assert.NoError(t, err)
+	assert.Len(t, pg.Tasks, 1)
+	assert.NoError(t, st.AddLabel("p2", id, "work", optional.None[int]()))
+}
+
+func TestStorageSQLite_MoveAndRebalance(t *testing.T) {
+	// arrange
+	st := newStorageSQLite(t, nil)
+	id1 := saveSQLite(t, st, "p1", "title 1")
+	id2 := saveSQLite(t, st, "p1", "title 2")
+	id3 := saveSQLite(t, st, "p1", "title 3")
+
+	// act
+	assert.NoError(t, st.Move("p1", id3, "", id1, optional.None[int]()))
+	n, err := st.Rebalance()
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+	pg, err := st.List("p1", &Query{Sort: Sort{Field: FieldRank}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{id3, id1, id2}, idsSQLite(pg.Tasks))
+}
+
+func TestStorageSQLite_Checklist(t *testing.T) {
+	// arrange
+	st := newStorageSQLite(t, nil)
+	id := saveSQLite(t, st, "p1", "title")
+
+	// act
+	item := &Item{Text: "milk"}
+	assert.NoError(t, st.AddItem("p1", id, item, optional.None[int]()))
+	assert.NoError(t, st.CheckItem("p1", id, item.ID, true, optional.None[int]()))
+	errNotFound := st.RemoveItem("p1", id, "unknown", optional.None[int]())
+
+	// assert
+	assert.ErrorIs(t, errNotFound, ErrStorageNotFound)
+	ts, err := st.Get("p1", id)
+	assert.NoError(t, err)
+	assert.Equal(t, []Item{{ID: item.ID, Text: "milk", Checked: true}}, ts.Checklist)
+	assert.Equal(t, optional.Some(3), ts.Version)
+}
+
+func TestStorageSQLite_BatchAtomic(t *testing.T) {
+	// arrange
+	st := newStorageSQLite(t, nil)
+	id := saveSQLite(t, st, "p1", "title")
+	ops := []*Op{
+		{Kind: OpCreate, Task: &Task{OwnerID: optional.Some("p1"), Title: optional.Some("new"), Status: optional.Some(StatusTodo)}},
+		{Kind: OpDelete, ID: id},
+		{Kind: OpDelete, ID: "unknown"},
+	}
+
+	// act
+	_, err := st.Batch("p1", ops, ModeAtomic)
+
+	// assert
+	assert.ErrorIs(t, err, ErrStorageBatchAborted)
+	pg, err := st.List("p1", &Query{})
+	assert.NoError(t, err)
+	assert.Len(t, pg.Tasks, 1)
+	assert.Equal(t, optional.Some(id), pg.Tasks[0].ID)
+}
@@ -0,0 +1,555 @@
+package task
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/LNMMusic/optional"
+)
+
+const (
+	// FileWAL is the name of the write-ahead log in the directory of the WAL storage
+	FileWAL 	 = "tasks.wal"
+	// FileSnapshot is the name of the snapshot in the directory of the WAL storage
+	FileSnapshot = "tasks.snapshot"
+	// DefaultSnapshotEvery is the default number of records of the log it is compacted after (see Config.SnapshotEvery)
+	DefaultSnapshotEvery = 1000
+)
+
+// constructor
+// - the tasks are loaded from the snapshot and the log in the directory (created if it does not exist)
+// - cfg is optional (nil for the default config)
+func NewStorageWAL(dir string, vl Validator, cfg *Config) (st *StorageWAL, err error) {
+	st = &StorageWAL{StorageLocal: NewStorageLocal([]*Task{}, vl, cfg), dir: dir, every: DefaultSnapshotEvery, write: (*os.File).Write}
+	if cfg != nil && cfg.SnapshotEvery > 0 {
+		st.every = cfg.SnapshotEvery
+	}
+
+	err = os.MkdirAll(dir, 0o755)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrStorageInternal, err)
+		return
+	}
+	err = st.load()
+	if err != nil {
+		st = nil
+		return
+	}
+	st.StorageLocal.commit = st.logged
+	return
+}
+
+// StorageWAL is the implementation of the task storage that keeps the tasks in memory (see StorageLocal) and on disk, in a directory.
+// - every change is appended to a write-ahead log, synced to disk before it returns: the log has a record per change,
+// with the state of the tasks it touched (their dependencies and grants included, or their removal)
+// - the log is compacted into a snapshot of all the tasks every Config.SnapshotEvery records
+// - on start, the snapshot and then the log are replayed; a last record that was only half written is dropped
+// - a change is logged before the lock of the tasks is released, so the readers never see a change that is not on disk
+// - a change that can not be written to the log is undone in memory and what was written of it is cut from the log (it fails with ErrStorageInternal),
+// so the records that follow are not lost behind a torn one; if the log can not be cut, the storage refuses the changes that follow
+// - it is safe for concurrent use: changes are applied and logged one at a time, reads go to the memory
+type StorageWAL struct {
+	// StorageLocal keeps the tasks in memory (reads go straight to it, changes are logged)
+	*StorageLocal
+
+	// mu serializes the changes, so they are logged in the order they are applied
+	mu 	  sync.Mutex
+	// dir is the directory of the log and the snapshot
+	dir   string
+	// log is the write-ahead log, open for appending
+	log   *os.File
+	// records is the number of records of the log
+	records int
+	// size is the size of the whole records of the log, where the next one starts
+	size int64
+	// failed is the error the log could not be cut back to its whole records with (nil if it could)
+	failed error
+	// write writes to the log
+	write func(f *os.File, p []byte) (n int, err error)
+	// every is the number of records the log is compacted after
+	every int
+}
+
+// recordWAL is a record of the log (or the snapshot): the state of the tasks changed at once.
+type recordWAL struct {
+	Entries []*entryWAL `json:"entries"`
+}
+
+// entryWAL is the state of a task.
+type entryWAL struct {
+	ID 	   string 	`json:"id"`
+	// Task is the task (nil if it was removed)
+	Task   *Task 	`json:"task"`
+	Deps   []string `json:"deps,omitempty"`
+	Grants []*Grant `json:"grants,omitempty"`
+}
+
+// header of a record: the length of its payload and its checksum (CRC-32, IEEE), both big endian
+const headerWAL = 8
+
+// WithContext returns the storage itself: the changes are applied in memory and logged to a file, there is nothing to cancel.
+// - it is not left to StorageLocal, which would return the local storage without the log
+func (s *StorageWAL) WithContext(ctx context.Context) (st Storage) {
+	st = s
+	return
+}
+
+// recording sets the recorder of the writes, and returns the storage itself.
+// - it is not left to StorageLocal, for the same reason as WithContext
+func (s *StorageWAL) recording(rc recorder) (st Storage) {
+	s.StorageLocal.recording(rc)
+	st = s
+	return
+}
+
+func (s *StorageWAL) Save(task *Task) (err error) {
+	err = s.change(func() error { return s.StorageLocal.Save(task) })
+	return
+}
+
+func (s *StorageWAL) Update(profileId string, task *Task) (err error) {
+	err = s.change(func() error { return s.StorageLocal.Update(profileId, task) })
+	return
+}
+
+func (s *StorageWAL) Delete(profileId string, id string, version optional.Option[int]) (err error) {
+	err = s.change(func() error { return s.StorageLocal.Delete(profileId, id, version) })
+	return
+}
+
+func (s *StorageWAL) Restore(profileId string, id string, version optional.Option[int]) (err error) {
+	err = s.change(func() error { return s.StorageLocal.Restore(profileId, id, version) })
+	return
+}
+
+func (s *StorageWAL) Purge(before time.Time) (n int, err error) {
+	err = s.change(func() (err error) {
+		n, err = s.StorageLocal.Purge(before)
+		return
+	})
+	return
+}
+
+func (s *StorageWAL) AddLabel(profileId string, id string, label string, version optional.Option[int]) (err error) {
+	err = s.change(func() error { return s.StorageLocal.AddLabel(profileId, id, label, version) })
+	return
+}
+
+func (s *StorageWAL) RemoveLabel(profileId string, id string, label string, version optional.Option[int]) (err error) {
+	err = s.change(func() error { return s.StorageLocal.RemoveLabel(profileId, id, label, version) })
+	return
+}
+
+func (s *StorageWAL) AddDependency(profileId string, id string, blockerId string) (err error) {
+	err = s.change(func() error { return s.StorageLocal.AddDependency(profileId, id, blockerId) })
+	return
+}
+
+func (s *StorageWAL) RemoveDependency(profileId string, id string, blockerId string) (err error) {
+	err = s.change(func() error { return s.StorageLocal.RemoveDependency(profileId, id, blockerId) })
+	return
+}
+
+func (s *StorageWAL) Grant(profileId string, id string, grant *Grant) (err error) {
+	err = s.change(func() error { return s.StorageLocal.Grant(profileId, id, grant) })
+	return
+}
+
+func (s *StorageWAL) Revoke(profileId string, id string, granteeId string) (err error) {
+	err = s.change(func() error { return s.StorageLocal.Revoke(profileId, id, granteeId) })
+	return
+}
+
+func (s *StorageWAL) Batch(profileId string, ops []*Op, mode Mode) (rs []*Result, err error) {
+	// an atomic batch is logged in a single record: on replay, its operations are applied all or none too
+	// (the operations of a best effort batch are logged one by one)
+	err = s.change(func() (err error) {
+		rs, err = s.StorageLocal.Batch(profileId, ops, mode)
+		return
+	})
+	return
+}
+
+func (s *StorageWAL) Move(profileId string, id string, afterId string, beforeId string, version optional.Option[int]) (err error) {
+	err = s.change(func() error { return s.StorageLocal.Move(profileId, id, afterId, beforeId, version) })
+	return
+}
+
+func (s *StorageWAL) Rebalance() (n int, err error) {
+	err = s.change(func() (err error) {
+		n, err = s.StorageLocal.Rebalance()
+		return
+	})
+	return
+}
+
+func (s *StorageWAL) AddItem(profileId string, id string, item *Item, version optional.Option[int]) (err error) {
+	err = s.change(func() error { return s.StorageLocal.AddItem(profileId, id, item, version) })
+	return
+}
+
+func (s *StorageWAL) CheckItem(profileId string, id string, itemId string, checked bool, version optional.Option[int]) (err error) {
+	err = s.change(func() error { return s.StorageLocal.CheckItem(profileId, id, itemId, checked, version) })
+	return
+}
+
+func (s *StorageWAL) MoveItem(profileId string, id string, itemId string, position int, version optional.Option[int]) (err error) {
+	err = s.change(func() error { return s.StorageLocal.MoveItem(profileId, id, itemId, position, version) })
+	return
+}
+
+func (s *StorageWAL) RemoveItem(profileId string, id string, itemId string, version optional.Option[int]) (err error) {
+	err = s.change(func() error { return s.StorageLocal.RemoveItem(profileId, id, itemId, version) })
+	return
+}
+
+// Compact writes a snapshot of all the tasks and empties the log.
+func (s *StorageWAL) Compact() (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err = s.compact()
+	return
+}
+
+// Close closes the log: the storage can not be changed anymore.
+func (s *StorageWAL) Close() (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err = s.log.Close()
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrStorageInternal, err)
+		return
+	}
+	return
+}
+
+// change applies the change to the tasks in memory, that logs it (see logged), and compacts the log when it is due.
+// - the change is logged even if the compaction that follows fails, it is tried again on the next change
+func (s *StorageWAL) change(apply func() error) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err = apply()
+	if err == nil && s.records >= s.every {
+		_ = s.compact()
+	}
+	return
+}
+
+// logged appends the state of the tasks a change touched to the log, before the lock of the tasks is released.
+// - a change that fails is undone by the local storage, it is not logged; nor is a change that can not be logged
+func (s *StorageWAL) logged(ims []*image) (err error) {
+	if s.failed != nil {
+		err = fmt.Errorf("%w: %v", ErrStorageInternal, s.failed)
+		return
+	}
+
+	// a task is touched again by each operation of a batch: it is logged once
+	ids := make([]string, 0, len(ims))
+	seen := make(map[string]bool, len(ims))
+	for _, im := range ims {
+		if seen[im.id] {
+			continue
+		}
+		seen[im.id] = true
+		ids = append(ids, im.id)
+	}
+	sort.Strings(ids)
+
+	err = s.append(s.entries(ids))
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrStorageInternal, err)
+		return
+	}
+	return
+}
+
+// entries returns a record with the state of the tasks with the given ids (the lock of the tasks must be held).
+func (s *StorageWAL) entries(ids []string) (rc *recordWAL) {
+	rc = &recordWAL{Entries: make([]*entryWAL, 0, len(ids))}
+	for _, id := range ids {
+		en := &entryWAL{ID: id, Deps: append([]string(nil), s.deps[id]...), Grants: copyGrants(s.grants[id])}
+		if t := s.lookup(id); t != nil {
+			en.Task = clone(t)
+		}
+		rc.Entries = append(rc.Entries, en)
+	}
+	return
+}
+
+// append writes the record at the end of the log and syncs it to disk.
+// - a record that can not be written whole, or synced, is cut from the log
+func (s *StorageWAL) append(rc *recordWAL) (err error) {
+	var frame []byte
+	frame, err = encodeRecord(rc)
+	if err != nil {
+		return
+	}
+
+	_, err = s.write(s.log, frame)
+	if err == nil {
+		err = s.log.Sync()
+	}
+	if err != nil {
+		e := s.cut()
+		if e != nil {
+			s.failed = fmt.Errorf("log not cut: %v", e)
+		}
+		return
+	}
+	s.size += int64(len(frame))
+	s.records++
+	return
+}
+
+// cut cuts the log back to its whole records, and syncs it to disk.
+func (s *StorageWAL) cut() (err error) {
+	err = s.log.Truncate(s.size)
+	if err != nil {
+		return
+	}
+	err = s.log.Sync()
+	return
+}
+
+// compact writes a snapshot of all the tasks and empties the log.
+// - the snapshot replaces the previous one at once (it is written aside and renamed), and the log is emptied after:
+// if it is not, replaying it over the new snapshot leaves the tasks as they are (records have the state of the tasks, not the changes)
+func (s *StorageWAL) compact() (err error) {
+	// snapshot
+	s.StorageLocal.mu.RLock()
+	ids := make([]string, 0, len(s.db))
+	for _, t := range s.db {
+		id, _ := t.ID.Unwrap()
+		ids = append(ids, id)
+	}
+	rc := s.entries(ids)
+	s.StorageLocal.mu.RUnlock()
+
+	var frame []byte
+	frame, err = encodeRecord(rc)
+	if err != nil {
+		return
+	}
+	path := filepath.Join(s.dir, FileSnapshot)
+	err = writeFile(path+".tmp", frame)
+	if err != nil {
+		return
+	}
+	err = os.Rename(path+".tmp", path)
+	if err != nil {
+		return
+	}
+	err = syncDir(s.dir)
+	if err != nil {
+		return
+	}
+
+	// log
+	err = s.log.Truncate(0)
+	if err != nil {
+		return
+	}
+	err = s.log.Sync()
+	if err != nil {
+		return
+	}
+	s.size = 0
+	s.records = 0
+	return
+}
+
+// load replays the snapshot and the log of the directory, and opens the log for appending.
+func (s *StorageWAL) load() (err error) {
+	// snapshot: written at once, it must be whole
+	var rcs []*recordWAL
+	f, err := os.Open(filepath.Join(s.dir, FileSnapshot))
+	switch {
+		case errors.Is(err, os.ErrNotExist):
+			err = nil
+		case err != nil:
+			err = fmt.Errorf("%w: %v", ErrStorageInternal, err)
+			return
+		default:
+			var torn bool
+			rcs, _, torn, err = readRecords(f)
+			f.Close()
+			if err == nil && torn {
+				err = errors.New("snapshot corrupted")
+			}
+			if err != nil {
+				err = fmt.Errorf("%w: %v", ErrStorageInternal, err)
+				return
+			}
+	}
+
+	// log: a last record that was only half written (or is corrupted) is dropped
+	s.log, err = os.OpenFile(filepath.Join(s.dir, FileWAL), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrStorageInternal, err)
+		return
+	}
+	logged, size, torn, err := readRecords(s.log)
+	if err == nil && torn {
+		err = s.log.Truncate(size)
+		if err == nil {
+			err = s.log.Sync()
+		}
+	}
+	if err != nil {
+		s.log.Close()
+		err = fmt.Errorf("%w: %v", ErrStorageInternal, err)
+		return
+	}
+	s.records = len(logged)
+	s.size = size
+
+	s.replay(append(rcs, logged...))
+	return
+}
+
+// replay applies the records to the tasks in memory, in order (before the changes are logged, see NewStorageWAL).
+func (s *StorageWAL) replay(rcs []*recordWAL) {
+	s.StorageLocal.mu.Lock()
+	defer s.StorageLocal.mu.Unlock()
+
+	// the last state of each task, in the order they were first logged
+	var ids []string
+	last := make(map[string]*entryWAL)
+	for _, rc := range rcs {
+		for _, en := range rc.Entries {
+			if _, ok := last[en.ID]; !ok {
+				ids = append(ids, en.ID)
+			}
+			last[en.ID] = en
+		}
+	}
+
+	s.db = make([]*Task, 0, len(ids))
+	for _, id := range ids {
+		en := last[id]
+		if en.Task != nil {
+			s.db = append(s.db, en.Task)
+		}
+		if len(en.Deps) > 0 {
+			s.deps[id] = en.Deps
+		}
+		if len(en.Grants) > 0 {
+			s.grants[id] = en.Grants
+		}
+	}
+	s.reindex()
+}
+
+// encodeRecord returns the record with its header.
+func encodeRecord(rc *recordWAL) (frame []byte, err error) {
+	var payload []byte
+	payload, err = json.Marshal(rc)
+	if err != nil {
+		return
+	}
+
+	frame = make([]byte, headerWAL+len(payload))
+	binary.BigEndian.PutUint32(frame[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(frame[4:8], crc32.ChecksumIEEE(payload))
+	copy(frame[headerWAL:], payload)
+	return
+}
+
+// readRecords reads the records of the file, from its start.
+// - size is the size of the whole records read
+// - torn: whether the file goes on after them with a record that is half written or does not match its checksum
+func readRecords(f *os.File) (rcs []*recordWAL, size int64, torn bool, err error) {
+	info, err := f.Stat()
+	if err != nil {
+		return
+	}
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		return
+	}
+
+	r := bufio.NewReader(f)
+	header := make([]byte, headerWAL)
+	for {
+		_, err = io.ReadFull(r, header)
+		if err == io.EOF {
+			err = nil
+			return
+		}
+		if err == io.ErrUnexpectedEOF {
+			err = nil
+			torn = true
+			return
+		}
+		if err != nil {
+			return
+		}
+
+		// a length past the end of the file is a half written header
+		n := int64(binary.BigEndian.Uint32(header[0:4]))
+		if size+headerWAL+n > info.Size() {
+			torn = true
+			return
+		}
+		payload := make([]byte, n)
+		_, err = io.ReadFull(r, payload)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = nil
+			torn = true
+			return
+		}
+		if err != nil {
+			return
+		}
+		rc := &recordWAL{}
+		if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) || json.Unmarshal(payload, rc) != nil {
+			torn = true
+			return
+		}
+
+		rcs = append(rcs, rc)
+		size += int64(headerWAL + len(payload))
+	}
+}
+
+// writeFile writes the data to a new file and syncs it to disk.
+func writeFile(path string, data []byte) (err error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	_, err = f.Write(data)
+	if err != nil {
+		return
+	}
+	err = f.Sync()
+	return
+}
+
+// syncDir syncs the directory to disk, so the files renamed in it stay renamed.
+func syncDir(dir string) (err error) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	defer d.Close()
+
+	err = d.Sync()
+	return
+}
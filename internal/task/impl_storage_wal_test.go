@@ -0,0 +1,279 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/LNMMusic/optional"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newStorageWAL returns a WAL storage on the directory, with a fixed time and sequential ids.
+func newStorageWAL(t *testing.T, dir string, cfg *Config) (st *StorageWAL) {
+	st, err := NewStorageWAL(dir, NewValidatorLocal(nil), cfg)
+	assert.NoError(t, err)
+	t.Cleanup(func() { st.Close() })
+
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	st.now = func() time.Time { return now }
+	next := len(st.Snapshot())
+	st.newId = func() string {
+		next++
+		return string(rune('0' + next))
+	}
+	return
+}
+
+// titlesOf returns the titles of the tasks.
+func titlesOf(ts []*Task) (titles []string) {
+	for _, tk := range ts {
+		title, _ := tk.Title.Unwrap()
+		titles = append(titles, title)
+	}
+	return
+}
+
+// Tests
+func TestStorageWAL_Replay(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	st := newStorageWAL(t, dir, nil)
+	for _, title := range []string{"title 1", "title 2", "title 3"} {
+		assert.NoError(t, st.Save(&Task{OwnerID: optional.Some("p1"), Title: optional.Some(title), Status: optional.Some(StatusTodo)}))
+	}
+	assert.NoError(t, st.AddLabel("p1", "1", "backend", optional.None[int]()))
+	assert.NoError(t, st.AddItem("p1", "1", &Item{Text: "milk"}, optional.None[int]()))
+	assert.NoError(t, st.AddDependency("p1", "2", "1"))
+	assert.NoError(t, st.Grant("p1", "2", &Grant{ProfileID: "p2", Permission: PermissionRead}))
+	assert.NoError(t, st.Delete("p1", "3", optional.None[int]()))
+	assert.NoError(t, st.Close())
+
+	// act
+	reopened := newStorageWAL(t, dir, nil)
+
+	// assert
+	assert.Equal(t, st.Snapshot(), reopened.Snapshot())
+	assert.Equal(t, st.deps, reopened.deps)
+	assert.Equal(t, st.grants, reopened.grants)
+	ts, err := reopened.Get("p2", "2")
+	assert.NoError(t, err)
+	assert.Equal(t, optional.Some("title 2"), ts.Title)
+	_, err = reopened.Get("p1", "3")
+	assert.ErrorIs(t, err, ErrStorageNotFound)
+}
+
+func TestStorageWAL_ReplayPurge(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	st := newStorageWAL(t, dir, nil)
+	assert.NoError(t, st.Save(&Task{OwnerID: optional.Some("p1"), Title: optional.Some("title 1"), Status: optional.Some(StatusTodo)}))
+	assert.NoError(t, st.Save(&Task{OwnerID: optional.Some("p1"), Title: optional.Some("title 2"), Status: optional.Some(StatusTodo), ParentID: optional.Some("1")}))
+	assert.NoError(t, st.Delete("p1", "1", optional.None[int]()))
+	n, err := st.Purge(time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.NoError(t, st.Close())
+
+	// act
+	reopened := newStorageWAL(t, dir, nil)
+
+	// assert
+	db := reopened.Snapshot()
+	assert.Len(t, db, 1)
+	assert.Equal(t, optional.Some("2"), db[0].ID)
+	assert.Equal(t, optional.None[string](), db[0].ParentID)
+}
+
+func TestStorageWAL_Compact(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	st := newStorageWAL(t, dir, &Config{SnapshotEvery: 2})
+
+	// act
+	for _, title := range []string{"title 1", "title 2", "title 3"} {
+		assert.NoError(t, st.Save(&Task{OwnerID: optional.Some("p1"), Title: optional.Some(title), Status: optional.Some(StatusTodo)}))
+	}
+	assert.NoError(t, st.Close())
+	reopened := newStorageWAL(t, dir, &Config{SnapshotEvery: 2})
+
+	// assert
+	// -> the first two tasks are in the snapshot and the third in the log
+	f, err := os.Open(filepath.Join(dir, FileSnapshot))
+	assert.NoError(t, err)
+	defer f.Close()
+	rcs, _, torn, err := readRecords(f)
+	assert.NoError(t, err)
+	assert.False(t, torn)
+	assert.Len(t, rcs, 1)
+	assert.Len(t, rcs[0].Entries, 2)
+	assert.Equal(t, 1, reopened.records)
+	// -> nothing is lost
+	assert.Equal(t, st.Snapshot(), reopened.Snapshot())
+}
+
+func TestStorageWAL_TornWrite(t *testing.T) {
+	type output struct {titles []string}
+	type testCase struct {
+		title  string
+		// tear changes the log with two whole records
+		tear   func(t *testing.T, path string)
+		output output
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "last record half written",
+			tear: func(t *testing.T, path string) {
+				info, err := os.Stat(path)
+				assert.NoError(t, err)
+				assert.NoError(t, os.Truncate(path, info.Size()-5))
+			},
+			output: output{titles: []string{"title 1"}},
+		},
+		{
+			title: "last header half written",
+			tear: func(t *testing.T, path string) {
+				appendBytes(t, path, []byte{0, 0, 1})
+			},
+			output: output{titles: []string{"title 1", "title 2"}},
+		},
+		{
+			title: "last record with a length past the end of the log",
+			tear: func(t *testing.T, path string) {
+				appendBytes(t, path, []byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0, '{'})
+			},
+			output: output{titles: []string{"title 1", "title 2"}},
+		},
+		{
+			title: "last record corrupted",
+			tear: func(t *testing.T, path string) {
+				data, err := os.ReadFile(path)
+				assert.NoError(t, err)
+				data[len(data)-2] ^= 0xff
+				assert.NoError(t, os.WriteFile(path, data, 0o644))
+			},
+			output: output{titles: []string{"title 1"}},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			dir := t.TempDir()
+			st := newStorageWAL(t, dir, nil)
+			assert.NoError(t, st.Save(&Task{OwnerID: optional.Some("p1"), Title: optional.Some("title 1"), Status: optional.Some(StatusTodo)}))
+			assert.NoError(t, st.Save(&Task{OwnerID: optional.Some("p1"), Title: optional.Some("title 2"), Status: optional.Some(StatusTodo)}))
+			assert.NoError(t, st.Close())
+			c.tear(t, filepath.Join(dir, FileWAL))
+
+			// act
+			reopened := newStorageWAL(t, dir, nil)
+			errSave := reopened.Save(&Task{OwnerID: optional.Some("p1"), Title: optional.Some("title 3"), Status: optional.Some(StatusTodo)})
+			assert.NoError(t, reopened.Close())
+			again := newStorageWAL(t, dir, nil)
+
+			// assert
+			assert.NoError(t, errSave)
+			var titles []string
+			for _, ts := range again.Snapshot() {
+				title, _ := ts.Title.Unwrap()
+				titles = append(titles, title)
+			}
+			// -> the torn record is dropped, and the log goes on after the last whole one
+			assert.Equal(t, append(c.output.titles, "title 3"), titles)
+		})
+	}
+}
+
+// appendBytes appends the data to the file.
+func appendBytes(t *testing.T, path string, data []byte) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	_, err = f.Write(data)
+	assert.NoError(t, err)
+}
+
+func TestStorageWAL_FailedWrite(t *testing.T) {
+	type output struct {err error; titles []string; reloaded []string}
+	type testCase struct {
+		title  string
+		// write fails the write of the second record
+		write  func(f *os.File, p []byte) (int, error)
+		output output
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "short write: cut from the log, the next change is logged",
+			write: func(f *os.File, p []byte) (int, error) {
+				n, _ := f.Write(p[:len(p)/2])
+				return n, errors.New("short write")
+			},
+			output: output{err: nil, titles: []string{"title 1", "title 3"}, reloaded: []string{"title 1", "title 3"}},
+		},
+
+		// failure cases
+		{
+			title: "log lost: not cut, the next change is refused",
+			write: func(f *os.File, p []byte) (int, error) {
+				f.Close()
+				return 0, errors.New("log lost")
+			},
+			output: output{err: ErrStorageInternal, titles: []string{"title 1"}, reloaded: []string{"title 1"}},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			dir := t.TempDir()
+			st := newStorageWAL(t, dir, nil)
+			assert.NoError(t, st.Save(&Task{OwnerID: optional.Some("p1"), Title: optional.Some("title 1"), Status: optional.Some(StatusTodo)}))
+
+			// act
+			st.write = c.write
+			errFailed := st.Save(&Task{OwnerID: optional.Some("p1"), Title: optional.Some("title 2"), Status: optional.Some(StatusTodo)})
+			st.write = (*os.File).Write
+			err := st.Save(&Task{OwnerID: optional.Some("p1"), Title: optional.Some("title 3"), Status: optional.Some(StatusTodo)})
+			titles := titlesOf(st.Snapshot())
+			_ = st.Close()
+			reloaded := titlesOf(newStorageWAL(t, dir, nil).Snapshot())
+
+			// assert
+			// -> the change that could not be logged is undone
+			assert.ErrorIs(t, errFailed, ErrStorageInternal)
+			assert.ErrorIs(t, err, c.output.err)
+			assert.Equal(t, c.output.titles, titles)
+			// -> what was acknowledged survives a restart
+			assert.Equal(t, c.output.reloaded, reloaded)
+		})
+	}
+}
+
+func TestStorageWAL_WithContext(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	st := newStorageWAL(t, dir, nil)
+
+	// act
+	// -> the changes through the bound storage are logged too
+	bound := st.WithContext(context.Background())
+	assert.NoError(t, bound.Save(&Task{OwnerID: optional.Some("p1"), Title: optional.Some("title 1"), Status: optional.Some(StatusTodo)}))
+	assert.NoError(t, st.Close())
+
+	// assert
+	assert.Same(t, st, bound)
+	reopened := newStorageWAL(t, dir, nil)
+	assert.Equal(t, st.Snapshot(), reopened.Snapshot())
+}
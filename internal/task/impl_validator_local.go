@@ -1,14 +1,57 @@
 package task
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidatorConfig is the configuration of the local validator.
+type ValidatorConfig struct {
+	// Workflow is the table of the allowed transitions between statuses.
+	Workflow Workflow
+	// MaxItems is the maximum amount of items of a checklist.
+	MaxItems int
+	// MaxItemText is the maximum length of the text of an item.
+	MaxItemText int
+}
+
+const (
+	// DefaultMaxItems is the default maximum amount of items of a checklist.
+	DefaultMaxItems = 50
+	// DefaultMaxItemText is the default maximum length of the text of an item.
+	DefaultMaxItemText = 200
+)
 
 // constructor
-func NewValidatorLocal() *ValidatorLocal {
-	return &ValidatorLocal{}
+// - cfg is optional (nil for the default config)
+func NewValidatorLocal(cfg *ValidatorConfig) *ValidatorLocal {
+	// default config
+	workflow := DefaultWorkflow
+	maxItems, maxItemText := DefaultMaxItems, DefaultMaxItemText
+	if cfg != nil {
+		if cfg.Workflow != nil {
+			workflow = cfg.Workflow
+		}
+		if cfg.MaxItems > 0 {
+			maxItems = cfg.MaxItems
+		}
+		if cfg.MaxItemText > 0 {
+			maxItemText = cfg.MaxItemText
+		}
+	}
+
+	return &ValidatorLocal{workflow: workflow, maxItems: maxItems, maxItemText: maxItemText}
 }
 
 // ValidatorLocal is the local implementation of the task validator.
-type ValidatorLocal struct {}
+type ValidatorLocal struct {
+	// workflow is the table of the allowed transitions
+	workflow Workflow
+	// maxItems is the maximum amount of items of a checklist
+	maxItems int
+	// maxItemText is the maximum length of the text of an item
+	maxItemText int
+}
 
 func (v *ValidatorLocal) Validate(task *Task) (err error) {
 	// check required fields (non nullable)
@@ -16,8 +59,8 @@ func (v *ValidatorLocal) Validate(task *Task) (err error) {
 		err = fmt.Errorf("%w: title", ErrValidatorFieldRequired)
 		return
 	}
-	if !task.Completed.IsSome() {
-		err = fmt.Errorf("%w: completed", ErrValidatorFieldRequired)
+	if !task.Status.IsSome() {
+		err = fmt.Errorf("%w: status", ErrValidatorFieldRequired)
 		return
 	}
 
@@ -32,6 +75,11 @@ func (v *ValidatorLocal) Validate(task *Task) (err error) {
 		err = fmt.Errorf("%w: title", ErrValidatorFieldQuality)
 		return
 	}
+	status, _ := task.Status.Unwrap()
+	if !status.Valid() {
+		err = fmt.Errorf("%w: status %q", ErrValidatorFieldQuality, status)
+		return
+	}
 
 	// check empty fields and quality values (nullable)
 	// -> safe to not check err, due to the previous check
@@ -44,5 +92,83 @@ func (v *ValidatorLocal) Validate(task *Task) (err error) {
 		}
 	}
 
+	// check parent: a task can not be its own parent
+	if task.ParentID.IsSome() {
+		parentId, _ := task.ParentID.Unwrap()
+		id, _ := task.ID.Unwrap()
+		if parentId == "" || parentId == id {
+			err = fmt.Errorf("%w: parent_id", ErrValidatorFieldQuality)
+			return
+		}
+	}
+
+	// check labels: normalized, unique and without commas
+	if len(task.Labels) > 20 {
+		err = fmt.Errorf("%w: labels", ErrValidatorFieldQuality)
+		return
+	}
+	seen := make(map[string]bool, len(task.Labels))
+	for _, label := range task.Labels {
+		if label == "" {
+			err = fmt.Errorf("%w: label", ErrValidatorFieldEmpty)
+			return
+		}
+		if len(label) > 30 || label != NormalizeLabel(label) || strings.Contains(label, ",") || seen[label] {
+			err = fmt.Errorf("%w: label %q", ErrValidatorFieldQuality, label)
+			return
+		}
+		seen[label] = true
+	}
+
+	// check checklist: items with text and unique ids
+	if len(task.Checklist) > v.maxItems {
+		err = fmt.Errorf("%w: checklist", ErrValidatorFieldQuality)
+		return
+	}
+	ids := make(map[string]bool, len(task.Checklist))
+	for _, it := range task.Checklist {
+		if strings.TrimSpace(it.Text) == "" {
+			err = fmt.Errorf("%w: item", ErrValidatorFieldEmpty)
+			return
+		}
+		if len(it.Text) > v.maxItemText || ids[it.ID] {
+			err = fmt.Errorf("%w: item %q", ErrValidatorFieldQuality, it.ID)
+			return
+		}
+		ids[it.ID] = true
+	}
+
+	// check dates: a task can not start after it is due
+	if task.StartAt.IsSome() && task.DueAt.IsSome() {
+		startAt, _ := task.StartAt.Unwrap()
+		dueAt, _ := task.DueAt.Unwrap()
+		if startAt.After(dueAt) {
+			err = fmt.Errorf("%w: start_at must be before due_at", ErrValidatorFieldQuality)
+			return
+		}
+	}
+
+	// check recurrence: a valid rule, and dates to move forward
+	if task.Recurrence.IsSome() {
+		recurrence, _ := task.Recurrence.Unwrap()
+		if _, e := ParseRule(recurrence); e != nil {
+			err = fmt.Errorf("%w: recurrence %v", ErrValidatorFieldQuality, e)
+			return
+		}
+		if !task.StartAt.IsSome() && !task.DueAt.IsSome() {
+			err = fmt.Errorf("%w: start_at or due_at of a recurring task", ErrValidatorFieldRequired)
+			return
+		}
+	}
+
+	return
+}
+
+func (v *ValidatorLocal) Transition(from Status, to Status) (err error) {
+	if !v.workflow.Allows(from, to) {
+		err = fmt.Errorf("%w: %s to %s", ErrValidatorTransition, from, to)
+		return
+	}
+
 	return
 }
\ No newline at end of file
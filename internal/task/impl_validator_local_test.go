@@ -1,7 +1,9 @@
 package task
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/LNMMusic/optional"
 
@@ -26,7 +28,28 @@ func TestValidatorLocal_Validate(t *testing.T) {
 				ID: optional.None[string](),
 				Title: optional.Some("title"),
 				Description: optional.Some("description"),
-				Completed: optional.Some(false),
+				Status: optional.Some(StatusTodo),
+			}},
+			output: output{err: nil, errMsg: ""},
+		},
+		{
+			title: "valid task with dates",
+			input: input{task: &Task{
+				ID: optional.None[string](),
+				Title: optional.Some("title"),
+				Description: optional.None[string](),
+				Status: optional.Some(StatusTodo),
+				StartAt: optional.Some(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)),
+				DueAt: optional.Some(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)),
+			}},
+			output: output{err: nil, errMsg: ""},
+		},
+		{
+			title: "valid task with labels",
+			input: input{task: &Task{
+				Title: optional.Some("title"),
+				Status: optional.Some(StatusTodo),
+				Labels: []string{"backend", "needs review"},
 			}},
 			output: output{err: nil, errMsg: ""},
 		},
@@ -36,7 +59,18 @@ func TestValidatorLocal_Validate(t *testing.T) {
 				ID: optional.None[string](),
 				Title: optional.Some("title"),
 				Description: optional.None[string](),
-				Completed: optional.Some(true),
+				Status: optional.Some(StatusDone),
+			}},
+			output: output{err: nil, errMsg: ""},
+		},
+
+		{
+			title: "valid recurring task",
+			input: input{task: &Task{
+				Title: optional.Some("title"),
+				Status: optional.Some(StatusTodo),
+				DueAt: optional.Some(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)),
+				Recurrence: optional.Some("FREQ=WEEKLY;BYDAY=MO,FR"),
 			}},
 			output: output{err: nil, errMsg: ""},
 		},
@@ -48,19 +82,19 @@ func TestValidatorLocal_Validate(t *testing.T) {
 				ID: optional.None[string](),
 				Title: optional.None[string](),
 				Description: optional.Some("description"),
-				Completed: optional.Some(false),
+				Status: optional.Some(StatusTodo),
 			}},
 			output: output{err: ErrValidatorFieldRequired, errMsg: "validator field required: title"},
 		},
 		{
-			title: "invalid task - completed required",
+			title: "invalid task - status required",
 			input: input{task: &Task{
 				ID: optional.None[string](),
 				Title: optional.Some("title"),
 				Description: optional.Some("description"),
-				Completed: optional.None[bool](),
+				Status: optional.None[Status](),
 			}},
-			output: output{err: ErrValidatorFieldRequired, errMsg: "validator field required: completed"},
+			output: output{err: ErrValidatorFieldRequired, errMsg: "validator field required: status"},
 		},
 		{
 			title: "invalid task - title empty",
@@ -68,7 +102,7 @@ func TestValidatorLocal_Validate(t *testing.T) {
 				ID: optional.None[string](),
 				Title: optional.Some(""),
 				Description: optional.Some("description"),
-				Completed: optional.Some(false),
+				Status: optional.Some(StatusTodo),
 			}},
 			output: output{err: ErrValidatorFieldEmpty, errMsg: "validator field empty: title"},
 		},
@@ -78,7 +112,7 @@ func TestValidatorLocal_Validate(t *testing.T) {
 				ID: optional.None[string](),
 				Title: optional.Some("title length is more than 50 characters so it is invalid"),
 				Description: optional.Some("description"),
-				Completed: optional.Some(false),
+				Status: optional.Some(StatusTodo),
 			}},
 			output: output{err: ErrValidatorFieldQuality, errMsg: "validator field quality: title"},
 		},
@@ -88,20 +122,202 @@ func TestValidatorLocal_Validate(t *testing.T) {
 				ID: optional.None[string](),
 				Title: optional.Some("title"),
 				Description: optional.Some("description length is more than 150 characters so it is invalid and here are some more characters to make it invalid and even more characters to make it even more invalid"),
-				Completed: optional.Some(false),
+				Status: optional.Some(StatusTodo),
 			}},
 			output: output{err: ErrValidatorFieldQuality, errMsg: "validator field quality: description"},
 		},
+		{
+			title: "invalid task - label empty",
+			input: input{task: &Task{
+				Title: optional.Some("title"),
+				Status: optional.Some(StatusTodo),
+				Labels: []string{"backend", ""},
+			}},
+			output: output{err: ErrValidatorFieldEmpty, errMsg: "validator field empty: label"},
+		},
+		{
+			title: "invalid task - label not normalized",
+			input: input{task: &Task{
+				Title: optional.Some("title"),
+				Status: optional.Some(StatusTodo),
+				Labels: []string{"Backend"},
+			}},
+			output: output{err: ErrValidatorFieldQuality, errMsg: "validator field quality: label \"Backend\""},
+		},
+		{
+			title: "invalid task - label duplicated",
+			input: input{task: &Task{
+				Title: optional.Some("title"),
+				Status: optional.Some(StatusTodo),
+				Labels: []string{"backend", "backend"},
+			}},
+			output: output{err: ErrValidatorFieldQuality, errMsg: "validator field quality: label \"backend\""},
+		},
+		{
+			title: "invalid task - unknown status",
+			input: input{task: &Task{
+				Title: optional.Some("title"),
+				Status: optional.Some(Status("finished")),
+			}},
+			output: output{err: ErrValidatorFieldQuality, errMsg: "validator field quality: status \"finished\""},
+		},
+		{
+			title: "invalid task - parent is itself",
+			input: input{task: &Task{
+				ID: optional.Some("1"),
+				Title: optional.Some("title"),
+				Status: optional.Some(StatusTodo),
+				ParentID: optional.Some("1"),
+			}},
+			output: output{err: ErrValidatorFieldQuality, errMsg: "validator field quality: parent_id"},
+		},
+		{
+			title: "invalid task - start after due",
+			input: input{task: &Task{
+				ID: optional.None[string](),
+				Title: optional.Some("title"),
+				Description: optional.None[string](),
+				Status: optional.Some(StatusTodo),
+				StartAt: optional.Some(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)),
+				DueAt: optional.Some(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)),
+			}},
+			output: output{err: ErrValidatorFieldQuality, errMsg: "validator field quality: start_at must be before due_at"},
+		},
+		{
+			title: "invalid task - recurrence quality",
+			input: input{task: &Task{
+				Title: optional.Some("title"),
+				Status: optional.Some(StatusTodo),
+				DueAt: optional.Some(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)),
+				Recurrence: optional.Some("FREQ=YEARLY"),
+			}},
+			output: output{err: ErrValidatorFieldQuality, errMsg: "validator field quality: recurrence rule invalid: unsupported frequency \"YEARLY\""},
+		},
+		{
+			title: "valid task with checklist",
+			input: input{task: &Task{
+				Title: optional.Some("title"),
+				Status: optional.Some(StatusTodo),
+				Checklist: []Item{{ID: "1", Text: "buy milk"}, {ID: "2", Text: "buy bread", Checked: true}},
+			}},
+			output: output{err: nil, errMsg: ""},
+		},
+		{
+			title: "invalid task - empty item",
+			input: input{task: &Task{
+				Title: optional.Some("title"),
+				Status: optional.Some(StatusTodo),
+				Checklist: []Item{{ID: "1", Text: "  "}},
+			}},
+			output: output{err: ErrValidatorFieldEmpty, errMsg: "validator field empty: item"},
+		},
+		{
+			title: "invalid task - item text too long",
+			input: input{task: &Task{
+				Title: optional.Some("title"),
+				Status: optional.Some(StatusTodo),
+				Checklist: []Item{{ID: "1", Text: strings.Repeat("a", DefaultMaxItemText+1)}},
+			}},
+			output: output{err: ErrValidatorFieldQuality, errMsg: "validator field quality: item \"1\""},
+		},
+		{
+			title: "invalid task - duplicated item",
+			input: input{task: &Task{
+				Title: optional.Some("title"),
+				Status: optional.Some(StatusTodo),
+				Checklist: []Item{{ID: "1", Text: "buy milk"}, {ID: "1", Text: "buy bread"}},
+			}},
+			output: output{err: ErrValidatorFieldQuality, errMsg: "validator field quality: item \"1\""},
+		},
+		{
+			title: "invalid task - too many items",
+			input: input{task: &Task{
+				Title: optional.Some("title"),
+				Status: optional.Some(StatusTodo),
+				Checklist: make([]Item, DefaultMaxItems+1),
+			}},
+			output: output{err: ErrValidatorFieldQuality, errMsg: "validator field quality: checklist"},
+		},
+		{
+			title: "invalid task - recurring without dates",
+			input: input{task: &Task{
+				Title: optional.Some("title"),
+				Status: optional.Some(StatusTodo),
+				Recurrence: optional.Some("FREQ=DAILY"),
+			}},
+			output: output{err: ErrValidatorFieldRequired, errMsg: "validator field required: start_at or due_at of a recurring task"},
+		},
 	}
 
 	for _, c := range cases {
 		t.Run(c.title, func(t *testing.T) {
 			// arrange
-			vl := NewValidatorLocal()
+			vl := NewValidatorLocal(nil)
 
 			// act
 			err := vl.Validate(c.input.task)
 
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestValidatorLocal_Transition(t *testing.T) {
+	type input struct {from Status; to Status}
+	type output struct {err error; errMsg string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+		// cfg is the validator config (nil for the default one)
+		cfg    *ValidatorConfig
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "allowed transition",
+			input: input{from: StatusTodo, to: StatusInProgress},
+			output: output{err: nil, errMsg: ""},
+		},
+		{
+			title: "same status",
+			input: input{from: StatusArchived, to: StatusArchived},
+			output: output{err: nil, errMsg: ""},
+		},
+		{
+			title: "allowed transition of a custom workflow",
+			input: input{from: StatusArchived, to: StatusDone},
+			output: output{err: nil, errMsg: ""},
+			cfg: &ValidatorConfig{Workflow: Workflow{StatusArchived: {StatusDone}}},
+		},
+
+		// failure cases
+		{
+			title: "transition not allowed",
+			input: input{from: StatusArchived, to: StatusDone},
+			output: output{err: ErrValidatorTransition, errMsg: "validator transition not allowed: archived to done"},
+		},
+		{
+			title: "transition not allowed by a custom workflow",
+			input: input{from: StatusTodo, to: StatusDone},
+			output: output{err: ErrValidatorTransition, errMsg: "validator transition not allowed: todo to done"},
+			cfg: &ValidatorConfig{Workflow: Workflow{StatusTodo: {StatusInProgress}}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			vl := NewValidatorLocal(c.cfg)
+
+			// act
+			err := vl.Transition(c.input.from, c.input.to)
+
 			// assert
 			assert.ErrorIs(t, err, c.output.err)
 			if err != nil {
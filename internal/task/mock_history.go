@@ -0,0 +1,36 @@
+package task
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// constructor
+func NewHistoryMock() *HistoryMock {
+	return &HistoryMock{}
+}
+
+// HistoryMock is a mock implementation of the history of the tasks.
+type HistoryMock struct {
+	mock.Mock
+}
+
+func (m *HistoryMock) Record(e *Entry) (err error) {
+	args := m.Called(e)
+	err = args.Error(0)
+	return
+}
+
+// WithContext returns the mock itself (the context is not recorded).
+func (m *HistoryMock) WithContext(ctx context.Context) (hs History) {
+	hs = m
+	return
+}
+
+func (m *HistoryMock) List(taskId string) (es []*Entry, err error) {
+	args := m.Called(taskId)
+	es = args.Get(0).([]*Entry)
+	err = args.Error(1)
+	return
+}
@@ -1,6 +1,13 @@
 package task
 
-import "github.com/stretchr/testify/mock"
+import (
+	"context"
+	"time"
+
+	"github.com/LNMMusic/optional"
+
+	"github.com/stretchr/testify/mock"
+)
 
 // constructor
 func NewStorageMock() *StorageMock {
@@ -10,23 +17,199 @@ func NewStorageMock() *StorageMock {
 }
 
 // StorageMock is a mock implementation of the task storage.
+// - once recording, the writes that succeed hand Writes to the recorder (with the owner of a saved task, the profile of the rest)
 type StorageMock struct {
 	mock.Mock
 	SetTask func(t *Task)
+	Writes  []*write
+
+	rec recorder
+	ctx context.Context
+}
+
+// record hands Writes to the recorder, if the write succeeded.
+func (m *StorageMock) record(profileId string, err error) error {
+	if err != nil || m.rec == nil || len(m.Writes) == 0 {
+		return err
+	}
+	return m.rec(m.ctx, nil, profileId, m.Writes)
+}
+
+// recording sets the recorder of the writes, and returns the mock itself.
+func (m *StorageMock) recording(rc recorder) (st Storage) {
+	m.rec = rc
+	st = m
+	return
 }
 
-func (m *StorageMock) Get(id string) (ts *Task, err error) {
-	args := m.Called(id)
+func (m *StorageMock) Get(profileId string, id string) (ts *Task, err error) {
+	args := m.Called(profileId, id)
 	ts = args.Get(0).(*Task)
 	err = args.Error(1)
 	return
 }
 
+func (m *StorageMock) List(profileId string, query *Query) (pg *Page, err error) {
+	args := m.Called(profileId, query)
+	pg = args.Get(0).(*Page)
+	err = args.Error(1)
+	return
+}
+
 func (m *StorageMock) Save(t *Task) (err error) {
 	args := m.Called(t)
 
 	m.SetTask(t)
 
+	ownerId, _ := t.OwnerID.Unwrap()
+	err = m.record(ownerId, args.Error(0))
+	return
+}
+
+
+func (m *StorageMock) Update(profileId string, t *Task) (err error) {
+	args := m.Called(profileId, t)
+	err = m.record(profileId, args.Error(0))
+	return
+}
+
+func (m *StorageMock) Delete(profileId string, id string, version optional.Option[int]) (err error) {
+	args := m.Called(profileId, id, version)
+	err = args.Error(0)
+	return
+}
+
+func (m *StorageMock) Restore(profileId string, id string, version optional.Option[int]) (err error) {
+	args := m.Called(profileId, id, version)
+	err = args.Error(0)
+	return
+}
+
+func (m *StorageMock) Purge(before time.Time) (n int, err error) {
+	args := m.Called(before)
+	n = args.Int(0)
+	err = args.Error(1)
+	return
+}
+
+func (m *StorageMock) AddLabel(profileId string, id string, label string, version optional.Option[int]) (err error) {
+	args := m.Called(profileId, id, label, version)
+	err = m.record(profileId, args.Error(0))
+	return
+}
+
+func (m *StorageMock) RemoveLabel(profileId string, id string, label string, version optional.Option[int]) (err error) {
+	args := m.Called(profileId, id, label, version)
+	err = m.record(profileId, args.Error(0))
+	return
+}
+
+func (m *StorageMock) Labels(profileId string) (ls []*Label, err error) {
+	args := m.Called(profileId)
+	ls = args.Get(0).([]*Label)
+	err = args.Error(1)
+	return
+}
+
+func (m *StorageMock) Tree(profileId string, id string) (nd *Node, err error) {
+	args := m.Called(profileId, id)
+	nd = args.Get(0).(*Node)
+	err = args.Error(1)
+	return
+}
+
+func (m *StorageMock) AddDependency(profileId string, id string, blockerId string) (err error) {
+	args := m.Called(profileId, id, blockerId)
+	err = args.Error(0)
+	return
+}
+
+func (m *StorageMock) RemoveDependency(profileId string, id string, blockerId string) (err error) {
+	args := m.Called(profileId, id, blockerId)
+	err = args.Error(0)
+	return
+}
+
+func (m *StorageMock) Order(profileId string, ids []string) (ts []*Task, err error) {
+	args := m.Called(profileId, ids)
+	ts = args.Get(0).([]*Task)
+	err = args.Error(1)
+	return
+}
+
+func (m *StorageMock) Grant(profileId string, id string, grant *Grant) (err error) {
+	args := m.Called(profileId, id, grant)
+	err = args.Error(0)
+	return
+}
+
+func (m *StorageMock) Revoke(profileId string, id string, granteeId string) (err error) {
+	args := m.Called(profileId, id, granteeId)
+	err = args.Error(0)
+	return
+}
+
+func (m *StorageMock) Grants(profileId string, id string) (gs []*Grant, err error) {
+	args := m.Called(profileId, id)
+	gs = args.Get(0).([]*Grant)
+	err = args.Error(1)
+	return
+}
+
+func (m *StorageMock) Shared(profileId string, query *Query) (pg *Page, err error) {
+	args := m.Called(profileId, query)
+	pg = args.Get(0).(*Page)
+	err = args.Error(1)
+	return
+}
+
+func (m *StorageMock) Batch(profileId string, ops []*Op, mode Mode) (rs []*Result, err error) {
+	args := m.Called(profileId, ops, mode)
+	rs = args.Get(0).([]*Result)
+	err = m.record(profileId, args.Error(1))
+	return
+}
+
+func (m *StorageMock) Move(profileId string, id string, afterId string, beforeId string, version optional.Option[int]) (err error) {
+	args := m.Called(profileId, id, afterId, beforeId, version)
 	err = args.Error(0)
 	return
-}
\ No newline at end of file
+}
+
+func (m *StorageMock) Rebalance() (n int, err error) {
+	args := m.Called()
+	n = args.Int(0)
+	err = args.Error(1)
+	return
+}
+
+func (m *StorageMock) AddItem(profileId string, id string, item *Item, version optional.Option[int]) (err error) {
+	args := m.Called(profileId, id, item, version)
+	err = m.record(profileId, args.Error(0))
+	return
+}
+
+func (m *StorageMock) CheckItem(profileId string, id string, itemId string, checked bool, version optional.Option[int]) (err error) {
+	args := m.Called(profileId, id, itemId, checked, version)
+	err = m.record(profileId, args.Error(0))
+	return
+}
+
+func (m *StorageMock) MoveItem(profileId string, id string, itemId string, position int, version optional.Option[int]) (err error) {
+	args := m.Called(profileId, id, itemId, position, version)
+	err = m.record(profileId, args.Error(0))
+	return
+}
+
+func (m *StorageMock) RemoveItem(profileId string, id string, itemId string, version optional.Option[int]) (err error) {
+	args := m.Called(profileId, id, itemId, version)
+	err = m.record(profileId, args.Error(0))
+	return
+}
+
+// WithContext returns the mock itself (the context is kept for the recorder).
+func (m *StorageMock) WithContext(ctx context.Context) (st Storage) {
+	m.ctx = ctx
+	st = m
+	return
+}
@@ -15,4 +15,9 @@ type ValidatorMock struct {
 func (v *ValidatorMock) Validate(task *Task) (err error) {
 	args := v.Called(task)
 	return args.Error(0)
+}
+
+func (v *ValidatorMock) Transition(from Status, to Status) (err error) {
+	args := v.Called(from, to)
+	return args.Error(0)
 }
\ No newline at end of file
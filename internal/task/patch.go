@@ -0,0 +1,58 @@
+package task
+
+import (
+	"time"
+
+	"github.com/LNMMusic/optional"
+)
+
+// Patch is a partial update of a task. Each field tells apart:
+// - None: the field was left out (it is kept as it is)
+// - Some(None): the field was sent as null (it is cleared)
+// - Some(Some(v)): the field was sent with a value (it is set)
+type Patch struct {
+	Title 		optional.Option[optional.Option[string]]
+	Description optional.Option[optional.Option[string]]
+	Status 		optional.Option[optional.Option[Status]]
+	ParentID 	optional.Option[optional.Option[string]]
+	StartAt 	optional.Option[optional.Option[time.Time]]
+	DueAt 		optional.Option[optional.Option[time.Time]]
+	// Labels replaces the labels of the task (null clears them)
+	Labels 		optional.Option[optional.Option[[]string]]
+	// Recurrence sets the recurrence rule of the task (null stops it from repeating)
+	Recurrence 	optional.Option[optional.Option[string]]
+	// ProjectID moves the task to another project (null takes it out of its project)
+	ProjectID 	optional.Option[optional.Option[string]]
+}
+
+// Apply applies the patch over the given task.
+func (p *Patch) Apply(task *Task) {
+	if p.Title.IsSome() {
+		task.Title, _ = p.Title.Unwrap()
+	}
+	if p.Description.IsSome() {
+		task.Description, _ = p.Description.Unwrap()
+	}
+	if p.Status.IsSome() {
+		task.Status, _ = p.Status.Unwrap()
+	}
+	if p.ParentID.IsSome() {
+		task.ParentID, _ = p.ParentID.Unwrap()
+	}
+	if p.StartAt.IsSome() {
+		task.StartAt, _ = p.StartAt.Unwrap()
+	}
+	if p.DueAt.IsSome() {
+		task.DueAt, _ = p.DueAt.Unwrap()
+	}
+	if p.Labels.IsSome() {
+		labels, _ := p.Labels.Unwrap()
+		task.Labels, _ = labels.Unwrap()
+	}
+	if p.Recurrence.IsSome() {
+		task.Recurrence, _ = p.Recurrence.Unwrap()
+	}
+	if p.ProjectID.IsSome() {
+		task.ProjectID, _ = p.ProjectID.Unwrap()
+	}
+}
@@ -0,0 +1,100 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LNMMusic/optional"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests
+func TestPatch_Apply(t *testing.T) {
+	type input struct {patch *Patch; task *Task}
+	type output struct {task *Task}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+	}
+
+	cases := []testCase{
+		{
+			title: "absent fields are kept",
+			input: input{
+				patch: &Patch{},
+				task: &Task{ID: optional.Some("1"), Title: optional.Some("title"), Status: optional.Some(StatusTodo)},
+			},
+			output: output{
+				task: &Task{ID: optional.Some("1"), Title: optional.Some("title"), Status: optional.Some(StatusTodo)},
+			},
+		},
+		{
+			title: "null fields are cleared",
+			input: input{
+				patch: &Patch{Description: optional.Some(optional.None[string]())},
+				task: &Task{ID: optional.Some("1"), Title: optional.Some("title"), Description: optional.Some("description")},
+			},
+			output: output{
+				task: &Task{ID: optional.Some("1"), Title: optional.Some("title"), Description: optional.None[string]()},
+			},
+		},
+		{
+			title: "dates are cleared and set",
+			input: input{
+				patch: &Patch{
+					StartAt: optional.Some(optional.None[time.Time]()),
+					DueAt: optional.Some(optional.Some(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC))),
+				},
+				task: &Task{ID: optional.Some("1"), StartAt: optional.Some(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))},
+			},
+			output: output{
+				task: &Task{ID: optional.Some("1"), StartAt: optional.None[time.Time](), DueAt: optional.Some(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC))},
+			},
+		},
+		{
+			title: "valued fields are set",
+			input: input{
+				patch: &Patch{
+					Title: optional.Some(optional.Some("new title")),
+					Status: optional.Some(optional.Some(StatusDone)),
+				},
+				task: &Task{ID: optional.Some("1"), Title: optional.Some("title"), Status: optional.Some(StatusTodo)},
+			},
+			output: output{
+				task: &Task{ID: optional.Some("1"), Title: optional.Some("new title"), Status: optional.Some(StatusDone)},
+			},
+		},
+		{
+			title: "recurrence is cleared",
+			input: input{
+				patch: &Patch{Recurrence: optional.Some(optional.None[string]())},
+				task: &Task{ID: optional.Some("1"), Recurrence: optional.Some("FREQ=DAILY"), SeriesID: optional.Some("1")},
+			},
+			output: output{
+				task: &Task{ID: optional.Some("1"), Recurrence: optional.None[string](), SeriesID: optional.Some("1")},
+			},
+		},
+		{
+			title: "task is moved to another project",
+			input: input{
+				patch: &Patch{ProjectID: optional.Some(optional.Some("pj2"))},
+				task: &Task{ID: optional.Some("1"), ProjectID: optional.Some("pj1")},
+			},
+			output: output{
+				task: &Task{ID: optional.Some("1"), ProjectID: optional.Some("pj2")},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// act
+			c.input.patch.Apply(c.input.task)
+
+			// assert
+			assert.Equal(t, c.output.task, c.input.task)
+		})
+	}
+}
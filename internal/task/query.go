@@ -0,0 +1,94 @@
+package task
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// cursor is the decoded representation of a page cursor.
+// - it points to the last task of the previous page
+type cursor struct {
+	// ID is the id of the task
+	ID string `json:"id"`
+	// Sort is the order of the page (the cursor is only valid for the same order)
+	Sort Sort `json:"sort"`
+	// Key is the value of the sort field of the task
+	Key any `json:"key,omitempty"`
+}
+
+// encodeCursor returns the opaque token that points after the given task.
+func encodeCursor(ts *Task, s Sort) (token string) {
+	c := cursor{Sort: s}
+	c.ID, _ = ts.ID.Unwrap()
+	if s.Field != "" {
+		c.Key = value(ts, s.Field)
+	}
+
+	data, _ := json.Marshal(c)
+	token = base64.RawURLEncoding.EncodeToString(data)
+	return
+}
+
+// decodeCursor returns the cursor of the given opaque token.
+// - an empty token is the cursor of the first page (nil)
+func decodeCursor(token string, s Sort) (c *cursor, err error) {
+	if token == "" {
+		return
+	}
+
+	var data []byte
+	data, err = base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		err = fmt.Errorf("%w: cursor", ErrStorageInvalidQuery)
+		return
+	}
+
+	c = &cursor{}
+	err = json.Unmarshal(data, c)
+	if err != nil || c.ID == "" || c.Sort != s {
+		c, err = nil, fmt.Errorf("%w: cursor", ErrStorageInvalidQuery)
+		return
+	}
+
+	// key: same kind as the sort field (times are encoded as RFC 3339 strings)
+	if s.Field != "" && c.Key != nil {
+		if raw, ok := c.Key.(string); ok && fieldSpecs[s.Field].kind == kindTime {
+			c.Key, err = time.Parse(time.RFC3339Nano, raw)
+			if err != nil {
+				c, err = nil, fmt.Errorf("%w: cursor", ErrStorageInvalidQuery)
+				return
+			}
+		}
+		if checkValue(s.Field, c.Key) != nil {
+			c, err = nil, fmt.Errorf("%w: cursor", ErrStorageInvalidQuery)
+			return
+		}
+	}
+
+	return
+}
+
+// checkQuery checks the query and returns the size of the requested page.
+func checkQuery(query *Query) (size int, err error) {
+	size = query.Size
+	if size == 0 {
+		size = DefaultPageSize
+	}
+	if size < 0 || size > MaxPageSize {
+		err = fmt.Errorf("%w: size", ErrStorageInvalidQuery)
+		return
+	}
+
+	err = checkFilter(query.Filter)
+	if err == nil {
+		err = checkSort(query.Sort)
+	}
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrStorageInvalidQuery, err)
+		return
+	}
+
+	return
+}
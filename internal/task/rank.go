@@ -0,0 +1,139 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Ranks are the keys that order the tasks of a profile by hand (fractional indexing).
+// - a rank is a string of rankDigits compared in lexicographic order, that never ends with the lowest digit,
+// so there is always room for another rank between two of them (or at both ends) without renumbering the rest
+// - inserting over and over at the same place makes the ranks longer, until they are rebalanced (see MaxRankLength)
+const rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// MaxRankLength is the length of a rank from which the ranks of its profile are rebalanced.
+const MaxRankLength = 12
+
+// checkRank checks the given rank is made of rank digits and does not end with the lowest one.
+func checkRank(rank string) (err error) {
+	if rank == "" || rank[len(rank)-1] == rankDigits[0] {
+		err = fmt.Errorf("%w: rank %q", ErrStorageInvalid, rank)
+		return
+	}
+	for i := 0; i < len(rank); i++ {
+		if strings.IndexByte(rankDigits, rank[i]) < 0 {
+			err = fmt.Errorf("%w: rank %q", ErrStorageInvalid, rank)
+			return
+		}
+	}
+	return
+}
+
+// rankBetween returns a rank between the given ones (lower < rank < upper).
+// - an empty lower has no lower bound, and an empty upper has no upper bound
+func rankBetween(lower string, upper string) (rank string, err error) {
+	for _, r := range []string{lower, upper} {
+		if r == "" {
+			continue
+		}
+		err = checkRank(r)
+		if err != nil {
+			return
+		}
+	}
+	if lower != "" && upper != "" && lower >= upper {
+		err = fmt.Errorf("%w: rank %q not before %q", ErrStorageInvalid, lower, upper)
+		return
+	}
+
+	rank = midpoint(lower, upper)
+	return
+}
+
+// midpoint returns the shortest rank between the given ones (an empty upper has no upper bound).
+// - lower is padded with the lowest digit
+func midpoint(lower string, upper string) string {
+	// common prefix
+	if upper != "" {
+		n := 0
+		for n < len(upper) && rankDigit(lower, n) == upper[n] {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(lower) {
+				rest = lower[n:]
+			}
+			return upper[:n] + midpoint(rest, upper[n:])
+		}
+	}
+
+	// first digits
+	dl := strings.IndexByte(rankDigits, rankDigit(lower, 0))
+	du := len(rankDigits)
+	if upper != "" {
+		du = strings.IndexByte(rankDigits, upper[0])
+	}
+	if du-dl > 1 {
+		return string(rankDigits[(dl+du)/2])
+	}
+
+	// consecutive digits
+	// -> the first digit of upper alone is between them, if upper goes on
+	if len(upper) > 1 {
+		return upper[:1]
+	}
+	rest := ""
+	if len(lower) > 1 {
+		rest = lower[1:]
+	}
+	return string(rankDigits[dl]) + midpoint(rest, "")
+}
+
+// rankDigit returns the digit of the rank at the given position (the lowest digit past its end).
+func rankDigit(rank string, i int) byte {
+	if i < len(rank) {
+		return rank[i]
+	}
+	return rankDigits[0]
+}
+
+// rankAfter returns a short rank after the given one (the first rank if it is empty), to append a task to the end.
+// - it increases the first digit that is not the highest one, dropping the rest
+func rankAfter(rank string) string {
+	if rank == "" {
+		return midpoint("", "")
+	}
+	for i := 0; i < len(rank); i++ {
+		d := strings.IndexByte(rankDigits, rank[i])
+		if d < len(rankDigits)-1 {
+			return rank[:i] + string(rankDigits[d+1])
+		}
+	}
+	return rank + midpoint("", "")
+}
+
+// rankSpread returns n ranks evenly spread, in order, as short as possible.
+func rankSpread(n int) (ranks []string) {
+	base := len(rankDigits)
+
+	// width: enough digits for n ranks with room between them
+	width, space := 1, base
+	for space <= 2*(n+1) {
+		width++
+		space *= base
+	}
+
+	ranks = make([]string, n)
+	step := space / (n + 1)
+	for i := range ranks {
+		v := step * (i + 1)
+		digits := make([]byte, width)
+		for j := width - 1; j >= 0; j-- {
+			digits[j] = rankDigits[v%base]
+			v /= base
+		}
+		ranks[i] = strings.TrimRight(string(digits), rankDigits[:1])
+	}
+	return
+}
@@ -0,0 +1,185 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests
+func TestRankBetween(t *testing.T) {
+	type input struct {lower string; upper string}
+	type output struct {rank string; err error; errMsg string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "first rank",
+			input: input{lower: "", upper: ""},
+			output: output{rank: "i"},
+		},
+		{
+			title: "rank at the end",
+			input: input{lower: "i", upper: ""},
+			output: output{rank: "r"},
+		},
+		{
+			title: "rank at the start",
+			input: input{lower: "", upper: "i"},
+			output: output{rank: "9"},
+		},
+		{
+			title: "rank between far ranks",
+			input: input{lower: "a", upper: "c"},
+			output: output{rank: "b"},
+		},
+		{
+			title: "rank between consecutive ranks",
+			input: input{lower: "a", upper: "b"},
+			output: output{rank: "ai"},
+		},
+		{
+			title: "rank between a rank and one that goes on",
+			input: input{lower: "a", upper: "b5"},
+			output: output{rank: "b"},
+		},
+		{
+			title: "rank between ranks with a common prefix",
+			input: input{lower: "a", upper: "a1"},
+			output: output{rank: "a0i"},
+		},
+		{
+			title: "rank before the lowest rank of one digit",
+			input: input{lower: "", upper: "1"},
+			output: output{rank: "0i"},
+		},
+
+		// failure cases
+		{
+			title: "ranks out of order",
+			input: input{lower: "b", upper: "a"},
+			output: output{err: ErrStorageInvalid, errMsg: "storage invalid task: rank \"b\" not before \"a\""},
+		},
+		{
+			title: "same ranks",
+			input: input{lower: "b", upper: "b"},
+			output: output{err: ErrStorageInvalid, errMsg: "storage invalid task: rank \"b\" not before \"b\""},
+		},
+		{
+			title: "rank with invalid digits",
+			input: input{lower: "A", upper: ""},
+			output: output{err: ErrStorageInvalid, errMsg: "storage invalid task: rank \"A\""},
+		},
+		{
+			title: "rank ending with the lowest digit",
+			input: input{lower: "", upper: "a0"},
+			output: output{err: ErrStorageInvalid, errMsg: "storage invalid task: rank \"a0\""},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// act
+			rank, err := rankBetween(c.input.lower, c.input.upper)
+
+			// assert
+			assert.Equal(t, c.output.rank, rank)
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestRankBetween_Repeated(t *testing.T) {
+	// arrange
+	lower, upper := "a", "b"
+
+	// act
+	// -> inserting over and over right after the same rank
+	for i := 0; i < 100; i++ {
+		rank, err := rankBetween(lower, upper)
+
+		// assert
+		assert.NoError(t, err)
+		assert.NoError(t, checkRank(rank))
+		assert.Less(t, lower, rank)
+		assert.Less(t, rank, upper)
+		upper = rank
+	}
+}
+
+func TestRankAfter(t *testing.T) {
+	type input struct {rank string}
+	type output struct {rank string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+	}
+
+	cases := []testCase{
+		{title: "first rank", input: input{rank: ""}, output: output{rank: "i"}},
+		{title: "next digit", input: input{rank: "i"}, output: output{rank: "j"}},
+		{title: "next digit of the first that is not the highest", input: input{rank: "z3b"}, output: output{rank: "z4"}},
+		{title: "highest digits", input: input{rank: "zz"}, output: output{rank: "zzi"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// act
+			rank := rankAfter(c.input.rank)
+
+			// assert
+			assert.Equal(t, c.output.rank, rank)
+			assert.Less(t, c.input.rank, rank)
+		})
+	}
+}
+
+func TestRankSpread(t *testing.T) {
+	type input struct {n int}
+	type output struct {ranks []string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+	}
+
+	cases := []testCase{
+		{title: "no ranks", input: input{n: 0}, output: output{ranks: []string{}}},
+		{title: "one rank", input: input{n: 1}, output: output{ranks: []string{"i"}}},
+		{title: "few ranks", input: input{n: 3}, output: output{ranks: []string{"9", "i", "r"}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// act
+			ranks := rankSpread(c.input.n)
+
+			// assert
+			assert.Equal(t, c.output.ranks, ranks)
+		})
+	}
+
+	t.Run("many ranks are short and in order", func(t *testing.T) {
+		// act
+		ranks := rankSpread(1000)
+
+		// assert
+		assert.Len(t, ranks, 1000)
+		for i, rank := range ranks {
+			assert.NoError(t, checkRank(rank))
+			assert.LessOrEqual(t, len(rank), 3)
+			if i > 0 {
+				assert.Less(t, ranks[i-1], rank)
+			}
+		}
+	})
+}
@@ -0,0 +1,316 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LNMMusic/optional"
+)
+
+// Frequency is how often a recurring task repeats.
+type Frequency string
+
+const (
+	FrequencyDaily 	 Frequency = "DAILY"
+	FrequencyWeekly  Frequency = "WEEKLY"
+	FrequencyMonthly Frequency = "MONTHLY"
+)
+
+// Rule is a recurrence rule: a practical subset of the iCalendar RRULE (RFC 5545).
+// - FREQ (required): DAILY, WEEKLY or MONTHLY
+// - INTERVAL: the amount of periods between occurrences (1 by default)
+// - BYDAY: the weekdays of a weekly rule (MO, TU, WE, TH, FR, SA, SU)
+// - BYMONTHDAY: the days of a monthly rule (1 to 31, months without the day are skipped)
+// - COUNT or UNTIL: the amount of occurrences of the series, or the last time an occurrence can be at
+type Rule struct {
+	Freq 	   Frequency
+	Interval   int
+	// ByDay is sorted from monday to sunday (empty for the weekday of the task)
+	ByDay 	   []time.Weekday
+	// ByMonthDay is sorted (empty for the day of the month of the task)
+	ByMonthDay []int
+	// Count is 0 for a series without a limit of occurrences
+	Count 	   int
+	// Until is the zero time for a series without an end
+	Until 	   time.Time
+}
+
+var (
+	// ErrRuleInvalid is returned when the recurrence rule can not be parsed.
+	ErrRuleInvalid = errors.New("rule invalid")
+)
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// ParseRule parses the given recurrence rule, e.g. "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;COUNT=10".
+// - the "RRULE:" prefix is optional and the rule is case insensitive
+func ParseRule(s string) (r *Rule, err error) {
+	s = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "RRULE:")
+
+	r = &Rule{Interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			err = fmt.Errorf("%w: malformed part %q", ErrRuleInvalid, part)
+			return
+		}
+		if seen[key] {
+			err = fmt.Errorf("%w: duplicated part %q", ErrRuleInvalid, key)
+			return
+		}
+		seen[key] = true
+
+		switch key {
+		case "FREQ":
+			r.Freq = Frequency(value)
+			if r.Freq != FrequencyDaily && r.Freq != FrequencyWeekly && r.Freq != FrequencyMonthly {
+				err = fmt.Errorf("%w: unsupported frequency %q", ErrRuleInvalid, value)
+				return
+			}
+		case "INTERVAL":
+			r.Interval, err = positive(key, value)
+		case "COUNT":
+			r.Count, err = positive(key, value)
+		case "UNTIL":
+			r.Until, err = time.Parse("20060102T150405Z", value)
+			if err != nil {
+				// -> a date includes the whole day
+				r.Until, err = time.Parse("20060102", value)
+				r.Until = r.Until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+			}
+			if err != nil {
+				err = fmt.Errorf("%w: malformed until %q", ErrRuleInvalid, value)
+			}
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := weekdays[day]
+				if !ok {
+					err = fmt.Errorf("%w: unsupported weekday %q", ErrRuleInvalid, day)
+					return
+				}
+				r.ByDay = append(r.ByDay, weekday)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				var d int
+				d, err = strconv.Atoi(day)
+				if err != nil || d < 1 || d > 31 {
+					err = fmt.Errorf("%w: unsupported month day %q", ErrRuleInvalid, day)
+					return
+				}
+				r.ByMonthDay = append(r.ByMonthDay, d)
+			}
+		default:
+			err = fmt.Errorf("%w: unsupported part %q", ErrRuleInvalid, key)
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	// check the combination of parts
+	if r.Freq == "" {
+		err = fmt.Errorf("%w: FREQ is required", ErrRuleInvalid)
+		return
+	}
+	if seen["COUNT"] && seen["UNTIL"] {
+		err = fmt.Errorf("%w: COUNT and UNTIL are exclusive", ErrRuleInvalid)
+		return
+	}
+	if seen["BYDAY"] && r.Freq != FrequencyWeekly {
+		err = fmt.Errorf("%w: BYDAY requires a weekly frequency", ErrRuleInvalid)
+		return
+	}
+	if seen["BYMONTHDAY"] && r.Freq != FrequencyMonthly {
+		err = fmt.Errorf("%w: BYMONTHDAY requires a monthly frequency", ErrRuleInvalid)
+		return
+	}
+
+	// normalize
+	sort.Slice(r.ByDay, func(i, j int) bool { return (r.ByDay[i]+6)%7 < (r.ByDay[j]+6)%7 })
+	sort.Ints(r.ByMonthDay)
+	return
+}
+
+// positive parses the value of the given part as a positive integer.
+func positive(key string, value string) (n int, err error) {
+	n, err = strconv.Atoi(value)
+	if err != nil || n < 1 {
+		err = fmt.Errorf("%w: %s must be a positive integer", ErrRuleInvalid, key)
+		return
+	}
+	return
+}
+
+// Next returns the time of the occurrence that follows the given one, keeping its time of day.
+// - occurrence is the position in the series of the given one (1 for the first)
+// - ok is false when the series is over (by COUNT or UNTIL)
+func (r *Rule) Next(after time.Time, occurrence int) (next time.Time, ok bool) {
+	if r.Count > 0 && occurrence >= r.Count {
+		return
+	}
+
+	switch r.Freq {
+	case FrequencyDaily:
+		next = after.AddDate(0, 0, r.Interval)
+	case FrequencyWeekly:
+		next = r.nextWeekly(after)
+	case FrequencyMonthly:
+		next, ok = r.nextMonthly(after)
+		if !ok {
+			return
+		}
+	}
+
+	if !r.Until.IsZero() && next.After(r.Until) {
+		ok = false
+		return
+	}
+	ok = true
+	return
+}
+
+// nextWeekly returns the first day after the given one that is one of the weekdays of the rule,
+// in a week that is a multiple of the interval away (weeks start on monday).
+func (r *Rule) nextWeekly(after time.Time) (next time.Time) {
+	if len(r.ByDay) == 0 {
+		return after.AddDate(0, 0, 7*r.Interval)
+	}
+
+	// days from the monday of the week of the given day
+	offset := (int(after.Weekday()) + 6) % 7
+	for day := 1; ; day++ {
+		next = after.AddDate(0, 0, day)
+		if (offset+day)/7%r.Interval != 0 {
+			continue
+		}
+		for _, weekday := range r.ByDay {
+			if next.Weekday() == weekday {
+				return
+			}
+		}
+	}
+}
+
+// nextMonthly returns the first day after the given one that is one of the days of the month of the rule,
+// in a month that is a multiple of the interval away (months without the day are skipped).
+// - ok is false when none of the next 100 periods has the day (e.g. the 30th of every february)
+func (r *Rule) nextMonthly(after time.Time) (next time.Time, ok bool) {
+	days := r.ByMonthDay
+	if len(days) == 0 {
+		days = []int{after.Day()}
+	}
+
+	year, month, _ := after.Date()
+	hour, minute, sec := after.Clock()
+	for period := 0; period <= 100; period++ {
+		for _, day := range days {
+			next = time.Date(year, month+time.Month(period*r.Interval), day, hour, minute, sec, after.Nanosecond(), after.Location())
+			// the day overflowed to the next month
+			if next.Day() != day {
+				continue
+			}
+			if next.After(after) {
+				ok = true
+				return
+			}
+		}
+	}
+	return
+}
+
+// nextOccurrence returns the task that follows the given recurring task in its series, with its dates moved forward.
+// - ok is false when the task is not recurring or the series is over
+// - the id and the timestamps are left to be set by the storage
+// - the checklist starts over, with every item unchecked
+func nextOccurrence(task *Task) (next *Task, ok bool, err error) {
+	recurrence, e := task.Recurrence.Unwrap()
+	if e != nil {
+		return
+	}
+	var r *Rule
+	r, err = ParseRule(recurrence)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrStorageInvalid, err)
+		return
+	}
+
+	// the dates are moved from the due time (or the start time)
+	anchor, e := task.DueAt.Unwrap()
+	if e != nil {
+		anchor, e = task.StartAt.Unwrap()
+		if e != nil {
+			return
+		}
+	}
+	occurrence, _ := task.Occurrence.Unwrap()
+	var at time.Time
+	at, ok = r.Next(anchor, occurrence)
+	if !ok {
+		return
+	}
+	shift := at.Sub(anchor)
+
+	next = &Task{
+		ID: optional.None[string](),
+		OwnerID: task.OwnerID,
+		Title: task.Title,
+		Description: task.Description,
+		Status: optional.Some(StatusTodo),
+		ParentID: task.ParentID,
+		StartAt: task.StartAt,
+		DueAt: task.DueAt,
+		Labels: append([]string{}, task.Labels...),
+		DeletedAt: optional.None[time.Time](),
+		Recurrence: task.Recurrence,
+		SeriesID: task.SeriesID,
+		Occurrence: optional.Some(occurrence + 1),
+		ProjectID: task.ProjectID,
+		Checklist: uncheck(task.Checklist),
+	}
+	if startAt, e := task.StartAt.Unwrap(); e == nil {
+		next.StartAt = optional.Some(startAt.Add(shift))
+	}
+	if dueAt, e := task.DueAt.Unwrap(); e == nil {
+		next.DueAt = optional.Some(dueAt.Add(shift))
+	}
+	return
+}
+
+// joinSeries links the task to the given series, or starts its own series if it is recurring and it has none.
+// - the task must already have its id
+func joinSeries(task *Task, seriesId optional.Option[string], occurrence optional.Option[int]) {
+	task.SeriesID = seriesId
+	task.Occurrence = occurrence
+	if task.Recurrence.IsSome() && !seriesId.IsSome() {
+		task.SeriesID = task.ID
+		task.Occurrence = optional.Some(1)
+	}
+}
+
+// completes returns whether the change of status from the stored status to the given one completes the task (moves it to done).
+func completes(from optional.Option[Status], to optional.Option[Status]) bool {
+	status, _ := to.Unwrap()
+	stored, _ := from.Unwrap()
+	return status == StatusDone && stored != StatusDone
+}
+
+// uncheck returns a copy of the checklist with every item unchecked.
+func uncheck(items []Item) (unchecked []Item) {
+	if items == nil {
+		return
+	}
+	unchecked = make([]Item, len(items))
+	for i, it := range items {
+		unchecked[i] = Item{ID: it.ID, Text: it.Text}
+	}
+	return
+}
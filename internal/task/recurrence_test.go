@@ -0,0 +1,314 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LNMMusic/optional"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests
+func TestParseRule(t *testing.T) {
+	type input struct {rule string}
+	type output struct {r *Rule; err error; errMsg string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "daily",
+			input: input{rule: "FREQ=DAILY"},
+			output: output{r: &Rule{Freq: FrequencyDaily, Interval: 1}},
+		},
+		{
+			title: "weekly on given weekdays, with prefix and lower case",
+			input: input{rule: "RRULE:freq=weekly;interval=2;byday=fr,mo;count=10"},
+			output: output{r: &Rule{Freq: FrequencyWeekly, Interval: 2, ByDay: []time.Weekday{time.Monday, time.Friday}, Count: 10}},
+		},
+		{
+			title: "monthly by day until a date",
+			input: input{rule: "FREQ=MONTHLY;BYMONTHDAY=15,1;UNTIL=20231231"},
+			output: output{r: &Rule{Freq: FrequencyMonthly, Interval: 1, ByMonthDay: []int{1, 15}, Until: time.Date(2023, 12, 31, 23, 59, 59, 999999999, time.UTC)}},
+		},
+		{
+			title: "until a time",
+			input: input{rule: "FREQ=DAILY;UNTIL=20231231T120000Z"},
+			output: output{r: &Rule{Freq: FrequencyDaily, Interval: 1, Until: time.Date(2023, 12, 31, 12, 0, 0, 0, time.UTC)}},
+		},
+
+		// failure cases
+		{
+			title: "frequency required",
+			input: input{rule: "INTERVAL=2"},
+			output: output{err: ErrRuleInvalid, errMsg: "rule invalid: FREQ is required"},
+		},
+		{
+			title: "unsupported frequency",
+			input: input{rule: "FREQ=YEARLY"},
+			output: output{err: ErrRuleInvalid, errMsg: "rule invalid: unsupported frequency \"YEARLY\""},
+		},
+		{
+			title: "unsupported part",
+			input: input{rule: "FREQ=DAILY;BYHOUR=9"},
+			output: output{err: ErrRuleInvalid, errMsg: "rule invalid: unsupported part \"BYHOUR\""},
+		},
+		{
+			title: "malformed part",
+			input: input{rule: "FREQ=DAILY;"},
+			output: output{err: ErrRuleInvalid, errMsg: "rule invalid: malformed part \"\""},
+		},
+		{
+			title: "duplicated part",
+			input: input{rule: "FREQ=DAILY;FREQ=WEEKLY"},
+			output: output{err: ErrRuleInvalid, errMsg: "rule invalid: duplicated part \"FREQ\""},
+		},
+		{
+			title: "interval not positive",
+			input: input{rule: "FREQ=DAILY;INTERVAL=0"},
+			output: output{err: ErrRuleInvalid, errMsg: "rule invalid: INTERVAL must be a positive integer"},
+		},
+		{
+			title: "unsupported weekday",
+			input: input{rule: "FREQ=WEEKLY;BYDAY=1MO"},
+			output: output{err: ErrRuleInvalid, errMsg: "rule invalid: unsupported weekday \"1MO\""},
+		},
+		{
+			title: "unsupported month day",
+			input: input{rule: "FREQ=MONTHLY;BYMONTHDAY=-1"},
+			output: output{err: ErrRuleInvalid, errMsg: "rule invalid: unsupported month day \"-1\""},
+		},
+		{
+			title: "malformed until",
+			input: input{rule: "FREQ=DAILY;UNTIL=tomorrow"},
+			output: output{err: ErrRuleInvalid, errMsg: "rule invalid: malformed until \"TOMORROW\""},
+		},
+		{
+			title: "count and until",
+			input: input{rule: "FREQ=DAILY;COUNT=2;UNTIL=20231231"},
+			output: output{err: ErrRuleInvalid, errMsg: "rule invalid: COUNT and UNTIL are exclusive"},
+		},
+		{
+			title: "weekdays of a monthly rule",
+			input: input{rule: "FREQ=MONTHLY;BYDAY=MO"},
+			output: output{err: ErrRuleInvalid, errMsg: "rule invalid: BYDAY requires a weekly frequency"},
+		},
+		{
+			title: "month days of a weekly rule",
+			input: input{rule: "FREQ=WEEKLY;BYMONTHDAY=1"},
+			output: output{err: ErrRuleInvalid, errMsg: "rule invalid: BYMONTHDAY requires a monthly frequency"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// act
+			r, err := ParseRule(c.input.rule)
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if err != nil {
+				assert.Equal(t, c.output.errMsg, err.Error())
+				return
+			}
+			assert.Equal(t, c.output.r, r)
+		})
+	}
+}
+
+func TestRule_Next(t *testing.T) {
+	type input struct {rule string; after time.Time; occurrence int}
+	type output struct {next time.Time; ok bool}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+	}
+
+	// 2023-01-04 is a wednesday
+	wednesday := time.Date(2023, 1, 4, 9, 30, 0, 0, time.UTC)
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "daily every two days",
+			input: input{rule: "FREQ=DAILY;INTERVAL=2", after: wednesday, occurrence: 1},
+			output: output{next: time.Date(2023, 1, 6, 9, 30, 0, 0, time.UTC), ok: true},
+		},
+		{
+			title: "weekly on the weekday of the task",
+			input: input{rule: "FREQ=WEEKLY", after: wednesday, occurrence: 1},
+			output: output{next: time.Date(2023, 1, 11, 9, 30, 0, 0, time.UTC), ok: true},
+		},
+		{
+			title: "weekly on a later weekday of the same week",
+			input: input{rule: "FREQ=WEEKLY;BYDAY=MO,FR", after: wednesday, occurrence: 1},
+			output: output{next: time.Date(2023, 1, 6, 9, 30, 0, 0, time.UTC), ok: true},
+		},
+		{
+			title: "weekly every two weeks skips the next week",
+			input: input{rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE", after: wednesday, occurrence: 1},
+			output: output{next: time.Date(2023, 1, 16, 9, 30, 0, 0, time.UTC), ok: true},
+		},
+		{
+			title: "monthly on the day of the task",
+			input: input{rule: "FREQ=MONTHLY", after: wednesday, occurrence: 1},
+			output: output{next: time.Date(2023, 2, 4, 9, 30, 0, 0, time.UTC), ok: true},
+		},
+		{
+			title: "monthly by day skips the months without the day",
+			input: input{rule: "FREQ=MONTHLY;BYMONTHDAY=30", after: time.Date(2023, 1, 30, 0, 0, 0, 0, time.UTC), occurrence: 1},
+			output: output{next: time.Date(2023, 3, 30, 0, 0, 0, 0, time.UTC), ok: true},
+		},
+		{
+			title: "monthly by days in the same month",
+			input: input{rule: "FREQ=MONTHLY;BYMONTHDAY=1,15", after: wednesday, occurrence: 1},
+			output: output{next: time.Date(2023, 1, 15, 9, 30, 0, 0, time.UTC), ok: true},
+		},
+		{
+			title: "last occurrence by count",
+			input: input{rule: "FREQ=DAILY;COUNT=3", after: wednesday, occurrence: 2},
+			output: output{next: time.Date(2023, 1, 5, 9, 30, 0, 0, time.UTC), ok: true},
+		},
+		{
+			title: "last occurrence by until",
+			input: input{rule: "FREQ=DAILY;UNTIL=20230105", after: wednesday, occurrence: 1},
+			output: output{next: time.Date(2023, 1, 5, 9, 30, 0, 0, time.UTC), ok: true},
+		},
+
+		// failure cases
+		{
+			title: "series over by count",
+			input: input{rule: "FREQ=DAILY;COUNT=3", after: wednesday, occurrence: 3},
+			output: output{ok: false},
+		},
+		{
+			title: "series over by until",
+			input: input{rule: "FREQ=DAILY;UNTIL=20230104T235959Z", after: wednesday, occurrence: 1},
+			output: output{ok: false},
+		},
+		{
+			title: "month day that never comes",
+			input: input{rule: "FREQ=MONTHLY;INTERVAL=12;BYMONTHDAY=30", after: time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC), occurrence: 1},
+			output: output{ok: false},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// arrange
+			r, err := ParseRule(c.input.rule)
+			assert.NoError(t, err)
+
+			// act
+			next, ok := r.Next(c.input.after, c.input.occurrence)
+
+			// assert
+			assert.Equal(t, c.output.ok, ok)
+			if ok {
+				assert.Equal(t, c.output.next, next)
+			}
+		})
+	}
+}
+
+func TestNextOccurrence(t *testing.T) {
+	type input struct {task *Task}
+	type output struct {next *Task; ok bool}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+	}
+
+	cases := []testCase{
+		// succeed cases
+		{
+			title: "dates are moved forward and the checklist unchecked",
+			input: input{task: &Task{
+				ID: optional.Some("2"),
+				Title: optional.Some("title"),
+				Status: optional.Some(StatusDone),
+				StartAt: optional.Some(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)),
+				DueAt: optional.Some(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)),
+				Labels: []string{"backend"},
+				Recurrence: optional.Some("FREQ=WEEKLY"),
+				SeriesID: optional.Some("1"),
+				Occurrence: optional.Some(2),
+				Checklist: []Item{{ID: "a", Text: "milk", Checked: true}, {ID: "b", Text: "bread"}},
+			}},
+			output: output{next: &Task{
+				ID: optional.None[string](),
+				Title: optional.Some("title"),
+				Status: optional.Some(StatusTodo),
+				StartAt: optional.Some(time.Date(2023, 1, 8, 0, 0, 0, 0, time.UTC)),
+				DueAt: optional.Some(time.Date(2023, 1, 9, 0, 0, 0, 0, time.UTC)),
+				Labels: []string{"backend"},
+				DeletedAt: optional.None[time.Time](),
+				Recurrence: optional.Some("FREQ=WEEKLY"),
+				SeriesID: optional.Some("1"),
+				Occurrence: optional.Some(3),
+				Checklist: []Item{{ID: "a", Text: "milk"}, {ID: "b", Text: "bread"}},
+			}, ok: true},
+		},
+		{
+			title: "start date only",
+			input: input{task: &Task{
+				ID: optional.Some("1"),
+				Title: optional.Some("title"),
+				Status: optional.Some(StatusDone),
+				StartAt: optional.Some(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)),
+				Recurrence: optional.Some("FREQ=DAILY"),
+				SeriesID: optional.Some("1"),
+				Occurrence: optional.Some(1),
+			}},
+			output: output{next: &Task{
+				ID: optional.None[string](),
+				Title: optional.Some("title"),
+				Status: optional.Some(StatusTodo),
+				StartAt: optional.Some(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)),
+				Labels: []string{},
+				DeletedAt: optional.None[time.Time](),
+				Recurrence: optional.Some("FREQ=DAILY"),
+				SeriesID: optional.Some("1"),
+				Occurrence: optional.Some(2),
+			}, ok: true},
+		},
+
+		// failure cases
+		{
+			title: "task not recurring",
+			input: input{task: &Task{ID: optional.Some("1"), Status: optional.Some(StatusDone)}},
+			output: output{ok: false},
+		},
+		{
+			title: "series over",
+			input: input{task: &Task{
+				ID: optional.Some("1"),
+				Status: optional.Some(StatusDone),
+				DueAt: optional.Some(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)),
+				Recurrence: optional.Some("FREQ=DAILY;COUNT=1"),
+				SeriesID: optional.Some("1"),
+				Occurrence: optional.Some(1),
+			}},
+			output: output{ok: false},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// act
+			next, ok, err := nextOccurrence(c.input.task)
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, c.output.ok, ok)
+			assert.Equal(t, c.output.next, next)
+		})
+	}
+}
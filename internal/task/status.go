@@ -0,0 +1,56 @@
+package task
+
+// Status is the stage of the workflow a task is in.
+type Status string
+
+const (
+	StatusTodo 		 Status = "todo"
+	StatusInProgress Status = "in_progress"
+	StatusBlocked 	 Status = "blocked"
+	StatusDone 		 Status = "done"
+	StatusArchived 	 Status = "archived"
+)
+
+// Statuses are all the statuses a task can be in.
+var Statuses = []Status{StatusTodo, StatusInProgress, StatusBlocked, StatusDone, StatusArchived}
+
+// Valid returns whether the status is one of the known statuses.
+func (s Status) Valid() bool {
+	for _, status := range Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Closed returns whether the task is no longer open (done or archived).
+func (s Status) Closed() bool {
+	return s == StatusDone || s == StatusArchived
+}
+
+// Workflow is the table of the allowed transitions: the statuses a task can move to from each status.
+// - staying in the same status is always allowed
+type Workflow map[Status][]Status
+
+// DefaultWorkflow is the workflow used when none is configured.
+var DefaultWorkflow = Workflow{
+	StatusTodo: 	  {StatusInProgress, StatusBlocked, StatusDone, StatusArchived},
+	StatusInProgress: {StatusTodo, StatusBlocked, StatusDone, StatusArchived},
+	StatusBlocked: 	  {StatusTodo, StatusInProgress, StatusArchived},
+	StatusDone: 	  {StatusTodo, StatusArchived},
+	StatusArchived:   {StatusTodo},
+}
+
+// Allows returns whether the workflow allows to move a task from a status to another.
+func (w Workflow) Allows(from Status, to Status) bool {
+	if from == to {
+		return true
+	}
+	for _, status := range w[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
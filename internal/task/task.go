@@ -1,7 +1,13 @@
 package task
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"api/internal/project"
 
 	"github.com/LNMMusic/optional"
 )
@@ -9,33 +15,268 @@ import (
 // Interfaces
 type Task struct {
 	ID 			optional.Option[string]
+	// OwnerID is the id of the profile that owns the task (required to save it, then kept by the storage)
+	OwnerID 	optional.Option[string]
 	Title 		optional.Option[string]
 	Description optional.Option[string]
-	Completed 	optional.Option[bool]
+	// Status is the stage of the workflow the task is in
+	Status 		optional.Option[Status]
+	// ParentID is the id of the parent task (None if it is a top level task)
+	ParentID 	optional.Option[string]
+	// StartAt is the time the task is planned to start
+	StartAt 	optional.Option[time.Time]
+	// DueAt is the time the task is due
+	DueAt 		optional.Option[time.Time]
+	// CreatedAt and UpdatedAt are set by the storage
+	CreatedAt 	optional.Option[time.Time]
+	UpdatedAt 	optional.Option[time.Time]
+	// Labels are the normalized labels of the task, sorted by name
+	Labels 		[]string
+	// DeletedAt is the time the task was moved to the trash (None if it is not deleted)
+	DeletedAt 	optional.Option[time.Time]
+	// Recurrence is the recurrence rule of the task (see ParseRule), None if it does not repeat
+	Recurrence 	optional.Option[string]
+	// SeriesID is the id of the first task of the series the task is an occurrence of (set by the storage)
+	SeriesID 	optional.Option[string]
+	// Occurrence is the position of the task in its series, starting at 1 (set by the storage)
+	Occurrence 	optional.Option[int]
+	// Version is the revision of the task, starting at 1 and increased on every change (set by the storage)
+	Version 	optional.Option[int]
+	// ProjectID is the id of the project the task is in (None if it is in none), owned by the owner of the task
+	ProjectID 	optional.Option[string]
+	// Rank is the key that orders the tasks of the owner by hand (set by the storage, see Move)
+	Rank 		optional.Option[string]
+	// Checklist are the items of the task, in order
+	Checklist 	[]Item
 }
 
 // Storage is the interface that wraps the basic methods for a task storage.
+// - tasks are scoped to the profile that owns them: the tasks of other profiles are not found (ErrStorageNotFound)
+// - unless the owner shares them (see Grant): read lets the profile Get and Tree the task, edit lets it Update it and its labels too,
+// and with not enough access it fails with ErrStorageForbidden (the rest of the methods are left to the owner)
 type Storage interface {
-	// Get returns the task with the given id.
-	Get(id string) (ts *Task, err error)
+	// Get returns the task with the given id (tasks in the trash are not found).
+	Get(profileId string, id string) (ts *Task, err error)
+
+	// List returns the page of tasks that matches the given query.
+	List(profileId string, query *Query) (pg *Page, err error)
 
-	// Save saves the given task.
+	// Save saves the given task, setting its id and timestamps.
+	// - the task must have an owner
+	// - a recurring task starts its own series
+	// - the task is ranked after the rest of the tasks of the owner
 	Save(task *Task) (err error)
+
+	// Update replaces the task with the same id as the given task.
+	// - the owner, the creation time, the rank and the checklist are kept and the update time is set
+	// - a change of status must be allowed by the workflow of the validator, or it fails with ErrStorageTransition
+	// - completing a recurring task saves the next occurrence of its series, unless the series is over
+	// - with a version, it is only updated if it is still the stored one, or it fails with ErrStorageVersionMismatch
+	Update(profileId string, task *Task) (err error)
+
+	// Delete moves the task with the given id to the trash.
+	// - with a version, it (as Restore, and the label and checklist operations) only changes the task if it still has
+	// the version, or it fails with ErrStorageVersionMismatch; None changes it at any version
+	Delete(profileId string, id string, version optional.Option[int]) (err error)
+
+	// Restore moves the task with the given id out of the trash.
+	Restore(profileId string, id string, version optional.Option[int]) (err error)
+
+	// Purge removes for good the tasks moved to the trash before the given time (of every profile).
+	Purge(before time.Time) (n int, err error)
+
+	// AddLabel adds the given label to the task with the given id (it is a no-op if the task already has it).
+	// - adding or removing a label increases the version of the task
+	AddLabel(profileId string, id string, label string, version optional.Option[int]) (err error)
+
+	// RemoveLabel removes the given label from the task with the given id.
+	RemoveLabel(profileId string, id string, label string, version optional.Option[int]) (err error)
+
+	// Labels returns the labels in use by the tasks (not in the trash), sorted by name.
+	Labels(profileId string) (ls []*Label, err error)
+
+	// Tree returns the task with the given id and all of its subtasks (not in the trash).
+	Tree(profileId string, id string) (nd *Node, err error)
+
+	// AddDependency makes the task with the given id blocked by the task with the blocker id (it is a no-op if it already is).
+	// - a dependency that would make a cycle fails with ErrStorageCycle
+	AddDependency(profileId string, id string, blockerId string) (err error)
+
+	// RemoveDependency makes the task with the given id no longer blocked by the task with the blocker id.
+	RemoveDependency(profileId string, id string, blockerId string) (err error)
+
+	// Order returns the tasks with the given ids (not in the trash) in topological order: every task comes after its blockers.
+	Order(profileId string, ids []string) (ts []*Task, err error)
+
+	// Grant shares the task with the given id with the profile of the grant (it replaces the permission if it already has one).
+	Grant(profileId string, id string, grant *Grant) (err error)
+
+	// Revoke stops sharing the task with the given id with the grantee.
+	Revoke(profileId string, id string, granteeId string) (err error)
+
+	// Grants returns the grants of the task with the given id, sorted by profile id.
+	Grants(profileId string, id string) (gs []*Grant, err error)
+
+	// Shared returns the page of tasks shared with the profile (not in the trash) that matches the given query.
+	// - the trash of other profiles is not shared (query.Deleted fails with ErrStorageInvalidQuery)
+	Shared(profileId string, query *Query) (pg *Page, err error)
+
+	// Batch runs the given operations in order on behalf of the profile, and returns the result of each one.
+	// - at most MaxBatch operations, or it fails with ErrStorageInvalid (as an unknown mode does)
+	// - ModeAtomic applies all of them or none: if one fails it fails with ErrStorageBatchAborted, and the rest are aborted
+	// - ModeBestEffort applies every operation on its own, the errors are only in the results
+	Batch(profileId string, ops []*Op, mode Mode) (rs []*Result, err error)
+
+	// Move ranks the task with the given id between its neighbours: after the task with the after id and before the one with the before id.
+	// - one of the neighbours can be empty, then the task is moved right next to the other one
+	// - the neighbours must be tasks of the profile with a rank, in order, or it fails with ErrStorageInvalid
+	// - with a version, it only moves the task if it still has the version, or it fails with ErrStorageVersionMismatch
+	// - moving a task increases its version
+	Move(profileId string, id string, afterId string, beforeId string, version optional.Option[int]) (err error)
+
+	// Rebalance spreads evenly the ranks of the tasks of every profile with a rank longer than MaxRankLength, or a task without rank,
+	// keeping their order, and returns the amount of tasks ranked again.
+	Rebalance() (n int, err error)
+
+	// AddItem adds the given item to the end of the checklist of the task with the given id, setting the id of the item.
+	// - the checklist must be valid with the item, or it fails with ErrStorageInvalid
+	AddItem(profileId string, id string, item *Item, version optional.Option[int]) (err error)
+
+	// CheckItem checks or unchecks the item with the given id of the checklist of the task.
+	CheckItem(profileId string, id string, itemId string, checked bool, version optional.Option[int]) (err error)
+
+	// MoveItem moves the item with the given id of the checklist of the task to the given position (from zero).
+	// - a position out of the checklist fails with ErrStorageInvalid
+	MoveItem(profileId string, id string, itemId string, position int, version optional.Option[int]) (err error)
+
+	// RemoveItem removes the item with the given id from the checklist of the task.
+	// - the checklist operations need edit permission on the task, an item that does not exist fails with ErrStorageNotFound,
+	// and they increase the version of the task
+	RemoveItem(profileId string, id string, itemId string, version optional.Option[int]) (err error)
+
+	// WithContext returns the storage that runs its methods with the given context.
+	// - they are canceled when the context is done (e.g. the client of the request went away), failing with ErrStorageInternal
+	WithContext(ctx context.Context) (st Storage)
 }
 var (
-	ErrStorageInternal = errors.New("storage internal error")
-	ErrStorageNotFound = errors.New("storage task not found")
-	ErrStorageInvalid  = errors.New("storage invalid task")
+	ErrStorageInternal 	   = errors.New("storage internal error")
+	ErrStorageNotFound 	   = errors.New("storage task not found")
+	ErrStorageInvalid  	   = errors.New("storage invalid task")
+	ErrStorageInvalidQuery = errors.New("storage invalid query")
+	// ErrStorageForbidden is returned when the task is shared with the profile without enough permission
+	ErrStorageForbidden    = errors.New("storage task forbidden")
+	// ErrStorageCycle is returned when the task would end up being its own ancestor or its own blocker
+	ErrStorageCycle 	   = fmt.Errorf("%w: cycle", ErrStorageInvalid)
+	// ErrStorageOpenChildren is returned when completing a task with open subtasks (HierarchyRestrict)
+	ErrStorageOpenChildren = fmt.Errorf("%w: open children", ErrStorageInvalid)
+	// ErrStorageOpenBlockers is returned when completing a task blocked by open tasks
+	ErrStorageOpenBlockers = fmt.Errorf("%w: open blockers", ErrStorageInvalid)
+	// ErrStorageTransition is returned when the workflow does not allow the change of status of the task
+	ErrStorageTransition   = fmt.Errorf("%w: transition", ErrStorageInvalid)
+	// ErrStorageVersionMismatch is returned when the task was changed since the version it is updated from
+	ErrStorageVersionMismatch = errors.New("storage task version mismatch")
+)
+
+// Config is the configuration of the task storages.
+type Config struct {
+	// Hierarchy is the rule applied to the subtasks of a task that is completed.
+	Hierarchy HierarchyRule
+	// Projects is the storage the project of a task is checked against by the local storage (nil leaves it unchecked).
+	// - the MySQL storage checks it on the projects table
+	Projects  project.Storage
+	// SnapshotEvery is the number of records the log of the WAL storage is compacted into a snapshot after (DefaultSnapshotEvery if 0).
+	SnapshotEvery int
+}
+
+// HierarchyRule is the rule applied to the subtasks of a task that is completed (moved to done).
+type HierarchyRule string
+
+const (
+	// HierarchyNone completes the task regardless of its subtasks.
+	HierarchyNone 	  HierarchyRule = "none"
+	// HierarchyRestrict refuses to complete the task while any of its subtasks is open.
+	HierarchyRestrict HierarchyRule = "restrict"
+	// HierarchyCascade completes all the open subtasks of the task, down the whole subtree.
+	HierarchyCascade  HierarchyRule = "cascade"
 )
 
+// newConfig returns the given config completed with the default values.
+func newConfig(cfg *Config) (defaultCfg *Config) {
+	defaultCfg = &Config{
+		Hierarchy: HierarchyNone,
+	}
+	if cfg != nil {
+		if cfg.Hierarchy != "" {
+			defaultCfg.Hierarchy = cfg.Hierarchy
+		}
+		defaultCfg.Projects = cfg.Projects
+		defaultCfg.SnapshotEvery = cfg.SnapshotEvery
+	}
+	return
+}
+
+// Node is a task with its subtasks.
+type Node struct {
+	Task 	 *Task
+	// Children are the subtasks of the task, sorted by id
+	Children []*Node
+}
+
+// Label is a label in use and the amount of tasks that have it.
+type Label struct {
+	Name  string
+	Tasks int
+}
+
+// NormalizeLabel returns the normalized form of the given label (trimmed and lower case).
+func NormalizeLabel(label string) string {
+	return strings.ToLower(strings.TrimSpace(label))
+}
+
+// Query is the set of parameters used to list tasks.
+type Query struct {
+	// Cursor is the opaque token of the page to fetch (empty for the first page).
+	Cursor string
+	// Size is the maximum amount of tasks of the page (DefaultPageSize if zero).
+	Size int
+	// Deleted lists the tasks in the trash instead of the active ones.
+	Deleted bool
+	// Filter is the filter the tasks must match (nil matches all the tasks).
+	Filter Filter
+	// Sort is the order of the tasks.
+	Sort Sort
+}
+var (
+	ErrQueryUnknownField 	= errors.New("query unknown field")
+	ErrQueryUnknownOperator = errors.New("query unknown operator")
+	ErrQueryInvalidValue 	= errors.New("query invalid value")
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize 	= 100
+)
+
+// Page is a page of tasks.
+type Page struct {
+	// Tasks are the tasks of the page.
+	Tasks []*Task
+	// Next is the cursor of the next page (None if it is the last page).
+	Next optional.Option[string]
+}
+
 // Validator is the interface that wraps the basic methods for a task validator.
 type Validator interface {
 	// Validate validates the given task.
 	Validate(task *Task) (err error)
+
+	// Transition validates the change of status of a task.
+	Transition(from Status, to Status) (err error)
 }
 var (
 	ErrValidatorInternal 	  = errors.New("validator internal error")
 	ErrValidatorFieldRequired = errors.New("validator field required")
 	ErrValidatorFieldEmpty	  = errors.New("validator field empty")
 	ErrValidatorFieldQuality  = errors.New("validator field quality")
+	ErrValidatorTransition 	  = errors.New("validator transition not allowed")
 )
\ No newline at end of file
@@ -1,6 +1,7 @@
 package transactioner
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 )
@@ -8,10 +9,14 @@ import (
 // operation is a function that can be run in a transaction
 type operation func() (err error)
 
+// operationTx is a function that runs its statements on the transaction
+type operationTx func(tx *sql.Tx) (err error)
+
 // NewImplTransactionerDefault creates a new default implementation of Transactioner
 func NewImplTransactionerDefault(db *sql.DB) (impl *ImplTransactionerDefault) {
 	impl = &ImplTransactionerDefault{
 		db: db,
+		ctx: context.Background(),
 	}
 	return
 }
@@ -19,12 +24,30 @@ func NewImplTransactionerDefault(db *sql.DB) (impl *ImplTransactionerDefault) {
 // ImplTransactionerDefault is the default implementation of Transactioner
 type ImplTransactionerDefault struct {
 	db *sql.DB
+	// ctx is the context the transactions are begun with (see WithContext)
+	ctx context.Context
+}
+
+// WithContext returns a copy of the transactioner that begins its transactions with the given context
+func (impl *ImplTransactionerDefault) WithContext(ctx context.Context) (tr Transactioner) {
+	cp := *impl
+	cp.ctx = ctx
+	tr = &cp
+	return
 }
 
 func (impl *ImplTransactionerDefault) Do(op operation) (err error) {
+	err = impl.DoTx(func(tx *sql.Tx) (err error) {
+		err = op()
+		return
+	})
+	return
+}
+
+func (impl *ImplTransactionerDefault) DoTx(op operationTx) (err error) {
 	// begin transaction
 	var tx *sql.Tx
-	tx, err = impl.db.Begin()
+	tx, err = impl.db.BeginTx(impl.ctx, nil)
 	if err != nil {
 		err = fmt.Errorf("%w. %v", ErrTransactionBegin, err)
 		return
@@ -51,7 +74,7 @@ func (impl *ImplTransactionerDefault) Do(op operation) (err error) {
 	}()
 
 	// run operation
-	err = op()
+	err = op(tx)
 	if err != nil {
 		err = fmt.Errorf("%w. %v", ErrTransactionOperation, err)
 		return
@@ -1,6 +1,8 @@
 package transactioner
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"testing"
 
@@ -99,4 +101,120 @@ func TestImplTransactionerDefault(t *testing.T) {
 			assert.NoError(t, mk.ExpectationsWereMet())
 		})
 	}
-}
\ No newline at end of file
+}
+func TestImplTransactionerDefault_DoTx(t *testing.T) {
+	type output struct { err error; errMsg string }
+	type test struct {
+		name string
+		output output
+		setUpMockDB func(mk sqlmock.Sqlmock)
+	}
+
+	cases := []test{
+		{
+			name: "valid case",
+			output: output{err: nil, errMsg: ""},
+			setUpMockDB: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.ExpectExec("UPDATE t SET v = 1").WillReturnResult(sqlmock.NewResult(0, 1))
+				mk.ExpectCommit()
+			},
+		},
+
+		{
+			name: "operation error",
+			output: output{err: ErrTransactionOperation, errMsg: "transactioner: operation failed. mysql exec error"},
+			setUpMockDB: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.ExpectExec("UPDATE t SET v = 1").WillReturnError(errors.New("mysql exec error"))
+				mk.ExpectRollback()
+			},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			c.setUpMockDB(mk)
+
+			impl := NewImplTransactionerDefault(db)
+
+			// act
+			// -> the statement runs on the transaction
+			err = impl.DoTx(func(tx *sql.Tx) (err error) {
+				_, err = tx.Exec("UPDATE t SET v = 1")
+				return
+			})
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if c.output.err != nil {
+				assert.EqualError(t, err, c.output.errMsg)
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestImplTransactionerDefault_WithContext(t *testing.T) {
+	type output struct { err error; errMsg string }
+	type test struct {
+		name string
+		output output
+		setUpContext func() (ctx context.Context)
+		setUpMockDB func(mk sqlmock.Sqlmock)
+	}
+
+	cases := []test{
+		{
+			name: "valid case",
+			output: output{err: nil, errMsg: ""},
+			setUpContext: func() (ctx context.Context) {return context.Background()},
+			setUpMockDB: func(mk sqlmock.Sqlmock) {
+				mk.ExpectBegin()
+				mk.ExpectCommit()
+			},
+		},
+		// -> the transaction is not begun with a canceled context
+		{
+			name: "canceled context",
+			output: output{err: ErrTransactionBegin, errMsg: "transactioner: cannot begin transaction. context canceled"},
+			setUpContext: func() (ctx context.Context) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return
+			},
+			setUpMockDB: func(mk sqlmock.Sqlmock) {},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			db, mk, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			c.setUpMockDB(mk)
+
+			impl := NewImplTransactionerDefault(db)
+
+			// act
+			err = impl.WithContext(c.setUpContext()).Do(func() (err error) {return})
+
+			// assert
+			assert.ErrorIs(t, err, c.output.err)
+			if c.output.err != nil {
+				assert.EqualError(t, err, c.output.errMsg)
+			}
+			// -> expectations
+			assert.NoError(t, mk.ExpectationsWereMet())
+		})
+	}
+}
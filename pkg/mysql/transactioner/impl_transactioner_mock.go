@@ -1,6 +1,10 @@
 package transactioner
 
-import "github.com/stretchr/testify/mock"
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
 
 // NewImplTransactionerMock returns a new mock for the Transactioner interface
 func NewImplTransactionerMock() *ImplTransactionerMock {
@@ -20,4 +24,21 @@ func (mk *ImplTransactionerMock) Do(op operation) (err error) {
 
 	err = args.Error(0)
 	return
+}
+
+// DoTx provides a mock function with given fields: f
+// - the operation is run without transaction (nil)
+func (mk *ImplTransactionerMock) DoTx(op operationTx) (err error) {
+	args := mk.Called(op)
+
+	op(nil)
+
+	err = args.Error(0)
+	return
+}
+
+// WithContext returns the mock itself (the context is not recorded)
+func (mk *ImplTransactionerMock) WithContext(ctx context.Context) (tr Transactioner) {
+	tr = mk
+	return
 }
\ No newline at end of file
@@ -1,6 +1,9 @@
 package transactioner
 
-import "errors"
+import (
+	"context"
+	"errors"
+)
 
 // Transactioner is an interface for a mysql transaction
 type Transactioner interface {
@@ -8,6 +11,15 @@ type Transactioner interface {
 	// - Success: transaction is committed
 	// - Failure: transaction is rolled back
 	Do(op operation) (err error)
+
+	// DoTx runs the operation in a transaction, with the statements of the operation run on it
+	// - Success: transaction is committed
+	// - Failure: transaction is rolled back
+	DoTx(op operationTx) (err error)
+
+	// WithContext returns the transactioner that begins its transactions with the given context
+	// - a transaction is rolled back if the context is done before it is committed
+	WithContext(ctx context.Context) (tr Transactioner)
 }
 var (
 	// ErrTransactionBegin is returned when a transaction cannot be started
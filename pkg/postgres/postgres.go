@@ -0,0 +1,22 @@
+package postgres
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Bind numbers the ? placeholders of the statement in order ($1, $2, ...), for the statements written for MySQL.
+// - the statements have no ? other than their placeholders
+func Bind(query string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r != '?' {
+			b.WriteRune(r)
+			continue
+		}
+		n++
+		b.WriteString("$" + strconv.Itoa(n))
+	}
+	return b.String()
+}
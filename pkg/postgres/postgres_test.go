@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests
+func TestBind(t *testing.T) {
+	type input struct {query string}
+	type output struct {query string}
+	type testCase struct {
+		title  string
+		input  input
+		output output
+	}
+
+	cases := []testCase{
+		{
+			title: "statement without placeholders",
+			input: input{query: "SELECT 1"},
+			output: output{query: "SELECT 1"},
+		},
+		{
+			title: "placeholders numbered in order",
+			input: input{query: "UPDATE tasks SET rank_key = ? WHERE id = ? AND owner_id IN (?, ?)"},
+			output: output{query: "UPDATE tasks SET rank_key = $1 WHERE id = $2 AND owner_id IN ($3, $4)"},
+		},
+	}
+
+	// run tests
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			// act
+			query := Bind(c.input.query)
+
+			// assert
+			assert.Equal(t, c.output.query, query)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package sqlite
+
+import (
+	"database/sql"
+	"net/url"
+
+	// pure go driver (no cgo), registered as "sqlite"
+	_ "modernc.org/sqlite"
+)
+
+// Open opens the SQLite database in the file at the given path (created if it does not exist).
+// - foreign keys are enforced (removals cascade as in MySQL)
+// - times are written in a format SQLite compares in order, and scanned back as time
+// - a single connection is kept: SQLite runs one writer at a time, so the statements wait for each other instead of failing busy
+func Open(path string) (db *sql.DB, err error) {
+	q := url.Values{}
+	q.Add("_pragma", "foreign_keys(1)")
+	q.Add("_pragma", "busy_timeout(5000)")
+	q.Add("_pragma", "journal_mode(WAL)")
+	q.Set("_time_format", "sqlite")
+
+	db, err = sql.Open("sqlite", "file:"+path+"?"+q.Encode())
+	if err != nil {
+		return
+	}
+	db.SetMaxOpenConns(1)
+	return
+}